@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newActivityCommand creates the activity command
+func newActivityCommand(app *App) *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "activity",
+		Short: "Show recent activity across all tasks",
+		Long: `Show a chronological stream of task creations, state changes, blocking
+changes, and edits across all tasks, one line per change like git log --oneline.`,
+		Example: `  gtd activity
+  gtd activity --since 24h
+  gtd activity --since 2024-01-01
+  gtd activity --since "last monday"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			history, err := repo.ListHistory(sinceTime)
+			if err != nil {
+				return fmt.Errorf("failed to list activity: %w", err)
+			}
+
+			tasks, err := repo.List(models.ListOptions{All: true, ShowDone: true, ShowCancelled: true})
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			formatActivity(app, cmd.OutOrStdout(), withLegacyCreations(history, tasks, sinceTime), tasks)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "Show activity since a relative age (24h, 7d, 2w) or date (yesterday, last monday, 2024-01-01)")
+
+	return cmd
+}
+
+// withLegacyCreations adds creation entries for tasks created before history
+// was recorded, keeping the result in chronological order
+func withLegacyCreations(history []*models.HistoryEntry, tasks []*models.Task, since time.Time) []*models.HistoryEntry {
+	recorded := make(map[string]bool)
+	for _, entry := range history {
+		if entry.Action == models.ActionCreate {
+			recorded[entry.TaskID] = true
+		}
+	}
+
+	added := false
+	for _, task := range tasks {
+		if recorded[task.ID] || task.Created.Before(since) {
+			continue
+		}
+		history = append(history, &models.HistoryEntry{
+			TaskID:  task.ID,
+			Author:  task.Author,
+			Action:  models.ActionCreate,
+			Created: task.Created,
+		})
+		added = true
+	}
+
+	if added {
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].Created.Before(history[j].Created)
+		})
+	}
+	return history
+}
+
+// formatActivity writes one line per history entry
+func formatActivity(app *App, w io.Writer, history []*models.HistoryEntry, tasks []*models.Task) {
+	if len(history) == 0 {
+		_, _ = fmt.Fprintln(w, "No activity found.")
+		return
+	}
+
+	byID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	for _, entry := range history {
+		title := ""
+		if task, ok := byID[entry.TaskID]; ok {
+			title = task.Title
+		}
+		_, _ = fmt.Fprintf(w, "%s %s %s %s: %s\n",
+			colorize(shortID(entry.TaskID), colorYellow),
+			app.formatTimestamp(entry.Created),
+			colorize(authorName(entry.Author), colorCyan),
+			describeHistoryEntry(entry),
+			title)
+	}
+}
+
+// authorName strips the email address from a "Name <email>" author string
+func authorName(author string) string {
+	if i := strings.Index(author, " <"); i > 0 {
+		return author[:i]
+	}
+	return author
+}
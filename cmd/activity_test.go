@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestActivityCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Active bug", "A bug with recent activity")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(task.ID, models.StateNew); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		contains []string
+	}{
+		{
+			name:     "default window",
+			args:     []string{},
+			contains: []string{task.ShortHash(), "created in INBOX: Active bug", "INBOX → NEW: Active bug"},
+		},
+		{
+			name:     "future window is empty",
+			args:     []string{"--since", time.Now().Add(48 * time.Hour).Format("2006-01-02")},
+			contains: []string{"No activity found."},
+		},
+		{
+			name:    "invalid since",
+			args:    []string{"--since", "yesterday-ish"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newActivityCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("Output does not contain %q\nGot: %s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "30m", want: now.Add(-30 * time.Minute)},
+		{value: "24h", want: now.Add(-24 * time.Hour)},
+		{value: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{value: "2w", want: now.Add(-14 * 24 * time.Hour)},
+		{value: "2024-01-01", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2024-01-01 08:30:00", want: time.Date(2024, 1, 1, 8, 30, 0, 0, time.Local)},
+		{value: "2024-01-01T08:30:00Z", want: time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)},
+		{value: "", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
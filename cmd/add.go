@@ -10,13 +10,18 @@ import (
 
 // Common flags for add commands
 type addFlags struct {
-	priority string
-	source   string
-	tags     string
+	priority  string
+	source    string
+	file      string
+	fromFile  string
+	tags      string
+	ref       string
+	createdAt string
+	noVerify  bool
 }
 
 // newAddBugCommand creates the add-bug command
-func newAddBugCommand() *cobra.Command {
+func newAddBugCommand(app *App) *cobra.Command {
 	var flags addFlags
 
 	cmd := &cobra.Command{
@@ -41,7 +46,7 @@ Users can access admin panel without proper credentials.
 This is a critical security vulnerability.
 EOF`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addTask(cmd, models.KindBug, &flags)
+			return addTask(app, cmd, models.KindBug, &flags)
 		},
 	}
 
@@ -50,7 +55,7 @@ EOF`,
 }
 
 // newAddFeatureCommand creates the add-feature command
-func newAddFeatureCommand() *cobra.Command {
+func newAddFeatureCommand(app *App) *cobra.Command {
 	var flags addFlags
 
 	cmd := &cobra.Command{
@@ -75,7 +80,7 @@ Allow users to customize their dashboard layout.
 Include options for widget placement and color schemes.
 EOF`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addTask(cmd, models.KindFeature, &flags)
+			return addTask(app, cmd, models.KindFeature, &flags)
 		},
 	}
 
@@ -84,7 +89,7 @@ EOF`,
 }
 
 // newAddRegressionCommand creates the add-regression command
-func newAddRegressionCommand() *cobra.Command {
+func newAddRegressionCommand(app *App) *cobra.Command {
 	var flags addFlags
 
 	cmd := &cobra.Command{
@@ -109,7 +114,7 @@ Search results are no longer sorted by relevance.
 This worked correctly in v2.0.5 but broke in v2.1.0.
 EOF`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addTask(cmd, models.KindRegression, &flags)
+			return addTask(app, cmd, models.KindRegression, &flags)
 		},
 	}
 
@@ -123,14 +128,30 @@ func addCommonFlags(cmd *cobra.Command, flags *addFlags) {
 		"Task priority (high, medium, low)")
 	cmd.Flags().StringVarP(&flags.source, "source", "s", "",
 		"Source reference (e.g., file:line, issue#, version)")
+	cmd.Flags().StringVar(&flags.file, "file", "",
+		"Set the source from a file path[:line], relative to the repository root")
 	cmd.Flags().StringVarP(&flags.tags, "tags", "t", "",
 		"Comma-separated tags")
+	cmd.Flags().StringVar(&flags.ref, "ref", "",
+		"External reference (issue URL or ticket key)")
+	cmd.Flags().BoolVar(&flags.noVerify, "no-verify", false,
+		"Skip title and description validation rules")
+	cmd.Flags().StringVarP(&flags.fromFile, "from-file", "F", "",
+		"Read the title and description from a file instead of stdin")
+	addCreatedAtFlag(cmd, &flags.createdAt)
+	cmd.MarkFlagsMutuallyExclusive("source", "file")
 }
 
 // addTask handles the common logic for adding tasks
-func addTask(cmd *cobra.Command, kind string, flags *addFlags) error {
+func addTask(app *App, cmd *cobra.Command, kind models.Kind, flags *addFlags) error {
 	// Read input
-	title, description, err := readTaskInput(cmd.InOrStdin())
+	var title, description string
+	var err error
+	if flags.fromFile != "" {
+		title, description, err = readTaskFile(flags.fromFile)
+	} else {
+		title, description, err = readTaskInputOrEdit(app, cmd.InOrStdin(), kind)
+	}
 	if err != nil {
 		return err
 	}
@@ -140,24 +161,34 @@ func addTask(cmd *cobra.Command, kind string, flags *addFlags) error {
 
 	// Apply flags
 	if flags.priority != "" {
-		// Validate priority
-		switch flags.priority {
-		case models.PriorityHigh, models.PriorityMedium, models.PriorityLow:
-			task.Priority = flags.priority
-		default:
-			return fmt.Errorf("invalid priority: %s (must be high, medium, or low)", flags.priority)
+		if task.Priority, err = models.ParsePriority(flags.priority); err != nil {
+			return err
 		}
 	}
 
-	task.Source = flags.source
+	task.Source, err = resolveSource(app, flags.source, flags.file)
+	if err != nil {
+		return err
+	}
 	task.Tags = flags.tags
+	task.ExternalRef = flags.ref
+	if err := applyCreatedAt(task, flags.createdAt); err != nil {
+		return err
+	}
 
 	// Save to database
-	if err := repo.Create(task); err != nil {
+	rules := app.ValidationRules()
+	if flags.noVerify {
+		rules = models.ValidationRules{}
+	}
+	if err := newTaskServiceWithRules(app, cmd, rules).CreateTask(task); err != nil {
 		// Check if it's a validation error and provide helpful guidance
 		if strings.Contains(err.Error(), "description is required") {
 			return fmt.Errorf("failed to create task: %w\n\nTasks must include both a title and a description.\nUse Git-style format:\n  <title>\n  \n  <description>", err)
 		}
+		if models.IsRuleViolation(err) {
+			return fmt.Errorf("failed to create task: %w\n\nUse --no-verify to skip validation rules", err)
+		}
 		return fmt.Errorf("failed to create task: %w", err)
 	}
 
@@ -3,20 +3,32 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
 // Common flags for add commands
 type addTaskFlags struct {
-	priority string
-	source   string
-	tags     string
+	priority  string
+	source    string
+	file      string
+	fromFile  string
+	tags      string
+	ref       string
+	severity     string
+	introducedIn string
+	environment  string
+	regresses    string
+	createdAt    string
+	noVerify  bool
+	dryRun    bool
 }
 
 // newAddCommand creates the add command with subcommands
-func newAddCommand() *cobra.Command {
+func newAddCommand(app *App) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Add a new task",
@@ -38,16 +50,16 @@ func newAddCommand() *cobra.Command {
 
 	// Add subcommands for each task type
 	cmd.AddCommand(
-		newAddTaskCommand("bug", models.KindBug),
-		newAddTaskCommand("feature", models.KindFeature),
-		newAddTaskCommand("regression", models.KindRegression),
+		newAddTaskCommand(app, "bug", models.KindBug),
+		newAddTaskCommand(app, "feature", models.KindFeature),
+		newAddTaskCommand(app, "regression", models.KindRegression),
 	)
 
 	return cmd
 }
 
 // newAddTaskCommand creates a subcommand for adding a specific task type
-func newAddTaskCommand(cmdName, taskKind string) *cobra.Command {
+func newAddTaskCommand(app *App, cmdName string, taskKind models.Kind) *cobra.Command {
 	var flags addTaskFlags
 
 	// Build command metadata
@@ -56,7 +68,10 @@ func newAddTaskCommand(cmdName, taskKind string) *cobra.Command {
 Input is read from stdin in Git-style format:
   TITLE
   
-  DESCRIPTION (required, can be multiple lines)`, cmdName)
+  DESCRIPTION (required, can be multiple lines)
+
+When run in a terminal without piped input, the editor from $VISUAL or
+$EDITOR opens with a commented template instead.`, cmdName)
 
 	// Build examples based on task type
 	var example string
@@ -69,7 +84,7 @@ Memory usage grows unbounded when processing large files.
 Need to investigate the file processing loop.
 EOF
 
-  gtd add bug --priority high --source "app.go:42" <<EOF
+  gtd add bug --priority high --severity critical --source "app.go:42" <<EOF
 Fix authentication bypass
 
 Users can access admin panel without proper credentials.
@@ -97,7 +112,7 @@ Authentication fails with valid credentials after v2.1.0 update.
 Users report "Invalid credentials" error despite correct password.
 EOF
 
-  gtd add regression --priority high --source "v2.1.0" <<EOF
+  gtd add regression --priority high --introduced-in v2.1.0 --environment prod --regresses abc123 <<EOF
 Search functionality regression
 
 Search results are no longer sorted by relevance.
@@ -111,7 +126,7 @@ EOF`
 		Long:    longDesc,
 		Example: example,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addTaskWithKind(cmd, taskKind, &flags)
+			return addTaskWithKind(app, cmd, taskKind, &flags)
 		},
 	}
 
@@ -120,16 +135,45 @@ EOF`
 		"Task priority (high, medium, low)")
 	cmd.Flags().StringVarP(&flags.source, "source", "s", "",
 		"Source reference (e.g., file:line, issue#, version)")
+	cmd.Flags().StringVar(&flags.file, "file", "",
+		"Set the source from a file path[:line], relative to the repository root")
 	cmd.Flags().StringVarP(&flags.tags, "tags", "t", "",
 		"Comma-separated tags")
+	cmd.Flags().StringVar(&flags.ref, "ref", "",
+		"External reference (issue URL or ticket key)")
+	cmd.Flags().BoolVar(&flags.noVerify, "no-verify", false,
+		"Skip title and description validation rules")
+	cmd.Flags().StringVarP(&flags.fromFile, "from-file", "F", "",
+		"Read the title and description from a file instead of stdin")
+	if taskKind != models.KindFeature {
+		cmd.Flags().StringVar(&flags.severity, "severity", "",
+			"Impact, separate from the priority (critical, major, minor)")
+	}
+	if taskKind == models.KindRegression {
+		cmd.Flags().StringVar(&flags.introducedIn, "introduced-in", "",
+			"Release that introduced the regression (e.g. v2.1.0)")
+		cmd.Flags().StringVar(&flags.environment, "environment", "",
+			"Environment the regression was seen in (e.g. prod)")
+		cmd.Flags().StringVar(&flags.regresses, "regresses", "",
+			"ID of the feature or bug this regresses, linked with a regresses link")
+	}
+	addCreatedAtFlag(cmd, &flags.createdAt)
+	addDryRunFlag(cmd, &flags.dryRun)
+	cmd.MarkFlagsMutuallyExclusive("source", "file")
 
 	return cmd
 }
 
 // addTaskWithKind handles the common logic for adding tasks
-func addTaskWithKind(cmd *cobra.Command, kind string, flags *addTaskFlags) error {
+func addTaskWithKind(app *App, cmd *cobra.Command, kind models.Kind, flags *addTaskFlags) error {
 	// Read input
-	title, description, err := readTaskInput(cmd.InOrStdin())
+	var title, description string
+	var err error
+	if flags.fromFile != "" {
+		title, description, err = readTaskFile(flags.fromFile)
+	} else {
+		title, description, err = readTaskInputOrEdit(app, cmd.InOrStdin(), kind)
+	}
 	if err != nil {
 		return err
 	}
@@ -139,31 +183,130 @@ func addTaskWithKind(cmd *cobra.Command, kind string, flags *addTaskFlags) error
 
 	// Apply flags
 	if flags.priority != "" {
-		// Validate priority
-		switch flags.priority {
-		case models.PriorityHigh, models.PriorityMedium, models.PriorityLow:
-			task.Priority = flags.priority
-		default:
-			return fmt.Errorf("invalid priority: %s (must be high, medium, or low)", flags.priority)
+		if task.Priority, err = models.ParsePriority(flags.priority); err != nil {
+			return err
 		}
 	}
 
-	task.Source = flags.source
+	task.Source, err = resolveSource(app, flags.source, flags.file)
+	if err != nil {
+		return err
+	}
 	task.Tags = flags.tags
+	if !cmd.Flags().Changed("priority") {
+		if priority := app.TagSettings().Priority(task.Tags); priority != "" {
+			task.Priority = priority
+		}
+	}
+	task.ExternalRef = flags.ref
+	if flags.severity != "" {
+		if task.Severity, err = models.ParseSeverity(flags.severity); err != nil {
+			return err
+		}
+	}
+	task.IntroducedIn = strings.TrimSpace(flags.introducedIn)
+	task.Environment = strings.TrimSpace(flags.environment)
+	if err := applyCreatedAt(task, flags.createdAt); err != nil {
+		return err
+	}
+	var regressed *models.Task
+	if flags.regresses != "" {
+		if regressed, err = repo.GetByID(flags.regresses); err != nil {
+			return fmt.Errorf("regressed task not found: %w", err)
+		}
+	}
 
 	// Save to database
-	if err := repo.Create(task); err != nil {
+	rules := app.ValidationRules()
+	if flags.noVerify {
+		rules = models.ValidationRules{}
+	}
+	create := newTaskServiceWithRules(app, cmd, rules).CreateTask
+	if flags.dryRun {
+		create = func(task *models.Task) error { return validateNewTask(task, rules) }
+	}
+	if err := create(task); err != nil {
 		// Check if it's a validation error and provide helpful guidance
 		if strings.Contains(err.Error(), "description is required") {
 			return fmt.Errorf("failed to create task: %w\n\nTasks must include both a title and a description.\nUse Git-style format:\n  <title>\n  \n  <description>", err)
 		}
+		if models.IsRuleViolation(err) {
+			return fmt.Errorf("failed to create task: %w\n\nUse --no-verify to skip validation rules", err)
+		}
 		return fmt.Errorf("failed to create task: %w", err)
 	}
 
 	// Output success message
+	if flags.dryRun {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would create %s task %s: %s\n", strings.ToLower(kind.String()), task.ShortHash(), task.Title)
+		return nil
+	}
 	if _, err := fmt.Fprintln(cmd.OutOrStdout(), formatTaskCreated(task.ID, kind)); err != nil {
 		return err
 	}
+	if regressed != nil {
+		link, err := repo.AddLink(task.ID, regressed.ID, models.LinkRegresses)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Linked: %s %s %s\n",
+			shortID(link.SourceID), link.Label(link.SourceID), shortID(link.TargetID))
+	}
+	if app.Config().AutoReview {
+		if err := reviewCreatedTask(app, cmd, task); err != nil {
+			return err
+		}
+	}
+
+	return warnSimilarTasks(app, cmd.ErrOrStderr(), task)
+}
+
+// reviewCreatedTask shows a created task as gtd show would, followed by how
+// many tasks wait in the inbox, so the result of an add can be checked
+// without another command
+func reviewCreatedTask(app *App, cmd *cobra.Command, task *models.Task) error {
+	inbox, err := repo.ListByState(models.StateInbox)
+	if err != nil {
+		return fmt.Errorf("failed to list inbox tasks: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "\n%s", app.formatTaskGitStyle(task, nil))
+	_, _ = fmt.Fprintf(out, "\n%d task(s) in INBOX; run 'gtd review' to triage them\n", len(inbox))
+	return nil
+}
+
+// addCreatedAtFlag adds the --created-at flag of commands that create tasks
+func addCreatedAtFlag(cmd *cobra.Command, createdAt *string) {
+	cmd.Flags().StringVar(createdAt, "created-at", "",
+		"Backdate the task, e.g. when importing (e.g. 2024-05-01 17:00, 3 days ago)")
+}
 
+// applyCreatedAt backdates a new task to the time given with --created-at,
+// if any
+func applyCreatedAt(task *models.Task, createdAt string) error {
+	if createdAt == "" {
+		return nil
+	}
+	now := time.Now()
+	created, err := parseDate(createdAt, now)
+	if err != nil {
+		return err
+	}
+	if created.After(now) {
+		return errors.NewValidationError("--created-at %q means %s, which is in the future", createdAt, created.Format(displayDateFormat))
+	}
+	task.Created, task.Updated = created, created
+	return nil
+}
+
+// validateNewTask checks a task like TaskService.CreateTask without saving it
+func validateNewTask(task *models.Task, rules models.ValidationRules) error {
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if err := rules.Check(task); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
 	return nil
 }
\ No newline at end of file
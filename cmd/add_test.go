@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/zw3rk/gtd/internal/database"
 	"github.com/zw3rk/gtd/internal/models"
 )
@@ -37,6 +40,17 @@ func setupTestCommand(t *testing.T) (*database.Database, *models.TaskRepository,
 	return testDB, testRepo, cleanup
 }
 
+// setupMemoryCommand points the commands at an in-memory store, for tests of
+// commands that only go through the repository
+func setupMemoryCommand(t *testing.T) (*models.MemoryStore, func()) {
+	testRepo := models.NewMemoryStore()
+
+	oldDB, oldRepo := db, repo
+	db, repo = nil, testRepo
+
+	return testRepo, func() { db, repo = oldDB, oldRepo }
+}
+
 func TestAddBugCommand(t *testing.T) {
 	_, testRepo, cleanup := setupTestCommand(t)
 	defer cleanup()
@@ -138,7 +152,7 @@ func TestAddBugCommand(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 			stdin := strings.NewReader(tt.input)
 
-			cmd := newAddBugCommand()
+			cmd := newAddBugCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetIn(stdin)
@@ -177,7 +191,7 @@ func TestAddFeatureCommand(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	stdin := strings.NewReader("Add dark mode\nImplement theme switching")
 
-	cmd := newAddFeatureCommand()
+	cmd := newAddFeatureCommand(NewApp())
 	cmd.SetOut(&stdout)
 	cmd.SetErr(&stderr)
 	cmd.SetIn(stdin)
@@ -222,11 +236,11 @@ func TestAddRegressionCommand(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	stdin := strings.NewReader("Login broken after update\n\nLogin functionality stopped working after updating to v2.1.0")
 
-	cmd := newAddRegressionCommand()
+	cmd := newAddRegressionCommand(NewApp())
 	cmd.SetOut(&stdout)
 	cmd.SetErr(&stderr)
 	cmd.SetIn(stdin)
-	cmd.SetArgs([]string{"--source", "v2.1.0"})
+	cmd.SetArgs([]string{"--source", "v2.1.0", "--ref", "AUTH-42"})
 
 	err := cmd.Execute()
 	if err != nil {
@@ -245,4 +259,423 @@ func TestAddRegressionCommand(t *testing.T) {
 	if task.Source != "v2.1.0" {
 		t.Errorf("Source = %q, want %q", task.Source, "v2.1.0")
 	}
+	if task.ExternalRef != "AUTH-42" {
+		t.Errorf("ExternalRef = %q, want %q", task.ExternalRef, "AUTH-42")
+	}
+}
+
+func TestAddValidationRules(t *testing.T) {
+	_, _, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	app := NewApp()
+	app.Config().NoTrailingPeriod = true
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "rule violation suggests --no-verify",
+			args:    []string{"bug"},
+			wantErr: "Use --no-verify",
+		},
+		{
+			name: "--no-verify skips the rules",
+			args: []string{"bug", "--no-verify"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newAddCommand(app)
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetIn(strings.NewReader("Fix the crash.\n\nThe app crashes on start"))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Execute() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddFromFile(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	content := "Fix crash on empty config\n\nThe app panics when the config file is empty.\nSee the stack trace in the logs."
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := newAddCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetIn(strings.NewReader("Ignored title\n\nStdin is not read"))
+	cmd.SetArgs([]string{"bug", "--from-file", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	tasks, err := testRepo.List(models.ListOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(tasks))
+	}
+	if tasks[0].Title != "Fix crash on empty config" {
+		t.Errorf("Title = %q", tasks[0].Title)
+	}
+	if tasks[0].Description != "The app panics when the config file is empty.\nSee the stack trace in the logs." {
+		t.Errorf("Description = %q", tasks[0].Description)
+	}
+
+	// A missing file is an error
+	cmd = newAddCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"bug", "-F", filepath.Join(t.TempDir(), "missing.md")})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Execute() with missing file should fail")
+	}
+}
+
+func TestAddSeverity(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	add := func(args ...string) error {
+		cmd := newAddCommand(NewApp())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader("Crash on start\n\nThe app crashes when the config is missing."))
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	if err := add("bug", "--severity", "Critical"); err != nil {
+		t.Fatalf("add bug --severity error = %v", err)
+	}
+	tasks, err := testRepo.List(models.ListOptions{All: true})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("List() = %v, %v", tasks, err)
+	}
+	if tasks[0].Severity != models.SeverityCritical {
+		t.Errorf("Severity = %q, want critical", tasks[0].Severity)
+	}
+
+	if err := add("bug", "--severity", "blocker"); err == nil {
+		t.Error("add bug accepted an invalid severity")
+	}
+	if err := add("feature", "--severity", "minor"); err == nil {
+		t.Error("add feature accepted --severity")
+	}
+}
+
+func TestAddRegressionOrigin(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	add := func(args ...string) error {
+		cmd := newAddCommand(NewApp())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader("Login broken\n\nLogin fails with valid credentials."))
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	if err := add("regression", "--introduced-in", "v2.1.0", "--environment", "prod"); err != nil {
+		t.Fatalf("add regression error = %v", err)
+	}
+	if err := add("regression", "--introduced-in", "v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := add("bug", "--introduced-in", "v2.1.0"); err == nil {
+		t.Error("add bug accepted --introduced-in")
+	}
+
+	tasks, err := testRepo.List(models.ListOptions{All: true, IntroducedIn: "v2.1.0"})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("List(IntroducedIn) = %v, %v", tasks, err)
+	}
+	if tasks[0].Environment != "prod" {
+		t.Errorf("Environment = %q, want prod", tasks[0].Environment)
+	}
+	if err := testRepo.UpdateState(tasks[0].ID, models.StateNew); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newListCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--kind", "regression", "--introduced-in", "v2.1.0"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "Introduced-in: v2.1.0") || !strings.Contains(out, "Environment: prod") {
+		t.Errorf("list output lacks the regression origin:\n%s", out)
+	}
+}
+
+func TestAddCreatedAt(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	tests := []struct {
+		name      string
+		createdAt string
+		wantErr   bool
+		want      time.Time
+	}{
+		{
+			name:      "absolute date",
+			createdAt: "2024-05-01 17:00",
+			want:      time.Date(2024, 5, 1, 17, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "future date",
+			createdAt: "+3d",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid date",
+			createdAt: "someday",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newAddCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetIn(strings.NewReader("Imported task\n\nCreated in another tracker"))
+			cmd.SetArgs([]string{"feature", "--created-at", tt.createdAt})
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			task, err := testRepo.GetByID(strings.Fields(stdout.String())[3])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !task.Created.Equal(tt.want) {
+				t.Errorf("Created = %v, want %v", task.Created, tt.want)
+			}
+			history, err := testRepo.GetHistory(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !history[0].Created.Equal(tt.want) {
+				t.Errorf("creation recorded at %v, want %v", history[0].Created, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatedAtOtherCommands(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	parent := models.NewTask(models.KindFeature, "Parent", "Has subtasks")
+	if err := testRepo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 5, 1, 17, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		newCmd func(*App) *cobra.Command
+		args   []string
+		input  string
+	}{
+		{
+			name:   "capture",
+			newCmd: newCaptureCommand,
+			args:   []string{"--created-at", "2024-05-01 17:00", "Imported capture: from another tracker"},
+		},
+		{
+			name:   "add-subtask",
+			newCmd: newAddSubtaskCommand,
+			args:   []string{parent.ID, "--kind", "bug", "--created-at", "2024-05-01 17:00"},
+			input:  "Imported subtask\n\nFrom another tracker",
+		},
+		{
+			name:   "add-bug",
+			newCmd: newAddBugCommand,
+			args:   []string{"--created-at", "2024-05-01 17:00"},
+			input:  "Imported bug\n\nFrom another tracker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := tt.newCmd(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			tasks, err := testRepo.List(models.ListOptions{All: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, task := range tasks {
+				if strings.HasPrefix(task.Title, "Imported "+strings.TrimPrefix(tt.name, "add-")) {
+					if !task.Created.Equal(want) {
+						t.Errorf("Created = %v, want %v", task.Created, want)
+					}
+					return
+				}
+			}
+			t.Error("task not created")
+		})
+	}
+}
+
+func TestAddDryRun(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	run := func(input string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newAddCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetArgs([]string{"bug", "--dry-run"})
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run("Fix memory leak\n\nMemory grows without bound")
+	if err != nil {
+		t.Fatalf("add --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "Would create bug task") || !strings.Contains(out, "Fix memory leak") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+	if _, err := run("Missing description"); err == nil {
+		t.Error("Expected a dry run to validate the task")
+	}
+
+	tasks, err := testRepo.List(models.ListOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("Expected no tasks to be created, got %d", len(tasks))
+	}
+}
+
+func TestAddAutoReview(t *testing.T) {
+	_, _, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	app := NewApp()
+
+	add := func(title string) string {
+		var stdout bytes.Buffer
+		cmd := newAddCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader(title + "\n\nDetails of " + title))
+		cmd.SetArgs([]string{"bug", "--priority", "high"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return stdout.String()
+	}
+
+	if got := add("Quiet add"); strings.Count(got, "\n") != 1 {
+		t.Errorf("Expected only the created line without auto review, got %q", got)
+	}
+
+	app.Config().AutoReview = true
+	got := add("Reviewed add")
+	for _, want := range []string{"Created bug task", "Reviewed add", "Details of Reviewed add", "high", "2 task(s) in INBOX"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestAddRegresses(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	feature := models.NewTask(models.KindFeature, "Login", "Users log in with a password.")
+	if err := testRepo.Create(feature); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newAddCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("Login broken\n\nLogin fails with valid credentials."))
+	cmd.SetArgs([]string{"regression", "--regresses", feature.ShortHash()})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("add regression --regresses error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "regresses "+feature.ShortHash()) {
+		t.Errorf("output lacks the link:\n%s", stdout.String())
+	}
+
+	links, err := testRepo.GetLinks(feature.ID)
+	if err != nil || len(links) != 1 {
+		t.Fatalf("GetLinks() = %v, %v", links, err)
+	}
+	if links[0].Type != models.LinkRegresses || links[0].TargetID != feature.ID {
+		t.Errorf("link = %+v, want a regresses link to the feature", links[0])
+	}
+
+	stdout.Reset()
+	show := newShowCommand(NewApp())
+	show.SetOut(&stdout)
+	show.SetArgs([]string{feature.ID})
+	if err := show.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "regressed by") {
+		t.Errorf("show lacks the back-reference:\n%s", stdout.String())
+	}
+
+	// An unknown task fails before the regression is created
+	cmd = newAddCommand(NewApp())
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("Search broken\n\nSearch returns nothing."))
+	cmd.SetArgs([]string{"regression", "--regresses", "ffffff"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("add regression accepted an unknown --regresses task")
+	}
+	tasks, err := testRepo.List(models.ListOptions{All: true, Kind: models.KindRegression})
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("List(regressions) = %v, %v, want only the first", tasks, err)
+	}
 }
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/config"
 	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/git"
 	"github.com/zw3rk/gtd/internal/models"
 	"github.com/zw3rk/gtd/internal/services"
@@ -14,8 +16,11 @@ import (
 type App struct {
 	config  *config.Config
 	db      *database.Database
-	repo    *models.TaskRepository
+	repo    models.Store
 	service services.TaskService
+
+	databasePath string // Set with --db, overrides the configured database
+	skipSchema   bool   // Leave the schema alone, for commands that manage it
 }
 
 // NewApp creates a new application instance
@@ -26,50 +31,126 @@ func NewApp() *App {
 }
 
 // Initialize sets up the application dependencies
-func (a *App) Initialize() error {
+func (a *App) Initialize() (err error) {
 	// Load configuration from environment
 	if err := a.config.Load(); err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if a.databasePath != "" {
+		a.config.DatabasePath = a.databasePath
+		a.config.DatabaseURL = ""
+	}
 
-	// Find git root
+	// Find git root. A database named with --db, GTD_DATABASE_PATH or
+	// GTD_DATABASE_URL can be used outside a repository, leaving GitRoot empty.
 	gitRoot, err := git.FindGitRoot(".")
-	if err != nil {
+	if err != nil && a.config.DatabasePath == "" && a.config.DatabaseURL == "" {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
 	a.config.GitRoot = gitRoot
 
-	// Open database
-	dbPath := a.config.GetDatabasePath()
-	a.db, err = database.New(dbPath)
+	// Display timestamps in the configured timezone; the database returns
+	// them in local time
+	if a.config.Timezone != "" {
+		loc, err := time.LoadLocation(a.config.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		time.Local = loc
+	}
+
+	// Open database: the shared Postgres database, or the SQLite file
+	if a.config.DatabaseURL != "" {
+		a.db, err = a.openPostgres()
+	} else {
+		a.db, err = a.openFile()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = a.Close()
+		}
+	}()
 
-	// Create schema if needed
-	if err := a.db.CreateSchema(); err != nil {
-		return fmt.Errorf("failed to create schema: %w", err)
+	// Create schema if needed, migrating older databases unless that's left
+	// to 'gtd migrate'
+	if !a.skipSchema {
+		if !a.config.AutoMigrate {
+			pending, err := a.db.PendingMigrations()
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("the database needs %d migrations, and GTD_AUTO_MIGRATE is off:\n%s\nRun 'gtd migrate' to apply them",
+					len(pending), formatMigrations(pending))
+			}
+		}
+		if err := a.db.CreateSchema(); err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
 	}
 
 	// Create repository
 	a.repo = models.NewTaskRepository(a.db)
+	a.repo.SetTagSettings(a.TagSettings())
 
 	// Create service
-	a.service = services.NewTaskService(a.repo)
+	a.service = services.NewTaskService(a.repo, a.ValidationRules())
 
 	return nil
 }
 
+// openPostgres connects to the Postgres database of GTD_DATABASE_URL
+func (a *App) openPostgres() (*database.Database, error) {
+	if a.config.EncryptionKey != "" || a.config.Keychain {
+		return nil, errors.NewValidationError("GTD_DB_KEY and GTD_DB_KEYCHAIN encrypt database files and can't be combined with GTD_DATABASE_URL")
+	}
+	return database.NewPostgres(a.config.DatabaseURL)
+}
+
+// openFile opens the SQLite database file, decrypting it when a key is
+// configured
+func (a *App) openFile() (*database.Database, error) {
+	dbPath := a.config.GetDatabasePath()
+	key := a.config.EncryptionKey
+	if key == "" && a.config.Keychain {
+		var err error
+		if key, err = database.KeychainKey(dbPath); err != nil {
+			return nil, err
+		}
+	}
+	if key != "" {
+		return database.NewEncrypted(dbPath, key)
+	}
+	return database.New(dbPath)
+}
+
 // Close cleans up application resources
 func (a *App) Close() error {
-	if a.db != nil {
-		return a.db.Close()
+	if a.db == nil {
+		return nil
 	}
-	return nil
+	err := a.db.Close()
+	a.db = nil
+	return err
+}
+
+// SetDatabasePath makes Initialize open the database file at path instead of
+// the one given by the environment or found at the git root
+func (a *App) SetDatabasePath(path string) {
+	a.databasePath = path
+}
+
+// SetSkipSchema makes Initialize open the database without creating or
+// migrating its schema
+func (a *App) SetSkipSchema(skip bool) {
+	a.skipSchema = skip
 }
 
 // Repository returns the task repository
-func (a *App) Repository() *models.TaskRepository {
+func (a *App) Repository() models.Store {
 	return a.repo
 }
 
@@ -81,4 +162,31 @@ func (a *App) Service() services.TaskService {
 // Config returns the application configuration
 func (a *App) Config() *config.Config {
 	return a.config
-}
\ No newline at end of file
+}
+
+// ValidationRules returns the configured title and description rules
+func (a *App) ValidationRules() models.ValidationRules {
+	return models.ValidationRules{
+		MaxTitleLength:       a.config.MaxTitleLength,
+		MinDescriptionLength: a.config.MinDescriptionLength,
+		NoTrailingPeriod:     a.config.NoTrailingPeriod,
+		ConventionalTitles:   a.config.ConventionalTitles,
+	}
+}
+// TagSettings returns the configured per-tag priorities and WIP limits
+func (a *App) TagSettings() models.TagSettings {
+	priorities := make(map[string]models.Priority, len(a.config.TagPriorities))
+	for tag, priority := range a.config.TagPriorities {
+		priorities[tag] = models.Priority(priority)
+	}
+	return models.TagSettings{WIPLimits: a.config.TagWIPLimits, Priorities: priorities}
+}
+
+// DatabaseFile returns the path of the SQLite database file, or "" for a
+// Postgres database
+func (a *App) DatabaseFile() string {
+	if a.config.DatabaseURL != "" {
+		return ""
+	}
+	return a.config.GetDatabasePath()
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/git"
+)
+
+// newAttachCommand creates the attach command
+func newAttachCommand(app *App) *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "attach TASK_ID PATH|URL",
+		Short: "Attach a file or URL to a task",
+		Long: `Associate a file path or URL with a task, such as a design document or a log.
+Files must exist and are stored relative to the repository root when they are
+inside it. Attachments are listed by show and included in JSON exports.`,
+		Example: `  gtd attach abc123 docs/design.md
+  gtd attach abc123 https://github.com/org/repo/issues/42
+  gtd attach abc123 docs/design.md --remove`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			location, err := normalizeAttachment(args[1], !remove)
+			if err != nil {
+				return err
+			}
+
+			if remove {
+				if err := repo.RemoveAttachment(task.ID, location); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from task %s\n", location, task.ShortHash())
+				return nil
+			}
+
+			if _, err := repo.AddAttachment(task.ID, location); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Attached %s to task %s\n", location, task.ShortHash())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove the attachment instead of adding it")
+
+	return cmd
+}
+
+// isURL reports whether an attachment location is a URL rather than a file path
+func isURL(location string) bool {
+	return strings.Contains(location, "://")
+}
+
+// normalizeAttachment returns the location to store for an attachment. URLs are
+// kept as given; file paths are made relative to the repository root when inside
+// it and absolute otherwise.
+func normalizeAttachment(location string, mustExist bool) (string, error) {
+	if isURL(location) {
+		return location, nil
+	}
+
+	if mustExist {
+		if _, err := os.Stat(location); err != nil {
+			return "", fmt.Errorf("cannot attach %s: %w", location, err)
+		}
+	}
+	return repoRelativePath(location)
+}
+
+// repoRelativePath returns path relative to the repository root when it is
+// inside the repository, and as an absolute path otherwise
+func repoRelativePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+
+	root, err := git.FindGitRoot(".")
+	if err != nil {
+		return absPath, nil
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return absPath, nil
+	}
+	return filepath.ToSlash(rel), nil
+}
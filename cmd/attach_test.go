@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestAttachCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Crash on start", "See the attached log")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	logFile := filepath.Join(t.TempDir(), "crash.log")
+	if err := os.WriteFile(logFile, []byte("panic: nil map"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		contains string
+	}{
+		{
+			name:     "attach file",
+			args:     []string{task.ID, logFile},
+			contains: "Attached " + logFile,
+		},
+		{
+			name:     "attach URL",
+			args:     []string{task.ID, "https://example.com/issues/42"},
+			contains: "Attached https://example.com/issues/42",
+		},
+		{
+			name:    "attach missing file",
+			args:    []string{task.ID, filepath.Join(t.TempDir(), "missing.log")},
+			wantErr: true,
+		},
+		{
+			name:    "attach twice",
+			args:    []string{task.ID, "https://example.com/issues/42"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown task",
+			args:    []string{"ffffffff", logFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newAttachCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.contains) {
+				t.Errorf("Output does not contain %q\nGot: %s", tt.contains, stdout.String())
+			}
+		})
+	}
+
+	// Attachments are listed by show
+	var stdout bytes.Buffer
+	cmd := newShowCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{task.ID})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Attachments:") || !strings.Contains(stdout.String(), logFile) {
+		t.Errorf("show output missing attachments\nGot: %s", stdout.String())
+	}
+
+	// ... and included in JSON exports
+	stdout.Reset()
+	cmd = newExportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var exported []struct {
+		Attachments []string `json:"attachments"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 1 || len(exported[0].Attachments) != 2 {
+		t.Errorf("Exported attachments = %+v, want 2", exported)
+	}
+
+	// Attachments can be removed again
+	stdout.Reset()
+	cmd = newAttachCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{task.ID, "https://example.com/issues/42", "--remove"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Removed https://example.com/issues/42") {
+		t.Errorf("Unexpected output: %s", stdout.String())
+	}
+}
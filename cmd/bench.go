@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// benchResult is the timing of one benchmarked operation
+type benchResult struct {
+	Operation string        `json:"operation"`
+	Runs      int           `json:"runs"`
+	Median    time.Duration `json:"median_ns"`
+	P90       time.Duration `json:"p90_ns"`
+}
+
+// benchOperation is an operation timed by gtd bench
+type benchOperation struct {
+	name string
+	run  func(repo models.Store) error
+}
+
+// benchOperations are the operations gtd bench times, in the order they are
+// shown
+var benchOperations = []benchOperation{
+	{"add", func(repo models.Store) error {
+		task := models.NewTask(models.KindFeature, "Benchmark task", "Created by gtd bench")
+		task.Tags = "bench"
+		return repo.Create(task)
+	}},
+	{"list", func(repo models.Store) error {
+		_, err := repo.List(models.ListOptions{})
+		return err
+	}},
+	{"list --state", func(repo models.Store) error {
+		_, err := repo.List(models.ListOptions{State: models.StateInProgress})
+		return err
+	}},
+	{"list --priority --kind", func(repo models.Store) error {
+		_, err := repo.List(models.ListOptions{Priority: models.PriorityHigh, Kind: models.KindBug})
+		return err
+	}},
+	{"list --tag", func(repo models.Store) error {
+		_, err := repo.List(models.ListOptions{Tag: "bench"})
+		return err
+	}},
+	{"search", func(repo models.Store) error {
+		_, err := repo.Search("task")
+		return err
+	}},
+	{"export", func(repo models.Store) error {
+		tasks, err := repo.List(models.ListOptions{All: true})
+		if err != nil {
+			return err
+		}
+		items, err := loadExportTasks(tasks)
+		if err != nil {
+			return err
+		}
+		return writeJSON(io.Discard, items)
+	}},
+}
+
+// newBenchCommand creates the bench command
+func newBenchCommand(app *App) *cobra.Command {
+	var (
+		runs     int
+		save     string
+		baseline string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Time common operations against the current database",
+		Long: `Time adding, listing with filters, searching, and exporting tasks against
+the current database and print the median and 90th percentile of each.
+
+The operations run on a temporary copy of the database, so the benchmark
+doesn't add tasks to it. Timings depend on how many tasks the database holds,
+which makes gtd bench useful to catch slow queries and missing indexes on
+real data.
+
+With --save, the results are written to a file as JSON. With --baseline, the
+results are compared with a file saved earlier, such as one from before an
+upgrade.`,
+		Example: `  gtd bench
+  gtd bench --runs 50 --save before.json
+  gtd bench --baseline before.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runs < 1 {
+				return errors.NewValidationError("invalid --runs: %d (must be at least 1)", runs)
+			}
+			if db.Postgres() {
+				return errors.NewValidationError("gtd bench times a copy of the database file, but GTD_DATABASE_URL points to a Postgres database")
+			}
+
+			var previous map[string]benchResult
+			if baseline != "" {
+				var err error
+				if previous, err = loadBenchBaseline(baseline); err != nil {
+					return err
+				}
+			}
+
+			results, err := runBench(runs)
+			if err != nil {
+				return err
+			}
+
+			if save != "" {
+				data, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(save, append(data, '\n'), 0644); err != nil {
+					return fmt.Errorf("failed to save results: %w", err)
+				}
+			}
+
+			formatBenchResults(cmd.OutOrStdout(), results, previous)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&runs, "runs", "n", 20, "Number of times to run each operation")
+	cmd.Flags().StringVar(&save, "save", "", "Write the results to a JSON file")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Compare with results saved with --save")
+
+	return cmd
+}
+
+// runBench times each operation on a copy of the database
+func runBench(runs int) (results []benchResult, err error) {
+	dir, err := os.MkdirTemp("", "gtd-bench-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "bench.db")
+	if _, err := db.DB.Exec("VACUUM INTO ?", path); err != nil {
+		return nil, fmt.Errorf("failed to copy database: %w", err)
+	}
+	copyDB, err := database.New(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := copyDB.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	// Export loads attachments and links through the global repository
+	saved := repo
+	repo = models.NewTaskRepository(copyDB)
+	defer func() { repo = saved }()
+
+	for _, op := range benchOperations {
+		timings := make([]time.Duration, runs)
+		for i := range timings {
+			start := time.Now()
+			if err := op.run(repo); err != nil {
+				return nil, fmt.Errorf("%s: %w", op.name, err)
+			}
+			timings[i] = time.Since(start)
+		}
+		sort.Slice(timings, func(i, j int) bool { return timings[i] < timings[j] })
+		results = append(results, benchResult{
+			Operation: op.name,
+			Runs:      runs,
+			Median:    timings[(runs-1)/2],
+			P90:       timings[(runs*9+9)/10-1],
+		})
+	}
+	return results, nil
+}
+
+// loadBenchBaseline reads results saved with --save, keyed by operation
+func loadBenchBaseline(path string) (map[string]benchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var results []benchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.NewValidationError("invalid baseline %s: %v", path, err)
+	}
+	byOperation := make(map[string]benchResult, len(results))
+	for _, result := range results {
+		byOperation[result.Operation] = result
+	}
+	return byOperation, nil
+}
+
+// formatBenchResults prints the results as a table, with the baseline median
+// and the change from it when there is a baseline
+func formatBenchResults(w io.Writer, results []benchResult, baseline map[string]benchResult) {
+	if baseline == nil {
+		_, _ = fmt.Fprintf(w, "%-24s %5s %10s %10s\n", "OPERATION", "RUNS", "MEDIAN", "P90")
+		for _, r := range results {
+			_, _ = fmt.Fprintf(w, "%-24s %5d %10s %10s\n", r.Operation, r.Runs, formatBenchDuration(r.Median), formatBenchDuration(r.P90))
+		}
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%-24s %5s %10s %10s %10s %8s\n", "OPERATION", "RUNS", "MEDIAN", "P90", "BASELINE", "CHANGE")
+	for _, r := range results {
+		before, change := "-", "-"
+		if previous, ok := baseline[r.Operation]; ok && previous.Median > 0 {
+			before = formatBenchDuration(previous.Median)
+			change = fmt.Sprintf("%+.0f%%", (float64(r.Median)/float64(previous.Median)-1)*100)
+		}
+		_, _ = fmt.Fprintf(w, "%-24s %5d %10s %10s %10s %8s\n",
+			r.Operation, r.Runs, formatBenchDuration(r.Median), formatBenchDuration(r.P90), before, change)
+	}
+}
+
+// formatBenchDuration rounds a timing to keep the table readable
+func formatBenchDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond).String()
+	}
+	return d.String()
+}
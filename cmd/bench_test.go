@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestBenchCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Existing task", "Description")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newBenchCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	saved := filepath.Join(t.TempDir(), "bench.json")
+	out, err := run("--runs", "3", "--save", saved)
+	if err != nil {
+		t.Fatalf("bench error = %v", err)
+	}
+	for _, op := range []string{"add", "list --tag", "search", "export"} {
+		if !strings.Contains(out, op) {
+			t.Errorf("Expected %q in the results: %s", op, out)
+		}
+	}
+	if strings.Contains(out, "BASELINE") {
+		t.Errorf("Expected no baseline column without --baseline: %s", out)
+	}
+
+	// The benchmark runs on a copy, so no tasks are added
+	tasks, err := testRepo.List(models.ListOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("Expected the database to be unchanged, got %d tasks", len(tasks))
+	}
+	if repo != testRepo {
+		t.Error("Expected the global repository to be restored")
+	}
+
+	out, err = run("--runs", "3", "--baseline", saved)
+	if err != nil {
+		t.Fatalf("bench --baseline error = %v", err)
+	}
+	if !strings.Contains(out, "BASELINE") || !strings.Contains(out, "%") {
+		t.Errorf("Expected a comparison with the baseline: %s", out)
+	}
+
+	if _, err := run("--runs", "0"); err == nil {
+		t.Error("Expected an error for --runs 0")
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newBlameCommand creates the blame command
+func newBlameCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "blame TASK_ID",
+		Short: "Show who changed a task and when",
+		Long: `Show the change history of a task, like git blame.
+Each line lists when a change was made, who made it, and what changed:
+creation, state transitions, blocking, and field edits.`,
+		Example: `  gtd blame abc123
+  gtd blame @current`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			history, err := repo.GetHistory(task.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get history: %w", err)
+			}
+
+			formatBlame(app, cmd.OutOrStdout(), task, history)
+			return nil
+		},
+	}
+}
+
+// formatBlame writes a task's history, one change per line
+func formatBlame(app *App, w io.Writer, task *models.Task, history []*models.HistoryEntry) {
+	// Tasks created before history was recorded get a synthetic creation entry
+	if len(history) == 0 || history[0].Action != models.ActionCreate {
+		created := &models.HistoryEntry{
+			TaskID:  task.ID,
+			Author:  task.Author,
+			Action:  models.ActionCreate,
+			Created: task.Created,
+		}
+		history = append([]*models.HistoryEntry{created}, history...)
+	}
+
+	header := task.ShortHash()
+	if seq := task.SeqRef(); seq != "" {
+		header += " " + seq
+	}
+	_, _ = fmt.Fprintf(w, "%s %s\n\n", colorize(header, colorYellow), task.Title)
+
+	timeWidth, authorWidth := 0, 0
+	times := make([]string, len(history))
+	for i, entry := range history {
+		times[i] = app.formatTimestamp(entry.Created)
+		if len(times[i]) > timeWidth {
+			timeWidth = len(times[i])
+		}
+		if len(entry.Author) > authorWidth {
+			authorWidth = len(entry.Author)
+		}
+	}
+
+	for i, entry := range history {
+		_, _ = fmt.Fprintf(w, "%-*s  %-*s  %s\n",
+			timeWidth, times[i],
+			authorWidth, entry.Author,
+			describeHistoryEntry(entry))
+	}
+}
+
+// describeHistoryEntry returns a short human-readable description of a change
+func describeHistoryEntry(entry *models.HistoryEntry) string {
+	switch entry.Action {
+	case models.ActionCreate:
+		if entry.NewValue == "" {
+			return "created"
+		}
+		return fmt.Sprintf("created in %s", entry.NewValue)
+	case models.ActionState:
+		return fmt.Sprintf("%s → %s", entry.OldValue, entry.NewValue)
+	case models.ActionBlock:
+		return fmt.Sprintf("blocked by %s", shortID(entry.NewValue))
+	case models.ActionUnblock:
+		return fmt.Sprintf("unblocked (was blocked by %s)", shortID(entry.OldValue))
+	case models.ActionAttach:
+		return fmt.Sprintf("attached %s", entry.NewValue)
+	case models.ActionDetach:
+		return fmt.Sprintf("removed attachment %s", entry.OldValue)
+	case models.ActionLink:
+		link := &models.TaskLink{SourceID: entry.TaskID, Type: entry.Field}
+		return fmt.Sprintf("linked: %s %s", link.Label(entry.TaskID), shortID(entry.NewValue))
+	case models.ActionUnlink:
+		link := &models.TaskLink{SourceID: entry.TaskID, Type: entry.Field}
+		return fmt.Sprintf("unlinked: %s %s", link.Label(entry.TaskID), shortID(entry.OldValue))
+	case models.ActionReason:
+		return fmt.Sprintf("reason: %s", entry.NewValue)
+	case models.ActionPin:
+		return "pinned"
+	case models.ActionUnpin:
+		return "unpinned"
+	case models.ActionMove:
+		if entry.NewValue == "" {
+			return "moved to the top"
+		}
+		return fmt.Sprintf("moved before %s", shortID(entry.NewValue))
+	case models.ActionEdit:
+		if entry.Field == "description" || strings.Contains(entry.OldValue+entry.NewValue, "\n") {
+			return fmt.Sprintf("changed %s", entry.Field)
+		}
+		if entry.Field == "parent" {
+			return fmt.Sprintf("changed parent: %s → %s", orNone(shortID(entry.OldValue)), orNone(shortID(entry.NewValue)))
+		}
+		return fmt.Sprintf("changed %s: %s → %s", entry.Field, orNone(entry.OldValue), orNone(entry.NewValue))
+	default:
+		return entry.Action
+	}
+}
+
+// shortID abbreviates a full task hash like Task.ShortHash
+func shortID(id string) string {
+	return models.ShortID(id)
+}
+
+// orNone returns "(none)" for empty values
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestBlameCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Blamed bug", "A bug with a history")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(task.ID, models.StateNew); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(task.ID, models.StateInProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newBlameCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{task.ID[:7]})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		task.ShortHash(),
+		"Blamed bug",
+		"created in INBOX",
+		"INBOX → NEW",
+		"NEW → IN_PROGRESS",
+		task.Author,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output does not contain %q\nGot: %s", want, output)
+		}
+	}
+
+	// Unknown tasks are reported
+	cmd = newBlameCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stdout)
+	cmd.SetArgs([]string{"ffffffff"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "task not found") {
+		t.Errorf("Execute() error = %v, want task not found", err)
+	}
+}
+
+func TestDescribeHistoryEntry(t *testing.T) {
+	blocker := "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		entry models.HistoryEntry
+		want  string
+	}{
+		{models.HistoryEntry{Action: models.ActionCreate}, "created"},
+		{models.HistoryEntry{Action: models.ActionState, OldValue: "NEW", NewValue: "DONE"}, "NEW → DONE"},
+		{models.HistoryEntry{Action: models.ActionBlock, NewValue: blocker}, "blocked by 0123456"},
+		{models.HistoryEntry{Action: models.ActionUnblock, OldValue: blocker}, "unblocked (was blocked by 0123456)"},
+		{models.HistoryEntry{Action: models.ActionEdit, Field: "tags", NewValue: "ui"}, "changed tags: (none) → ui"},
+		{models.HistoryEntry{Action: models.ActionEdit, Field: "description", OldValue: "a", NewValue: "b"}, "changed description"},
+	}
+
+	for _, tt := range tests {
+		if got := describeHistoryEntry(&tt.entry); got != tt.want {
+			t.Errorf("describeHistoryEntry(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 )
 
 // newBlockCommand creates the block command
-func newBlockCommand() *cobra.Command {
-	var blockingTaskID string
+func newBlockCommand(app *App) *cobra.Command {
+	var (
+		blockingTaskID string
+		dryRun         bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "block TASK_ID --by BLOCKING_TASK_ID",
@@ -24,13 +28,13 @@ This indicates that the task cannot proceed until the blocking task is completed
 
 			// Validate blocking task ID was provided
 			if blockingTaskID == "" {
-				return fmt.Errorf("blocking task ID is required (use --by flag)")
+				return errors.NewValidationError("blocking task ID is required (use --by flag)")
 			}
 
 			// Get both tasks to show info
 			task, err := repo.GetByID(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
 
 			blockingTask, err := repo.GetByID(blockingTaskID)
@@ -40,11 +44,18 @@ This indicates that the task cannot proceed until the blocking task is completed
 
 			// Validate not blocking by itself
 			if task.ID == blockingTask.ID {
-				return fmt.Errorf("cannot block a task by itself")
+				return errors.NewValidationError("cannot block a task by itself")
+			}
+
+			if dryRun {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(),
+					"Would block task %s by task %s\n  %s\n  blocked by: %s\n",
+					task.ShortHash(), blockingTask.ShortHash(), task.Title, blockingTask.Title)
+				return nil
 			}
 
 			// Block the task
-			if err := repo.Block(task.ID, blockingTask.ID); err != nil {
+			if err := newTaskService(app, cmd).BlockTask(task.ID, blockingTask.ID); err != nil {
 				return fmt.Errorf("failed to block task: %w", err)
 			}
 
@@ -62,12 +73,13 @@ This indicates that the task cannot proceed until the blocking task is completed
 	cmd.Flags().StringVar(&blockingTaskID, "by", "", "ID/hash of the task that is blocking this task")
 	// MarkFlagRequired panics on error, so we can safely ignore the return value
 	_ = cmd.MarkFlagRequired("by")
+	addDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 // newUnblockCommand creates the unblock command
-func newUnblockCommand() *cobra.Command {
+func newUnblockCommand(app *App) *cobra.Command {
 	return &cobra.Command{
 		Use:   "unblock TASK_ID",
 		Short: "Remove blocking status from a task",
@@ -82,14 +94,14 @@ func newUnblockCommand() *cobra.Command {
 			// Get the task to show info
 			task, err := repo.GetByID(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
 
 			// Check if it was blocked
 			wasBlocked := task.IsBlocked()
 
 			// Unblock the task
-			if err := repo.Unblock(task.ID); err != nil {
+			if err := newTaskService(app, cmd).UnblockTask(task.ID); err != nil {
 				return fmt.Errorf("failed to unblock task: %w", err)
 			}
 
@@ -93,7 +93,7 @@ func TestBlockCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newBlockCommand()
+			cmd := newBlockCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -197,7 +197,7 @@ func TestUnblockCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newUnblockCommand()
+			cmd := newUnblockCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newCaptureCommand creates the capture command
+func newCaptureCommand(app *App) *cobra.Command {
+	var (
+		kind      string
+		tags      string
+		createdAt string
+	)
+
+	cmd := &cobra.Command{
+		Use:   `capture "TITLE[: DESCRIPTION]"`,
+		Short: "Quickly capture a task into the inbox",
+		Long: `Capture a task in a single argument without reading stdin or prompting.
+Text after the first ": " becomes the description; without it the title is
+used as the description. Captured tasks land in INBOX as features unless
+--kind is given, and skip the configured validation rules so capturing never
+fails on style. Refine them later with review.`,
+		Example: `  gtd capture "Retry failed uploads: uploads over 1GB time out"
+  gtd capture "Look into flaky CI"
+  gtd capture --kind bug "Crash when config is empty"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			title, description := parseCapture(args[0])
+			if title == "" {
+				return errors.NewValidationError("title cannot be empty")
+			}
+
+			normalizedKind, err := models.ParseKind(kind)
+			if err != nil {
+				return err
+			}
+
+			task := models.NewTask(normalizedKind, title, description)
+			task.Tags = tags
+			if priority := app.TagSettings().Priority(tags); priority != "" {
+				task.Priority = priority
+			}
+			if err := applyCreatedAt(task, createdAt); err != nil {
+				return err
+			}
+
+			// Capture must not be interrupted by style rules
+			if err := newTaskServiceWithRules(app, cmd, models.ValidationRules{}).CreateTask(task); err != nil {
+				return fmt.Errorf("failed to capture task: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatTaskCreated(task.ID, normalizedKind))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&kind, "kind", "k", "feature", "Task kind (bug, feature, regression)")
+	cmd.Flags().StringVarP(&tags, "tags", "t", "", "Comma-separated tags")
+	addCreatedAtFlag(cmd, &createdAt)
+
+	return cmd
+}
+
+// parseCapture splits captured text into a title and description at the
+// first ": ". The title doubles as the description when none is given.
+func parseCapture(text string) (title, description string) {
+	title, description, _ = strings.Cut(strings.TrimSpace(text), ": ")
+	title = strings.TrimSpace(title)
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = title
+	}
+	return title, description
+}
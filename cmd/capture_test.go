@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestParseCapture(t *testing.T) {
+	tests := []struct {
+		text            string
+		wantTitle       string
+		wantDescription string
+	}{
+		{"Retry uploads: uploads over 1GB time out", "Retry uploads", "uploads over 1GB time out"},
+		{"Look into flaky CI", "Look into flaky CI", "Look into flaky CI"},
+		{"  Fix: the parser: again  ", "Fix", "the parser: again"},
+		{"Trailing colon:", "Trailing colon:", "Trailing colon:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			title, description := parseCapture(tt.text)
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("parseCapture(%q) = %q, %q; want %q, %q",
+					tt.text, title, description, tt.wantTitle, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestCaptureCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	// Rules that would reject the captured title are skipped
+	app := NewApp()
+	app.Config().ConventionalTitles = true
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		wantKind models.Kind
+	}{
+		{
+			name:     "default kind",
+			args:     []string{"Retry uploads: uploads over 1GB time out"},
+			wantKind: models.KindFeature,
+		},
+		{
+			name:     "explicit kind",
+			args:     []string{"--kind", "bug", "Crash when config is empty"},
+			wantKind: models.KindBug,
+		},
+		{
+			name:    "invalid kind",
+			args:    []string{"--kind", "chore", "Tidy up"},
+			wantErr: true,
+		},
+		{
+			name:    "empty title",
+			args:    []string{"  "},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newCaptureCommand(app)
+			cmd.SetOut(&stdout)
+			cmd.SetIn(strings.NewReader("stdin is never read"))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			id := strings.Fields(stdout.String())[3]
+			task, err := testRepo.GetByID(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if task.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", task.Kind, tt.wantKind)
+			}
+			if task.State != models.StateInbox {
+				t.Errorf("State = %q, want INBOX", task.State)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+// newCheckCommand creates the check command
+func newCheckCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check TASK_ID ITEM",
+		Short: "Check or uncheck a checklist item in a task's description",
+		Long: `Toggle a checklist item in a task's description without editing the
+whole description. Checklist items are Markdown task list lines such as
+"- [ ] Write docs" and "- [x] Add tests", numbered from 1 in the order they
+appear. Checking a checked item unchecks it.`,
+		Example: `  gtd check abc123 3`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errors.NewValidationError("invalid checklist item: %s (must be a number)", args[1])
+			}
+
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+			item, err := task.ToggleChecklistItem(n)
+			if err != nil {
+				return err
+			}
+			if err := repo.Update(task); err != nil {
+				return err
+			}
+
+			verb := "Checked"
+			if !item.Done {
+				verb = "Unchecked"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s item %d of task %s: %s (%s)\n",
+				verb, n, colorize(task.ShortHash(), colorYellow), item.Text, output.ChecklistSummary(task))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestCheckCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindFeature, "Release", "Steps:\n- [x] Update changelog\n- [ ] Tag the release\n- [ ] Build binaries")
+	task.State = models.StateNew
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newCheckCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run(task.ID, "2")
+	if err != nil {
+		t.Fatalf("check error = %v", err)
+	}
+	if !strings.Contains(out, "Checked item 2") || !strings.Contains(out, "Tag the release (2/3 checklist items)") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+	updated, err := testRepo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(updated.Description, "- [x] Tag the release") {
+		t.Errorf("Expected item to be checked in the description:\n%s", updated.Description)
+	}
+
+	if out, err := run(task.ID, "1"); err != nil || !strings.Contains(out, "Unchecked item 1") {
+		t.Errorf("Expected item 1 to be unchecked, got %q (%v)", out, err)
+	}
+
+	for _, view := range [][]string{{"list", "--oneline"}, {"show", task.ID}} {
+		var stdout bytes.Buffer
+		viewCmd := newListCommand(NewApp())
+		if view[0] == "show" {
+			viewCmd = newShowCommand(NewApp())
+		}
+		viewCmd.SetOut(&stdout)
+		viewCmd.SetArgs(view[1:])
+		if err := viewCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(stdout.String(), "1/3 checklist items") {
+			t.Errorf("Expected checklist progress in %s output:\n%s", view[0], stdout.String())
+		}
+	}
+
+	for _, args := range [][]string{{task.ID, "4"}, {task.ID, "two"}, {task.ID, "0"}} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("check %v error = nil, want error", args)
+		}
+	}
+}
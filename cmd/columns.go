@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+// taskColumn is a task field that can be selected with --columns
+type taskColumn struct {
+	names   []string // Accepted names, canonical name first
+	header  string   // CSV header
+	value   func(task *models.Task) string
+	display func(app *App, task *models.Task) string // Oneline rendering, value if nil
+}
+
+// taskColumns lists the selectable columns in their default CSV order
+var taskColumns = []taskColumn{
+	{
+		names:   []string{"id", "hash"},
+		header:  "ID",
+		value:   func(task *models.Task) string { return task.ID },
+		display: func(_ *App, task *models.Task) string { return colorize(task.ShortHash(), colorYellow) },
+	},
+	{
+		names:   []string{"type", "kind"},
+		header:  "Type",
+		value:   func(task *models.Task) string { return task.Kind.String() },
+		display: func(_ *App, task *models.Task) string { return strings.ToLower(task.Kind.String()) },
+	},
+	{
+		names:   []string{"state"},
+		header:  "State",
+		value:   func(task *models.Task) string { return task.State.String() },
+		display: func(app *App, task *models.Task) string { return app.taskColors().StateIcon(task.State) },
+	},
+	{
+		names:  []string{"priority"},
+		header: "Priority",
+		value:  func(task *models.Task) string { return task.Priority.String() },
+	},
+	{
+		names:  []string{"title"},
+		header: "Title",
+		value:  func(task *models.Task) string { return task.Title },
+	},
+	{
+		names:   []string{"tags"},
+		header:  "Tags",
+		value:   func(task *models.Task) string { return task.Tags },
+		display: func(app *App, task *models.Task) string { return app.taskColors().TagList(task.Tags) },
+	},
+	{
+		names:  []string{"source"},
+		header: "Source",
+		value:  func(task *models.Task) string { return task.Source },
+	},
+	{
+		names:   []string{"parent"},
+		header:  "Parent",
+		value:   func(task *models.Task) string { return derefOrEmpty(task.Parent) },
+		display: func(_ *App, task *models.Task) string { return shortID(derefOrEmpty(task.Parent)) },
+	},
+	{
+		names:   []string{"blocked_by", "blockedby", "blocked-by"},
+		header:  "BlockedBy",
+		value:   func(task *models.Task) string { return derefOrEmpty(task.BlockedBy) },
+		display: func(_ *App, task *models.Task) string { return shortID(derefOrEmpty(task.BlockedBy)) },
+	},
+	{
+		names:   []string{"created"},
+		header:  "Created",
+		value:   func(task *models.Task) string { return output.FormatExportTime(task.Created) },
+		display: func(app *App, task *models.Task) string { return app.formatTimestamp(task.Created) },
+	},
+	{
+		names:   []string{"updated"},
+		header:  "Updated",
+		value:   func(task *models.Task) string { return output.FormatExportTime(task.Updated) },
+		display: func(app *App, task *models.Task) string { return app.formatTimestamp(task.Updated) },
+	},
+	{
+		names:  []string{"seq", "number"},
+		header: "Seq",
+		value: func(task *models.Task) string {
+			if task.Seq == 0 {
+				return ""
+			}
+			return strconv.Itoa(task.Seq)
+		},
+		display: func(_ *App, task *models.Task) string { return task.SeqRef() },
+	},
+	{
+		names:  []string{"ref"},
+		header: "Ref",
+		value:  func(task *models.Task) string { return task.ExternalRef },
+	},
+	{
+		names:  []string{"author"},
+		header: "Author",
+		value:  func(task *models.Task) string { return task.Author },
+	},
+	{
+		names:   []string{"description"},
+		header:  "Description",
+		value:   func(task *models.Task) string { return task.Description },
+		display: func(_ *App, task *models.Task) string { return strings.Join(strings.Fields(task.Description), " ") },
+	},
+	{
+		names:  []string{"severity"},
+		header: "Severity",
+		value:  func(task *models.Task) string { return task.Severity.String() },
+	},
+	{
+		names:  []string{"introduced_in"},
+		header: "Introduced In",
+		value:  func(task *models.Task) string { return task.IntroducedIn },
+	},
+	{
+		names:  []string{"environment"},
+		header: "Environment",
+		value:  func(task *models.Task) string { return task.Environment },
+	},
+}
+
+// defaultCSVColumns are the columns exported to CSV without --columns
+var defaultCSVColumns = taskColumns[:11]
+
+// parseColumns parses a comma-separated --columns value
+func parseColumns(spec string) ([]taskColumn, error) {
+	var columns []taskColumn
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		column, ok := lookupColumn(name)
+		if !ok {
+			return nil, errors.NewValidationError("unknown column: %s (must be one of %s)", name, strings.Join(columnNames(), ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, errors.NewValidationError("no columns given")
+	}
+	return columns, nil
+}
+
+// lookupColumn finds a column by any of its names
+func lookupColumn(name string) (taskColumn, bool) {
+	for _, column := range taskColumns {
+		for _, candidate := range column.names {
+			if candidate == name {
+				return column, true
+			}
+		}
+	}
+	return taskColumn{}, false
+}
+
+// columnNames returns the canonical names of all columns
+func columnNames() []string {
+	names := make([]string, len(taskColumns))
+	for i, column := range taskColumns {
+		names[i] = column.names[0]
+	}
+	return names
+}
+
+// formatTaskColumns renders the selected columns of a task on one line,
+// separated by tabs
+func formatTaskColumns(app *App, task *models.Task, columns []taskColumn) string {
+	fields := make([]string, len(columns))
+	for i, column := range columns {
+		if column.display != nil {
+			fields[i] = column.display(app, task)
+		} else {
+			fields[i] = column.value(task)
+		}
+	}
+	return strings.Join(fields, "\t")
+}
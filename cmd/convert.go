@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newConvertCommand creates the convert command
+func newConvertCommand(app *App) *cobra.Command {
+	var (
+		to     string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "convert TASK_ID --to bug|feature|regression",
+		Short: "Change the kind of a task",
+		Long: `Change the kind of a misclassified task, e.g. a bug report that is really a
+feature request, keeping its hash, history, subtasks, and links.
+Fields that don't apply to the new kind are cleared: the severity when
+converting to a feature, and the release and environment a regression was
+seen in when converting a regression to anything else. The change is recorded
+in the task's history.`,
+		Example: `  gtd convert abc123 --to feature
+  gtd convert abc123 --to regression --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := models.ParseKind(to)
+			if err != nil {
+				return err
+			}
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+			if task.Kind == kind {
+				return errors.NewValidationError("task %s is already a %s", task.ShortHash(), strings.ToLower(kind.String()))
+			}
+
+			from := task.Kind
+			cleared := task.ConvertTo(kind)
+			if dryRun {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would convert task %s from %s to %s\n",
+					task.ShortHash(), strings.ToLower(from.String()), strings.ToLower(kind.String()))
+			} else {
+				if err := repo.Update(task); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Converted task %s from %s to %s\n",
+					colorize(task.ShortHash(), colorYellow), strings.ToLower(from.String()), strings.ToLower(kind.String()))
+			}
+			if len(cleared) > 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s\n", strings.Join(cleared, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Kind to convert the task to (bug, feature, regression) [required]")
+	// MarkFlagRequired panics on error, so we can safely ignore the return value
+	_ = cmd.MarkFlagRequired("to")
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
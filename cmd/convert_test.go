@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestConvertCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Dark mode", "Add a dark theme")
+	task.Severity = models.SeverityMinor
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	convert := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newConvertCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	if _, err := convert(task.ID, "--to", "bug"); err == nil {
+		t.Error("convert accepted the kind the task already has")
+	}
+	if _, err := convert(task.ID, "--to", "epic"); err == nil {
+		t.Error("convert accepted an invalid kind")
+	}
+
+	out, err := convert(task.ID, "--to", "feature", "--dry-run")
+	if err != nil {
+		t.Fatalf("convert --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "Would convert task") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+	if got, _ := testRepo.GetByID(task.ID); got.Kind != models.KindBug {
+		t.Errorf("dry run changed the kind to %s", got.Kind)
+	}
+
+	out, err = convert(task.ID, "--to", "feature")
+	if err != nil {
+		t.Fatalf("convert error = %v", err)
+	}
+	if !strings.Contains(out, "from bug to feature") || !strings.Contains(out, "Cleared severity") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+
+	got, err := testRepo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != models.KindFeature || got.Severity != "" {
+		t.Errorf("task = %s with severity %q, want a feature without severity", got.Kind, got.Severity)
+	}
+
+	history, err := testRepo.GetHistory(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded bool
+	for _, entry := range history {
+		if entry.Field == "kind" && entry.OldValue == "BUG" && entry.NewValue == "FEATURE" {
+			recorded = true
+		}
+	}
+	if !recorded {
+		t.Error("the kind change was not recorded in the history")
+	}
+}
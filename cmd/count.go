@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// newCountCommand creates the count command
+func newCountCommand(app *App) *cobra.Command {
+	var flags listFlags
+
+	cmd := &cobra.Command{
+		Use:   "count [filters]",
+		Short: "Print the number of matching tasks",
+		Long: `Print just the number of tasks 'gtd list' would show with the same filters,
+without its limit, for shell scripts and git hooks. Like list, DONE and
+CANCELLED tasks are only counted with --all or --state.`,
+		Example: `  gtd count --state IN_PROGRESS
+  gtd count --kind bug --priority high
+  [ "$(gtd count --tag release --include-children)" -eq 0 ] && echo "Ready to release"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := listOptions(&flags)
+			if err != nil {
+				return err
+			}
+			count, err := repo.Count(opts)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), count)
+			return nil
+		},
+	}
+
+	addListFilterFlags(cmd, &flags)
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestCountCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	for i, state := range []models.State{models.StateNew, models.StateNew, models.StateInProgress, models.StateDone} {
+		task := models.NewTask(models.KindBug, "Task", "Details")
+		task.State = state
+		if i == 0 {
+			task.Priority = models.PriorityHigh
+		}
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "3\n"},
+		{[]string{"--all"}, "4\n"},
+		{[]string{"--state", "NEW"}, "2\n"},
+		{[]string{"--priority", "high"}, "1\n"},
+		{[]string{"--kind", "feature"}, "0\n"},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		cmd := newCountCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(tt.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("count %v error = %v", tt.args, err)
+		}
+		if stdout.String() != tt.want {
+			t.Errorf("count %v = %q, want %q", tt.args, stdout.String(), tt.want)
+		}
+	}
+
+	cmd := newCountCommand(NewApp())
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--priority", "urgent"})
+	if err := cmd.Execute(); errors.CodeOf(err) != errors.CodeValidation {
+		t.Errorf("count with an invalid filter error = %v, want a validation error", err)
+	}
+}
+
+func TestExistsCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Flaky login test", "Details")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(id string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newExistsCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stdout)
+		cmd.SetArgs([]string{id})
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	if out, err := exists(task.ShortHash()); err != nil || out != "" {
+		t.Errorf("exists %s = %q, %v, want no output and no error", task.ShortHash(), out, err)
+	}
+	if out, err := exists("#1"); err != nil || out != "" {
+		t.Errorf("exists #1 = %q, %v, want no output and no error", out, err)
+	}
+	out, err := exists("fffffff")
+	if errors.ExitCode(err) != 2 || out != "" {
+		t.Errorf("exists of a missing task = %q, %v, want no output and exit status 2", out, err)
+	}
+
+	// Other commands accept a title word, but it doesn't name the task
+	for _, ref := range []string{"login", "login^", "@9"} {
+		if out, err := exists(ref); errors.ExitCode(err) != 2 || out != "" {
+			t.Errorf("exists %s = %q, %v, want no output and exit status 2", ref, out, err)
+		}
+	}
+
+	var report bytes.Buffer
+	reportError(&report, err)
+	if report.Len() != 0 {
+		t.Errorf("exit status reported as %q", report.String())
+	}
+}
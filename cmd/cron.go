@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+var (
+	// readCrontab returns the user's crontab, or "" when there is none;
+	// replaced in tests
+	readCrontab = func() (string, error) {
+		out, err := exec.Command("crontab", "-l").Output()
+		if exitErr, ok := err.(*exec.ExitError); ok && bytes.Contains(exitErr.Stderr, []byte("no crontab")) {
+			return "", nil
+		}
+		return string(out), err
+	}
+
+	// writeCrontab replaces the user's crontab; replaced in tests
+	writeCrontab = func(content string) error {
+		cmd := exec.Command("crontab", "-")
+		cmd.Stdin = strings.NewReader(content)
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+
+	// runSystemctl runs systemctl --user with args; replaced in tests
+	runSystemctl = func(args ...string) error {
+		cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+)
+
+// cronJob is a periodic job gtd cron can schedule
+type cronJob struct {
+	name        string
+	description string
+	cron        string // Crontab schedule
+	onCalendar  string // systemd timer schedule
+}
+
+// cronJobs lists the jobs gtd cron can schedule, in the order they run
+var cronJobs = []cronJob{
+	{"backup", "nightly backup", "30 2 * * *", "*-*-* 02:30:00"},
+	{"digest", "weekly digest", "0 8 * * 1", "Mon *-*-* 08:00:00"},
+	{"stale", "weekly stale task report", "0 9 * * 1", "Mon *-*-* 09:00:00"},
+}
+
+// cronOptions configures the commands of scheduled jobs
+type cronOptions struct {
+	root       string // git root the jobs run in
+	executable string
+	database   string
+	backupDir  string
+	digestTo   string
+	staleDays  int
+}
+
+// newCronCommand creates the cron command
+func newCronCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "Schedule periodic jobs with cron or systemd timers",
+		Long: `Schedule periodic jobs for the current project: a nightly backup, a weekly
+digest, and a weekly report of stale tasks. The entries are generated by gtd
+and managed as a block in your crontab, or as systemd user timers with
+--systemd; 'gtd cron remove' removes them again.`,
+		Example: `  gtd cron install
+  gtd cron install --jobs backup,digest --to team@example.com
+  gtd cron install --systemd
+  gtd cron remove`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newCronInstallCommand(app))
+	cmd.AddCommand(newCronRemoveCommand(app))
+
+	return cmd
+}
+
+// newCronInstallCommand creates the cron install command
+func newCronInstallCommand(app *App) *cobra.Command {
+	var (
+		jobNames string
+		systemd  bool
+		opts     cronOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the periodic jobs for this project",
+		Long: `Install periodic jobs for the current project, replacing any installed before:
+
+  backup  02:30 daily    export all tasks to a gzipped JSON file, kept
+                         next to the database unless --backup-dir is given;
+                         restore it with 'gtd import'
+  digest  08:00 Mondays  summarize the week; mailed with sendmail when --to
+                         or GTD_DIGEST_TO gives recipients
+  stale   09:00 Mondays  list NEW tasks untouched for --stale-days
+
+Jobs run in the git repository against its database. Cron mails the output
+of a job to you; systemd keeps it in the journal.`,
+		Example: `  gtd cron install
+  gtd cron install --jobs backup
+  gtd cron install --systemd --stale-days 30`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.root = app.Config().GitRoot
+			if err := requireGitRoot(opts.root); err != nil {
+				return err
+			}
+			databaseFile := app.DatabaseFile()
+			if databaseFile == "" {
+				return errors.NewValidationError("cron jobs run on a database file, but GTD_DATABASE_URL points to a Postgres database")
+			}
+			jobs, err := parseCronJobs(jobNames)
+			if err != nil {
+				return err
+			}
+			if opts.staleDays <= 0 {
+				return errors.NewValidationError("invalid --stale-days: %d (must be positive)", opts.staleDays)
+			}
+			if opts.digestTo == "" {
+				opts.digestTo = app.Config().DigestTo
+			}
+			if opts.executable, err = os.Executable(); err != nil {
+				return fmt.Errorf("failed to find the gtd binary: %w", err)
+			}
+			if opts.database, err = filepath.Abs(databaseFile); err != nil {
+				return err
+			}
+			if opts.backupDir == "" {
+				opts.backupDir = filepath.Dir(opts.database)
+			}
+			if opts.backupDir, err = filepath.Abs(opts.backupDir); err != nil {
+				return err
+			}
+			if slices.ContainsFunc(jobs, func(job cronJob) bool { return job.name == "backup" }) {
+				if err := os.MkdirAll(opts.backupDir, 0o755); err != nil {
+					return fmt.Errorf("failed to create backup directory: %w", err)
+				}
+			}
+
+			if systemd {
+				err = installSystemdTimers(jobs, opts)
+			} else {
+				err = installCrontab(jobs, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			where := "your crontab"
+			if systemd {
+				where = "systemd user timers"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Installed %s for %s as %s:\n", formatTaskCount(len(jobs), "job"), opts.root, where)
+			for _, job := range jobs {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %-7s %s\n", job.name, job.description)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&jobNames, "jobs", "backup,digest,stale", "Comma-separated jobs to install (backup, digest, stale)")
+	cmd.Flags().BoolVar(&systemd, "systemd", false, "Install systemd user timers instead of crontab entries")
+	cmd.Flags().StringVar(&opts.backupDir, "backup-dir", "", "Directory for the nightly backup (default: the database's directory)")
+	cmd.Flags().StringVar(&opts.digestTo, "to", "", "Comma-separated recipients to mail the digest to [default: GTD_DIGEST_TO]")
+	cmd.Flags().IntVar(&opts.staleDays, "stale-days", 14, "Report NEW tasks not updated for this many days")
+
+	return cmd
+}
+
+// newCronRemoveCommand creates the cron remove command
+func newCronRemoveCommand(app *App) *cobra.Command {
+	var systemd bool
+
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove the periodic jobs of this project",
+		Long: `Remove the periodic jobs 'gtd cron install' installed for the current project.
+Other entries in your crontab are left alone.`,
+		Example: `  gtd cron remove
+  gtd cron remove --systemd`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := app.Config().GitRoot
+			if err := requireGitRoot(root); err != nil {
+				return err
+			}
+			var removed bool
+			var err error
+			if systemd {
+				removed, err = removeSystemdTimers(root)
+			} else {
+				removed, err = removeCrontab(root)
+			}
+			if err != nil {
+				return err
+			}
+
+			if !removed {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No jobs installed for %s.\n", root)
+				return nil
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed the jobs for %s.\n", root)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&systemd, "systemd", false, "Remove systemd user timers instead of crontab entries")
+
+	return cmd
+}
+
+// parseCronJobs looks up comma-separated job names, keeping the order of
+// cronJobs
+func parseCronJobs(names string) ([]cronJob, error) {
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.ContainsFunc(cronJobs, func(job cronJob) bool { return job.name == name }) {
+			return nil, errors.NewValidationError("invalid job: %s (must be backup, digest, or stale)", name)
+		}
+		selected[name] = true
+	}
+
+	var jobs []cronJob
+	for _, job := range cronJobs {
+		if selected[job.name] {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// requireGitRoot refuses to manage jobs outside a git repository, as they
+// are installed per project
+func requireGitRoot(root string) error {
+	if root == "" {
+		return errors.NewValidationError("not in a git repository: cron jobs are installed per project")
+	}
+	return nil
+}
+
+// backupFile returns the path of the nightly backup of a database in dir
+func backupFile(dir, database string) string {
+	return filepath.Join(dir, filepath.Base(database)+".backup.json.gz")
+}
+
+// cronCommand returns the shell command a job runs, from the git root
+func cronCommand(job cronJob, opts cronOptions) string {
+	gtd := shellQuote(opts.executable) + " --db " + shellQuote(opts.database)
+	switch job.name {
+	case "backup":
+		return gtd + " export --format json --output " + shellQuote(backupFile(opts.backupDir, opts.database))
+	case "digest":
+		if opts.digestTo == "" {
+			return gtd + " digest --period week"
+		}
+		return gtd + " digest --period week --format email --to " + shellQuote(opts.digestTo) + " | sendmail -t"
+	default:
+		return fmt.Sprintf("%s escalate --dry-run --days %d", gtd, opts.staleDays)
+	}
+}
+
+// shellSafe matches words that need no quoting in a shell command
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// crontabMarkers returns the comment lines that delimit the jobs of the
+// project at root in a crontab
+func crontabMarkers(root string) (begin, end string) {
+	return "# BEGIN gtd cron " + root, "# END gtd cron " + root
+}
+
+// stripCrontabBlock removes the block of the project at root from a crontab,
+// reporting whether it had one
+func stripCrontabBlock(crontab, root string) (string, bool) {
+	begin, end := crontabMarkers(root)
+	var lines []string
+	inBlock, found := false, false
+	for _, line := range strings.Split(strings.TrimRight(crontab, "\n"), "\n") {
+		switch {
+		case line == begin:
+			inBlock, found = true, true
+		case line == end && inBlock:
+			inBlock = false
+		case !inBlock && (line != "" || len(lines) > 0):
+			lines = append(lines, line)
+		}
+	}
+	// Drop the blank line that separated the block
+	rest := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if rest == "" {
+		return "", found
+	}
+	return rest + "\n", found
+}
+
+// installCrontab writes the jobs to the user's crontab, replacing the block
+// of the current project
+func installCrontab(jobs []cronJob, opts cronOptions) error {
+	crontab, err := readCrontab()
+	if err != nil {
+		return fmt.Errorf("failed to read crontab: %w", err)
+	}
+	crontab, _ = stripCrontabBlock(crontab, opts.root)
+
+	begin, end := crontabMarkers(opts.root)
+	var sb strings.Builder
+	sb.WriteString(crontab)
+	if crontab != "" {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(begin + "\n")
+	sb.WriteString("# Generated by 'gtd cron install'; remove with 'gtd cron remove'\n")
+	for _, job := range jobs {
+		// cron reads an unescaped % as a newline
+		command := strings.ReplaceAll("cd "+shellQuote(opts.root)+" && "+cronCommand(job, opts), "%", `\%`)
+		fmt.Fprintf(&sb, "%s %s # gtd %s\n", job.cron, command, job.name)
+	}
+	sb.WriteString(end + "\n")
+
+	if err := writeCrontab(sb.String()); err != nil {
+		return fmt.Errorf("failed to write crontab: %w", err)
+	}
+	return nil
+}
+
+// removeCrontab removes the block of the project at root from the user's
+// crontab
+func removeCrontab(root string) (bool, error) {
+	crontab, err := readCrontab()
+	if err != nil {
+		return false, fmt.Errorf("failed to read crontab: %w", err)
+	}
+	crontab, found := stripCrontabBlock(crontab, root)
+	if !found {
+		return false, nil
+	}
+	if err := writeCrontab(crontab); err != nil {
+		return false, fmt.Errorf("failed to write crontab: %w", err)
+	}
+	return true, nil
+}
+
+// systemdUnitDir returns the directory of the user's systemd units
+func systemdUnitDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user"), nil
+}
+
+// systemdUnitName names the units of a job of the project at root, e.g.
+// "gtd-backup-1a2b3c4d"
+func systemdUnitName(root string, job cronJob) string {
+	sum := sha1.Sum([]byte(root))
+	return "gtd-" + job.name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// installSystemdTimers writes a service and a timer for each job and enables
+// the timers
+func installSystemdTimers(jobs []cronJob, opts cronOptions) error {
+	dir, err := systemdUnitDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var timers []string
+	for _, job := range jobs {
+		name := systemdUnitName(opts.root, job)
+		// systemd expands % specifiers, and ExecStart unquotes its arguments
+		command := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(cronCommand(job, opts))
+		service := fmt.Sprintf(`# Generated by 'gtd cron install'; remove with 'gtd cron remove --systemd'
+[Unit]
+Description=gtd %s for %s
+
+[Service]
+Type=oneshot
+WorkingDirectory=%s
+ExecStart=/bin/sh -c "%s"
+`, job.description, opts.root, opts.root, command)
+		timer := fmt.Sprintf(`# Generated by 'gtd cron install'; remove with 'gtd cron remove --systemd'
+[Unit]
+Description=gtd %s for %s
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, job.description, opts.root, job.onCalendar)
+
+		if err := os.WriteFile(filepath.Join(dir, name+".service"), []byte(service), 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".timer"), []byte(timer), 0o644); err != nil {
+			return err
+		}
+		timers = append(timers, name+".timer")
+	}
+
+	if err := runSystemctl("daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+	if err := runSystemctl(append([]string{"enable", "--now"}, timers...)...); err != nil {
+		return fmt.Errorf("failed to enable timers: %w", err)
+	}
+	return nil
+}
+
+// removeSystemdTimers disables and deletes the timers of the project at root
+func removeSystemdTimers(root string) (bool, error) {
+	dir, err := systemdUnitDir()
+	if err != nil {
+		return false, err
+	}
+
+	var timers, files []string
+	for _, job := range cronJobs {
+		name := systemdUnitName(root, job)
+		timer := filepath.Join(dir, name+".timer")
+		if _, err := os.Stat(timer); err != nil {
+			continue
+		}
+		timers = append(timers, name+".timer")
+		files = append(files, timer, filepath.Join(dir, name+".service"))
+	}
+	if len(timers) == 0 {
+		return false, nil
+	}
+
+	if err := runSystemctl(append([]string{"disable", "--now"}, timers...)...); err != nil {
+		return false, fmt.Errorf("failed to disable timers: %w", err)
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	if err := runSystemctl("daemon-reload"); err != nil {
+		return false, fmt.Errorf("failed to reload systemd: %w", err)
+	}
+	return true, nil
+}
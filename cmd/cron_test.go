@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupCronTest points gtd cron at a project in a temporary directory and a
+// crontab held in memory
+func setupCronTest(t *testing.T, crontab string) (*App, *string) {
+	t.Helper()
+	app := NewApp()
+	app.Config().GitRoot = filepath.Join(t.TempDir(), "my project")
+	app.Config().DatabasePath = filepath.Join(app.Config().GitRoot, "claude-tasks.db")
+
+	oldRead, oldWrite, oldSystemctl := readCrontab, writeCrontab, runSystemctl
+	t.Cleanup(func() {
+		readCrontab, writeCrontab, runSystemctl = oldRead, oldWrite, oldSystemctl
+	})
+
+	readCrontab = func() (string, error) { return crontab, nil }
+	writeCrontab = func(content string) error {
+		crontab = content
+		return nil
+	}
+	return app, &crontab
+}
+
+func runCron(t *testing.T, app *App, args ...string) (string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	cmd := newCronCommand(app)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestCronInstallCrontab(t *testing.T) {
+	app, crontab := setupCronTest(t, "MAILTO=me@example.com\n0 * * * * other-job\n")
+	gitRoot, databaseFile := app.Config().GitRoot, app.Config().DatabasePath
+
+	out, err := runCron(t, app, "install", "--to", "team@example.com", "--stale-days", "30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Installed 3 jobs") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	for _, want := range []string{
+		"MAILTO=me@example.com\n0 * * * * other-job\n\n# BEGIN gtd cron " + gitRoot + "\n",
+		"30 2 * * * cd '" + gitRoot + "' && ",
+		" --db '" + databaseFile + "' export --format json --output '" + databaseFile + ".backup.json.gz' # gtd backup\n",
+		"0 8 * * 1 cd ",
+		"digest --period week --format email --to team@example.com | sendmail -t # gtd digest\n",
+		"escalate --dry-run --days 30 # gtd stale\n",
+		"# END gtd cron " + gitRoot + "\n",
+	} {
+		if !strings.Contains(*crontab, want) {
+			t.Errorf("crontab does not contain %q\nGot:\n%s", want, *crontab)
+		}
+	}
+	if _, err := os.Stat(gitRoot); err != nil {
+		t.Errorf("backup directory not created: %v", err)
+	}
+
+	// Installing again replaces the block
+	if _, err := runCron(t, app, "install", "--jobs", "backup"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(*crontab, "# BEGIN gtd cron") != 1 || strings.Contains(*crontab, "# gtd digest") {
+		t.Errorf("block not replaced:\n%s", *crontab)
+	}
+
+	out, err = runCron(t, app, "remove")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Removed the jobs") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if *crontab != "MAILTO=me@example.com\n0 * * * * other-job\n" {
+		t.Errorf("other entries not kept:\n%q", *crontab)
+	}
+
+	out, err = runCron(t, app, "remove")
+	if err != nil || !strings.Contains(out, "No jobs installed") {
+		t.Errorf("remove without jobs = %q, %v", out, err)
+	}
+}
+
+func TestCronInstallSystemd(t *testing.T) {
+	app, _ := setupCronTest(t, "")
+	gitRoot := app.Config().GitRoot
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var calls []string
+	runSystemctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+
+	if _, err := runCron(t, app, "install", "--systemd", "--jobs", "stale,backup"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, _ := systemdUnitDir()
+	backup := systemdUnitName(gitRoot, cronJobs[0])
+	service, err := os.ReadFile(filepath.Join(dir, backup+".service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(service), "WorkingDirectory="+gitRoot+"\n") || !strings.Contains(string(service), `ExecStart=/bin/sh -c "`) {
+		t.Errorf("unexpected service:\n%s", service)
+	}
+	timer, err := os.ReadFile(filepath.Join(dir, backup+".timer"))
+	if err != nil || !strings.Contains(string(timer), "OnCalendar=*-*-* 02:30:00\n") {
+		t.Errorf("unexpected timer:\n%s (%v)", timer, err)
+	}
+	stale := systemdUnitName(gitRoot, cronJobs[2])
+	if len(calls) != 2 || calls[1] != "enable --now "+backup+".timer "+stale+".timer" {
+		t.Errorf("systemctl calls = %q", calls)
+	}
+
+	calls = nil
+	if _, err := runCron(t, app, "remove", "--systemd"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "disable --now "+backup+".timer "+stale+".timer" {
+		t.Errorf("systemctl calls = %q", calls)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("units left behind: %v", entries)
+	}
+}
+
+func TestCronInstallValidation(t *testing.T) {
+	app, crontab := setupCronTest(t, "")
+
+	if _, err := runCron(t, app, "install", "--jobs", "backup,vacuum"); err == nil || !strings.Contains(err.Error(), "invalid job: vacuum") {
+		t.Errorf("expected invalid job error, got %v", err)
+	}
+	if _, err := runCron(t, app, "install", "--stale-days", "0"); err == nil {
+		t.Error("expected an error for --stale-days 0")
+	}
+	if *crontab != "" {
+		t.Errorf("crontab written on error:\n%s", *crontab)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newDedupeCommand creates the dedupe command
+func newDedupeCommand(app *App) *cobra.Command {
+	var (
+		threshold float64
+		merge     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find and merge near-duplicate open tasks",
+		Long: `Scan open tasks (INBOX, NEW, IN_PROGRESS) for near-duplicate titles and
+descriptions and show them in clusters.
+
+When run in a terminal, or with --merge, each cluster asks which task to
+keep. The others are merged into it: their tags are combined, their
+subtasks and the tasks they block move to the kept task, and they are
+closed (INBOX tasks become INVALID, others CANCELLED).`,
+		Example: `  gtd dedupe
+  gtd dedupe --threshold 0.8
+  gtd dedupe --merge`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if threshold <= 0 || threshold > 1 {
+				return errors.NewValidationError("threshold must be between 0 and 1, got %v", threshold)
+			}
+
+			tasks, err := repo.List(models.ListOptions{All: true})
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+			var open []*models.Task
+			for _, task := range tasks {
+				if task.State != models.StateInvalid {
+					open = append(open, task)
+				}
+			}
+
+			clusters := models.FindDuplicates(open, threshold)
+			if len(clusters) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No duplicates found")
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			var in *bufio.Reader
+			if merge || isInteractive() {
+				in = bufio.NewReader(cmd.InOrStdin())
+			}
+
+			merged := 0
+			for i, cluster := range clusters {
+				_, _ = fmt.Fprintf(out, "Cluster %d (%d tasks):\n", i+1, len(cluster))
+				for j, task := range cluster {
+					_, _ = fmt.Fprintf(out, "  %d) %s\n", j+1, app.formatTaskOneline(task))
+				}
+
+				if in != nil {
+					keep, err := promptKeepChoice(in, out, len(cluster))
+					if err != nil {
+						return err
+					}
+					if keep >= 0 {
+						for j, task := range cluster {
+							if j == keep {
+								continue
+							}
+							if err := repo.MergeInto(cluster[keep].ID, task.ID); err != nil {
+								return fmt.Errorf("failed to merge %s: %w", task.ShortHash(), err)
+							}
+							merged++
+						}
+					}
+				}
+				_, _ = fmt.Fprintln(out)
+			}
+
+			if in != nil {
+				_, _ = fmt.Fprintf(out, "Merged %d duplicate task(s)\n", merged)
+			} else {
+				_, _ = fmt.Fprintf(out, "Found %d cluster(s); run with --merge to merge them\n", len(clusters))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&threshold, "threshold", models.DefaultDuplicateThreshold, "Similarity (0-1) at which tasks count as duplicates")
+	cmd.Flags().BoolVar(&merge, "merge", false, "Ask which task to keep in each cluster and merge the rest into it")
+
+	return cmd
+}
+
+// promptKeepChoice asks which task of a cluster to keep and returns its
+// 0-based index, or -1 to leave the cluster alone
+func promptKeepChoice(in *bufio.Reader, out io.Writer, size int) (int, error) {
+	_, _ = fmt.Fprintf(out, "Keep which task? [1-%d, Enter to skip]: ", size)
+
+	line, err := in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		if err != nil && err != io.EOF {
+			return -1, fmt.Errorf("failed to read selection: %w", err)
+		}
+		return -1, nil
+	}
+
+	choice, err := strconv.Atoi(line)
+	if err != nil || choice < 1 || choice > size {
+		return -1, errors.NewValidationError("invalid selection %q", line)
+	}
+	return choice - 1, nil
+}
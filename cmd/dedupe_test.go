@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestDedupeCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	first := models.NewTask(models.KindBug, "Login fails on Safari", "Users cannot log in")
+	second := models.NewTask(models.KindBug, "Safari login fails", "Users cannot log in")
+	other := models.NewTask(models.KindFeature, "Add dark mode", "Theme toggle in settings")
+	for _, task := range []*models.Task{first, second, other} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		wantErr  bool
+		contains []string
+	}{
+		{
+			name:     "list clusters",
+			args:     []string{},
+			contains: []string{"Cluster 1 (2 tasks):", "Login fails on Safari", "Safari login fails", "run with --merge"},
+		},
+		{
+			name:     "strict threshold",
+			args:     []string{"--threshold", "1"},
+			contains: []string{"No duplicates found"},
+		},
+		{
+			name:    "invalid threshold",
+			args:    []string{"--threshold", "2"},
+			wantErr: true,
+		},
+		{
+			name:     "skip merge",
+			args:     []string{"--merge"},
+			input:    "\n",
+			contains: []string{"Keep which task?", "Merged 0 duplicate task(s)"},
+		},
+		{
+			name:    "invalid selection",
+			args:    []string{"--merge"},
+			input:   "5\n",
+			wantErr: true,
+		},
+		{
+			name:     "merge cluster",
+			args:     []string{"--merge"},
+			input:    "1\n",
+			contains: []string{"Merged 1 duplicate task(s)"},
+		},
+		{
+			name:     "nothing left after merge",
+			args:     []string{},
+			contains: []string{"No duplicates found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newDedupeCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stdout)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			output := stdout.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("Output does not contain %q\nGot: %s", want, output)
+				}
+			}
+		})
+	}
+}
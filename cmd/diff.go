@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// fieldChange is a single field that differs between two snapshots of a task
+type fieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// taskChange lists the field changes of a task present in both snapshots
+type taskChange struct {
+	Task   exportTask
+	Fields []fieldChange
+}
+
+// snapshotDiff is the difference between two task databases
+type snapshotDiff struct {
+	Added   []exportTask
+	Removed []exportTask
+	Changed []taskChange
+}
+
+// newDiffCommand creates the diff command
+func newDiffCommand(app *App) *cobra.Command {
+	var (
+		sinceBackup bool
+		backupDir   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff [OTHER_DB]",
+		Short: "Compare tasks with another database",
+		Long: `Compare the current task database with another one, such as a copy
+taken before an agent session. Reports tasks that were added, removed,
+and changed, with field-level changes. OTHER_DB is treated as the older
+snapshot; it is only read, never migrated, and may also be a JSON export.
+
+With --since-backup, the tasks are compared with the nightly backup made by
+'gtd cron', kept next to the database unless --backup-dir is given.`,
+		Example: `  cp claude-tasks.db before.db
+  # ... work ...
+  gtd diff before.db
+  gtd diff --since-backup`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path string
+			databaseFile := app.DatabaseFile()
+			switch {
+			case sinceBackup && len(args) > 0:
+				return errors.NewValidationError("OTHER_DB cannot be combined with --since-backup")
+			case sinceBackup && databaseFile == "":
+				return errors.NewValidationError("--since-backup compares with the backup of a database file, but GTD_DATABASE_URL points to a Postgres database")
+			case sinceBackup:
+				dir := backupDir
+				if dir == "" {
+					dir = filepath.Dir(databaseFile)
+				}
+				path = backupFile(dir, databaseFile)
+			case backupDir != "":
+				return errors.NewValidationError("--backup-dir requires --since-backup")
+			case len(args) == 0:
+				return errors.NewValidationError("expected OTHER_DB or --since-backup")
+			default:
+				path = args[0]
+			}
+
+			before, err := loadSnapshot(path)
+			if err != nil {
+				return err
+			}
+
+			tasks, err := repo.List(allTasksOptions())
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			formatDiff(cmd.OutOrStdout(), diffSnapshots(before, toExportTasks(tasks)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&sinceBackup, "since-backup", false, "Compare with the nightly backup of the database")
+	cmd.Flags().StringVar(&backupDir, "backup-dir", "", "Directory of the nightly backup (default: the database's directory)")
+
+	return cmd
+}
+
+// allTasksOptions returns list options that match every task regardless of state
+func allTasksOptions() models.ListOptions {
+	return models.ListOptions{All: true, ShowDone: true, ShowCancelled: true}
+}
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// loadSnapshot reads all tasks from another database file, or from a JSON
+// export such as a backup. Databases are opened read-only.
+func loadSnapshot(path string) ([]exportTask, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if string(header[:n]) != sqliteHeader {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		items, err := readImportTasks(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return items, nil
+	}
+
+	other, err := database.OpenReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = other.Close()
+	}()
+
+	tasks, err := models.NewTaskRepository(other).List(allTasksOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks in %s: %w", path, err)
+	}
+	return toExportTasks(tasks), nil
+}
+
+// diffSnapshots compares two snapshots, reporting what changed from before to after
+func diffSnapshots(before, after []exportTask) snapshotDiff {
+	var diff snapshotDiff
+
+	old := make(map[string]exportTask, len(before))
+	for _, task := range before {
+		old[task.ID] = task
+	}
+
+	seen := make(map[string]bool, len(after))
+	for _, task := range after {
+		seen[task.ID] = true
+		prev, ok := old[task.ID]
+		if !ok {
+			diff.Added = append(diff.Added, task)
+			continue
+		}
+		if fields := diffTaskFields(prev, task); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, taskChange{Task: task, Fields: fields})
+		}
+	}
+
+	for _, task := range before {
+		if !seen[task.ID] {
+			diff.Removed = append(diff.Removed, task)
+		}
+	}
+
+	return diff
+}
+
+// diffTaskFields lists the fields that differ between two versions of a task.
+// Timestamps are left out since any change bumps updated_at.
+func diffTaskFields(before, after exportTask) []fieldChange {
+	pairs := []fieldChange{
+		{"kind", before.Kind, after.Kind},
+		{"state", before.State, after.State},
+		{"priority", before.Priority, after.Priority},
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"tags", before.Tags, after.Tags},
+		{"source", before.Source, after.Source},
+		{"external_ref", before.ExternalRef, after.ExternalRef},
+		{"severity", before.Severity, after.Severity},
+		{"introduced_in", before.IntroducedIn, after.IntroducedIn},
+		{"environment", before.Environment, after.Environment},
+		{"parent", derefOrEmpty(before.Parent), derefOrEmpty(after.Parent)},
+		{"blocked_by", derefOrEmpty(before.BlockedBy), derefOrEmpty(after.BlockedBy)},
+	}
+
+	var changes []fieldChange
+	for _, pair := range pairs {
+		if pair.Old != pair.New {
+			changes = append(changes, pair)
+		}
+	}
+	return changes
+}
+
+// derefOrEmpty returns the string a pointer refers to, or "" for nil
+func derefOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// describeFieldChange renders a field change as "field: old → new"
+func describeFieldChange(change fieldChange) string {
+	switch change.Field {
+	case "description":
+		// Descriptions are multi-line; only note that they changed
+		return "description changed"
+	case "parent", "blocked_by":
+		return fmt.Sprintf("%s: %s → %s", change.Field, orNone(shortID(change.Old)), orNone(shortID(change.New)))
+	}
+	return fmt.Sprintf("%s: %s → %s", change.Field, orNone(change.Old), orNone(change.New))
+}
+
+// formatDiff writes a snapshot diff in a git-like format
+func formatDiff(w io.Writer, diff snapshotDiff) {
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		_, _ = fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	if len(diff.Added) > 0 {
+		_, _ = fmt.Fprintf(w, "Added (%d):\n", len(diff.Added))
+		for _, task := range diff.Added {
+			_, _ = fmt.Fprintf(w, "  %s %s %s: %s\n", colorize("+", colorGreen), colorize(shortID(task.ID), colorYellow), task.Kind, task.Title)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(diff.Removed) > 0 {
+		_, _ = fmt.Fprintf(w, "Removed (%d):\n", len(diff.Removed))
+		for _, task := range diff.Removed {
+			_, _ = fmt.Fprintf(w, "  %s %s %s: %s\n", colorize("-", colorRed), colorize(shortID(task.ID), colorYellow), task.Kind, task.Title)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(diff.Changed) > 0 {
+		_, _ = fmt.Fprintf(w, "Changed (%d):\n", len(diff.Changed))
+		for _, change := range diff.Changed {
+			_, _ = fmt.Fprintf(w, "  %s %s %s: %s\n", colorize("~", colorBlue), colorize(shortID(change.Task.ID), colorYellow), change.Task.Kind, change.Task.Title)
+			for _, field := range change.Fields {
+				_, _ = fmt.Fprintf(w, "      %s\n", describeFieldChange(field))
+			}
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestDiffCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	// Build an older snapshot in a separate database
+	snapshotPath := filepath.Join(t.TempDir(), "before.db")
+	snapshotDB, err := database.New(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotDB.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	snapshotRepo := models.NewTaskRepository(snapshotDB)
+
+	shared := models.NewTask(models.KindBug, "Shared bug", "Present in both databases")
+	removed := models.NewTask(models.KindFeature, "Removed feature", "Only in the snapshot")
+	for _, task := range []*models.Task{shared, removed} {
+		if err := snapshotRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := snapshotDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The current database keeps the shared task, changes it, and adds one
+	if err := testRepo.Create(shared); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(shared.ID, models.StateNew); err != nil {
+		t.Fatal(err)
+	}
+	added := models.NewTask(models.KindRegression, "Added regression", "Only in the current database")
+	if err := testRepo.Create(added); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newDiffCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{snapshotPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"Added (1):",
+		"Added regression",
+		"Removed (1):",
+		"Removed feature",
+		"Changed (1):",
+		"Shared bug",
+		"state: INBOX → NEW",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output does not contain %q\nGot: %s", want, output)
+		}
+	}
+
+	// Identical snapshots report nothing
+	tasks, err := testRepo.List(allTasksOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	formatDiff(&stdout, diffSnapshots(toExportTasks(tasks), toExportTasks(tasks)))
+	if !strings.Contains(stdout.String(), "No differences") {
+		t.Errorf("Expected no differences, got: %s", stdout.String())
+	}
+
+	// Missing files are reported instead of silently created
+	cmd = newDiffCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stdout)
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.db")})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected error for missing database file")
+	}
+}
+
+func TestDiffSnapshotReadOnly(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	snapshotPath := filepath.Join(t.TempDir(), "before.db")
+	snapshotDB, err := database.New(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotDB.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.NewTaskRepository(snapshotDB).Create(models.NewTask(models.KindBug, "Old bug", "Only in the snapshot")); err != nil {
+		t.Fatal(err)
+	}
+	// Pretend the snapshot predates the last migration
+	if _, err := snapshotDB.DB.Exec("PRAGMA user_version = 12"); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	diff := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newDiffCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	// Older schemas are refused rather than migrated
+	if _, err := diff(snapshotPath); err == nil || !strings.Contains(err.Error(), "pending migrations") {
+		t.Errorf("Expected the older schema to be refused, got %v", err)
+	}
+	after, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("Expected the snapshot file to be left unchanged")
+	}
+
+	// The nightly backup is found next to the database
+	databaseFile := filepath.Join(t.TempDir(), "claude-tasks.db")
+	app.Config().DatabasePath = databaseFile
+
+	task := models.NewTask(models.KindFeature, "Backed up feature", "In the backup and the database")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(backupFile(filepath.Dir(databaseFile), databaseFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	if err := exportJSON(gzipWriter, []*models.Task{task}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	task.Priority = models.PriorityHigh
+	if err := testRepo.Update(task); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := diff("--since-backup")
+	if err != nil {
+		t.Fatalf("diff --since-backup error = %v", err)
+	}
+	if !strings.Contains(out, "Backed up feature") || !strings.Contains(out, "priority: medium → high") {
+		t.Errorf("Expected the change since the backup, got:\n%s", out)
+	}
+	if _, err := diff("--since-backup", snapshotPath); err == nil {
+		t.Error("Expected OTHER_DB and --since-backup to be rejected together")
+	}
+}
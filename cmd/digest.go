@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/config"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// defaultDigestSubject is the subject of email digests without
+// GTD_DIGEST_SUBJECT
+const defaultDigestSubject = "{{.Title}}"
+
+// defaultDigestHTML renders the HTML part of email digests without
+// GTD_DIGEST_TEMPLATE
+const defaultDigestHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h1 style="font-size: 1.4em;">{{.Title}}</h1>
+{{range .Sections}}
+<h2 style="font-size: 1.1em;">{{.Name}} ({{len .Tasks}})</h2>
+{{if .Tasks}}<ul>
+{{range .Tasks}}<li>{{.Title}} <span style="color: #888;">{{.ShortHash}} · {{.Kind}} · {{.Priority}}</span></li>
+{{end}}</ul>
+{{else}}<p style="color: #888;">None</p>
+{{end}}{{end}}
+</body>
+</html>
+`
+
+// digestPeriods maps --period values to their length and name
+var digestPeriods = map[string]struct {
+	days int
+	name string
+}{
+	"day":   {1, "Daily"},
+	"week":  {7, "Weekly"},
+	"month": {30, "Monthly"},
+}
+
+// digest summarizes a period's work for gtd digest
+type digest struct {
+	Name       string // Daily, Weekly, or Monthly
+	Start, End time.Time
+	Completed  []*models.Task // Marked done during the period
+	Created    []*models.Task // Added during the period
+	Overdue    []*models.Task // Open and not updated during the period
+	Blocked    []*models.Task // Open and blocked by another task
+}
+
+// digestSection is one titled list of tasks in a digest
+type digestSection struct {
+	Name  string
+	Tasks []*models.Task
+}
+
+// Title describes the digest and its period, e.g. "Weekly digest: 2024-05-01
+// to 2024-05-08"
+func (d *digest) Title() string {
+	return fmt.Sprintf("%s digest: %s to %s", d.Name, d.Start.Format("2006-01-02"), d.End.Format("2006-01-02"))
+}
+
+// Sections lists the digest's task lists in the order they are shown
+func (d *digest) Sections() []digestSection {
+	return []digestSection{
+		{"Completed", d.Completed},
+		{"New", d.Created},
+		{"Overdue", d.Overdue},
+		{"Blocked", d.Blocked},
+	}
+}
+
+// newDigestCommand creates the digest command
+func newDigestCommand(app *App) *cobra.Command {
+	var (
+		period string
+		format string
+		to     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Summarize recent work for a status email",
+		Long: `Summarize a period's work: tasks completed and added during the period, open
+tasks that were not updated during it (overdue), and open tasks that are
+blocked. The period ends now.
+
+--format email writes a complete email with a plain text and an HTML part,
+ready to pipe into sendmail -t. Recipients come from --to or GTD_DIGEST_TO;
+GTD_DIGEST_FROM, GTD_DIGEST_SUBJECT, and GTD_DIGEST_TEMPLATE set the sender,
+the subject, and the HTML template.`,
+		Example: `  gtd digest
+  gtd digest --period day --format html > today.html
+  gtd digest --period week --format email | sendmail -t`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, ok := digestPeriods[period]
+			if !ok {
+				return errors.NewValidationError("invalid --period: %s (must be day, week, or month)", period)
+			}
+			if format != "text" && format != "html" && format != "email" {
+				return errors.NewValidationError("invalid --format: %s (must be text, html, or email)", format)
+			}
+			if to == "" {
+				to = app.Config().DigestTo
+			}
+			if format == "email" && to == "" {
+				return errors.NewValidationError("no recipients: use --to or set GTD_DIGEST_TO")
+			}
+
+			now := time.Now()
+			d, err := buildDigest(p.name, now.AddDate(0, 0, -p.days), now)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "html":
+				return writeDigestHTML(out, d, app.Config().DigestTemplate)
+			case "email":
+				return writeDigestEmail(out, d, app.Config(), to, now)
+			}
+			writeDigestText(out, d)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&period, "period", "week", "Period to summarize (day, week, month)")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, html, email)")
+	cmd.Flags().StringVar(&to, "to", "", "Comma-separated recipients for --format email [default: GTD_DIGEST_TO]")
+
+	return cmd
+}
+
+// buildDigest collects the tasks for a digest of the period from start to end
+func buildDigest(name string, start, end time.Time) (*digest, error) {
+	tasks, err := repo.List(models.ListOptions{All: true, ShowDone: true, ShowCancelled: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	d := &digest{Name: name, Start: start, End: end}
+	for _, task := range tasks {
+		if !task.Created.Before(start) && task.Created.Before(end) {
+			d.Created = append(d.Created, task)
+		}
+		switch task.State {
+		case models.StateDone:
+			done, err := stateReachedAt(task)
+			if err != nil {
+				return nil, err
+			}
+			if !done.Before(start) && done.Before(end) {
+				d.Completed = append(d.Completed, task)
+			}
+		case models.StateNew, models.StateInProgress:
+			if task.Updated.Before(start) {
+				d.Overdue = append(d.Overdue, task)
+			}
+			if task.IsBlocked() {
+				d.Blocked = append(d.Blocked, task)
+			}
+		}
+	}
+	return d, nil
+}
+
+// writeDigestText writes the digest as plain text
+func writeDigestText(w io.Writer, d *digest) {
+	_, _ = fmt.Fprintln(w, d.Title())
+	for _, section := range d.Sections() {
+		_, _ = fmt.Fprintf(w, "\n%s (%d)\n", section.Name, len(section.Tasks))
+		if len(section.Tasks) == 0 {
+			_, _ = fmt.Fprintln(w, "  None")
+		}
+		for _, task := range section.Tasks {
+			_, _ = fmt.Fprintf(w, "  - %s (%s, %s, %s)\n", task.Title, task.ShortHash(), strings.ToLower(task.Kind.String()), task.Priority)
+		}
+	}
+}
+
+// writeDigestHTML renders the digest with the template file at path
+// (GTD_DIGEST_TEMPLATE) or the built-in template
+func writeDigestHTML(w io.Writer, d *digest, path string) error {
+	text := defaultDigestHTML
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read GTD_DIGEST_TEMPLATE: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := htmltemplate.New("digest").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid digest template: %w", err)
+	}
+	return tmpl.Execute(w, d)
+}
+
+// writeDigestEmail writes the digest as a multipart email with a plain text
+// and an HTML part, for sendmail -t. cfg supplies the sender, subject, and
+// template (GTD_DIGEST_*)
+func writeDigestEmail(w io.Writer, d *digest, cfg *config.Config, to string, now time.Time) error {
+	subjectText := cfg.DigestSubject
+	if subjectText == "" {
+		subjectText = defaultDigestSubject
+	}
+	subjectTmpl, err := template.New("subject").Parse(subjectText)
+	if err != nil {
+		return fmt.Errorf("invalid GTD_DIGEST_SUBJECT: %w", err)
+	}
+	var subject strings.Builder
+	if err := subjectTmpl.Execute(&subject, d); err != nil {
+		return fmt.Errorf("invalid GTD_DIGEST_SUBJECT: %w", err)
+	}
+
+	var plain, html bytes.Buffer
+	writeDigestText(&plain, d)
+	if err := writeDigestHTML(&html, d, cfg.DigestTemplate); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", plain.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		pw, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write(part.content); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+
+	var recipients []string
+	for _, address := range strings.Split(to, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+
+	var header strings.Builder
+	if cfg.DigestFrom != "" {
+		_, _ = fmt.Fprintf(&header, "From: %s\r\n", cfg.DigestFrom)
+	}
+	_, _ = fmt.Fprintf(&header, "To: %s\r\n", strings.Join(recipients, ", "))
+	_, _ = fmt.Fprintf(&header, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject.String()))
+	_, _ = fmt.Fprintf(&header, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	_, _ = fmt.Fprintf(&header, "MIME-Version: 1.0\r\n")
+	_, _ = fmt.Fprintf(&header, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+
+	if _, err := io.WriteString(w, header.String()); err != nil {
+		return err
+	}
+	_, err = body.WriteTo(w)
+	return err
+}
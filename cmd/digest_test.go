@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestDigestCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	old := time.Now().AddDate(0, 0, -30)
+	stale := models.NewTask(models.KindBug, "Old crash", "Nobody looked at this")
+	stale.State = models.StateNew
+	stale.Created, stale.Updated = old, old
+	finished := models.NewTask(models.KindFeature, "Export to CSV", "Write tasks as CSV")
+	waiting := models.NewTask(models.KindFeature, "Import from CSV", "Read tasks from CSV")
+	for _, task := range []*models.Task{stale, finished, waiting} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, state := range []models.State{models.StateNew, models.StateInProgress, models.StateDone} {
+		if err := testRepo.UpdateState(finished.ID, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testRepo.UpdateState(waiting.ID, models.StateNew); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.Block(waiting.ID, stale.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	app.Config().DigestFrom = "gtd@example.com"
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newDigestCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run()
+	if err != nil {
+		t.Fatalf("digest error = %v", err)
+	}
+	for _, want := range []string{
+		"Weekly digest",
+		"Completed (1)\n  - Export to CSV",
+		"New (2)",
+		"Overdue (1)\n  - Old crash",
+		"Blocked (1)\n  - Import from CSV",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the digest:\n%s", want, out)
+		}
+	}
+
+	if _, err := run("--format", "email"); err == nil {
+		t.Error("Expected an error for an email without recipients")
+	}
+	if _, err := run("--period", "year"); err == nil {
+		t.Error("Expected an error for an invalid period")
+	}
+
+	out, err = run("--format", "email", "--to", "team@example.com")
+	if err != nil {
+		t.Fatalf("digest --format email error = %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Output is not an email: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "team@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := msg.Header.Get("From"); got != "gtd@example.com" {
+		t.Errorf("From = %q", got)
+	}
+	if !strings.HasPrefix(msg.Header.Get("Subject"), "Weekly digest") {
+		t.Errorf("Subject = %q", msg.Header.Get("Subject"))
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	var types []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), "Export to CSV") {
+			t.Errorf("Expected the completed task in the %s part:\n%s", part.Header.Get("Content-Type"), body)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Errorf("Expected a text and an HTML part, got %v", types)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/models"
+	"golang.org/x/term"
+)
+
+// editorTemplate is the commented buffer shown in the editor, like git commit
+const editorTemplate = `
+# Enter the %s title on the first line, followed by a blank line and
+# the description. Lines starting with '#' are ignored, and an empty
+# message aborts the task.
+`
+
+// readTaskInputOrEdit reads task input from r, or opens the editor when r is
+// an interactive terminal so add does not silently wait on stdin
+func readTaskInputOrEdit(app *App, r io.Reader, kind models.Kind) (title, description string, err error) {
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return editTaskInput(app, kind)
+	}
+	return readTaskInput(r)
+}
+
+// editTaskInput opens the configured editor on a commented template and
+// parses the saved buffer the same way as stdin input
+func editTaskInput(app *App, kind models.Kind) (title, description string, err error) {
+	file, err := os.CreateTemp("", "gtd-task-*.txt")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create editor file: %w", err)
+	}
+	path := file.Name()
+	defer func() { _ = os.Remove(path) }()
+
+	_, err = fmt.Fprintf(file, editorTemplate, strings.ToLower(kind.String()))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write editor file: %w", err)
+	}
+
+	// The editor may include arguments, e.g. "code --wait"
+	args := strings.Fields(app.Config().Editor)
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("no editor configured (set EDITOR or VISUAL)")
+	}
+	editor := exec.Command(args[0], append(args[1:], path)...)
+	editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editor.Run(); err != nil {
+		return "", "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read editor file: %w", err)
+	}
+	message := stripEditorComments(string(data))
+	if message == "" {
+		return "", "", fmt.Errorf("aborting task due to empty message")
+	}
+	return readTaskInput(strings.NewReader(message))
+}
+
+// stripEditorComments removes '#' comment lines and surrounding blank lines
+func stripEditorComments(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripEditorComments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "template only",
+			text: "\n# Enter the bug title\n# Lines starting with '#' are ignored\n",
+			want: "",
+		},
+		{
+			name: "message below template",
+			text: "\n# comment\nFix crash\n\nCrashes on start\n",
+			want: "Fix crash\n\nCrashes on start",
+		},
+		{
+			name: "indented hash is kept",
+			text: "Fix crash\n\n  # not a comment\n",
+			want: "Fix crash\n\n  # not a comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripEditorComments(tt.text); got != tt.want {
+				t.Errorf("stripEditorComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditTaskInput(t *testing.T) {
+	app := NewApp()
+	// A fake editor that appends a message below the template
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	content := "#!/bin/sh\nprintf 'Fix crash\\n\\nCrashes on start\\n' >> \"$1\"\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Config().Editor = script
+	title, description, err := editTaskInput(app, "BUG")
+	if err != nil {
+		t.Fatalf("editTaskInput() error = %v", err)
+	}
+	if title != "Fix crash" || description != "Crashes on start" {
+		t.Errorf("editTaskInput() = %q, %q", title, description)
+	}
+
+	// Saving the template unchanged aborts
+	app.Config().Editor = "true"
+	if _, _, err := editTaskInput(app, "BUG"); err == nil || !strings.Contains(err.Error(), "empty message") {
+		t.Errorf("editTaskInput() with unchanged template error = %v", err)
+	}
+}
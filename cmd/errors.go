@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// errorReport is the JSON form of an error, written with --json
+type errorReport struct {
+	Error errorDetail `json:"error"`
+}
+
+// errorDetail describes an error for scripts
+type errorDetail struct {
+	Code     errors.Code `json:"code"`
+	Message  string      `json:"message"`
+	ExitCode int         `json:"exit_code"`
+}
+
+// reportError writes a command's error to w, as JSON with --json. Exit
+// statuses are not errors to report.
+func reportError(w io.Writer, err error) {
+	if errors.IsExitStatus(err) {
+		return
+	}
+	if !jsonErrors {
+		_, _ = fmt.Fprintln(w, "Error:", err)
+		return
+	}
+
+	report := errorReport{Error: errorDetail{
+		Code:     errors.CodeOf(err),
+		Message:  err.Error(),
+		ExitCode: errors.ExitCode(err),
+	}}
+	// One line, so scripts can read it alongside other stderr output
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		_, _ = fmt.Fprintln(w, "Error:", report.Error.Message)
+	}
+}
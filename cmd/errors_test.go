@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestReportError(t *testing.T) {
+	oldJSON := jsonErrors
+	defer func() { jsonErrors = oldJSON }()
+
+	err := fmt.Errorf("failed to create task: %w", errors.NewValidationError("title is required"))
+
+	jsonErrors = false
+	var text bytes.Buffer
+	reportError(&text, err)
+	if text.String() != "Error: failed to create task: title is required\n" {
+		t.Errorf("text report = %q", text.String())
+	}
+
+	jsonErrors = true
+	var out bytes.Buffer
+	reportError(&out, err)
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("JSON report should be one line, got %q", out.String())
+	}
+	var report errorReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if report.Error.Code != errors.CodeValidation || report.Error.ExitCode != 6 ||
+		report.Error.Message != "failed to create task: title is required" {
+		t.Errorf("report = %+v", report.Error)
+	}
+}
+
+func TestCommandErrorCodes(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Fix login", "Users cannot log in")
+	task.State = models.StateNew
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		args []string
+		code errors.Code
+	}{
+		{name: "unknown task", cmd: newShowCommand(NewApp()), args: []string{"ffffff"}, code: errors.CodeNotFound},
+		{name: "invalid transition", cmd: newReopenCommand(NewApp()), args: []string{task.ID}, code: errors.CodeInvalidTransition},
+		{name: "invalid flag value", cmd: newListCommand(NewApp()), args: []string{"--priority", "urgent"}, code: errors.CodeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cmd.SetArgs(tt.args)
+			tt.cmd.SetOut(&bytes.Buffer{})
+			tt.cmd.SetErr(&bytes.Buffer{})
+			err := tt.cmd.Execute()
+			if got := errors.CodeOf(err); got != tt.code {
+				t.Errorf("CodeOf(%v) = %s, want %s", err, got, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newEscalateCommand creates the escalate command
+func newEscalateCommand(app *App) *cobra.Command {
+	var (
+		days   int
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "escalate",
+		Short: "Raise the priority of NEW tasks left untouched",
+		Long: `Raise the priority of NEW tasks that have not been updated for a number of
+days by one level: low becomes medium and medium becomes high. Each change is
+recorded in the task's history.
+
+The age defaults to GTD_ESCALATE_AFTER_DAYS. When that is set, review also
+escalates stale tasks before listing the inbox.`,
+		Example: `  gtd escalate --days 14
+  gtd escalate --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days == 0 {
+				days = app.Config().EscalateAfterDays
+			}
+			if days <= 0 {
+				return fmt.Errorf("no escalation age: use --days or set GTD_ESCALATE_AFTER_DAYS")
+			}
+
+			count, err := escalateStaleTasks(cmd.OutOrStdout(), days, dryRun)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No NEW tasks untouched for %d days.\n", days)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 0, "Escalate tasks not updated for this many days (default GTD_ESCALATE_AFTER_DAYS)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the tasks that would be escalated without changing them")
+
+	return cmd
+}
+
+// escalateStaleTasks raises the priority of NEW tasks not updated for the
+// given number of days, writing one line per task to out, and returns how
+// many tasks were (or with dryRun would be) escalated
+func escalateStaleTasks(out io.Writer, days int, dryRun bool) (int, error) {
+	tasks, err := repo.ListStale(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, task := range tasks {
+		from, to := task.Priority, models.NextPriority(task.Priority)
+		if dryRun {
+			_, _ = fmt.Fprintf(out, "Would escalate %s %s → %s: %s\n", task.ShortHash(), from, to, task.Title)
+			continue
+		}
+		if err := repo.Escalate(task); err != nil {
+			return 0, fmt.Errorf("failed to escalate task %s: %w", task.ShortHash(), err)
+		}
+		_, _ = fmt.Fprintf(out, "Escalated %s %s → %s: %s\n", task.ShortHash(), from, to, task.Title)
+	}
+	return len(tasks), nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestEscalateCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	// newTask creates a NEW task last updated the given number of days ago
+	newTask := func(title string, priority models.Priority, age int) *models.Task {
+		t.Helper()
+		task := models.NewTask(models.KindBug, title, "Escalation test")
+		task.State = models.StateNew
+		task.Priority = priority
+		task.Updated = time.Now().AddDate(0, 0, -age)
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	low := newTask("Old low", models.PriorityLow, 20)
+	medium := newTask("Old medium", models.PriorityMedium, 20)
+	recent := newTask("Recent low", models.PriorityLow, 2)
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      bool
+		wantOutput   []string
+		wantPriority map[*models.Task]models.Priority
+	}{
+		{
+			name:    "no age configured",
+			args:    []string{},
+			wantErr: true,
+		},
+		{
+			name:       "dry run",
+			args:       []string{"--days", "7", "--dry-run"},
+			wantOutput: []string{"Would escalate", "low → medium: Old low", "medium → high: Old medium"},
+			wantPriority: map[*models.Task]models.Priority{
+				low:    models.PriorityLow,
+				medium: models.PriorityMedium,
+			},
+		},
+		{
+			name:       "escalate",
+			args:       []string{"--days", "7"},
+			wantOutput: []string{"Escalated", "low → medium: Old low", "medium → high: Old medium"},
+			wantPriority: map[*models.Task]models.Priority{
+				low:    models.PriorityMedium,
+				medium: models.PriorityHigh,
+				recent: models.PriorityLow,
+			},
+		},
+		{
+			name:       "nothing left to escalate",
+			args:       []string{"--days", "7"},
+			wantOutput: []string{"No NEW tasks untouched for 7 days"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newEscalateCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, stdout.String())
+				}
+			}
+			for task, want := range tt.wantPriority {
+				got, err := testRepo.GetByID(task.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Priority != want {
+					t.Errorf("%s priority = %s, want %s", task.Title, got.Priority, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// existsMissing is the exit status of exists for tasks that don't exist
+const existsMissing = 2
+
+// newExistsCommand creates the exists command
+func newExistsCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "exists TASK_ID",
+		Short: "Check whether a task exists",
+		Long: `Check whether a task exists, for shell scripts and git hooks. Prints nothing
+and exits with status 0 when TASK_ID names a task, and 2 when it doesn't.
+
+TASK_ID must be a task's ID, a hash prefix matching only that task, or an
+alias such as @current or #12. Unlike other commands, exists doesn't match
+task titles, so a word that happens to appear in a title doesn't count. An
+ambiguous prefix or an unknown alias exits with 2 as well; other failures are
+reported as errors with their usual exit status.`,
+		Example: `  gtd exists abc123 && gtd show abc123
+  if ! gtd exists "$TASK"; then echo "unknown task $TASK" >&2; exit 1; fi`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := repo.LookupID(args[0])
+			switch errors.CodeOf(err) {
+			case errors.CodeNotFound, errors.CodeAmbiguousPrefix, errors.CodeValidation:
+				cmd.SilenceUsage, cmd.SilenceErrors = true, true
+				return errors.ExitStatus(existsMissing)
+			}
+			return err
+		},
+	}
+}
@@ -1,43 +1,129 @@
 package cmd
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
 // newExportCommand creates the export command
-func newExportCommand() *cobra.Command {
+func newExportCommand(app *App) *cobra.Command {
 	var (
 		format         string
 		outputFile     string
 		activeOnly     bool
 		stateFilter    string
+		statesFilter   string
+		includeInbox   bool
+		includeInvalid bool
 		priorityFilter string
 		kindFilter     string
+		severityFilter string
 		tagFilter      string
+		fieldFilters   []string
+		sinceFilter    string
+		columnsSpec    string
+		delimiter      string
+		noHeader       bool
+		compress       bool
+		resolveRefs    bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export tasks to various formats",
-		Long: `Export tasks to JSON, CSV, or Markdown format.
-Tasks can be filtered by state, priority, kind, or tags before export.`,
+		Long: `Export tasks to JSON, CSV, Markdown, or XLSX format.
+Tasks can be filtered by state, priority, kind, severity, tags, or custom
+fields before export. JSON exports include the custom fields of each task.
+
+XLSX exports are Excel workbooks with an overview sheet of task counts and
+one sheet per state, with date cells and a filterable header row.
+
+mermaid-gantt produces a Mermaid Gantt chart for embedding in Markdown, with
+one section per parent task. Bars run from creation until the task was
+finished, or until now for open tasks.
+
+With --since, only tasks created or updated after the given time are
+exported, together with the IDs of tasks deleted since then. The JSON
+output then carries an exported_at timestamp to use as the next --since.
+
+Without filters, tasks in every state are exported, including INBOX and
+INVALID. --active and --state leave those out unless --include-inbox or
+--include-invalid is given; --states selects exactly the listed states.
+
+With --resolve-refs, JSON, CSV, and Markdown exports carry the titles of the
+parent and blocker next to their IDs, so consumers don't need to look them up.`,
 		Example: `  claude-gtd export --format json
   claude-gtd export --format csv --output tasks.csv
+  claude-gtd export --format csv --delimiter '\t' --columns id,title,description
   claude-gtd export --format markdown --active
-  claude-gtd export --format json --state done --kind bug`,
+  claude-gtd export --format xlsx --output tasks.xlsx
+  claude-gtd export --format mermaid-gantt --tag release
+  claude-gtd export --format json --state done --kind bug
+  claude-gtd export --format csv --field customer=acme
+  claude-gtd export --format json --active --include-inbox
+  claude-gtd export --format json --states inbox,new,invalid
+  claude-gtd export --format json --since 2024-01-01T00:00:00Z
+  claude-gtd export --format json --since 24h --output changes.json.gz
+  claude-gtd export --format csv --resolve-refs`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Validate format
-			format = strings.ToLower(format)
-			if format != "json" && format != "csv" && format != "markdown" {
-				return fmt.Errorf("unsupported format: %s", format)
+			// Validate format; without --format, GTD_DEFAULT_FORMAT applies
+			// when it names an export format
+			flagFormat := ""
+			if cmd.Flags().Changed("format") {
+				flagFormat = format
+			}
+			resolved, err := output.ResolveFormat(flagFormat, app.Config().DefaultFormat,
+				output.FormatJSON, output.FormatCSV, output.FormatMarkdown, "xlsx", "mermaid-gantt")
+			if err != nil {
+				return err
+			}
+			format = resolved
+
+			// Validate CSV options
+			csvOpts := defaultCSVOptions
+			for _, name := range []string{"columns", "delimiter", "no-header"} {
+				if cmd.Flags().Changed(name) && format != "csv" {
+					return errors.NewValidationError("--%s is only supported with --format csv", name)
+				}
+			}
+			if cmd.Flags().Changed("columns") {
+				columns, err := parseColumns(columnsSpec)
+				if err != nil {
+					return err
+				}
+				csvOpts.columns = columns
+			}
+			if cmd.Flags().Changed("delimiter") {
+				comma, err := parseDelimiter(delimiter)
+				if err != nil {
+					return err
+				}
+				csvOpts.delimiter = comma
+			}
+			csvOpts.noHeader = noHeader
+
+			// Parent and blocker titles are looked up as tasks are exported
+			var titles *refTitles
+			if resolveRefs {
+				if format != "json" && format != "csv" && format != "markdown" {
+					return errors.NewValidationError("--resolve-refs is only supported with --format json, csv, or markdown")
+				}
+				titles = newRefTitles()
+				csvOpts.columns = titles.csvColumns(csvOpts.columns)
 			}
 
 			// Build list options
@@ -53,56 +139,108 @@ Tasks can be filtered by state, priority, kind, or tags before export.`,
 			}
 
 			if stateFilter != "" {
-				state := strings.ToUpper(stateFilter)
-				if state == "IN_PROGRESS" || state == "IN-PROGRESS" {
-					state = models.StateInProgress
+				state, err := models.ParseState(stateFilter)
+				if err != nil {
+					return err
 				}
-				if state != models.StateNew && state != models.StateInProgress &&
-					state != models.StateDone && state != models.StateCancelled {
-					return fmt.Errorf("invalid state: %s", stateFilter)
+				if state == models.StateInbox || state == models.StateInvalid {
+					return errors.NewValidationError("invalid state: %s (use --include-%s)", stateFilter, strings.ToLower(state.String()))
 				}
 				opts.State = state
 			}
 
+			if statesFilter != "" {
+				if activeOnly || stateFilter != "" {
+					return errors.NewValidationError("--states cannot be combined with --state or --active")
+				}
+				states, err := parseStates(statesFilter)
+				if err != nil {
+					return err
+				}
+				opts.States = states
+			}
+
+			// INBOX and INVALID are only excluded when filtering by state or
+			// with --active; the include flags add them back
+			if (activeOnly || opts.State != "" || len(opts.States) > 0) && (includeInbox || includeInvalid) {
+				switch {
+				case len(opts.States) > 0:
+				case opts.State != "":
+					opts.States = []models.State{opts.State}
+					opts.State = ""
+				default:
+					opts.States = []models.State{models.StateNew, models.StateInProgress}
+				}
+				if includeInbox {
+					opts.States = appendState(opts.States, models.StateInbox)
+				}
+				if includeInvalid {
+					opts.States = appendState(opts.States, models.StateInvalid)
+				}
+			}
+
 			if priorityFilter != "" {
-				priority := strings.ToLower(priorityFilter)
-				if priority != models.PriorityHigh && priority != models.PriorityMedium &&
-					priority != models.PriorityLow {
-					return fmt.Errorf("invalid priority: %s", priorityFilter)
+				priority, err := models.ParsePriority(priorityFilter)
+				if err != nil {
+					return err
 				}
 				opts.Priority = priority
 			}
 
 			if kindFilter != "" {
-				kind := strings.ToUpper(kindFilter)
-				if kind != models.KindBug && kind != models.KindFeature &&
-					kind != models.KindRegression {
-					return fmt.Errorf("invalid kind: %s", kindFilter)
+				kind, err := models.ParseKind(kindFilter)
+				if err != nil {
+					return err
 				}
 				opts.Kind = kind
 			}
 
+			if severityFilter != "" {
+				severity, err := models.ParseSeverity(severityFilter)
+				if err != nil {
+					return err
+				}
+				opts.Severity = severity
+			}
+
 			if tagFilter != "" {
 				opts.Tag = tagFilter
 			}
 
-			// Get tasks
-			tasks, err := repo.List(opts)
+			fields, err := models.ParseFieldFilters(fieldFilters)
 			if err != nil {
-				return fmt.Errorf("failed to list tasks: %w", err)
+				return err
 			}
+			opts.Fields = fields
 
-			// Determine output writer
+			exportedAt := time.Now()
+			var deleted []*models.Tombstone
+			if sinceFilter != "" {
+				since, err := parseSince(sinceFilter, exportedAt)
+				if err != nil {
+					return err
+				}
+				opts.UpdatedSince = since
+				deleted, err = repo.ListDeletedSince(since)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Determine output writer. A file is written under a temporary
+			// name and only replaces the output file once the export is
+			// complete, so a failed export leaves the previous one intact.
 			var writer io.Writer
+			var file *os.File
 			if outputFile != "" {
-				file, err := os.Create(outputFile)
+				file, err = createOutputFile(outputFile)
 				if err != nil {
 					return fmt.Errorf("failed to create output file: %w", err)
 				}
 				defer func() {
-					if err := file.Close(); err != nil {
-						// Log the error but don't fail the export
-						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to close file: %v\n", err)
+					if file != nil {
+						_ = file.Close()
+						_ = os.Remove(file.Name())
 					}
 				}()
 				writer = file
@@ -110,261 +248,475 @@ Tasks can be filtered by state, priority, kind, or tags before export.`,
 				writer = cmd.OutOrStdout()
 			}
 
-			// Export based on format
-			switch format {
-			case "json":
-				if err := exportJSON(writer, tasks); err != nil {
+			// Compress when asked to or when the output file is named *.gz
+			var gzipWriter *gzip.Writer
+			if compress || strings.HasSuffix(strings.ToLower(outputFile), ".gz") {
+				gzipWriter = gzip.NewWriter(writer)
+				writer = gzipWriter
+			}
+
+			// Formats written task by task stream the tasks from the
+			// database; the others need all of them at once
+			var exported int
+			switch {
+			case format == "json" && sinceFilter == "":
+				exported, err = streamExport(cmd.Context(), writer, output.FormatJSON, opts, output.Options{JSONTask: titles.jsonTask(loadExportTask)})
+				if err != nil {
 					return fmt.Errorf("failed to export JSON: %w", err)
 				}
-			case "csv":
-				if err := exportCSV(writer, tasks); err != nil {
+			case format == "csv":
+				exported, err = streamExport(cmd.Context(), writer, output.FormatCSV, opts, csvOpts.formatOptions())
+				if err == nil {
+					err = exportDeletedCSV(writer, deleted, csvOpts)
+				}
+				if err != nil {
 					return fmt.Errorf("failed to export CSV: %w", err)
 				}
-			case "markdown":
-				if err := exportMarkdown(writer, tasks); err != nil {
+			case format == "markdown":
+				exported, err = streamExport(cmd.Context(), writer, output.FormatMarkdown, opts, output.Options{RefTitle: titles.lookup()})
+				if err == nil {
+					err = exportDeletedMarkdown(writer, deleted)
+				}
+				if err != nil {
 					return fmt.Errorf("failed to export Markdown: %w", err)
 				}
+			default:
+				tasks, err := repo.List(opts)
+				if err != nil {
+					return fmt.Errorf("failed to list tasks: %w", err)
+				}
+				exported = len(tasks)
+
+				switch format {
+				case "json":
+					var items []exportTask
+					items, err = loadExportTasks(tasks)
+					if err == nil && titles != nil {
+						for i := range items {
+							titles.resolve(&items[i])
+						}
+					}
+					if err == nil {
+						err = exportIncrementalJSON(writer, items, deleted, opts.UpdatedSince, exportedAt)
+					}
+					if err != nil {
+						return fmt.Errorf("failed to export JSON: %w", err)
+					}
+				case "xlsx":
+					if err := exportXLSX(writer, tasks, deleted, exportedAt); err != nil {
+						return fmt.Errorf("failed to export XLSX: %w", err)
+					}
+				case "mermaid-gantt":
+					if err := exportMermaidGantt(writer, tasks, exportedAt); err != nil {
+						return fmt.Errorf("failed to export Gantt chart: %w", err)
+					}
+				}
 			}
 
-			// Show success message if writing to file
-			if outputFile != "" {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported %d tasks to %s\n", len(tasks), outputFile)
+			if titles != nil && titles.err != nil {
+				return fmt.Errorf("failed to resolve references: %w", titles.err)
+			}
+
+			if gzipWriter != nil {
+				if err := gzipWriter.Close(); err != nil {
+					return fmt.Errorf("failed to compress export: %w", err)
+				}
+			}
+
+			// Put the finished export in place and confirm it
+			if file != nil {
+				err := replaceOutputFile(file, outputFile)
+				file = nil
+				if err != nil {
+					return fmt.Errorf("failed to write output file: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported %d tasks to %s\n", exported, outputFile)
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", "json", "Export format (json, csv, markdown)")
+	cmd.Flags().StringVarP(&format, "format", "f", "json", "Export format (json, csv, markdown, xlsx, mermaid-gantt)")
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
 	cmd.Flags().BoolVar(&activeOnly, "active", false, "Export only active tasks (exclude DONE and CANCELLED)")
 	cmd.Flags().StringVar(&stateFilter, "state", "", "Filter by state (new, in_progress, done, cancelled)")
+	cmd.Flags().StringVar(&statesFilter, "states", "", "Export only these comma-separated states, including inbox and invalid")
+	cmd.Flags().BoolVar(&includeInbox, "include-inbox", false, "Also export INBOX tasks when filtering by state or with --active")
+	cmd.Flags().BoolVar(&includeInvalid, "include-invalid", false, "Also export INVALID tasks when filtering by state or with --active")
 	cmd.Flags().StringVar(&priorityFilter, "priority", "", "Filter by priority (high, medium, low)")
 	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by kind (bug, feature, regression)")
+	cmd.Flags().StringVar(&severityFilter, "severity", "", "Filter by severity (critical, major, minor)")
 	cmd.Flags().StringVar(&tagFilter, "tag", "", "Filter by tag")
+	cmd.Flags().StringArrayVar(&fieldFilters, "field", nil, "Filter by custom field as name=value, or name for any value (repeatable)")
+	cmd.Flags().StringVar(&sinceFilter, "since", "", "Only export changes after this time (e.g. 24h, 7d, yesterday, 2024-01-01, RFC3339); includes deletions")
+	cmd.Flags().StringVar(&columnsSpec, "columns", "", "Comma-separated CSV columns (e.g. id,state,title,tags)")
+	cmd.Flags().StringVar(&delimiter, "delimiter", ",", "CSV field delimiter, a single character or '\\t' for TSV")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "Omit the CSV header row")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip the output (automatic for --output files ending in .gz)")
+	cmd.Flags().BoolVar(&resolveRefs, "resolve-refs", false, "Include the titles of parents and blockers next to their IDs (json, csv, markdown)")
 
 	return cmd
 }
 
-// exportJSON exports tasks as JSON
-func exportJSON(w io.Writer, tasks []*models.Task) error {
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-
-	// Convert tasks to a format that includes all fields
-	type exportTask struct {
-		ID          string  `json:"id"`
-		Kind        string  `json:"kind"`
-		State       string  `json:"state"`
-		Priority    string  `json:"priority"`
-		Title       string  `json:"title"`
-		Description string  `json:"description"`
-		Tags        string  `json:"tags"`
-		Source      string  `json:"source"`
-		Parent      *string `json:"parent,omitempty"`
-		BlockedBy   *string `json:"blocked_by,omitempty"`
-		CreatedAt   string  `json:"created_at"`
-		UpdatedAt   string  `json:"updated_at"`
+// parseStates parses a comma-separated list of task states, accepting any
+// case and in-progress for IN_PROGRESS
+func parseStates(spec string) ([]models.State, error) {
+	var states []models.State
+	for _, value := range strings.Split(spec, ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		state, err := models.ParseState(value)
+		if err != nil {
+			return nil, err
+		}
+		states = appendState(states, state)
 	}
+	if len(states) == 0 {
+		return nil, errors.NewValidationError("no states given")
+	}
+	return states, nil
+}
 
-	exportTasks := make([]exportTask, len(tasks))
-	for i, task := range tasks {
-		exportTasks[i] = exportTask{
-			ID:          task.ID,
-			Kind:        task.Kind,
-			State:       task.State,
-			Priority:    task.Priority,
-			Title:       task.Title,
-			Description: task.Description,
-			Tags:        task.Tags,
-			Source:      task.Source,
-			Parent:      task.Parent,
-			BlockedBy:   task.BlockedBy,
-			CreatedAt:   task.Created.Format("2006-01-02 15:04:05"),
-			UpdatedAt:   task.Updated.Format("2006-01-02 15:04:05"),
+// appendState adds a state to a list unless it is already in it
+func appendState(states []models.State, state models.State) []models.State {
+	for _, existing := range states {
+		if existing == state {
+			return states
 		}
 	}
-
-	return encoder.Encode(exportTasks)
+	return append(states, state)
 }
 
-// exportCSV exports tasks as CSV
-func exportCSV(w io.Writer, tasks []*models.Task) error {
-	csvWriter := csv.NewWriter(w)
-	defer csvWriter.Flush()
+// exportTask is the JSON representation of a task in exports
+type exportTask = output.JSONTask
 
-	// Write header
-	header := []string{"ID", "Type", "State", "Priority", "Title", "Tags", "Source", "Parent", "BlockedBy", "Created", "Updated"}
-	if err := csvWriter.Write(header); err != nil {
-		return err
+// exportLink is an outgoing link from an exported task
+type exportLink = output.JSONLink
+
+// toExportTasks converts tasks to their JSON export representation
+func toExportTasks(tasks []*models.Task) []exportTask {
+	exportTasks := make([]exportTask, len(tasks))
+	for i, task := range tasks {
+		exportTasks[i] = *output.NewJSONTask(task)
 	}
+	return exportTasks
+}
 
-	// Write data rows
-	for _, task := range tasks {
-		parentStr := ""
-		if task.Parent != nil {
-			parentStr = *task.Parent
+// loadExportTasks converts tasks for export and loads their attachments,
+// links, and custom fields
+func loadExportTasks(tasks []*models.Task) ([]exportTask, error) {
+	items := make([]exportTask, len(tasks))
+	for i, task := range tasks {
+		item, err := loadExportTask(task)
+		if err != nil {
+			return nil, err
 		}
+		items[i] = *item
+	}
+	return items, nil
+}
 
-		blockedByStr := ""
-		if task.BlockedBy != nil {
-			blockedByStr = *task.BlockedBy
-		}
+// loadExportTask converts a task for export and loads its attachments, links,
+// and custom fields
+func loadExportTask(task *models.Task) (*exportTask, error) {
+	item := output.NewJSONTask(task)
+	attachments, err := repo.GetAttachments(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		item.Attachments = append(item.Attachments, attachment.Location)
+	}
 
-		row := []string{
-			task.ID,
-			task.Kind,
-			task.State,
-			task.Priority,
-			task.Title,
-			task.Tags,
-			task.Source,
-			parentStr,
-			blockedByStr,
-			task.Created.Format("2006-01-02 15:04:05"),
-			task.Updated.Format("2006-01-02 15:04:05"),
+	// Each link is exported once, on its source task
+	links, err := repo.GetLinks(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.SourceID == task.ID {
+			item.Links = append(item.Links, exportLink{Type: link.Type, Target: link.TargetID})
 		}
+	}
 
-		if err := csvWriter.Write(row); err != nil {
-			return err
-		}
+	fields, err := repo.GetFields(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		item.Fields = fields
 	}
+	return item, nil
+}
 
-	return nil
+// refTitles looks up the titles of parents and blockers for export
+// --resolve-refs, reading each task once
+type refTitles struct {
+	titles map[string]string
+	err    error // First failed lookup
 }
 
-// exportMarkdown exports tasks as Markdown
-func exportMarkdown(w io.Writer, tasks []*models.Task) error {
-	if _, err := fmt.Fprintln(w, "# Tasks Export"); err != nil {
-		return err
-	}
-	if _, err := fmt.Fprintln(w); err != nil {
-		return err
+// newRefTitles creates an empty title cache
+func newRefTitles() *refTitles {
+	return &refTitles{titles: make(map[string]string)}
+}
+
+// title returns the title of the task with the given ID, or "" when it
+// doesn't exist
+func (r *refTitles) title(id string) string {
+	if title, ok := r.titles[id]; ok {
+		return title
 	}
-	if _, err := fmt.Fprintf(w, "Total tasks: %d\n", len(tasks)); err != nil {
-		return err
+	task, err := repo.GetByID(id)
+	switch {
+	case err == nil:
+		r.titles[id] = task.Title
+	case errors.CodeOf(err) == errors.CodeNotFound:
+		r.titles[id] = ""
+	case r.err == nil:
+		r.err = err
 	}
-	if _, err := fmt.Fprintln(w); err != nil {
-		return err
+	return r.titles[id]
+}
+
+// lookup returns the title lookup for output.Options, nil when titles aren't
+// resolved
+func (r *refTitles) lookup() func(id string) string {
+	if r == nil {
+		return nil
 	}
+	return r.title
+}
 
-	// Table header
-	if _, err := fmt.Fprintln(w, "| ID | Type | State | Priority | Title | Tags | Source | Parent | Blocked By |"); err != nil {
-		return err
+// resolve sets the parent and blocker titles of an exported task
+func (r *refTitles) resolve(item *exportTask) {
+	if item.Parent != nil {
+		item.ParentTitle = r.title(*item.Parent)
 	}
-	if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|"); err != nil {
-		return err
+	if item.BlockedBy != nil {
+		item.BlockedByTitle = r.title(*item.BlockedBy)
 	}
+}
 
-	// Table rows
-	for i, task := range tasks {
-		parentStr := "-"
-		if task.Parent != nil {
-			parentStr = fmt.Sprintf("#%s", (*task.Parent)[:7])
+// jsonTask wraps a conversion for the json format to resolve titles, unless
+// r is nil
+func (r *refTitles) jsonTask(convert func(task *models.Task) (*exportTask, error)) func(task *models.Task) (*exportTask, error) {
+	if r == nil {
+		return convert
+	}
+	return func(task *models.Task) (*exportTask, error) {
+		item, err := convert(task)
+		if err != nil {
+			return nil, err
 		}
+		r.resolve(item)
+		return item, nil
+	}
+}
 
-		blockedByStr := "-"
-		if task.BlockedBy != nil {
-			blockedByStr = fmt.Sprintf("#%s", (*task.BlockedBy)[:7])
+// csvColumns adds a title column after each parent and blocked_by column
+func (r *refTitles) csvColumns(columns []taskColumn) []taskColumn {
+	var resolved []taskColumn
+	for _, column := range columns {
+		resolved = append(resolved, column)
+		switch column.names[0] {
+		case "parent":
+			resolved = append(resolved, taskColumn{
+				names:  []string{"parent_title"},
+				header: "ParentTitle",
+				value:  func(task *models.Task) string { return r.refTitle(task.Parent) },
+			})
+		case "blocked_by":
+			resolved = append(resolved, taskColumn{
+				names:  []string{"blocked_by_title"},
+				header: "BlockedByTitle",
+				value:  func(task *models.Task) string { return r.refTitle(task.BlockedBy) },
+			})
 		}
+	}
+	return resolved
+}
 
-		tagsStr := "-"
-		if task.Tags != "" {
-			tagsStr = task.Tags
-		}
+// refTitle returns the title of the task id points to, or "" when it is nil
+func (r *refTitles) refTitle(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return r.title(*id)
+}
 
-		sourceStr := "-"
-		if task.Source != "" {
-			sourceStr = task.Source
-		}
+// streamExport writes the tasks matching opts in the named format as they
+// are read from the database, returning how many it wrote
+func streamExport(ctx context.Context, w io.Writer, format string, opts models.ListOptions, formatOpts output.Options) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	formatter, err := output.Formats.GetFormatter(format, w, formatOpts)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = repo.Iterate(ctx, opts, func(task *models.Task) error {
+		count++
+		return formatter.WriteTask(task)
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, formatter.Close()
+}
 
-		if _, err := fmt.Fprintf(w, "| %d | %s | %s | %s | %s | %s | %s | %s | %s |\n",
-			i+1,
-			task.Kind,
-			task.State,
-			task.Priority,
-			task.Title,
-			tagsStr,
-			sourceStr,
-			parentStr,
-			blockedByStr,
-		); err != nil {
+// exportJSON exports tasks as JSON
+func exportJSON(w io.Writer, tasks []*models.Task) error {
+	return output.Formats.WriteTasks(w, output.FormatJSON, tasks, output.Options{})
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// incrementalExport is the JSON document produced by export --since
+type incrementalExport struct {
+	Since      string              `json:"since"`
+	ExportedAt string              `json:"exported_at"`
+	Tasks      []exportTask        `json:"tasks"`
+	Deleted    []*models.Tombstone `json:"deleted"`
+}
+
+// exportIncrementalJSON exports changed tasks and deletions since a point in time.
+// exported_at can be passed as --since on the next run to continue the sync.
+func exportIncrementalJSON(w io.Writer, tasks []exportTask, deleted []*models.Tombstone, since, exportedAt time.Time) error {
+	if deleted == nil {
+		deleted = []*models.Tombstone{}
+	}
+	return writeJSON(w, incrementalExport{
+		Since:      since.UTC().Format(time.RFC3339),
+		ExportedAt: exportedAt.UTC().Format(time.RFC3339),
+		Tasks:      tasks,
+		Deleted:    deleted,
+	})
+}
+
+// exportDeletedCSV appends tombstone rows to a CSV export, marked with state
+// DELETED and the deletion time as the update time
+func exportDeletedCSV(w io.Writer, deleted []*models.Tombstone, opts csvOptions) error {
+	csvWriter := opts.newWriter(w)
+	defer csvWriter.Flush()
+
+	for _, tombstone := range deleted {
+		row := make([]string, len(opts.columns))
+		for i, column := range opts.columns {
+			switch column.names[0] {
+			case "id":
+				row[i] = tombstone.ID
+			case "state":
+				row[i] = "DELETED"
+			case "updated":
+				row[i] = output.FormatExportTime(tombstone.Deleted)
+			}
+		}
+		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
 	}
 
-	// Add detailed task descriptions
-	if _, err := fmt.Fprintln(w); err != nil {
-		return err
+	return nil
+}
+
+// exportDeletedMarkdown appends a section listing deleted tasks to a Markdown export
+func exportDeletedMarkdown(w io.Writer, deleted []*models.Tombstone) error {
+	if len(deleted) == 0 {
+		return nil
 	}
-	if _, err := fmt.Fprintln(w, "## Task Details"); err != nil {
+	if _, err := fmt.Fprintln(w, "## Deleted Tasks"); err != nil {
 		return err
 	}
 	if _, err := fmt.Fprintln(w); err != nil {
 		return err
 	}
-
-	for _, task := range tasks {
-		if _, err := fmt.Fprintf(w, "### #%s: %s\n", task.ID, task.Title); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintln(w); err != nil {
+	for _, tombstone := range deleted {
+		if _, err := fmt.Fprintf(w, "- #%s (deleted %s)\n", tombstone.ID, output.FormatExportTime(tombstone.Deleted)); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if task.Description != "" {
-			if _, err := fmt.Fprintln(w, task.Description); err != nil {
-				return err
-			}
-			if _, err := fmt.Fprintln(w); err != nil {
-				return err
-			}
-		}
-
-		if _, err := fmt.Fprintf(w, "- **Type:** %s\n", formatKind(task.Kind)); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintf(w, "- **State:** %s %s\n", task.State, getStateEmoji(task.State)); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintf(w, "- **Priority:** %s %s\n", task.Priority, getPriorityEmoji(task.Priority)); err != nil {
-			return err
-		}
+// csvOptions controls the layout of CSV exports
+type csvOptions struct {
+	columns   []taskColumn
+	delimiter rune
+	noHeader  bool
+}
 
-		if task.Tags != "" {
-			if _, err := fmt.Fprintf(w, "- **Tags:** %s\n", task.Tags); err != nil {
-				return err
-			}
-		}
+// defaultCSVOptions are comma-separated default columns with a header row
+var defaultCSVOptions = csvOptions{columns: defaultCSVColumns, delimiter: ','}
 
-		if task.Source != "" {
-			if _, err := fmt.Fprintf(w, "- **Source:** %s\n", task.Source); err != nil {
-				return err
-			}
-		}
+// formatOptions returns the options for the csv format of output.Formats
+func (o csvOptions) formatOptions() output.Options {
+	columns := make([]output.CSVColumn, len(o.columns))
+	for i, column := range o.columns {
+		columns[i] = output.CSVColumn{Header: column.header, Value: column.value}
+	}
+	return output.Options{CSVColumns: columns, CSVComma: o.delimiter, CSVNoHeader: o.noHeader}
+}
 
-		if task.Parent != nil {
-			if _, err := fmt.Fprintf(w, "- **Parent:** #%s\n", *task.Parent); err != nil {
-				return err
-			}
-		}
+// newWriter returns a CSV writer using the options' delimiter
+func (o csvOptions) newWriter(w io.Writer) *csv.Writer {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = o.delimiter
+	return csvWriter
+}
 
-		if task.BlockedBy != nil {
-			if _, err := fmt.Fprintf(w, "- **Blocked by:** #%s\n", *task.BlockedBy); err != nil {
-				return err
-			}
-		}
+// parseDelimiter parses a --delimiter value, accepting the escape \t and the
+// name "tab" for tab-separated output
+func parseDelimiter(value string) (rune, error) {
+	switch value {
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' || runes[0] == utf8.RuneError {
+		return 0, errors.NewValidationError("invalid delimiter: %q (must be a single character other than a quote or newline)", value)
+	}
+	return runes[0], nil
+}
 
-		if _, err := fmt.Fprintf(w, "- **Created:** %s\n", task.Created.Format("2006-01-02 15:04:05")); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintf(w, "- **Updated:** %s\n", task.Updated.Format("2006-01-02 15:04:05")); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintln(w); err != nil {
-			return err
-		}
+// createOutputFile creates a temporary file next to path to write an export
+// to, with the permissions of the file it will replace
+func createOutputFile(path string) (*os.File, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
 	}
+	if err := file.Chmod(mode); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
 
+// replaceOutputFile closes a file made by createOutputFile and moves it to
+// path. The temporary file is removed if that fails.
+func replaceOutputFile(file *os.File, path string) error {
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// ganttTimeFormat is the Go layout matching the dateFormat of exported charts
+const ganttTimeFormat = "2006-01-02T15:04"
+
+// ganttSection is a group of bars in a Gantt chart: a parent task and its
+// subtasks, or the standalone tasks
+type ganttSection struct {
+	title string
+	tasks []*models.Task
+}
+
+// exportMermaidGantt exports tasks as a Mermaid Gantt chart with one section
+// per parent task. Tasks have no due dates or estimates, so each bar runs from
+// creation until the task was finished, or until now for open tasks.
+func exportMermaidGantt(w io.Writer, tasks []*models.Task, now time.Time) error {
+	sections, err := ganttSections(tasks)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		"gantt",
+		"    title Tasks",
+		"    dateFormat YYYY-MM-DDTHH:mm",
+		"    axisFormat %Y-%m-%d",
+	}
+	for _, section := range sections {
+		lines = append(lines, "    section "+ganttLabel(section.title))
+		for _, task := range section.tasks {
+			line, err := ganttTaskLine(task, now)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, "    "+line)
+		}
+	}
+
+	_, err = fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// ganttSections groups tasks by parent, in the order they were listed. Top-level
+// tasks without subtasks are collected in a final "Other tasks" section.
+func ganttSections(tasks []*models.Task) ([]ganttSection, error) {
+	exported := make(map[string]*models.Task)
+	hasChildren := make(map[string]bool)
+	for _, task := range tasks {
+		exported[task.ID] = task
+		if task.Parent != nil {
+			hasChildren[*task.Parent] = true
+		}
+	}
+
+	var sections []ganttSection
+	index := make(map[string]int)
+	var standalone []*models.Task
+	for _, task := range tasks {
+		groupID := task.ID
+		if task.Parent != nil {
+			groupID = *task.Parent
+		} else if !hasChildren[task.ID] {
+			standalone = append(standalone, task)
+			continue
+		}
+
+		i, ok := index[groupID]
+		if !ok {
+			parent := exported[groupID]
+			if parent == nil {
+				// Parent was filtered out of the export; still name the section after it
+				var err error
+				if parent, err = repo.GetByID(groupID); err != nil {
+					return nil, err
+				}
+			}
+			i = len(sections)
+			index[groupID] = i
+			sections = append(sections, ganttSection{title: parent.Title})
+		}
+
+		// Parents lead their section
+		if task.ID == groupID {
+			sections[i].tasks = append([]*models.Task{task}, sections[i].tasks...)
+		} else {
+			sections[i].tasks = append(sections[i].tasks, task)
+		}
+	}
+
+	if len(standalone) > 0 {
+		sections = append(sections, ganttSection{title: "Other tasks", tasks: standalone})
+	}
+	return sections, nil
+}
+
+// ganttTaskLine formats a task as a Gantt bar. Finished tasks are marked done,
+// tasks in progress active, and high priority tasks critical.
+func ganttTaskLine(task *models.Task, now time.Time) (string, error) {
+	var tags []string
+	if task.Priority == models.PriorityHigh {
+		tags = append(tags, "crit")
+	}
+
+	end := now
+	switch task.State {
+	case models.StateDone, models.StateCancelled, models.StateInvalid:
+		tags = append(tags, "done")
+		finished, err := stateReachedAt(task)
+		if err != nil {
+			return "", err
+		}
+		end = finished
+	case models.StateInProgress:
+		tags = append(tags, "active")
+	}
+
+	start := task.Created.In(time.Local)
+	end = end.In(time.Local)
+	// Keep very short tasks visible at the chart's minute resolution
+	if end.Sub(start) < time.Minute {
+		end = start.Add(time.Minute)
+	}
+
+	fields := append(tags, "t"+task.ShortHash(), start.Format(ganttTimeFormat), end.Format(ganttTimeFormat))
+	return fmt.Sprintf("%s :%s", ganttLabel(task.Title), strings.Join(fields, ", ")), nil
+}
+
+// stateReachedAt returns when a task last entered its current state, falling
+// back to its last update for tasks created in that state
+func stateReachedAt(task *models.Task) (time.Time, error) {
+	history, err := repo.GetHistory(task.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		if entry.Action == models.ActionState && entry.NewValue == task.State.String() {
+			return entry.Created, nil
+		}
+	}
+	return task.Updated, nil
+}
+
+// ganttLabel removes characters with special meaning in Mermaid Gantt lines
+func ganttLabel(s string) string {
+	s = strings.NewReplacer(":", " ", ";", ",", "#", "").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
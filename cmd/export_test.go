@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/models"
 )
@@ -106,6 +114,68 @@ func TestExportCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "export CSV with selected columns",
+			args: []string{"--format", "csv", "--columns", "id, state,title,blocked-by"},
+			validate: func(t *testing.T, output string) {
+				records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+				if err != nil {
+					t.Errorf("Failed to parse CSV output: %v", err)
+					return
+				}
+				if got := strings.Join(records[0], ","); got != "ID,State,Title,BlockedBy" {
+					t.Errorf("Header = %q, want ID,State,Title,BlockedBy", got)
+				}
+				for _, record := range records[1:] {
+					if record[2] == "Feature 1" && record[3] != task1.ID {
+						t.Errorf("Expected Feature 1 blocked by %s, got %q", task1.ID, record[3])
+					}
+				}
+			},
+		},
+		{
+			name: "export TSV without header",
+			args: []string{"--format", "csv", "--delimiter", `\t`, "--no-header", "--columns", "title,description"},
+			validate: func(t *testing.T, output string) {
+				reader := csv.NewReader(strings.NewReader(output))
+				reader.Comma = '\t'
+				records, err := reader.ReadAll()
+				if err != nil {
+					t.Errorf("Failed to parse TSV output: %v", err)
+					return
+				}
+				if len(records) != 3 {
+					t.Errorf("Expected 3 rows without header, got %d", len(records))
+				}
+				if !strings.Contains(output, "Bug 1\tDescription 1\n") {
+					t.Errorf("Expected tab-separated title and description, got:\n%s", output)
+				}
+			},
+		},
+		{
+			name:    "invalid delimiter",
+			args:    []string{"--format", "csv", "--delimiter", "::"},
+			wantErr: true,
+			errMsg:  "invalid delimiter",
+		},
+		{
+			name:    "delimiter with non-CSV format",
+			args:    []string{"--format", "markdown", "--delimiter", ";"},
+			wantErr: true,
+			errMsg:  "--delimiter is only supported with --format csv",
+		},
+		{
+			name:    "unknown CSV column",
+			args:    []string{"--format", "csv", "--columns", "id,due"},
+			wantErr: true,
+			errMsg:  "unknown column: due",
+		},
+		{
+			name:    "columns with non-CSV format",
+			args:    []string{"--format", "json", "--columns", "id"},
+			wantErr: true,
+			errMsg:  "only supported with --format csv",
+		},
 		{
 			name: "export to Markdown",
 			args: []string{"--format", "markdown"},
@@ -174,6 +244,51 @@ func TestExportCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "export active tasks with inbox",
+			args: []string{"--format", "json", "--active", "--include-inbox"},
+			validate: func(t *testing.T, output string) {
+				var tasks []map[string]interface{}
+				if err := json.Unmarshal([]byte(output), &tasks); err != nil {
+					t.Errorf("Failed to parse JSON output: %v", err)
+					return
+				}
+				if len(tasks) != 2 {
+					t.Errorf("Expected 2 active and inbox tasks, got %d", len(tasks))
+				}
+			},
+		},
+		{
+			name: "export with states",
+			args: []string{"--format", "json", "--states", "inbox,done"},
+			validate: func(t *testing.T, output string) {
+				var tasks []map[string]interface{}
+				if err := json.Unmarshal([]byte(output), &tasks); err != nil {
+					t.Errorf("Failed to parse JSON output: %v", err)
+					return
+				}
+				if len(tasks) != 2 {
+					t.Errorf("Expected 2 inbox and done tasks, got %d", len(tasks))
+				}
+				for _, task := range tasks {
+					if task["state"] != "INBOX" && task["state"] != "DONE" {
+						t.Errorf("Unexpected state %v", task["state"])
+					}
+				}
+			},
+		},
+		{
+			name:    "states with state",
+			args:    []string{"--states", "inbox", "--state", "new"},
+			wantErr: true,
+			errMsg:  "cannot be combined",
+		},
+		{
+			name:    "invalid states",
+			args:    []string{"--states", "inbox,waiting"},
+			wantErr: true,
+			errMsg:  "invalid state: WAITING",
+		},
 		{
 			name: "export with kind filter",
 			args: []string{"--format", "json", "--kind", "bug"},
@@ -194,6 +309,44 @@ func TestExportCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "incremental export since past",
+			args: []string{"--format", "json", "--since", "1h"},
+			validate: func(t *testing.T, output string) {
+				var result struct {
+					ExportedAt string                   `json:"exported_at"`
+					Tasks      []map[string]interface{} `json:"tasks"`
+					Deleted    []map[string]interface{} `json:"deleted"`
+				}
+				if err := json.Unmarshal([]byte(output), &result); err != nil {
+					t.Errorf("Failed to parse JSON output: %v", err)
+					return
+				}
+				if len(result.Tasks) != 3 {
+					t.Errorf("Expected 3 changed tasks, got %d", len(result.Tasks))
+				}
+				if result.Deleted == nil {
+					t.Error("Expected deleted list to be present")
+				}
+				if result.ExportedAt == "" {
+					t.Error("Expected exported_at timestamp")
+				}
+			},
+		},
+		{
+			name: "incremental export since future",
+			args: []string{"--format", "json", "--since", "2999-01-01"},
+			validate: func(t *testing.T, output string) {
+				if !strings.Contains(output, `"tasks": []`) {
+					t.Errorf("Expected no changed tasks, got:\n%s", output)
+				}
+			},
+		},
+		{
+			name:    "invalid since",
+			args:    []string{"--since", "yesterday-ish"},
+			wantErr: true,
+		},
 		{
 			name: "default format is JSON",
 			args: []string{},
@@ -226,7 +379,7 @@ func TestExportCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newExportCommand()
+			cmd := newExportCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -247,3 +400,379 @@ func TestExportCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestExportXLSX(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task1 := models.NewTask(models.KindBug, "Bug <1> & co", "Description 1")
+	task1.State = models.StateNew
+	if err := testRepo.Create(task1); err != nil {
+		t.Fatal(err)
+	}
+	task2 := models.NewTask(models.KindFeature, "Feature 1", "Description 2")
+	task2.State = models.StateInProgress
+	if err := testRepo.Create(task2); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newExportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--format", "xlsx"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(stdout.Bytes()), int64(stdout.Len()))
+	if err != nil {
+		t.Fatalf("Output is not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[file.Name] = string(content)
+	}
+
+	// Every part must be well-formed XML
+	for name, content := range parts {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", name, err)
+			}
+		}
+	}
+
+	workbook := parts["xl/workbook.xml"]
+	for _, sheet := range []string{`name="Overview"`, `name="New"`, `name="In Progress"`} {
+		if !strings.Contains(workbook, sheet) {
+			t.Errorf("Workbook missing sheet %s:\n%s", sheet, workbook)
+		}
+	}
+	if strings.Contains(workbook, `name="Done"`) {
+		t.Error("Workbook should not have a sheet for states without tasks")
+	}
+
+	overview := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(overview, "Total") {
+		t.Errorf("Overview sheet missing totals:\n%s", overview)
+	}
+
+	newSheet := parts["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(newSheet, `<autoFilter ref="A1:K2"/>`) {
+		t.Errorf("New sheet missing autofilter:\n%s", newSheet)
+	}
+	if !strings.Contains(newSheet, "Bug &lt;1&gt; &amp; co") {
+		t.Errorf("New sheet missing escaped title:\n%s", newSheet)
+	}
+	if !strings.Contains(newSheet, `<c r="J2" s="1"><v>`) {
+		t.Errorf("New sheet missing date cell:\n%s", newSheet)
+	}
+}
+
+func TestXLSXHelpers(t *testing.T) {
+	columns := map[int]string{0: "A", 10: "K", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for i, want := range columns {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+
+	if got := xlsxSheetName(models.StateInProgress); got != "In Progress" {
+		t.Errorf("xlsxSheetName() = %q, want %q", got, "In Progress")
+	}
+
+	noon := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.Local)
+	if got := xlsxSerial(noon); got != 45292.5 {
+		t.Errorf("xlsxSerial() = %v, want 45292.5", got)
+	}
+}
+
+func TestExportMermaidGantt(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	parent := models.NewTask(models.KindFeature, "Release: v2", "Parent")
+	parent.State = models.StateInProgress
+	parent.Priority = models.PriorityHigh
+	if err := testRepo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := models.NewTask(models.KindBug, "Fix #42", "Child")
+	child.Parent = &parent.ID
+	child.State = models.StateNew
+	if err := testRepo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(child.ID, models.StateInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.UpdateState(child.ID, models.StateDone); err != nil {
+		t.Fatal(err)
+	}
+	standalone := models.NewTask(models.KindBug, "Standalone", "Alone")
+	standalone.State = models.StateNew
+	if err := testRepo.Create(standalone); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newExportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--format", "mermaid-gantt"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"gantt\n",
+		"dateFormat YYYY-MM-DDTHH:mm",
+		"section Release v2\n    Release v2 :crit, active, t" + parent.ShortHash(),
+		"Fix 42 :done, t" + child.ShortHash(),
+		"section Other tasks\n    Standalone :t" + standalone.ShortHash(),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output does not contain %q\nGot: %s", want, output)
+		}
+	}
+}
+
+func TestExportCompress(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Bug 1", "Description 1")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	gunzip := func(t *testing.T, data []byte) string {
+		t.Helper()
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Output is not gzip compressed: %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	t.Run("compress flag", func(t *testing.T) {
+		var stdout bytes.Buffer
+		cmd := newExportCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{"--format", "csv", "--compress"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if output := gunzip(t, stdout.Bytes()); !strings.Contains(output, "Bug 1") {
+			t.Errorf("Expected decompressed CSV to contain Bug 1, got:\n%s", output)
+		}
+	})
+
+	t.Run("gz output file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tasks.json.gz")
+		cmd := newExportCommand(NewApp())
+		cmd.SetOut(io.Discard)
+		cmd.SetArgs([]string{"--format", "json", "--output", path})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tasks []map[string]interface{}
+		if err := json.Unmarshal([]byte(gunzip(t, data)), &tasks); err != nil {
+			t.Fatalf("Failed to parse decompressed JSON: %v", err)
+		}
+		if len(tasks) != 1 {
+			t.Errorf("Expected 1 task, got %d", len(tasks))
+		}
+	})
+
+	t.Run("failed export keeps previous file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "tasks.json.gz")
+		if err := os.WriteFile(path, []byte("previous backup"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cmd := newExportCommand(NewApp())
+		cmd.SetOut(io.Discard)
+		cmd.SetArgs([]string{"--format", "json", "--output", path})
+		if err := cmd.ExecuteContext(ctx); err == nil {
+			t.Fatal("Expected the interrupted export to fail")
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "previous backup" {
+			t.Errorf("Output file = %q, want the previous backup untouched", data)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("Expected only the output file to remain, found %d files", len(entries))
+		}
+	})
+}
+
+func TestExportSections(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Crash on save", "Saving crashes.\n\nRepro Steps:\nPress Ctrl+S\n\nExpected Behavior: the file is saved")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newExportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--format", "json", "--include-inbox"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var items []exportTask
+	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(items))
+	}
+	want := map[string]string{"repro": "Press Ctrl+S", "expected": "the file is saved"}
+	if len(items[0].Sections) != len(want) {
+		t.Errorf("Sections = %v, want %v", items[0].Sections, want)
+	}
+	for key, body := range want {
+		if items[0].Sections[key] != body {
+			t.Errorf("Sections[%s] = %q, want %q", key, items[0].Sections[key], body)
+		}
+	}
+	if items[0].Description != task.Description {
+		t.Errorf("Expected the description to be exported as written, got %q", items[0].Description)
+	}
+}
+
+func TestExportResolveRefs(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	parent := models.NewTask(models.KindFeature, "Checkout flow", "Let customers pay for their cart")
+	blocker := models.NewTask(models.KindBug, "Payment API times out", "Requests hang after 30 seconds")
+	for _, task := range []*models.Task{parent, blocker} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	child := models.NewTask(models.KindFeature, "Add coupon field", "Accept discount codes at checkout")
+	child.Parent = &parent.ID
+	if err := testRepo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.Block(child.ID, blocker.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	export := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newExportCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"--include-inbox", "--resolve-refs"}, args...))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, err := export("--format", "json")
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		var items []exportTask
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			if item.ID != child.ID {
+				if item.ParentTitle != "" || item.BlockedByTitle != "" {
+					t.Errorf("Task %s has reference titles without references", item.Title)
+				}
+				continue
+			}
+			if item.ParentTitle != parent.Title {
+				t.Errorf("ParentTitle = %q, want %q", item.ParentTitle, parent.Title)
+			}
+			if item.BlockedByTitle != blocker.Title {
+				t.Errorf("BlockedByTitle = %q, want %q", item.BlockedByTitle, blocker.Title)
+			}
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		out, err := export("--format", "csv", "--columns", "id,parent,blocked_by")
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(records[0], ","); got != "ID,Parent,ParentTitle,BlockedBy,BlockedByTitle" {
+			t.Errorf("Header = %q", got)
+		}
+		want := []string{child.ID, parent.ID, parent.Title, blocker.ID, blocker.Title}
+		var found bool
+		for _, record := range records[1:] {
+			if record[0] == child.ID {
+				found = true
+				if strings.Join(record, ",") != strings.Join(want, ",") {
+					t.Errorf("Row = %v, want %v", record, want)
+				}
+			}
+		}
+		if !found {
+			t.Error("Subtask not exported")
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		out, err := export("--format", "markdown")
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		for _, want := range []string{
+			"#" + models.ShortID(parent.ID) + " Checkout flow |",
+			"- **Parent:** #" + parent.ID + " Checkout flow",
+			"- **Blocked by:** #" + blocker.ID + " Payment API times out",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Markdown lacks %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if _, err := export("--format", "xlsx", "--output", filepath.Join(t.TempDir(), "tasks.xlsx")); err == nil {
+			t.Error("Expected --resolve-refs to be rejected with xlsx")
+		}
+	})
+}
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// xlsxStates is the order of the per-state sheets in an XLSX export
+var xlsxStates = []models.State{
+	models.StateInbox,
+	models.StateNew,
+	models.StateInProgress,
+	models.StateDone,
+	models.StateCancelled,
+	models.StateInvalid,
+}
+
+// xlsxTaskHeader is the header row of the per-state sheets
+var xlsxTaskHeader = []string{"ID", "Type", "State", "Priority", "Title", "Tags", "Source", "Parent", "BlockedBy", "Created", "Updated"}
+
+// xlsxTaskColumnWidths are the widths of the per-state sheet columns, in characters
+var xlsxTaskColumnWidths = []int{12, 12, 13, 10, 50, 20, 25, 12, 12, 18, 18}
+
+// Cell styles, as indexes into cellXfs in xlsxStyles
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleDate    = 1
+	xlsxStyleHeader  = 2
+)
+
+// xlsxCell is a single spreadsheet cell: a string, an int, or a date
+type xlsxCell struct {
+	text   string
+	number *int
+	date   *time.Time
+	style  int
+}
+
+func xlsxText(s string) xlsxCell { return xlsxCell{text: s} }
+
+func xlsxNumber(n int) xlsxCell { return xlsxCell{number: &n} }
+
+func xlsxDate(t time.Time) xlsxCell { return xlsxCell{date: &t, style: xlsxStyleDate} }
+
+// xlsxSheet is a worksheet; the first row is the header when filter is set
+type xlsxSheet struct {
+	name   string
+	widths []int
+	rows   [][]xlsxCell
+	filter bool
+}
+
+// exportXLSX exports tasks as an Excel workbook with an overview sheet and
+// one sheet per state. Deleted tasks, when given, get a sheet of their own.
+func exportXLSX(w io.Writer, tasks []*models.Task, deleted []*models.Tombstone, exportedAt time.Time) error {
+	byState := make(map[models.State][]*models.Task)
+	for _, task := range tasks {
+		byState[task.State] = append(byState[task.State], task)
+	}
+
+	sheets := []xlsxSheet{xlsxOverviewSheet(tasks, byState, exportedAt)}
+	for _, state := range xlsxStates {
+		if len(byState[state]) == 0 {
+			continue
+		}
+		sheets = append(sheets, xlsxTaskSheet(xlsxSheetName(state), byState[state]))
+	}
+	if len(deleted) > 0 {
+		sheet := xlsxSheet{
+			name:   "Deleted",
+			widths: []int{42, 18},
+			rows:   [][]xlsxCell{xlsxHeaderRow("ID", "Deleted")},
+			filter: true,
+		}
+		for _, tombstone := range deleted {
+			sheet.rows = append(sheet.rows, []xlsxCell{xlsxText(tombstone.ID), xlsxDate(tombstone.Deleted)})
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	return writeXLSX(w, sheets)
+}
+
+// xlsxOverviewSheet summarizes task counts per state, kind and priority
+func xlsxOverviewSheet(tasks []*models.Task, byState map[models.State][]*models.Task, exportedAt time.Time) xlsxSheet {
+	sheet := xlsxSheet{
+		name:   "Overview",
+		widths: []int{14, 10, 10, 10, 12, 8, 8, 8},
+		rows: [][]xlsxCell{
+			{xlsxText("Exported"), xlsxDate(exportedAt)},
+			{},
+			xlsxHeaderRow("State", "Tasks", "Bugs", "Features", "Regressions", "High", "Medium", "Low"),
+		},
+	}
+
+	summary := func(label string, tasks []*models.Task) []xlsxCell {
+		kinds := make(map[models.Kind]int)
+		priorities := make(map[models.Priority]int)
+		for _, task := range tasks {
+			kinds[task.Kind]++
+			priorities[task.Priority]++
+		}
+		return []xlsxCell{
+			xlsxText(label),
+			xlsxNumber(len(tasks)),
+			xlsxNumber(kinds[models.KindBug]),
+			xlsxNumber(kinds[models.KindFeature]),
+			xlsxNumber(kinds[models.KindRegression]),
+			xlsxNumber(priorities[models.PriorityHigh]),
+			xlsxNumber(priorities[models.PriorityMedium]),
+			xlsxNumber(priorities[models.PriorityLow]),
+		}
+	}
+
+	for _, state := range xlsxStates {
+		if len(byState[state]) > 0 {
+			sheet.rows = append(sheet.rows, summary(state.String(), byState[state]))
+		}
+	}
+	totalRow := summary("Total", tasks)
+	for i := range totalRow {
+		totalRow[i].style = xlsxStyleHeader
+	}
+	sheet.rows = append(sheet.rows, totalRow)
+
+	return sheet
+}
+
+// xlsxTaskSheet lists tasks with the same columns as the CSV export
+func xlsxTaskSheet(name string, tasks []*models.Task) xlsxSheet {
+	sheet := xlsxSheet{
+		name:   name,
+		widths: xlsxTaskColumnWidths,
+		rows:   [][]xlsxCell{xlsxHeaderRow(xlsxTaskHeader...)},
+		filter: true,
+	}
+	for _, task := range tasks {
+		sheet.rows = append(sheet.rows, []xlsxCell{
+			xlsxText(task.ID),
+			xlsxText(task.Kind.String()),
+			xlsxText(task.State.String()),
+			xlsxText(task.Priority.String()),
+			xlsxText(task.Title),
+			xlsxText(task.Tags),
+			xlsxText(task.Source),
+			xlsxText(derefOrEmpty(task.Parent)),
+			xlsxText(derefOrEmpty(task.BlockedBy)),
+			xlsxDate(task.Created),
+			xlsxDate(task.Updated),
+		})
+	}
+	return sheet
+}
+
+// xlsxHeaderRow creates a row of bold text cells
+func xlsxHeaderRow(titles ...string) []xlsxCell {
+	row := make([]xlsxCell, len(titles))
+	for i, title := range titles {
+		row[i] = xlsxCell{text: title, style: xlsxStyleHeader}
+	}
+	return row
+}
+
+// xlsxSheetName turns a state such as IN_PROGRESS into a sheet name such as "In Progress"
+func xlsxSheetName(state models.State) string {
+	words := strings.Split(strings.ToLower(state.String()), "_")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// xlsxEpoch is day zero of Excel's 1900 date system
+var xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxSerial converts a time to an Excel date serial number. Spreadsheets
+// have no timezones, so the wall clock time in the display timezone is used.
+func xlsxSerial(t time.Time) float64 {
+	t = t.In(time.Local)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(xlsxEpoch).Hours() / 24
+}
+
+// xlsxColumn converts a zero-based column index to its letters, e.g. 0 to A and 26 to AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// writeXLSX writes sheets as a minimal Office Open XML workbook
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	zw := zip.NewWriter(w)
+
+	write := func(name, content string) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(fw, content)
+		return err
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		if err := write(part.name, part.content); err != nil {
+			return err
+		}
+	}
+	for i, sheet := range sheets {
+		if err := write(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxWorksheet(sheet)); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const xlsxRootRels = xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell styles: default, date and bold header
+const xlsxStyles = xlsxHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+func xlsxContentTypes(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func xlsxWorkbook(sheets []xlsxSheet) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&sb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.name), i+1, i+1)
+	}
+	sb.WriteString(`</sheets>`)
+
+	// Autofilters need a hidden defined name per sheet for Excel to accept them
+	var names strings.Builder
+	for i, sheet := range sheets {
+		if sheet.filter && len(sheet.rows) > 0 {
+			fmt.Fprintf(&names, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">'%s'!%s</definedName>`,
+				i, xlsxEscape(sheet.name), xlsxFilterRange(sheet, true))
+		}
+	}
+	if names.Len() > 0 {
+		sb.WriteString(`<definedNames>` + names.String() + `</definedNames>`)
+	}
+
+	sb.WriteString(`</workbook>`)
+	return sb.String()
+}
+
+func xlsxWorkbookRels(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// xlsxFilterRange returns the range covered by a sheet's autofilter, e.g.
+// A1:K12, or $A$1:$K$12 when absolute
+func xlsxFilterRange(sheet xlsxSheet, absolute bool) string {
+	last := xlsxColumn(len(sheet.rows[0]) - 1)
+	if absolute {
+		return fmt.Sprintf("$A$1:$%s$%d", last, len(sheet.rows))
+	}
+	return fmt.Sprintf("A1:%s%d", last, len(sheet.rows))
+}
+
+func xlsxWorksheet(sheet xlsxSheet) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	// Keep the header row visible while scrolling
+	if sheet.filter {
+		sb.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+
+	if len(sheet.widths) > 0 {
+		sb.WriteString(`<cols>`)
+		for i, width := range sheet.widths {
+			fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+		sb.WriteString(`</cols>`)
+	}
+
+	sb.WriteString(`<sheetData>`)
+	for r, row := range sheet.rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(c), r+1)
+			switch {
+			case cell.date != nil:
+				fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%.6f</v></c>`, ref, cell.style, xlsxSerial(*cell.date))
+			case cell.number != nil:
+				fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%d</v></c>`, ref, cell.style, *cell.number)
+			case cell.text != "":
+				fmt.Fprintf(&sb, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.style, xlsxEscape(cell.text))
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData>`)
+
+	if sheet.filter && len(sheet.rows) > 0 {
+		fmt.Fprintf(&sb, `<autoFilter ref="%s"/>`, xlsxFilterRange(sheet, false))
+	}
+
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+// xlsxEscape escapes text for use in XML content and attributes
+func xlsxEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newFocusCommand creates the focus command
+func newFocusCommand(app *App) *cobra.Command {
+	var clearFocus bool
+
+	cmd := &cobra.Command{
+		Use:   "focus [TASK_ID]",
+		Short: "Set or show the current task",
+		Long: `Set or show the task currently in focus.
+With a TASK_ID, the task becomes the current task for this workspace.
+Without arguments, the current task is shown.
+
+Other commands accept @current wherever a TASK_ID is expected.`,
+		Example: `  gtd focus abc123
+  gtd focus
+  gtd in-progress @current
+  gtd done @current
+  gtd focus --clear`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearFocus {
+				if len(args) > 0 {
+					return errors.NewValidationError("cannot combine --clear with a task ID")
+				}
+				if err := repo.ClearCurrentTask(); err != nil {
+					return fmt.Errorf("failed to clear current task: %w", err)
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Current task cleared")
+				return nil
+			}
+
+			if len(args) == 0 {
+				task, err := repo.GetCurrentTask()
+				if err != nil {
+					return err
+				}
+				if task == nil {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No current task. Use 'gtd focus TASK_ID' to set one.")
+					return nil
+				}
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), app.formatTaskGitStyle(task, nil))
+				return nil
+			}
+
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := repo.SetCurrentTask(task.ID); err != nil {
+				return fmt.Errorf("failed to set current task: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Focused on task %s: %s\n", task.ShortHash(), task.Title)
+			if task.State == models.StateDone || task.State == models.StateCancelled || task.State == models.StateInvalid {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Note: task is in %s state\n", task.State)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clearFocus, "clear", false, "Clear the current task")
+
+	return cmd
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestFocusCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Focus bug", "A bug worth focusing on")
+	task.State = models.StateNew
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := newFocusCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	// No current task yet
+	out, err := run()
+	if err != nil {
+		t.Fatalf("focus without task failed: %v", err)
+	}
+	if !strings.Contains(out, "No current task") {
+		t.Errorf("Output = %q, want no current task message", out)
+	}
+
+	// Set the current task by prefix
+	out, err = run(task.ID[:7])
+	if err != nil {
+		t.Fatalf("focus failed: %v", err)
+	}
+	if !strings.Contains(out, "Focused on task "+task.ShortHash()) {
+		t.Errorf("Output = %q, want focus confirmation", out)
+	}
+
+	// Show the current task
+	out, err = run()
+	if err != nil {
+		t.Fatalf("focus show failed: %v", err)
+	}
+	if !strings.Contains(out, task.ID) || !strings.Contains(out, "Focus bug") {
+		t.Errorf("Output = %q, want current task details", out)
+	}
+
+	// @current resolves in other commands
+	var stdout bytes.Buffer
+	cmd := newInProgressCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{models.CurrentTaskAlias})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("in-progress @current failed: %v", err)
+	}
+	updated, err := testRepo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.State != models.StateInProgress {
+		t.Errorf("State = %q, want %q", updated.State, models.StateInProgress)
+	}
+
+	// Clear the current task
+	if _, err := run("--clear"); err != nil {
+		t.Fatalf("focus --clear failed: %v", err)
+	}
+	if _, err := testRepo.GetByID(models.CurrentTaskAlias); err == nil {
+		t.Error("Expected error resolving @current after clear")
+	}
+
+	// Unknown task
+	if _, err := run("ffffffff"); err == nil {
+		t.Error("Expected error focusing unknown task")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/models"
 	"github.com/zw3rk/gtd/internal/output"
@@ -12,273 +13,90 @@ import (
 // SubtaskStats is re-exported from output package for compatibility
 type SubtaskStats = output.SubtaskStats
 
-// Priority indicators
-const (
-	emojiHigh   = "!" // Exclamation mark for high priority
-	emojiMedium = "=" // Equals sign for medium priority
-	emojiLow    = "-" // Hyphen for low priority
-)
-
-// State indicators
-const (
-	emojiNew        = "◆" // U+25C6 - Black Diamond (was 📋)
-	emojiInProgress = "▶" // U+25B6 - Black Right-Pointing Triangle (was 🔄)
-	emojiDone       = "✓" // U+2713 - Check Mark (was ✅)
-	emojiCancelled  = "✗" // U+2717 - Ballot X (was ❌)
-	emojiBlocked    = "⊘" // U+2298 - Circled Division Slash (was 🚫)
-)
-
-// formatTaskGitStyle formats a task in git log style - wrapper for compatibility
-func formatTaskGitStyle(task *models.Task, subtaskStats *SubtaskStats) string {
-	// Use the centralized formatter if colors are disabled
-	if !useColor {
-		return output.FormatTaskGitStyle(task, subtaskStats)
-	}
-
-	// Keep the colored version here for now
-	var b strings.Builder
-
-	// Line 1: task <full-hash>
-	hashLine := fmt.Sprintf("task %s", task.ID)
-	if useColor {
-		b.WriteString(colorize("task", colorYellow))
-		b.WriteString(" ")
-		b.WriteString(colorize(task.ID, colorYellow))
-	} else {
-		b.WriteString(hashLine)
-	}
-	b.WriteString("\n")
-
-	// Line 2: Author: Name <email>
-	b.WriteString("Author: ")
-	b.WriteString(task.Author)
-	b.WriteString("\n")
-
-	// Line 3: Date: timestamp
-	b.WriteString("Date:   ")
-	b.WriteString(task.Created.Format("Mon Jan 2 15:04:05 2006 -0700"))
-	b.WriteString("\n\n")
-
-	// Line 4 (indented): state indicator + kind(priority): title
-	b.WriteString("  ")
-
-	// State indicator
-	if useColor {
-		b.WriteString(formatStateColor(task.State))
-	} else {
-		b.WriteString(getStateEmoji(task.State))
-	}
-	b.WriteString(" ")
-
-	// Format kind(priority):
-	kindPriority := fmt.Sprintf("%s(%s): ", strings.ToLower(task.Kind), task.Priority)
-	if useColor {
-		b.WriteString(formatKindPriorityColor(task.Kind, task.Priority))
-	} else {
-		b.WriteString(kindPriority)
-	}
-
-	// Title
-	title := task.Title
-	if subtaskStats != nil && subtaskStats.Total > 0 {
-		title = fmt.Sprintf("%s (%d/%d)", task.Title, subtaskStats.Done, subtaskStats.Total)
-	}
-	if useColor {
-		b.WriteString(colorize(title, colorBold))
-	} else {
-		b.WriteString(title)
-	}
-	b.WriteString("\n")
-
-	// Body (indented with extra indent)
-	if task.Description != "" {
-		b.WriteString("\n")
-		for _, line := range strings.Split(task.Description, "\n") {
-			b.WriteString("    ")
-			b.WriteString(line)
-			b.WriteString("\n")
-		}
-	}
+// listFormats are the output formats of commands that list tasks
+var listFormats = output.Formats.Names()
 
-	// Blocked-by (if applicable)
-	if task.IsBlocked() && task.BlockedBy != nil {
-		b.WriteString("\n    Blocked-by: ")
-		if useColor {
-			b.WriteString(colorize(*task.BlockedBy, colorRed))
-		} else {
-			b.WriteString(*task.BlockedBy)
-		}
-		b.WriteString("\n")
+// resolveListFormat picks the --output format of a command that lists
+// tasks, with --oneline as a shorthand for --output oneline and
+// GTD_DEFAULT_FORMAT when neither is given
+func (a *App) resolveListFormat(format string, oneline bool) (string, error) {
+	if oneline && format == "" {
+		format = output.FormatOneline
 	}
-
-	return b.String()
+	return output.ResolveFormat(format, a.config.DefaultFormat, listFormats...)
 }
 
-// formatTaskCompact formats a task in the new compact single-line format
-func formatTaskCompact(task *models.Task, showDetails bool) string {
-	var b strings.Builder
-
-	// Build the main line: hash state kind(priority): title #tags
-	var mainParts []string
-
-	// Hash at the beginning (like git log)
-	hash := task.ShortHash()
-	if useColor {
-		hash = colorize(hash, colorYellow)
-	}
-	mainParts = append(mainParts, hash)
-
-	// State indicator
-	if useColor {
-		mainParts = append(mainParts, formatStateColor(task.State))
-	} else {
-		mainParts = append(mainParts, getStateEmoji(task.State))
-	}
-
-	// kind(priority): format
-	kindPriority := fmt.Sprintf("%s(%s):", strings.ToLower(task.Kind), task.Priority)
-	if useColor {
-		kindPriority = formatKindPriorityColor(task.Kind, task.Priority)
-	}
-	mainParts = append(mainParts, kindPriority)
-
-	// Title
-	title := task.Title
-	if useColor {
-		title = colorize(title, colorBold)
-	}
-	mainParts = append(mainParts, title)
-
-	// Tags with # prefix
-	if task.Tags != "" {
-		mainParts = append(mainParts, formatTagsColor(task.Tags))
-	}
-
-	// Blocked indicator
-	if task.IsBlocked() {
-		blocked := emojiBlocked
-		if useColor {
-			blocked = colorize(blocked, colorRed)
-		}
-		mainParts = append(mainParts, blocked)
-	}
-
-	// Build main line
-	mainLine := strings.Join(mainParts, " ")
-	b.WriteString(mainLine)
-
-	if showDetails {
-		// Add description if present, indented
-		if task.Description != "" {
-			b.WriteString("\n")
-			// Split description into lines and indent each
-			descLines := strings.Split(task.Description, "\n")
-			for _, line := range descLines {
-				fmt.Fprintf(&b, "    %s\n", line)
-			}
-		}
-
-		// Add metadata as part of the body if relevant
-		if task.IsBlocked() && task.BlockedBy != nil {
-			fmt.Fprintf(&b, "\n    Blocked by: %s\n", *task.BlockedBy)
-		}
+// writeTasksAs writes tasks in one of the export formats of output.Formats,
+// reporting whether format was one of them. The standard and oneline
+// formats are left to the caller, which adds subtask progress and ages.
+func writeTasksAs(w io.Writer, tasks []*models.Task, format string) (bool, error) {
+	switch format {
+	case output.FormatJSON, output.FormatCSV, output.FormatMarkdown:
+		return true, output.Formats.WriteTasks(w, format, tasks, defaultCSVOptions.formatOptions())
 	}
-
-	return b.String()
+	return false, nil
 }
 
-// formatTaskOneline formats a task for oneline output using compact format
-func formatTaskOneline(task *models.Task) string {
-	if !useColor {
-		return output.FormatTaskOneline(task)
-	}
-	return formatTaskCompact(task, false)
+// formatTaskGitStyle formats a task in git log style, with color when
+// enabled
+func (a *App) formatTaskGitStyle(task *models.Task, subtaskStats *SubtaskStats) string {
+	return a.taskFormatter(nil).GitStyle(task, subtaskStats)
 }
 
-// formatSubtask formats a subtask - wrapper for compatibility
-func formatSubtask(task *models.Task) string {
-	if !useColor {
-		return output.FormatSubtask(task)
-	}
-
-	// Keep the colored version here for now
-	// Build the main line: hash state kind(priority): title #tags
-	var mainParts []string
-
-	// Hash at the beginning (like git log)
-	hash := task.ShortHash()
-	if useColor {
-		hash = colorize(hash, colorYellow)
-	}
-	mainParts = append(mainParts, hash)
-
-	// State indicator
-	if useColor {
-		mainParts = append(mainParts, formatStateColor(task.State))
-	} else {
-		mainParts = append(mainParts, getStateEmoji(task.State))
-	}
+// formatTaskOneline formats a task for oneline output using compact format,
+// followed by when it was last updated
+func (a *App) formatTaskOneline(task *models.Task) string {
+	return a.formatTaskOnelineWithStats(task, nil)
+}
 
-	// kind(priority): format
-	kindPriority := fmt.Sprintf("%s(%s):", strings.ToLower(task.Kind), task.Priority)
-	if useColor {
-		kindPriority = formatKindPriorityColor(task.Kind, task.Priority)
-	}
-	mainParts = append(mainParts, kindPriority)
+// formatTaskOnelineWithStats formats a task for oneline output like
+// formatTaskOneline, with the progress of its subtasks
+func (a *App) formatTaskOnelineWithStats(task *models.Task, stats *SubtaskStats) string {
+	age := colorize("("+a.formatTimestamp(task.Updated)+")", colorGray)
+	return a.taskFormatter(nil).Oneline(task, stats) + " " + age
+}
 
-	// Title
-	title := task.Title
-	if useColor {
-		title = colorize(title, colorBold)
-	}
-	mainParts = append(mainParts, title)
+// relativeTimes reports whether timestamps are shown as "3 days ago"
+// instead of dates; see GTD_TIME_FORMAT
+func (a *App) relativeTimes() bool {
+	return a.config.TimeFormat != "absolute"
+}
 
-	// Tags with # prefix
-	if task.Tags != "" {
-		mainParts = append(mainParts, formatTagsColor(task.Tags))
+// formatTimestamp renders a timestamp for list views, relative or absolute
+// depending on configuration
+func (a *App) formatTimestamp(t time.Time) string {
+	if a.relativeTimes() {
+		return output.RelativeTime(t, time.Now())
 	}
+	return t.Local().Format("2006-01-02 15:04")
+}
 
-	// Blocked indicator
-	if task.IsBlocked() {
-		blocked := emojiBlocked
-		if useColor {
-			blocked = colorize(blocked, colorRed)
-		}
-		mainParts = append(mainParts, blocked)
+// formatDate renders a timestamp for detail views like formatTimestamp, but
+// absolute dates are shown in full as in git log
+func (a *App) formatDate(t time.Time) string {
+	if a.relativeTimes() {
+		return output.RelativeTime(t, time.Now())
 	}
-
-	// Build main line
-	return strings.Join(mainParts, " ")
+	return t.Format(time.RFC1123Z)
 }
 
-// getPriorityEmoji returns the emoji for a priority level
-func getPriorityEmoji(priority string) string {
-	switch priority {
-	case models.PriorityHigh:
-		return emojiHigh
-	case models.PriorityMedium:
-		return emojiMedium
-	case models.PriorityLow:
-		return emojiLow
-	default:
-		return "." // Period for unknown/default priority
+// formatBlockerChain renders a task's blockers nearest first, e.g.
+// "a1b2c3d ← e4f5g6h", marking chains that loop back on themselves
+func formatBlockerChain(chain []*models.Task, cyclic bool) string {
+	hashes := make([]string, len(chain))
+	for i, blocker := range chain {
+		hashes[i] = colorize(blocker.ShortHash(), colorYellow)
+	}
+	result := strings.Join(hashes, " ← ")
+	if cyclic {
+		result += colorize(" ← … (cycle)", colorRed)
 	}
+	return result
 }
 
-// getStateEmoji returns the emoji for a state
-func getStateEmoji(state string) string {
-	switch state {
-	case models.StateNew:
-		return emojiNew
-	case models.StateInProgress:
-		return emojiInProgress
-	case models.StateDone:
-		return emojiDone
-	case models.StateCancelled:
-		return emojiCancelled
-	default:
-		return "?" // Question mark
-	}
+// formatSubtask formats a subtask line of show, with color when enabled
+func (a *App) formatSubtask(task *models.Task) string {
+	return a.taskFormatter(nil).Subtask(task)
 }
 
 // formatTaskCount formats a count with proper pluralization
@@ -290,7 +108,7 @@ func formatTaskCount(count int, singular string) string {
 }
 
 // formatKind formats a task kind for display
-func formatKind(kind string) string {
+func formatKind(kind models.Kind) string {
 	switch kind {
 	case models.KindBug:
 		return "Bug"
@@ -299,47 +117,14 @@ func formatKind(kind string) string {
 	case models.KindRegression:
 		return "Regression"
 	default:
-		return kind
+		return kind.String()
 	}
 }
 
 // formatTaskList formats a list of tasks for output
-func formatTaskList(w io.Writer, tasks []*models.Task, oneline bool) {
-	formatter := output.NewFormatter(w)
-	if err := formatter.FormatTaskList(tasks, oneline); err != nil {
+func (a *App) formatTaskList(w io.Writer, tasks []*models.Task, oneline bool) {
+	if err := a.taskFormatter(w).FormatTaskList(tasks, oneline); err != nil {
 		// Ignore write errors for now
 		return
 	}
 }
-
-// formatKindPriorityColor formats kind(priority) with appropriate colors
-func formatKindPriorityColor(kind, priority string) string {
-	// Format the kind part
-	kindLower := strings.ToLower(kind)
-	var kindColored string
-	switch kind {
-	case models.KindBug:
-		kindColored = colorize(kindLower, colorRed)
-	case models.KindFeature:
-		kindColored = colorize(kindLower, colorGreen)
-	case models.KindRegression:
-		kindColored = colorize(kindLower, colorYellow)
-	default:
-		kindColored = kindLower
-	}
-
-	// Format the priority part
-	var priorityColored string
-	switch priority {
-	case models.PriorityHigh:
-		priorityColored = colorize(priority, colorBrightRed)
-	case models.PriorityMedium:
-		priorityColored = colorize(priority, colorYellow)
-	case models.PriorityLow:
-		priorityColored = colorize(priority, colorGreen)
-	default:
-		priorityColored = priority
-	}
-
-	return fmt.Sprintf("%s(%s): ", kindColored, priorityColored)
-}
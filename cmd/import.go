@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+// Conflict policies for tasks that already exist when importing
+const (
+	onConflictSkip      = "skip"
+	onConflictUpdate    = "update"
+	onConflictDuplicate = "duplicate"
+)
+
+// importSummary counts what an import did
+type importSummary struct {
+	created, updated, skipped int
+}
+
+// newImportCommand creates the import command
+func newImportCommand(app *App) *cobra.Command {
+	var (
+		onConflict string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import tasks from a JSON export",
+		Long: `Import tasks from a file written by 'gtd export --format json', or from
+stdin when FILE is "-". Gzip-compressed exports are detected automatically.
+
+A task already exists when a task with the same content hash (its ID) or the
+same external reference is in the database. --on-conflict decides what happens
+to it:
+  skip       keep the existing task (default)
+  update     overwrite the existing task with the imported fields, skipping
+             it when they are the same
+  duplicate  import it as a new task anyway
+
+Parents, blockers, links, and attachments are kept when the tasks they refer
+to exist after the import, and custom fields are always kept. Deletions in
+incremental exports are not applied.`,
+		Example: `  claude-gtd import tasks.json
+  claude-gtd import --on-conflict update backup.json.gz
+  claude-gtd export --format json | claude-gtd import -`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			onConflict = strings.ToLower(onConflict)
+			switch onConflict {
+			case onConflictSkip, onConflictUpdate, onConflictDuplicate:
+			default:
+				return errors.NewValidationError("invalid --on-conflict: %s (must be skip, update, or duplicate)", onConflict)
+			}
+
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open import file: %w", err)
+				}
+				defer func() { _ = file.Close() }()
+				in = file
+			}
+
+			items, err := readImportTasks(in)
+			if err != nil {
+				return err
+			}
+
+			summary, err := importTasks(items, onConflict, dryRun)
+			if err != nil {
+				return err
+			}
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %d tasks: %d created, %d updated, %d skipped\n",
+				verb, len(items), summary.created, summary.updated, summary.skipped)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&onConflict, "on-conflict", onConflictSkip, "What to do with tasks that already exist (skip, update, duplicate)")
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+// readImportTasks decodes a JSON export, either a plain task list or an
+// incremental export object, decompressing gzip input
+func readImportTasks(r io.Reader) ([]exportTask, error) {
+	in := bufio.NewReader(r)
+	if magic, err := in.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress import: %w", err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		in = bufio.NewReader(gzipReader)
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+
+	var items []exportTask
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var incremental struct {
+			Tasks []exportTask `json:"tasks"`
+		}
+		err = json.Unmarshal(trimmed, &incremental)
+		items = incremental.Tasks
+	} else {
+		err = json.Unmarshal(trimmed, &items)
+	}
+	if err != nil {
+		return nil, errors.NewValidationError("invalid import file: %v", err)
+	}
+	return items, nil
+}
+
+// importTasks imports tasks in two passes: the tasks themselves first, then
+// their references to other tasks, which may appear later in the file. The
+// whole file is checked before anything is written, so an invalid task does
+// not leave a partial import behind. New tasks are created together in one
+// transaction. With dryRun, the tasks are only checked and counted.
+func importTasks(items []exportTask, onConflict string, dryRun bool) (importSummary, error) {
+	var summary importSummary
+	targets := make(map[string]*models.Task, len(items)) // imported ID -> local task
+	imported := make([]*models.Task, len(items))
+	var created, updated []*models.Task
+	pending := make(map[string]*models.Task) // ID or external ref -> task to create
+
+	for i, item := range items {
+		task, err := taskFromImport(item)
+		if err != nil {
+			return summary, err
+		}
+
+		// Tasks yet to be created exist too, when the file repeats a task
+		existing, isPending := pending[item.ID], true
+		if existing == nil && item.ExternalRef != "" {
+			existing = pending["ref:"+item.ExternalRef]
+		}
+		if existing == nil {
+			isPending = false
+			if existing, err = repo.FindImportMatch(item.ID, item.ExternalRef); err != nil {
+				return summary, err
+			}
+		}
+
+		switch {
+		case existing == nil:
+			created = append(created, task)
+			summary.created++
+			addPendingImport(pending, task)
+		case onConflict == onConflictSkip:
+			targets[item.ID] = existing
+			summary.skipped++
+			continue
+		case onConflict == onConflictUpdate:
+			task.ID = existing.ID
+			task.Author = existing.Author
+			task.Parent = existing.Parent
+			task.BlockedBy = existing.BlockedBy
+			switch {
+			case isPending:
+				*existing = *task
+				task = existing
+				summary.updated++
+			case models.TaskChanged(existing, task):
+				updated = append(updated, task)
+				summary.updated++
+			default:
+				// Identical tasks are left alone, keeping their history and
+				// updated time, but still get the references in the file
+				task = existing
+				summary.skipped++
+			}
+		default:
+			if existing.ID == item.ID {
+				task.ID = models.NewTask(task.Kind, task.Title, task.Description).ID
+			}
+			created = append(created, task)
+			summary.created++
+			addPendingImport(pending, task)
+		}
+		targets[item.ID] = task
+		imported[i] = task
+	}
+
+	for i, task := range imported {
+		if task != nil {
+			if err := checkImport(task, items[i]); err != nil {
+				return summary, fmt.Errorf("failed to import %q: %w", items[i].Title, err)
+			}
+		}
+	}
+	if dryRun {
+		return summary, nil
+	}
+
+	if err := repo.CreateBatch(created); err != nil {
+		return summary, fmt.Errorf("failed to import tasks: %w", err)
+	}
+	for _, task := range updated {
+		if err := repo.Update(task); err != nil {
+			return summary, fmt.Errorf("failed to import %q: %w", task.Title, err)
+		}
+	}
+
+	// Local IDs are final once the tasks are created
+	ids := make(map[string]string, len(targets)) // imported ID -> local ID
+	for id, task := range targets {
+		ids[id] = task.ID
+	}
+
+	for i, task := range imported {
+		if task != nil {
+			if err := importReferences(task, items[i], ids); err != nil {
+				return summary, fmt.Errorf("failed to import %q: %w", items[i].Title, err)
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// addPendingImport indexes a task to be created by its ID and external
+// reference, so later tasks in the import match it like FindImportMatch
+func addPendingImport(pending map[string]*models.Task, task *models.Task) {
+	pending[task.ID] = task
+	if task.ExternalRef != "" {
+		pending["ref:"+task.ExternalRef] = task
+	}
+}
+
+// checkImport checks what importing a task writes, the task itself, its link
+// types, and its custom fields, the way the repository would
+func checkImport(task *models.Task, item exportTask) error {
+	if err := validateNewTask(task, models.ValidationRules{}); err != nil {
+		return err
+	}
+	for _, link := range item.Links {
+		if err := models.ValidateLinkType(link.Type); err != nil {
+			return err
+		}
+	}
+	for name, value := range item.Fields {
+		if err := models.ValidateField(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskFromImport builds a task from its JSON export representation
+func taskFromImport(item exportTask) (*models.Task, error) {
+	task := models.NewTask(models.Kind(item.Kind), item.Title, item.Description)
+	if item.ID != "" {
+		task.ID = item.ID
+	}
+	task.State = models.State(item.State)
+	task.Priority = models.Priority(item.Priority)
+	task.Tags = item.Tags
+	task.Source = item.Source
+	task.ExternalRef = item.ExternalRef
+	task.Severity = models.Severity(item.Severity)
+	task.IntroducedIn = item.IntroducedIn
+	task.Environment = item.Environment
+
+	for _, field := range []struct {
+		value  string
+		target *time.Time
+	}{
+		{item.CreatedAt, &task.Created},
+		{item.UpdatedAt, &task.Updated},
+	} {
+		if field.value == "" {
+			continue
+		}
+		at, err := output.ParseExportTime(field.value)
+		if err != nil {
+			return nil, errors.NewValidationError("invalid timestamp %q for %q", field.value, item.Title)
+		}
+		*field.target = at
+	}
+	return task, nil
+}
+
+// importReferences restores an imported task's parent, blocker, links,
+// attachments, and custom fields, mapping imported IDs to local ones. Links
+// and attachments the task already has are left alone; imported fields
+// replace the values the task has.
+func importReferences(task *models.Task, item exportTask, ids map[string]string) error {
+	resolve := func(id *string) *string {
+		if id == nil {
+			return nil
+		}
+		if local, ok := ids[*id]; ok {
+			return &local
+		}
+		if existing, err := repo.FindImportMatch(*id, ""); err == nil && existing != nil {
+			return &existing.ID
+		}
+		return nil
+	}
+
+	changed := false
+	if parent := resolve(item.Parent); parent != nil && (task.Parent == nil || *task.Parent != *parent) {
+		task.Parent = parent
+		changed = true
+	}
+	if blockedBy := resolve(item.BlockedBy); blockedBy != nil && (task.BlockedBy == nil || *task.BlockedBy != *blockedBy) {
+		task.BlockedBy = blockedBy
+		changed = true
+	}
+	if changed {
+		if err := repo.Update(task); err != nil {
+			return err
+		}
+	}
+
+	for _, link := range item.Links {
+		target := resolve(&link.Target)
+		if target == nil || *target == task.ID {
+			continue // Tasks merged by the import can't link to themselves
+		}
+		if _, err := repo.AddLink(task.ID, *target, link.Type); err != nil && errors.CodeOf(err) != errors.CodeConflict {
+			return err
+		}
+	}
+
+	for _, location := range item.Attachments {
+		if _, err := repo.AddAttachment(task.ID, location); err != nil && errors.CodeOf(err) != errors.CodeConflict {
+			return err
+		}
+	}
+
+	for _, name := range models.FieldNames(item.Fields) {
+		if err := repo.SetField(task.ID, name, item.Fields[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
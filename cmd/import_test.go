@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+func TestImportCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+
+	parent := models.NewTask(models.KindFeature, "Parent feature", "Parent description")
+	parent.State = models.StateNew
+	parent.ExternalRef = "PROJ-1"
+	if err := testRepo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := models.NewTask(models.KindBug, "Child bug", "Child description")
+	child.Parent = &parent.ID
+	if err := testRepo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testRepo.AddAttachment(child.ID, "docs/spec.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	var exported bytes.Buffer
+	exportCmd := newExportCommand(NewApp())
+	exportCmd.SetOut(&exported)
+	exportCmd.SetArgs([]string{"--format", "json"})
+	if err := exportCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+
+	runImport := func(t *testing.T, input string, args ...string) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newImportCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetArgs(append(args, "-"))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Run("into an empty database", func(t *testing.T) {
+		_, testRepo, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		out, err := runImport(t, exported.String())
+		if err != nil {
+			t.Fatalf("import error = %v", err)
+		}
+		if !strings.Contains(out, "2 created, 0 updated, 0 skipped") {
+			t.Errorf("Unexpected summary: %s", out)
+		}
+
+		imported, err := testRepo.GetByID(child.ID)
+		if err != nil {
+			t.Fatalf("Child not imported under its ID: %v", err)
+		}
+		if imported.Parent == nil || *imported.Parent != parent.ID {
+			t.Errorf("Expected parent %s, got %v", parent.ID, imported.Parent)
+		}
+		attachments, err := testRepo.GetAttachments(child.ID)
+		if err != nil || len(attachments) != 1 {
+			t.Errorf("Expected 1 attachment, got %d (%v)", len(attachments), err)
+		}
+		if !imported.Created.Equal(child.Created.Truncate(time.Millisecond)) {
+			t.Errorf("Expected created %v, got %v", child.Created, imported.Created)
+		}
+	})
+
+	t.Run("timestamps of older exports", func(t *testing.T) {
+		_, testRepo, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		// Older versions exported local time without a zone
+		legacy := strings.ReplaceAll(exported.String(), output.FormatExportTime(child.Created), "2024-01-02 03:04:05")
+		if _, err := runImport(t, legacy); err != nil {
+			t.Fatalf("import error = %v", err)
+		}
+		imported, err := testRepo.GetByID(child.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.Local); !imported.Created.Equal(want) {
+			t.Errorf("Expected created %v, got %v", want, imported.Created)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		_, testRepo, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		if _, err := runImport(t, exported.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := runImport(t, exported.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "0 created, 0 updated, 2 skipped") {
+			t.Errorf("Expected existing tasks to be skipped: %s", out)
+		}
+
+		// Updating with identical tasks writes nothing
+		before, err := testRepo.GetByID(child.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := testRepo.GetHistory(child.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err = runImport(t, exported.String(), "--on-conflict", "update")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "0 created, 0 updated, 2 skipped") {
+			t.Errorf("Expected identical tasks to be skipped: %s", out)
+		}
+		after, err := testRepo.GetByID(child.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !after.Updated.Equal(before.Updated) {
+			t.Errorf("Expected updated time %v to be kept, got %v", before.Updated, after.Updated)
+		}
+		if got, err := testRepo.GetHistory(child.ID); err != nil || len(got) != len(history) {
+			t.Errorf("Expected %d history entries, got %d (%v)", len(history), len(got), err)
+		}
+
+		// A task with a different hash but the same external reference is
+		// the same task
+		changed := strings.Replace(exported.String(), parent.ID, strings.Repeat("0", 40), -1)
+		changed = strings.Replace(changed, "Parent feature", "Renamed feature", 1)
+		out, err = runImport(t, changed, "--on-conflict", "update")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "0 created, 1 updated, 1 skipped") {
+			t.Errorf("Expected the renamed task to be updated: %s", out)
+		}
+		updated, err := testRepo.GetByID(parent.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Title != "Renamed feature" {
+			t.Errorf("Expected title to be updated, got %q", updated.Title)
+		}
+
+		out, err = runImport(t, exported.String(), "--on-conflict", "duplicate")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "2 created, 0 updated, 0 skipped") {
+			t.Errorf("Expected duplicates to be created: %s", out)
+		}
+		tasks, err := testRepo.List(models.ListOptions{All: true, ShowDone: true, ShowCancelled: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 4 {
+			t.Errorf("Expected 4 tasks after importing duplicates, got %d", len(tasks))
+		}
+	})
+
+	t.Run("invalid task writes nothing", func(t *testing.T) {
+		_, testRepo, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		if _, err := runImport(t, exported.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The parent comes first in the file and would be updated before
+		// the child is found to be invalid
+		changed := strings.Replace(exported.String(), "Parent feature", "Renamed feature", 1)
+		changed = strings.Replace(changed, `"BUG"`, `"BOGUS"`, 1)
+		if _, err := runImport(t, changed, "--on-conflict", "update"); err == nil {
+			t.Fatal("Expected error for an invalid task")
+		}
+
+		unchanged, err := testRepo.GetByID(parent.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if unchanged.Title != "Parent feature" {
+			t.Errorf("Expected the failed import to change nothing, got title %q", unchanged.Title)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		_, testRepo, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		out, err := runImport(t, exported.String(), "--dry-run")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "Would import 2 tasks: 2 created, 0 updated, 0 skipped") {
+			t.Errorf("Unexpected summary: %s", out)
+		}
+		tasks, err := testRepo.List(models.ListOptions{All: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 0 {
+			t.Errorf("Expected no tasks to be imported, got %d", len(tasks))
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, _, cleanup := setupTestCommand(t)
+		defer cleanup()
+
+		if _, err := runImport(t, exported.String(), "--on-conflict", "merge"); err == nil {
+			t.Error("Expected error for invalid --on-conflict")
+		}
+		if _, err := runImport(t, "not json"); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+}
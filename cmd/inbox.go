@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newInboxCommand creates the inbox command
+func newInboxCommand(app *App) *cobra.Command {
+	var oneline bool
+
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "List tasks in INBOX state",
+		Long: `List tasks waiting to be triaged in INBOX state, newest first, with the
+age of the oldest one. The same as 'gtd list --state INBOX'.
+
+When the inbox holds more than GTD_INBOX_LIMIT tasks (default 20), a reminder
+to run 'gtd review' is printed.`,
+		Example: `  claude-gtd inbox
+  claude-gtd inbox --oneline`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tasks, err := repo.ListByState(models.StateInbox)
+			if err != nil {
+				return fmt.Errorf("failed to list inbox tasks: %w", err)
+			}
+
+			if len(tasks) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Inbox is empty.")
+				return nil
+			}
+
+			formatTaskListWithStats(app, cmd.OutOrStdout(), tasks, oneline)
+			rememberRecentTasks(cmd, tasks)
+
+			oldest := tasks[0]
+			for _, task := range tasks[1:] {
+				if task.Created.Before(oldest.Created) {
+					oldest = task
+				}
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Oldest: %s %s, added %s\n",
+				colorize(oldest.ShortHash(), colorYellow), oldest.Title, app.formatTimestamp(oldest.Created))
+
+			// A limit of 0 disables the nudge
+			if limit := app.Config().InboxLimit; limit > 0 && len(tasks) > limit {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "\n⚠️  Your inbox has %d tasks, more than %d. Run 'gtd review' to triage them.\n", len(tasks), limit)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&oneline, "oneline", false, "Show tasks in compact format")
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestInboxCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	app := NewApp()
+	run := func(t *testing.T) (string, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		cmd := newInboxCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{"--oneline"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return stdout.String(), stderr.String()
+	}
+
+	if out, _ := run(t); !strings.Contains(out, "Inbox is empty.") {
+		t.Errorf("Expected empty inbox message, got: %s", out)
+	}
+
+	for i, title := range []string{"Oldest idea", "Newer idea", "Newest idea"} {
+		task := models.NewTask(models.KindFeature, title, "Idea description")
+		task.Created = time.Now().Add(-time.Duration(3-i) * 7 * 24 * time.Hour)
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	accepted := models.NewTask(models.KindBug, "Accepted bug", "Bug description")
+	accepted.State = models.StateNew
+	if err := testRepo.Create(accepted); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Config().InboxLimit = 3
+	out, errOut := run(t)
+	for _, want := range []string{"Newest idea", "3 tasks", "Oldest idea, added 3 weeks ago"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output does not contain %q\nGot: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "Accepted bug") {
+		t.Errorf("Inbox should not list NEW tasks\nGot: %s", out)
+	}
+	if errOut != "" {
+		t.Errorf("Expected no nudge at the limit, got: %s", errOut)
+	}
+
+	app.Config().InboxLimit = 2
+	if _, errOut := run(t); !strings.Contains(errOut, "inbox has 3 tasks, more than 2") {
+		t.Errorf("Expected nudge above the limit, got: %s", errOut)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newLinkCommand creates the link command
+func newLinkCommand(app *App) *cobra.Command {
+	var (
+		to       string
+		linkType string
+		remove   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "link TASK_ID --to OTHER_ID [--type relates|duplicates|causes|regresses]",
+		Short: "Link a task to another task",
+		Long: `Record a relationship between two tasks beyond parent and blocked-by:
+  relates     - the tasks are related (no direction)
+  duplicates  - TASK_ID duplicates OTHER_ID
+  causes      - TASK_ID causes OTHER_ID
+  regresses   - TASK_ID is a regression of OTHER_ID
+Links are shown by show on both tasks and included in JSON exports.`,
+		Example: `  gtd link abc123 --to def456
+  gtd link abc123 --to def456 --type duplicates
+  gtd link abc123 --to def456 --type causes --remove`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			linkType = strings.ToLower(linkType)
+
+			if remove {
+				if err := repo.RemoveLink(args[0], to, linkType); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s link\n", linkType)
+				return nil
+			}
+
+			link, err := repo.AddLink(args[0], to, linkType)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Linked: %s %s %s\n",
+				shortID(link.SourceID), link.Label(link.SourceID), shortID(link.TargetID))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "ID of the task to link to [required]")
+	// MarkFlagRequired panics on error, so we can safely ignore the return value
+	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().StringVar(&linkType, "type", models.LinkRelates, "Link type (relates, duplicates, causes, regresses)")
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove the link instead of adding it")
+
+	return cmd
+}
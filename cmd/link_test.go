@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestLinkCommand(t *testing.T) {
+	testRepo, cleanup := setupMemoryCommand(t)
+	defer cleanup()
+
+	cause := models.NewTask(models.KindRegression, "Cache rewrite", "Rewrote the cache layer")
+	effect := models.NewTask(models.KindBug, "Stale results", "Search shows stale results")
+	for _, task := range []*models.Task{cause, effect} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		contains string
+	}{
+		{
+			name:     "link with type",
+			args:     []string{cause.ID, "--to", effect.ID, "--type", "causes"},
+			contains: "causes " + effect.ShortHash(),
+		},
+		{
+			name:     "default type relates",
+			args:     []string{cause.ID, "--to", effect.ID},
+			contains: "relates to " + effect.ShortHash(),
+		},
+		{
+			name:    "invalid type",
+			args:    []string{cause.ID, "--to", effect.ID, "--type", "blocks"},
+			wantErr: true,
+		},
+		{
+			name:    "missing target",
+			args:    []string{cause.ID},
+			wantErr: true,
+		},
+		{
+			name:     "remove link",
+			args:     []string{effect.ID, "--to", cause.ID, "--remove"},
+			contains: "Removed relates link",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newLinkCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.contains) {
+				t.Errorf("Output does not contain %q\nGot: %s", tt.contains, stdout.String())
+			}
+		})
+	}
+
+	// Both sides show the link
+	for task, want := range map[*models.Task]string{cause: "causes", effect: "caused by"} {
+		var stdout bytes.Buffer
+		cmd := newShowCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{task.ID})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(stdout.String(), "Links:") || !strings.Contains(stdout.String(), want) {
+			t.Errorf("show %s missing %q link\nGot: %s", task.Title, want, stdout.String())
+		}
+	}
+
+	// JSON exports include the link once, on its source
+	var stdout bytes.Buffer
+	cmd := newExportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var exported []struct {
+		ID    string `json:"id"`
+		Links []struct {
+			Type   string `json:"type"`
+			Target string `json:"target"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, task := range exported {
+		for _, link := range task.Links {
+			total++
+			if task.ID != cause.ID || link.Type != models.LinkCauses || link.Target != effect.ID {
+				t.Errorf("Unexpected exported link %+v on %s", link, task.ID)
+			}
+		}
+	}
+	if total != 1 {
+		t.Errorf("Exported %d links, want 1", total)
+	}
+}
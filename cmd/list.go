@@ -5,53 +5,60 @@ import (
 	"io"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
 // List command flags
 type listFlags struct {
 	oneline  bool
+	output   string
 	all      bool
 	state    string
 	priority string
 	kind     string
+	severity string
+	release  string
+	env      string
 	tag      string
+	children bool
 	blocked  bool
 	limit    int
+	columns  string
+	fields   []string
 }
 
 // newListCommand creates the list command
-func newListCommand() *cobra.Command {
+func newListCommand(app *App) *cobra.Command {
 	var flags listFlags
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List tasks",
 		Long: `List tasks with various filtering options.
-By default, shows top 20 tasks (IN_PROGRESS first, then NEW), excluding DONE and CANCELLED tasks.`,
+By default, shows top 20 tasks (IN_PROGRESS first, then NEW), excluding DONE and CANCELLED tasks.
+With --blocked, each task shows its chain of blockers and the chain's depth.`,
 		Example: `  claude-gtd list
   claude-gtd list --oneline
+  claude-gtd list --output json
   claude-gtd list --all
   claude-gtd list --state NEW --priority high
   claude-gtd list --kind bug --tag backend
-  claude-gtd list --blocked`,
+  claude-gtd list --kind bug --severity critical
+  claude-gtd list --kind regression --introduced-in v2.1.0
+  claude-gtd list --blocked
+  claude-gtd list --field customer=acme
+  claude-gtd list --columns id,state,title,tags`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Validate filters
-			if err := validateListFlags(&flags); err != nil {
+			// Validate filters and build list options
+			opts, err := listOptions(&flags)
+			if err != nil {
 				return err
 			}
-
-			// Build list options
-			opts := models.ListOptions{
-				State:         flags.state,
-				Priority:      flags.priority,
-				Kind:          flags.kind,
-				Tag:           flags.tag,
-				Blocked:       flags.blocked,
-				All:           flags.all,
-				Limit:         flags.limit,
-				ShowDone:      flags.all || flags.state == models.StateDone,
-				ShowCancelled: flags.all || flags.state == models.StateCancelled,
+			format, err := app.resolveListFormat(flags.output, flags.oneline)
+			if err != nil {
+				return err
 			}
 
 			// List tasks
@@ -61,26 +68,56 @@ By default, shows top 20 tasks (IN_PROGRESS first, then NEW), excluding DONE and
 			}
 
 			// Format and output
-			formatTaskListWithStats(cmd.OutOrStdout(), tasks, flags.oneline)
+			if flags.columns != "" {
+				columns, err := parseColumns(flags.columns)
+				if err != nil {
+					return err
+				}
+				for _, task := range tasks {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatTaskColumns(app, task, columns))
+				}
+			} else if ok, err := writeTasksAs(cmd.OutOrStdout(), tasks, format); ok {
+				return err
+			} else if flags.blocked {
+				if err := formatBlockedTaskList(app, cmd.OutOrStdout(), tasks, format == output.FormatOneline); err != nil {
+					return err
+				}
+			} else {
+				formatTaskListWithStats(app, cmd.OutOrStdout(), tasks, format == output.FormatOneline)
+			}
+			rememberRecentTasks(cmd, tasks)
 
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&flags.oneline, "oneline", false, "Show tasks in compact format")
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "Output format: standard, oneline, json, csv, markdown [default: GTD_DEFAULT_FORMAT or standard]")
+	addListFilterFlags(cmd, &flags)
+	cmd.Flags().IntVar(&flags.limit, "limit", 20, "Maximum number of tasks to show")
+	cmd.Flags().StringVar(&flags.columns, "columns", "", "Show only these comma-separated columns, one task per line (e.g. id,state,title,tags)")
+
+	return cmd
+}
+
+// addListFilterFlags adds the flags that select tasks, shared by list and
+// count
+func addListFilterFlags(cmd *cobra.Command, flags *listFlags) {
 	cmd.Flags().BoolVar(&flags.all, "all", false, "Show all tasks including DONE and CANCELLED")
-	cmd.Flags().StringVar(&flags.state, "state", "", "Filter by state (NEW, IN_PROGRESS, DONE, CANCELLED)")
+	cmd.Flags().StringVar(&flags.state, "state", "", "Filter by state (INBOX, NEW, IN_PROGRESS, DONE, CANCELLED)")
 	cmd.Flags().StringVar(&flags.priority, "priority", "", "Filter by priority (high, medium, low)")
 	cmd.Flags().StringVar(&flags.kind, "kind", "", "Filter by kind (bug, feature, regression)")
+	cmd.Flags().StringVar(&flags.severity, "severity", "", "Filter by severity (critical, major, minor)")
+	cmd.Flags().StringVar(&flags.release, "introduced-in", "", "Filter by the release that introduced a regression")
+	cmd.Flags().StringVar(&flags.env, "environment", "", "Filter by the environment a regression was seen in")
 	cmd.Flags().StringVar(&flags.tag, "tag", "", "Filter by tag")
+	cmd.Flags().BoolVar(&flags.children, "include-children", false, "With --tag, also list the subtasks of tagged tasks")
 	cmd.Flags().BoolVar(&flags.blocked, "blocked", false, "Show only blocked tasks")
-	cmd.Flags().IntVar(&flags.limit, "limit", 20, "Maximum number of tasks to show")
-
-	return cmd
+	cmd.Flags().StringArrayVar(&flags.fields, "field", nil, "Filter by custom field as name=value, or name for any value (repeatable)")
 }
 
 // newListDoneCommand creates the list-done command
-func newListDoneCommand() *cobra.Command {
+func newListDoneCommand(app *App) *cobra.Command {
 	var oneline bool
 
 	cmd := &cobra.Command{
@@ -101,7 +138,8 @@ func newListDoneCommand() *cobra.Command {
 				return fmt.Errorf("failed to list done tasks: %w", err)
 			}
 
-			formatTaskListWithStats(cmd.OutOrStdout(), tasks, oneline)
+			formatTaskListWithStats(app, cmd.OutOrStdout(), tasks, oneline)
+			rememberRecentTasks(cmd, tasks)
 
 			return nil
 		},
@@ -113,13 +151,13 @@ func newListDoneCommand() *cobra.Command {
 }
 
 // newListCancelledCommand creates the list-cancelled command
-func newListCancelledCommand() *cobra.Command {
+func newListCancelledCommand(app *App) *cobra.Command {
 	var oneline bool
 
 	cmd := &cobra.Command{
 		Use:   "list-cancelled",
 		Short: "List cancelled tasks",
-		Long:  `List cancelled tasks.`,
+		Long:  `List cancelled tasks, with the reason they were cancelled when one was given.`,
 		Example: `  claude-gtd list-cancelled
   claude-gtd list-cancelled --oneline`,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -134,7 +172,10 @@ func newListCancelledCommand() *cobra.Command {
 				return fmt.Errorf("failed to list cancelled tasks: %w", err)
 			}
 
-			formatTaskListWithStats(cmd.OutOrStdout(), tasks, oneline)
+			if err := formatCancelledTaskList(app, cmd.OutOrStdout(), tasks, oneline); err != nil {
+				return err
+			}
+			rememberRecentTasks(cmd, tasks)
 
 			return nil
 		},
@@ -145,47 +186,66 @@ func newListCancelledCommand() *cobra.Command {
 	return cmd
 }
 
-// validateListFlags validates the list command flags
-func validateListFlags(flags *listFlags) error {
-	// Validate state
+// listOptions validates the list command flags and builds the list options
+// they select
+func listOptions(flags *listFlags) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		IntroducedIn:    flags.release,
+		Environment:     flags.env,
+		Tag:             flags.tag,
+		IncludeChildren: flags.children,
+		Blocked:         flags.blocked,
+		All:             flags.all,
+		Limit:           flags.limit,
+	}
+	var err error
+
+	if flags.children && flags.tag == "" {
+		return opts, errors.NewValidationError("--include-children requires --tag")
+	}
+
+	// Validate state; rejected tasks are listed by export, not list
 	if flags.state != "" {
-		switch flags.state {
-		case models.StateNew, models.StateInProgress, models.StateDone, models.StateCancelled:
-			// valid
-		default:
-			return fmt.Errorf("invalid state: %s (must be NEW, IN_PROGRESS, DONE, or CANCELLED)", flags.state)
+		if opts.State, err = models.ParseState(flags.state); err != nil {
+			return opts, err
+		}
+		if opts.State == models.StateInvalid {
+			return opts, errors.NewValidationError("invalid state: %s (must be INBOX, NEW, IN_PROGRESS, DONE, or CANCELLED)", flags.state)
 		}
 	}
+	opts.ShowDone = flags.all || opts.State == models.StateDone
+	opts.ShowCancelled = flags.all || opts.State == models.StateCancelled
 
 	// Validate priority
 	if flags.priority != "" {
-		switch flags.priority {
-		case models.PriorityHigh, models.PriorityMedium, models.PriorityLow:
-			// valid
-		default:
-			return fmt.Errorf("invalid priority: %s (must be high, medium, or low)", flags.priority)
+		if opts.Priority, err = models.ParsePriority(flags.priority); err != nil {
+			return opts, err
 		}
 	}
 
 	// Validate kind
 	if flags.kind != "" {
-		switch flags.kind {
-		case "bug":
-			flags.kind = models.KindBug
-		case "feature":
-			flags.kind = models.KindFeature
-		case "regression":
-			flags.kind = models.KindRegression
-		default:
-			return fmt.Errorf("invalid kind: %s (must be bug, feature, or regression)", flags.kind)
+		if opts.Kind, err = models.ParseKind(flags.kind); err != nil {
+			return opts, err
 		}
 	}
 
-	return nil
+	// Validate severity
+	if flags.severity != "" {
+		if opts.Severity, err = models.ParseSeverity(flags.severity); err != nil {
+			return opts, err
+		}
+	}
+
+	if opts.Fields, err = models.ParseFieldFilters(flags.fields); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
 }
 
 // formatTaskListWithStats formats and outputs a list of tasks with subtask stats
-func formatTaskListWithStats(w io.Writer, tasks []*models.Task, oneline bool) {
+func formatTaskListWithStats(app *App, w io.Writer, tasks []*models.Task, oneline bool) {
 	if len(tasks) == 0 {
 		if _, err := fmt.Fprintln(w, "No tasks found."); err != nil {
 			return
@@ -193,28 +253,25 @@ func formatTaskListWithStats(w io.Writer, tasks []*models.Task, oneline bool) {
 		return
 	}
 
+	// Count the subtasks of all listed parent tasks at once; progress is
+	// left out if they can't be counted
+	parentIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Parent == nil {
+			parentIDs = append(parentIDs, task.ID)
+		}
+	}
+	allStats, _ := repo.GetSubtaskStats(parentIDs)
+
 	for i, task := range tasks {
+		stats := allStats[task.ID]
 		if oneline {
-			if _, err := fmt.Fprintln(w, formatTaskOneline(task)); err != nil {
+			if _, err := fmt.Fprintln(w, app.formatTaskOnelineWithStats(task, stats)); err != nil {
 				return
 			}
 		} else {
-			// Get subtask stats for the task
-			var stats *SubtaskStats
-			if task.Parent == nil { // Only get subtasks for parent tasks
-				subtasks, err := repo.GetChildren(task.ID)
-				if err == nil && len(subtasks) > 0 {
-					stats = &SubtaskStats{Total: len(subtasks)}
-					for _, st := range subtasks {
-						if st.State == models.StateDone {
-							stats.Done++
-						}
-					}
-				}
-			}
-
 			// Use git-style format
-			if _, err := fmt.Fprint(w, formatTaskGitStyle(task, stats)); err != nil {
+			if _, err := fmt.Fprint(w, app.formatTaskGitStyle(task, stats)); err != nil {
 				return
 			}
 			// Add blank line between tasks
@@ -231,3 +288,70 @@ func formatTaskListWithStats(w io.Writer, tasks []*models.Task, oneline bool) {
 		return
 	}
 }
+
+// formatBlockedTaskList outputs blocked tasks with their blocker chains and
+// chain depth
+func formatBlockedTaskList(app *App, w io.Writer, tasks []*models.Task, oneline bool) error {
+	if len(tasks) == 0 {
+		_, _ = fmt.Fprintln(w, "No tasks found.")
+		return nil
+	}
+
+	for i, task := range tasks {
+		chain, cyclic, err := repo.GetBlockerChain(task.ID)
+		if err != nil {
+			return err
+		}
+		depth := colorize(fmt.Sprintf("depth %d", len(chain)), colorRed)
+
+		if oneline {
+			_, _ = fmt.Fprintf(w, "%s [%s]\n", app.formatTaskOneline(task), depth)
+			continue
+		}
+
+		_, _ = fmt.Fprint(w, app.formatTaskGitStyle(task, nil))
+		_, _ = fmt.Fprintf(w, "    Chain: %s (%s)\n", formatBlockerChain(chain, cyclic), depth)
+		if i < len(tasks)-1 {
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%s\n", formatTaskCount(len(tasks), "task"))
+	return nil
+}
+
+// formatCancelledTaskList outputs cancelled tasks with the reason recorded
+// when they were cancelled
+func formatCancelledTaskList(app *App, w io.Writer, tasks []*models.Task, oneline bool) error {
+	if len(tasks) == 0 {
+		_, _ = fmt.Fprintln(w, "No tasks found.")
+		return nil
+	}
+
+	for i, task := range tasks {
+		reason, err := repo.GetReason(task.ID)
+		if err != nil {
+			return err
+		}
+
+		if oneline {
+			line := app.formatTaskOneline(task)
+			if reason != "" {
+				line += " " + colorize("— "+reason, colorGray)
+			}
+			_, _ = fmt.Fprintln(w, line)
+			continue
+		}
+
+		_, _ = fmt.Fprint(w, app.formatTaskGitStyle(task, nil))
+		if reason != "" {
+			_, _ = fmt.Fprintf(w, "\n    Reason: %s\n", reason)
+		}
+		if i < len(tasks)-1 {
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%s\n", formatTaskCount(len(tasks), "task"))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
@@ -14,10 +15,10 @@ func TestListCommand(t *testing.T) {
 
 	// Create test tasks with various states and priorities
 	tasks := []struct {
-		kind      string
+		kind      models.Kind
 		title     string
-		state     string
-		priority  string
+		state     models.State
+		priority  models.Priority
 		source    string
 		tags      string
 		blockedBy *string
@@ -78,7 +79,8 @@ func TestListCommand(t *testing.T) {
 			args: []string{"--oneline"},
 			contains: []string{
 				"High priority bug in progress",
-				"▶", // IN_PROGRESS symbol
+				"▶",          // IN_PROGRESS symbol
+				"(just now)", // last updated
 			},
 			notContains: []string{
 				"Source:",
@@ -145,12 +147,38 @@ func TestListCommand(t *testing.T) {
 			contains: []string{
 				"Blocked feature",
 				"Blocked-by:",
+				"Chain: " + createdTasks[0].ShortHash() + " (depth 1)",
 			},
 			notContains: []string{
 				"High priority bug in progress",
 				"Medium priority feature",
 			},
 		},
+		{
+			name: "show blocked tasks oneline with chain depth",
+			args: []string{"--blocked", "--oneline"},
+			contains: []string{
+				"Blocked feature",
+				"[depth 1]",
+			},
+		},
+		{
+			name: "selected columns",
+			args: []string{"--columns", "id,title,tags"},
+			contains: []string{
+				createdTasks[0].ShortHash() + "\tHigh priority bug in progress\t",
+				"Medium priority feature",
+			},
+			notContains: []string{
+				"Description for",
+				"tasks",
+			},
+		},
+		{
+			name:    "unknown column",
+			args:    []string{"--columns", "id,due"},
+			wantErr: true,
+		},
 		{
 			name:    "invalid state filter",
 			args:    []string{"--state", "INVALID"},
@@ -172,7 +200,7 @@ func TestListCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newListCommand()
+			cmd := newListCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -223,7 +251,7 @@ func TestListDoneCommand(t *testing.T) {
 	}
 
 	var stdout bytes.Buffer
-	cmd := newListDoneCommand()
+	cmd := newListDoneCommand(NewApp())
 	cmd.SetOut(&stdout)
 
 	if err := cmd.Execute(); err != nil {
@@ -256,6 +284,9 @@ func TestListCancelledCommand(t *testing.T) {
 	if err := testRepo.Create(cancelledTask); err != nil {
 		t.Fatal(err)
 	}
+	if err := testRepo.RecordReason(cancelledTask.ID, "fixed upstream"); err != nil {
+		t.Fatal(err)
+	}
 
 	activeTask := models.NewTask(models.KindBug, "Active bug", "This bug is currently active")
 	if err := testRepo.Create(activeTask); err != nil {
@@ -263,7 +294,7 @@ func TestListCancelledCommand(t *testing.T) {
 	}
 
 	var stdout bytes.Buffer
-	cmd := newListCancelledCommand()
+	cmd := newListCancelledCommand(NewApp())
 	cmd.SetOut(&stdout)
 
 	if err := cmd.Execute(); err != nil {
@@ -276,9 +307,141 @@ func TestListCancelledCommand(t *testing.T) {
 	if !strings.Contains(output, "Cancelled bug") {
 		t.Error("Output should contain 'Cancelled bug'")
 	}
+	if !strings.Contains(output, "Reason: fixed upstream") {
+		t.Error("Output should contain the cancellation reason")
+	}
 
 	// Should not contain active task
 	if strings.Contains(output, "Active bug") {
 		t.Error("Output should not contain 'Active bug'")
 	}
 }
+
+func TestListSubtaskProgress(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	parent := models.NewTask(models.KindFeature, "Parent feature", "Parent description")
+	parent.State = models.StateNew
+	if err := testRepo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	for i, state := range []models.State{models.StateDone, models.StateNew, models.StateInProgress} {
+		child := models.NewTask(models.KindBug, "Subtask "+string(rune('A'+i)), "Subtask description")
+		child.Parent = &parent.ID
+		child.State = state
+		if err := testRepo.Create(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"--oneline"}, {}} {
+		var stdout bytes.Buffer
+		cmd := newListCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		if !strings.Contains(stdout.String(), "Parent feature [1/3]") {
+			t.Errorf("Expected subtask progress with %v:\n%s", args, stdout.String())
+		}
+	}
+}
+
+func TestDefaultFormat(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	app := NewApp()
+
+	task := models.NewTask(models.KindBug, "Fix login crash", "Crashes on submit")
+	task.State = models.StateNew
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(cmd *cobra.Command, args ...string) string {
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%s %v: %v", cmd.Name(), args, err)
+		}
+		return stdout.String()
+	}
+	isJSON := func(out string) bool {
+		return strings.HasPrefix(strings.TrimSpace(out), "[") || strings.HasPrefix(strings.TrimSpace(out), "{")
+	}
+
+	app.Config().DefaultFormat = "json"
+	for name, out := range map[string]string{
+		"list":   run(newListCommand(app)),
+		"search": run(newSearchCommand(app), "login"),
+		"show":   run(newShowCommand(app), task.ID),
+		"export": run(newExportCommand(app)),
+	} {
+		if !isJSON(out) {
+			t.Errorf("%s with GTD_DEFAULT_FORMAT=json printed %q", name, out)
+		}
+	}
+
+	// Flags override the default
+	if out := run(newListCommand(app), "--oneline"); isJSON(out) || strings.Contains(out, "Crashes on submit") {
+		t.Errorf("list --oneline printed %q", out)
+	}
+	if out := run(newShowCommand(app), task.ID, "-o", "standard"); !strings.Contains(out, "Crashes on submit") || isJSON(out) {
+		t.Errorf("show -o standard printed %q", out)
+	}
+	if out := run(newExportCommand(app), "--format", "csv"); !strings.HasPrefix(out, "ID,") {
+		t.Errorf("export --format csv printed %q", out)
+	}
+
+	// Commands without the default format use their own
+	app.Config().DefaultFormat = "oneline"
+	if out := run(newSearchCommand(app), "login"); !strings.Contains(out, "Fix login crash") || strings.Contains(out, "Crashes on submit") {
+		t.Errorf("search with GTD_DEFAULT_FORMAT=oneline printed %q", out)
+	}
+	if out := run(newExportCommand(app)); !isJSON(out) {
+		t.Errorf("export with GTD_DEFAULT_FORMAT=oneline printed %q", out)
+	}
+
+	// Formats a command doesn't support are rejected
+	cmd := newShowCommand(app)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{task.ID, "-o", "xml"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected show -o xml to fail")
+	}
+}
+
+func TestListRecentTasksWarning(t *testing.T) {
+	testDB, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Fix login crash", "Crashes on submit")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	// Without the settings table the listed tasks can't be remembered
+	if _, err := testDB.Exec("DROP TABLE settings"); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := newListCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--all"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Fix login crash") {
+		t.Errorf("Expected the task to be listed, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Warning: failed to remember listed tasks") {
+		t.Errorf("Expected the warning on the command's error output, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+// annotationSkipSchema marks commands that open the database without
+// creating or migrating its schema
+const annotationSkipSchema = "gtd/skip-schema"
+
+// newMigrateCommand creates the migrate command
+func newMigrateCommand(app *App) *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Bring the database schema up to date",
+		Long: `Apply the schema migrations that an older database is missing.
+
+Every command migrates the database it opens by default. With
+GTD_AUTO_MIGRATE=false, commands refuse to run on a database that needs
+migrating and list the pending migrations instead, so that a shared database
+is only changed on purpose.
+
+With --check, the pending migrations are listed without applying them, and
+the command fails when there are any.`,
+		Example: `  claude-gtd migrate --check
+  claude-gtd migrate`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationSkipSchema: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if check {
+				pending, err := db.PendingMigrations()
+				if err != nil {
+					return err
+				}
+				if len(pending) == 0 {
+					return printSchemaUpToDate(cmd)
+				}
+				_, _ = fmt.Fprintf(out, "%d pending migrations:\n%s\n", len(pending), formatMigrations(pending))
+				return fmt.Errorf("the database needs %d migrations; run 'gtd migrate' to apply them", len(pending))
+			}
+
+			applied, err := db.Migrate()
+			if len(applied) > 0 {
+				_, _ = fmt.Fprintf(out, "Applied %d migrations:\n%s\n", len(applied), formatMigrations(applied))
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				return printSchemaUpToDate(cmd)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "List pending migrations without applying them")
+
+	return cmd
+}
+
+// printSchemaUpToDate reports that no migrations are pending
+func printSchemaUpToDate(cmd *cobra.Command) error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Database is up to date (schema version %d)\n", version)
+	return nil
+}
+
+// formatMigrations lists migrations one per line with their versions
+func formatMigrations(migrations []database.Migration) string {
+	lines := make([]string, len(migrations))
+	for i, m := range migrations {
+		lines[i] = fmt.Sprintf("  %3d  %s", m.Version, m.Description)
+	}
+	return strings.Join(lines, "\n")
+}
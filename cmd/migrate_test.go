@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+func TestMigrateCommand(t *testing.T) {
+	testDB, _, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newMigrateCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run("--check")
+	if err != nil {
+		t.Fatalf("migrate --check error = %v", err)
+	}
+	if !strings.Contains(out, "Database is up to date") {
+		t.Errorf("Expected a new database to be up to date: %s", out)
+	}
+
+	// Pretend the last migrations haven't run
+	if _, err := testDB.DB.Exec("PRAGMA user_version = 11"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = run("--check")
+	if err == nil {
+		t.Error("Expected migrate --check to fail with pending migrations")
+	}
+	if !strings.Contains(out, "2 pending migrations") || !strings.Contains(out, "Add regression links") {
+		t.Errorf("Expected the pending migrations to be listed: %s", out)
+	}
+
+	out, err = run()
+	if err != nil {
+		t.Fatalf("migrate error = %v", err)
+	}
+	if !strings.Contains(out, "Applied 2 migrations") {
+		t.Errorf("Expected the migrations to be applied: %s", out)
+	}
+	if pending, _ := testDB.PendingMigrations(); len(pending) != 0 {
+		t.Errorf("Expected no pending migrations after migrate, got %d", len(pending))
+	}
+}
+
+func TestAutoMigrateOff(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	old, err := database.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := old.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.DB.Exec("PRAGMA user_version = 11"); err != nil {
+		t.Fatal(err)
+	}
+	if err := old.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GTD_DATABASE_PATH", dbPath)
+	t.Setenv("GTD_AUTO_MIGRATE", "false")
+	app := NewApp()
+	err = app.Initialize()
+	if err == nil {
+		_ = app.Close()
+		t.Fatal("Expected Initialize to refuse a database with pending migrations")
+	}
+	if !strings.Contains(err.Error(), "Add regression links") || !strings.Contains(err.Error(), "gtd migrate") {
+		t.Errorf("Expected the pending migrations in the error: %v", err)
+	}
+
+	app = NewApp()
+	app.SetSkipSchema(true)
+	if err := app.Initialize(); err != nil {
+		t.Fatalf("Expected the migrate command to open the database: %v", err)
+	}
+	_ = app.Close()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// newMoveCommand creates the move command
+func newMoveCommand(app *App) *cobra.Command {
+	var (
+		before string
+		top    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "move TASK_ID (--before OTHER_ID | --top)",
+		Short: "Rank a task within its priority",
+		Long: `Order tasks of the same priority deliberately.
+With --before, the task is listed directly above OTHER_ID, which must have the
+same priority. With --top, it is listed first among the tasks of its priority.
+
+Lists are ordered by state, then priority, then rank. Tasks that were never
+moved come after ranked tasks of the same priority, newest first.`,
+		Example: `  gtd move abc123 --top
+  gtd move abc123 --before def456`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if top == (before != "") {
+				return errors.NewValidationError("use exactly one of --before or --top")
+			}
+
+			if top {
+				task, err := repo.MoveToTop(args[0])
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Moved task %s to the top of %s priority tasks\n",
+					colorize(task.ShortHash(), colorYellow), task.Priority)
+				return nil
+			}
+
+			task, other, err := repo.MoveBefore(args[0], before)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Moved task %s before %s\n",
+				colorize(task.ShortHash(), colorYellow), colorize(other.ShortHash(), colorYellow))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&before, "before", "", "Rank the task directly above this task")
+	cmd.Flags().BoolVar(&top, "top", false, "Rank the task first within its priority")
+
+	return cmd
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestMoveCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	var tasks []*models.Task
+	for _, title := range []string{"First", "Second"} {
+		task := models.NewTask(models.KindFeature, title, "Task to rank")
+		task.State = models.StateNew
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		want    string
+	}{
+		{
+			name: "top",
+			args: []string{tasks[0].ID, "--top"},
+			want: "Moved task " + tasks[0].ShortHash() + " to the top of medium priority tasks",
+		},
+		{
+			name: "before",
+			args: []string{tasks[1].ID, "--before", tasks[0].ID},
+			want: "Moved task " + tasks[1].ShortHash() + " before " + tasks[0].ShortHash(),
+		},
+		{
+			name:    "no position",
+			args:    []string{tasks[0].ID},
+			wantErr: true,
+		},
+		{
+			name:    "both positions",
+			args:    []string{tasks[0].ID, "--top", "--before", tasks[1].ID},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newMoveCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("Output = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/git"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/notify"
+	"github.com/zw3rk/gtd/internal/services"
+)
+
+// notifyListener posts the task events the webhook of GTD_WEBHOOK_URL is
+// subscribed to, writing warnings about failed posts to w
+func notifyListener(app *App, w io.Writer) services.Listener {
+	cfg := app.Config()
+	var webhook *notify.Webhook // nil when GTD_WEBHOOK_URL is unset
+	if cfg.WebhookURL != "" {
+		webhook = notify.NewWebhook(cfg.WebhookURL, cfg.WebhookEvents)
+	}
+	return func(event services.Event) {
+		switch e := event.(type) {
+		case services.TaskCreated:
+			notifyTaskCreated(w, webhook, cfg.InboxLimit, e.Task)
+		case services.StateChanged:
+			if e.To == models.StateDone {
+				notifyTaskDone(w, webhook, e.Task)
+			}
+		}
+	}
+}
+
+// notifyTaskCreated posts a new high-priority task, and posts when the new
+// task grows the inbox past inboxLimit
+func notifyTaskCreated(w io.Writer, webhook *notify.Webhook, inboxLimit int, task *models.Task) {
+	if webhook.Wants(notify.EventHighPriority) && task.Priority == models.PriorityHigh {
+		postNotification(w, webhook, fmt.Sprintf("High-priority %s added: %s (`%s`)", strings.ToLower(task.Kind.String()), task.Title, task.ShortHash()))
+	}
+
+	if webhook.Wants(notify.EventInboxFull) && task.State == models.StateInbox && inboxLimit > 0 {
+		inbox, err := repo.ListByState(models.StateInbox)
+		if err != nil {
+			return
+		}
+		// Post once, when the limit is crossed, rather than for every task
+		// added to a full inbox
+		if len(inbox) == inboxLimit+1 {
+			postNotification(w, webhook, fmt.Sprintf("The inbox has %d tasks, more than %d; run `gtd review` to triage them", len(inbox), inboxLimit))
+		}
+	}
+}
+
+// notifyTaskDone posts a task that was marked as done
+func notifyTaskDone(w io.Writer, webhook *notify.Webhook, task *models.Task) {
+	if webhook.Wants(notify.EventDone) {
+		postNotification(w, webhook, fmt.Sprintf("Done: %s (`%s`)", task.Title, task.ShortHash()))
+	}
+}
+
+// postNotification posts a message prefixed with the project name. Failures
+// are warnings: the change itself has already been made.
+func postNotification(w io.Writer, webhook *notify.Webhook, text string) {
+	if root, err := git.FindGitRoot("."); err == nil {
+		text = fmt.Sprintf("[%s] %s", filepath.Base(root), text)
+	}
+	if err := webhook.Post(text); err != nil {
+		_, _ = fmt.Fprintf(w, "Warning: failed to send notification: %v\n", err)
+	}
+}
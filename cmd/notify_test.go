@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/notify"
+)
+
+func TestNotifications(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		messages = append(messages, payload["text"])
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	app := NewApp()
+	app.Config().WebhookURL = server.URL
+	app.Config().InboxLimit = 1
+
+	take := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := messages
+		messages = nil
+		return taken
+	}
+
+	cmd := newDoneCommand(app)
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	service := newTaskService(app, cmd)
+
+	// A high-priority task that crosses the inbox limit posts both events
+	first := models.NewTask(models.KindBug, "Login crash", "Crashes on submit")
+	second := models.NewTask(models.KindBug, "Logout crash", "Crashes on logout")
+	second.Priority = models.PriorityHigh
+	for _, task := range []*models.Task{first, second} {
+		if err := service.CreateTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := take()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 messages, got %q", got)
+	}
+	if !strings.Contains(got[0], "High-priority bug added: Logout crash") {
+		t.Errorf("Unexpected high-priority message: %q", got[0])
+	}
+	if !strings.Contains(got[1], "The inbox has 2 tasks, more than 1") {
+		t.Errorf("Unexpected inbox message: %q", got[1])
+	}
+
+	// A third task doesn't post the inbox event again
+	third := models.NewTask(models.KindBug, "Signup crash", "Crashes on signup")
+	if err := service.CreateTask(third); err != nil {
+		t.Fatal(err)
+	}
+	if got := take(); len(got) != 0 {
+		t.Errorf("Expected no messages, got %q", got)
+	}
+
+	// Marking a task as done posts it
+	for _, state := range []models.State{models.StateNew, models.StateInProgress} {
+		if err := testRepo.UpdateState(first.ID, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{first.ID})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	got = take()
+	if len(got) != 1 || !strings.Contains(got[0], "Done: Login crash") {
+		t.Errorf("Unexpected done messages: %q", got)
+	}
+
+	// Unsubscribed events post nothing
+	app.Config().WebhookEvents = []string{notify.EventInboxFull}
+	service = newTaskService(app, cmd)
+	high := models.NewTask(models.KindBug, "Payment crash", "Crashes on checkout")
+	high.Priority = models.PriorityHigh
+	high.State = models.StateNew
+	if err := service.CreateTask(high); err != nil {
+		t.Fatal(err)
+	}
+	if got := take(); len(got) != 0 {
+		t.Errorf("Expected no messages, got %q", got)
+	}
+
+	// A failing webhook only warns
+	server.Close()
+	notifyTaskDone(&stderr, notify.NewWebhook(server.URL, notify.Events), first)
+	if !strings.Contains(stderr.String(), "Warning: failed to send notification") {
+		t.Errorf("Expected a warning, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/git"
+)
+
+// runEditor runs an editor command attached to the terminal; replaced in tests
+var runEditor = func(args []string) error {
+	editor := exec.Command(args[0], args[1:]...)
+	editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return editor.Run()
+}
+
+// newOpenCommand creates the open command
+func newOpenCommand(app *App) *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open TASK_ID",
+		Short: "Open a task's source location in your editor",
+		Long: `Open the file in a task's source (set with --source or --file) in the
+editor from $VISUAL or $EDITOR, at the source's line when it has one.
+
+Sources are read as path:line, with the path relative to the repository root.
+The line is passed the way the editor expects it, for example +42 for vim,
+emacs, and nano, --goto for VS Code, and path:42 for Sublime Text, Zed, and
+Helix. Other editors just open the file.
+
+With --print, the editor command is printed instead of run, for editors that
+run outside the terminal or for scripts.`,
+		Example: `  gtd open abc123
+  gtd open @current --print`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			path, line, err := parseSourceLocation(task.Source)
+			if err != nil {
+				return fmt.Errorf("task %s: %w", task.ShortHash(), err)
+			}
+
+			editor := strings.Fields(app.Config().Editor)
+			if len(editor) == 0 {
+				return fmt.Errorf("no editor configured (set EDITOR or VISUAL)")
+			}
+			command := append(editor, editorJumpArgs(editor[0], path, line)...)
+
+			if printOnly {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatShellCommand(command))
+				return nil
+			}
+			if err := runEditor(command); err != nil {
+				return fmt.Errorf("editor %s failed: %w", editor[0], err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the editor command instead of running it")
+
+	return cmd
+}
+
+// parseSourceLocation resolves a path[:line] source to a file in the
+// repository. The line is 0 when the source has none. Sources such as
+// "app.go:abc123" with a non-numeric suffix open the file without a line.
+func parseSourceLocation(source string) (path string, line int, err error) {
+	if source == "" {
+		return "", 0, errors.NewNotFoundError("no source set")
+	}
+
+	root, err := git.FindGitRoot(".")
+	if err != nil {
+		root = "."
+	}
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(root, filepath.FromSlash(p))
+	}
+	isFile := func(p string) bool {
+		info, err := os.Stat(p)
+		return err == nil && !info.IsDir()
+	}
+
+	if i := strings.LastIndex(source, ":"); i > 0 {
+		if p := resolve(source[:i]); isFile(p) {
+			if n, err := strconv.Atoi(source[i+1:]); err == nil && n > 0 {
+				return p, n, nil
+			}
+			return p, 0, nil
+		}
+	}
+	if p := resolve(source); isFile(p) {
+		return p, 0, nil
+	}
+	return "", 0, errors.NewNotFoundError("source %q is not a file in the repository", source)
+}
+
+// editorJumpArgs returns the arguments that open path at line in the given
+// editor, falling back to just the path for editors without known syntax
+func editorJumpArgs(editor, path string, line int) []string {
+	if line == 0 {
+		return []string{path}
+	}
+
+	switch strings.TrimSuffix(filepath.Base(editor), ".exe") {
+	case "vi", "vim", "nvim", "gvim", "nano", "emacs", "emacsclient", "micro", "kak", "joe", "mg":
+		return []string{"+" + strconv.Itoa(line), path}
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", path + ":" + strconv.Itoa(line)}
+	case "subl", "zed", "hx", "helix":
+		return []string{path + ":" + strconv.Itoa(line)}
+	case "idea", "goland", "pycharm", "webstorm":
+		return []string{"--line", strconv.Itoa(line), path}
+	}
+	return []string{path}
+}
+
+// formatShellCommand joins a command's arguments for a POSIX shell, quoting
+// those that need it
+func formatShellCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
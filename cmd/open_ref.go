@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// openBrowser opens a URL in the user's browser; replaced in tests
+var openBrowser = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// newOpenRefCommand creates the open-ref command
+func newOpenRefCommand(app *App) *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open-ref TASK_ID",
+		Short: "Open a task's external reference in a browser",
+		Long: `Open the external reference of a task (set with --ref on 'gtd add' or
+'gtd set') in the default browser.
+URLs are opened as-is. Ticket keys such as PROJ-123 are expanded using
+GTD_REF_URL_TEMPLATE, for example https://jira.example.com/browse/%s.`,
+		Example: `  gtd open-ref abc123
+  gtd open-ref abc123 --print`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			url, err := resolveRefURL(task.ExternalRef, app.Config().RefURLTemplate)
+			if err != nil {
+				return fmt.Errorf("task %s: %w", task.ShortHash(), err)
+			}
+
+			if printOnly {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), url)
+				return nil
+			}
+			if err := openBrowser(url); err != nil {
+				return fmt.Errorf("failed to open %s: %w", url, err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Opened %s\n", url)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the URL instead of opening it")
+
+	return cmd
+}
+
+// resolveRefURL returns the URL for an external reference, expanding ticket
+// keys with template, in which %s is replaced by the key
+func resolveRefURL(ref, template string) (string, error) {
+	switch {
+	case ref == "":
+		return "", errors.NewNotFoundError("no external reference set")
+	case isURL(ref):
+		return ref, nil
+	case template == "":
+		return "", fmt.Errorf("reference %q is not a URL; set GTD_REF_URL_TEMPLATE to open ticket keys", ref)
+	default:
+		return strings.ReplaceAll(template, "%s", ref), nil
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestOpenRefCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	var opened []string
+	defer func(orig func(string) error) { openBrowser = orig }(openBrowser)
+	openBrowser = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	withURL := models.NewTask(models.KindBug, "Crash on start", "The app crashes")
+	withURL.ExternalRef = "https://github.com/org/repo/issues/42"
+	withKey := models.NewTask(models.KindBug, "Slow search", "Search takes seconds")
+	withKey.ExternalRef = "PROJ-123"
+	without := models.NewTask(models.KindFeature, "Dark mode", "Add a dark theme")
+	for _, task := range []*models.Task{withURL, withKey, without} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		wantErr  bool
+		wantURL  string
+	}{
+		{
+			name:    "URL opened as-is",
+			args:    []string{withURL.ID},
+			wantURL: "https://github.com/org/repo/issues/42",
+		},
+		{
+			name:     "ticket key expanded with template",
+			template: "https://jira.example.com/browse/%s",
+			args:     []string{withKey.ID},
+			wantURL:  "https://jira.example.com/browse/PROJ-123",
+		},
+		{
+			name:    "ticket key without template",
+			args:    []string{withKey.ID},
+			wantErr: true,
+		},
+		{
+			name:    "no reference",
+			args:    []string{without.ID},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened = nil
+			app := NewApp()
+			app.Config().RefURLTemplate = tt.template
+
+			var stdout, stderr bytes.Buffer
+			cmd := newOpenRefCommand(app)
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(opened) != 1 || opened[0] != tt.wantURL {
+				t.Errorf("opened = %v, want [%s]", opened, tt.wantURL)
+			}
+		})
+	}
+
+	// --print shows the URL without opening it
+	opened = nil
+	var stdout bytes.Buffer
+	cmd := newOpenRefCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{withURL.ID, "--print"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 0 {
+		t.Errorf("--print opened %v", opened)
+	}
+	if strings.TrimSpace(stdout.String()) != withURL.ExternalRef {
+		t.Errorf("--print output = %q", stdout.String())
+	}
+
+	// show displays the reference
+	stdout.Reset()
+	show := newShowCommand(NewApp())
+	show.SetOut(&stdout)
+	show.SetArgs([]string{withKey.ID})
+	if err := show.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Ref: PROJ-123") {
+		t.Errorf("show output does not contain ref\nGot: %s", stdout.String())
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestOpenCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+	app := NewApp()
+
+	var ran [][]string
+	defer func(orig func([]string) error) { runEditor = orig }(runEditor)
+	runEditor = func(args []string) error {
+		ran = append(ran, args)
+		return nil
+	}
+
+	withLine := models.NewTask(models.KindBug, "Crash in open", "Fails on missing files")
+	withLine.Source = "cmd/open.go:42"
+	withCommit := models.NewTask(models.KindBug, "Wrong root", "Uses the wrong directory")
+	withCommit.Source = "cmd/open.go:abc1234"
+	missing := models.NewTask(models.KindBug, "Gone", "The file was deleted")
+	missing.Source = "no/such/file.go:3"
+	without := models.NewTask(models.KindFeature, "Dark mode", "Add a dark theme")
+	for _, task := range []*models.Task{withLine, withCommit, missing, without} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := filepath.Abs("open.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		editor  string
+		args    []string
+		wantErr bool
+		want    []string
+	}{
+		{
+			name:   "vim jumps with +line",
+			editor: "vim",
+			args:   []string{withLine.ID},
+			want:   []string{"vim", "+42", file},
+		},
+		{
+			name:   "VS Code jumps with --goto",
+			editor: "code --wait",
+			args:   []string{withLine.ID},
+			want:   []string{"code", "--wait", "--goto", file + ":42"},
+		},
+		{
+			name:   "source without a line",
+			editor: "vim",
+			args:   []string{withCommit.ID},
+			want:   []string{"vim", file},
+		},
+		{
+			name:    "missing file",
+			editor:  "vim",
+			args:    []string{missing.ID},
+			wantErr: true,
+		},
+		{
+			name:    "no source",
+			editor:  "vim",
+			args:    []string{without.ID},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			app.Config().Editor = tt.editor
+
+			cmd := newOpenCommand(app)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(ran) != 1 || !reflect.DeepEqual(ran[0], tt.want) {
+				t.Errorf("ran %v, want %v", ran, tt.want)
+			}
+		})
+	}
+
+	t.Run("print", func(t *testing.T) {
+		ran = nil
+		app.Config().Editor = "subl"
+
+		var stdout bytes.Buffer
+		cmd := newOpenCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{withLine.ID, "--print"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ran) != 0 {
+			t.Errorf("Expected --print not to run the editor, ran %v", ran)
+		}
+		if got := strings.TrimSpace(stdout.String()); got != "subl "+file+":42" {
+			t.Errorf("printed %q", got)
+		}
+	})
+}
+
+func TestFormatShellCommand(t *testing.T) {
+	got := formatShellCommand([]string{"vim", "+3", "/tmp/my file.go", "it's"})
+	want := `vim +3 '/tmp/my file.go' 'it'\''s'`
+	if got != want {
+		t.Errorf("formatShellCommand() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// pickIndex chooses which of n eligible tasks pick returns; replaced in tests
+var pickIndex = rand.Intn
+
+// newPickCommand creates the pick command
+func newPickCommand(app *App) *cobra.Command {
+	var (
+		tag      string
+		priority string
+		start    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pick",
+		Short: "Pick a random task to work on next",
+		Long: `Pick one random task that is ready to be worked on, for when choosing is
+harder than doing. Eligible tasks are NEW, not blocked, and have no open subtasks.
+
+With --start, the picked task is marked as in progress.`,
+		Example: `  gtd pick
+  gtd pick --tag backend
+  gtd pick --priority high --start`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var priorityFilter models.Priority
+			if priority != "" {
+				var err error
+				if priorityFilter, err = models.ParsePriority(priority); err != nil {
+					return err
+				}
+			}
+
+			candidates, err := pickCandidates(tag, priorityFilter)
+			if err != nil {
+				return err
+			}
+			if len(candidates) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No ready tasks to pick from.")
+				return nil
+			}
+
+			task := candidates[pickIndex(len(candidates))]
+			rememberRecentTasks(cmd, []*models.Task{task})
+
+			if start {
+				return updateTaskState(app, cmd, task.ID, models.StateInProgress, stateChangeFlags{})
+			}
+
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), app.formatTaskGitStyle(task, nil))
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nPicked from %s. Start it with: gtd in-progress %s\n",
+				formatTaskCount(len(candidates), "ready task"), task.ShortHash())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tag, "tag", "", "Only pick tasks with this tag")
+	cmd.Flags().StringVar(&priority, "priority", "", "Only pick tasks with this priority (high, medium, low)")
+	cmd.Flags().BoolVar(&start, "start", false, "Mark the picked task as in progress")
+
+	return cmd
+}
+
+// pickCandidates returns the NEW, unblocked tasks without open subtasks
+func pickCandidates(tag string, priority models.Priority) ([]*models.Task, error) {
+	tasks, err := repo.List(models.ListOptions{State: models.StateNew, Tag: tag, Priority: priority})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var candidates []*models.Task
+	for _, task := range tasks {
+		if task.IsBlocked() {
+			continue
+		}
+		children, err := repo.GetChildren(task.ID)
+		if err != nil {
+			return nil, err
+		}
+		hasOpenChildren := false
+		for _, child := range children {
+			if !isFinishedState(child.State) {
+				hasOpenChildren = true
+				break
+			}
+		}
+		if !hasOpenChildren {
+			candidates = append(candidates, task)
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestPickCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	create := func(title string, priority models.Priority, tags string) *models.Task {
+		task := models.NewTask(models.KindFeature, title, "Description")
+		task.State = models.StateNew
+		task.Priority = priority
+		task.Tags = tags
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	ready := create("Ready task", models.PriorityHigh, "backend")
+	create("Other ready task", models.PriorityLow, "ui")
+	blocked := create("Blocked task", models.PriorityHigh, "backend")
+	if err := testRepo.Block(blocked.ID, ready.ID); err != nil {
+		t.Fatal(err)
+	}
+	parent := create("Parent with open subtask", models.PriorityHigh, "backend")
+	child := models.NewTask(models.KindBug, "Open subtask", "Description")
+	child.Parent = &parent.ID
+	child.State = models.StateInProgress
+	if err := testRepo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+
+	// Always pick the first candidate
+	oldPickIndex := pickIndex
+	pickIndex = func(n int) int { return 0 }
+	defer func() { pickIndex = oldPickIndex }()
+
+	run := func(args ...string) string {
+		var stdout bytes.Buffer
+		cmd := newPickCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return stdout.String()
+	}
+
+	t.Run("only ready tasks are candidates", func(t *testing.T) {
+		candidates, err := pickCandidates("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(candidates) != 2 {
+			t.Errorf("Expected 2 candidates, got %d", len(candidates))
+		}
+		for _, task := range candidates {
+			if task.ID == blocked.ID || task.ID == parent.ID {
+				t.Errorf("Task %q should not be a candidate", task.Title)
+			}
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		output := run("--tag", "backend", "--priority", "high")
+		if !strings.Contains(output, "Ready task") || !strings.Contains(output, "Picked from 1 ready task.") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		if output := run("--tag", "missing"); !strings.Contains(output, "No ready tasks") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+	})
+
+	t.Run("start", func(t *testing.T) {
+		output := run("--priority", "high", "--start")
+		if !strings.Contains(output, "marked as in progress") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+		task, err := testRepo.GetByID(ready.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.State != models.StateInProgress {
+			t.Errorf("State = %s, want %s", task.State, models.StateInProgress)
+		}
+	})
+
+	t.Run("invalid priority", func(t *testing.T) {
+		cmd := newPickCommand(NewApp())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--priority", "urgent"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected error for invalid priority")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// newPinCommand creates the pin command
+func newPinCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin TASK_ID",
+		Short: "Pin a task to the top of lists",
+		Long: `Pin an important task. Pinned tasks are listed before all other tasks,
+regardless of state, priority, or rank, and are marked with a star.`,
+		Example: `  gtd pin abc123
+  gtd unpin abc123`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPinned(cmd, args[0], true)
+		},
+	}
+}
+
+// newUnpinCommand creates the unpin command
+func newUnpinCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:     "unpin TASK_ID",
+		Short:   "Unpin a task",
+		Long:    `Unpin a task, so it is listed in its usual place again.`,
+		Example: `  gtd unpin abc123`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPinned(cmd, args[0], false)
+		},
+	}
+}
+
+// setPinned pins or unpins a task and reports the result
+func setPinned(cmd *cobra.Command, id string, pinned bool) error {
+	task, err := repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	verb := "pinned"
+	if !pinned {
+		verb = "unpinned"
+	}
+	hash := colorize(task.ShortHash(), colorYellow)
+	if task.Pinned == pinned {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s is already %s\n", hash, verb)
+		return nil
+	}
+
+	if err := repo.SetPinned(task.ID, pinned); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s %s\n", hash, verb)
+	return nil
+}
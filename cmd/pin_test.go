@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+func TestPinCommands(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	urgent := models.NewTask(models.KindBug, "Urgent bug", "Bug description")
+	urgent.State = models.StateInProgress
+	urgent.Priority = models.PriorityHigh
+	if err := testRepo.Create(urgent); err != nil {
+		t.Fatal(err)
+	}
+	chore := models.NewTask(models.KindFeature, "Pinned chore", "Chore description")
+	chore.State = models.StateNew
+	chore.Priority = models.PriorityLow
+	if err := testRepo.Create(chore); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, cmd *cobra.Command, args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return stdout.String()
+	}
+
+	if out := run(t, newPinCommand(NewApp()), chore.ID); !strings.Contains(out, "Task "+chore.ShortHash()+" pinned") {
+		t.Errorf("Unexpected pin output: %s", out)
+	}
+	if out := run(t, newPinCommand(NewApp()), chore.ID); !strings.Contains(out, "already pinned") {
+		t.Errorf("Expected already pinned message, got: %s", out)
+	}
+
+	out := run(t, newListCommand(NewApp()), "--oneline")
+	if !strings.Contains(out, output.PinnedIcon()) {
+		t.Errorf("Expected pinned marker in oneline output:\n%s", out)
+	}
+	if strings.Index(out, "Pinned chore") > strings.Index(out, "Urgent bug") {
+		t.Errorf("Expected pinned task first:\n%s", out)
+	}
+
+	if out := run(t, newUnpinCommand(NewApp()), chore.ID); !strings.Contains(out, "unpinned") {
+		t.Errorf("Unexpected unpin output: %s", out)
+	}
+	out = run(t, newListCommand(NewApp()), "--oneline")
+	if strings.Index(out, "Pinned chore") < strings.Index(out, "Urgent bug") {
+		t.Errorf("Expected usual order after unpinning:\n%s", out)
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// pomodoroNote marks worklog entries recorded by the pomodoro command
+const pomodoroNote = "pomodoro"
+
+// pomodoroWait waits out a pomodoro, calling tick with the remaining time
+// every second; tests replace it
+var pomodoroWait = waitPomodoro
+
+// newPomodoroCommand creates the pomodoro command
+func newPomodoroCommand(app *App) *cobra.Command {
+	var length time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "pomodoro TASK_ID",
+		Short: "Run a pomodoro timer on a task",
+		Long: `Run a countdown timer for focused work on a task and record the interval
+in the task's worklog. NEW tasks are marked as in progress when the timer starts.
+
+When the timer ends in a terminal, you are asked whether to start another
+pomodoro, mark the task as done, or stop. Interrupting the timer with Ctrl-C
+records the time worked so far.`,
+		Example: `  gtd pomodoro abc123
+  gtd pomodoro @current --length 50m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if length <= 0 {
+				return errors.NewValidationError("invalid length: %s (must be positive)", length)
+			}
+
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+			if isFinishedState(task.State) {
+				return errors.NewInvalidStateTransitionErrorf(task.State, models.StateInProgress, "task %s is already %s", task.ShortHash(), strings.ToLower(task.State.String()))
+			}
+			if task.State == models.StateNew {
+				if err := updateTaskState(app, cmd, task.ID, models.StateInProgress, stateChangeFlags{}); err != nil {
+					return err
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			return runPomodoros(app, ctx, cmd, task, length)
+		},
+	}
+
+	cmd.Flags().DurationVar(&length, "length", 25*time.Minute, "Length of each pomodoro (e.g. 25m, 50m)")
+
+	return cmd
+}
+
+// runPomodoros runs pomodoros on a task until the user stops, marks the task
+// done, or interrupts the timer
+func runPomodoros(app *App, ctx context.Context, cmd *cobra.Command, task *models.Task, length time.Duration) error {
+	out := cmd.OutOrStdout()
+	errOut := cmd.ErrOrStderr()
+	interactive := canPrompt()
+	in := bufio.NewReader(cmd.InOrStdin())
+
+	for {
+		_, _ = fmt.Fprintf(out, "Pomodoro started on %s (%s): %s\n",
+			task.ShortHash(), formatWorkDuration(length), task.Title)
+
+		started := time.Now()
+		waitErr := pomodoroWait(ctx, length, func(remaining time.Duration) {
+			if interactive {
+				_, _ = fmt.Fprintf(errOut, "\r%s remaining ", formatCountdown(remaining))
+			}
+		})
+		ended := time.Now()
+		if interactive {
+			_, _ = fmt.Fprint(errOut, "\r\a")
+		}
+
+		if _, err := repo.AddWorklog(task.ID, started, ended, pomodoroNote); err != nil {
+			return err
+		}
+		if waitErr != nil {
+			_, _ = fmt.Fprintf(out, "Pomodoro interrupted: logged %s on %s\n",
+				formatWorkDuration(ended.Sub(started)), task.ShortHash())
+			return nil
+		}
+		_, _ = fmt.Fprintf(out, "Pomodoro finished: logged %s on %s\n",
+			formatWorkDuration(ended.Sub(started)), task.ShortHash())
+
+		if !interactive {
+			return nil
+		}
+		_, _ = fmt.Fprint(errOut, "[c]ontinue with another pomodoro, mark [d]one, or [q]uit? ")
+		line, _ := in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "c", "continue":
+			continue
+		case "d", "done":
+			return updateTaskState(app, cmd, task.ID, models.StateDone, stateChangeFlags{})
+		default:
+			return nil
+		}
+	}
+}
+
+// waitPomodoro waits for length to pass, calling tick with the remaining time
+// every second. It returns the context's error if interrupted.
+func waitPomodoro(ctx context.Context, length time.Duration, tick func(remaining time.Duration)) error {
+	deadline := time.Now().Add(length)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		tick(remaining)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatCountdown formats remaining time as mm:ss
+func formatCountdown(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// formatWorkDuration formats time worked to the minute, e.g. "25m" or "1h 5m"
+func formatWorkDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes < 1:
+		return "<1m"
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestPomodoroCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	defer func(old func(context.Context, time.Duration, func(time.Duration)) error) { pomodoroWait = old }(pomodoroWait)
+	defer func(old func() bool) { canPrompt = old }(canPrompt)
+
+	create := func(title string) *models.Task {
+		task := models.NewTask(models.KindFeature, title, "Description")
+		task.State = models.StateNew
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	run := func(input string, args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newPomodoroCommand(NewApp())
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Run("continue then done", func(t *testing.T) {
+		task := create("Focused work")
+		canPrompt = func() bool { return true }
+		pomodoroWait = func(ctx context.Context, length time.Duration, tick func(time.Duration)) error {
+			tick(length)
+			return nil
+		}
+
+		output, err := run("c\nd\n", task.ID, "--length", "1m")
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		for _, want := range []string{"marked as in progress", "Pomodoro started on " + task.ShortHash() + " (1m)", "Pomodoro finished", "marked as done"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Output does not contain %q\nGot: %s", want, output)
+			}
+		}
+
+		worklog, err := testRepo.GetWorklog(task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(worklog) != 2 || worklog[0].Note != pomodoroNote {
+			t.Errorf("Expected 2 pomodoro worklog entries, got %+v", worklog)
+		}
+		updated, err := testRepo.GetByID(task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.State != models.StateDone {
+			t.Errorf("State = %s, want %s", updated.State, models.StateDone)
+		}
+	})
+
+	t.Run("interrupted without terminal", func(t *testing.T) {
+		task := create("Interrupted work")
+		canPrompt = func() bool { return false }
+		pomodoroWait = func(ctx context.Context, length time.Duration, tick func(time.Duration)) error {
+			return context.Canceled
+		}
+
+		output, err := run("", task.ID)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !strings.Contains(output, "Pomodoro interrupted: logged <1m") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+		if worklog, _ := testRepo.GetWorklog(task.ID); len(worklog) != 1 {
+			t.Errorf("Expected the partial interval to be logged, got %d entries", len(worklog))
+		}
+	})
+
+	t.Run("finished task", func(t *testing.T) {
+		task := create("Finished work")
+		if err := testRepo.UpdateState(task.ID, models.StateDone); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run("", task.ID); err == nil || !strings.Contains(err.Error(), "already done") {
+			t.Errorf("Expected already done error, got %v", err)
+		}
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		task := create("Zero length")
+		if _, err := run("", task.ID, "--length", "0s"); err == nil {
+			t.Error("Expected error for zero length")
+		}
+	})
+}
+
+func TestWaitPomodoro(t *testing.T) {
+	ticks := 0
+	if err := waitPomodoro(context.Background(), 10*time.Millisecond, func(time.Duration) { ticks++ }); err != nil {
+		t.Fatalf("waitPomodoro() error = %v", err)
+	}
+	if ticks == 0 {
+		t.Error("Expected at least one tick")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitPomodoro(ctx, time.Hour, func(time.Duration) {}); err != context.Canceled {
+		t.Errorf("waitPomodoro() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFormatWorkDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		20 * time.Second:  "<1m",
+		25 * time.Minute:  "25m",
+		2 * time.Hour:     "2h",
+		65 * time.Minute:  "1h 5m",
+		150 * time.Second: "3m",
+	}
+	for d, want := range tests {
+		if got := formatWorkDuration(d); got != want {
+			t.Errorf("formatWorkDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+	if got := formatCountdown(90 * time.Second); got != "01:30" {
+		t.Errorf("formatCountdown() = %q, want 01:30", got)
+	}
+}
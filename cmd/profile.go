@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+var (
+	// profileCommand reports the time spent per phase (--profile)
+	profileCommand bool
+
+	// slowQueryThreshold is the duration above which --profile lists SQL
+	// statements (--slow-query)
+	slowQueryThreshold time.Duration
+)
+
+// commandProfile times the phases of a command for --profile
+type commandProfile struct {
+	sql *database.Profiler
+
+	start          time.Time
+	opened         time.Time     // Database opened, command starts
+	ran            time.Time     // Command finished, database closes
+	openSQL        time.Duration // SQL time and statements while opening
+	openStatements int
+	ranSQL         time.Duration // SQL time and statements until the end of the command
+	ranStatements  int
+}
+
+// maxProfileQuery is the length slow statements are shortened to
+const maxProfileQuery = 120
+
+// startProfile starts timing a command, recording the SQL statements of the
+// database it opens
+func startProfile() *commandProfile {
+	p := &commandProfile{sql: database.NewProfiler(slowQueryThreshold), start: time.Now()}
+	database.SetProfiler(p.sql)
+	return p
+}
+
+// markOpened ends the phase of opening the database
+func (p *commandProfile) markOpened() {
+	p.opened = time.Now()
+	p.openStatements, p.openSQL = p.sql.Totals()
+}
+
+// markRan ends the phase of running the command
+func (p *commandProfile) markRan() {
+	p.ran = time.Now()
+	p.ranStatements, p.ranSQL = p.sql.Totals()
+}
+
+// write reports the time spent per phase, and the statements slower than
+// the threshold. Time spent in the command outside SQL statements is counted
+// as formatting.
+func (p *commandProfile) write(w io.Writer) {
+	end := time.Now()
+	queries := p.ranSQL - p.openSQL
+	run := p.ran.Sub(p.opened)
+
+	_, _ = fmt.Fprintln(w, "Profile:")
+	_, _ = fmt.Fprintf(w, "  %-15s %s\n", "open database", formatProfileDuration(p.opened.Sub(p.start)))
+	_, _ = fmt.Fprintf(w, "  %-15s %s (%s)\n", "queries", formatProfileDuration(queries),
+		formatTaskCount(p.ranStatements-p.openStatements, "statement"))
+	_, _ = fmt.Fprintf(w, "  %-15s %s\n", "formatting", formatProfileDuration(max(run-queries, 0)))
+	_, _ = fmt.Fprintf(w, "  %-15s %s\n", "close database", formatProfileDuration(end.Sub(p.ran)))
+	_, _ = fmt.Fprintf(w, "  %-15s %s\n", "total", formatProfileDuration(end.Sub(p.start)))
+
+	slow := p.sql.Slow()
+	if len(slow) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\nSlow statements (over %s):\n", p.sql.Threshold())
+	for _, stmt := range slow {
+		query := stmt.Query
+		if len(query) > maxProfileQuery {
+			query = query[:maxProfileQuery-3] + "..."
+		}
+		_, _ = fmt.Fprintf(w, "  %10s  %s\n", formatProfileDuration(stmt.Duration), query)
+	}
+}
+
+// formatProfileDuration formats a duration to a tenth of a millisecond,
+// e.g. "12.3ms"
+func formatProfileDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"golang.org/x/term"
+)
+
+// isInteractive reports whether both stdin and stderr are attached to a terminal
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// canPrompt reports whether confirmation prompts can be shown; tests replace it
+var canPrompt = isInteractive
+
+// confirmAction asks a yes/no question on cmd's streams when confirmations are
+// enabled with GTD_CONFIRM_DONE, unless skip (--yes) is set or no terminal is
+// attached, and returns an error if the user declines
+func confirmAction(app *App, cmd *cobra.Command, skip bool, question string) error {
+	if skip || !app.Config().ConfirmDone || !canPrompt() {
+		return nil
+	}
+	if !promptYesNo(bufio.NewReader(cmd.InOrStdin()), cmd.ErrOrStderr(), question) {
+		return fmt.Errorf("aborted (use --yes to skip confirmation)")
+	}
+	return nil
+}
+
+// promptYesNo asks a question and reports whether the answer was yes
+func promptYesNo(in *bufio.Reader, out io.Writer, question string) bool {
+	_, _ = fmt.Fprintf(out, "%s [y/N]: ", question)
+	line, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// newTaskChooser returns a models.ChooseFunc that asks the user to pick one of
+// several tasks matching an ambiguous reference
+func newTaskChooser(app *App, in io.Reader, out io.Writer) models.ChooseFunc {
+	reader := bufio.NewReader(in)
+	return func(ref string, candidates []*models.Task) (*models.Task, error) {
+		return promptTaskChoice(app, reader, out, ref, candidates)
+	}
+}
+
+// promptTaskChoice lists the candidates and reads a 1-based selection
+func promptTaskChoice(app *App, in *bufio.Reader, out io.Writer, ref string, candidates []*models.Task) (*models.Task, error) {
+	_, _ = fmt.Fprintf(out, "Task ID '%s' is ambiguous:\n", ref)
+	for i, task := range candidates {
+		_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, app.formatTaskOneline(task))
+	}
+	_, _ = fmt.Fprintf(out, "Select a task [1-%d]: ", len(candidates))
+
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("no task selected for ambiguous ID '%s'", ref)
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(candidates) {
+		return nil, errors.NewValidationError("invalid selection %q for ambiguous ID '%s'", strings.TrimSpace(line), ref)
+	}
+	return candidates[choice-1], nil
+}
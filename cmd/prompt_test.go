@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestPromptTaskChoice(t *testing.T) {
+	candidates := []*models.Task{
+		models.NewTask(models.KindBug, "First candidate", "Description"),
+		models.NewTask(models.KindFeature, "Second candidate", "Description"),
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    *models.Task
+		wantErr string
+	}{
+		{name: "select first", input: "1\n", want: candidates[0]},
+		{name: "select second without newline", input: "2", want: candidates[1]},
+		{name: "out of range", input: "3\n", wantErr: "invalid selection"},
+		{name: "not a number", input: "abc\n", wantErr: "invalid selection"},
+		{name: "no input", input: "", wantErr: "no task selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptTaskChoice(NewApp(), bufio.NewReader(strings.NewReader(tt.input)), &out, "abcd", candidates)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("selected %q, want %q", got.Title, tt.want.Title)
+			}
+			for _, want := range []string{"ambiguous", "1) ", "First candidate", "2) ", "Second candidate"} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("prompt does not contain %q\nGot: %s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPromptYesNo(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"maybe\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			var out bytes.Buffer
+			got := promptYesNo(bufio.NewReader(strings.NewReader(tt.input)), &out, "Proceed?")
+			if got != tt.want {
+				t.Errorf("promptYesNo(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if !strings.Contains(out.String(), "Proceed? [y/N]") {
+				t.Errorf("prompt = %q", out.String())
+			}
+		})
+	}
+}
+
+func TestConfirmationPrompts(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	defer func(old func() bool) { canPrompt = old }(canPrompt)
+	canPrompt = func() bool { return true }
+	app := NewApp()
+	app.Config().ConfirmDone = true
+
+	newTask := func(title string, state models.State, parent *models.Task) *models.Task {
+		t.Helper()
+		task := models.NewTask(models.KindFeature, title, "Needs confirmation")
+		task.State = state
+		if parent != nil {
+			task.Parent = &parent.ID
+		}
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	tests := []struct {
+		name      string
+		newCmd    func(*App) *cobra.Command
+		task      func() *models.Task
+		args      []string
+		input     string
+		noConfirm bool // GTD_CONFIRM_DONE unset
+		wantErr   bool
+		wantState models.State
+	}{
+		{
+			name:   "parent done declined",
+			newCmd: newDoneCommand,
+			task: func() *models.Task {
+				parent := newTask("Declined parent", models.StateInProgress, nil)
+				newTask("Finished child", models.StateDone, parent)
+				return parent
+			},
+			input:     "n\n",
+			wantErr:   true,
+			wantState: models.StateInProgress,
+		},
+		{
+			name:   "parent done confirmed",
+			newCmd: newDoneCommand,
+			task: func() *models.Task {
+				parent := newTask("Confirmed parent", models.StateInProgress, nil)
+				newTask("Finished child", models.StateDone, parent)
+				return parent
+			},
+			input:     "y\n",
+			wantState: models.StateDone,
+		},
+		{
+			name:   "parent done with --yes",
+			newCmd: newDoneCommand,
+			task: func() *models.Task {
+				parent := newTask("Scripted parent", models.StateInProgress, nil)
+				newTask("Finished child", models.StateDone, parent)
+				return parent
+			},
+			args:      []string{"--yes"},
+			wantState: models.StateDone,
+		},
+		{
+			name:      "task without subtasks is not confirmed",
+			newCmd:    newDoneCommand,
+			task:      func() *models.Task { return newTask("Leaf", models.StateInProgress, nil) },
+			wantState: models.StateDone,
+		},
+		{
+			name:      "reject declined",
+			newCmd:    newRejectCommand,
+			task:      func() *models.Task { return newTask("Inbox item", models.StateInbox, nil) },
+			input:     "\n",
+			wantErr:   true,
+			wantState: models.StateInbox,
+		},
+		{
+			name:      "cascade cancel with -y",
+			newCmd:    newCancelCommand,
+			task:      func() *models.Task { return newTask("Cascade root", models.StateNew, nil) },
+			args:      []string{"--cascade", "-y"},
+			wantState: models.StateCancelled,
+		},
+		{
+			name:   "parent done without GTD_CONFIRM_DONE",
+			newCmd: newDoneCommand,
+			task: func() *models.Task {
+				parent := newTask("Unconfirmed parent", models.StateInProgress, nil)
+				newTask("Finished child", models.StateDone, parent)
+				return parent
+			},
+			noConfirm: true,
+			wantState: models.StateDone,
+		},
+		{
+			name:      "reject without GTD_CONFIRM_DONE",
+			newCmd:    newRejectCommand,
+			task:      func() *models.Task { return newTask("Unconfirmed inbox item", models.StateInbox, nil) },
+			input:     "\n",
+			noConfirm: true,
+			wantState: models.StateInvalid,
+		},
+		{
+			name:      "cascade cancel without GTD_CONFIRM_DONE",
+			newCmd:    newCancelCommand,
+			task:      func() *models.Task { return newTask("Unconfirmed cascade root", models.StateNew, nil) },
+			args:      []string{"--cascade"},
+			input:     "\n",
+			noConfirm: true,
+			wantState: models.StateCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task()
+			app.Config().ConfirmDone = !tt.noConfirm
+
+			var stdout, stderr bytes.Buffer
+			cmd := tt.newCmd(app)
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(append([]string{task.ID}, tt.args...))
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := testRepo.GetByID(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("State = %s, want %s", got.State, tt.wantState)
+			}
+		})
+	}
+}
@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
 // newReopenCommand creates the reopen command to move tasks from CANCELLED to NEW
-func newReopenCommand() *cobra.Command {
+func newReopenCommand(app *App) *cobra.Command {
+	var dryRun bool
+
 	cmd := &cobra.Command{
 		Use:   "reopen <task-id>",
 		Short: "Reopen a cancelled task (move to NEW state)",
@@ -24,16 +28,24 @@ This command allows you to resume work on tasks that were previously cancelled.`
 			// Find the task
 			task, err := repo.GetByID(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
 
 			// Check current state
 			if task.State != models.StateCancelled {
-				return fmt.Errorf("task %s is not in CANCELLED state (current: %s)", task.ShortHash(), task.State)
+				return errors.NewInvalidStateTransitionErrorf(task.State, models.StateNew, "task %s is not in CANCELLED state (current: %s)", task.ShortHash(), task.State)
+			}
+
+			if dryRun {
+				if _, err := repo.CheckTransition(task.ID, models.StateNew, time.Now()); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would reopen task %s (move from CANCELLED to NEW)\n", task.ShortHash())
+				return nil
 			}
 
 			// Update to NEW state
-			if err := repo.UpdateState(task.ID, models.StateNew); err != nil {
+			if err := newTaskService(app, cmd).UpdateTaskState(task.ID, models.StateNew); err != nil {
 				return fmt.Errorf("failed to update task state: %w", err)
 			}
 
@@ -42,5 +54,7 @@ This command allows you to resume work on tasks that were previously cancelled.`
 		},
 	}
 
+	addDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
\ No newline at end of file
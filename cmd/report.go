@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newReportCommand creates the report command, which groups the reports
+func newReportCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show reports computed from task history",
+		Long:  `Show reports computed from the recorded history and worklog of tasks.`,
+		Example: `  gtd report cycle-time
+  gtd report cycle-time --since 30d --format csv
+  gtd report monthly --month 2024-06 -o report.md
+  gtd report time --by kind`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newCycleTimeReportCommand(app))
+	cmd.AddCommand(newMonthlyReportCommand(app))
+	cmd.AddCommand(newTimeReportCommand(app))
+
+	return cmd
+}
+
+// newCycleTimeReportCommand creates the report cycle-time command
+func newCycleTimeReportCommand(app *App) *cobra.Command {
+	var (
+		since  string
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cycle-time",
+		Short: "Show lead and cycle time percentiles per kind",
+		Long: `Show how long tasks completed in a period took, per kind, as the median (p50)
+and 90th percentile (p90) of:
+
+  NEW→DONE          time from creation until completion (lead time)
+  IN_PROGRESS→DONE  time from first starting work until completion (cycle time)
+
+Tasks that were never marked in progress only count towards lead time. Tasks
+reopened after completion are left out until they are done again.`,
+		Example: `  gtd report cycle-time
+  gtd report cycle-time --since 30d
+  gtd report cycle-time --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			if format != "table" && format != "json" && format != "csv" {
+				return errors.NewValidationError("unsupported format: %s (must be table, json, or csv)", format)
+			}
+
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			report, err := buildCycleTimeReport(cmd.Context(), sinceTime)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "json":
+				return writeJSON(out, report)
+			case "csv":
+				return writeCycleTimeCSV(out, report)
+			}
+			formatCycleTimeReport(out, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "90d", "Only include tasks completed since a relative age (30d, 12w) or date (2024-01-01)")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json, csv)")
+
+	return cmd
+}
+
+// cycleTimeReport is the result of report cycle-time
+type cycleTimeReport struct {
+	Since string           `json:"since"`
+	Kinds []cycleTimeStats `json:"kinds"`
+}
+
+// cycleTimeStats are the lead and cycle time percentiles of one kind of task,
+// or of all tasks when Kind is "ALL"
+type cycleTimeStats struct {
+	Kind             string              `json:"kind"`
+	Tasks            int                 `json:"tasks"`
+	NewToDone        durationPercentiles `json:"new_to_done"`
+	InProgressToDone durationPercentiles `json:"in_progress_to_done"`
+}
+
+// durationPercentiles summarizes a set of durations, in hours
+type durationPercentiles struct {
+	Count    int     `json:"count"`
+	P50Hours float64 `json:"p50_hours"`
+	P90Hours float64 `json:"p90_hours"`
+}
+
+// buildCycleTimeReport computes lead and cycle times of tasks completed since
+// the given time from their state change history
+func buildCycleTimeReport(ctx context.Context, since time.Time) (*cycleTimeReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	history, err := repo.ListHistory(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	// The latest completion of each task within the period
+	completed := make(map[string]time.Time)
+	for _, entry := range history {
+		if entry.Action == models.ActionState && entry.NewValue == models.StateDone.String() {
+			completed[entry.TaskID] = entry.Created
+		}
+	}
+
+	leadTimes := make(map[models.Kind][]time.Duration)
+	cycleTimes := make(map[models.Kind][]time.Duration)
+	err = repo.Iterate(ctx, models.ListOptions{State: models.StateDone, ShowDone: true, All: true}, func(task *models.Task) error {
+		done, ok := completed[task.ID]
+		if !ok {
+			return nil
+		}
+		leadTimes[task.Kind] = append(leadTimes[task.Kind], done.Sub(task.Created))
+
+		started, err := firstStartedAt(task.ID, done)
+		if err != nil {
+			return err
+		}
+		if !started.IsZero() {
+			cycleTimes[task.Kind] = append(cycleTimes[task.Kind], done.Sub(started))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	report := &cycleTimeReport{Since: since.UTC().Format(time.RFC3339), Kinds: []cycleTimeStats{}}
+	var allLead, allCycle []time.Duration
+	for _, kind := range models.Kinds {
+		if len(leadTimes[kind]) == 0 {
+			continue
+		}
+		report.Kinds = append(report.Kinds, cycleTimeStats{
+			Kind:             kind.String(),
+			Tasks:            len(leadTimes[kind]),
+			NewToDone:        percentiles(leadTimes[kind]),
+			InProgressToDone: percentiles(cycleTimes[kind]),
+		})
+		allLead = append(allLead, leadTimes[kind]...)
+		allCycle = append(allCycle, cycleTimes[kind]...)
+	}
+	report.Kinds = append(report.Kinds, cycleTimeStats{
+		Kind:             "ALL",
+		Tasks:            len(allLead),
+		NewToDone:        percentiles(allLead),
+		InProgressToDone: percentiles(allCycle),
+	})
+
+	return report, nil
+}
+
+// firstStartedAt returns when a task was first marked in progress before it
+// was completed, or the zero time if it never was
+func firstStartedAt(taskID string, done time.Time) (time.Time, error) {
+	history, err := repo.GetHistory(taskID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, entry := range history {
+		if entry.Created.After(done) {
+			break
+		}
+		if entry.Action == models.ActionState && entry.NewValue == models.StateInProgress.String() {
+			return entry.Created, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// percentiles computes the nearest-rank p50 and p90 of durations
+func percentiles(durations []time.Duration) durationPercentiles {
+	if len(durations) == 0 {
+		return durationPercentiles{}
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return math.Round(sorted[i].Hours()*100) / 100
+	}
+
+	return durationPercentiles{Count: len(sorted), P50Hours: rank(50), P90Hours: rank(90)}
+}
+
+// formatCycleTimeReport prints the cycle time report as a table
+func formatCycleTimeReport(w io.Writer, report *cycleTimeReport) {
+	all := report.Kinds[len(report.Kinds)-1]
+	if all.Tasks == 0 {
+		_, _ = fmt.Fprintln(w, "No tasks completed in this period.")
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%-12s %6s  %-19s  %-19s\n", "", "", "NEW→DONE", "IN_PROGRESS→DONE")
+	_, _ = fmt.Fprintf(w, "%-12s %6s  %9s %9s  %9s %9s\n", "KIND", "TASKS", "p50", "p90", "p50", "p90")
+	for _, stats := range report.Kinds {
+		_, _ = fmt.Fprintf(w, "%-12s %6d  %9s %9s  %9s %9s\n",
+			stats.Kind, stats.Tasks,
+			formatHours(stats.NewToDone, stats.NewToDone.P50Hours),
+			formatHours(stats.NewToDone, stats.NewToDone.P90Hours),
+			formatHours(stats.InProgressToDone, stats.InProgressToDone.P50Hours),
+			formatHours(stats.InProgressToDone, stats.InProgressToDone.P90Hours))
+	}
+}
+
+// formatHours shows a percentile in minutes, hours, or days, or "-" when
+// there were no durations to compute it from
+func formatHours(p durationPercentiles, hours float64) string {
+	switch {
+	case p.Count == 0:
+		return "-"
+	case hours < 1:
+		return fmt.Sprintf("%.0fm", hours*60)
+	case hours < 24:
+		return fmt.Sprintf("%.1fh", hours)
+	default:
+		return fmt.Sprintf("%.1fd", hours/24)
+	}
+}
+
+// writeCycleTimeCSV writes the cycle time report as CSV, with durations in hours
+func writeCycleTimeCSV(w io.Writer, report *cycleTimeReport) error {
+	csvWriter := csv.NewWriter(w)
+
+	header := []string{"kind", "tasks",
+		"new_to_done_count", "new_to_done_p50_hours", "new_to_done_p90_hours",
+		"in_progress_to_done_count", "in_progress_to_done_p50_hours", "in_progress_to_done_p90_hours"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	hours := func(h float64) string { return strconv.FormatFloat(h, 'f', 2, 64) }
+	for _, stats := range report.Kinds {
+		row := []string{stats.Kind, strconv.Itoa(stats.Tasks),
+			strconv.Itoa(stats.NewToDone.Count), hours(stats.NewToDone.P50Hours), hours(stats.NewToDone.P90Hours),
+			strconv.Itoa(stats.InProgressToDone.Count), hours(stats.InProgressToDone.P50Hours), hours(stats.InProgressToDone.P90Hours)}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// newTimeReportCommand creates the report time command
+func newTimeReportCommand(app *App) *cobra.Command {
+	var (
+		by     string
+		since  string
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "time",
+		Short: "Show time logged per tag or kind",
+		Long: `Show the time logged in the worklog (e.g. by gtd pomodoro) in a period,
+grouped by tag or by kind, with each group's share of the total and its split by
+task priority, to compare where time went against what was planned.
+
+Time on tasks with several tags counts towards each of them, so with --by tag
+the shares can add up to more than 100%.`,
+		Example: `  gtd report time
+  gtd report time --by kind --since 7d
+  gtd report time --format csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			by = strings.ToLower(by)
+			if by != "tag" && by != "kind" {
+				return errors.NewValidationError("invalid grouping: %s (must be tag or kind)", by)
+			}
+			format = strings.ToLower(format)
+			if format != "table" && format != "json" && format != "csv" {
+				return errors.NewValidationError("unsupported format: %s (must be table, json, or csv)", format)
+			}
+
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			report, err := buildTimeReport(by, sinceTime)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "json":
+				return writeJSON(out, report)
+			case "csv":
+				return writeTimeReportCSV(out, report)
+			}
+			formatTimeReport(out, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "tag", "Group time by tag or kind")
+	cmd.Flags().StringVar(&since, "since", "30d", "Only include time logged since a relative age (7d, 4w) or date (2024-01-01)")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json, csv)")
+
+	return cmd
+}
+
+// timeReport is the result of report time
+type timeReport struct {
+	By         string      `json:"by"`
+	Since      string      `json:"since"`
+	TotalHours float64     `json:"total_hours"`
+	Groups     []timeGroup `json:"groups"`
+}
+
+// timeGroup is the time logged on the tasks of one tag or kind
+type timeGroup struct {
+	Name        string  `json:"name"`
+	Hours       float64 `json:"hours"`
+	Share       float64 `json:"share"`
+	HighHours   float64 `json:"high_hours"`
+	MediumHours float64 `json:"medium_hours"`
+	LowHours    float64 `json:"low_hours"`
+}
+
+// untaggedGroup names the group of tasks without tags in time reports
+const untaggedGroup = "(untagged)"
+
+// buildTimeReport aggregates worklog entries since the given time by tag or kind
+func buildTimeReport(by string, since time.Time) (*timeReport, error) {
+	entries, err := repo.ListWorklog(since)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[string]*models.Task)
+	groups := make(map[string]map[models.Priority]time.Duration)
+	var total time.Duration
+	for _, entry := range entries {
+		task, ok := tasks[entry.TaskID]
+		if !ok {
+			if task, err = repo.GetByID(entry.TaskID); err != nil {
+				return nil, err
+			}
+			tasks[entry.TaskID] = task
+		}
+
+		names := []string{task.Kind.String()}
+		if by == "tag" {
+			names = task.ParseTags()
+			if len(names) == 0 {
+				names = []string{untaggedGroup}
+			}
+		}
+		for _, name := range names {
+			if groups[name] == nil {
+				groups[name] = make(map[models.Priority]time.Duration)
+			}
+			groups[name][task.Priority] += entry.Duration()
+		}
+		total += entry.Duration()
+	}
+
+	report := &timeReport{
+		By:         by,
+		Since:      since.UTC().Format(time.RFC3339),
+		TotalHours: roundHours(total),
+		Groups:     []timeGroup{},
+	}
+	for name, byPriority := range groups {
+		var sum time.Duration
+		for _, d := range byPriority {
+			sum += d
+		}
+		group := timeGroup{
+			Name:        name,
+			Hours:       roundHours(sum),
+			HighHours:   roundHours(byPriority[models.PriorityHigh]),
+			MediumHours: roundHours(byPriority[models.PriorityMedium]),
+			LowHours:    roundHours(byPriority[models.PriorityLow]),
+		}
+		if total > 0 {
+			group.Share = math.Round(float64(sum)/float64(total)*1000) / 10
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Hours != report.Groups[j].Hours {
+			return report.Groups[i].Hours > report.Groups[j].Hours
+		}
+		return report.Groups[i].Name < report.Groups[j].Name
+	})
+
+	return report, nil
+}
+
+// roundHours converts a duration to hours, rounded to two decimals
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// formatTimeReport prints the time report as a table, most time first
+func formatTimeReport(w io.Writer, report *timeReport) {
+	if len(report.Groups) == 0 {
+		_, _ = fmt.Fprintln(w, "No time logged in this period.")
+		return
+	}
+
+	hours := func(h float64) string {
+		if h == 0 {
+			return "-"
+		}
+		return formatWorkDuration(time.Duration(h * float64(time.Hour)))
+	}
+
+	_, _ = fmt.Fprintf(w, "%-20s %9s %6s  %9s %9s %9s\n", strings.ToUpper(report.By), "TIME", "SHARE", "HIGH", "MEDIUM", "LOW")
+	for _, group := range report.Groups {
+		_, _ = fmt.Fprintf(w, "%-20s %9s %5.1f%%  %9s %9s %9s\n",
+			group.Name, hours(group.Hours), group.Share,
+			hours(group.HighHours), hours(group.MediumHours), hours(group.LowHours))
+	}
+	_, _ = fmt.Fprintf(w, "\nTotal: %s\n", hours(report.TotalHours))
+}
+
+// writeTimeReportCSV writes the time report as CSV, with durations in hours
+func writeTimeReportCSV(w io.Writer, report *timeReport) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write([]string{report.By, "hours", "share", "high_hours", "medium_hours", "low_hours"}); err != nil {
+		return err
+	}
+
+	hours := func(h float64) string { return strconv.FormatFloat(h, 'f', 2, 64) }
+	for _, group := range report.Groups {
+		row := []string{group.Name, hours(group.Hours), strconv.FormatFloat(group.Share, 'f', 1, 64),
+			hours(group.HighHours), hours(group.MediumHours), hours(group.LowHours)}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+// maxBlockerHotspots is the number of blockers listed in a monthly report
+const maxBlockerHotspots = 10
+
+// newMonthlyReportCommand creates the report monthly command
+func newMonthlyReportCommand(app *App) *cobra.Command {
+	var (
+		month      string
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "monthly",
+		Short: "Write a Markdown report of a month's work",
+		Long: `Write a Markdown report for a month, summarizing the work completed grouped
+by tag, the tasks added during the month, and the open tasks currently blocking
+the most other work. Defaults to the current month.`,
+		Example: `  gtd report monthly
+  gtd report monthly --month 2024-06 -o report.md`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := parseMonth(month, time.Now())
+			if err != nil {
+				return err
+			}
+			end := start.AddDate(0, 1, 0)
+
+			tasks, err := repo.List(models.ListOptions{All: true, ShowDone: true, ShowCancelled: true})
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			var completed, intake []*models.Task
+			for _, task := range tasks {
+				if !task.Created.Before(start) && task.Created.Before(end) {
+					intake = append(intake, task)
+				}
+				if task.State != models.StateDone {
+					continue
+				}
+				done, err := stateReachedAt(task)
+				if err != nil {
+					return err
+				}
+				if !done.Before(start) && done.Before(end) {
+					completed = append(completed, task)
+				}
+			}
+
+			// Like exports, the report replaces the output file only once
+			// it is complete
+			var writer io.Writer = cmd.OutOrStdout()
+			var file *os.File
+			if outputFile != "" {
+				if file, err = createOutputFile(outputFile); err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer func() {
+					if file != nil {
+						_ = file.Close()
+						_ = os.Remove(file.Name())
+					}
+				}()
+				writer = file
+			}
+
+			if err := writeMonthlyReport(writer, start, completed, intake, blockerHotspots(tasks)); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+
+			if file != nil {
+				err := replaceOutputFile(file, outputFile)
+				file = nil
+				if err != nil {
+					return fmt.Errorf("failed to write output file: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote report for %s to %s\n", start.Format("January 2006"), outputFile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&month, "month", "", "Month to report on, as YYYY-MM (default: current month)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+
+	return cmd
+}
+
+// parseMonth parses a YYYY-MM month into the local midnight of its first day,
+// returning the current month for an empty value
+func parseMonth(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), nil
+	}
+	month, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.NewValidationError("invalid month: %s (expected YYYY-MM)", value)
+	}
+	return month, nil
+}
+
+// blockerHotspot is an open task and the open tasks it blocks
+type blockerHotspot struct {
+	task    *models.Task
+	blocked []*models.Task
+}
+
+// blockerHotspots returns open tasks blocking other open tasks, those
+// blocking the most first
+func blockerHotspots(tasks []*models.Task) []blockerHotspot {
+	byID := make(map[string]*models.Task)
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	blocked := make(map[string][]*models.Task)
+	for _, task := range tasks {
+		if task.BlockedBy == nil || isFinishedState(task.State) {
+			continue
+		}
+		if blocker := byID[*task.BlockedBy]; blocker != nil && !isFinishedState(blocker.State) {
+			blocked[blocker.ID] = append(blocked[blocker.ID], task)
+		}
+	}
+
+	var hotspots []blockerHotspot
+	for id, tasks := range blocked {
+		hotspots = append(hotspots, blockerHotspot{task: byID[id], blocked: tasks})
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if len(hotspots[i].blocked) != len(hotspots[j].blocked) {
+			return len(hotspots[i].blocked) > len(hotspots[j].blocked)
+		}
+		return hotspots[i].task.Created.Before(hotspots[j].task.Created)
+	})
+	if len(hotspots) > maxBlockerHotspots {
+		hotspots = hotspots[:maxBlockerHotspots]
+	}
+	return hotspots
+}
+
+// isFinishedState reports whether a task in this state no longer needs work
+func isFinishedState(state models.State) bool {
+	return state == models.StateDone || state == models.StateCancelled || state == models.StateInvalid
+}
+
+// writeMonthlyReport writes the monthly report as Markdown
+func writeMonthlyReport(w io.Writer, month time.Time, completed, intake []*models.Task, hotspots []blockerHotspot) error {
+	var sb strings.Builder
+	last := month.AddDate(0, 1, -1)
+
+	fmt.Fprintf(&sb, "# Monthly Report: %s\n\n", month.Format("January 2006"))
+	fmt.Fprintf(&sb, "Period: %s to %s\n\n", month.Format("2006-01-02"), last.Format("2006-01-02"))
+
+	sb.WriteString("## Summary\n\n")
+	fmt.Fprintf(&sb, "- **Completed:** %s\n", formatTaskCount(len(completed), "task"))
+	fmt.Fprintf(&sb, "- **New intake:** %s%s\n", formatTaskCount(len(intake), "task"), formatKindBreakdown(intake))
+	fmt.Fprintf(&sb, "- **Blocker hotspots:** %s\n\n", formatTaskCount(len(hotspots), "open blocker"))
+
+	sb.WriteString("## Completed Work\n\n")
+	if len(completed) == 0 {
+		sb.WriteString("No tasks were completed this month.\n\n")
+	}
+	for _, group := range groupByTag(completed) {
+		fmt.Fprintf(&sb, "### %s (%d)\n\n", group.tag, len(group.tasks))
+		for _, task := range group.tasks {
+			fmt.Fprintf(&sb, "- %s: %s (#%s, %s)\n", formatKind(task.Kind), task.Title, task.ShortHash(), task.Priority)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## New Intake\n\n")
+	if len(intake) == 0 {
+		sb.WriteString("No tasks were added this month.\n\n")
+	} else {
+		rows := make([][]string, len(intake))
+		for i, task := range intake {
+			rows[i] = []string{
+				"#" + task.ShortHash(), task.Kind.String(), task.Priority.String(), task.State.String(),
+				task.Title, task.Created.Format("2006-01-02"),
+			}
+		}
+		if err := output.WriteMarkdownTable(&sb, []string{"ID", "Type", "Priority", "State", "Title", "Created"}, rows); err != nil {
+			return err
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Blocker Hotspots\n\n")
+	if len(hotspots) == 0 {
+		sb.WriteString("No open tasks are blocking other work.\n")
+	} else {
+		sb.WriteString("Open tasks currently blocking the most other open tasks.\n\n")
+		rows := make([][]string, len(hotspots))
+		for i, hotspot := range hotspots {
+			var refs []string
+			for _, task := range hotspot.blocked {
+				refs = append(refs, "#"+task.ShortHash())
+			}
+			rows[i] = []string{
+				"#" + hotspot.task.ShortHash(), hotspot.task.State.String(), hotspot.task.Title,
+				fmt.Sprintf("%d (%s)", len(hotspot.blocked), strings.Join(refs, ", ")),
+			}
+		}
+		if err := output.WriteMarkdownTable(&sb, []string{"Blocker", "State", "Title", "Blocks"}, rows); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// tagGroup is a tag and the tasks carrying it
+type tagGroup struct {
+	tag   string
+	tasks []*models.Task
+}
+
+// groupByTag groups tasks by tag, alphabetically, followed by the untagged
+// tasks. Tasks with several tags appear in each of their groups.
+func groupByTag(tasks []*models.Task) []tagGroup {
+	byTag := make(map[string][]*models.Task)
+	var untagged []*models.Task
+	for _, task := range tasks {
+		tags := task.ParseTags()
+		if len(tags) == 0 {
+			untagged = append(untagged, task)
+		}
+		for _, tag := range tags {
+			byTag[tag] = append(byTag[tag], task)
+		}
+	}
+
+	var groups []tagGroup
+	for tag, tasks := range byTag {
+		groups = append(groups, tagGroup{tag: tag, tasks: tasks})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].tag < groups[j].tag })
+	if len(untagged) > 0 {
+		groups = append(groups, tagGroup{tag: "Untagged", tasks: untagged})
+	}
+	return groups
+}
+
+// formatKindBreakdown formats task counts per kind, e.g. " (2 bugs, 1 feature)"
+func formatKindBreakdown(tasks []*models.Task) string {
+	counts := make(map[models.Kind]int)
+	for _, task := range tasks {
+		counts[task.Kind]++
+	}
+
+	var parts []string
+	for _, kind := range []struct {
+		kind models.Kind
+		name string
+	}{
+		{models.KindBug, "bug"},
+		{models.KindFeature, "feature"},
+		{models.KindRegression, "regression"},
+	} {
+		if n := counts[kind.kind]; n > 0 {
+			parts = append(parts, formatTaskCount(n, kind.name))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestCycleTimeReport(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	create := func(kind models.Kind, title string, created time.Time) *models.Task {
+		task := models.NewTask(kind, title, "Description")
+		task.State = models.StateNew
+		task.Created = created
+		task.Updated = created
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	transition := func(task *models.Task, state models.State, at time.Time) {
+		if err := testRepo.UpdateStateAt(task.ID, state, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Bugs with lead times of 8d and 4d, one started 2d before completion
+	bug1 := create(models.KindBug, "Bug 1", days(10))
+	transition(bug1, models.StateInProgress, days(4))
+	transition(bug1, models.StateDone, days(2))
+
+	bug2 := create(models.KindBug, "Bug 2", days(5))
+	transition(bug2, models.StateDone, days(1))
+
+	// Completed before the period
+	old := create(models.KindFeature, "Old feature", days(200))
+	transition(old, models.StateDone, days(150))
+
+	// Still open
+	create(models.KindFeature, "Open feature", days(3))
+
+	run := func(args ...string) string {
+		var stdout bytes.Buffer
+		cmd := newCycleTimeReportCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return stdout.String()
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var report cycleTimeReport
+		if err := json.Unmarshal([]byte(run("--format", "json")), &report); err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Kinds) != 2 {
+			t.Fatalf("Expected BUG and ALL rows, got %+v", report.Kinds)
+		}
+		bugs := report.Kinds[0]
+		if bugs.Kind != models.KindBug.String() || bugs.Tasks != 2 {
+			t.Errorf("Unexpected bug stats: %+v", bugs)
+		}
+		if bugs.NewToDone.P50Hours != 96 || bugs.NewToDone.P90Hours != 192 {
+			t.Errorf("NewToDone = %+v, want p50 96h and p90 192h", bugs.NewToDone)
+		}
+		if bugs.InProgressToDone.Count != 1 || bugs.InProgressToDone.P50Hours != 48 {
+			t.Errorf("InProgressToDone = %+v, want one task at 48h", bugs.InProgressToDone)
+		}
+		if report.Kinds[1].Kind != "ALL" || report.Kinds[1].Tasks != 2 {
+			t.Errorf("Unexpected totals: %+v", report.Kinds[1])
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		output := run()
+		for _, want := range []string{"NEW→DONE", "IN_PROGRESS→DONE", "BUG", "4.0d", "8.0d", "2.0d", "ALL"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Output does not contain %q\nGot: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		records, err := csv.NewReader(strings.NewReader(run("--format", "csv"))).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[1][0] != models.KindBug.String() || records[1][3] != "96.00" {
+			t.Errorf("Unexpected CSV: %v", records)
+		}
+	})
+
+	t.Run("longer period includes older completions", func(t *testing.T) {
+		if output := run("--since", "365d"); !strings.Contains(output, "FEATURE") {
+			t.Errorf("Output does not contain FEATURE\nGot: %s", output)
+		}
+	})
+
+	t.Run("empty period", func(t *testing.T) {
+		if output := run("--since", "1h"); !strings.Contains(output, "No tasks completed") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+	})
+}
+
+func TestPercentiles(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 10; i++ {
+		durations = append(durations, time.Duration(i)*time.Hour)
+	}
+	got := percentiles(durations)
+	if got.Count != 10 || got.P50Hours != 5 || got.P90Hours != 9 {
+		t.Errorf("percentiles() = %+v, want count 10, p50 5, p90 9", got)
+	}
+	if got := percentiles(nil); got.Count != 0 {
+		t.Errorf("percentiles(nil) = %+v, want zero", got)
+	}
+}
+
+func TestMonthlyReport(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	june := time.Date(2024, time.June, 10, 12, 0, 0, 0, time.Local)
+	create := func(kind models.Kind, title, tags string, created time.Time) *models.Task {
+		task := models.NewTask(kind, title, "Description")
+		task.State = models.StateNew
+		task.Tags = tags
+		task.Created = created
+		task.Updated = created
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	fixed := create(models.KindBug, "Fix login", "backend,auth", june)
+	if err := testRepo.UpdateStateAt(fixed.ID, models.StateDone, june.AddDate(0, 0, 5)); err != nil {
+		t.Fatal(err)
+	}
+	untagged := create(models.KindFeature, "Dark mode", "", june.AddDate(0, 0, 1))
+	if err := testRepo.UpdateStateAt(untagged.ID, models.StateDone, june.AddDate(0, 0, 2)); err != nil {
+		t.Fatal(err)
+	}
+	// Completed the following month
+	late := create(models.KindFeature, "Late feature", "backend", june)
+	if err := testRepo.UpdateStateAt(late.ID, models.StateDone, june.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// Added before June, blocking two open tasks
+	blocker := create(models.KindBug, "Flaky CI | builds", "", june.AddDate(0, -1, 0))
+	for _, title := range []string{"Blocked one", "Blocked two"} {
+		task := create(models.KindFeature, title, "", june.AddDate(0, -1, 0))
+		if err := testRepo.Block(task.ID, blocker.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	cmd := newMonthlyReportCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--month", "2024-06"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"# Monthly Report: June 2024",
+		"Period: 2024-06-01 to 2024-06-30",
+		"- **Completed:** 2 tasks",
+		"- **New intake:** 3 tasks (1 bug, 2 features)",
+		"### auth (1)",
+		"### backend (1)\n\n- Bug: Fix login (#" + fixed.ShortHash() + ", medium)",
+		"### Untagged (1)\n\n- Feature: Dark mode",
+		"| #" + blocker.ShortHash() + " | NEW | Flaky CI \\| builds | 2 (",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output does not contain %q\nGot: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "- Feature: Late feature") {
+		t.Errorf("Tasks completed after the month should not be listed\nGot: %s", output)
+	}
+
+	t.Run("invalid month", func(t *testing.T) {
+		cmd := newMonthlyReportCommand(NewApp())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--month", "June"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid month") {
+			t.Errorf("Expected invalid month error, got %v", err)
+		}
+	})
+}
+
+func TestTimeReport(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	create := func(kind models.Kind, priority models.Priority, tags string) *models.Task {
+		task := models.NewTask(kind, "Task "+tags, "Description")
+		task.Priority = priority
+		task.Tags = tags
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	logWork := func(task *models.Task, started time.Time, d time.Duration) {
+		if _, err := testRepo.AddWorklog(task.ID, started, started.Add(d), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	backend := create(models.KindBug, models.PriorityHigh, "backend,api")
+	untagged := create(models.KindFeature, models.PriorityLow, "")
+	logWork(backend, now.Add(-3*time.Hour), 2*time.Hour)
+	logWork(untagged, now.Add(-5*time.Hour), time.Hour)
+	// Logged before the period
+	logWork(untagged, now.Add(-60*24*time.Hour), 5*time.Hour)
+
+	run := func(args ...string) string {
+		var stdout bytes.Buffer
+		cmd := newTimeReportCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return stdout.String()
+	}
+
+	t.Run("by tag", func(t *testing.T) {
+		var report timeReport
+		if err := json.Unmarshal([]byte(run("--format", "json")), &report); err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalHours != 3 || len(report.Groups) != 3 {
+			t.Fatalf("Unexpected report: %+v", report)
+		}
+		if first := report.Groups[0]; first.Name != "api" || first.Hours != 2 || first.HighHours != 2 || first.Share != 66.7 {
+			t.Errorf("Unexpected first group: %+v", first)
+		}
+		if last := report.Groups[2]; last.Name != untaggedGroup || last.LowHours != 1 {
+			t.Errorf("Unexpected untagged group: %+v", last)
+		}
+	})
+
+	t.Run("by kind table", func(t *testing.T) {
+		output := run("--by", "kind")
+		for _, want := range []string{"KIND", "BUG", "2h", "66.7%", "FEATURE", "Total: 3h"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Output does not contain %q\nGot: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		records, err := csv.NewReader(strings.NewReader(run("--by", "kind", "--format", "csv"))).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[1][0] != models.KindBug.String() || records[1][1] != "2.00" {
+			t.Errorf("Unexpected CSV: %v", records)
+		}
+	})
+
+	t.Run("empty period", func(t *testing.T) {
+		if output := run("--since", "1m"); !strings.Contains(output, "No time logged") {
+			t.Errorf("Unexpected output: %s", output)
+		}
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		cmd := newTimeReportCommand(NewApp())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--by", "priority"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected error for invalid grouping")
+		}
+	})
+}
@@ -2,14 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
 // newReviewCommand creates the review command
-func newReviewCommand() *cobra.Command {
-	var outputFormat string
+func newReviewCommand(app *App) *cobra.Command {
+	var (
+		outputFormat string
+		oldestFirst  bool
+		kind         string
+		limit        int
+	)
 
 	cmd := &cobra.Command{
 		Use:   "review",
@@ -20,44 +30,79 @@ Tasks in INBOX are items that need to be triaged and processed.
 Use 'gtd accept <task-id>' to accept a task (move from INBOX to NEW).
 Use 'gtd reject <task-id>' to reject a task (mark as INVALID).
 
+When GTD_ESCALATE_AFTER_DAYS is set, NEW tasks untouched for that long are
+escalated first (see 'gtd escalate').
+
 Note: You should complete your current active tasks before reviewing INBOX items.`,
 		Example: `  gtd review
   gtd review --output json
-  gtd review -o oneline`,
+  gtd review -o oneline
+  gtd review --oldest-first --kind bug --limit 10`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var kindFilter models.Kind
+			if kind != "" {
+				var err error
+				if kindFilter, err = models.ParseKind(kind); err != nil {
+					return err
+				}
+			}
+			if limit < 0 {
+				return errors.NewValidationError("invalid limit: %d (must not be negative)", limit)
+			}
+
 			// Check for active tasks first
-			activeTasks, err := repo.List(models.ListOptions{
-				ShowDone:      false,
-				ShowCancelled: false,
-				All:           false,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to check active tasks: %w", err)
+			if err := warnActiveWork(app, cmd.ErrOrStderr()); err != nil {
+				return err
 			}
 
-			if len(activeTasks) > 0 {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  Warning: You have %d active tasks. Consider completing them before reviewing INBOX.\n", len(activeTasks))
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "   Use 'gtd list' to see your active tasks.\n\n")
+			// Escalate stale tasks when the rule is configured
+			if days := app.Config().EscalateAfterDays; days > 0 {
+				count, err := escalateStaleTasks(cmd.ErrOrStderr(), days, false)
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+				}
 			}
 			tasks, err := repo.ListByState(models.StateInbox)
 			if err != nil {
 				return fmt.Errorf("failed to list inbox tasks: %w", err)
 			}
 
+			if kindFilter != "" {
+				filtered := tasks[:0]
+				for _, task := range tasks {
+					if task.Kind == kindFilter {
+						filtered = append(filtered, task)
+					}
+				}
+				tasks = filtered
+			}
+
 			if len(tasks) == 0 {
 				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No tasks in INBOX.")
 				return nil
 			}
 
-			switch outputFormat {
-			case "json":
-				return exportJSON(cmd.OutOrStdout(), tasks)
-			case "csv":
-				return exportCSV(cmd.OutOrStdout(), tasks)
-			case "markdown":
-				return exportMarkdown(cmd.OutOrStdout(), tasks)
-			default:
-				formatTaskList(cmd.OutOrStdout(), tasks, outputFormat == "oneline")
+			// Inbox tasks are listed newest first
+			if oldestFirst {
+				sort.SliceStable(tasks, func(i, j int) bool {
+					return tasks[i].Created.Before(tasks[j].Created)
+				})
+			}
+			total := len(tasks)
+			if limit > 0 && limit < total {
+				tasks = tasks[:limit]
+			}
+
+			if ok, err := writeTasksAs(cmd.OutOrStdout(), tasks, outputFormat); ok {
+				return err
+			}
+			formatReviewList(app, cmd.OutOrStdout(), tasks, outputFormat == "oneline")
+			rememberRecentTasks(cmd, tasks)
+			if len(tasks) < total {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nShowing %d of %d tasks in INBOX\n", len(tasks), total)
 			}
 
 			return nil
@@ -65,78 +110,216 @@ Note: You should complete your current active tasks before reviewing INBOX items
 	}
 
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: json, csv, markdown, oneline")
+	cmd.Flags().BoolVar(&oldestFirst, "oldest-first", false, "Show the oldest tasks first")
+	cmd.Flags().StringVar(&kind, "kind", "", "Only review tasks of this kind (bug, feature, regression)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of tasks to review (default: all)")
 
 	return cmd
 }
 
+// formatReviewList outputs inbox tasks with how long each has been waiting
+func formatReviewList(app *App, w io.Writer, tasks []*models.Task, oneline bool) {
+	for i, task := range tasks {
+		age := "added " + app.formatTimestamp(task.Created)
+		if oneline {
+			_, _ = fmt.Fprintf(w, "%s %s\n", app.taskFormatter(nil).Oneline(task, nil), colorize("("+age+")", colorGray))
+			continue
+		}
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprint(w, app.formatTaskGitStyle(task, nil))
+		_, _ = fmt.Fprintf(w, "    Age: %s\n", colorize(age, colorGray))
+	}
+}
+
 // newAcceptCommand creates the accept command to move tasks from INBOX to NEW
-func newAcceptCommand() *cobra.Command {
+func newAcceptCommand(app *App) *cobra.Command {
+	var (
+		flags  acceptFlags
+		dryRun bool
+	)
+
 	cmd := &cobra.Command{
 		Use:   "accept <task-id>",
 		Short: "Accept task from INBOX (move to NEW state)",
-		Long:  `Accept a task from INBOX state by moving it to NEW state, indicating it has been reviewed and accepted for work.`,
+		Long: `Accept a task from INBOX state by moving it to NEW state, indicating it has been reviewed and accepted for work.
+--priority, --tags, and --parent adjust the task in the same step, so triage
+doesn't need separate commands afterwards.`,
 		Example: `  gtd accept abc123
-  gtd accept 1a2b3c4`,
-		Args:  cobra.ExactArgs(1),
+  gtd accept 1a2b3c4
+  gtd accept abc123 --priority high --tags backend --parent def456`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
 
 			// Find the task
 			task, err := repo.GetByID(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
 
 			// Check current state
 			if task.State != models.StateInbox {
-				return fmt.Errorf("task %s is not in INBOX state (current: %s)", task.ID[:7], task.State)
+				return errors.NewInvalidStateTransitionErrorf(task.State, models.StateNew, "task %s is not in INBOX state (current: %s)", task.ShortHash(), task.State)
+			}
+
+			changes, err := flags.apply(task)
+			if err != nil {
+				return err
+			}
+			if _, err := repo.CheckTransition(task.ID, models.StateNew, time.Now()); err != nil {
+				return err
+			}
+
+			if dryRun {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would accept task %s (move from INBOX to NEW)\n", task.ShortHash())
+				if len(changes) > 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would set %s\n", strings.Join(changes, ", "))
+				}
+				return nil
+			}
+
+			// Adjust the task before it leaves the inbox
+			if len(changes) > 0 {
+				if err := repo.Update(task); err != nil {
+					return err
+				}
 			}
 
 			// Update to NEW state
-			if err := repo.UpdateState(task.ID, models.StateNew); err != nil {
+			if err := newTaskService(app, cmd).UpdateTaskState(task.ID, models.StateNew); err != nil {
 				return fmt.Errorf("failed to update task state: %w", err)
 			}
 
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s accepted (moved from INBOX to NEW)\n", task.ID[:7])
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s accepted (moved from INBOX to NEW)\n", task.ShortHash())
+			if len(changes) > 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Set %s\n", strings.Join(changes, ", "))
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVarP(&flags.priority, "priority", "p", "",
+		"Set the task priority (high, medium, low)")
+	cmd.Flags().StringVarP(&flags.tags, "tags", "t", "",
+		"Replace the task's tags (comma-separated)")
+	cmd.Flags().StringVar(&flags.parent, "parent", "",
+		"Make the task a subtask of this task")
+	addDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
 
+// acceptFlags are the adjustments accept makes to a task as it is triaged
+type acceptFlags struct {
+	priority string
+	tags     string
+	parent   string
+}
+
+// apply makes the adjustments to task, returning a description of each
+// change. A parent must exist and must not be the task or one of its
+// subtasks.
+func (f *acceptFlags) apply(task *models.Task) ([]string, error) {
+	var changes []string
+	if f.priority != "" {
+		priority, err := models.ParsePriority(f.priority)
+		if err != nil {
+			return nil, err
+		}
+		task.Priority = priority
+		changes = append(changes, "priority "+priority.String())
+	}
+	if f.tags != "" {
+		task.SetTags((&models.Task{Tags: f.tags}).ParseTags())
+		changes = append(changes, "tags "+task.Tags)
+	}
+	if f.parent != "" {
+		parent, err := repo.GetByID(f.parent)
+		if err != nil {
+			return nil, fmt.Errorf("parent task not found: %w", err)
+		}
+		ancestors, err := repo.GetAncestors(parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range append(ancestors, parent) {
+			if ancestor.ID == task.ID {
+				return nil, errors.NewValidationError("task %s cannot be a subtask of itself or of its own subtask %s", task.ShortHash(), parent.ShortHash())
+			}
+		}
+		task.Parent = &parent.ID
+		changes = append(changes, "parent "+parent.ShortHash())
+	}
+	return changes, nil
+}
+
 // newRejectCommand creates the reject command to mark tasks as INVALID
-func newRejectCommand() *cobra.Command {
+func newRejectCommand(app *App) *cobra.Command {
+	var (
+		reason string
+		yes    bool
+		dryRun bool
+	)
+
 	cmd := &cobra.Command{
 		Use:   "reject <task-id>",
 		Short: "Reject task from INBOX (mark as INVALID)",
-		Long:  `Reject a task from INBOX state by marking it as INVALID, indicating it should not be worked on.`,
+		Long: `Reject a task from INBOX state by marking it as INVALID, indicating it should not be worked on.
+The --reason is recorded in the task's history; set GTD_REQUIRE_REASON to make it mandatory.
+With GTD_CONFIRM_DONE set, reject asks for confirmation in a terminal unless --yes is given.`,
 		Example: `  gtd reject abc123
-  gtd reject 1a2b3c4`,
+  gtd reject 1a2b3c4 --reason "duplicate of the login bug"`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkReason(app, reason); err != nil {
+				return err
+			}
+
 			taskID := args[0]
 
 			// Find the task
 			task, err := repo.GetByID(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
 
 			// Check if task can be marked invalid
 			if task.State == models.StateDone {
-				return fmt.Errorf("cannot mark completed task as invalid")
+				return errors.NewInvalidStateTransitionErrorf(task.State, models.StateInvalid, "cannot mark completed task as invalid")
+			}
+
+			if dryRun {
+				if _, err := repo.CheckTransition(task.ID, models.StateInvalid, time.Now()); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would reject task %s (mark as INVALID)\n", task.ShortHash())
+				return nil
+			}
+
+			if err := confirmAction(app, cmd, yes, fmt.Sprintf("Reject %s: %s?", task.ShortHash(), task.Title)); err != nil {
+				return err
 			}
 
 			// Update to INVALID state
-			if err := repo.UpdateState(task.ID, models.StateInvalid); err != nil {
+			if err := newTaskService(app, cmd).UpdateTaskState(task.ID, models.StateInvalid); err != nil {
 				return fmt.Errorf("failed to update task state: %w", err)
 			}
+			if reason != "" {
+				if err := repo.RecordReason(task.ID, reason); err != nil {
+					return err
+				}
+			}
 
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s rejected (marked as INVALID)\n", task.ID[:7])
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s rejected (marked as INVALID)\n", task.ShortHash())
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the task is rejected")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	addDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
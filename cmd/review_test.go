@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestReviewCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	for i, item := range []struct {
+		kind  models.Kind
+		title string
+	}{
+		{models.KindBug, "Oldest bug"},
+		{models.KindFeature, "Middle feature"},
+		{models.KindBug, "Newest bug"},
+	} {
+		task := models.NewTask(item.kind, item.title, "Inbox item description")
+		task.Created = time.Now().Add(-time.Duration(3-i) * 24 * time.Hour)
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		order    []string
+		excluded []string
+		contains []string
+	}{
+		{
+			name:     "newest first with ages",
+			args:     []string{"-o", "oneline"},
+			order:    []string{"Newest bug", "Middle feature", "Oldest bug"},
+			contains: []string{"(added 3 days ago)"},
+		},
+		{
+			name:  "oldest first",
+			args:  []string{"-o", "oneline", "--oldest-first"},
+			order: []string{"Oldest bug", "Middle feature", "Newest bug"},
+		},
+		{
+			name:     "kind filter",
+			args:     []string{"--kind", "bug"},
+			order:    []string{"Newest bug", "Oldest bug"},
+			excluded: []string{"Middle feature"},
+			contains: []string{"Age: added 1 day ago"},
+		},
+		{
+			name:     "limit",
+			args:     []string{"-o", "oneline", "--oldest-first", "--limit", "2"},
+			order:    []string{"Oldest bug", "Middle feature"},
+			excluded: []string{"Newest bug"},
+			contains: []string{"Showing 2 of 3 tasks in INBOX"},
+		},
+		{
+			name:    "invalid kind",
+			args:    []string{"--kind", "chore"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newReviewCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			output := stdout.String()
+			last := -1
+			for _, title := range tt.order {
+				index := strings.Index(output, title)
+				if index <= last {
+					t.Errorf("Expected %q after the previous tasks\nGot: %s", title, output)
+				}
+				last = index
+			}
+			for _, title := range tt.excluded {
+				if strings.Contains(output, title) {
+					t.Errorf("Output should not contain %q\nGot: %s", title, output)
+				}
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("Output does not contain %q\nGot: %s", want, output)
+				}
+			}
+		})
+	}
+}
@@ -2,33 +2,75 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
 var (
-	// Version is set at build time
-	Version = "dev"
+	// Version, Commit, and BuildDate are set at build time with -ldflags "-X";
+	// see the Makefile. Without them, gtd version falls back to the module
+	// and VCS information Go embeds in the binary.
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
 
 	// Global database and repository instances - DEPRECATED: use App instead
 	db   *database.Database
-	repo *models.TaskRepository
+	repo models.Store
+
+	// jsonErrors reports errors as JSON on stderr (--json)
+	jsonErrors bool
+
+	// asciiOutput replaces state glyphs with bracketed words (--ascii)
+	asciiOutput bool
+
+	// databasePath selects the database file (--db)
+	databasePath string
 )
 
 // NewRootCommand creates the root command with the provided app instance
 func NewRootCommand(app *App) *cobra.Command {
+	var profile *commandProfile
+
 	rootCmd := &cobra.Command{
 		Use:   "gtd",
 		Short: "A SQLite-driven CLI task management tool",
 		Long: `gtd is a task management tool following GTD methodology.
-It stores tasks per-project in a claude-tasks.db file at the git repository root.`,
+It stores tasks per-project in a claude-tasks.db file at the git repository root.
+Run without a command, it shows this help, or runs the command named by
+GTD_DEFAULT_COMMAND (summary, list, inbox, or focus).`,
 		Version: Version,
+		// Report unknown commands with suggestions instead of cobra's default message
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil
+			}
+			var available []string
+			for _, sub := range cmd.Commands() {
+				if sub.IsAvailableCommand() {
+					available = append(available, sub.Name())
+					available = append(available, sub.Aliases...)
+				}
+			}
+			cmd.SilenceUsage = true
+			return errors.NewInvalidCommandError(args[0], available)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDefaultCommand(cmd, app)
+		},
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Skip DB initialization for help and version commands
-			if cmd.Name() == "help" || cmd.Name() == "version" {
+			if cmd.Name() == "help" || cmd.Name() == "version" || !cmd.HasParent() {
 				return nil
 			}
 			if cmd.Parent() != nil && cmd.Parent().Name() == "help" {
@@ -36,54 +78,226 @@ It stores tasks per-project in a claude-tasks.db file at the git repository root
 			}
 
 			// Initialize the app
+			if profileCommand {
+				profile = startProfile()
+			}
+			app.SetDatabasePath(databasePath)
+			app.SetSkipSchema(cmd.Annotations[annotationSkipSchema] != "")
 			if err := app.Initialize(); err != nil {
 				return err
 			}
 
 			// Apply configuration
-			SetColorEnabled(app.Config().ColorEnabled)
+			cfg := app.Config()
+			SetColorEnabled(cfg.ColorEnabled)
+			icons := output.UnicodeIcons
+			if asciiOutput || cfg.ASCII {
+				icons = output.ASCIIIcons
+			}
+			output.SetIcons(icons.With(cfg.Icons))
+			models.SetShortHashLength(cfg.HashLength)
+
+			// Let the user pick between tasks matching an ambiguous prefix
+			if isInteractive() {
+				app.Repository().SetChooser(newTaskChooser(app, cmd.InOrStdin(), cmd.ErrOrStderr()))
+			}
 
 			// Set global variables for backward compatibility
 			// TODO: Remove these once all commands are refactored
 			db = app.db
 			repo = app.repo
 
+			if profile != nil {
+				profile.markOpened()
+			}
+
 			return nil
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
-			return app.Close()
+			if profile == nil {
+				return app.Close()
+			}
+			profile.markRan()
+			err := app.Close()
+			profile.write(cmd.ErrOrStderr())
+			database.SetProfiler(nil)
+			profile = nil
+			return err
 		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json", false,
+		"Report errors as JSON with a machine-readable code, and print gtd version and summary as JSON")
+
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false,
+		"Show states as bracketed words instead of Unicode symbols (or set GTD_ASCII=1)")
+
+	rootCmd.PersistentFlags().StringVar(&databasePath, "db", "",
+		"Database file to use, overriding GTD_DATABASE_PATH and the git root")
+
+	rootCmd.PersistentFlags().BoolVar(&profileCommand, "profile", false,
+		"Report the time spent opening the database, in queries, and formatting on stderr")
+
+	rootCmd.PersistentFlags().DurationVar(&slowQueryThreshold, "slow-query", 50*time.Millisecond,
+		"With --profile, list SQL statements slower than this")
+
+	// Bad flags are invalid input, like other validation errors
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		cmd.SilenceUsage = jsonErrors
+		return &errors.ValidationError{Message: err.Error()}
+	})
+
+	// Keep stderr parseable when errors are reported as JSON
+	usage := rootCmd.UsageFunc()
+	rootCmd.SetUsageFunc(func(cmd *cobra.Command) error {
+		if jsonErrors {
+			return nil
+		}
+		return usage(cmd)
+	})
+
 	// Add commands
 	rootCmd.AddCommand(
-		newAddCommand(),
-		newAddSubtaskCommand(),
-		newInProgressCommand(),
-		newDoneCommand(),
-		newCancelCommand(),
-		newBlockCommand(),
-		newUnblockCommand(),
-		newListCommand(),
-		newListDoneCommand(),
-		newListCancelledCommand(),
-		newShowCommand(),
-		newSearchCommand(),
-		newSummaryCommand(),
-		newExportCommand(),
-		newReviewCommand(),
-		newAcceptCommand(),
-		newRejectCommand(),
-		newReopenCommand(),
+		newAddCommand(app),
+		newAddSubtaskCommand(app),
+		newInProgressCommand(app),
+		newDoneCommand(app),
+		newCancelCommand(app),
+		newBlockCommand(app),
+		newUnblockCommand(app),
+		newListCommand(app),
+		newListDoneCommand(app),
+		newListCancelledCommand(app),
+		newShowCommand(app),
+		newSearchCommand(app),
+		newSummaryCommand(app),
+		newExportCommand(app),
+		newReviewCommand(app),
+		newAcceptCommand(app),
+		newRejectCommand(app),
+		newReopenCommand(app),
+		newFocusCommand(app),
+		newBlameCommand(app),
+		newActivityCommand(app),
+		newDiffCommand(app),
+		newDedupeCommand(app),
+		newAttachCommand(app),
+		newLinkCommand(app),
+		newOpenRefCommand(app),
+		newScanCommand(app),
+		newCaptureCommand(app),
+		newEscalateCommand(app),
+		newReportCommand(app),
+		newPickCommand(app),
+		newPomodoroCommand(app),
+		newWatchCommand(app),
+		newServeCommand(app),
+		newRPCCommand(app),
+		newImportCommand(app),
+		newInboxCommand(app),
+		newMoveCommand(app),
+		newPinCommand(app),
+		newUnpinCommand(app),
+		newSetCommand(app),
+		newConvertCommand(app),
+		newCountCommand(app),
+		newExistsCommand(app),
+		newCheckCommand(app),
+		newMigrateCommand(app),
+		newBenchCommand(app),
+		newOpenCommand(app),
+		newVersionCommand(app),
+		newDigestCommand(app),
+		newCronCommand(app),
 	)
+	validateArgsAsInput(rootCmd)
 
 	return rootCmd
 }
 
-// Execute runs the root command
+// runDefaultCommand runs the command GTD_DEFAULT_COMMAND names for a bare
+// gtd, with its default flags, or shows help when none is configured
+func runDefaultCommand(root *cobra.Command, app *App) error {
+	cfg := app.Config()
+	if err := cfg.Load(); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.DefaultCommand == "" || cfg.DefaultCommand == "help" {
+		return root.Help()
+	}
+
+	sub, _, err := root.Find([]string{cfg.DefaultCommand})
+	if err != nil {
+		return err
+	}
+	if err := root.PersistentPreRunE(sub, nil); err != nil {
+		return err
+	}
+	return sub.RunE(sub, nil)
+}
+
+// validateArgsAsInput makes wrong argument counts on subcommands validation
+// errors, like bad flags
+func validateArgsAsInput(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		if validate := sub.Args; validate != nil {
+			sub.Args = func(cmd *cobra.Command, args []string) error {
+				if err := validate(cmd, args); err != nil {
+					cmd.SilenceUsage = jsonErrors
+					return &errors.ValidationError{Message: err.Error()}
+				}
+				return nil
+			}
+		}
+		validateArgsAsInput(sub)
+	}
+}
+
+// interruptGrace is how long a command gets to finish after an interrupt
+// before gtd closes the database and exits anyway
+const interruptGrace = 2 * time.Second
+
+// Execute runs the root command, exiting with a status that reflects the
+// error's code on failure
 func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// A second interrupt ends gtd at once
+		<-ctx.Done()
+		stop()
+	}()
+
 	app := NewApp()
-	if err := NewRootCommand(app).Execute(); err != nil {
-		os.Exit(1)
+	rootCmd := NewRootCommand(app)
+	rootCmd.SilenceErrors = true
+	err := executeApp(ctx, rootCmd, app)
+	stop()
+	if err != nil {
+		reportError(rootCmd.ErrOrStderr(), err)
+		os.Exit(errors.ExitCode(err))
+	}
+}
+
+// executeApp runs the root command and closes the app however it ends, which
+// encrypts an encrypted database back and removes its decrypted copy. Cobra
+// skips PersistentPostRunE when the command fails, and a command that doesn't
+// stop when ctx is cancelled is given up on after interruptGrace.
+func executeApp(ctx context.Context, rootCmd *cobra.Command, app *App) error {
+	done := make(chan error, 1)
+	go func() { done <- rootCmd.ExecuteContext(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		select {
+		case err = <-done:
+		case <-time.After(interruptGrace):
+			err = errors.ExitStatus(130)
+		}
+	}
+	if closeErr := app.Close(); err == nil {
+		err = closeErr
 	}
+	return err
 }
@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/zw3rk/gtd/internal/database"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -31,6 +36,12 @@ func TestRootCommand(t *testing.T) {
 			wantErr:  true,
 			contains: []string{"unknown command"},
 		},
+		{
+			name:     "mistyped command suggests similar",
+			args:     []string{"lst"},
+			wantErr:  true,
+			contains: []string{"unknown command: lst", "Did you mean: list?"},
+		},
 		{
 			name:     "version flag",
 			args:     []string{"--version"},
@@ -89,6 +100,34 @@ func TestCommandStructure(t *testing.T) {
 		"accept",
 		"reject",
 		"reopen",
+		"focus",
+		"blame",
+		"activity",
+		"diff",
+		"dedupe",
+		"attach",
+		"link",
+		"open-ref",
+		"scan",
+		"capture",
+		"escalate",
+		"report",
+		"pick",
+		"pomodoro",
+		"watch",
+		"serve",
+		"rpc",
+		"import",
+		"inbox",
+		"move",
+		"pin",
+		"unpin",
+		"check",
+		"migrate",
+		"bench",
+		"open",
+		"version",
+		"digest",
 	}
 
 	// Get all subcommands
@@ -105,3 +144,163 @@ func TestCommandStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestDBFlag(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "other.db")
+	t.Setenv("GTD_DATABASE_PATH", filepath.Join(t.TempDir(), "ignored.db"))
+
+	var stdout bytes.Buffer
+	rootCmd := NewRootCommand(NewApp())
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetIn(strings.NewReader("Title from --db\n\nDescription"))
+	rootCmd.SetArgs([]string{"--db", dbPath, "add", "bug"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("Expected the task to be stored in the --db file: %v", err)
+	}
+	if _, err := os.Stat(os.Getenv("GTD_DATABASE_PATH")); err == nil {
+		t.Error("Expected --db to override GTD_DATABASE_PATH")
+	}
+}
+
+func TestDefaultCommand(t *testing.T) {
+	t.Setenv("GTD_DATABASE_PATH", filepath.Join(t.TempDir(), "default.db"))
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd := NewRootCommand(NewApp())
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(&stdout)
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Setenv("GTD_DEFAULT_COMMAND", "summary")
+	out, err := run()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out, "Task Summary") || strings.Contains(out, "Usage") {
+		t.Errorf("bare gtd should run summary, got:\n%s", out)
+	}
+
+	// --help is unchanged
+	out, err = run("--help")
+	if err != nil || !strings.Contains(out, "Available Commands") {
+		t.Errorf("--help = %v, %q", err, out)
+	}
+
+	t.Setenv("GTD_DEFAULT_COMMAND", "list")
+	if out, err = run(); err != nil || !strings.Contains(out, "No tasks found.") {
+		t.Errorf("bare gtd should run list, got %v, %q", err, out)
+	}
+
+	t.Setenv("GTD_DEFAULT_COMMAND", "help")
+	if out, err = run(); err != nil || !strings.Contains(out, "Usage") {
+		t.Errorf("bare gtd should show help, got %v, %q", err, out)
+	}
+
+	t.Setenv("GTD_DEFAULT_COMMAND", "delete")
+	if _, err = run(); err == nil || !strings.Contains(err.Error(), "invalid GTD_DEFAULT_COMMAND") {
+		t.Errorf("Execute() error = %v, want invalid GTD_DEFAULT_COMMAND", err)
+	}
+}
+
+func TestProfileFlag(t *testing.T) {
+	t.Setenv("GTD_DATABASE_PATH", filepath.Join(t.TempDir(), "profile.db"))
+
+	var stdout, stderr bytes.Buffer
+	rootCmd := NewRootCommand(NewApp())
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs([]string{"--profile", "--slow-query", "0s", "list"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), "No tasks found.") || strings.Contains(stdout.String(), "Profile:") {
+		t.Errorf("the profile should only go to stderr, got stdout:\n%s", stdout.String())
+	}
+	for _, want := range []string{"Profile:", "open database", "queries", "statement", "formatting", "total", "Slow statements (over 0s):", "SELECT id, parent"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("profile does not contain %q\nGot:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestExecuteAppClosesOnError(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "encrypted.db")
+	t.Setenv("GTD_DB_KEY", "passphrase")
+
+	// A failing command skips PersistentPostRunE
+	app := NewApp()
+	rootCmd := NewRootCommand(app)
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"--db", dbPath, "show", "0123456789abcdef"})
+	if err := executeApp(context.Background(), rootCmd, app); err == nil {
+		t.Fatal("Expected an error for an unknown task")
+	}
+
+	if encrypted, err := database.IsEncrypted(dbPath); err != nil || !encrypted {
+		t.Errorf("Expected the database to be encrypted after a failing command, got %v, %v", encrypted, err)
+	}
+	reopened, err := database.NewEncrypted(dbPath, "passphrase")
+	if err != nil {
+		t.Fatalf("Expected the database to be unlocked after a failing command: %v", err)
+	}
+	_ = reopened.Close()
+}
+
+func TestDBFlagOutsideGitRepo(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+
+	run := func(stdin string, args ...string) (string, error) {
+		var stdout bytes.Buffer
+		app := NewApp()
+		rootCmd := NewRootCommand(app)
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetIn(strings.NewReader(stdin))
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		_ = app.Close()
+		return stdout.String(), err
+	}
+
+	if _, err := run("Outside git\n\nDescription", "--db", dbPath, "add", "bug"); err != nil {
+		t.Fatalf("add with --db outside a git repository: %v", err)
+	}
+	out, err := run("", "--db", dbPath, "list", "--all")
+	if err != nil {
+		t.Fatalf("list with --db outside a git repository: %v", err)
+	}
+	if !strings.Contains(out, "Outside git") {
+		t.Errorf("Expected the task in the list, got:\n%s", out)
+	}
+
+	// Without a database path, gtd still needs the repository to find one
+	t.Setenv("GTD_DATABASE_PATH", "")
+	if _, err := run("", "list"); err == nil || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Errorf("list outside a git repository error = %v, want not in a git repository", err)
+	}
+}
+
+func TestDatabaseURLWithKey(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("GTD_DATABASE_URL", "postgres://gtd@localhost/gtd")
+	t.Setenv("GTD_DB_KEY", "passphrase")
+
+	rootCmd := NewRootCommand(NewApp())
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"list"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "can't be combined with GTD_DATABASE_URL") {
+		t.Errorf("Execute() error = %v, want GTD_DB_KEY refused with GTD_DATABASE_URL", err)
+	}
+}
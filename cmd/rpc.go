@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/server"
+)
+
+// newRPCCommand creates the rpc command
+func newRPCCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpc",
+		Short: "Answer JSON-RPC requests on stdin for editor plugins",
+		Long: `Run as a persistent process answering JSON-RPC 2.0 requests, one per line
+on stdin, with one response per line on stdout. Editor plugins can keep the
+process open instead of running gtd for every request. Exits when stdin is
+closed.
+
+Methods and their named parameters:
+  list        state, priority, kind, tag, all, limit
+  show        id
+  add         kind, title, description, priority, tags, source, ref
+  transition  id, action (accept, reject, start, done, cancel, reopen), reason
+  search      query
+
+Task IDs are resolved as on the command line.`,
+		Example: `  echo '{"jsonrpc":"2.0","id":1,"method":"list","params":{"tag":"ui"}}' | gtd rpc`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv := server.New(repo, server.Config{Rules: app.ValidationRules(), RequireReason: app.Config().RequireReason})
+			srv.Subscribe(notifyListener(app, cmd.ErrOrStderr()))
+			return srv.ServeRPC(cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/git"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// gtdComment matches TODO(gtd) and FIXME(gtd) markers and captures the text after them
+var gtdComment = regexp.MustCompile(`\b(TODO|FIXME)\(gtd\):?\s*(.*)$`)
+
+// codeComment is a TODO(gtd) or FIXME(gtd) comment found in a tracked file
+type codeComment struct {
+	Marker string // TODO or FIXME
+	Text   string
+	File   string // relative to the repository root
+	Line   int
+}
+
+// Source returns the file:line location of the comment
+func (c codeComment) Source() string {
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// Fingerprint identifies the comment independently of its line number, so
+// moving code around does not import it again
+func (c codeComment) Fingerprint() string {
+	sum := sha256.Sum256([]byte(c.File + "\x00" + c.Marker + "\x00" + c.Text))
+	return hex.EncodeToString(sum[:])
+}
+
+// newScanCommand creates the scan command
+func newScanCommand(app *App) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "scan [PATH]",
+		Short: "Import TODO(gtd) and FIXME(gtd) comments into the inbox",
+		Long: `Search files tracked by git for TODO(gtd) and FIXME(gtd) comments and create
+an INBOX task for each one, with its source set to file:line. TODO comments
+become features and FIXME comments become bugs.
+
+Comments that were imported before are skipped, even if they have moved to
+another line, so scan can be run repeatedly.`,
+		Example: `  gtd scan
+  gtd scan internal/
+  gtd scan --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			root, err := git.FindGitRoot(".")
+			if err != nil {
+				return err
+			}
+			files, err := git.TrackedFiles(path)
+			if err != nil {
+				return err
+			}
+
+			var comments []codeComment
+			for _, file := range files {
+				found, err := scanFile(root, file)
+				if err != nil {
+					return err
+				}
+				comments = append(comments, found...)
+			}
+
+			out := cmd.OutOrStdout()
+			imported, skipped := 0, 0
+			var fingerprints []string
+			var tasks []*models.Task
+			for _, comment := range comments {
+				done, err := repo.IsImported(comment.Fingerprint())
+				if err != nil {
+					return err
+				}
+				if done {
+					skipped++
+					continue
+				}
+
+				if dryRun {
+					_, _ = fmt.Fprintf(out, "Would import %s %s: %s\n", comment.Marker, comment.Source(), comment.Text)
+					imported++
+					continue
+				}
+				fingerprints = append(fingerprints, comment.Fingerprint())
+				tasks = append(tasks, commentTask(comment))
+			}
+
+			// Create the tasks and remember their comments in one transaction
+			if err := repo.CreateFromComments(tasks, fingerprints); err != nil {
+				return fmt.Errorf("failed to import comments: %w", err)
+			}
+			for _, task := range tasks {
+				_, _ = fmt.Fprintln(out, app.formatTaskOneline(task))
+				imported++
+			}
+
+			if dryRun {
+				_, _ = fmt.Fprintf(out, "Would import %d comment(s), %d already imported\n", imported, skipped)
+			} else {
+				_, _ = fmt.Fprintf(out, "Imported %d comment(s), %d already imported\n", imported, skipped)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the comments that would be imported without creating tasks")
+
+	return cmd
+}
+
+// scanFile returns the gtd comments in a tracked file. Binary and missing
+// files are skipped.
+func scanFile(root, file string) ([]codeComment, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Deleted from the working tree but still tracked
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+	return scanComments(bytes.NewReader(data), file)
+}
+
+// scanComments finds TODO(gtd) and FIXME(gtd) comments in r
+func scanComments(r io.Reader, file string) ([]codeComment, error) {
+	var comments []codeComment
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		m := gtdComment.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(m[2])
+		// Drop the closing delimiter of block comments
+		for _, closer := range []string{"*/", "-->", "#}", "%>"} {
+			text = strings.TrimSpace(strings.TrimSuffix(text, closer))
+		}
+		if text == "" {
+			continue
+		}
+		comments = append(comments, codeComment{Marker: m[1], Text: text, File: file, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", file, err)
+	}
+	return comments, nil
+}
+
+// commentTask builds the INBOX task for a code comment
+func commentTask(comment codeComment) *models.Task {
+	kind := models.KindFeature
+	if comment.Marker == "FIXME" {
+		kind = models.KindBug
+	}
+	description := fmt.Sprintf("Imported from a %s(gtd) comment in %s.", comment.Marker, comment.Source())
+	task := models.NewTask(kind, comment.Text, description)
+	task.Source = comment.Source()
+	return task
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestScanComments(t *testing.T) {
+	input := `package main
+
+// TODO(gtd): Support config files
+func main() {
+	// TODO: not for gtd
+	run() // FIXME(gtd) Crash on empty input
+	/* TODO(gtd): Handle signals */
+	// TODO(gtd):
+}
+`
+	comments, err := scanComments(strings.NewReader(input), "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []codeComment{
+		{Marker: "TODO", Text: "Support config files", File: "main.go", Line: 3},
+		{Marker: "FIXME", Text: "Crash on empty input", File: "main.go", Line: 6},
+		{Marker: "TODO", Text: "Handle signals", File: "main.go", Line: 7},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i := range want {
+		if comments[i] != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, comments[i], want[i])
+		}
+	}
+
+	// Fingerprints ignore the line number
+	moved := want[0]
+	moved.Line = 10
+	if moved.Fingerprint() != want[0].Fingerprint() {
+		t.Error("fingerprint changed when the comment moved")
+	}
+	if want[0].Fingerprint() == want[2].Fingerprint() {
+		t.Error("different comments share a fingerprint")
+	}
+}
+
+func TestScanCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	source := "package main\n\n// TODO(gtd): Support config files\n// FIXME(gtd): Crash on empty input\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "untracked.go"), []byte("// TODO(gtd): Ignored\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "main.go"}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newScanCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return stdout.String()
+	}
+
+	if out := run("--dry-run"); !strings.Contains(out, "Would import 2 comment(s)") {
+		t.Errorf("dry run output = %s", out)
+	}
+	if out := run(); !strings.Contains(out, "Imported 2 comment(s), 0 already imported") {
+		t.Errorf("first scan output = %s", out)
+	}
+	if out := run(); !strings.Contains(out, "Imported 0 comment(s), 2 already imported") {
+		t.Errorf("second scan output = %s", out)
+	}
+
+	tasks, err := testRepo.List(models.ListOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	sources := map[string]string{}
+	for _, task := range tasks {
+		if task.State != models.StateInbox {
+			t.Errorf("task %q state = %s, want INBOX", task.Title, task.State)
+		}
+		sources[task.Title] = task.Source + " " + task.Kind.String()
+	}
+	if got := sources["Support config files"]; got != "main.go:3 "+models.KindFeature.String() {
+		t.Errorf("TODO task = %q", got)
+	}
+	if got := sources["Crash on empty input"]; got != "main.go:4 "+models.KindBug.String() {
+		t.Errorf("FIXME task = %q", got)
+	}
+}
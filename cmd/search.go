@@ -2,35 +2,80 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
+// snippetRadius is how much context is shown around a search match
+const snippetRadius = 30
+
 // newSearchCommand creates the search command
-func newSearchCommand() *cobra.Command {
-	var oneline bool
+func newSearchCommand(app *App) *cobra.Command {
+	var (
+		oneline      bool
+		outputFormat string
+		all          bool
+		limit        int
+		in           string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "search QUERY",
 		Short: "Search tasks",
-		Long: `Search for tasks by looking in title and description fields.
-The search is case-insensitive and matches partial words.`,
+		Long: `Search for tasks by looking in title and description fields, or in the
+fields given with --in: title, description (or desc), tags, source, and author.
+The search is case-insensitive and matches partial words.
+
+Results are ordered by relevance: matches in the title come before matches in
+the description, and whole words before parts of words. When only the
+other fields match, a snippet around the match is shown with the match
+highlighted. Like list, DONE and CANCELLED tasks are only searched with --all.`,
 		Example: `  claude-gtd search "memory leak"
   claude-gtd search database
-  claude-gtd search --oneline connection`,
+  claude-gtd search --oneline connection
+  claude-gtd search --output json crash
+  claude-gtd search --all --limit 0 crash
+  claude-gtd search --in title,desc,tags backend
+  claude-gtd search --in source main.go`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Join all args to form the search query
 			query := strings.Join(args, " ")
 
+			fields, err := parseSearchFields(in)
+			if err != nil {
+				return err
+			}
+			format, err := app.resolveListFormat(outputFormat, oneline)
+			if err != nil {
+				return err
+			}
+
 			// Search tasks
-			tasks, err := repo.Search(query)
+			matches, err := repo.Search(query, fields...)
 			if err != nil {
 				return fmt.Errorf("search failed: %w", err)
 			}
+			var tasks []*models.Task
+			for _, task := range matches {
+				if all || (task.State != models.StateDone && task.State != models.StateCancelled) {
+					tasks = append(tasks, task)
+				}
+			}
+			total := len(tasks)
+			if limit > 0 && limit < total {
+				tasks = tasks[:limit]
+			}
 
 			// Format and output
+			if ok, err := writeTasksAs(cmd.OutOrStdout(), tasks, format); ok {
+				return err
+			}
 			if len(tasks) == 0 {
 				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No tasks found.")
 			} else {
@@ -38,7 +83,11 @@ The search is case-insensitive and matches partial words.`,
 				_, _ = fmt.Fprintln(cmd.OutOrStdout(), strings.Repeat("=", 50))
 				_, _ = fmt.Fprintln(cmd.OutOrStdout())
 
-				formatTaskList(cmd.OutOrStdout(), tasks, oneline)
+				formatSearchResults(app, cmd.OutOrStdout(), tasks, query, fields, format == output.FormatOneline)
+				rememberRecentTasks(cmd, tasks)
+				if len(tasks) < total {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nShowing %d of %d matching tasks\n", len(tasks), total)
+				}
 			}
 
 			return nil
@@ -46,6 +95,69 @@ The search is case-insensitive and matches partial words.`,
 	}
 
 	cmd.Flags().BoolVar(&oneline, "oneline", false, "Show results in compact format")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: standard, oneline, json, csv, markdown [default: GTD_DEFAULT_FORMAT or standard]")
+	cmd.Flags().BoolVar(&all, "all", false, "Search all tasks including DONE and CANCELLED")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of results to show (0 for all)")
+	cmd.Flags().StringVar(&in, "in", "title,description", "Comma-separated fields to search: title, description (desc), tags, source, author")
 
 	return cmd
 }
+
+// parseSearchFields parses a comma-separated --in value
+func parseSearchFields(spec string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		switch field {
+		case "":
+			continue
+		case "desc":
+			field = models.SearchDescription
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, errors.NewValidationError("no search fields given")
+	}
+	return fields, nil
+}
+
+// formatSearchResults outputs search results. Tasks whose title doesn't
+// match get a highlighted snippet of the first other field that does.
+func formatSearchResults(app *App, w io.Writer, tasks []*models.Task, query string, fields []string, oneline bool) {
+	for i, task := range tasks {
+		if oneline {
+			_, _ = fmt.Fprintln(w, app.formatTaskOneline(task))
+		} else {
+			_, _ = fmt.Fprint(w, app.formatTaskGitStyle(task, nil))
+		}
+
+		if !strings.Contains(strings.ToLower(task.Title), strings.ToLower(strings.TrimSpace(query))) {
+			for _, field := range fields {
+				before, match, after, ok := output.MatchSnippet(models.SearchFieldValue(task, field), query, snippetRadius)
+				if !ok {
+					continue
+				}
+				label := "Match"
+				if field != models.SearchDescription {
+					label += " (" + field + ")"
+				}
+				_, _ = fmt.Fprintf(w, "    %s: %s%s%s\n", label, before, highlightMatch(match), after)
+				break
+			}
+		}
+
+		if !oneline && i < len(tasks)-1 {
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+}
+
+// highlightMatch marks a search match, in bold reverse video when colors
+// are enabled and between asterisks otherwise
+func highlightMatch(match string) string {
+	if !useColor {
+		return "*" + match + "*"
+	}
+	return colorize(match, colorBold+colorInverse)
+}
@@ -14,7 +14,7 @@ func TestSearchCommand(t *testing.T) {
 
 	// Create test tasks with searchable content
 	tasks := []struct {
-		kind        string
+		kind        models.Kind
 		title       string
 		description string
 		tags        string
@@ -129,7 +129,7 @@ func TestSearchCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newSearchCommand()
+			cmd := newSearchCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -180,3 +180,85 @@ func TestSearchCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchLimitAndSnippets(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	for i, state := range []models.State{models.StateNew, models.StateNew, models.StateDone} {
+		task := models.NewTask(models.KindBug, "Timeout "+string(rune('A'+i)), "Requests to the upstream timeout service fail after a deploy")
+		task.State = state
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hidden := models.NewTask(models.KindBug, "Deploys are flaky", "The upstream timeout service rejects requests during a deploy")
+	if err := testRepo.Create(hidden); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newSearchCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return stdout.String()
+	}
+
+	out := run("--oneline", "timeout")
+	if strings.Contains(out, "Timeout C") {
+		t.Errorf("Expected DONE tasks to be left out without --all:\n%s", out)
+	}
+	if strings.Index(out, "Timeout A") > strings.Index(out, "Deploys are flaky") {
+		t.Errorf("Expected title matches before description matches:\n%s", out)
+	}
+	if !strings.Contains(out, "Match: The upstream *timeout* service rejects") {
+		t.Errorf("Expected a highlighted snippet for the description match:\n%s", out)
+	}
+	if strings.Count(out, "Match:") != 1 {
+		t.Errorf("Expected snippets only for tasks whose title doesn't match:\n%s", out)
+	}
+
+	out = run("--oneline", "--all", "--limit", "2", "timeout")
+	if strings.Count(out, "Timeout") != 2 || !strings.Contains(out, "Showing 2 of 4 matching tasks") {
+		t.Errorf("Expected 2 of 4 results with --all --limit 2:\n%s", out)
+	}
+}
+
+func TestSearchInFields(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Slow queries", "Queries take seconds")
+	task.Tags = "backend,database"
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newSearchCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	if out, err := run("backend"); err != nil || !strings.Contains(out, "No tasks found") {
+		t.Errorf("Expected tags not to be searched by default, got %q (%v)", out, err)
+	}
+	out, err := run("--oneline", "--in", "title,desc,tags", "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Slow queries") || !strings.Contains(out, "Match (tags): *backend*,database") {
+		t.Errorf("Expected tag match with snippet:\n%s", out)
+	}
+	if _, err := run("--in", "kind", "backend"); err == nil {
+		t.Error("Expected error for an unknown field")
+	}
+}
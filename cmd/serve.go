@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/server"
+)
+
+// newServeCommand creates the serve command
+func newServeCommand(app *App) *cobra.Command {
+	var addr string
+	var ui bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve tasks over HTTP",
+		Long: `Serve tasks over a JSON HTTP API, by default on localhost only.
+
+  GET  /api/tasks                 List tasks (?all=1, ?state=, ?tag=)
+  GET  /api/tasks/ID              Show a task with its subtasks and history
+  POST /api/tasks/ID/ACTION       accept, reject, start, done, cancel, or reopen
+
+State changes must be sent as application/json and may include a reason,
+e.g. {"reason": "duplicate of #12"}, which reject and cancel require when
+GTD_REQUIRE_REASON is set.
+
+With --ui, a small web UI is served at / with a board, inbox triage, and task
+details. The API has no authentication, so only bind to other addresses on
+networks you trust. On loopback addresses, requests for any other host name
+than the address or localhost are refused. Press Ctrl-C to stop.`,
+		Example: `  gtd serve --ui
+  gtd serve --addr 127.0.0.1:9000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Serving on http://%s (press Ctrl-C to stop)\n", listener.Addr())
+			srv := server.New(repo, server.Config{
+				Rules:         app.ValidationRules(),
+				RequireReason: app.Config().RequireReason,
+				UI:            ui,
+				Addr:          listener.Addr(),
+			})
+			srv.Subscribe(notifyListener(app, cmd.ErrOrStderr()))
+			return serve(ctx, listener, srv.Handler())
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().BoolVar(&ui, "ui", false, "Serve the web UI at /")
+
+	return cmd
+}
+
+// serve serves handler on listener until ctx is cancelled, then shuts down
+// gracefully
+func serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/services"
+)
+
+// newTaskService creates a task service on the current repository that
+// checks tasks against the configured validation rules, with the listeners
+// that react to changes subscribed. Commands make changes through it so that
+// every change reaches them.
+func newTaskService(app *App, cmd *cobra.Command) services.TaskService {
+	return newTaskServiceWithRules(app, cmd, app.ValidationRules())
+}
+
+// newTaskServiceWithRules creates a task service like newTaskService that
+// checks tasks against the given rules instead of the configured ones
+func newTaskServiceWithRules(app *App, cmd *cobra.Command, rules models.ValidationRules) services.TaskService {
+	service := services.NewTaskService(repo, rules)
+	service.Subscribe(notifyListener(app, cmd.ErrOrStderr()))
+	return service
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// newSetCommand creates the set command
+func newSetCommand(app *App) *cobra.Command {
+	var ref string
+
+	cmd := &cobra.Command{
+		Use:   "set TASK_ID [FIELD=VALUE...]",
+		Short: "Set custom fields of a task",
+		Long: `Set custom fields of a task: domain-specific metadata such as the customer,
+severity, or environment, kept apart from the task's title and description.
+Field names start with a lowercase letter and contain only lowercase letters,
+digits, dashes, and underscores. An empty value removes the field.
+
+Fields are shown by 'gtd show', included in JSON exports, and can be filtered
+on with 'gtd list --field' and 'gtd export --field'.
+
+--ref sets the task's external reference, the issue URL or ticket key given
+to 'gtd add --ref'; an empty reference removes it.`,
+		Example: `  gtd set abc123 customer=acme
+  gtd set abc123 severity=critical environment=production
+  gtd set abc123 customer=
+  gtd set abc123 --ref AUTH-42
+  gtd set abc123 --ref ""
+  gtd list --field customer=acme`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("ref") {
+				return cobra.MinimumNArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := repo.GetByID(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Check every assignment before changing anything
+			type assignment struct{ name, value string }
+			var assignments []assignment
+			for _, arg := range args[1:] {
+				name, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return errors.NewValidationError("invalid field: %q (expected FIELD=VALUE)", arg)
+				}
+				if err := models.ValidateFieldName(name); err != nil {
+					return err
+				}
+				assignments = append(assignments, assignment{name, value})
+			}
+
+			hash := colorize(task.ShortHash(), colorYellow)
+			if cmd.Flags().Changed("ref") {
+				task.ExternalRef = strings.TrimSpace(ref)
+				if err := newTaskService(app, cmd).UpdateTask(task); err != nil {
+					return fmt.Errorf("failed to set reference: %w", err)
+				}
+				if task.ExternalRef == "" {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s: removed ref\n", hash)
+				} else {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s: ref = %s\n", hash, task.ExternalRef)
+				}
+			}
+			for _, a := range assignments {
+				if err := repo.SetField(task.ID, a.name, a.value); err != nil {
+					return err
+				}
+				if a.value == "" {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s: removed %s\n", hash, a.name)
+				} else {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s: %s = %s\n", hash, a.name, a.value)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&ref, "ref", "", "External reference (issue URL or ticket key), empty to remove it")
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestSetCommand(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	acme := models.NewTask(models.KindBug, "Acme bug", "Bug description")
+	acme.State = models.StateNew
+	other := models.NewTask(models.KindBug, "Other bug", "Bug description")
+	other.State = models.StateNew
+	for _, task := range []*models.Task{acme, other} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run(t, newSetCommand(NewApp()), acme.ID, "customer=acme", "severity=critical")
+	if err != nil {
+		t.Fatalf("set error = %v", err)
+	}
+	if !strings.Contains(out, "customer = acme") || !strings.Contains(out, "severity = critical") {
+		t.Errorf("Unexpected set output: %s", out)
+	}
+
+	out, err = run(t, newListCommand(NewApp()), "--oneline", "--field", "customer=acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Acme bug") || strings.Contains(out, "Other bug") {
+		t.Errorf("list --field output:\n%s", out)
+	}
+
+	out, err = run(t, newExportCommand(NewApp()), "--format", "json", "--field", "severity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"customer": "acme"`) || strings.Contains(out, "Other bug") {
+		t.Errorf("export --field output:\n%s", out)
+	}
+
+	out, err = run(t, newShowCommand(NewApp()), acme.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Fields:\n  customer: acme\n  severity: critical") {
+		t.Errorf("show output lacks the fields:\n%s", out)
+	}
+
+	if out, err = run(t, newSetCommand(NewApp()), acme.ID, "severity="); err != nil || !strings.Contains(out, "removed severity") {
+		t.Errorf("set severity= = %q, %v", out, err)
+	}
+
+	// Nothing is set when any assignment is invalid
+	if _, err := run(t, newSetCommand(NewApp()), other.ID, "customer=globex", "severity"); err == nil {
+		t.Error("set accepted an assignment without a value")
+	}
+	if fields, _ := testRepo.GetFields(other.ID); len(fields) != 0 {
+		t.Errorf("fields after a failed set = %v", fields)
+	}
+}
+
+func TestSetRef(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Login bug", "Bug description")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newSetCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	if _, err := run(task.ID); err == nil {
+		t.Error("set accepted a task without fields or --ref")
+	}
+
+	out, err := run(task.ID, "--ref", "AUTH-42")
+	if err != nil || !strings.Contains(out, "ref = AUTH-42") {
+		t.Fatalf("set --ref = %q, %v", out, err)
+	}
+	if got, _ := testRepo.GetByID(task.ID); got.ExternalRef != "AUTH-42" {
+		t.Errorf("ExternalRef = %q, want %q", got.ExternalRef, "AUTH-42")
+	}
+
+	out, err = run(task.ID, "--ref", "")
+	if err != nil || !strings.Contains(out, "removed ref") {
+		t.Fatalf("set --ref \"\" = %q, %v", out, err)
+	}
+	if got, _ := testRepo.GetByID(task.ID); got.ExternalRef != "" {
+		t.Errorf("ExternalRef = %q, want it removed", got.ExternalRef)
+	}
+}
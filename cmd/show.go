@@ -4,52 +4,317 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
 // newShowCommand creates the show command
-func newShowCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "show TASK_ID",
+func newShowCommand(app *App) *cobra.Command {
+	var raw bool
+	var section string
+	var outputFormat string
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:   "show TASK_ID...",
 		Short: "Show task details",
-		Long:  `Show detailed information about a task, including description, metadata, and subtasks.`,
+		Long: `Show detailed information about a task, including description, metadata, and subtasks.
+The task can also be referenced by a unique, case-insensitive part of its title.
+Markdown in the description is rendered for the terminal unless --raw is given.
+Recognized sections of the description, such as "Acceptance Criteria:" or
+"Repro Steps:", are shown with headers, and --section shows just one of them
+(acceptance, repro, expected, or actual).
+Subtasks show their chain of parents, from the top-level task down.
+Blocked tasks show their full chain of blockers, nearest first.
+Several tasks can be shown at once, and --recursive also shows all their
+subtasks, depth first and indented by level.`,
 		Example: `  claude-gtd show abc123
-  claude-gtd show 1a2b3c4`,
-		Args: cobra.ExactArgs(1),
+  claude-gtd show 1a2b3c4
+  claude-gtd show "memory leak"
+  claude-gtd show abc123 --raw
+  claude-gtd show abc123 --section acceptance
+  claude-gtd show abc123 --output json
+  claude-gtd show abc123 def456
+  claude-gtd show abc123 --recursive`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Get task ID (hash or hash prefix)
-			taskID := args[0]
-
-			// Get the task
-			task, err := repo.GetByID(taskID)
+			format, err := output.ResolveFormat(outputFormat, app.Config().DefaultFormat, listFormats...)
 			if err != nil {
-				return fmt.Errorf("task not found: %w", err)
+				return err
 			}
-
-			// Get parent if this is a subtask
-			var parent *models.Task
-			if task.Parent != nil {
-				parent, _ = repo.GetByID(*task.Parent)
+			if section != "" && (len(args) > 1 || recursive) {
+				return errors.NewValidationError("--section shows a single task; it cannot be combined with several task IDs or --recursive")
 			}
 
-			// Get subtasks
-			subtasks, err := repo.GetChildren(task.ID)
-			if err != nil {
-				return fmt.Errorf("failed to get subtasks: %w", err)
+			// Get the tasks (hash, hash prefix, or title)
+			tasks := make([]*models.Task, len(args))
+			for i, taskID := range args {
+				if tasks[i], err = repo.GetByID(taskID); err != nil {
+					return err
+				}
 			}
 
-			// Format and output
-			formatTaskDetails(cmd.OutOrStdout(), task, parent, subtasks)
+			if section != "" {
+				return showSection(cmd.OutOrStdout(), tasks[0], section, raw)
+			}
+			if recursive {
+				return showSubtrees(app, cmd.OutOrStdout(), tasks, format)
+			}
+			switch format {
+			case output.FormatStandard:
+			case output.FormatOneline:
+				for _, task := range tasks {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), app.formatTaskOneline(task))
+				}
+				return nil
+			default:
+				return output.Formats.WriteTasks(cmd.OutOrStdout(), format, tasks, output.Options{JSONTask: loadShowTask})
+			}
 
+			for i, task := range tasks {
+				if i > 0 {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout())
+				}
+				if err := showTaskDetails(app, cmd.OutOrStdout(), task, raw); err != nil {
+					return err
+				}
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&raw, "raw", false, "Show the description as written, without rendering Markdown")
+	cmd.Flags().StringVar(&section, "section", "", "Show only this section of the description (acceptance, repro, expected, actual)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: standard, oneline, json, csv, markdown [default: GTD_DEFAULT_FORMAT or standard]")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Also show all subtasks of each task, depth first")
+
+	return cmd
+}
+
+// showTaskDetails prints a task with its parent chain, blockers, custom
+// fields, links, attachments, subtasks, checklist, and logged time
+func showTaskDetails(app *App, w io.Writer, task *models.Task, raw bool) error {
+	// Get the parent chain if this is a subtask
+	ancestors, err := repo.GetAncestors(task.ID)
+	if err != nil {
+		return err
+	}
+
+	// Get subtasks
+	subtasks, err := repo.GetChildren(task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	// Render Markdown in the description on a copy of the task. Its
+	// checklist is counted on the description as written, as the
+	// rendered one no longer has checklist lines.
+	var checklist string
+	if !raw {
+		checklist = output.ChecklistSummary(task)
+		rendered := *task
+		rendered.Description = output.RenderMarkdown(output.SectionHeadings(task.Description), useColor)
+		task = &rendered
+	}
+
+	attachments, err := repo.GetAttachments(task.ID)
+	if err != nil {
+		return err
+	}
+
+	fields, err := repo.GetFields(task.ID)
+	if err != nil {
+		return err
+	}
+
+	links, err := loadLinkedTasks(task.ID)
+	if err != nil {
+		return err
+	}
+
+	blockers, cyclic, err := repo.GetBlockerChain(task.ID)
+	if err != nil {
+		return err
+	}
+	var blockerChain string
+	if len(blockers) > 0 {
+		blockerChain = formatBlockerChain(blockers, cyclic)
+	}
+
+	worklog, err := repo.GetWorklog(task.ID)
+	if err != nil {
+		return err
+	}
+
+	// Format and output
+	formatTaskDetails(app, w, task, ancestors, blockerChain, fields, subtasks, attachments, links)
+	if checklist != "" {
+		_, _ = fmt.Fprintf(w, "\n%s\n", checklist)
+	}
+	if len(worklog) > 0 {
+		var logged time.Duration
+		for _, entry := range worklog {
+			logged += entry.Duration()
+		}
+		_, _ = fmt.Fprintf(w, "\nTime logged: %s (%s)\n",
+			formatWorkDuration(logged), formatTaskCount(len(worklog), "interval"))
+	}
+	return nil
+}
+
+// subtreeTask is a task of a subtree with its depth below the subtree's root
+type subtreeTask struct {
+	task  *models.Task
+	depth int
+}
+
+// loadSubtree returns a task and all its descendants, depth first. A task
+// reached twice, through a corrupted parent chain, is only included once.
+func loadSubtree(root *models.Task, seen map[string]bool) ([]subtreeTask, error) {
+	var tree []subtreeTask
+	var visit func(task *models.Task, depth int) error
+	visit = func(task *models.Task, depth int) error {
+		if seen[task.ID] {
+			return nil
+		}
+		seen[task.ID] = true
+		tree = append(tree, subtreeTask{task: task, depth: depth})
+
+		children, err := repo.GetChildren(task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get subtasks: %w", err)
+		}
+		for _, child := range children {
+			if err := visit(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return tree, visit(root, 0)
+}
+
+// showSubtrees prints tasks and their descendants depth first, indenting
+// subtasks by their depth. The json, csv, and markdown formats list the tasks
+// in the same order, without indentation.
+func showSubtrees(app *App, w io.Writer, roots []*models.Task, format string) error {
+	var tree []subtreeTask
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		subtree, err := loadSubtree(root, seen)
+		if err != nil {
+			return err
+		}
+		tree = append(tree, subtree...)
+	}
+
+	switch format {
+	case output.FormatStandard:
+		for i, item := range tree {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+			indent := strings.Repeat("    ", item.depth)
+			for _, line := range strings.Split(strings.TrimSuffix(app.formatTaskGitStyle(item.task, nil), "\n"), "\n") {
+				_, _ = fmt.Fprintln(w, strings.TrimRight(indent+line, " "))
+			}
+		}
+		return nil
+	case output.FormatOneline:
+		for _, item := range tree {
+			_, _ = fmt.Fprintln(w, strings.Repeat("  ", item.depth)+app.formatTaskOneline(item.task))
+		}
+		return nil
+	default:
+		tasks := make([]*models.Task, len(tree))
+		for i, item := range tree {
+			tasks[i] = item.task
+		}
+		return output.Formats.WriteTasks(w, format, tasks, output.Options{JSONTask: loadShowTask})
+	}
+}
+
+// showSection prints one recognized section of a task's description
+func showSection(w io.Writer, task *models.Task, name string, raw bool) error {
+	sectionType, ok := models.LookupSectionType(name)
+	if !ok {
+		keys := make([]string, len(models.SectionTypes))
+		for i, sectionType := range models.SectionTypes {
+			keys[i] = sectionType.Key
+		}
+		return errors.NewValidationError("unknown section: %s (must be one of %s)", name, strings.Join(keys, ", "))
+	}
+
+	section, ok := models.FindSection(task.Description, sectionType.Key)
+	if !ok {
+		return errors.NewNotFoundError("task %s has no %s section", task.ShortHash(), sectionType.Titles[0])
+	}
+	body := section.Body
+	if !raw {
+		body = output.RenderMarkdown(body, useColor)
+	}
+	_, _ = fmt.Fprintln(w, body)
+	return nil
+}
+
+// loadShowTask converts a task for show's JSON output, with its attachments,
+// links, custom fields, and parent chain
+func loadShowTask(task *models.Task) (*exportTask, error) {
+	item, err := loadExportTask(task)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := repo.GetAncestors(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestor := range ancestors {
+		item.ParentChain = append(item.ParentChain, output.JSONTaskRef{ID: ancestor.ID, Title: ancestor.Title})
+	}
+	return item, nil
+}
+
+// formatParentChain formats the ancestors of a subtask, root first, e.g.
+// "a1b2c3d Implement dashboard › b2c3d4e Add charts"
+func formatParentChain(ancestors []*models.Task) string {
+	parts := make([]string, len(ancestors))
+	for i, ancestor := range ancestors {
+		parts[i] = colorize(ancestor.ShortHash(), colorYellow) + " " + ancestor.Title
+	}
+	return strings.Join(parts, " › ")
+}
+
+// linkedTask is a task linked to the one being shown, with the link described
+// from the shown task's side
+type linkedTask struct {
+	Label string
+	Task  *models.Task
+}
+
+// loadLinkedTasks retrieves the tasks linked to a task
+func loadLinkedTasks(taskID string) ([]linkedTask, error) {
+	links, err := repo.GetLinks(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []linkedTask
+	for _, link := range links {
+		other, err := repo.GetByID(link.OtherID(taskID))
+		if err != nil {
+			return nil, err
+		}
+		linked = append(linked, linkedTask{Label: link.Label(taskID), Task: other})
+	}
+	return linked, nil
 }
 
 // formatTaskDetails formats detailed task information
-func formatTaskDetails(w io.Writer, task *models.Task, parent *models.Task, subtasks []*models.Task) {
+func formatTaskDetails(app *App, w io.Writer, task *models.Task, ancestors []*models.Task, blockerChain string, fields map[string]string, subtasks []*models.Task, attachments []*models.Attachment, links []linkedTask) {
 	// Calculate subtask stats
 	var stats *SubtaskStats
 	if len(subtasks) > 0 {
@@ -62,15 +327,58 @@ func formatTaskDetails(w io.Writer, task *models.Task, parent *models.Task, subt
 	}
 
 	// Use git-style format
-	if _, err := fmt.Fprint(w, formatTaskGitStyle(task, stats)); err != nil {
+	if _, err := fmt.Fprint(w, app.formatTaskGitStyle(task, stats)); err != nil {
 		return
 	}
 
-	// Parent info if this is a subtask
-	if parent != nil {
-		if _, err := fmt.Fprintf(w, "\nParent: %s - %s\n", parent.ShortHash(), parent.Title); err != nil {
+	// Parent chain if this is a subtask
+	if len(ancestors) > 0 {
+		if _, err := fmt.Fprintf(w, "\nParent: %s\n", formatParentChain(ancestors)); err != nil {
+			return
+		}
+	}
+
+	// Transitive blockers, nearest first
+	if blockerChain != "" {
+		if _, err := fmt.Fprintf(w, "\nBlocked by: %s\n", blockerChain); err != nil {
+			return
+		}
+	}
+
+	// Custom fields, by name
+	if len(fields) > 0 {
+		if _, err := fmt.Fprintln(w, "\nFields:"); err != nil {
+			return
+		}
+		for _, name := range models.FieldNames(fields) {
+			if _, err := fmt.Fprintf(w, "  %s: %s\n", name, fields[name]); err != nil {
+				return
+			}
+		}
+	}
+
+	// Links to other tasks
+	if len(links) > 0 {
+		if _, err := fmt.Fprintln(w, "\nLinks:"); err != nil {
 			return
 		}
+		for _, link := range links {
+			if _, err := fmt.Fprintf(w, "  %-13s %s %s\n", link.Label, colorize(link.Task.ShortHash(), colorYellow), link.Task.Title); err != nil {
+				return
+			}
+		}
+	}
+
+	// Attached files and URLs
+	if len(attachments) > 0 {
+		if _, err := fmt.Fprintln(w, "\nAttachments:"); err != nil {
+			return
+		}
+		for _, attachment := range attachments {
+			if _, err := fmt.Fprintf(w, "  %s\n", attachment.Location); err != nil {
+				return
+			}
+		}
 	}
 
 	// Subtasks
@@ -84,7 +392,7 @@ func formatTaskDetails(w io.Writer, task *models.Task, parent *models.Task, subt
 
 		for _, subtask := range subtasks {
 			// Use the subtask format with metadata on the right
-			subtaskLine := formatSubtask(subtask)
+			subtaskLine := app.formatSubtask(subtask)
 			if _, err := fmt.Fprintf(w, "  %s\n", subtaskLine); err != nil {
 				return
 			}
@@ -107,7 +415,7 @@ func formatTaskDetails(w io.Writer, task *models.Task, parent *models.Task, subt
 
 // formatSubtaskSummary creates a summary of subtask states
 func formatSubtaskSummary(subtasks []*models.Task) string {
-	counts := make(map[string]int)
+	counts := make(map[models.State]int)
 	for _, task := range subtasks {
 		counts[task.State]++
 	}
@@ -37,6 +37,13 @@ func TestShowCommand(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// And a subtask of a subtask
+	nested := models.NewTask(models.KindFeature, "Nested subtask", "Part of the second subtask")
+	nested.Parent = &subtask2.ID
+	if err := testRepo.Create(nested); err != nil {
+		t.Fatal(err)
+	}
+
 	// Create a task that blocks the parent
 	blocker := models.NewTask(models.KindBug, "Blocking task", "This task blocks the parent task")
 	if err := testRepo.Create(blocker); err != nil {
@@ -46,6 +53,21 @@ func TestShowCommand(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Which is itself blocked, making a chain
+	rootBlocker := models.NewTask(models.KindBug, "Root blocker", "This task blocks the blocking task")
+	if err := testRepo.Create(rootBlocker); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.Block(blocker.ID, rootBlocker.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a task with a Markdown description
+	formatted := models.NewTask(models.KindFeature, "Markdown task", "Steps:\n- run **make**\n- see [log](https://example.com/log)")
+	if err := testRepo.Create(formatted); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name        string
 		args        []string
@@ -66,6 +88,7 @@ func TestShowCommand(t *testing.T) {
 				"First subtask",
 				"✓", // DONE symbol
 				"Second subtask",
+				"Blocked by: " + blocker.ShortHash() + " ← " + rootBlocker.ShortHash(),
 			},
 		},
 		{
@@ -85,10 +108,96 @@ func TestShowCommand(t *testing.T) {
 			contains: []string{
 				"First subtask",
 				"Fix the bug",
-				"Parent: ",
-				"Parent feature",
+				"Parent: " + parent.ShortHash() + " Parent feature\n",
+			},
+		},
+		{
+			name: "show nested subtask with parent chain",
+			args: []string{nested.ID},
+			contains: []string{
+				"Parent: " + parent.ShortHash() + " Parent feature › " + subtask2.ShortHash() + " Second subtask\n",
+			},
+		},
+		{
+			name: "json includes parent chain",
+			args: []string{nested.ID, "--output", "json"},
+			contains: []string{
+				`"parent_chain": [`,
+				`"id": "` + parent.ID + `",
+        "title": "Parent feature"`,
+				`"id": "` + subtask2.ID + `",
+        "title": "Second subtask"`,
 			},
 		},
+		{
+			name:        "json omits parent chain of top-level task",
+			args:        []string{parent.ID, "--output", "json"},
+			notContains: []string{"parent_chain"},
+		},
+		{
+			name: "render markdown description",
+			args: []string{formatted.ID},
+			contains: []string{
+				"• run make",
+				"log (https://example.com/log)",
+			},
+			notContains: []string{
+				"**make**",
+			},
+		},
+		{
+			name: "raw markdown description",
+			args: []string{formatted.ID, "--raw"},
+			contains: []string{
+				"- run **make**",
+				"[log](https://example.com/log)",
+			},
+		},
+		{
+			name: "show several tasks",
+			args: []string{blocker.ID, formatted.ID},
+			contains: []string{
+				"Blocking task",
+				"Markdown task",
+				"• run make",
+			},
+		},
+		{
+			name: "show several tasks in one line each",
+			args: []string{blocker.ID, formatted.ID, "-o", "oneline"},
+			contains: []string{
+				blocker.ShortHash(),
+				formatted.ShortHash(),
+			},
+		},
+		{
+			name: "show subtree",
+			args: []string{parent.ID, "--recursive", "--output", "oneline"},
+			contains: []string{
+				parent.ShortHash(),
+				"\n  " + subtask1.ShortHash(),
+				"\n  " + subtask2.ShortHash(),
+				"\n    " + nested.ShortHash(),
+			},
+			notContains: []string{
+				blocker.ShortHash(),
+			},
+		},
+		{
+			name: "show subtree in git style",
+			args: []string{subtask2.ID, "-r"},
+			contains: []string{
+				"task " + subtask2.ID,
+				"\n    task " + nested.ID,
+				"Nested subtask",
+			},
+		},
+		{
+			name:    "section of several tasks",
+			args:    []string{parent.ID, blocker.ID, "--section", "acceptance"},
+			wantErr: true,
+			errMsg:  "--section shows a single task",
+		},
 		{
 			name:    "missing task ID",
 			args:    []string{},
@@ -112,7 +221,7 @@ func TestShowCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newShowCommand()
+			cmd := newShowCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -142,3 +251,53 @@ func TestShowCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestShowSection(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Crash on save", "Saving crashes the editor.\n\nRepro Steps:\n1. Open a file\n2. Press Ctrl+S\n\nAcceptance Criteria:\nNo crash when saving")
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newShowCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(append([]string{task.ID}, args...))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run("--section", "acceptance")
+	if err != nil {
+		t.Fatalf("show --section error = %v", err)
+	}
+	if out != "No crash when saving\n" {
+		t.Errorf("Expected only the acceptance criteria, got %q", out)
+	}
+
+	out, err = run("--section", "Repro Steps", "--raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "1. Open a file\n2. Press Ctrl+S\n" {
+		t.Errorf("Expected only the repro steps, got %q", out)
+	}
+
+	out, err = run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Acceptance Criteria:") || !strings.Contains(out, "Acceptance Criteria") {
+		t.Errorf("Expected the section header to be rendered as a heading:\n%s", out)
+	}
+
+	if _, err := run("--section", "expected"); err == nil {
+		t.Error("Expected error for a missing section")
+	}
+	if _, err := run("--section", "notes"); err == nil {
+		t.Error("Expected error for an unknown section")
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/git"
+)
+
+// resolveSource determines the source reference of a new task. An explicit
+// --source wins; otherwise a --file path[:line] is made repository-relative,
+// and with GTD_AUTO_SOURCE enabled the current git revision is used.
+func resolveSource(app *App, source, file string) (string, error) {
+	if source != "" {
+		return source, nil
+	}
+	if file != "" {
+		return fileSource(file)
+	}
+	if app.Config().AutoSource {
+		return revisionSource(), nil
+	}
+	return "", nil
+}
+
+// fileSource turns a path[:line] argument into a repository-relative file:line
+// source. The file must exist.
+func fileSource(file string) (string, error) {
+	path, line := file, ""
+	if i := strings.LastIndex(file, ":"); i > 0 {
+		if n, err := strconv.Atoi(file[i+1:]); err == nil {
+			if n < 1 {
+				return "", errors.NewValidationError("invalid line number in %s", file)
+			}
+			path, line = file[:i], file[i+1:]
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("invalid --file %s: %w", file, err)
+	}
+	rel, err := repoRelativePath(path)
+	if err != nil {
+		return "", err
+	}
+	if line != "" {
+		rel += ":" + line
+	}
+	return rel, nil
+}
+
+// revisionSource describes the current git revision as branch@commit, or just
+// the commit for a detached HEAD. It returns "" outside a repository.
+func revisionSource() string {
+	root, err := git.FindGitRoot(".")
+	if err != nil {
+		return ""
+	}
+	branch, commit, err := git.CurrentRevision(root)
+	if err != nil {
+		return ""
+	}
+	if branch == "" {
+		return commit
+	}
+	return branch + "@" + commit
+}
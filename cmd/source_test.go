@@ -0,0 +1,68 @@
+package cmd
+
+import "testing"
+
+func TestResolveSource(t *testing.T) {
+	app := NewApp()
+
+	tests := []struct {
+		name    string
+		source  string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "explicit source wins",
+			source: "issue#42",
+			file:   "source.go:10",
+			want:   "issue#42",
+		},
+		{
+			name: "file with line is repository-relative",
+			file: "source.go:10",
+			want: "cmd/source.go:10",
+		},
+		{
+			name: "file without line",
+			file: "source.go",
+			want: "cmd/source.go",
+		},
+		{
+			name:    "missing file",
+			file:    "does-not-exist.go:3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid line number",
+			file:    "source.go:0",
+			wantErr: true,
+		},
+		{
+			name: "nothing given without auto source",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSource(app, tt.source, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// With auto source the current revision is used
+	app.Config().AutoSource = true
+	got, err := resolveSource(app, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != revisionSource() {
+		t.Errorf("resolveSource() = %q, want %q", got, revisionSource())
+	}
+}
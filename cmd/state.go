@@ -2,14 +2,28 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
+// stateChangeFlags holds the options shared by the state change commands
+type stateChangeFlags struct {
+	cascade bool
+	reason  string
+	yes     bool
+	at      string // backdate the change, done only
+	dryRun  bool
+}
+
 // newInProgressCommand creates the in-progress command
-func newInProgressCommand() *cobra.Command {
-	return &cobra.Command{
+func newInProgressCommand(app *App) *cobra.Command {
+	var flags stateChangeFlags
+
+	cmd := &cobra.Command{
 		Use:   "in-progress TASK_ID",
 		Short: "Mark a task as in progress",
 		Long:  `Mark a task as in progress. This changes the task state to IN_PROGRESS.`,
@@ -17,65 +31,250 @@ func newInProgressCommand() *cobra.Command {
   claude-gtd in-progress 1a2b3c4`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return updateTaskState(cmd, args[0], models.StateInProgress)
+			return updateTaskState(app, cmd, args[0], models.StateInProgress, flags)
 		},
 	}
+
+	addDryRunFlag(cmd, &flags.dryRun)
+
+	return cmd
 }
 
 // newDoneCommand creates the done command
-func newDoneCommand() *cobra.Command {
-	return &cobra.Command{
+func newDoneCommand(app *App) *cobra.Command {
+	var flags stateChangeFlags
+
+	cmd := &cobra.Command{
 		Use:   "done TASK_ID",
 		Short: "Mark a task as done",
 		Long: `Mark a task as done. This changes the task state to DONE.
-Parent tasks can only be marked as done when all their subtasks are either DONE or CANCELLED.`,
+Parent tasks can only be marked as done when all their subtasks are either DONE or CANCELLED.
+With --cascade, open subtasks at any depth are marked as done first, in one transaction.
+Tasks blocked by this task are unblocked.
+
+With GTD_CONFIRM_DONE set, --cascade and completing a parent task ask for
+confirmation in a terminal. Use --yes to skip the prompt.
+
+Use --at to record work that was finished earlier; the completion is
+recorded in the task's history at that time. It cannot be earlier than the
+task was created: to record work done before it was entered in gtd, backdate
+the task with --created-at when adding it.`,
 		Example: `  claude-gtd done abc123
-  claude-gtd done 1a2b3c4`,
+  claude-gtd done 1a2b3c4
+  claude-gtd done abc123 --cascade
+  claude-gtd done abc123 --at "2024-05-01 17:00"
+  claude-gtd done abc123 --at yesterday`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return updateTaskState(cmd, args[0], models.StateDone)
+			return updateTaskState(app, cmd, args[0], models.StateDone, flags)
 		},
 	}
+
+	cmd.Flags().BoolVar(&flags.cascade, "cascade", false, "Also mark all open subtasks as done")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().StringVar(&flags.at, "at", "", "When the task was finished (e.g. yesterday, 2024-05-01 17:00)")
+	addDryRunFlag(cmd, &flags.dryRun)
+	cmd.MarkFlagsMutuallyExclusive("cascade", "at")
+
+	return cmd
 }
 
 // newCancelCommand creates the cancel command
-func newCancelCommand() *cobra.Command {
-	return &cobra.Command{
+func newCancelCommand(app *App) *cobra.Command {
+	var flags stateChangeFlags
+
+	cmd := &cobra.Command{
 		Use:   "cancel TASK_ID",
 		Short: "Cancel a task",
-		Long:  `Cancel a task. This changes the task state to CANCELLED.`,
-		Example: `  claude-gtd cancel abc123
-  claude-gtd cancel 1a2b3c4`,
+		Long: `Cancel a task. This changes the task state to CANCELLED.
+With --cascade, open subtasks at any depth are cancelled too, in one transaction.
+Tasks blocked by this task are unblocked. The --reason is recorded in the
+task's history and shown by list-cancelled; set GTD_REQUIRE_REASON to make it
+mandatory. With GTD_CONFIRM_DONE set, --cascade asks for confirmation in a
+terminal unless --yes is given.`,
+		Example: `  claude-gtd cancel abc123 --reason "superseded by the new importer"
+  claude-gtd cancel 1a2b3c4
+  claude-gtd cancel abc123 --cascade`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return updateTaskState(cmd, args[0], models.StateCancelled)
+			return updateTaskState(app, cmd, args[0], models.StateCancelled, flags)
 		},
 	}
+
+	cmd.Flags().BoolVar(&flags.cascade, "cascade", false, "Also cancel all open subtasks")
+	cmd.Flags().StringVar(&flags.reason, "reason", "", "Why the task is cancelled")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Do not ask for confirmation")
+	addDryRunFlag(cmd, &flags.dryRun)
+
+	return cmd
 }
 
-// updateTaskState is a helper function to update task state
-func updateTaskState(cmd *cobra.Command, taskIDStr string, newState string) error {
+// updateTaskState is a helper function to update task state. With cascade,
+// DONE and CANCELLED are applied to the task's open subtasks as well.
+func updateTaskState(app *App, cmd *cobra.Command, taskIDStr string, newState models.State, flags stateChangeFlags) error {
+	if newState == models.StateCancelled {
+		if err := checkReason(app, flags.reason); err != nil {
+			return err
+		}
+	}
+
+	at := time.Now()
+	if flags.at != "" {
+		var err error
+		if at, err = parseDate(flags.at, at); err != nil {
+			return err
+		}
+	}
+
 	// Get the task first to show info
 	task, err := repo.GetByID(taskIDStr)
 	if err != nil {
-		return fmt.Errorf("task not found: %w", err)
+		return err
 	}
 
-	// Update state
-	if err := repo.UpdateState(task.ID, newState); err != nil {
+	if flags.dryRun {
+		return previewStateChange(app, cmd, task, newState, at, flags)
+	}
+
+	if err := confirmStateChange(app, cmd, task, newState, flags); err != nil {
+		return err
+	}
+
+	// Update state, releasing dependents of finished tasks
+	var changed, unblocked []*models.Task
+	service := newTaskService(app, cmd)
+	switch {
+	case newState == models.StateDone && flags.cascade:
+		changed, unblocked, err = service.CompleteTaskCascade(task.ID)
+	case newState == models.StateCancelled && flags.cascade:
+		changed, unblocked, err = service.CancelTaskCascade(task.ID)
+	case newState == models.StateDone:
+		unblocked, err = service.CompleteTaskAt(task.ID, at)
+	case newState == models.StateCancelled:
+		unblocked, err = service.CancelTask(task.ID)
+	default:
+		err = service.UpdateTaskState(task.ID, newState)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to update task state: %w", err)
 	}
+	if flags.reason != "" {
+		if err := repo.RecordReason(task.ID, flags.reason); err != nil {
+			return err
+		}
+	}
 
 	// Output success message
+	out := cmd.OutOrStdout()
 	stateVerb := getStateVerb(newState)
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %s marked as %s: %s\n",
+	if len(changed) > 0 {
+		_, _ = fmt.Fprintf(out, "%d subtask(s) marked as %s:\n", len(changed), stateVerb)
+		for _, subtask := range changed {
+			_, _ = fmt.Fprintf(out, "  %s\n", app.formatTaskOneline(subtask))
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Task %s marked as %s: %s\n",
 		task.ShortHash(), stateVerb, task.Title)
 
+	if len(unblocked) > 0 {
+		_, _ = fmt.Fprintf(out, "%d task(s) now unblocked:\n", len(unblocked))
+		for _, dependent := range unblocked {
+			_, _ = fmt.Fprintf(out, "  %s\n", app.formatTaskOneline(dependent))
+		}
+	}
+
+	return nil
+}
+
+// previewStateChange checks a state change like updateTaskState and prints
+// what it would change, without changing anything
+func previewStateChange(app *App, cmd *cobra.Command, task *models.Task, newState models.State, at time.Time, flags stateChangeFlags) error {
+	var changed []*models.Task
+	var err error
+	if flags.cascade {
+		changed, err = repo.PlanSubtreeTransition(task.ID, newState)
+	} else {
+		_, err = repo.CheckTransition(task.ID, newState, at)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Dependents of every finished task would be unblocked, unless the
+	// cascade finishes them too
+	var unblocked []*models.Task
+	if newState == models.StateDone || newState == models.StateCancelled {
+		finished := map[string]bool{task.ID: true}
+		for _, subtask := range changed {
+			finished[subtask.ID] = true
+		}
+		for _, t := range append(changed, task) {
+			dependents, err := repo.GetBlockedTasks(t.ID)
+			if err != nil {
+				return err
+			}
+			for _, dependent := range dependents {
+				if !finished[dependent.ID] {
+					unblocked = append(unblocked, dependent)
+				}
+			}
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	stateVerb := getStateVerb(newState)
+	if len(changed) > 0 {
+		_, _ = fmt.Fprintf(out, "Would mark %d subtask(s) as %s:\n", len(changed), stateVerb)
+		for _, subtask := range changed {
+			_, _ = fmt.Fprintf(out, "  %s\n", app.formatTaskOneline(subtask))
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Would mark task %s as %s: %s\n", task.ShortHash(), stateVerb, task.Title)
+	if len(unblocked) > 0 {
+		_, _ = fmt.Fprintf(out, "%d task(s) would be unblocked:\n", len(unblocked))
+		for _, dependent := range unblocked {
+			_, _ = fmt.Fprintf(out, "  %s\n", app.formatTaskOneline(dependent))
+		}
+	}
+	return nil
+}
+
+// addDryRunFlag adds the --dry-run flag of commands that change tasks
+func addDryRunFlag(cmd *cobra.Command, dryRun *bool) {
+	cmd.Flags().BoolVar(dryRun, "dry-run", false, "Check the change and show what it would do without making it")
+}
+
+// confirmStateChange asks before cascading and before marking a parent task as
+// done, when GTD_CONFIRM_DONE is set
+func confirmStateChange(app *App, cmd *cobra.Command, task *models.Task, newState models.State, flags stateChangeFlags) error {
+	verb := getStateVerb(newState)
+	if flags.cascade {
+		return confirmAction(app, cmd, flags.yes,
+			fmt.Sprintf("Mark %s and all its open subtasks as %s?", task.ShortHash(), verb))
+	}
+	if newState == models.StateDone && app.Config().ConfirmDone {
+		children, err := repo.GetChildren(task.ID)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return confirmAction(app, cmd, flags.yes,
+				fmt.Sprintf("Mark parent task %s (%s) as %s?", task.ShortHash(), formatTaskCount(len(children), "subtask"), verb))
+		}
+	}
+	return nil
+}
+
+// checkReason enforces GTD_REQUIRE_REASON for cancel and reject
+func checkReason(app *App, reason string) error {
+	if app.Config().RequireReason && strings.TrimSpace(reason) == "" {
+		return errors.NewValidationError("a reason is required: use --reason (GTD_REQUIRE_REASON is set)")
+	}
 	return nil
 }
 
 // getStateVerb returns a human-friendly verb for the state
-func getStateVerb(state string) string {
+func getStateVerb(state models.State) string {
 	switch state {
 	case models.StateInProgress:
 		return "in progress"
@@ -84,6 +283,6 @@ func getStateVerb(state string) string {
 	case models.StateCancelled:
 		return "cancelled"
 	default:
-		return state
+		return state.String()
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
@@ -84,7 +86,7 @@ func TestInProgressCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newInProgressCommand()
+			cmd := newInProgressCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -202,7 +204,7 @@ func TestDoneCommand(t *testing.T) {
 
 			var stdout, stderr bytes.Buffer
 
-			cmd := newDoneCommand()
+			cmd := newDoneCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -266,7 +268,7 @@ func TestCancelCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newCancelCommand()
+			cmd := newCancelCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -283,3 +285,354 @@ func TestCancelCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestDoneUnblocksDependents(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	blocker := models.NewTask(models.KindBug, "Blocker", "Has to be fixed first")
+	blocker.State = models.StateInProgress
+	if err := testRepo.Create(blocker); err != nil {
+		t.Fatal(err)
+	}
+	dependent := models.NewTask(models.KindFeature, "Dependent", "Waits for the blocker")
+	dependent.State = models.StateNew
+	if err := testRepo.Create(dependent); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepo.Block(dependent.ID, blocker.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newDoneCommand(NewApp())
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{blocker.ID})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stdout.String(), "1 task(s) now unblocked") || !strings.Contains(stdout.String(), "Dependent") {
+		t.Errorf("output should list the unblocked task:\n%s", stdout.String())
+	}
+	task, err := testRepo.GetByID(dependent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.IsBlocked() {
+		t.Errorf("BlockedBy = %v, want nil", *task.BlockedBy)
+	}
+}
+
+func TestCascadeCommands(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	createTree := func() (*models.Task, *models.Task) {
+		t.Helper()
+		parent := models.NewTask(models.KindFeature, "Parent", "Has open subtasks")
+		parent.State = models.StateInProgress
+		if err := testRepo.Create(parent); err != nil {
+			t.Fatal(err)
+		}
+		child := models.NewTask(models.KindBug, "Open child", "Still to do")
+		child.State = models.StateNew
+		child.Parent = &parent.ID
+		if err := testRepo.Create(child); err != nil {
+			t.Fatal(err)
+		}
+		return parent, child
+	}
+
+	tests := []struct {
+		name      string
+		newCmd    func(*App) *cobra.Command
+		cascade   bool
+		wantErr   bool
+		wantState models.State
+	}{
+		{"done without cascade", newDoneCommand, false, true, models.StateNew},
+		{"done with cascade", newDoneCommand, true, false, models.StateDone},
+		{"cancel with cascade", newCancelCommand, true, false, models.StateCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, child := createTree()
+
+			args := []string{parent.ID}
+			if tt.cascade {
+				args = append(args, "--cascade")
+			}
+			var stdout bytes.Buffer
+			cmd := tt.newCmd(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !strings.Contains(stdout.String(), "1 subtask(s) marked as") {
+				t.Errorf("output should list the cascaded subtask:\n%s", stdout.String())
+			}
+
+			got, err := testRepo.GetByID(child.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("child state = %s, want %s", got.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCancelReason(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		require    bool
+		reason     string
+		wantErr    bool
+		wantReason string
+	}{
+		{"optional reason omitted", false, "", false, ""},
+		{"reason given", false, "superseded", false, "superseded"},
+		{"required reason missing", true, "", true, ""},
+		{"required reason given", true, "out of scope", false, "out of scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp()
+			app.Config().RequireReason = tt.require
+
+			task := models.NewTask(models.KindFeature, "Feature "+tt.name, "May be cancelled")
+			task.State = models.StateNew
+			if err := testRepo.Create(task); err != nil {
+				t.Fatal(err)
+			}
+
+			args := []string{task.ID}
+			if tt.reason != "" {
+				args = append(args, "--reason", tt.reason)
+			}
+			var stdout bytes.Buffer
+			cmd := newCancelCommand(app)
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := testRepo.GetByID(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantState := models.StateCancelled
+			if tt.wantErr {
+				wantState = models.StateNew
+			}
+			if got.State != wantState {
+				t.Errorf("State = %s, want %s", got.State, wantState)
+			}
+
+			reason, err := testRepo.GetReason(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDoneAt(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	task := models.NewTask(models.KindBug, "Fixed last week", "Finished before it was tracked")
+	task.State = models.StateInProgress
+	task.Created = time.Date(2024, 4, 1, 9, 0, 0, 0, time.Local)
+	if err := testRepo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		at      string
+		wantErr bool
+	}{
+		{"before creation", "2024-03-01", true},
+		{"in the future", "tomorrow", true},
+		{"backdated", "2024-05-01 17:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newDoneCommand(NewApp())
+			cmd.SetOut(&stdout)
+			cmd.SetArgs([]string{task.ID, "--at", tt.at})
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	history, err := testRepo.GetHistory(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	want := time.Date(2024, 5, 1, 17, 0, 0, 0, time.Local)
+	if last.NewValue != models.StateDone.String() || !last.Created.Equal(want) {
+		t.Errorf("last history entry = %s at %v, want DONE at %v", last.NewValue, last.Created, want)
+	}
+}
+
+func TestAcceptWithAdjustments(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	project := models.NewTask(models.KindFeature, "Project", "Groups the work")
+	if err := testRepo.Create(project); err != nil {
+		t.Fatal(err)
+	}
+	inbox := models.NewTask(models.KindBug, "Inbox task", "Not reviewed yet")
+	inbox.Tags = "captured"
+	if err := testRepo.Create(inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	accept := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newAcceptCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	// Invalid adjustments leave the task in the inbox
+	if _, err := accept(inbox.ID, "--priority", "urgent"); err == nil {
+		t.Error("accept accepted an invalid priority")
+	}
+	if _, err := accept(inbox.ID, "--parent", inbox.ID); err == nil {
+		t.Error("accept made a task its own subtask")
+	}
+
+	out, err := accept(inbox.ID, "--priority", "high", "--tags", "backend, api", "--parent", project.ID)
+	if err != nil {
+		t.Fatalf("accept error = %v", err)
+	}
+	if !strings.Contains(out, "accepted") || !strings.Contains(out, "Set priority high, tags backend,api, parent "+project.ShortHash()) {
+		t.Errorf("Unexpected output: %s", out)
+	}
+
+	task, err := testRepo.GetByID(inbox.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != models.StateNew || task.Priority != models.PriorityHigh || task.Tags != "backend,api" {
+		t.Errorf("task = %s %s %q, want NEW high \"backend,api\"", task.State, task.Priority, task.Tags)
+	}
+	if task.Parent == nil || *task.Parent != project.ID {
+		t.Errorf("Parent = %v, want %s", task.Parent, project.ID)
+	}
+}
+
+func TestStateChangeDryRun(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	parent := models.NewTask(models.KindFeature, "Parent", "Has an open subtask")
+	parent.State = models.StateInProgress
+	if err := testRepo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := models.NewTask(models.KindBug, "Open child", "Still to do")
+	child.State = models.StateNew
+	child.Parent = &parent.ID
+	if err := testRepo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+	dependent := models.NewTask(models.KindBug, "Waiting task", "Blocked by the parent")
+	dependent.State = models.StateNew
+	dependent.BlockedBy = &parent.ID
+	if err := testRepo.Create(dependent); err != nil {
+		t.Fatal(err)
+	}
+	inbox := models.NewTask(models.KindBug, "Inbox task", "Not reviewed yet")
+	if err := testRepo.Create(inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(newCmd func(*App) *cobra.Command, args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newCmd(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(append(args, "--dry-run"))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	// The transition checks still apply
+	if _, err := run(newDoneCommand, parent.ID); err == nil {
+		t.Error("Expected a dry run to fail for a parent with open subtasks")
+	}
+
+	out, err := run(newDoneCommand, parent.ID, "--cascade")
+	if err != nil {
+		t.Fatalf("done --cascade --dry-run error = %v", err)
+	}
+	for _, want := range []string{"Would mark 1 subtask(s) as done", "Open child", "Would mark task", "1 task(s) would be unblocked", "Waiting task"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+
+	out, err = run(newAcceptCommand, inbox.ID)
+	if err != nil {
+		t.Fatalf("accept --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "Would accept task") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+	if _, err := run(newReopenCommand, inbox.ID); err == nil {
+		t.Error("Expected a dry run to fail for reopening a task that isn't cancelled")
+	}
+
+	out, err = run(newBlockCommand, inbox.ID, "--by", child.ID)
+	if err != nil {
+		t.Fatalf("block --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "Would block task") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+
+	// Nothing was changed
+	for id, want := range map[string]models.State{parent.ID: models.StateInProgress, child.ID: models.StateNew, inbox.ID: models.StateInbox} {
+		task, err := testRepo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.State != want {
+			t.Errorf("%s: state = %s, want %s", task.Title, task.State, want)
+		}
+		if id == inbox.ID && task.IsBlocked() {
+			t.Error("Expected the dry run not to block the task")
+		}
+	}
+	if task, _ := testRepo.GetByID(dependent.ID); task == nil || !task.IsBlocked() {
+		t.Error("Expected the dependent to stay blocked")
+	}
+}
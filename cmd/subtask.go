@@ -4,14 +4,19 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
 // newAddSubtaskCommand creates the add-subtask command
-func newAddSubtaskCommand() *cobra.Command {
+func newAddSubtaskCommand(app *App) *cobra.Command {
 	var flags struct {
-		kind     string
-		priority string
+		kind      string
+		priority  string
+		ref       string
+		createdAt string
+		noVerify  bool
+		noInherit bool
 	}
 
 	cmd := &cobra.Command{
@@ -42,20 +47,13 @@ EOF`,
 
 			// Validate kind is provided
 			if flags.kind == "" {
-				return fmt.Errorf("kind is required (use --kind flag)")
+				return errors.NewValidationError("kind is required (use --kind flag)")
 			}
 
 			// Validate and normalize kind value
-			var normalizedKind string
-			switch flags.kind {
-			case "bug", "BUG":
-				normalizedKind = models.KindBug
-			case "feature", "FEATURE":
-				normalizedKind = models.KindFeature
-			case "regression", "REGRESSION":
-				normalizedKind = models.KindRegression
-			default:
-				return fmt.Errorf("invalid kind: %s (must be bug, feature, or regression)", flags.kind)
+			normalizedKind, err := models.ParseKind(flags.kind)
+			if err != nil {
+				return err
 			}
 
 			// Check parent exists
@@ -65,7 +63,7 @@ EOF`,
 			}
 
 			// Read input
-			title, description, err := readTaskInput(cmd.InOrStdin())
+			title, description, err := readTaskInputOrEdit(app, cmd.InOrStdin(), normalizedKind)
 			if err != nil {
 				return err
 			}
@@ -73,19 +71,33 @@ EOF`,
 			// Create subtask
 			task := models.NewTask(normalizedKind, title, description)
 			task.Parent = &parent.ID
+			task.ExternalRef = flags.ref
+			if err := applyCreatedAt(task, flags.createdAt); err != nil {
+				return err
+			}
+			if app.Config().InheritTags && !flags.noInherit {
+				task.Tags = parent.Tags
+			}
 
-			// Apply priority if specified
+			// Apply priority if specified, or the priority of the tags
 			if flags.priority != "" {
-				switch flags.priority {
-				case models.PriorityHigh, models.PriorityMedium, models.PriorityLow:
-					task.Priority = flags.priority
-				default:
-					return fmt.Errorf("invalid priority: %s (must be high, medium, or low)", flags.priority)
+				if task.Priority, err = models.ParsePriority(flags.priority); err != nil {
+					return err
 				}
 			}
+			if priority := app.TagSettings().Priority(task.Tags); priority != "" && !cmd.Flags().Changed("priority") {
+				task.Priority = priority
+			}
 
 			// Save to database
-			if err := repo.Create(task); err != nil {
+			rules := app.ValidationRules()
+			if flags.noVerify {
+				rules = models.ValidationRules{}
+			}
+			if err := newTaskServiceWithRules(app, cmd, rules).CreateTask(task); err != nil {
+				if models.IsRuleViolation(err) {
+					return fmt.Errorf("failed to create subtask: %w\n\nUse --no-verify to skip validation rules", err)
+				}
 				return fmt.Errorf("failed to create subtask: %w", err)
 			}
 
@@ -105,6 +117,13 @@ EOF`,
 
 	cmd.Flags().StringVarP(&flags.priority, "priority", "p", "medium",
 		"Task priority (high, medium, low)")
+	cmd.Flags().StringVar(&flags.ref, "ref", "",
+		"External reference (issue URL or ticket key)")
+	cmd.Flags().BoolVar(&flags.noVerify, "no-verify", false,
+		"Skip title and description validation rules")
+	cmd.Flags().BoolVar(&flags.noInherit, "no-inherit", false,
+		"Don't give the subtask its parent's tags when GTD_INHERIT_TAGS is set")
+	addCreatedAtFlag(cmd, &flags.createdAt)
 
 	return cmd
 }
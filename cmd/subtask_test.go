@@ -121,7 +121,7 @@ func TestAddSubtaskCommand(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 			stdin := strings.NewReader(tt.input)
 
-			cmd := newAddSubtaskCommand()
+			cmd := newAddSubtaskCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetIn(stdin)
@@ -158,3 +158,77 @@ func TestAddSubtaskCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestAddSubtaskInheritTags(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+	app := NewApp()
+	app.Config().InheritTags = true
+
+	project := models.NewTask(models.KindFeature, "Checkout", "The new checkout flow")
+	project.Tags = "checkout,web"
+	project.State = models.StateNew
+	if err := testRepo.Create(project); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(args ...string) *models.Task {
+		t.Helper()
+		cmd := newAddSubtaskCommand(app)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader("Subtask " + strings.Join(args, " ") + "\n\nPart of the checkout"))
+		cmd.SetArgs(append([]string{project.ID, "--kind", "feature"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("add-subtask %v error = %v", args, err)
+		}
+		children, err := testRepo.GetChildren(project.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return children[len(children)-1]
+	}
+
+	if child := add(); child.Tags != "checkout,web" {
+		t.Errorf("Tags = %q, want the parent's tags", child.Tags)
+	}
+	if child := add("--no-inherit"); child.Tags != "" {
+		t.Errorf("Tags with --no-inherit = %q, want none", child.Tags)
+	}
+
+	// Subtasks are listed with their tagged parent with --include-children
+	children, err := testRepo.GetChildren(project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range children {
+		if err := testRepo.UpdateState(child.ID, models.StateNew); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := newListCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(append([]string{"--oneline"}, args...))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+	out, err := list("--tag", "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Subtask --no-inherit") {
+		t.Errorf("list --tag lists the untagged subtask:\n%s", out)
+	}
+	out, err = list("--tag", "checkout", "--include-children")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Subtask --no-inherit") || !strings.Contains(out, "Checkout") {
+		t.Errorf("list --tag --include-children lacks the subtasks:\n%s", out)
+	}
+	if _, err := list("--include-children"); err == nil {
+		t.Error("list accepted --include-children without --tag")
+	}
+}
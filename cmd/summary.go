@@ -3,23 +3,43 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
 )
 
+// noProjectGroup names the group of tasks outside any project in summaries
+const noProjectGroup = "(no project)"
+
 // newSummaryCommand creates the summary command
-func newSummaryCommand() *cobra.Command {
+func newSummaryCommand(app *App) *cobra.Command {
 	var activeOnly bool
+	var by string
 
 	cmd := &cobra.Command{
 		Use:   "summary",
 		Short: "Show task summary statistics",
-		Long:  `Display a summary of all tasks, showing counts by state, type, and priority.`,
+		Long: `Display a summary of all tasks, showing counts by state, type, and priority,
+and the severities of bugs and regressions.
+
+With --by, the counts per state are also broken down per project or per tag.
+A project is a top-level task with subtasks; it and all its descendants are
+counted in its group. With the global --json flag, the summary is printed as
+JSON, including the counts per tag and the age of the oldest INBOX task.`,
 		Example: `  claude-gtd summary
-  claude-gtd summary --active`,
+  claude-gtd summary --active
+  claude-gtd summary --by project
+  claude-gtd summary --json`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if by != "" && by != "project" && by != "tag" {
+				return errors.NewValidationError("invalid grouping: %s (must be project or tag)", by)
+			}
+
 			// Get all tasks
 			opts := models.ListOptions{
 				All:           true,
@@ -34,90 +54,204 @@ func newSummaryCommand() *cobra.Command {
 			}
 
 			// Generate and display summary
-			formatSummary(cmd.OutOrStdout(), tasks, activeOnly)
+			summary := buildSummary(tasks, activeOnly, by, time.Now())
+			if jsonErrors {
+				return writeJSON(cmd.OutOrStdout(), summary)
+			}
+			formatSummary(cmd.OutOrStdout(), summary)
 
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&activeOnly, "active", false, "Show only active tasks (exclude DONE and CANCELLED)")
+	cmd.Flags().StringVar(&by, "by", "", "Also break the counts down by project or tag")
 
 	return cmd
 }
 
-// formatSummary formats and displays task statistics
-func formatSummary(w io.Writer, tasks []*models.Task, activeOnly bool) {
-	// Initialize counters
-	stateCounts := make(map[string]int)
-	typeCounts := make(map[string]int)
-	priorityCounts := make(map[string]int)
-	blockedCount := 0
-	parentCount := 0
-	subtaskCount := 0
-
-	// Count tasks
-	activeTasks := 0
+// taskSummary holds task counts for summary, as printed with --json
+type taskSummary struct {
+	Total      int            `json:"total"`
+	Active     int            `json:"active"`
+	States     map[string]int `json:"states"`
+	Kinds      map[string]int `json:"kinds"`
+	Priorities map[string]int `json:"priorities"`
+	Severities map[string]int `json:"severities"` // Bugs and regressions with a severity
+	Tags       map[string]int `json:"tags"`
+	Blocked    int            `json:"blocked"`
+	Parents    int            `json:"parents"`
+	Subtasks   int            `json:"subtasks"`
+
+	// Age of the oldest INBOX task in hours, null when the inbox is empty
+	OldestInboxHours *float64 `json:"oldest_inbox_hours"`
+
+	By     string         `json:"by,omitempty"`
+	Groups []summaryGroup `json:"groups,omitempty"`
+
+	activeOnly bool
+}
+
+// summaryGroup holds the counts of one project or tag in a summary
+type summaryGroup struct {
+	Name    string         `json:"name"`
+	ID      string         `json:"id,omitempty"` // Top-level task of a project
+	Total   int            `json:"total"`
+	States  map[string]int `json:"states"`
+	Blocked int            `json:"blocked"`
+}
+
+// buildSummary counts tasks by state, kind, priority, severity, and tag,
+// leaving out DONE and CANCELLED tasks when activeOnly is set. by groups the
+// counts per project or tag when not empty.
+func buildSummary(tasks []*models.Task, activeOnly bool, by string, now time.Time) *taskSummary {
+	summary := &taskSummary{
+		States:     make(map[string]int),
+		Kinds:      make(map[string]int),
+		Priorities: make(map[string]int),
+		Severities: make(map[string]int),
+		Tags:       make(map[string]int),
+		By:         by,
+		activeOnly: activeOnly,
+	}
+
+	byID := make(map[string]*models.Task, len(tasks))
+	hasChildren := make(map[string]bool)
+	for _, task := range tasks {
+		byID[task.ID] = task
+		if task.Parent != nil {
+			hasChildren[*task.Parent] = true
+		}
+	}
+
+	groups := make(map[string]*summaryGroup)
+	var oldestInbox time.Time
 	for _, task := range tasks {
 		// Skip done/cancelled if activeOnly
 		if activeOnly && (task.State == models.StateDone || task.State == models.StateCancelled) {
 			continue
 		}
 
-		stateCounts[task.State]++
-		typeCounts[formatKind(task.Kind)]++
-		priorityCounts[task.Priority]++
+		summary.Total++
+		summary.States[task.State.String()]++
+		summary.Kinds[task.Kind.String()]++
+		summary.Priorities[task.Priority.String()]++
+		if task.Severity != "" {
+			summary.Severities[task.Severity.String()]++
+		}
+		for _, tag := range task.ParseTags() {
+			summary.Tags[tag]++
+		}
 
 		if task.IsBlocked() {
-			blockedCount++
+			summary.Blocked++
+		}
+		if hasChildren[task.ID] {
+			summary.Parents++
+		}
+		if task.Parent != nil {
+			summary.Subtasks++
+		}
+		if task.State == models.StateNew || task.State == models.StateInProgress {
+			summary.Active++
+		}
+		if task.State == models.StateInbox && (oldestInbox.IsZero() || task.Created.Before(oldestInbox)) {
+			oldestInbox = task.Created
 		}
 
-		// Count parents and subtasks
-		hasChildren := false
-		for _, other := range tasks {
-			if other.Parent != nil && *other.Parent == task.ID {
-				hasChildren = true
-				break
+		for _, key := range summaryGroupKeys(task, by, byID, hasChildren) {
+			group := groups[key.Name+"\x00"+key.ID]
+			if group == nil {
+				group = &summaryGroup{Name: key.Name, ID: key.ID, States: make(map[string]int)}
+				groups[key.Name+"\x00"+key.ID] = group
+			}
+			group.Total++
+			group.States[task.State.String()]++
+			if task.IsBlocked() {
+				group.Blocked++
 			}
 		}
-		if hasChildren {
-			parentCount++
+	}
+
+	if !oldestInbox.IsZero() {
+		hours := roundHours(now.Sub(oldestInbox))
+		summary.OldestInboxHours = &hours
+	}
+
+	// Largest groups first, and tasks outside any project or tag last
+	for _, group := range groups {
+		summary.Groups = append(summary.Groups, *group)
+	}
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		a, b := summary.Groups[i], summary.Groups[j]
+		if (a.Name == noProjectGroup || a.Name == untaggedGroup) != (b.Name == noProjectGroup || b.Name == untaggedGroup) {
+			return b.Name == noProjectGroup || b.Name == untaggedGroup
 		}
-		if task.Parent != nil {
-			subtaskCount++
+		if a.Total != b.Total {
+			return a.Total > b.Total
 		}
+		return a.Name < b.Name
+	})
 
-		if task.State == models.StateNew || task.State == models.StateInProgress {
-			activeTasks++
+	return summary
+}
+
+// summaryGroupKeys returns the groups a task is counted in: the project it
+// belongs to, or each of its tags
+func summaryGroupKeys(task *models.Task, by string, byID map[string]*models.Task, hasChildren map[string]bool) []summaryGroup {
+	switch by {
+	case "project":
+		// Follow the parents up to the top-level task, stopping at a loop
+		root := task
+		seen := map[string]bool{task.ID: true}
+		for root.Parent != nil && byID[*root.Parent] != nil && !seen[*root.Parent] {
+			root = byID[*root.Parent]
+			seen[root.ID] = true
+		}
+		if root == task && !hasChildren[task.ID] {
+			return []summaryGroup{{Name: noProjectGroup}}
+		}
+		return []summaryGroup{{Name: root.Title, ID: root.ID}}
+	case "tag":
+		tags := task.ParseTags()
+		if len(tags) == 0 {
+			return []summaryGroup{{Name: untaggedGroup}}
+		}
+		keys := make([]summaryGroup, len(tags))
+		for i, tag := range tags {
+			keys[i] = summaryGroup{Name: tag}
 		}
+		return keys
+	default:
+		return nil
 	}
+}
 
-	// Calculate total
-	total := 0
-	for _, count := range stateCounts {
-		total += count
-	}
+// formatSummary formats and displays task statistics
+func formatSummary(w io.Writer, summary *taskSummary) {
+	activeOnly := summary.activeOnly
 
 	// Display summary
 	if activeOnly {
-		_, _ = fmt.Fprintf(w, "Active Tasks: %d\n", activeTasks)
+		_, _ = fmt.Fprintf(w, "Active Tasks: %d\n", summary.Active)
 	} else {
 		_, _ = fmt.Fprintf(w, "Task Summary\n")
 		_, _ = fmt.Fprintln(w, strings.Repeat("=", 50))
-		_, _ = fmt.Fprintf(w, "Total Tasks: %d\n", total)
+		_, _ = fmt.Fprintf(w, "Total Tasks: %d\n", summary.Total)
 	}
 	_, _ = fmt.Fprintln(w)
 
 	// By State
 	_, _ = fmt.Fprintln(w, "By State:")
 	if !activeOnly {
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "INBOX:", stateCounts[models.StateInbox])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "INBOX:", summary.States[models.StateInbox.String()])
 	}
-	_, _ = fmt.Fprintf(w, "  %-12s %d\n", "NEW:", stateCounts[models.StateNew])
-	_, _ = fmt.Fprintf(w, "  %-12s %d\n", "IN_PROGRESS:", stateCounts[models.StateInProgress])
+	_, _ = fmt.Fprintf(w, "  %-12s %d\n", "NEW:", summary.States[models.StateNew.String()])
+	_, _ = fmt.Fprintf(w, "  %-12s %d\n", "IN_PROGRESS:", summary.States[models.StateInProgress.String()])
 	if !activeOnly {
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "DONE:", stateCounts[models.StateDone])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "CANCELLED:", stateCounts[models.StateCancelled])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "INVALID:", stateCounts[models.StateInvalid])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "DONE:", summary.States[models.StateDone.String()])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "CANCELLED:", summary.States[models.StateCancelled.String()])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "INVALID:", summary.States[models.StateInvalid.String()])
 	}
 
 	if !activeOnly {
@@ -125,22 +259,64 @@ func formatSummary(w io.Writer, tasks []*models.Task, activeOnly bool) {
 
 		// By Type
 		_, _ = fmt.Fprintln(w, "By Type:")
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Bug:", typeCounts["Bug"])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Feature:", typeCounts["Feature"])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Regression:", typeCounts["Regression"])
+		for _, kind := range models.Kinds {
+			_, _ = fmt.Fprintf(w, "  %-12s %d\n", formatKind(kind)+":", summary.Kinds[kind.String()])
+		}
 		_, _ = fmt.Fprintln(w)
 
 		// By Priority
 		_, _ = fmt.Fprintln(w, "By Priority:")
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "High:", priorityCounts[models.PriorityHigh])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Medium:", priorityCounts[models.PriorityMedium])
-		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Low:", priorityCounts[models.PriorityLow])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "High:", summary.Priorities[models.PriorityHigh.String()])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Medium:", summary.Priorities[models.PriorityMedium.String()])
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Low:", summary.Priorities[models.PriorityLow.String()])
+		_, _ = fmt.Fprintln(w)
+
+		// By Severity, of bugs and regressions
+		_, _ = fmt.Fprintln(w, "By Severity:")
+		rated := 0
+		for _, severity := range models.Severities {
+			n := summary.Severities[severity.String()]
+			rated += n
+			_, _ = fmt.Fprintf(w, "  %-12s %d\n", strings.ToUpper(severity.String()[:1])+severity.String()[1:]+":", n)
+		}
+		unrated := summary.Kinds[models.KindBug.String()] + summary.Kinds[models.KindRegression.String()] - rated
+		_, _ = fmt.Fprintf(w, "  %-12s %d\n", "Not rated:", unrated)
 		_, _ = fmt.Fprintln(w)
 
 		// Special categories
 		_, _ = fmt.Fprintln(w, "Special:")
-		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Blocked:", blockedCount)
-		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Parent tasks:", parentCount)
-		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Subtasks:", subtaskCount)
+		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Blocked:", summary.Blocked)
+		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Parent tasks:", summary.Parents)
+		_, _ = fmt.Fprintf(w, "  %-13s %d\n", "Subtasks:", summary.Subtasks)
+		if summary.OldestInboxHours != nil {
+			_, _ = fmt.Fprintf(w, "  %-13s %s\n", "Oldest inbox:", formatWorkDuration(time.Duration(*summary.OldestInboxHours*float64(time.Hour))))
+		}
+	}
+
+	if len(summary.Groups) > 0 {
+		_, _ = fmt.Fprintf(w, "\nBy %s:\n", strings.ToUpper(summary.By[:1])+summary.By[1:])
+		for _, group := range summary.Groups {
+			name := group.Name
+			if group.ID != "" {
+				name = colorize(models.ShortID(group.ID), colorYellow) + " " + name
+			}
+			_, _ = fmt.Fprintf(w, "  %s: %s\n", name, formatGroupCounts(group))
+		}
+	}
+}
+
+// formatGroupCounts describes the counts of a summary group, e.g.
+// "5 tasks (2 new, 3 done; 1 blocked)"
+func formatGroupCounts(group summaryGroup) string {
+	var parts []string
+	for _, state := range models.States {
+		if n := group.States[state.String()]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ToLower(strings.ReplaceAll(state.String(), "_", " "))))
+		}
+	}
+	counts := strings.Join(parts, ", ")
+	if group.Blocked > 0 {
+		counts += fmt.Sprintf("; %d blocked", group.Blocked)
 	}
+	return fmt.Sprintf("%s (%s)", formatTaskCount(group.Total, "task"), counts)
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -15,9 +16,9 @@ func TestSummaryCommand(t *testing.T) {
 
 	// Create a variety of tasks for statistics
 	tasks := []struct {
-		kind     string
-		state    string
-		priority string
+		kind     models.Kind
+		state    models.State
+		priority models.Priority
 		blocked  bool
 	}{
 		// Bugs
@@ -124,7 +125,7 @@ func TestSummaryCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
 
-			cmd := newSummaryCommand()
+			cmd := newSummaryCommand(NewApp())
 			cmd.SetOut(&stdout)
 			cmd.SetErr(&stderr)
 			cmd.SetArgs(tt.args)
@@ -144,3 +145,113 @@ func TestSummaryCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestSummaryJSONAndGroups(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	project := models.NewTask(models.KindFeature, "Launch", "Details")
+	project.State = models.StateInProgress
+	project.Tags = "web"
+	if err := testRepo.Create(project); err != nil {
+		t.Fatal(err)
+	}
+	step := models.NewTask(models.KindBug, "Fix login", "Details")
+	step.Parent = &project.ID
+	step.State = models.StateNew
+	step.Tags = "web,auth"
+	if err := testRepo.Create(step); err != nil {
+		t.Fatal(err)
+	}
+	nested := models.NewTask(models.KindBug, "Fix token refresh", "Details")
+	nested.Parent = &step.ID
+	nested.State = models.StateDone
+	if err := testRepo.Create(nested); err != nil {
+		t.Fatal(err)
+	}
+	loose := models.NewTask(models.KindBug, "Triage crash", "Details")
+	if err := testRepo.Create(loose); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newSummaryCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Run("by project", func(t *testing.T) {
+		out, err := run(t, "--by", "project")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"By Project:",
+			project.ShortHash() + " Launch: 3 tasks (1 new, 1 in progress, 1 done)",
+			"(no project): 1 task (1 inbox)",
+			"Oldest inbox:",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Output does not contain %q\nGot:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("by tag", func(t *testing.T) {
+		out, err := run(t, "--by", "tag")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"web: 2 tasks", "auth: 1 task", "(untagged): 2 tasks"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Output does not contain %q\nGot:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		jsonErrors = true
+		defer func() { jsonErrors = false }()
+
+		out, err := run(t, "--by", "project")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Total            int            `json:"total"`
+			States           map[string]int `json:"states"`
+			Kinds            map[string]int `json:"kinds"`
+			Tags             map[string]int `json:"tags"`
+			OldestInboxHours *float64       `json:"oldest_inbox_hours"`
+			Groups           []struct {
+				Name  string `json:"name"`
+				ID    string `json:"id"`
+				Total int    `json:"total"`
+			} `json:"groups"`
+		}
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if got.Total != 4 || got.States["INBOX"] != 1 || got.Kinds["BUG"] != 3 || got.Tags["web"] != 2 {
+			t.Errorf("unexpected counts: %+v", got)
+		}
+		if got.OldestInboxHours == nil {
+			t.Error("oldest_inbox_hours missing")
+		}
+		if len(got.Groups) != 2 || got.Groups[0].ID != project.ID || got.Groups[0].Total != 3 || got.Groups[1].Name != "(no project)" {
+			t.Errorf("unexpected groups: %+v", got.Groups)
+		}
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		_, err := run(t, "--by", "milestone")
+		if err == nil || !strings.Contains(err.Error(), "invalid grouping: milestone (must be project or tag)") {
+			t.Errorf("expected invalid grouping error, got %v", err)
+		}
+	})
+}
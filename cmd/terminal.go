@@ -1,27 +1,25 @@
 package cmd
 
 import (
+	"io"
 	"os"
-	"strings"
 
+	"github.com/zw3rk/gtd/internal/output"
 	"golang.org/x/term"
 )
 
 // ANSI color codes
 const (
-	colorReset = "\033[0m"
-	colorBold  = "\033[1m"
+	colorReset   = output.ANSIReset
+	colorBold    = output.ANSIBold
+	colorInverse = "\033[7m"
 
-	colorRed     = "\033[31m"
-	colorGreen   = "\033[32m"
-	colorYellow  = "\033[33m"
-	colorBlue    = "\033[34m"
-	colorCyan    = "\033[36m"
-	colorGray    = "\033[90m"
-
-	colorBrightRed    = "\033[91m"
-	colorBrightGreen  = "\033[92m"
-	colorBrightYellow = "\033[93m"
+	colorRed    = output.ANSIRed
+	colorGreen  = output.ANSIGreen
+	colorYellow = output.ANSIYellow
+	colorBlue   = output.ANSIBlue
+	colorCyan   = output.ANSICyan
+	colorGray   = output.ANSIGray
 )
 
 var (
@@ -63,32 +61,24 @@ func SetColorEnabled(enabled bool) {
 	useColor = enabled && isColorTerminal()
 }
 
-// formatStateColor returns colored state indicator
-func formatStateColor(state string) string {
-	switch state {
-	case "NEW":
-		return colorize("◆", colorCyan)
-	case "IN_PROGRESS":
-		return colorize("▶", colorBrightYellow)
-	case "DONE":
-		return colorize("✓", colorBrightGreen)
-	case "CANCELLED":
-		return colorize("✗", colorGray)
-	default:
-		return "?"
+// taskColors returns the color scheme of task output, with the tag colors
+// of GTD_TAG_COLORS, or nil when colors are disabled
+func (a *App) taskColors() *output.ColorScheme {
+	if !useColor {
+		return nil
 	}
-}
-
-// formatTagsColor returns colored tags with # prefix
-func formatTagsColor(tags string) string {
-	if tags == "" {
-		return ""
+	if len(a.config.TagColors) == 0 {
+		return output.DefaultColors
 	}
-
-	tagList := strings.Split(tags, ",")
-	coloredTags := make([]string, len(tagList))
-	for i, tag := range tagList {
-		coloredTags[i] = colorize("#"+strings.TrimSpace(tag), colorBlue)
+	tagColors := make(map[string]string, len(a.config.TagColors))
+	for tag, color := range a.config.TagColors {
+		tagColors[tag] = output.NamedColors[color]
 	}
-	return strings.Join(coloredTags, " ")
+	return output.DefaultColors.WithTagColors(tagColors)
+}
+
+// taskFormatter returns a formatter for task output that follows the color
+// and time settings
+func (a *App) taskFormatter(w io.Writer) *output.Formatter {
+	return output.NewColorFormatter(w, a.taskColors()).WithTimes(a.formatDate)
 }
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// dateLayouts are the absolute timestamp formats accepted by parseDate
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// durationUnits maps the short and long names of offset units to durations
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+var (
+	// signedOffset matches +3d, -2w, +90m
+	signedOffset = regexp.MustCompile(`^([+-])(\d+)\s*([a-z]+)$`)
+	// inOffset matches "in 3 days"
+	inOffset = regexp.MustCompile(`^in\s+(\d+)\s*([a-z]+)$`)
+	// agoOffset matches "3 days ago" and "2w ago"
+	agoOffset = regexp.MustCompile(`^(\d+)\s*([a-z]+)\s+ago$`)
+	// weekdayRef matches "friday", "next friday", and "last friday"
+	weekdayRef = regexp.MustCompile(`^(?:(next|last)\s+)?([a-z]+)$`)
+)
+
+// displayDateFormat shows an interpreted date back to the user
+const displayDateFormat = "Mon 2006-01-02 15:04"
+
+// parseDate parses a date flag value relative to now. It accepts absolute
+// dates and timestamps in local time, the words now, today, tomorrow, and
+// yesterday, weekdays ("friday", "next friday", "last monday"), "next week"
+// and "last week", and offsets such as +3d, -2w, "in 3 days", or "2 hours ago".
+// Day-based values resolve to midnight.
+func parseDate(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.NewValidationError("empty date value")
+	}
+
+	// Absolute timestamps
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	lower := strings.ToLower(strings.Join(strings.Fields(value), " "))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch lower {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "last week":
+		return today.AddDate(0, 0, -7), nil
+	}
+
+	// Offsets from now
+	if m := signedOffset.FindStringSubmatch(lower); m != nil {
+		if offset, ok := dateOffset(m[2], m[3]); ok {
+			if m[1] == "-" {
+				offset = -offset
+			}
+			return now.Add(offset), nil
+		}
+	}
+	if m := inOffset.FindStringSubmatch(lower); m != nil {
+		if offset, ok := dateOffset(m[1], m[2]); ok {
+			return now.Add(offset), nil
+		}
+	}
+	if m := agoOffset.FindStringSubmatch(lower); m != nil {
+		if offset, ok := dateOffset(m[1], m[2]); ok {
+			return now.Add(-offset), nil
+		}
+	}
+
+	// Weekdays: the next occurrence after today, or the last one before it
+	if m := weekdayRef.FindStringSubmatch(lower); m != nil {
+		if weekday, ok := parseWeekday(m[2]); ok {
+			days := (int(weekday) - int(today.Weekday()) + 7) % 7
+			if m[1] == "last" {
+				days = -((int(today.Weekday()) - int(weekday) + 7) % 7)
+				if days == 0 {
+					days = -7
+				}
+			} else if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+
+	return time.Time{}, errors.NewValidationError("invalid date %q (use e.g. tomorrow, next friday, +3d, 2 days ago, or 2006-01-02)", value)
+}
+
+// dateOffset converts a count and unit name into a duration
+func dateOffset(count, unit string) (time.Duration, bool) {
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, false
+	}
+	d, ok := durationUnits[unit]
+	return time.Duration(n) * d, ok
+}
+
+// parseWeekday parses a full or three-letter weekday name
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parseSince parses a --since value: either a relative age such as 30m, 24h,
+// 7d or 2w, or any date accepted by parseDate. Relative dates that land in
+// the future are rejected with the interpreted date so the user can see what
+// was understood; explicit future dates simply select nothing.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.NewValidationError("empty time value")
+	}
+
+	// Relative ages
+	if unit, ok := durationUnits[value[len(value)-1:]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+
+	// Absolute timestamps
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := parseDate(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.After(now) {
+		return time.Time{}, errors.NewValidationError("%q means %s, which is in the future", value, t.Format(displayDateFormat))
+	}
+	return t, nil
+}
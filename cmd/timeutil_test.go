@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestParseDate(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "now", want: now},
+		{value: "today", want: day(3, 13)},
+		{value: "Tomorrow", want: day(3, 14)},
+		{value: "yesterday", want: day(3, 12)},
+		{value: "next week", want: day(3, 20)},
+		{value: "last week", want: day(3, 6)},
+		{value: "friday", want: day(3, 15)},
+		{value: "next friday", want: day(3, 15)},
+		{value: "wednesday", want: day(3, 20)},
+		{value: "last monday", want: day(3, 11)},
+		{value: "last wed", want: day(3, 6)},
+		{value: "+3d", want: now.Add(3 * 24 * time.Hour)},
+		{value: "-2w", want: now.Add(-14 * 24 * time.Hour)},
+		{value: "+90m", want: now.Add(90 * time.Minute)},
+		{value: "in 3 days", want: now.Add(3 * 24 * time.Hour)},
+		{value: "2 hours ago", want: now.Add(-2 * time.Hour)},
+		{value: "2024-04-01", want: day(4, 1)},
+		{value: "", wantErr: true},
+		{value: "someday", wantErr: true},
+		{value: "+3y", wantErr: true},
+		{value: "next fortnight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseSinceFuture(t *testing.T) {
+	now := time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)
+
+	if got, err := parseSince("last monday", now); err != nil || !got.Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("parseSince(last monday) = %v, %v", got, err)
+	}
+
+	// Future dates are rejected, showing how the value was understood
+	_, err := parseSince("tomorrow", now)
+	if err == nil || !strings.Contains(err.Error(), "Thu 2024-03-14") {
+		t.Errorf("parseSince(tomorrow) error = %v, want interpreted date", err)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	app := NewApp()
+
+	ts := time.Now().Add(-3 * 24 * time.Hour)
+
+	app.Config().TimeFormat = "relative"
+	if got := app.formatTimestamp(ts); got != "3 days ago" {
+		t.Errorf("relative formatTimestamp() = %q, want %q", got, "3 days ago")
+	}
+
+	app.Config().TimeFormat = "absolute"
+	if got, want := app.formatTimestamp(ts), ts.Local().Format("2006-01-02 15:04"); got != want {
+		t.Errorf("absolute formatTimestamp() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	app := NewApp()
+
+	task := models.NewTask(models.KindBug, "Old bug", "Bug description")
+	task.Created = time.Now().Add(-3 * 24 * time.Hour)
+
+	app.Config().TimeFormat = "relative"
+	if got := app.formatTaskGitStyle(task, nil); !strings.Contains(got, "Date:   3 days ago\n") {
+		t.Errorf("relative git style output lacks the relative date:\n%s", got)
+	}
+
+	app.Config().TimeFormat = "absolute"
+	want := "Date:   " + task.Created.Format(time.RFC1123Z) + "\n"
+	if got := app.formatTaskGitStyle(task, nil); !strings.Contains(got, want) {
+		t.Errorf("absolute git style output lacks %q:\n%s", want, got)
+	}
+}
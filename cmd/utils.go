@@ -6,6 +6,10 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
 )
 
 // readTaskInput reads title and optional description from stdin
@@ -19,7 +23,7 @@ func readTaskInput(r io.Reader) (title, description string, err error) {
 	}
 
 	if title == "" {
-		return "", "", fmt.Errorf("title cannot be empty")
+		return "", "", errors.NewValidationError("title cannot be empty")
 	}
 
 	// Look for blank line separator (Git-style)
@@ -78,7 +82,27 @@ func readTaskInput(r io.Reader) (title, description string, err error) {
 	return title, description, nil
 }
 
+// readTaskFile reads title and description from a file, in the same format as stdin
+func readTaskFile(path string) (title, description string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return readTaskInput(file)
+}
+
 // formatTaskCreated formats the output message for a created task
-func formatTaskCreated(id string, kind string) string {
-	return fmt.Sprintf("Created %s task %s", strings.ToLower(kind), id)
+func formatTaskCreated(id string, kind models.Kind) string {
+	return fmt.Sprintf("Created %s task %s", strings.ToLower(kind.String()), id)
+}
+
+// rememberRecentTasks records the listed tasks so they can be referenced as @1..@9.
+// Failures are not fatal: the listing itself has already succeeded, so they
+// are only reported on the command's error output.
+func rememberRecentTasks(cmd *cobra.Command, tasks []*models.Task) {
+	if err := repo.SetRecentTasks(tasks); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to remember listed tasks: %v\n", err)
+	}
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+// buildInfo describes the gtd binary, for bug reports and for tools that
+// check what a gtd installation supports
+type buildInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit,omitempty"`
+	Modified      bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes
+	BuildDate     string `json:"build_date,omitempty"`
+	GoVersion     string `json:"go_version"`
+	Platform      string `json:"platform"`
+	SQLiteDriver  string `json:"sqlite_driver"`
+	SQLiteVersion string `json:"sqlite_version,omitempty"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// newVersionCommand creates the version command
+func newVersionCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the version of gtd, the commit and date it was built from, the SQLite
+driver and library it uses, and the database schema version it migrates
+databases to.
+
+With the global --json flag, the information is printed as JSON, so scripts
+and agents can check what an installation supports.`,
+		Example: `  gtd version
+  gtd version --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := readBuildInfo()
+			if jsonErrors {
+				return writeJSON(cmd.OutOrStdout(), info)
+			}
+			formatBuildInfo(cmd.OutOrStdout(), info)
+			return nil
+		},
+	}
+}
+
+// readBuildInfo combines the values set with -ldflags with the module and
+// VCS information Go embeds in the binary
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:       Version,
+		Commit:        Commit,
+		BuildDate:     BuildDate,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		SQLiteDriver:  database.Driver,
+		SchemaVersion: database.LatestSchemaVersion(),
+	}
+	if version, err := database.SQLiteVersion(); err == nil {
+		info.SQLiteVersion = version
+	}
+
+	embedded, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && embedded.Main.Version != "" && embedded.Main.Version != "(devel)" {
+		info.Version = embedded.Main.Version
+	}
+	for _, dep := range embedded.Deps {
+		if dep.Path == database.Driver {
+			info.SQLiteDriver += " " + dep.Version
+		}
+	}
+	for _, setting := range embedded.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// formatBuildInfo prints the build information one field per line
+func formatBuildInfo(w io.Writer, info buildInfo) {
+	_, _ = fmt.Fprintf(w, "gtd %s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		_, _ = fmt.Fprintf(w, "  %-10s %s\n", "Commit:", commit)
+	}
+	if info.BuildDate != "" {
+		_, _ = fmt.Fprintf(w, "  %-10s %s\n", "Built:", info.BuildDate)
+	}
+	_, _ = fmt.Fprintf(w, "  %-10s %s %s\n", "Go:", info.GoVersion, info.Platform)
+	sqlite := info.SQLiteDriver
+	if info.SQLiteVersion != "" {
+		sqlite = info.SQLiteVersion + " (" + info.SQLiteDriver + ")"
+	}
+	_, _ = fmt.Fprintf(w, "  %-10s %s\n", "SQLite:", sqlite)
+	_, _ = fmt.Fprintf(w, "  %-10s %d\n", "Schema:", info.SchemaVersion)
+}
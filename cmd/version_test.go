@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+func TestVersionCommand(t *testing.T) {
+	defer func(version, commit, date string) {
+		Version, Commit, BuildDate = version, commit, date
+	}(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.2.3", "abc1234", "2024-05-01T12:00:00Z"
+	defer func(orig bool) { jsonErrors = orig }(jsonErrors)
+
+	run := func() string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newVersionCommand(NewApp())
+		cmd.SetOut(&stdout)
+		cmd.SetArgs(nil)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("version error = %v", err)
+		}
+		return stdout.String()
+	}
+
+	jsonErrors = false
+	out := run()
+	for _, want := range []string{"gtd v1.2.3", "abc1234", "2024-05-01T12:00:00Z", database.Driver} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+
+	jsonErrors = true
+	var info buildInfo
+	if err := json.Unmarshal([]byte(run()), &info); err != nil {
+		t.Fatalf("version --json output is not JSON: %v", err)
+	}
+	if info.Version != "v1.2.3" || info.Commit != "abc1234" {
+		t.Errorf("Unexpected build info: %+v", info)
+	}
+	if info.SchemaVersion != database.LatestSchemaVersion() {
+		t.Errorf("schema_version = %d, want %d", info.SchemaVersion, database.LatestSchemaVersion())
+	}
+	if info.SQLiteVersion == "" {
+		t.Error("Expected the SQLite library version")
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/output"
+)
+
+// maxSimilarWarnings caps the similar tasks listed after adding a task
+const maxSimilarWarnings = 3
+
+// warnActiveWork warns before triaging the inbox that there is already work
+// in progress or accepted, unless GTD_SHOW_WARNINGS is off
+func warnActiveWork(app *App, w io.Writer) error {
+	if !app.Config().ShowWarnings {
+		return nil
+	}
+
+	active, err := repo.List(models.ListOptions{States: []models.State{models.StateNew, models.StateInProgress}})
+	if err != nil {
+		return fmt.Errorf("failed to check active tasks: %w", err)
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	inProgress := 0
+	for _, task := range active {
+		if task.State == models.StateInProgress {
+			inProgress++
+		}
+	}
+	_, _ = fmt.Fprintf(w, "⚠️  Warning: You have %d task(s) IN_PROGRESS and %d NEW. Consider completing them before reviewing INBOX.\n",
+		inProgress, len(active)-inProgress)
+	_, _ = fmt.Fprintf(w, "   Use 'gtd list' to see your active tasks.\n\n")
+	return nil
+}
+
+// warnSimilarTasks warns after adding a task that open tasks look like it,
+// so duplicates are caught while they are fresh, unless GTD_SHOW_WARNINGS is
+// off
+func warnSimilarTasks(app *App, w io.Writer, task *models.Task) error {
+	if !app.Config().ShowWarnings {
+		return nil
+	}
+
+	open, err := repo.List(models.ListOptions{States: []models.State{models.StateInbox, models.StateNew, models.StateInProgress}})
+	if err != nil {
+		return fmt.Errorf("failed to check similar tasks: %w", err)
+	}
+
+	var similar []*models.Task
+	for _, other := range open {
+		if other.ID != task.ID && models.Similarity(task, other) >= models.DefaultDuplicateThreshold {
+			similar = append(similar, other)
+		}
+	}
+	if len(similar) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "⚠️  Warning: %d similar open task(s) already exist:\n", len(similar))
+	for i, other := range similar {
+		if i == maxSimilarWarnings {
+			_, _ = fmt.Fprintf(w, "   ... and %d more; use 'gtd dedupe' to review them\n", len(similar)-i)
+			break
+		}
+		_, _ = fmt.Fprintf(w, "   %s\n", output.FormatTaskOneline(other))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestWarnings(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	app := NewApp()
+	started := models.NewTask(models.KindBug, "Fix login crash on empty password", "The login form crashes")
+	started.State = models.StateInProgress
+	accepted := models.NewTask(models.KindFeature, "Export to CSV", "Write tasks as CSV")
+	accepted.State = models.StateNew
+	inbox := models.NewTask(models.KindBug, "Typo in help", "Says 'teh'")
+	for _, task := range []*models.Task{started, accepted, inbox} {
+		if err := testRepo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	review := func() string {
+		var stdout, stderr bytes.Buffer
+		cmd := newReviewCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return stderr.String()
+	}
+	add := func(title string) string {
+		var stdout, stderr bytes.Buffer
+		cmd := newAddCommand(app)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetIn(strings.NewReader(title + "\n\nThe login form crashes"))
+		cmd.SetArgs([]string{"bug"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return stderr.String()
+	}
+
+	app.Config().ShowWarnings = true
+	if got := review(); !strings.Contains(got, "You have 1 task(s) IN_PROGRESS and 1 NEW") {
+		t.Errorf("review warnings = %q", got)
+	}
+	got := add("Fix login crash on empty username")
+	if !strings.Contains(got, "1 similar open task(s) already exist") || !strings.Contains(got, started.ShortHash()) {
+		t.Errorf("add warnings = %q", got)
+	}
+	if got := add("Dark mode for the settings page"); got != "" {
+		t.Errorf("Expected no warnings for an unrelated task, got %q", got)
+	}
+
+	app.Config().ShowWarnings = false
+	if got := review(); got != "" {
+		t.Errorf("Expected no review warnings, got %q", got)
+	}
+	if got := add("Fix login crash on empty email"); got != "" {
+		t.Errorf("Expected no add warnings, got %q", got)
+	}
+}
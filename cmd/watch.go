@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// watchPollInterval is how often watch checks the database for changes
+var watchPollInterval = 250 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// newWatchCommand creates the watch command
+func newWatchCommand(app *App) *cobra.Command {
+	var interval time.Duration
+	// watch accepts the flags of list and passes the ones given on to it
+	listFlags := newListCommand(app).Flags()
+
+	cmd := &cobra.Command{
+		Use:   "watch [--interval 10s] [list flags]",
+		Short: "Show a live-updating task list",
+		Long: `Show the output of gtd list and redraw it whenever the database changes,
+e.g. when tasks are added or updated from another terminal, and at least every
+--interval to keep relative times current. Any other flags are passed to list.
+Press Ctrl-C to stop.`,
+		Example: `  gtd watch
+  gtd watch --oneline
+  gtd watch --interval 30s --tag backend --oneline`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return errors.NewValidationError("invalid interval: %s", interval)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			return watchList(ctx, app, cmd.OutOrStdout(), changedFlagArgs(listFlags), interval)
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "Also redraw at least this often, to keep relative times current")
+	cmd.Flags().AddFlagSet(listFlags)
+
+	return cmd
+}
+
+// changedFlagArgs turns the flags of a set that were given on the command
+// line back into arguments, in alphabetical order
+func changedFlagArgs(flags *pflag.FlagSet) []string {
+	var args []string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		switch value := flag.Value.(type) {
+		case pflag.SliceValue:
+			for _, item := range value.GetSlice() {
+				args = append(args, "--"+flag.Name+"="+item)
+			}
+		default:
+			if flag.Value.Type() == "bool" && flag.Value.String() == "true" {
+				args = append(args, "--"+flag.Name)
+			} else {
+				args = append(args, "--"+flag.Name+"="+flag.Value.String())
+			}
+		}
+	})
+	return args
+}
+
+// watchList redraws the task list when the database changes or interval
+// passes, until ctx is cancelled
+func watchList(ctx context.Context, app *App, w io.Writer, listArgs []string, interval time.Duration) error {
+	if db.Encrypted() {
+		return errors.NewValidationError("gtd watch can't follow an encrypted database, which only one gtd command can open at a time")
+	}
+	if db.Postgres() {
+		return errors.NewValidationError("gtd watch follows changes to database files, but GTD_DATABASE_URL points to a Postgres database")
+	}
+	watcher, err := db.Watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Close() }()
+
+	draw := func() error {
+		var buf bytes.Buffer
+		list := newListCommand(app)
+		list.SetArgs(listArgs)
+		list.SetOut(&buf)
+		list.SetErr(&buf)
+		list.SilenceUsage = true
+		list.SilenceErrors = true
+		if err := list.Execute(); err != nil {
+			return err
+		}
+
+		header := fmt.Sprintf("Every %s: gtd list %s", interval, strings.Join(listArgs, " "))
+		_, _ = fmt.Fprintf(w, "%s%s  %s\n\n%s",
+			clearScreen, colorize(strings.TrimSpace(header), colorGray),
+			colorize(time.Now().Format("15:04:05"), colorGray), buf.String())
+
+		// Listing records the shown tasks; don't treat that as a change
+		_, err := watcher.Changed(ctx)
+		return err
+	}
+
+	if err := draw(); err != nil {
+		return err
+	}
+	lastDraw := time.Now()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, err := watcher.Changed(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if changed || time.Since(lastDraw) >= interval {
+			if err := draw(); err != nil {
+				return err
+			}
+			lastDraw = time.Now()
+		}
+	}
+}
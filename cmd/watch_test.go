@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchList(t *testing.T) {
+	_, testRepo, cleanup := setupTestCommand(t)
+	defer cleanup()
+
+	defer func(old time.Duration) { watchPollInterval = old }(watchPollInterval)
+	watchPollInterval = 5 * time.Millisecond
+
+	first := models.NewTask(models.KindBug, "First task", "Description")
+	first.State = models.StateNew
+	if err := testRepo.Create(first); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- watchList(ctx, NewApp(), &out, []string{"--oneline"}, time.Hour) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				cancel()
+				t.Fatalf("Timed out waiting for %q\nGot: %s", want, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor("First task")
+	if !strings.Contains(out.String(), "gtd list --oneline") {
+		t.Errorf("Output is missing the header\nGot: %s", out.String())
+	}
+
+	// A change from elsewhere triggers a redraw before the interval passes
+	second := models.NewTask(models.KindFeature, "Second task", "Description")
+	second.State = models.StateNew
+	if err := testRepo.Create(second); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("Second task")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchList() error = %v", err)
+	}
+	if draws := strings.Count(out.String(), clearScreen); draws > 3 {
+		t.Errorf("Expected redraws only on change, got %d draws", draws)
+	}
+}
+
+func TestWatchCommandFlags(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "watched.db")
+	t.Setenv("GTD_DATABASE_PATH", filepath.Join(t.TempDir(), "ignored.db"))
+
+	// Root flags such as --db apply to watch; list flags are passed on
+	add := NewRootCommand(NewApp())
+	add.SetOut(&bytes.Buffer{})
+	add.SetIn(strings.NewReader("Watched task\n\nDescription"))
+	add.SetArgs([]string{"--db", dbPath, "add", "bug"})
+	if err := add.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out syncBuffer
+	rootCmd := NewRootCommand(NewApp())
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"watch", "--db", dbPath, "--oneline", "--all", "--interval", "30s"})
+	done := make(chan error, 1)
+	go func() { done <- rootCmd.ExecuteContext(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "Watched task") {
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatalf("Timed out waiting for the list\nGot: %s", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch error = %v", err)
+	}
+	if want := "Every 30s: gtd list --all --oneline"; !strings.Contains(out.String(), want) {
+		t.Errorf("Output lacks %q\nGot: %s", want, out.String())
+	}
+
+	// Repeated flags are passed on once per value
+	watch := newWatchCommand(NewApp())
+	if err := watch.ParseFlags([]string{"--field", "a=1", "--field", "b", "--tag=x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(changedFlagArgs(watch.Flags()), " "); got != "--field=a=1 --field=b --tag=x" {
+		t.Errorf("changedFlagArgs() = %q", got)
+	}
+
+	for _, args := range [][]string{{"watch", "--interval", "soon"}, {"watch", "--interval=0s"}, {"watch", "extra"}} {
+		rootCmd := NewRootCommand(NewApp())
+		rootCmd.SetOut(&bytes.Buffer{})
+		rootCmd.SetErr(&bytes.Buffer{})
+		rootCmd.SetArgs(append([]string{"--db", dbPath}, args...))
+		if err := rootCmd.Execute(); err == nil {
+			t.Errorf("%v expected error", args)
+		}
+	}
+}
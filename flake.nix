@@ -29,6 +29,8 @@
           ldflags = [
             "-s"
             "-w"
+            "-X github.com/zw3rk/gtd/cmd.Version=0.1.0"
+            "-X github.com/zw3rk/gtd/cmd.Commit=${inputs.self.rev or "dirty"}"
           ] ++ pkgs.lib.optionals pkgs.stdenv.isLinux [
             "-linkmode external"
             "-extldflags '-static'"
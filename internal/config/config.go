@@ -2,10 +2,17 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/notify"
+	"github.com/zw3rk/gtd/internal/output"
 )
 
 // Config holds all configuration values for the application
@@ -13,17 +20,62 @@ type Config struct {
 	// Database configuration
 	DatabaseName string
 	DatabasePath string // Full path, empty means auto-detect
+	EncryptionKey string // Passphrase the database is encrypted with, empty for plaintext
+	Keychain     bool   // Read the encryption key from the OS keychain
+	DatabaseURL  string // Shared Postgres database URL, empty for the SQLite file
+	AutoMigrate  bool   // Migrate older databases on any command, not just 'gtd migrate'
 
 	// Output configuration
 	DefaultFormat string // json, csv, markdown, oneline, or empty for standard
 	ColorEnabled  bool
-	PageSize      int // Default number of items to show in lists
+	PageSize      int    // Default number of items to show in lists
+	TimeFormat    string // relative or absolute timestamps in list views
+	Timezone      string // IANA timezone for displaying timestamps, empty for local
+	ASCII         bool   // Bracketed words instead of Unicode state glyphs
+	Icons         map[string]string // Icon overrides keyed by state, priority, or BLOCKED
+	HashLength    int    // Length of abbreviated task IDs, longer when needed to be unambiguous
 
 	// Behavior configuration
 	AutoReview      bool // Automatically show review after adding tasks
 	ShowWarnings    bool // Show warnings about active tasks when reviewing
 	ConfirmDone     bool // Require confirmation when marking parent tasks done
 	DefaultPriority string
+	InboxLimit      int // Nudge to review when the inbox holds more tasks, 0 disables
+	DefaultCommand  string // Command run by a bare gtd, one of DefaultCommands; empty shows help
+	InheritTags     bool   // Give new subtasks the tags of their parent
+
+	// Per-tag settings, keyed by lowercase tag
+	TagColors     map[string]string // Color name of the titles and tags of tasks with the tag
+	TagWIPLimits  map[string]int    // Maximum number of IN_PROGRESS tasks with the tag
+	TagPriorities map[string]string // Priority of new tasks with the tag, unless one is given
+
+	// Cancellation
+	RequireReason bool // Require --reason when cancelling or rejecting tasks
+
+	// Priority escalation
+	EscalateAfterDays int // Raise the priority of NEW tasks untouched this long, 0 disables
+
+	// Validation rules for task titles and descriptions
+	MaxTitleLength       int  // 0 means no limit
+	MinDescriptionLength int  // 0 means no minimum
+	NoTrailingPeriod     bool // Reject titles ending with a period
+	ConventionalTitles   bool // Require conventional-commit style title prefixes
+
+	// External references
+	RefURLTemplate string // URL for ticket keys, with %s replaced by the key
+
+	// Source detection
+	AutoSource bool // Derive a missing task source from the current branch and commit
+
+	// Email digest
+	DigestTo       string // Comma-separated recipients of gtd digest --format email
+	DigestFrom     string // Sender address, empty for none
+	DigestSubject  string // Subject line, a text/template
+	DigestTemplate string // File with an html/template for the HTML part, empty for the built-in one
+
+	// Chat notifications
+	WebhookURL    string   // Slack or Mattermost incoming webhook, empty to disable
+	WebhookEvents []string // Events posted to the webhook
 
 	// Git configuration
 	GitRoot string // Detected git root, empty if not in git repo
@@ -36,14 +88,19 @@ type Config struct {
 func NewConfig() *Config {
 	return &Config{
 		DatabaseName:    "claude-tasks.db",
+		AutoMigrate:     true,
 		DefaultFormat:   "",
 		ColorEnabled:    true,
 		PageSize:        20,
+		TimeFormat:      "relative",
+		HashLength:      7,
 		AutoReview:      false,
 		ShowWarnings:    true,
 		ConfirmDone:     false,
 		DefaultPriority: "medium",
+		InboxLimit:      20,
 		Editor:          "vi",
+		WebhookEvents:   notify.Events,
 	}
 }
 
@@ -56,6 +113,22 @@ func (c *Config) Load() error {
 	if dbPath := os.Getenv("GTD_DATABASE_PATH"); dbPath != "" {
 		c.DatabasePath = dbPath
 	}
+	c.DatabaseURL = os.Getenv("GTD_DATABASE_URL")
+	if autoMigrate := os.Getenv("GTD_AUTO_MIGRATE"); autoMigrate != "" {
+		value, err := strconv.ParseBool(autoMigrate)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_AUTO_MIGRATE value: %s", autoMigrate)
+		}
+		c.AutoMigrate = value
+	}
+	c.EncryptionKey = os.Getenv("GTD_DB_KEY")
+	if keychain := os.Getenv("GTD_DB_KEYCHAIN"); keychain != "" {
+		value, err := strconv.ParseBool(keychain)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_DB_KEYCHAIN value: %s", keychain)
+		}
+		c.Keychain = value
+	}
 
 	// Output configuration
 	if format := os.Getenv("GTD_DEFAULT_FORMAT"); format != "" {
@@ -87,7 +160,48 @@ func (c *Config) Load() error {
 		c.PageSize = pageSize
 	}
 
+	if timeFormat := os.Getenv("GTD_TIME_FORMAT"); timeFormat != "" {
+		timeFormat = strings.ToLower(timeFormat)
+		switch timeFormat {
+		case "relative", "absolute":
+			c.TimeFormat = timeFormat
+		default:
+			return fmt.Errorf("invalid GTD_TIME_FORMAT: %s (must be relative or absolute)", timeFormat)
+		}
+	}
+
+	if tz := os.Getenv("GTD_TIMEZONE"); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid GTD_TIMEZONE: %s", tz)
+		}
+		c.Timezone = tz
+	}
+
 	// Behavior configuration
+	if ascii := os.Getenv("GTD_ASCII"); ascii != "" {
+		value, err := strconv.ParseBool(ascii)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_ASCII value: %s", ascii)
+		}
+		c.ASCII = value
+	}
+
+	if iconSpec := os.Getenv("GTD_ICONS"); iconSpec != "" {
+		icons, err := parseIcons(iconSpec)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_ICONS: %w", err)
+		}
+		c.Icons = icons
+	}
+
+	if hashLength := os.Getenv("GTD_HASH_LENGTH"); hashLength != "" {
+		length, err := strconv.Atoi(hashLength)
+		if err != nil || length < 4 || length > 40 {
+			return fmt.Errorf("invalid GTD_HASH_LENGTH: %s (must be 4 to 40)", hashLength)
+		}
+		c.HashLength = length
+	}
+
 	if autoReview := os.Getenv("GTD_AUTO_REVIEW"); autoReview != "" {
 		review, err := strconv.ParseBool(autoReview)
 		if err != nil {
@@ -122,6 +236,173 @@ func (c *Config) Load() error {
 		}
 	}
 
+	if command := os.Getenv("GTD_DEFAULT_COMMAND"); command != "" {
+		command = strings.ToLower(strings.TrimSpace(command))
+		if !slices.Contains(DefaultCommands, command) {
+			return fmt.Errorf("invalid GTD_DEFAULT_COMMAND: %s (must be %s)", command, strings.Join(DefaultCommands, ", "))
+		}
+		c.DefaultCommand = command
+	}
+
+	if spec := os.Getenv("GTD_TAG_COLORS"); spec != "" {
+		colors, err := parseTagValues(spec)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_TAG_COLORS: %w", err)
+		}
+		for tag, color := range colors {
+			if _, ok := output.NamedColors[color]; !ok {
+				names := slices.Sorted(maps.Keys(output.NamedColors))
+				return fmt.Errorf("invalid GTD_TAG_COLORS: unknown color %q for tag %s (must be one of %s)", color, tag, strings.Join(names, ", "))
+			}
+		}
+		c.TagColors = colors
+	}
+
+	if spec := os.Getenv("GTD_TAG_WIP_LIMITS"); spec != "" {
+		values, err := parseTagValues(spec)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_TAG_WIP_LIMITS: %w", err)
+		}
+		c.TagWIPLimits = make(map[string]int, len(values))
+		for tag, value := range values {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return fmt.Errorf("invalid GTD_TAG_WIP_LIMITS: limit %q for tag %s must be a number of at least 1", value, tag)
+			}
+			c.TagWIPLimits[tag] = limit
+		}
+	}
+
+	if spec := os.Getenv("GTD_TAG_PRIORITIES"); spec != "" {
+		priorities, err := parseTagValues(spec)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_TAG_PRIORITIES: %w", err)
+		}
+		for tag, priority := range priorities {
+			switch priority {
+			case "high", "medium", "low":
+				// valid
+			default:
+				return fmt.Errorf("invalid GTD_TAG_PRIORITIES: priority %q for tag %s (must be high, medium, or low)", priority, tag)
+			}
+		}
+		c.TagPriorities = priorities
+	}
+
+	if inherit := os.Getenv("GTD_INHERIT_TAGS"); inherit != "" {
+		value, err := strconv.ParseBool(inherit)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_INHERIT_TAGS value: %s", inherit)
+		}
+		c.InheritTags = value
+	}
+
+	if requireReason := os.Getenv("GTD_REQUIRE_REASON"); requireReason != "" {
+		value, err := strconv.ParseBool(requireReason)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_REQUIRE_REASON value: %s", requireReason)
+		}
+		c.RequireReason = value
+	}
+
+	if inboxLimit := os.Getenv("GTD_INBOX_LIMIT"); inboxLimit != "" {
+		limit, err := strconv.Atoi(inboxLimit)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid GTD_INBOX_LIMIT: %s", inboxLimit)
+		}
+		c.InboxLimit = limit
+	}
+
+	if escalate := os.Getenv("GTD_ESCALATE_AFTER_DAYS"); escalate != "" {
+		days, err := strconv.Atoi(escalate)
+		if err != nil || days < 0 {
+			return fmt.Errorf("invalid GTD_ESCALATE_AFTER_DAYS: %s", escalate)
+		}
+		c.EscalateAfterDays = days
+	}
+
+	// Validation rules
+	if maxTitle := os.Getenv("GTD_MAX_TITLE_LENGTH"); maxTitle != "" {
+		length, err := strconv.Atoi(maxTitle)
+		if err != nil || length < 0 {
+			return fmt.Errorf("invalid GTD_MAX_TITLE_LENGTH: %s", maxTitle)
+		}
+		c.MaxTitleLength = length
+	}
+
+	if minDesc := os.Getenv("GTD_MIN_DESCRIPTION_LENGTH"); minDesc != "" {
+		length, err := strconv.Atoi(minDesc)
+		if err != nil || length < 0 {
+			return fmt.Errorf("invalid GTD_MIN_DESCRIPTION_LENGTH: %s", minDesc)
+		}
+		c.MinDescriptionLength = length
+	}
+
+	if noPeriod := os.Getenv("GTD_NO_TRAILING_PERIOD"); noPeriod != "" {
+		value, err := strconv.ParseBool(noPeriod)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_NO_TRAILING_PERIOD value: %s", noPeriod)
+		}
+		c.NoTrailingPeriod = value
+	}
+
+	if conventional := os.Getenv("GTD_CONVENTIONAL_TITLES"); conventional != "" {
+		value, err := strconv.ParseBool(conventional)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_CONVENTIONAL_TITLES value: %s", conventional)
+		}
+		c.ConventionalTitles = value
+	}
+
+	// External references
+	if refTemplate := os.Getenv("GTD_REF_URL_TEMPLATE"); refTemplate != "" {
+		if !strings.Contains(refTemplate, "%s") {
+			return fmt.Errorf("invalid GTD_REF_URL_TEMPLATE: %s (must contain %%s)", refTemplate)
+		}
+		c.RefURLTemplate = refTemplate
+	}
+
+	// Source detection
+	if auto := os.Getenv("GTD_AUTO_SOURCE"); auto != "" {
+		value, err := strconv.ParseBool(auto)
+		if err != nil {
+			return fmt.Errorf("invalid GTD_AUTO_SOURCE value: %s", auto)
+		}
+		c.AutoSource = value
+	}
+
+	// Email digest
+	c.DigestTo = os.Getenv("GTD_DIGEST_TO")
+	c.DigestFrom = os.Getenv("GTD_DIGEST_FROM")
+	if subject := os.Getenv("GTD_DIGEST_SUBJECT"); subject != "" {
+		if _, err := template.New("subject").Parse(subject); err != nil {
+			return fmt.Errorf("invalid GTD_DIGEST_SUBJECT: %w", err)
+		}
+		c.DigestSubject = subject
+	}
+	c.DigestTemplate = os.Getenv("GTD_DIGEST_TEMPLATE")
+
+	// Chat notifications
+	if webhook := os.Getenv("GTD_WEBHOOK_URL"); webhook != "" {
+		if !strings.HasPrefix(webhook, "https://") && !strings.HasPrefix(webhook, "http://") {
+			return fmt.Errorf("invalid GTD_WEBHOOK_URL: %s (must be an http or https URL)", webhook)
+		}
+		c.WebhookURL = webhook
+	}
+	if events := os.Getenv("GTD_WEBHOOK_EVENTS"); events != "" {
+		c.WebhookEvents = nil
+		for _, event := range strings.Split(events, ",") {
+			event = strings.ToLower(strings.TrimSpace(event))
+			if event == "" {
+				continue
+			}
+			if !slices.Contains(notify.Events, event) {
+				return fmt.Errorf("invalid GTD_WEBHOOK_EVENTS: unknown event %q (must be %s)", event, strings.Join(notify.Events, ", "))
+			}
+			c.WebhookEvents = append(c.WebhookEvents, event)
+		}
+	}
+
 	// Editor configuration
 	if editor := os.Getenv("EDITOR"); editor != "" {
 		c.Editor = editor
@@ -133,6 +414,57 @@ func (c *Config) Load() error {
 	return nil
 }
 
+// DefaultCommands are the commands GTD_DEFAULT_COMMAND can name. They all
+// run without arguments.
+var DefaultCommands = []string{"help", "summary", "list", "inbox", "focus"}
+
+// iconKeys are the names accepted in GTD_ICONS, in their canonical case
+var iconKeys = []string{
+	"INBOX", "NEW", "IN_PROGRESS", "DONE", "CANCELLED", "INVALID", "BLOCKED", "PINNED",
+	"high", "medium", "low",
+}
+
+// parseTagValues parses per-tag settings such as "incident=red,docs=blue".
+// Tags and values are lowercased.
+func parseTagValues(spec string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		tag, value, ok := strings.Cut(pair, "=")
+		tag, value = strings.ToLower(strings.TrimSpace(tag)), strings.ToLower(strings.TrimSpace(value))
+		if !ok || tag == "" || value == "" {
+			return nil, fmt.Errorf("%q must be TAG=VALUE", pair)
+		}
+		values[tag] = value
+	}
+	return values, nil
+}
+
+// parseIcons parses icon overrides such as "DONE=✅,BLOCKED=🚫,high=🔴".
+// Names are case-insensitive.
+func parseIcons(spec string) (map[string]string, error) {
+	icons := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		name, icon, ok := strings.Cut(pair, "=")
+		name, icon = strings.TrimSpace(name), strings.TrimSpace(icon)
+		if !ok || icon == "" {
+			return nil, fmt.Errorf("%q must be NAME=ICON", pair)
+		}
+
+		key := ""
+		for _, candidate := range iconKeys {
+			if strings.EqualFold(name, candidate) {
+				key = candidate
+				break
+			}
+		}
+		if key == "" {
+			return nil, fmt.Errorf("unknown icon name %q (must be one of %s)", name, strings.Join(iconKeys, ", "))
+		}
+		icons[key] = icon
+	}
+	return icons, nil
+}
+
 // LoadFromFile loads configuration from a file (future enhancement)
 func (c *Config) LoadFromFile(path string) error {
 	// TODO: Implement config file loading (YAML/TOML)
@@ -165,7 +497,7 @@ func (c *Config) Validate() error {
 	// Validate format if set
 	if c.DefaultFormat != "" {
 		switch c.DefaultFormat {
-		case "json", "csv", "markdown", "oneline":
+		case "json", "csv", "markdown", "oneline", "standard":
 			// valid
 		default:
 			return fmt.Errorf("invalid default format: %s", c.DefaultFormat)
@@ -184,14 +516,37 @@ func (c *Config) Validate() error {
 func (c *Config) String() string {
 	var sb strings.Builder
 	sb.WriteString("GTD Configuration:\n")
-	sb.WriteString(fmt.Sprintf("  Database: %s\n", c.GetDatabasePath()))
+	if c.DatabaseURL != "" {
+		// The URL may hold a password
+		sb.WriteString("  Database: Postgres (GTD_DATABASE_URL)\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("  Database: %s\n", c.GetDatabasePath()))
+	}
+	sb.WriteString(fmt.Sprintf("  Auto Migrate: %v\n", c.AutoMigrate))
 	sb.WriteString(fmt.Sprintf("  Default Format: %s\n", c.DefaultFormat))
 	sb.WriteString(fmt.Sprintf("  Color Enabled: %v\n", c.ColorEnabled))
 	sb.WriteString(fmt.Sprintf("  Page Size: %d\n", c.PageSize))
+	sb.WriteString(fmt.Sprintf("  Time Format: %s\n", c.TimeFormat))
+	sb.WriteString(fmt.Sprintf("  Timezone: %s\n", c.Timezone))
+	sb.WriteString(fmt.Sprintf("  ASCII: %v\n", c.ASCII))
+	sb.WriteString(fmt.Sprintf("  Icons: %v\n", c.Icons))
 	sb.WriteString(fmt.Sprintf("  Auto Review: %v\n", c.AutoReview))
 	sb.WriteString(fmt.Sprintf("  Show Warnings: %v\n", c.ShowWarnings))
 	sb.WriteString(fmt.Sprintf("  Confirm Done: %v\n", c.ConfirmDone))
 	sb.WriteString(fmt.Sprintf("  Default Priority: %s\n", c.DefaultPriority))
+	sb.WriteString(fmt.Sprintf("  Default Command: %s\n", c.DefaultCommand))
+	sb.WriteString(fmt.Sprintf("  Inherit Tags: %v\n", c.InheritTags))
+	sb.WriteString(fmt.Sprintf("  Tag Colors: %v\n", c.TagColors))
+	sb.WriteString(fmt.Sprintf("  Tag WIP Limits: %v\n", c.TagWIPLimits))
+	sb.WriteString(fmt.Sprintf("  Tag Priorities: %v\n", c.TagPriorities))
+	sb.WriteString(fmt.Sprintf("  Require Reason: %v\n", c.RequireReason))
+	sb.WriteString(fmt.Sprintf("  Escalate After Days: %d\n", c.EscalateAfterDays))
+	sb.WriteString(fmt.Sprintf("  Max Title Length: %d\n", c.MaxTitleLength))
+	sb.WriteString(fmt.Sprintf("  Min Description Length: %d\n", c.MinDescriptionLength))
+	sb.WriteString(fmt.Sprintf("  No Trailing Period: %v\n", c.NoTrailingPeriod))
+	sb.WriteString(fmt.Sprintf("  Conventional Titles: %v\n", c.ConventionalTitles))
+	sb.WriteString(fmt.Sprintf("  Ref URL Template: %s\n", c.RefURLTemplate))
+	sb.WriteString(fmt.Sprintf("  Auto Source: %v\n", c.AutoSource))
 	sb.WriteString(fmt.Sprintf("  Editor: %s\n", c.Editor))
 	return sb.String()
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -23,6 +24,34 @@ func TestNewConfig(t *testing.T) {
 	if cfg.DefaultPriority != "medium" {
 		t.Errorf("DefaultPriority = %s, want medium", cfg.DefaultPriority)
 	}
+	if cfg.TimeFormat != "relative" {
+		t.Errorf("TimeFormat = %s, want relative", cfg.TimeFormat)
+	}
+	if cfg.InboxLimit != 20 {
+		t.Errorf("InboxLimit = %d, want 20", cfg.InboxLimit)
+	}
+	if cfg.HashLength != 7 {
+		t.Errorf("HashLength = %d, want 7", cfg.HashLength)
+	}
+	if !cfg.AutoMigrate {
+		t.Error("AutoMigrate = false, want true")
+	}
+}
+
+func TestConfigLoadAutoMigrate(t *testing.T) {
+	t.Setenv("GTD_AUTO_MIGRATE", "false")
+	cfg := NewConfig()
+	if err := cfg.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AutoMigrate {
+		t.Error("AutoMigrate = true, want false")
+	}
+
+	t.Setenv("GTD_AUTO_MIGRATE", "sometimes")
+	if err := NewConfig().Load(); err == nil {
+		t.Error("Expected error for invalid GTD_AUTO_MIGRATE")
+	}
 }
 
 func TestConfigLoad(t *testing.T) {
@@ -39,6 +68,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    true,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vi",
@@ -53,6 +84,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "custom.db",
 				ColorEnabled:    true,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vi",
@@ -67,6 +100,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    false,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vi",
@@ -81,6 +116,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    false,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vi",
@@ -95,6 +132,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    true,
 				PageSize:        50,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vi",
@@ -109,6 +148,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    true,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "nano",
@@ -124,6 +165,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    true,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				ShowWarnings:    true,
 				Editor:          "vim",
@@ -140,6 +183,8 @@ func TestConfigLoad(t *testing.T) {
 				DatabaseName:    "claude-tasks.db",
 				ColorEnabled:    true,
 				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
 				DefaultPriority: "medium",
 				AutoReview:      true,
 				ShowWarnings:    false,
@@ -147,6 +192,401 @@ func TestConfigLoad(t *testing.T) {
 				Editor:          "vi",
 			},
 		},
+		{
+			name: "validation rules",
+			envVars: map[string]string{
+				"GTD_MAX_TITLE_LENGTH":       "72",
+				"GTD_MIN_DESCRIPTION_LENGTH": "20",
+				"GTD_NO_TRAILING_PERIOD":     "true",
+				"GTD_CONVENTIONAL_TITLES":    "true",
+			},
+			want: &Config{
+				DatabaseName:         "claude-tasks.db",
+				ColorEnabled:         true,
+				PageSize:             20,
+				InboxLimit:           20,
+				HashLength:           7,
+				DefaultPriority:      "medium",
+				ShowWarnings:         true,
+				Editor:               "vi",
+				MaxTitleLength:       72,
+				MinDescriptionLength: 20,
+				NoTrailingPeriod:     true,
+				ConventionalTitles:   true,
+			},
+		},
+		{
+			name: "ref URL template",
+			envVars: map[string]string{
+				"GTD_REF_URL_TEMPLATE": "https://jira.example.com/browse/%s",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+				RefURLTemplate:  "https://jira.example.com/browse/%s",
+			},
+		},
+		{
+			name: "email digest",
+			envVars: map[string]string{
+				"GTD_DIGEST_TO":      "team@example.com,lead@example.com",
+				"GTD_DIGEST_FROM":    "gtd@example.com",
+				"GTD_DIGEST_SUBJECT": "Tasks: {{.Title}}",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+				DigestTo:        "team@example.com,lead@example.com",
+				DigestFrom:      "gtd@example.com",
+				DigestSubject:   "Tasks: {{.Title}}",
+			},
+		},
+		{
+			name: "webhook",
+			envVars: map[string]string{
+				"GTD_WEBHOOK_URL":    "https://hooks.slack.com/services/T0/B0/XXXX",
+				"GTD_WEBHOOK_EVENTS": "done, Inbox-Full",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+				WebhookURL:      "https://hooks.slack.com/services/T0/B0/XXXX",
+				WebhookEvents:   []string{"done", "inbox-full"},
+			},
+		},
+		{
+			name: "absolute time format",
+			envVars: map[string]string{
+				"GTD_TIME_FORMAT": "Absolute",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				TimeFormat:      "absolute",
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "timezone",
+			envVars: map[string]string{
+				"GTD_TIMEZONE": "Europe/Berlin",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				Timezone:        "Europe/Berlin",
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "ascii output",
+			envVars: map[string]string{
+				"GTD_ASCII": "1",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				ASCII:           true,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "invalid ascii value",
+			envVars: map[string]string{
+				"GTD_ASCII": "maybe",
+			},
+			wantErr: true,
+		},
+		{
+			name: "icon overrides",
+			envVars: map[string]string{
+				"GTD_ICONS": "done=✅, BLOCKED=🚫,High=🔴",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				Icons:           map[string]string{"DONE": "✅", "BLOCKED": "🚫", "high": "🔴"},
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "unknown icon name",
+			envVars: map[string]string{
+				"GTD_ICONS": "URGENT=🔥",
+			},
+			wantErr: true,
+		},
+		{
+			name: "icon without value",
+			envVars: map[string]string{
+				"GTD_ICONS": "DONE",
+			},
+			wantErr: true,
+		},
+		{
+			name: "require reason",
+			envVars: map[string]string{
+				"GTD_REQUIRE_REASON": "true",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				RequireReason:   true,
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "inherit tags",
+			envVars: map[string]string{
+				"GTD_INHERIT_TAGS": "true",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				InheritTags:     true,
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "invalid inherit tags",
+			envVars: map[string]string{
+				"GTD_INHERIT_TAGS": "sometimes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "escalation",
+			envVars: map[string]string{
+				"GTD_ESCALATE_AFTER_DAYS": "14",
+			},
+			want: &Config{
+				DatabaseName:      "claude-tasks.db",
+				ColorEnabled:      true,
+				PageSize:          20,
+				InboxLimit:        20,
+				HashLength:        7,
+				DefaultPriority:   "medium",
+				EscalateAfterDays: 14,
+				ShowWarnings:      true,
+				Editor:            "vi",
+			},
+		},
+		{
+			name: "inbox limit",
+			envVars: map[string]string{
+				"GTD_INBOX_LIMIT": "0",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "invalid inbox limit",
+			envVars: map[string]string{
+				"GTD_INBOX_LIMIT": "many",
+			},
+			wantErr: true,
+		},
+		{
+			name: "hash length",
+			envVars: map[string]string{
+				"GTD_HASH_LENGTH": "10",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      10,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "database encryption",
+			envVars: map[string]string{
+				"GTD_DB_KEY":      "s3cret",
+				"GTD_DB_KEYCHAIN": "true",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				EncryptionKey:   "s3cret",
+				Keychain:        true,
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "database URL",
+			envVars: map[string]string{
+				"GTD_DATABASE_URL": "postgres://gtd@db.example.com/tasks",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				DatabaseURL:     "postgres://gtd@db.example.com/tasks",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "invalid keychain",
+			envVars: map[string]string{
+				"GTD_DB_KEYCHAIN": "maybe",
+			},
+			wantErr: true,
+		},
+		{
+			name: "hash length too short",
+			envVars: map[string]string{
+				"GTD_HASH_LENGTH": "3",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid escalation days",
+			envVars: map[string]string{
+				"GTD_ESCALATE_AFTER_DAYS": "-1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid timezone",
+			envVars: map[string]string{
+				"GTD_TIMEZONE": "Mars/Olympus_Mons",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid time format",
+			envVars: map[string]string{
+				"GTD_TIME_FORMAT": "fuzzy",
+			},
+			wantErr: true,
+		},
+		{
+			name: "auto source",
+			envVars: map[string]string{
+				"GTD_AUTO_SOURCE": "true",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				ShowWarnings:    true,
+				Editor:          "vi",
+				AutoSource:      true,
+			},
+		},
+		{
+			name: "invalid auto source",
+			envVars: map[string]string{
+				"GTD_AUTO_SOURCE": "sometimes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "ref URL template without placeholder",
+			envVars: map[string]string{
+				"GTD_REF_URL_TEMPLATE": "https://jira.example.com/browse/",
+			},
+			wantErr: true,
+		},
+		{
+			name: "webhook URL without scheme",
+			envVars: map[string]string{
+				"GTD_WEBHOOK_URL": "hooks.slack.com/services/T0/B0/XXXX",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown webhook event",
+			envVars: map[string]string{
+				"GTD_WEBHOOK_EVENTS": "done,deleted",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid digest subject",
+			envVars: map[string]string{
+				"GTD_DIGEST_SUBJECT": "Tasks: {{.Title",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max title length",
+			envVars: map[string]string{
+				"GTD_MAX_TITLE_LENGTH": "-1",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid format",
 			envVars: map[string]string{
@@ -154,6 +594,30 @@ func TestConfigLoad(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "default command",
+			envVars: map[string]string{
+				"GTD_DEFAULT_COMMAND": " Summary ",
+			},
+			want: &Config{
+				DatabaseName:    "claude-tasks.db",
+				ColorEnabled:    true,
+				PageSize:        20,
+				InboxLimit:      20,
+				HashLength:      7,
+				DefaultPriority: "medium",
+				DefaultCommand:  "summary",
+				ShowWarnings:    true,
+				Editor:          "vi",
+			},
+		},
+		{
+			name: "invalid default command",
+			envVars: map[string]string{
+				"GTD_DEFAULT_COMMAND": "add",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid priority",
 			envVars: map[string]string{
@@ -192,7 +656,14 @@ func TestConfigLoad(t *testing.T) {
 					"GTD_DATABASE_NAME", "GTD_DATABASE_PATH", "GTD_DEFAULT_FORMAT",
 					"GTD_COLOR", "NO_COLOR", "GTD_PAGE_SIZE", "GTD_AUTO_REVIEW",
 					"GTD_SHOW_WARNINGS", "GTD_CONFIRM_DONE", "GTD_DEFAULT_PRIORITY",
-					"EDITOR", "VISUAL",
+					"EDITOR", "VISUAL", "GTD_MAX_TITLE_LENGTH", "GTD_MIN_DESCRIPTION_LENGTH",
+					"GTD_NO_TRAILING_PERIOD", "GTD_CONVENTIONAL_TITLES", "GTD_REF_URL_TEMPLATE",
+					"GTD_AUTO_SOURCE", "GTD_TIME_FORMAT", "GTD_TIMEZONE", "GTD_ASCII", "GTD_ICONS",
+					"GTD_ESCALATE_AFTER_DAYS", "GTD_REQUIRE_REASON", "GTD_INBOX_LIMIT",
+					"GTD_HASH_LENGTH", "GTD_DB_KEY", "GTD_DB_KEYCHAIN",
+					"GTD_DATABASE_URL", "GTD_DIGEST_TO", "GTD_DIGEST_FROM", "GTD_DIGEST_SUBJECT",
+					"GTD_DIGEST_TEMPLATE", "GTD_WEBHOOK_URL", "GTD_WEBHOOK_EVENTS",
+					"GTD_DEFAULT_COMMAND", "GTD_INHERIT_TAGS",
 				}
 				for _, v := range vars {
 					_ = os.Unsetenv(v)
@@ -239,9 +710,72 @@ func TestConfigLoad(t *testing.T) {
 				if cfg.ConfirmDone != tt.want.ConfirmDone {
 					t.Errorf("ConfirmDone = %v, want %v", cfg.ConfirmDone, tt.want.ConfirmDone)
 				}
+				if cfg.DefaultCommand != tt.want.DefaultCommand {
+					t.Errorf("DefaultCommand = %s, want %s", cfg.DefaultCommand, tt.want.DefaultCommand)
+				}
 				if cfg.Editor != tt.want.Editor {
 					t.Errorf("Editor = %s, want %s", cfg.Editor, tt.want.Editor)
 				}
+				if cfg.MaxTitleLength != tt.want.MaxTitleLength {
+					t.Errorf("MaxTitleLength = %d, want %d", cfg.MaxTitleLength, tt.want.MaxTitleLength)
+				}
+				if cfg.MinDescriptionLength != tt.want.MinDescriptionLength {
+					t.Errorf("MinDescriptionLength = %d, want %d", cfg.MinDescriptionLength, tt.want.MinDescriptionLength)
+				}
+				if cfg.NoTrailingPeriod != tt.want.NoTrailingPeriod {
+					t.Errorf("NoTrailingPeriod = %v, want %v", cfg.NoTrailingPeriod, tt.want.NoTrailingPeriod)
+				}
+				if cfg.ConventionalTitles != tt.want.ConventionalTitles {
+					t.Errorf("ConventionalTitles = %v, want %v", cfg.ConventionalTitles, tt.want.ConventionalTitles)
+				}
+				if cfg.RefURLTemplate != tt.want.RefURLTemplate {
+					t.Errorf("RefURLTemplate = %v, want %v", cfg.RefURLTemplate, tt.want.RefURLTemplate)
+				}
+				if tt.want.TimeFormat != "" && cfg.TimeFormat != tt.want.TimeFormat {
+					t.Errorf("TimeFormat = %v, want %v", cfg.TimeFormat, tt.want.TimeFormat)
+				}
+				if cfg.Timezone != tt.want.Timezone {
+					t.Errorf("Timezone = %v, want %v", cfg.Timezone, tt.want.Timezone)
+				}
+				if cfg.ASCII != tt.want.ASCII {
+					t.Errorf("ASCII = %v, want %v", cfg.ASCII, tt.want.ASCII)
+				}
+				if !reflect.DeepEqual(cfg.Icons, tt.want.Icons) {
+					t.Errorf("Icons = %v, want %v", cfg.Icons, tt.want.Icons)
+				}
+				if cfg.InheritTags != tt.want.InheritTags {
+					t.Errorf("InheritTags = %v, want %v", cfg.InheritTags, tt.want.InheritTags)
+				}
+				if cfg.RequireReason != tt.want.RequireReason {
+					t.Errorf("RequireReason = %v, want %v", cfg.RequireReason, tt.want.RequireReason)
+				}
+				if cfg.EscalateAfterDays != tt.want.EscalateAfterDays {
+					t.Errorf("EscalateAfterDays = %d, want %d", cfg.EscalateAfterDays, tt.want.EscalateAfterDays)
+				}
+				if cfg.DatabaseURL != tt.want.DatabaseURL {
+					t.Errorf("DatabaseURL = %q, want %q", cfg.DatabaseURL, tt.want.DatabaseURL)
+				}
+				if cfg.EncryptionKey != tt.want.EncryptionKey {
+					t.Errorf("EncryptionKey = %q, want %q", cfg.EncryptionKey, tt.want.EncryptionKey)
+				}
+				if cfg.Keychain != tt.want.Keychain {
+					t.Errorf("Keychain = %v, want %v", cfg.Keychain, tt.want.Keychain)
+				}
+				if cfg.AutoSource != tt.want.AutoSource {
+					t.Errorf("AutoSource = %v, want %v", cfg.AutoSource, tt.want.AutoSource)
+				}
+				if cfg.DigestTo != tt.want.DigestTo || cfg.DigestFrom != tt.want.DigestFrom {
+					t.Errorf("DigestTo, DigestFrom = %q, %q, want %q, %q", cfg.DigestTo, cfg.DigestFrom, tt.want.DigestTo, tt.want.DigestFrom)
+				}
+				if cfg.WebhookURL != tt.want.WebhookURL {
+					t.Errorf("WebhookURL = %q, want %q", cfg.WebhookURL, tt.want.WebhookURL)
+				}
+				if tt.want.WebhookEvents != nil && !reflect.DeepEqual(cfg.WebhookEvents, tt.want.WebhookEvents) {
+					t.Errorf("WebhookEvents = %v, want %v", cfg.WebhookEvents, tt.want.WebhookEvents)
+				}
+				if cfg.DigestSubject != tt.want.DigestSubject {
+					t.Errorf("DigestSubject = %q, want %q", cfg.DigestSubject, tt.want.DigestSubject)
+				}
 			}
 		})
 	}
@@ -329,6 +863,15 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "standard format is valid",
+			config: &Config{
+				DefaultPriority: "medium",
+				DefaultFormat:   "standard",
+				PageSize:        10,
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty format is valid",
 			config: &Config{
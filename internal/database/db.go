@@ -1,4 +1,5 @@
-// Package database provides the SQLite database layer for claude-gtd
+// Package database provides the database layer for claude-gtd: SQLite
+// files, or a database on a Postgres server shared by several clones
 package database
 
 import (
@@ -6,19 +7,26 @@ import (
 	"fmt"
 	"os"
 	"strings"
-
-	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"time"
 )
 
 // Database wraps the SQL database connection
 type Database struct {
 	DB *sql.DB
+
+	path       string
+	encryption *encryption // Set when the database is stored encrypted
+	postgres   bool        // Set when the database is on a Postgres server
 }
 
 // New creates a new database connection
 func New(dbPath string) (*Database, error) {
-	// Open database with foreign key support
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	if encrypted, err := IsEncrypted(dbPath); err == nil && encrypted {
+		return nil, fmt.Errorf("database %s is encrypted: set GTD_DB_KEY or GTD_DB_KEYCHAIN to open it", dbPath)
+	}
+
+	// Open database with the driver selected at build time
+	db, err := openDB(dataSourceName(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -39,21 +47,175 @@ func New(dbPath string) (*Database, error) {
 		return nil, fmt.Errorf("failed to set WAL mode: %w", err)
 	}
 
-	return &Database{DB: db}, nil
+	return &Database{DB: db, path: dbPath}, nil
+}
+
+// dataSource holds the settings a database file is opened with. Each driver
+// spells them differently, so formatDSN translates them into the connection
+// string of the driver gtd was built with.
+type dataSource struct {
+	path        string
+	readOnly    bool          // Open the file without writing to it
+	foreignKeys bool          // Enforce foreign key constraints
+	busyTimeout time.Duration // How long to wait for another connection's lock
+	immediate   bool          // Take the write lock when a transaction begins
+}
+
+// dataSourceName returns the connection string for a database file. Foreign
+// keys are enforced, and transactions take the write lock when they begin,
+// so that a concurrent writer makes Begin wait rather than failing a
+// statement halfway through.
+func dataSourceName(dbPath string) string {
+	return formatDSN(dataSource{path: dbPath, foreignKeys: true, busyTimeout: busyTimeout, immediate: true})
+}
+
+// readOnlyDataSourceName returns the connection string for reading a
+// database file without writing to it
+func readOnlyDataSourceName(dbPath string) string {
+	return formatDSN(dataSource{path: dbPath, readOnly: true, busyTimeout: busyTimeout})
+}
+
+// OpenReadOnly opens an existing database file for reading, such as a
+// snapshot to compare with. Nothing is written to the file: its schema is
+// neither created nor migrated, so a database with pending migrations is
+// refused.
+func OpenReadOnly(dbPath string) (*Database, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if encrypted, err := IsEncrypted(dbPath); err == nil && encrypted {
+		return nil, fmt.Errorf("database %s is encrypted", dbPath)
+	}
+
+	db, err := openDB(readOnlyDataSourceName(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	d := &Database{DB: db, path: dbPath}
+
+	hasTasks, err := d.hasTable("tasks")
+	if err == nil && !hasTasks {
+		err = fmt.Errorf("%s is not a task database", dbPath)
+	}
+	if err == nil {
+		var pending []Migration
+		if pending, err = d.PendingMigrations(); err == nil && len(pending) > 0 {
+			err = fmt.Errorf("database %s has an older schema with %d pending migrations: migrate a copy with 'gtd --db COPY migrate' first", dbPath, len(pending))
+		}
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return d, nil
 }
 
-// Close closes the database connection
+// Close closes the database connection. An encrypted database is encrypted
+// back to its file if it changed, and unlocked.
 func (d *Database) Close() error {
-	return d.DB.Close()
+	if d.encryption == nil {
+		return d.DB.Close()
+	}
+	// Fold the write-ahead log into the database file before encrypting it.
+	// Closing the last connection does too, so the database is still sealed
+	// when the checkpoint fails.
+	_, checkpointErr := d.DB.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	closeErr := d.DB.Close()
+	if err := d.encryption.seal(d.path); err != nil {
+		return err
+	}
+	if checkpointErr != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", checkpointErr)
+	}
+	return closeErr
 }
 
-// Begin starts a new transaction
+// Encrypted reports whether the database is stored encrypted
+func (d *Database) Encrypted() bool {
+	return d.encryption != nil
+}
+
+// Begin starts a new write transaction, retrying while the database is
+// locked by another process
 func (d *Database) Begin() (*sql.Tx, error) {
-	return d.DB.Begin()
+	var tx *sql.Tx
+	err := Retry(func() (err error) {
+		tx, err = d.DB.Begin()
+		return err
+	})
+	return tx, err
+}
+
+// updateTimestampTrigger keeps tasks.updated current, in the same RFC3339 UTC
+// layout as FormatTime. Reordering tasks by rank does not count as an update.
+const updateTimestampTrigger = `
+	CREATE TRIGGER IF NOT EXISTS update_task_timestamp
+	AFTER UPDATE ON tasks
+	WHEN NEW.rank IS OLD.rank
+	BEGIN
+		UPDATE tasks SET updated = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE id = NEW.id;
+	END;
+	`
+
+// rankColumns defines the positions of states and priorities in the list
+// order: in progress before new before other tasks, and high before medium
+// before low priority. They are computed when read, so an index can cover
+// the list order instead of sorting by expression every time.
+var rankColumns = []string{
+	`state_rank INTEGER GENERATED ALWAYS AS (
+		CASE state WHEN 'IN_PROGRESS' THEN 0 WHEN 'NEW' THEN 1 ELSE 2 END
+	) VIRTUAL`,
+	`priority_rank INTEGER GENERATED ALWAYS AS (
+		CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 WHEN 'low' THEN 2 END
+	) VIRTUAL`,
+}
+
+// listOrderIndex covers the order tasks are listed in, so listing the first
+// tasks reads them in order instead of sorting the whole table
+const listOrderIndex = `
+	CREATE INDEX IF NOT EXISTS idx_list_order
+	ON tasks(pinned DESC, state_rank, priority_rank, rank IS NULL, rank, created DESC)`
+
+// lastSeqTrigger keeps the highest task number ever assigned in the
+// settings, so numbers of deleted tasks aren't given out again
+const lastSeqTrigger = `
+	CREATE TRIGGER IF NOT EXISTS record_last_seq
+	AFTER INSERT ON tasks
+	WHEN NEW.seq IS NOT NULL
+	BEGIN
+		INSERT INTO settings (key, value) VALUES ('last_seq', NEW.seq)
+		ON CONFLICT(key) DO UPDATE SET value = MAX(CAST(value AS INTEGER), CAST(excluded.value AS INTEGER));
+	END`
+
+// timestampColumns lists every stored timestamp as table and column
+var timestampColumns = [][2]string{
+	{"tasks", "created"},
+	{"tasks", "updated"},
+	{"task_history", "created"},
+	{"task_attachments", "created"},
+	{"task_links", "created"},
+	{"imported_comments", "created"},
+	{"deleted_tasks", "deleted"},
 }
 
-// CreateSchema creates the database schema
+// CreateSchema creates the database schema, migrating existing databases
 func (d *Database) CreateSchema() error {
+	_, err := d.Migrate()
+	return err
+}
+
+// Migrate creates any missing tables and applies the pending migrations,
+// returning them
+func (d *Database) Migrate() ([]Migration, error) {
+	if d.postgres {
+		return nil, d.createPostgresSchema()
+	}
+
+	existed, err := d.hasTable("tasks")
+	if err != nil {
+		return nil, err
+	}
+
 	schema := `
 	CREATE TABLE IF NOT EXISTS tasks (
 		id TEXT PRIMARY KEY,
@@ -64,11 +226,19 @@ func (d *Database) CreateSchema() error {
 		title TEXT NOT NULL,
 		description TEXT,
 		author TEXT NOT NULL,
-		created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+		updated TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
 		source TEXT,
 		blocked_by TEXT REFERENCES tasks(id),
-		tags TEXT
+		tags TEXT,
+		seq INTEGER,
+		external_ref TEXT,
+		rank INTEGER,
+		pinned INTEGER NOT NULL DEFAULT 0,
+		severity TEXT CHECK(severity IN ('critical', 'major', 'minor')),
+		introduced_in TEXT,
+		environment TEXT,
+		` + strings.Join(rankColumns, ",\n\t\t") + `
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_state_priority ON tasks(state, priority);
@@ -80,31 +250,235 @@ func (d *Database) CreateSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_updated ON tasks(updated);
 	CREATE INDEX IF NOT EXISTS idx_tags ON tasks(tags) WHERE tags IS NOT NULL;
 
+	-- Per-workspace key/value state (e.g. the focused task)
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	-- Change history: who did what to each task and when
+	CREATE TABLE IF NOT EXISTS task_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		author TEXT NOT NULL,
+		action TEXT NOT NULL,
+		field TEXT,
+		old_value TEXT,
+		new_value TEXT,
+		created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_history_task ON task_history(task_id);
+	CREATE INDEX IF NOT EXISTS idx_history_created ON task_history(created);
+
+	-- Files and URLs associated with tasks
+	CREATE TABLE IF NOT EXISTS task_attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		location TEXT NOT NULL,
+		author TEXT NOT NULL,
+		created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+		UNIQUE(task_id, location)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attachments_task ON task_attachments(task_id);
+
+	-- Typed relationships between tasks beyond parent and blocked-by
+	CREATE TABLE IF NOT EXISTS task_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		target_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		type TEXT CHECK(type IN ('relates', 'duplicates', 'causes', 'regresses')) NOT NULL,
+		author TEXT NOT NULL,
+		created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+		UNIQUE(source_id, target_id, type)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_links_source ON task_links(source_id);
+	CREATE INDEX IF NOT EXISTS idx_links_target ON task_links(target_id);
+
+	-- Fingerprints of code comments imported by scan, to skip them next time
+	CREATE TABLE IF NOT EXISTS imported_comments (
+		fingerprint TEXT PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+	);
+
+	-- Intervals of time spent working on tasks, e.g. pomodoros
+	CREATE TABLE IF NOT EXISTS task_worklog (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		author TEXT NOT NULL,
+		started TIMESTAMP NOT NULL,
+		ended TIMESTAMP NOT NULL,
+		note TEXT
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_worklog_task ON task_worklog(task_id);
+	CREATE INDEX IF NOT EXISTS idx_worklog_started ON task_worklog(started);
+
+	-- Custom fields: domain-specific metadata such as the customer
+	CREATE TABLE IF NOT EXISTS task_fields (
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY(task_id, name)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_fields_name ON task_fields(name, value);
+
+	-- Tombstones for deleted tasks, used by incremental exports
+	CREATE TABLE IF NOT EXISTS deleted_tasks (
+		id TEXT PRIMARY KEY,
+		deleted TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+	);
+
 	-- Trigger to update the updated timestamp
-	CREATE TRIGGER IF NOT EXISTS update_task_timestamp 
-	AFTER UPDATE ON tasks
-	BEGIN
-		UPDATE tasks SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
-	END;
-	`
+	` + updateTimestampTrigger
+
+	if _, err := d.DB.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	// A new database starts out with the current schema
+	if !existed {
+		if _, err := d.DB.Exec(listOrderIndex); err != nil {
+			return nil, fmt.Errorf("failed to create index: %w", err)
+		}
+		if _, err := d.DB.Exec(lastSeqTrigger); err != nil {
+			return nil, fmt.Errorf("failed to create trigger: %w", err)
+		}
+		return nil, d.setSchemaVersion(LatestSchemaVersion())
+	}
+
+	applied, err := d.runMigrations()
+	if err != nil {
+		return applied, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// Migration is a versioned change to the schema of existing databases
+type Migration struct {
+	Version     int
+	Description string
+	apply       func(d *Database) error
+}
+
+// migrations lists every migration in the order it is applied. The version
+// of the last applied migration is kept in the database's user_version;
+// databases from before versioning start at 0 and, as every migration checks
+// whether it is still needed, safely run them all once. New migrations are
+// appended with the next version.
+var migrations = []Migration{
+	{1, "Add the INBOX and INVALID states", (*Database).migrateStates},
+	// Ranks go before the timestamp trigger that refers to them is recreated
+	{2, "Add manual task ranks", (*Database).migrateRankColumn},
+	// Timestamps go before later migrations touch tasks
+	{3, "Store timestamps as RFC3339 UTC", (*Database).migrateTimestamps},
+	{4, "Keep update times when tasks are reranked", (*Database).migrateRankTrigger},
+	{5, "Add performance indexes", (*Database).migrateIndexes},
+	{6, "Add sequential task numbers", (*Database).migrateSeq},
+	{7, "Add external references", (*Database).migrateExternalRef},
+	{8, "Add pinned tasks", (*Database).migratePinned},
+	{9, "Add an index for the list order", (*Database).migrateListOrder},
+	{10, "Add bug severities", (*Database).migrateSeverity},
+	{11, "Add regression releases and environments", (*Database).migrateRegressionOrigin},
+	{12, "Add regression links", (*Database).migrateRegressionLinks},
+	{13, "Never reuse the numbers of deleted tasks", (*Database).migrateLastSeq},
+}
+
+// LatestSchemaVersion returns the schema version this build of gtd migrates
+// databases to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
 
-	_, err := d.DB.Exec(schema)
+// SQLiteVersion returns the version of the SQLite library the driver uses
+func SQLiteVersion() (string, error) {
+	db, err := sql.Open(driverName, ":memory:")
 	if err != nil {
-		return fmt.Errorf("failed to create schema: %w", err)
+		return "", err
 	}
+	defer func() { _ = db.Close() }()
 
-	// Run migrations
-	if err := d.runMigrations(); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	var version string
+	if err := db.QueryRow("SELECT sqlite_version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to read SQLite version: %w", err)
 	}
+	return version, nil
+}
 
+// SchemaVersion returns the version of the last migration applied to the
+// database
+func (d *Database) SchemaVersion() (int, error) {
+	if d.postgres {
+		return d.postgresSchemaVersion()
+	}
+
+	var version int
+	if err := d.DB.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// PendingMigrations returns the migrations CreateSchema would apply. A new,
+// empty database gets the current schema and needs none, as does a Postgres
+// database.
+func (d *Database) PendingMigrations() ([]Migration, error) {
+	if d.postgres {
+		return nil, nil
+	}
+
+	exists, err := d.hasTable("tasks")
+	if err != nil || !exists {
+		return nil, err
+	}
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// runMigrations applies the pending migrations in order, recording each
+// one's version once it succeeded, and returns them
+func (d *Database) runMigrations() ([]Migration, error) {
+	pending, err := d.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range pending {
+		if err := m.apply(d); err != nil {
+			return pending[:i], fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		if err := d.setSchemaVersion(m.Version); err != nil {
+			return pending[:i], err
+		}
+	}
+	return pending, nil
+}
+
+// setSchemaVersion records the version of the last applied migration
+func (d *Database) setSchemaVersion(version int) error {
+	// PRAGMA statements don't take parameters
+	if _, err := d.DB.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 	return nil
 }
 
-// runMigrations runs database migrations to update schema
-func (d *Database) runMigrations() error {
-	// Check if we need to add INBOX and INVALID states
-	// First, check if the constraint exists with the old states
+// migrateStates recreates the tasks table of databases from before the INBOX
+// and INVALID states, whose CHECK constraint rejects them
+func (d *Database) migrateStates() error {
 	var constraintSQL string
 	err := d.DB.QueryRow(`
 		SELECT sql FROM sqlite_master 
@@ -203,7 +577,26 @@ func (d *Database) runMigrations() error {
 		}
 	}
 
-	// Add new performance indices if they don't exist
+	return nil
+}
+
+// migrateRankColumn adds manual ranking within a priority
+func (d *Database) migrateRankColumn() error {
+	hasRank, err := d.hasColumn("tasks", "rank")
+	if err != nil {
+		return err
+	}
+	if !hasRank {
+		if _, err := d.DB.Exec(`ALTER TABLE tasks ADD COLUMN rank INTEGER`); err != nil {
+			return fmt.Errorf("failed to add rank column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateIndexes adds the performance indexes missing from older databases
+func (d *Database) migrateIndexes() error {
 	newIndices := []string{
 		"CREATE INDEX IF NOT EXISTS idx_kind_state ON tasks(kind, state)",
 		"CREATE INDEX IF NOT EXISTS idx_blocked_by ON tasks(blocked_by) WHERE blocked_by IS NOT NULL",
@@ -220,3 +613,327 @@ func (d *Database) runMigrations() error {
 
 	return nil
 }
+
+// migrateSeq adds sequential short IDs, numbering existing tasks in creation
+// order. Numbering a task doesn't count as updating it.
+func (d *Database) migrateSeq() (err error) {
+	hasSeq, err := d.hasColumn("tasks", "seq")
+	if err != nil {
+		return err
+	}
+	if !hasSeq {
+		if _, err := d.DB.Exec(`ALTER TABLE tasks ADD COLUMN seq INTEGER`); err != nil {
+			return fmt.Errorf("failed to add seq column: %w", err)
+		}
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin numbering tasks: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback migration: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	// Drop the timestamp trigger while numbering, so the tasks keep their
+	// update times, and recreate it as it was
+	var triggerSQL sql.NullString
+	err = tx.QueryRow(`
+		SELECT sql FROM sqlite_master WHERE type='trigger' AND name='update_task_timestamp'
+	`).Scan(&triggerSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to inspect timestamp trigger: %w", err)
+	}
+	if triggerSQL.Valid {
+		if _, err = tx.Exec(`DROP TRIGGER update_task_timestamp`); err != nil {
+			return fmt.Errorf("failed to drop timestamp trigger: %w", err)
+		}
+	}
+	if _, err = tx.Exec(`
+		UPDATE tasks SET seq = (
+			SELECT COUNT(*) FROM tasks t
+			WHERE t.created < tasks.created
+			   OR (t.created = tasks.created AND t.rowid <= tasks.rowid)
+		)
+		WHERE seq IS NULL AND NOT EXISTS (SELECT 1 FROM tasks WHERE seq IS NOT NULL)
+	`); err != nil {
+		return fmt.Errorf("failed to number existing tasks: %w", err)
+	}
+	if triggerSQL.Valid {
+		if _, err = tx.Exec(triggerSQL.String); err != nil {
+			return fmt.Errorf("failed to recreate timestamp trigger: %w", err)
+		}
+	}
+	if _, err = tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_seq ON tasks(seq) WHERE seq IS NOT NULL"); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit task numbers: %w", err)
+	}
+	return nil
+}
+
+// migrateExternalRef adds external references (issue URLs, ticket keys)
+func (d *Database) migrateExternalRef() error {
+	hasRef, err := d.hasColumn("tasks", "external_ref")
+	if err != nil {
+		return err
+	}
+	if !hasRef {
+		if _, err := d.DB.Exec(`ALTER TABLE tasks ADD COLUMN external_ref TEXT`); err != nil {
+			return fmt.Errorf("failed to add external_ref column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migratePinned adds pinned tasks, listed before all others
+func (d *Database) migratePinned() error {
+	hasPinned, err := d.hasColumn("tasks", "pinned")
+	if err != nil {
+		return err
+	}
+	if !hasPinned {
+		if _, err := d.DB.Exec(`ALTER TABLE tasks ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("failed to add pinned column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateListOrder adds the state and priority ranks the list order is
+// indexed by
+func (d *Database) migrateListOrder() error {
+	hasRanks, err := d.hasColumn("tasks", "state_rank")
+	if err != nil {
+		return err
+	}
+	if !hasRanks {
+		for _, column := range rankColumns {
+			if _, err := d.DB.Exec("ALTER TABLE tasks ADD COLUMN " + column); err != nil {
+				return fmt.Errorf("failed to add rank columns: %w", err)
+			}
+		}
+	}
+	if _, err := d.DB.Exec(listOrderIndex); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return nil
+}
+
+// migrateSeverity adds the severity of bugs and regressions
+func (d *Database) migrateSeverity() error {
+	hasSeverity, err := d.hasColumn("tasks", "severity")
+	if err != nil {
+		return err
+	}
+	if !hasSeverity {
+		if _, err := d.DB.Exec(`ALTER TABLE tasks ADD COLUMN severity TEXT CHECK(severity IN ('critical', 'major', 'minor'))`); err != nil {
+			return fmt.Errorf("failed to add severity column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateRegressionOrigin adds the release that introduced a regression and
+// the environment it was seen in
+func (d *Database) migrateRegressionOrigin() error {
+	for _, column := range []string{"introduced_in", "environment"} {
+		hasColumn, err := d.hasColumn("tasks", column)
+		if err != nil {
+			return err
+		}
+		if !hasColumn {
+			if _, err := d.DB.Exec("ALTER TABLE tasks ADD COLUMN " + column + " TEXT"); err != nil {
+				return fmt.Errorf("failed to add %s column: %w", column, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateRegressionLinks allows links from a regression to the task it
+// regresses. SQLite can't alter a CHECK constraint, so the links table is
+// recreated.
+func (d *Database) migrateRegressionLinks() error {
+	var tableSQL string
+	if err := d.DB.QueryRow(`
+		SELECT sql FROM sqlite_master WHERE type='table' AND name='task_links'
+	`).Scan(&tableSQL); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect links table: %w", err)
+	}
+	if strings.Contains(tableSQL, "'regresses'") {
+		return nil
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback migration: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(`
+		CREATE TABLE task_links_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+			target_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+			type TEXT CHECK(type IN ('relates', 'duplicates', 'causes', 'regresses')) NOT NULL,
+			author TEXT NOT NULL,
+			created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+			UNIQUE(source_id, target_id, type)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create new links table: %w", err)
+	}
+	if _, err = tx.Exec(`
+		INSERT INTO task_links_new (id, source_id, target_id, type, author, created)
+		SELECT id, source_id, target_id, type, author, created FROM task_links
+	`); err != nil {
+		return fmt.Errorf("failed to copy links: %w", err)
+	}
+	if _, err = tx.Exec(`DROP TABLE task_links`); err != nil {
+		return fmt.Errorf("failed to drop old links table: %w", err)
+	}
+	if _, err = tx.Exec(`ALTER TABLE task_links_new RENAME TO task_links`); err != nil {
+		return fmt.Errorf("failed to rename links table: %w", err)
+	}
+	if _, err = tx.Exec(`
+		CREATE INDEX idx_links_source ON task_links(source_id);
+		CREATE INDEX idx_links_target ON task_links(target_id);
+	`); err != nil {
+		return fmt.Errorf("failed to recreate link indexes: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+	return nil
+}
+
+// migrateLastSeq records the highest task number, which the insert of a new
+// task counts from along with the numbers of existing tasks
+func (d *Database) migrateLastSeq() error {
+	if _, err := d.DB.Exec(lastSeqTrigger); err != nil {
+		return fmt.Errorf("failed to create trigger: %w", err)
+	}
+	return nil
+}
+
+// migrateTimestamps rewrites timestamps stored by older versions, which used
+// SQLite's CURRENT_TIMESTAMP layout, as RFC3339 UTC. Databases whose trigger
+// already uses the new layout have been migrated.
+func (d *Database) migrateTimestamps() error {
+	var triggerSQL string
+	if err := d.DB.QueryRow(`
+		SELECT sql FROM sqlite_master WHERE type='trigger' AND name='update_task_timestamp'
+	`).Scan(&triggerSQL); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect timestamp trigger: %w", err)
+	}
+	if !strings.Contains(triggerSQL, "CURRENT_TIMESTAMP") {
+		return nil
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin timestamp migration: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback migration: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	// Drop the trigger first so rewriting tasks does not touch updated
+	if _, err = tx.Exec(`DROP TRIGGER update_task_timestamp`); err != nil {
+		return fmt.Errorf("failed to drop timestamp trigger: %w", err)
+	}
+	for _, tc := range timestampColumns {
+		table, column := tc[0], tc[1]
+		_, err = tx.Exec(fmt.Sprintf(`
+			UPDATE %[1]s SET %[2]s = strftime('%%Y-%%m-%%dT%%H:%%M:%%fZ', %[2]s)
+			WHERE %[2]s IS NOT NULL AND %[2]s NOT LIKE '%%Z'
+		`, table, column))
+		if err != nil {
+			return fmt.Errorf("failed to convert %s.%s timestamps: %w", table, column, err)
+		}
+	}
+	if _, err = tx.Exec(updateTimestampTrigger); err != nil {
+		return fmt.Errorf("failed to recreate timestamp trigger: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit timestamp migration: %w", err)
+	}
+	return nil
+}
+
+// migrateRankTrigger recreates timestamp triggers from before ranking, which
+// would mark every reordered task as updated
+func (d *Database) migrateRankTrigger() error {
+	var triggerSQL string
+	if err := d.DB.QueryRow(`
+		SELECT sql FROM sqlite_master WHERE type='trigger' AND name='update_task_timestamp'
+	`).Scan(&triggerSQL); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect timestamp trigger: %w", err)
+	}
+	if strings.Contains(triggerSQL, "NEW.rank") {
+		return nil
+	}
+
+	if _, err := d.DB.Exec(`DROP TRIGGER update_task_timestamp`); err != nil {
+		return fmt.Errorf("failed to drop timestamp trigger: %w", err)
+	}
+	if _, err := d.DB.Exec(updateTimestampTrigger); err != nil {
+		return fmt.Errorf("failed to recreate timestamp trigger: %w", err)
+	}
+	return nil
+}
+
+// hasTable reports whether the database has the named table
+func (d *Database) hasTable(table string) (bool, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect tables: %w", err)
+	}
+	return count > 0, nil
+}
+
+// hasColumn reports whether the given table has the named column, including
+// generated columns
+func (d *Database) hasColumn(table, column string) (bool, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT COUNT(*) FROM pragma_table_xinfo(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s columns: %w", table, err)
+	}
+	return count > 0, nil
+}
@@ -104,6 +104,53 @@ func TestRunMigrations(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "migration assigns sequential IDs in creation order",
+			setupFunc: func(db *sql.DB) error {
+				// Create table as it existed before sequential IDs
+				_, err := db.Exec(`
+					CREATE TABLE tasks (
+						id TEXT PRIMARY KEY,
+						parent TEXT REFERENCES tasks(id),
+						priority TEXT CHECK(priority IN ('high', 'medium', 'low')) DEFAULT 'medium',
+						state TEXT CHECK(state IN ('INBOX', 'NEW', 'IN_PROGRESS', 'DONE', 'CANCELLED', 'INVALID')) DEFAULT 'INBOX',
+						kind TEXT CHECK(kind IN ('BUG', 'FEATURE', 'REGRESSION')) NOT NULL,
+						title TEXT NOT NULL,
+						description TEXT,
+						author TEXT NOT NULL DEFAULT 'Test User <test@example.com>',
+						created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						source TEXT,
+						blocked_by TEXT REFERENCES tasks(id),
+						tags TEXT
+					)
+				`)
+				if err != nil {
+					return err
+				}
+
+				_, err = db.Exec(`
+					INSERT INTO tasks (id, kind, title, description, created)
+					VALUES
+					('newer', 'BUG', 'Newer', 'Description', '2024-02-01 00:00:00'),
+					('older', 'BUG', 'Older', 'Description', '2024-01-01 00:00:00')
+				`)
+				return err
+			},
+			wantErr: false,
+			verify: func(db *sql.DB) error {
+				for id, want := range map[string]int{"older": 1, "newer": 2} {
+					var seq int
+					if err := db.QueryRow("SELECT seq FROM tasks WHERE id = ?", id).Scan(&seq); err != nil {
+						return err
+					}
+					if seq != want {
+						return fmt.Errorf("task %s has seq %d, want %d", id, seq, want)
+					}
+				}
+				return nil
+			},
+		},
 		{
 			name: "migration with parent-child relationships",
 			setupFunc: func(db *sql.DB) error {
@@ -212,6 +259,228 @@ func TestRunMigrations(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "timestamps converted to RFC3339 UTC",
+			setupFunc: func(db *sql.DB) error {
+				// Create a table and trigger as written by older versions
+				_, err := db.Exec(`
+					CREATE TABLE tasks (
+						id TEXT PRIMARY KEY,
+						parent TEXT REFERENCES tasks(id),
+						priority TEXT CHECK(priority IN ('high', 'medium', 'low')) DEFAULT 'medium',
+						state TEXT CHECK(state IN ('INBOX', 'NEW', 'IN_PROGRESS', 'DONE', 'CANCELLED', 'INVALID')) DEFAULT 'INBOX',
+						kind TEXT CHECK(kind IN ('BUG', 'FEATURE', 'REGRESSION')) NOT NULL,
+						title TEXT NOT NULL,
+						description TEXT,
+						author TEXT NOT NULL DEFAULT 'Test User <test@example.com>',
+						created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						source TEXT,
+						blocked_by TEXT REFERENCES tasks(id),
+						tags TEXT
+					);
+
+					CREATE TRIGGER update_task_timestamp
+					AFTER UPDATE ON tasks
+					BEGIN
+						UPDATE tasks SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+					END;
+
+					INSERT INTO tasks (id, kind, title, description, created, updated)
+					VALUES
+					('utc', 'BUG', 'UTC', 'Description', '2024-03-10 12:00:00', '2024-03-10 12:00:00'),
+					('offset', 'BUG', 'Offset', 'Description',
+					 '2024-03-10 14:30:00.123456789+02:00', '2024-03-10 14:30:00.123456789+02:00');
+				`)
+				return err
+			},
+			wantErr: false,
+			verify: func(db *sql.DB) error {
+				want := map[string]string{
+					"utc":    "2024-03-10T12:00:00.000Z",
+					"offset": "2024-03-10T12:30:00.123Z",
+				}
+				for id, wantCreated := range want {
+					var created string
+					if err := db.QueryRow("SELECT CAST(created AS TEXT) FROM tasks WHERE id = ?", id).Scan(&created); err != nil {
+						return err
+					}
+					if created != wantCreated {
+						return fmt.Errorf("task %s created = %s, want %s", id, created, wantCreated)
+					}
+				}
+
+				// The trigger writes the new layout
+				if _, err := db.Exec("UPDATE tasks SET title = 'Changed' WHERE id = 'utc'"); err != nil {
+					return err
+				}
+				var updated string
+				if err := db.QueryRow("SELECT CAST(updated AS TEXT) FROM tasks WHERE id = 'utc'").Scan(&updated); err != nil {
+					return err
+				}
+				if !strings.HasSuffix(updated, "Z") || !strings.Contains(updated, "T") {
+					return fmt.Errorf("trigger wrote %s, want RFC3339 UTC", updated)
+				}
+				return nil
+			},
+		},
+		{
+			name: "numbering tasks keeps update times",
+			setupFunc: func(db *sql.DB) error {
+				// Create the table and trigger as they were at schema version 5
+				_, err := db.Exec(`
+					CREATE TABLE tasks (
+						id TEXT PRIMARY KEY,
+						parent TEXT REFERENCES tasks(id),
+						priority TEXT CHECK(priority IN ('high', 'medium', 'low')) DEFAULT 'medium',
+						state TEXT CHECK(state IN ('INBOX', 'NEW', 'IN_PROGRESS', 'DONE', 'CANCELLED', 'INVALID')) DEFAULT 'INBOX',
+						kind TEXT CHECK(kind IN ('BUG', 'FEATURE', 'REGRESSION')) NOT NULL,
+						title TEXT NOT NULL,
+						description TEXT,
+						author TEXT NOT NULL DEFAULT 'Test User <test@example.com>',
+						created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+						updated TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+						source TEXT,
+						blocked_by TEXT REFERENCES tasks(id),
+						tags TEXT,
+						rank INTEGER
+					);
+					` + updateTimestampTrigger + `
+
+					INSERT INTO tasks (id, kind, title, created, updated)
+					VALUES
+					('first', 'BUG', 'First', '2024-01-01T09:00:00.000Z', '2024-02-01T10:00:00.000Z'),
+					('second', 'BUG', 'Second', '2024-01-02T09:00:00.000Z', '2024-03-01T10:00:00.000Z');
+
+					PRAGMA user_version = 5;
+				`)
+				return err
+			},
+			wantErr: false,
+			verify: func(db *sql.DB) error {
+				want := map[string]struct {
+					seq     int
+					updated string
+				}{
+					"first":  {1, "2024-02-01T10:00:00.000Z"},
+					"second": {2, "2024-03-01T10:00:00.000Z"},
+				}
+				for id, w := range want {
+					var seq int
+					var updated string
+					if err := db.QueryRow("SELECT seq, CAST(updated AS TEXT) FROM tasks WHERE id = ?", id).Scan(&seq, &updated); err != nil {
+						return err
+					}
+					if seq != w.seq || updated != w.updated {
+						return fmt.Errorf("task %s has seq %d, updated %s, want %d, %s", id, seq, updated, w.seq, w.updated)
+					}
+				}
+
+				// The trigger still marks edited tasks as updated
+				if _, err := db.Exec("UPDATE tasks SET title = 'Edited' WHERE id = 'first'"); err != nil {
+					return err
+				}
+				var updated string
+				if err := db.QueryRow("SELECT CAST(updated AS TEXT) FROM tasks WHERE id = 'first'").Scan(&updated); err != nil {
+					return err
+				}
+				if updated == want["first"].updated {
+					return fmt.Errorf("timestamp trigger not recreated")
+				}
+				return nil
+			},
+		},
+		{
+			name: "list order ranks and index added",
+			setupFunc: func(db *sql.DB) error {
+				// Create the table as it was at schema version 8
+				_, err := db.Exec(`
+					CREATE TABLE tasks (
+						id TEXT PRIMARY KEY,
+						parent TEXT REFERENCES tasks(id),
+						priority TEXT CHECK(priority IN ('high', 'medium', 'low')) DEFAULT 'medium',
+						state TEXT CHECK(state IN ('INBOX', 'NEW', 'IN_PROGRESS', 'DONE', 'CANCELLED', 'INVALID')) DEFAULT 'INBOX',
+						kind TEXT CHECK(kind IN ('BUG', 'FEATURE', 'REGRESSION')) NOT NULL,
+						title TEXT NOT NULL,
+						description TEXT,
+						author TEXT NOT NULL DEFAULT 'Test User <test@example.com>',
+						created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+						updated TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+						source TEXT,
+						blocked_by TEXT REFERENCES tasks(id),
+						tags TEXT,
+						seq INTEGER,
+						external_ref TEXT,
+						rank INTEGER,
+						pinned INTEGER NOT NULL DEFAULT 0
+					);
+
+					INSERT INTO tasks (id, kind, title, state, priority)
+					VALUES
+					('working', 'BUG', 'Working', 'IN_PROGRESS', 'low'),
+					('next', 'BUG', 'Next', 'NEW', 'high'),
+					('done', 'BUG', 'Done', 'DONE', 'medium');
+
+					CREATE TABLE task_links (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						source_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+						target_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+						type TEXT CHECK(type IN ('relates', 'duplicates', 'causes')) NOT NULL,
+						author TEXT NOT NULL,
+						created TIMESTAMP DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+						UNIQUE(source_id, target_id, type)
+					);
+
+					INSERT INTO task_links (source_id, target_id, type, author)
+					VALUES ('next', 'working', 'causes', 'Test User <test@example.com>');
+
+					PRAGMA user_version = 8;
+				`)
+				return err
+			},
+			wantErr: false,
+			verify: func(db *sql.DB) error {
+				want := map[string][2]int{"working": {0, 2}, "next": {1, 0}, "done": {2, 1}}
+				for id, ranks := range want {
+					var stateRank, priorityRank int
+					if err := db.QueryRow("SELECT state_rank, priority_rank FROM tasks WHERE id = ?", id).Scan(&stateRank, &priorityRank); err != nil {
+						return err
+					}
+					if stateRank != ranks[0] || priorityRank != ranks[1] {
+						return fmt.Errorf("task %s has ranks %d, %d, want %v", id, stateRank, priorityRank, ranks)
+					}
+				}
+
+				var count int
+				if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_list_order'").Scan(&count); err != nil {
+					return err
+				}
+				if count != 1 {
+					return fmt.Errorf("idx_list_order not created")
+				}
+
+				// Later migrations ran too
+				if _, err := db.Exec("UPDATE tasks SET severity = 'critical' WHERE id = 'next'"); err != nil {
+					return fmt.Errorf("severity not added: %w", err)
+				}
+				if _, err := db.Exec("UPDATE tasks SET severity = 'urgent' WHERE id = 'next'"); err == nil {
+					return fmt.Errorf("severity accepted an invalid value")
+				}
+				if _, err := db.Exec("UPDATE tasks SET introduced_in = 'v2.1.0', environment = 'prod' WHERE id = 'next'"); err != nil {
+					return fmt.Errorf("regression origin not added: %w", err)
+				}
+				if err := db.QueryRow("SELECT COUNT(*) FROM task_links WHERE type = 'causes'").Scan(&count); err != nil {
+					return err
+				}
+				if count != 1 {
+					return fmt.Errorf("links not kept when the links table was recreated")
+				}
+				if _, err := db.Exec("INSERT INTO task_links (source_id, target_id, type, author) VALUES ('next', 'done', 'regresses', 'Test')"); err != nil {
+					return fmt.Errorf("regresses links not allowed: %w", err)
+				}
+				return nil
+			},
+		},
 	}
 
 	for _, tt := range tests {
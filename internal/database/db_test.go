@@ -130,6 +130,7 @@ func TestDatabase_CreateSchema(t *testing.T) {
 	expectedIndexes := []string{
 		"idx_state_priority",
 		"idx_parent",
+		"idx_list_order",
 	}
 
 	for _, idx := range expectedIndexes {
@@ -311,3 +312,47 @@ func TestDatabase_ForeignKeys(t *testing.T) {
 		t.Error("Expected foreign key constraint error")
 	}
 }
+
+// TestDataSourceSettings checks the connection settings through SQLite
+// itself, so it holds for whichever driver gtd is built with
+func TestDataSourceSettings(t *testing.T) {
+	// Characters that would end the path in an unescaped URI
+	dbPath := filepath.Join(t.TempDir(), "tasks #1 ?.db")
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	var foreignKeys, timeout int
+	if err := db.DB.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DB.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatal(err)
+	}
+	if foreignKeys != 1 || timeout != int(busyTimeout.Milliseconds()) {
+		t.Errorf("foreign_keys = %d, busy_timeout = %d, want 1 and %d", foreignKeys, timeout, busyTimeout.Milliseconds())
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("database not created at its path: %v", err)
+	}
+
+	readOnly, err := OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := readOnly.Close(); err != nil {
+			t.Errorf("failed to close database: %v", err)
+		}
+	}()
+	if _, err := readOnly.DB.Exec("INSERT INTO tasks (kind, title) VALUES ('BUG', 'Written')"); err == nil {
+		t.Error("Expected a read-only database to refuse writes")
+	}
+}
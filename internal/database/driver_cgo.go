@@ -0,0 +1,46 @@
+//go:build !purego
+
+package database
+
+import (
+	stderrors "errors"
+	"net/url"
+	"strconv"
+
+	"github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+const (
+	// driverName is the database/sql driver SQLite databases are opened with
+	driverName = "sqlite3"
+
+	// Driver is the module path of the SQLite driver gtd was built with
+	Driver = "github.com/mattn/go-sqlite3"
+)
+
+// formatDSN spells out the connection settings in go-sqlite3's parameters.
+// Timestamps are returned in local time.
+func formatDSN(src dataSource) string {
+	params := url.Values{}
+	params.Set("_loc", "auto")
+	params.Set("_busy_timeout", strconv.FormatInt(src.busyTimeout.Milliseconds(), 10))
+	if src.readOnly {
+		params.Set("mode", "ro")
+	}
+	if src.foreignKeys {
+		params.Set("_foreign_keys", "on")
+	}
+	if src.immediate {
+		params.Set("_txlock", "immediate")
+	}
+	return "file:" + (&url.URL{Path: src.path}).EscapedPath() + "?" + params.Encode()
+}
+
+// isBusy reports whether err is SQLite failing to get a lock
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !stderrors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
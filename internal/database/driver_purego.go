@@ -0,0 +1,52 @@
+//go:build purego
+
+package database
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/url"
+
+	"modernc.org/sqlite" // Pure-Go SQLite driver
+	sqlitelib "modernc.org/sqlite/lib"
+)
+
+const (
+	// driverName is the database/sql driver SQLite databases are opened with
+	driverName = "sqlite"
+
+	// Driver is the module path of the SQLite driver gtd was built with
+	Driver = "modernc.org/sqlite"
+)
+
+// formatDSN spells out the connection settings in modernc.org/sqlite's
+// parameters, with go-sqlite3's default synchronous mode. Time values are
+// written in the same layout as the cgo build; there is no counterpart to its
+// _loc, so timestamps are returned in UTC and the models convert them to
+// local time.
+func formatDSN(src dataSource) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", src.busyTimeout.Milliseconds()))
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Set("_time_format", "sqlite")
+	if src.readOnly {
+		params.Set("mode", "ro")
+	}
+	if src.foreignKeys {
+		params.Add("_pragma", "foreign_keys(1)")
+	}
+	if src.immediate {
+		params.Set("_txlock", "immediate")
+	}
+	return "file:" + (&url.URL{Path: src.path}).EscapedPath() + "?" + params.Encode()
+}
+
+// isBusy reports whether err is SQLite failing to get a lock
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !stderrors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff // Primary result code
+	return code == sqlitelib.SQLITE_BUSY || code == sqlitelib.SQLITE_LOCKED
+}
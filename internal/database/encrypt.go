@@ -0,0 +1,287 @@
+package database
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// encryptedMagic starts every database file encrypted by gtd. It is followed
+// by the key derivation salt, the GCM nonce, and the sealed SQLite file.
+const encryptedMagic = "GTDCRYPT1\n"
+
+const (
+	saltSize = 16
+	// keyIterations is the PBKDF2 work factor. Every command derives the key
+	// once, so it stays low enough not to be noticed at the prompt.
+	keyIterations = 100_000
+)
+
+// ErrWrongKey is returned when an encrypted database can't be decrypted
+// with the given passphrase, or has been tampered with
+var ErrWrongKey = stderrors.New("wrong encryption key or corrupted database")
+
+// encryption holds what's needed to write a decrypted database back
+type encryption struct {
+	path     string   // Encrypted database file
+	plainDir string   // Private directory holding the decrypted copy
+	lock     *os.File // Held while the database is open
+	salt     []byte
+	key      []byte
+	digest   []byte // SHA-256 of the decrypted database when it was opened
+	encrypt  bool   // The file is plaintext and must be encrypted on close
+}
+
+// NewEncrypted opens a database stored at dbPath encrypted with a key derived
+// from passphrase. While it's open the database is decrypted into a private
+// temporary file, which Close encrypts back to dbPath if it changed, and
+// removes. A plaintext database at dbPath is encrypted the first time it's
+// closed.
+//
+// Only one process at a time can open an encrypted database, as each works on
+// its own decrypted copy; others fail with a BusyError until it's closed.
+func NewEncrypted(dbPath, passphrase string) (*Database, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("encryption key is empty")
+	}
+
+	lock, err := lockDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	enc := &encryption{path: dbPath, lock: lock}
+	db, err := enc.decrypt(passphrase)
+	if err != nil {
+		enc.release()
+		return nil, err
+	}
+	return db, nil
+}
+
+// decrypt opens the decrypted copy of the database
+func (e *encryption) decrypt(passphrase string) (*Database, error) {
+	data, err := os.ReadFile(e.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+
+	var plain []byte
+	if bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		header := data[len(encryptedMagic):]
+		if len(header) < saltSize {
+			return nil, ErrWrongKey
+		}
+		e.salt = header[:saltSize]
+		if e.key, err = deriveKey(passphrase, e.salt); err != nil {
+			return nil, err
+		}
+		if plain, err = e.open(header[saltSize:]); err != nil {
+			return nil, err
+		}
+	} else {
+		e.encrypt = true
+		e.salt = make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, e.salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		if e.key, err = deriveKey(passphrase, e.salt); err != nil {
+			return nil, err
+		}
+		if plain, err = checkpointed(e.path, data); err != nil {
+			return nil, err
+		}
+	}
+	digest := sha256.Sum256(plain)
+	e.digest = digest[:]
+
+	if e.plainDir, err = os.MkdirTemp("", "gtd-db-"); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	plainPath := filepath.Join(e.plainDir, filepath.Base(e.path))
+	if len(plain) > 0 {
+		if err := os.WriteFile(plainPath, plain, 0600); err != nil {
+			return nil, fmt.Errorf("failed to decrypt database: %w", err)
+		}
+	}
+
+	db, err := New(plainPath)
+	if err != nil {
+		return nil, err
+	}
+	db.encryption = e
+	return db, nil
+}
+
+// lockDatabase takes the lock file next to an encrypted database, retrying
+// with backoff like Retry while another process holds it
+func lockDatabase(dbPath string) (*os.File, error) {
+	file, err := os.OpenFile(dbPath+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock database: %w", err)
+	}
+
+	wait := retryBackoff
+	for attempt := 0; ; attempt++ {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock database: %w", err)
+		}
+		if locked {
+			return file, nil
+		}
+		if attempt == maxRetries {
+			_ = file.Close()
+			return nil, &errors.BusyError{Err: fmt.Errorf("encrypted database %s is open in another process", dbPath)}
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// release removes the decrypted copy and unlocks the database
+func (e *encryption) release() {
+	if e.plainDir != "" {
+		_ = os.RemoveAll(e.plainDir)
+	}
+	_ = e.lock.Close()
+}
+
+// checkpointed returns the contents of a plaintext database with its
+// write-ahead log folded in, which closing the database does
+func checkpointed(dbPath string, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	db, err := New(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	data, err = os.ReadFile(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+	return data, nil
+}
+
+// IsEncrypted reports whether the database file at path is encrypted. A
+// missing file is not.
+func IsEncrypted(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	magic := make([]byte, len(encryptedMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false, nil
+	}
+	return string(magic) == encryptedMagic, nil
+}
+
+// deriveKey derives an AES-256 key from a passphrase
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// aead returns the AES-GCM cipher for the key
+func (e *encryption) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// open decrypts a nonce followed by the sealed database
+func (e *encryption) open(sealed []byte) ([]byte, error) {
+	gcm, err := e.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(encryptedMagic))
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plain, nil
+}
+
+// seal encrypts the decrypted database back to its file, replacing it
+// atomically, unless it's unchanged. The decrypted copy is removed and the
+// database unlocked either way.
+func (e *encryption) seal(plainPath string) error {
+	defer e.release()
+
+	plain, err := os.ReadFile(plainPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read decrypted database: %w", err)
+	}
+	if digest := sha256.Sum256(plain); !e.encrypt && bytes.Equal(digest[:], e.digest) {
+		return nil
+	}
+
+	gcm, err := e.aead()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(encryptedMagic)
+	out.Write(e.salt)
+	out.Write(nonce)
+	out.Write(gcm.Seal(nil, nonce, plain, []byte(encryptedMagic)))
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), ".gtd-encrypt-*")
+	if err != nil {
+		return fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	if _, err := tmp.Write(out.Bytes()); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), e.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to encrypt database: %w", err)
+	}
+
+	// A plaintext database being encrypted may have left its journal behind
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(e.path + suffix)
+	}
+	return nil
+}
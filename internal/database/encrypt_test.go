@@ -0,0 +1,175 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gtderrors "github.com/zw3rk/gtd/internal/errors"
+)
+
+func TestNewEncrypted(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	const secret = "confidential task title"
+
+	db, err := NewEncrypted(dbPath, "passphrase")
+	if err != nil {
+		t.Fatalf("NewEncrypted() error = %v", err)
+	}
+	if _, err := db.DB.Exec("CREATE TABLE notes (body TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec("INSERT INTO notes (body) VALUES (?)", secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		t.Error("Expected the database file to be encrypted")
+	}
+	if bytes.Contains(data, []byte(secret)) {
+		t.Error("Encrypted database contains plaintext")
+	}
+	if encrypted, err := IsEncrypted(dbPath); err != nil || !encrypted {
+		t.Errorf("IsEncrypted() = %v, %v, want true", encrypted, err)
+	}
+
+	t.Run("reopens with the key", func(t *testing.T) {
+		db, err := NewEncrypted(dbPath, "passphrase")
+		if err != nil {
+			t.Fatalf("NewEncrypted() error = %v", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		var body string
+		if err := db.DB.QueryRow("SELECT body FROM notes").Scan(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body != secret {
+			t.Errorf("body = %q, want %q", body, secret)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		if _, err := NewEncrypted(dbPath, "guess"); !errors.Is(err, ErrWrongKey) {
+			t.Errorf("NewEncrypted() error = %v, want ErrWrongKey", err)
+		}
+	})
+
+	t.Run("without a key", func(t *testing.T) {
+		if _, err := New(dbPath); err == nil {
+			t.Error("Expected an error opening an encrypted database without a key")
+		}
+	})
+}
+
+func TestNewEncryptedConvertsPlaintext(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = NewEncrypted(dbPath, "passphrase")
+	if err != nil {
+		t.Fatalf("NewEncrypted() error = %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, _ := IsEncrypted(dbPath); !encrypted {
+		t.Fatal("Expected a plaintext database to be encrypted when closed")
+	}
+
+	db, err = NewEncrypted(dbPath, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	var count int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&count); err != nil {
+		t.Errorf("Expected the schema to survive encryption: %v", err)
+	}
+}
+
+func TestNewEncryptedSession(t *testing.T) {
+	oldBackoff := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = oldBackoff }()
+
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	db, err := NewEncrypted(dbPath, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process would work on a copy of its own
+	var busy *gtderrors.BusyError
+	if _, err := NewEncrypted(dbPath, "passphrase"); !errors.As(err, &busy) {
+		t.Errorf("NewEncrypted() while open error = %v, want BusyError", err)
+	}
+
+	plainDir := db.encryption.plainDir
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(plainDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the decrypted copy to be removed, got %v", err)
+	}
+	sealed, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reading leaves the file alone", func(t *testing.T) {
+		db, err := NewEncrypted(dbPath, "passphrase")
+		if err != nil {
+			t.Fatalf("NewEncrypted() after Close error = %v", err)
+		}
+		var count int
+		if err := db.DB.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(dbPath); !bytes.Equal(data, sealed) {
+			t.Error("Expected an unchanged database not to be encrypted again")
+		}
+	})
+
+	t.Run("writing encrypts again", func(t *testing.T) {
+		db, err := NewEncrypted(dbPath, "passphrase")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.DB.Exec("CREATE TABLE notes (body TEXT)"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(dbPath); bytes.Equal(data, sealed) {
+			t.Error("Expected a changed database to be encrypted again")
+		}
+	})
+}
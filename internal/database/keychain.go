@@ -0,0 +1,36 @@
+package database
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainService is the service name database keys are stored under
+const keychainService = "gtd"
+
+// KeychainKey looks up the encryption key of the database at dbPath in the
+// OS keychain: the login keychain on macOS, the Secret Service on Linux. Keys
+// are stored under the service "gtd" with the database path as account.
+func KeychainKey(dbPath string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", dbPath, "-w")
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", dbPath)
+	default:
+		return "", fmt.Errorf("no OS keychain support on %s", runtime.GOOS)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read encryption key for %s from keychain: %w", dbPath, err)
+	}
+	key := strings.TrimRight(string(out), "\r\n")
+	if key == "" {
+		return "", fmt.Errorf("no encryption key for %s in keychain", dbPath)
+	}
+	return key, nil
+}
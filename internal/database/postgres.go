@@ -0,0 +1,342 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq" // Postgres driver
+)
+
+// NewPostgres connects to a shared database on a Postgres server, given as
+// a postgres:// URL or a libpq connection string. The models' queries run
+// unchanged: their ? placeholders are numbered as Postgres expects, and the
+// schema keeps the tables and columns of SQLite databases.
+func NewPostgres(url string) (*Database, error) {
+	connector, err := pq.NewConnector(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	db := openConnector(&postgresConnector{Connector: connector})
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w (also failed to close: %v)", err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return &Database{DB: db, postgres: true}, nil
+}
+
+// Postgres reports whether the database is on a Postgres server rather than
+// in a SQLite file
+func (d *Database) Postgres() bool {
+	return d.postgres
+}
+
+// postgresSchema creates the tables of a new Postgres database. They match
+// the SQLite schema after every migration, with Postgres types: timestamps
+// are TIMESTAMPTZ, generated columns are stored, and triggers call functions.
+// Task numbers come from a sequence, so that clones adding tasks at the same
+// time never get the same number, and numbers are never reused.
+var postgresSchema = `
+	CREATE TABLE tasks (
+		id TEXT PRIMARY KEY,
+		parent TEXT REFERENCES tasks(id),
+		priority TEXT CHECK(priority IN ('high', 'medium', 'low')) DEFAULT 'medium',
+		state TEXT CHECK(state IN ('INBOX', 'NEW', 'IN_PROGRESS', 'DONE', 'CANCELLED', 'INVALID')) DEFAULT 'INBOX',
+		kind TEXT CHECK(kind IN ('BUG', 'FEATURE', 'REGRESSION')) NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT,
+		author TEXT NOT NULL,
+		created TIMESTAMPTZ DEFAULT now(),
+		updated TIMESTAMPTZ DEFAULT now(),
+		source TEXT,
+		blocked_by TEXT REFERENCES tasks(id),
+		tags TEXT,
+		seq INTEGER,
+		external_ref TEXT,
+		rank INTEGER,
+		pinned BOOLEAN NOT NULL DEFAULT FALSE,
+		severity TEXT CHECK(severity IN ('critical', 'major', 'minor')),
+		introduced_in TEXT,
+		environment TEXT,
+		` + strings.ReplaceAll(strings.Join(rankColumns, ",\n\t\t"), " VIRTUAL", " STORED") + `
+	);
+
+	CREATE INDEX idx_state_priority ON tasks(state, priority);
+	CREATE INDEX idx_parent ON tasks(parent);
+	CREATE INDEX idx_id_prefix ON tasks(id text_pattern_ops);
+	CREATE INDEX idx_kind_state ON tasks(kind, state);
+	CREATE INDEX idx_blocked_by ON tasks(blocked_by) WHERE blocked_by IS NOT NULL;
+	CREATE INDEX idx_created ON tasks(created);
+	CREATE INDEX idx_updated ON tasks(updated);
+	CREATE INDEX idx_tags ON tasks(tags) WHERE tags IS NOT NULL;
+	CREATE UNIQUE INDEX idx_seq ON tasks(seq) WHERE seq IS NOT NULL;
+	CREATE INDEX idx_list_order ON tasks(pinned DESC, state_rank, priority_rank, (rank IS NULL), rank, created DESC);
+
+	CREATE TABLE settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE task_history (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		author TEXT NOT NULL,
+		action TEXT NOT NULL,
+		field TEXT,
+		old_value TEXT,
+		new_value TEXT,
+		created TIMESTAMPTZ DEFAULT now()
+	);
+
+	CREATE INDEX idx_history_task ON task_history(task_id);
+	CREATE INDEX idx_history_created ON task_history(created);
+
+	CREATE TABLE task_attachments (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		location TEXT NOT NULL,
+		author TEXT NOT NULL,
+		created TIMESTAMPTZ DEFAULT now(),
+		UNIQUE(task_id, location)
+	);
+
+	CREATE INDEX idx_attachments_task ON task_attachments(task_id);
+
+	CREATE TABLE task_links (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		target_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		type TEXT CHECK(type IN ('relates', 'duplicates', 'causes', 'regresses')) NOT NULL,
+		author TEXT NOT NULL,
+		created TIMESTAMPTZ DEFAULT now(),
+		UNIQUE(source_id, target_id, type)
+	);
+
+	CREATE INDEX idx_links_source ON task_links(source_id);
+	CREATE INDEX idx_links_target ON task_links(target_id);
+
+	CREATE TABLE imported_comments (
+		fingerprint TEXT PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		created TIMESTAMPTZ DEFAULT now()
+	);
+
+	CREATE TABLE task_worklog (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		author TEXT NOT NULL,
+		started TIMESTAMPTZ NOT NULL,
+		ended TIMESTAMPTZ NOT NULL,
+		note TEXT
+	);
+
+	CREATE INDEX idx_worklog_task ON task_worklog(task_id);
+	CREATE INDEX idx_worklog_started ON task_worklog(started);
+
+	CREATE TABLE task_fields (
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY(task_id, name)
+	);
+
+	CREATE INDEX idx_fields_name ON task_fields(name, value);
+
+	CREATE TABLE deleted_tasks (
+		id TEXT PRIMARY KEY,
+		deleted TIMESTAMPTZ DEFAULT now()
+	);
+
+	CREATE SEQUENCE task_seq OWNED BY tasks.seq;
+
+	CREATE FUNCTION number_task() RETURNS trigger AS $$
+	BEGIN
+		NEW.seq := nextval('task_seq');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql;
+
+	CREATE TRIGGER number_task
+	BEFORE INSERT ON tasks
+	FOR EACH ROW EXECUTE FUNCTION number_task();
+
+	CREATE FUNCTION update_task_timestamp() RETURNS trigger AS $$
+	BEGIN
+		NEW.updated := now();
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql;
+
+	CREATE TRIGGER update_task_timestamp
+	BEFORE UPDATE ON tasks
+	FOR EACH ROW WHEN (NEW.rank IS NOT DISTINCT FROM OLD.rank)
+	EXECUTE FUNCTION update_task_timestamp();
+	`
+
+// schemaVersionSetting is the setting holding the schema version of a
+// Postgres database, which has no user_version
+const schemaVersionSetting = "schema_version"
+
+// createPostgresSchema creates the schema of a new Postgres database. The
+// migrations convert older SQLite files; a Postgres database starts out with
+// the current schema, so there are none to apply.
+func (d *Database) createPostgresSchema() (err error) {
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin schema transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback schema: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	// Clones starting at the same time create the schema one after another
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('gtd schema'))`); err != nil {
+		return fmt.Errorf("failed to lock schema: %w", err)
+	}
+	var exists bool
+	if err = tx.QueryRow(`SELECT to_regclass('tasks') IS NOT NULL`).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to inspect tables: %w", err)
+	}
+	if exists {
+		return tx.Commit()
+	}
+
+	if _, err = tx.Exec(postgresSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	if _, err = tx.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)`,
+		schemaVersionSetting, strconv.Itoa(LatestSchemaVersion())); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema: %w", err)
+	}
+	return nil
+}
+
+// postgresSchemaVersion returns the schema version recorded when a Postgres
+// database was created, or 0 before it has a schema
+func (d *Database) postgresSchemaVersion() (int, error) {
+	var exists bool
+	if err := d.DB.QueryRow(`SELECT to_regclass('settings') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to inspect tables: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	err := d.DB.QueryRow(`SELECT CAST(value AS INTEGER) FROM settings WHERE key = ?`, schemaVersionSetting).Scan(&version)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// postgresConnector opens Postgres connections that take the SQLite-style
+// ? placeholders of the models' queries
+type postgresConnector struct {
+	driver.Connector
+}
+
+func (c *postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pgConn, ok := conn.(postgresDriverConn)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unsupported Postgres connection %T", conn)
+	}
+	return &postgresConn{postgresDriverConn: pgConn}, nil
+}
+
+// postgresDriverConn is the part of lib/pq's connections that database/sql
+// uses
+type postgresDriverConn interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	driver.NamedValueChecker
+}
+
+// postgresConn numbers the placeholders of the statements run on a lib/pq
+// connection, passing everything else through
+type postgresConn struct {
+	postgresDriverConn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.postgresDriverConn.Prepare(numberPlaceholders(query))
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.postgresDriverConn.PrepareContext(ctx, numberPlaceholders(query))
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.postgresDriverConn.ExecContext(ctx, numberPlaceholders(query), args)
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.postgresDriverConn.QueryContext(ctx, numberPlaceholders(query), args)
+}
+
+// numberPlaceholders rewrites the ? placeholders of a query as $1, $2, ...
+// Question marks in string literals, quoted identifiers, and comments are
+// left alone.
+func numberPlaceholders(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	var quote byte // The closing quote while in a literal or identifier
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			// A doubled quote ends the literal and starts it again
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case c == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNumberPlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "no placeholders",
+			query: "SELECT COUNT(*) FROM tasks",
+			want:  "SELECT COUNT(*) FROM tasks",
+		},
+		{
+			name:  "numbered in order",
+			query: "UPDATE tasks SET title = ?, updated = ? WHERE id = ?",
+			want:  "UPDATE tasks SET title = $1, updated = $2 WHERE id = $3",
+		},
+		{
+			name:  "string literal",
+			query: "SELECT '?', 'it''s ?' FROM tasks WHERE id = ?",
+			want:  "SELECT '?', 'it''s ?' FROM tasks WHERE id = $1",
+		},
+		{
+			name:  "quoted identifier",
+			query: `SELECT "what?" FROM tasks WHERE id = ?`,
+			want:  `SELECT "what?" FROM tasks WHERE id = $1`,
+		},
+		{
+			name:  "comment",
+			query: "SELECT id FROM tasks -- why?\nWHERE id = ?",
+			want:  "SELECT id FROM tasks -- why?\nWHERE id = $1",
+		},
+		{
+			name:  "trailing comment",
+			query: "SELECT ? -- done?",
+			want:  "SELECT $1 -- done?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numberPlaceholders(tt.query); got != tt.want {
+				t.Errorf("numberPlaceholders(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewPostgres needs a Postgres server, given as GTD_TEST_DATABASE_URL.
+// It creates its tables in a schema of its own and drops them afterwards.
+func TestNewPostgres(t *testing.T) {
+	url := os.Getenv("GTD_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("GTD_TEST_DATABASE_URL is not set")
+	}
+	db := openPostgresTest(t, url)
+
+	if !db.Postgres() {
+		t.Error("Postgres() = false for a Postgres database")
+	}
+	if version, err := db.SchemaVersion(); err != nil || version != 0 {
+		t.Errorf("SchemaVersion() before CreateSchema = %d, %v, want 0", version, err)
+	}
+	if err := db.CreateSchema(); err != nil {
+		t.Fatalf("CreateSchema() error = %v", err)
+	}
+	// Creating the schema again leaves it alone
+	if err := db.CreateSchema(); err != nil {
+		t.Fatalf("second CreateSchema() error = %v", err)
+	}
+	if version, err := db.SchemaVersion(); err != nil || version != LatestSchemaVersion() {
+		t.Errorf("SchemaVersion() = %d, %v, want %d", version, err, LatestSchemaVersion())
+	}
+	if pending, err := db.PendingMigrations(); err != nil || len(pending) != 0 {
+		t.Errorf("PendingMigrations() = %v, %v, want none", pending, err)
+	}
+
+	for i, title := range []string{"First", "Second"} {
+		if _, err := db.Exec(`INSERT INTO tasks (id, kind, title, author) VALUES (?, 'BUG', ?, 'Test <test@example.com>')`,
+			title, title); err != nil {
+			t.Fatalf("insert task: %v", err)
+		}
+		var seq int
+		if err := db.DB.QueryRow(`SELECT seq FROM tasks WHERE id = ?`, title).Scan(&seq); err != nil || seq != i+1 {
+			t.Errorf("seq of task %d = %d, %v, want %d", i+1, seq, err, i+1)
+		}
+	}
+}
+
+// openPostgresTest connects to the Postgres server at url with a new, empty
+// schema first in the search path, which is dropped when the test ends
+func openPostgresTest(t *testing.T, url string) *Database {
+	t.Helper()
+	admin, err := NewPostgres(url)
+	if err != nil {
+		t.Fatalf("NewPostgres() error = %v", err)
+	}
+	schema := fmt.Sprintf("gtd_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+		_ = admin.Close()
+	})
+
+	// Unknown connection parameters are set for the session
+	sep := " "
+	if strings.Contains(url, "://") {
+		sep = "?"
+		if strings.Contains(url, "?") {
+			sep = "&"
+		}
+	}
+	db, err := NewPostgres(url + sep + "search_path=" + schema)
+	if err != nil {
+		t.Fatalf("NewPostgres() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
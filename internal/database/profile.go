@@ -0,0 +1,276 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profiler records the statements of databases opened while it's set
+var profiler *Profiler
+
+// SetProfiler makes databases opened afterwards record the time spent in
+// each SQL statement with p; nil turns profiling off
+func SetProfiler(p *Profiler) {
+	profiler = p
+}
+
+// Profiler records how long SQL statements take, keeping those slower than
+// a threshold
+type Profiler struct {
+	threshold time.Duration
+
+	mu         sync.Mutex
+	statements int
+	total      time.Duration
+	slow       []SlowStatement
+}
+
+// SlowStatement is a statement that took longer than the threshold
+type SlowStatement struct {
+	Query    string
+	Duration time.Duration
+}
+
+// NewProfiler creates a profiler keeping statements slower than threshold
+func NewProfiler(threshold time.Duration) *Profiler {
+	return &Profiler{threshold: threshold}
+}
+
+// Threshold returns the duration above which statements are kept as slow
+func (p *Profiler) Threshold() time.Duration {
+	return p.threshold
+}
+
+// Totals returns the number of statements run and the time spent in them
+func (p *Profiler) Totals() (int, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.statements, p.total
+}
+
+// Slow returns the statements slower than the threshold, in the order they
+// ran
+func (p *Profiler) Slow() []SlowStatement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]SlowStatement(nil), p.slow...)
+}
+
+// record adds a statement that took d
+func (p *Profiler) record(query string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements++
+	p.total += d
+	if d > p.threshold {
+		p.slow = append(p.slow, SlowStatement{Query: strings.Join(strings.Fields(query), " "), Duration: d})
+	}
+}
+
+// openDB opens a connection pool with the SQLite driver, recording its
+// statements when a profiler is set
+func openDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil || profiler == nil {
+		return db, err
+	}
+	// sql.Open doesn't connect, so this only borrows the driver
+	drv := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return openConnector(&dsnConnector{driver: drv, dsn: dsn}), nil
+}
+
+// openConnector opens a connection pool with connector, recording its
+// statements when a profiler is set
+func openConnector(connector driver.Connector) *sql.DB {
+	if profiler != nil {
+		connector = &profiledConnector{connector: connector, profiler: profiler}
+	}
+	return sql.OpenDB(connector)
+}
+
+// dsnConnector opens connections with a driver and a connection string
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// profiledConnector opens connections that record their statements
+type profiledConnector struct {
+	connector driver.Connector
+	profiler  *Profiler
+}
+
+func (c *profiledConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &profiledConn{Conn: conn, profiler: c.profiler}, nil
+}
+
+func (c *profiledConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// profiledConn times the statements run on a driver connection, passing
+// everything else through
+type profiledConn struct {
+	driver.Conn
+	profiler *Profiler
+}
+
+func (c *profiledConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profiledStmt{Stmt: stmt, query: query, profiler: c.profiler}, nil
+}
+
+func (c *profiledConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // For drivers without BeginTx
+}
+
+func (c *profiledConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.profiler.record(query, time.Since(start))
+	}
+	return result, err
+}
+
+func (c *profiledConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if err != driver.ErrSkip {
+			c.profiler.record(query, time.Since(start))
+		}
+		return nil, err
+	}
+	return &profiledRows{Rows: rows, query: query, profiler: c.profiler, elapsed: time.Since(start)}, nil
+}
+
+func (c *profiledConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *profiledConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *profiledConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *profiledConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// profiledStmt times a prepared statement
+type profiledStmt struct {
+	driver.Stmt
+	query    string
+	profiler *Profiler
+}
+
+func (s *profiledStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	defer func() { s.profiler.record(s.query, time.Since(start)) }()
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(namedValues(args)) // For drivers without ExecContext
+}
+
+func (s *profiledStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args)) // For drivers without QueryContext
+	}
+	if err != nil {
+		s.profiler.record(s.query, time.Since(start))
+		return nil, err
+	}
+	return &profiledRows{Rows: rows, query: s.query, profiler: s.profiler, elapsed: time.Since(start)}, nil
+}
+
+// namedValues drops the names of statement arguments for drivers that only
+// take positional ones
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// profiledRows adds the time spent reading rows to their query, which is
+// recorded when the rows are closed
+type profiledRows struct {
+	driver.Rows
+	query    string
+	profiler *Profiler
+	elapsed  time.Duration
+}
+
+func (r *profiledRows) Next(dest []driver.Value) error {
+	start := time.Now()
+	err := r.Rows.Next(dest)
+	r.elapsed += time.Since(start)
+	return err
+}
+
+func (r *profiledRows) Close() error {
+	r.profiler.record(r.query, r.elapsed)
+	return r.Rows.Close()
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfiler(t *testing.T) {
+	p := NewProfiler(0)
+	SetProfiler(p)
+	defer SetProfiler(nil)
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := p.Totals()
+	if _, err := db.Exec("INSERT INTO settings (key, value) VALUES (?, ?)", "profile", "on"); err != nil {
+		t.Fatal(err)
+	}
+	var value string
+	if err := db.DB.QueryRow("SELECT value\n  FROM settings WHERE key = ?", "profile").Scan(&value); err != nil || value != "on" {
+		t.Fatalf("QueryRow() = %q, %v", value, err)
+	}
+
+	// Transactions still take the write lock and commit
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("DELETE FROM settings WHERE key = ?", "profile"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	statements, total := p.Totals()
+	if statements-before != 3 || total <= 0 {
+		t.Errorf("Totals() = %d, %v, want 3 more statements", statements, total)
+	}
+	slow := p.Slow()
+	if len(slow) != statements {
+		t.Errorf("Slow() = %d statements, want all %d over a zero threshold", len(slow), statements)
+	}
+	if got := slow[len(slow)-2].Query; got != "SELECT value FROM settings WHERE key = ?" {
+		t.Errorf("slow query = %q, want whitespace collapsed", got)
+	}
+}
+
+func TestProfilerThreshold(t *testing.T) {
+	p := NewProfiler(time.Second)
+	p.record("SELECT 1", time.Millisecond)
+	p.record("SELECT  2", 2*time.Second)
+
+	if statements, total := p.Totals(); statements != 2 || total != 2*time.Second+time.Millisecond {
+		t.Errorf("Totals() = %d, %v", statements, total)
+	}
+	slow := p.Slow()
+	if len(slow) != 1 || !strings.HasPrefix(slow[0].Query, "SELECT 2") {
+		t.Errorf("Slow() = %+v", slow)
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+var (
+	// busyTimeout is how long SQLite itself waits for another connection's
+	// write lock before failing with SQLITE_BUSY
+	busyTimeout = 5 * time.Second
+
+	// retryBackoff is the wait before each retry of a write that still found
+	// the database locked; it doubles between attempts
+	retryBackoff = 100 * time.Millisecond
+	maxRetries   = 4
+)
+
+// Retry runs fn, running it again with exponential backoff while it fails
+// because another process holds the database's write lock. Once the retries
+// are exhausted, the result is a BusyError.
+func Retry(fn func() error) error {
+	wait := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if !isBusy(err) {
+			return err
+		}
+		if attempt == maxRetries {
+			return &errors.BusyError{Err: err}
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// Exec runs a write statement outside a transaction, retrying while the
+// database is locked
+func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := Retry(func() (err error) {
+		result, err = d.DB.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+func TestRetryWhileLocked(t *testing.T) {
+	oldTimeout, oldBackoff := busyTimeout, retryBackoff
+	busyTimeout, retryBackoff = 10*time.Millisecond, time.Millisecond
+	defer func() { busyTimeout, retryBackoff = oldTimeout, oldBackoff }()
+
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	first, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = first.Close() }()
+	if err := first.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = second.Close() }()
+
+	// The first process holds the write lock
+	tx, err := first.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = second.Exec("INSERT INTO settings (key, value) VALUES ('a', '1')")
+	if errors.CodeOf(err) != errors.CodeBusy {
+		t.Fatalf("Exec() error = %v, want a busy error", err)
+	}
+	if _, err := second.Begin(); errors.CodeOf(err) != errors.CodeBusy {
+		t.Fatalf("Begin() error = %v, want a busy error", err)
+	}
+
+	// Writes go through once the lock is released during the retries
+	retryBackoff = 20 * time.Millisecond
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = tx.Commit()
+	}()
+	if _, err := second.Exec("INSERT INTO settings (key, value) VALUES ('a', '1')"); err != nil {
+		t.Errorf("Exec() error = %v, want the write to be retried", err)
+	}
+}
@@ -0,0 +1,12 @@
+package database
+
+import "time"
+
+// TimeLayout is the layout of stored timestamps: RFC3339 in UTC with
+// millisecond precision, so that stored values sort chronologically as text
+const TimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// FormatTime formats t in UTC for storage and for comparison with stored values
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(TimeLayout)
+}
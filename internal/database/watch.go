@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ChangeWatcher detects changes committed to the database by other connections,
+// including other processes, using SQLite's data_version pragma
+type ChangeWatcher struct {
+	conn    *sql.Conn
+	version int64
+}
+
+// Watch returns a ChangeWatcher holding a dedicated connection until closed
+func (d *Database) Watch(ctx context.Context) (*ChangeWatcher, error) {
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch connection: %w", err)
+	}
+
+	w := &ChangeWatcher{conn: conn}
+	if _, err := w.Changed(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Changed reports whether the database changed since the previous call
+func (w *ChangeWatcher) Changed(ctx context.Context) (bool, error) {
+	var version int64
+	if err := w.conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version); err != nil {
+		return false, fmt.Errorf("failed to check for database changes: %w", err)
+	}
+	changed := version != w.version
+	w.version = version
+	return changed, nil
+}
+
+// Close releases the watcher's connection
+func (w *ChangeWatcher) Close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeWatcher(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	watcher, err := db.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	if changed, err := watcher.Changed(ctx); err != nil || changed {
+		t.Fatalf("Changed() = %v, %v before any write", changed, err)
+	}
+
+	if _, err := db.DB.Exec("INSERT INTO settings (key, value) VALUES ('k', 'v')"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := watcher.Changed(ctx); err != nil || !changed {
+		t.Errorf("Changed() = %v, %v after a write", changed, err)
+	}
+	if changed, err := watcher.Changed(ctx); err != nil || changed {
+		t.Errorf("Changed() = %v, %v after the change was reported", changed, err)
+	}
+}
@@ -0,0 +1,173 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// Code is a machine-readable error category, stable across releases so that
+// scripts can branch on it instead of parsing messages
+type Code string
+
+// Error codes
+const (
+	CodeNotFound          Code = "NOT_FOUND"
+	CodeAmbiguousPrefix   Code = "AMBIGUOUS_PREFIX"
+	CodeInvalidTransition Code = "INVALID_TRANSITION"
+	CodeValidation        Code = "VALIDATION"
+	CodeConflict          Code = "CONFLICT"
+	CodeBusy              Code = "BUSY"
+	CodeUnknown           Code = "ERROR" // Any error without a more specific code
+)
+
+// exitCodes maps error codes to process exit codes; other errors exit with 1
+var exitCodes = map[Code]int{
+	CodeNotFound:          3,
+	CodeAmbiguousPrefix:   4,
+	CodeInvalidTransition: 5,
+	CodeValidation:        6,
+	CodeConflict:          7,
+	CodeBusy:              8,
+}
+
+// Coded is implemented by errors that carry an error code
+type Coded interface {
+	error
+	Code() Code
+}
+
+// CodeOf returns the code of the first coded error in err's chain, or
+// CodeUnknown when there is none
+func CodeOf(err error) Code {
+	var coded Coded
+	if stderrors.As(err, &coded) {
+		return coded.Code()
+	}
+	return CodeUnknown
+}
+
+// ExitStatus is an error that only sets the exit status of gtd, for
+// commands such as exists that answer through it. It is not reported.
+type ExitStatus int
+
+func (e ExitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// IsExitStatus reports whether err is or wraps an ExitStatus
+func IsExitStatus(err error) bool {
+	var status ExitStatus
+	return stderrors.As(err, &status)
+}
+
+// ExitCode returns the process exit code for err
+func ExitCode(err error) int {
+	var status ExitStatus
+	if stderrors.As(err, &status) {
+		return int(status)
+	}
+	if code, ok := exitCodes[CodeOf(err)]; ok {
+		return code
+	}
+	return 1
+}
+
+// Code returns CodeNotFound
+func (e *TaskNotFoundError) Code() Code {
+	return CodeNotFound
+}
+
+// Code returns CodeAmbiguousPrefix
+func (e *AmbiguousTaskError) Code() Code {
+	return CodeAmbiguousPrefix
+}
+
+// Code returns CodeInvalidTransition
+func (e *InvalidStateTransitionError) Code() Code {
+	return CodeInvalidTransition
+}
+
+// Code returns CodeValidation, as a mistyped command is invalid input
+func (e *InvalidCommandError) Code() Code {
+	return CodeValidation
+}
+
+// NotFoundError reports a missing task or other missing data where a
+// TaskNotFoundError's suggestions don't apply
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// Code returns CodeNotFound
+func (e *NotFoundError) Code() Code {
+	return CodeNotFound
+}
+
+// NewNotFoundError creates a not found error with a formatted message
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationError reports invalid input, such as a bad flag value or a task
+// missing its description
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Code returns CodeValidation
+func (e *ValidationError) Code() Code {
+	return CodeValidation
+}
+
+// NewValidationError creates a validation error with a formatted message
+func NewValidationError(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ConflictError reports a request that conflicts with existing data, such as
+// adding a link that already exists
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// Code returns CodeConflict
+func (e *ConflictError) Code() Code {
+	return CodeConflict
+}
+
+// NewConflictError creates a conflict error with a formatted message
+func NewConflictError(format string, args ...interface{}) error {
+	return &ConflictError{Message: fmt.Sprintf(format, args...)}
+}
+
+// BusyError reports that the database stayed locked by another process for
+// longer than gtd is willing to wait
+type BusyError struct {
+	Err error // The last locking error
+}
+
+func (e *BusyError) Error() string {
+	return "database is busy: another gtd command is writing to it, try again"
+}
+
+// Unwrap returns the last locking error
+func (e *BusyError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeBusy
+func (e *BusyError) Code() Code {
+	return CodeBusy
+}
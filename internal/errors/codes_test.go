@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     Code
+		exitCode int
+	}{
+		{name: "not found", err: NewTaskNotFoundError("abc", nil), code: CodeNotFound, exitCode: 3},
+		{name: "plain not found", err: NewNotFoundError("no tasks found"), code: CodeNotFound, exitCode: 3},
+		{name: "ambiguous", err: NewAmbiguousTaskError("abc", nil), code: CodeAmbiguousPrefix, exitCode: 4},
+		{name: "transition", err: NewInvalidStateTransitionError("DONE", "NEW", nil, nil), code: CodeInvalidTransition, exitCode: 5},
+		{name: "validation", err: NewValidationError("invalid priority: %s", "urgent"), code: CodeValidation, exitCode: 6},
+		{name: "conflict", err: NewConflictError("already linked"), code: CodeConflict, exitCode: 7},
+		{name: "busy", err: fmt.Errorf("failed to update task: %w", &BusyError{}), code: CodeBusy, exitCode: 8},
+		{name: "wrapped", err: fmt.Errorf("failed to create task: %w", NewValidationError("title is required")), code: CodeValidation, exitCode: 6},
+		{name: "uncoded", err: fmt.Errorf("disk full"), code: CodeUnknown, exitCode: 1},
+		{name: "exit status", err: ExitStatus(2), code: CodeUnknown, exitCode: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.code {
+				t.Errorf("CodeOf() = %s, want %s", got, tt.code)
+			}
+			if got := ExitCode(tt.err); got != tt.exitCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestNewInvalidStateTransitionErrorf(t *testing.T) {
+	err := NewInvalidStateTransitionErrorf("NEW", "NEW", "task %s is not in INBOX state", "abc1234")
+	if err.Error() != "task abc1234 is not in INBOX state" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if CodeOf(err) != CodeInvalidTransition {
+		t.Errorf("CodeOf() = %s, want %s", CodeOf(err), CodeInvalidTransition)
+	}
+}
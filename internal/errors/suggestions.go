@@ -14,14 +14,15 @@ type TaskNotFoundError struct {
 func (e *TaskNotFoundError) Error() string {
 	msg := fmt.Sprintf("task not found: %s", e.ID)
 	
-	if len(e.Suggestions) > 0 {
-		if len(e.Suggestions) == 1 {
-			msg += fmt.Sprintf("\n\nDid you mean: %s?", e.Suggestions[0])
-		} else {
-			msg += "\n\nDid you mean one of these?"
-			for _, s := range e.Suggestions {
-				msg += fmt.Sprintf("\n  - %s", s)
-			}
+	switch len(e.Suggestions) {
+	case 0:
+		msg += "\n\nHint: Use 'gtd list' to see available tasks"
+	case 1:
+		msg += fmt.Sprintf("\n\nDid you mean: %s?", e.Suggestions[0])
+	default:
+		msg += "\n\nDid you mean one of these?"
+		for _, s := range e.Suggestions {
+			msg += fmt.Sprintf("\n  - %s", s)
 		}
 	}
 	
@@ -37,16 +38,32 @@ type Task interface {
 
 // NewTaskNotFoundError creates a new error with suggestions
 func NewTaskNotFoundError(id string, allTasks []Task) error {
-	suggestions := findSimilarTaskIDs(id, allTasks)
-	
-	if len(suggestions) == 0 {
-		// No similar tasks found, provide general help
-		return fmt.Errorf("task not found: %s\n\nHint: Use 'gtd list' to see available tasks", id)
-	}
-	
 	return &TaskNotFoundError{
 		ID:          id,
-		Suggestions: suggestions,
+		Suggestions: findSimilarTaskIDs(id, allTasks),
+	}
+}
+
+// AmbiguousTaskError lists the candidates when a task ID prefix matches several tasks
+type AmbiguousTaskError struct {
+	ID         string
+	Candidates []Task
+}
+
+func (e *AmbiguousTaskError) Error() string {
+	msg := fmt.Sprintf("ambiguous task ID '%s' matches %d tasks:", e.ID, len(e.Candidates))
+	for _, task := range e.Candidates {
+		msg += fmt.Sprintf("\n  - %s (%s)", task.ShortHash(), task.GetTitle())
+	}
+	msg += "\n\nHint: Use a longer prefix to select one task"
+	return msg
+}
+
+// NewAmbiguousTaskError creates a new error listing the matching tasks
+func NewAmbiguousTaskError(id string, candidates []Task) error {
+	return &AmbiguousTaskError{
+		ID:         id,
+		Candidates: candidates,
 	}
 }
 
@@ -101,9 +118,14 @@ type InvalidStateTransitionError struct {
 	TargetState  string
 	ValidStates  []string
 	Commands     map[string]string // Maps target states to commands
+	Reason       string            // Replaces the generic message and guidance when set
 }
 
 func (e *InvalidStateTransitionError) Error() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+
 	msg := fmt.Sprintf("cannot transition from %s to %s", e.CurrentState, e.TargetState)
 	
 	if len(e.ValidStates) > 0 {
@@ -120,58 +142,25 @@ func (e *InvalidStateTransitionError) Error() string {
 	return msg
 }
 
-// State constants to avoid circular imports
-const (
-	StateInbox      = "INBOX"
-	StateNew        = "NEW"
-	StateInProgress = "IN_PROGRESS"
-	StateDone       = "DONE"
-	StateCancelled  = "CANCELLED"
-	StateInvalid    = "INVALID"
-)
-
-// NewInvalidStateTransitionError creates a new error with helpful transition guidance
-func NewInvalidStateTransitionError(currentState, targetState string) error {
-	commands := map[string]map[string]string{
-		StateInbox: {
-			StateNew:     "accept",
-			StateInvalid: "reject",
-		},
-		StateNew: {
-			StateInProgress: "in-progress",
-			StateDone:       "done",
-			StateCancelled:  "cancel",
-		},
-		StateInProgress: {
-			StateDone:      "done",
-			StateCancelled: "cancel",
-		},
-		StateDone: {
-			StateInProgress: "in-progress",
-		},
-		StateCancelled: {
-			StateNew:        "reopen",
-			StateInProgress: "reopen",
-		},
-	}
-	
-	validTransitions := map[string][]string{
-		StateInbox:      {StateNew, StateInvalid},
-		StateNew:        {StateInProgress, StateDone, StateCancelled},
-		StateInProgress: {StateDone, StateCancelled},
-		StateDone:       {StateInProgress},
-		StateCancelled:  {StateNew, StateInProgress},
-		StateInvalid:    {}, // No valid transitions from INVALID
-	}
-	
-	validStates := validTransitions[currentState]
-	stateCommands := commands[currentState]
-	
+// NewInvalidStateTransitionError creates a new error with helpful transition
+// guidance: the states the task can move to instead, and the commands that
+// move it there by target state. models.NewTransitionError fills these in.
+func NewInvalidStateTransitionError(currentState, targetState string, validStates []string, commands map[string]string) error {
 	return &InvalidStateTransitionError{
 		CurrentState: currentState,
 		TargetState:  targetState,
 		ValidStates:  validStates,
-		Commands:     stateCommands,
+		Commands:     commands,
+	}
+}
+
+// NewInvalidStateTransitionErrorf creates an invalid transition error with a
+// specific explanation instead of the generic guidance
+func NewInvalidStateTransitionErrorf[S ~string](currentState, targetState S, format string, args ...interface{}) error {
+	return &InvalidStateTransitionError{
+		CurrentState: string(currentState),
+		TargetState:  string(targetState),
+		Reason:       fmt.Sprintf(format, args...),
 	}
 }
 
@@ -200,6 +189,14 @@ func (e *InvalidCommandError) Error() string {
 	return msg
 }
 
+// NewInvalidCommandError creates a new error suggesting similar commands
+func NewInvalidCommandError(command string, availableCommands []string) error {
+	return &InvalidCommandError{
+		Command:     command,
+		Suggestions: FindSimilarCommands(command, availableCommands),
+	}
+}
+
 // FindSimilarCommands finds commands similar to the given input
 func FindSimilarCommands(input string, availableCommands []string) []string {
 	var suggestions []string
@@ -221,10 +218,11 @@ func FindSimilarCommands(input string, availableCommands []string) []string {
 		}
 	}
 	
-	// Check for Levenshtein distance of 1 or 2
-	if len(suggestions) == 0 {
+	// Check for Levenshtein distance of 1, then 2, so short commands like
+	// "set" don't crowd out a closer match
+	for maxDistance := 1; maxDistance <= 2 && len(suggestions) == 0; maxDistance++ {
 		for _, cmd := range availableCommands {
-			if levenshteinDistance(inputLower, cmd) <= 2 {
+			if levenshteinDistance(inputLower, cmd) <= maxDistance {
 				suggestions = append(suggestions, cmd)
 			}
 		}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+)
+
+type testTask struct {
+	id, title string
+}
+
+func (t testTask) GetID() string     { return t.id }
+func (t testTask) GetTitle() string  { return t.title }
+func (t testTask) ShortHash() string { return t.id[:7] }
+
+func TestNewTaskNotFoundError(t *testing.T) {
+	tasks := []Task{
+		testTask{id: "abc1234567", title: "Fix login"},
+		testTask{id: "def7654321", title: "Add search"},
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		contains []string
+	}{
+		{name: "no matching prefix", id: "abc9", contains: []string{"task not found: abc9", "Hint"}},
+		{name: "single suggestion", id: "abc12", contains: []string{"Did you mean: abc1234 (Fix login)?"}},
+		{name: "title suggestion", id: "search", contains: []string{"Did you mean: def7654 (Add search)?"}},
+		{name: "no suggestions", id: "zzzz", contains: []string{"Hint: Use 'gtd list'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewTaskNotFoundError(tt.id, tasks)
+			var notFound *TaskNotFoundError
+			if !stderrors.As(err, &notFound) {
+				t.Fatalf("error %T is not a *TaskNotFoundError", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewInvalidCommandError(t *testing.T) {
+	err := NewInvalidCommandError("shw", []string{"show", "list", "search"})
+	if !strings.Contains(err.Error(), "Did you mean: show?") {
+		t.Errorf("Error() = %q, want suggestion for show", err.Error())
+	}
+
+	err = NewInvalidCommandError("xyzzy", []string{"show", "list"})
+	if strings.Contains(err.Error(), "Did you mean") {
+		t.Errorf("Error() = %q, want no suggestions", err.Error())
+	}
+}
+
+func TestNewInvalidStateTransitionError(t *testing.T) {
+	err := NewInvalidStateTransitionError("CANCELLED", "DONE", []string{"NEW", "IN_PROGRESS", "BLOCKED"},
+		map[string]string{"NEW": "reopen", "IN_PROGRESS": "in-progress"})
+	for _, want := range []string{"cannot transition from CANCELLED to DONE", "NEW (use 'gtd reopen')", "IN_PROGRESS (use 'gtd in-progress')", "\n  - BLOCKED"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+
+	// Without valid states there is no guidance
+	if err := NewInvalidStateTransitionError("INVALID", "NEW", nil, nil); err.Error() != "cannot transition from INVALID to NEW" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
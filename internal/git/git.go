@@ -69,3 +69,41 @@ func GetAuthor() (string, error) {
 	// Format like git does: Name <email>
 	return fmt.Sprintf("%s <%s>", name, email), nil
 }
+
+// CurrentRevision returns the checked-out branch and abbreviated commit hash of
+// the repository containing dir. The branch is empty for a detached HEAD.
+func CurrentRevision(dir string) (branch, commit string, err error) {
+	commitOut, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current commit: %w", err)
+	}
+	commit = strings.TrimSpace(string(commitOut))
+
+	branchOut, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	branch = strings.TrimSpace(string(branchOut))
+	if branch == "HEAD" {
+		branch = ""
+	}
+
+	return branch, commit, nil
+}
+
+// TrackedFiles lists the files tracked by git under path, relative to the
+// repository root
+func TrackedFiles(path string) ([]string, error) {
+	out, err := exec.Command("git", "ls-files", "--full-name", "-z", "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -176,3 +177,47 @@ func TestFindGitRootWithSymlink(t *testing.T) {
 		t.Errorf("FindGitRoot() = %v, but .git not found there", got)
 	}
 }
+
+func TestCurrentRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir,
+			"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("commit", "-q", "--allow-empty", "-m", "initial")
+
+	branch, commit, err := CurrentRevision(dir)
+	if err != nil {
+		t.Fatalf("CurrentRevision() error = %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("branch = %q, want %q", branch, "main")
+	}
+	if commit == "" {
+		t.Error("commit is empty")
+	}
+
+	// A detached HEAD has no branch
+	run("checkout", "-q", "--detach")
+	branch, _, err = CurrentRevision(dir)
+	if err != nil {
+		t.Fatalf("CurrentRevision() error = %v", err)
+	}
+	if branch != "" {
+		t.Errorf("detached branch = %q, want empty", branch)
+	}
+
+	// Outside a repository
+	if _, _, err := CurrentRevision(t.TempDir()); err == nil {
+		t.Error("CurrentRevision() outside a repository should fail")
+	}
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Task ID aliases accepted wherever a task ID is expected
+const (
+	// CurrentTaskAlias refers to the task currently in focus
+	CurrentTaskAlias = "@current"
+	// LastTaskAlias refers to the task most recently created or modified by the current author
+	LastTaskAlias = "@last"
+	// SeqPrefix introduces a sequential short ID (#123)
+	SeqPrefix = "#"
+	// ParentSuffix appended to a task reference resolves to its parent (TASK^, TASK^^, ...)
+	ParentSuffix = "^"
+	// MaxRecentTasks is the number of listed tasks addressable as @1..@9
+	MaxRecentTasks = 9
+)
+
+// IsTaskAlias reports whether id is an alias rather than a hash or hash prefix
+func IsTaskAlias(id string) bool {
+	return strings.HasPrefix(id, "@") || strings.HasPrefix(id, SeqPrefix) || strings.HasSuffix(id, ParentSuffix)
+}
+
+// aliasSource looks up the tasks that aliases refer to
+type aliasSource interface {
+	GetByID(id string) (*Task, error)
+	GetCurrentTask() (*Task, error)
+	getByExactID(id string) (*Task, error)
+	getBySeq(seq int) (*Task, error)
+	getLastTask() (*Task, error)
+	getRecentTaskID(position int) (string, error)
+}
+
+// idLookup is an aliasSource that resolves the base of parent references
+// with lookup rather than GetByID
+type idLookup struct {
+	aliasSource
+	lookup func(id string) (*Task, error)
+}
+
+func (s idLookup) GetByID(id string) (*Task, error) {
+	return s.lookup(id)
+}
+
+// resolveAlias resolves a task alias to a task
+func (r *TaskRepository) resolveAlias(id string) (*Task, error) {
+	return resolveAlias(r, id)
+}
+
+// resolveAlias resolves a task alias to one of the tasks of r
+func resolveAlias(r aliasSource, id string) (*Task, error) {
+	// Parent references: resolve the base, then walk up once per caret
+	if base := strings.TrimRight(id, ParentSuffix); base != id {
+		if base == "" {
+			return nil, errors.NewValidationError("invalid task reference: %s", id)
+		}
+		task, err := r.GetByID(base)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(id)-len(base); i++ {
+			if task.Parent == nil {
+				return nil, errors.NewNotFoundError("task %s has no parent", task.ShortHash())
+			}
+			if task, err = r.getByExactID(*task.Parent); err != nil {
+				return nil, err
+			}
+		}
+		return task, nil
+	}
+
+	// Sequential short IDs: #123
+	if strings.HasPrefix(id, SeqPrefix) {
+		seq, err := strconv.Atoi(strings.TrimPrefix(id, SeqPrefix))
+		if err != nil || seq < 1 {
+			return nil, errors.NewValidationError("invalid task number: %s", id)
+		}
+		return r.getBySeq(seq)
+	}
+
+	switch id {
+	case CurrentTaskAlias:
+		task, err := r.GetCurrentTask()
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			return nil, errors.NewNotFoundError("no current task set (use 'gtd focus TASK_ID')")
+		}
+		return task, nil
+	case LastTaskAlias:
+		return r.getLastTask()
+	}
+
+	// Positions in the most recent listing: @1..@9
+	position, err := strconv.Atoi(strings.TrimPrefix(id, "@"))
+	if err != nil {
+		return nil, errors.NewValidationError("unknown task alias: %s (use @current, @last, or @1..@%d)", id, MaxRecentTasks)
+	}
+	taskID, err := r.getRecentTaskID(position)
+	if err != nil {
+		return nil, err
+	}
+	return r.getByExactID(taskID)
+}
+
+// getLastTask returns the task the acting author most recently created or
+// modified, going by the newest of their history entries
+func (r *TaskRepository) getLastTask() (*Task, error) {
+	query := `
+		SELECT h.task_id FROM task_history h
+		JOIN tasks t ON t.id = h.task_id
+		WHERE h.author = ?
+		ORDER BY h.created DESC, h.id DESC
+		LIMIT 1
+	`
+
+	var id string
+	if err := r.db.DB.QueryRow(query, r.actor()).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("no tasks found for @last")
+		}
+		return nil, fmt.Errorf("failed to resolve @last: %w", err)
+	}
+	return r.getByExactID(id)
+}
+
+// getBySeq retrieves a task by its sequential short ID
+func (r *TaskRepository) getBySeq(seq int) (*Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE seq = ?
+	`
+
+	task, err := scanTask(r.db.DB.QueryRow(query, seq))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("task not found: %s%d", SeqPrefix, seq)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return task, nil
+}
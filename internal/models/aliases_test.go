@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+func TestTaskRepository_GetByIDAliases(t *testing.T) {
+	repo := setupTestDB(t)
+
+	grandparent := NewTask(KindFeature, "Grandparent", "Top-level feature")
+	if err := repo.Create(grandparent); err != nil {
+		t.Fatal(err)
+	}
+	parent := NewTask(KindFeature, "Parent", "Mid-level feature")
+	parent.Parent = &grandparent.ID
+	if err := repo.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := NewTask(KindBug, "Child", "Leaf bug")
+	child.Parent = &parent.ID
+	if err := repo.Create(child); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetRecentTasks([]*Task{child, grandparent}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetCurrentTask(parent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr string
+	}{
+		{name: "last created", id: "@last", want: child.ID},
+		{name: "current", id: "@current", want: parent.ID},
+		{name: "first recent", id: "@1", want: child.ID},
+		{name: "second recent", id: "@2", want: grandparent.ID},
+		{name: "recent out of range", id: "@3", wantErr: "out of range"},
+		{name: "parent of prefix", id: child.ID[:7] + "^", want: parent.ID},
+		{name: "grandparent", id: child.ID + "^^", want: grandparent.ID},
+		{name: "parent of alias", id: "@current^", want: grandparent.ID},
+		{name: "no parent", id: grandparent.ID + "^", wantErr: "has no parent"},
+		{name: "bare caret", id: "^", wantErr: "invalid task reference"},
+		{name: "unknown alias", id: "@bogus", wantErr: "unknown task alias"},
+		{name: "sequential ID", id: "#2", want: parent.ID},
+		{name: "parent of sequential ID", id: "#3^", want: parent.ID},
+		{name: "unknown sequential ID", id: "#99", wantErr: "task not found"},
+		{name: "invalid sequential ID", id: "#abc", wantErr: "invalid task number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := repo.GetByID(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetByID(%q) error = %v, want error containing %q", tt.id, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetByID(%q) error = %v", tt.id, err)
+			}
+			if task.ID != tt.want {
+				t.Errorf("GetByID(%q) = %s, want %s", tt.id, task.ShortHash(), tt.want[:7])
+			}
+		})
+	}
+}
+
+func TestLastTaskAlias(t *testing.T) {
+	sqlite, memory := setupTestDB(t), NewMemoryStore()
+	stores := map[string]struct {
+		store     Store
+		setAuthor func(string)
+	}{
+		"sqlite": {sqlite, func(author string) { sqlite.author = author }},
+		"memory": {memory, func(author string) { memory.author = author }},
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			s.setAuthor("Bob <bob@example.com>")
+			if _, err := s.store.GetByID("@last"); err == nil || !strings.Contains(err.Error(), "no tasks found") {
+				t.Errorf("@last without history: error = %v", err)
+			}
+
+			s.setAuthor("Alice <alice@example.com>")
+			older := NewTask(KindBug, "Older", "Created by Alice")
+			newer := NewTask(KindBug, "Newer", "Created by Alice")
+			for _, task := range []*Task{older, newer} {
+				if err := s.store.Create(task); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Bob changed the older task last, though Alice created it
+			s.setAuthor("Bob <bob@example.com>")
+			if err := s.store.UpdateStateAt(older.ID, StateNew, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			for author, want := range map[string]*Task{
+				"Bob <bob@example.com>":     older,
+				"Alice <alice@example.com>": newer,
+			} {
+				s.setAuthor(author)
+				task, err := s.store.GetByID("@last")
+				if err != nil {
+					t.Fatalf("@last for %s: %v", author, err)
+				}
+				if task.ID != want.ID {
+					t.Errorf("@last for %s = %q, want %q", author, task.Title, want.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRepository_CreateAssignsSeq(t *testing.T) {
+	repo := setupTestDB(t)
+
+	for i := 1; i <= 3; i++ {
+		task := NewTask(KindBug, "Numbered", "Sequential numbering")
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		if task.Seq != i {
+			t.Errorf("Seq = %d, want %d", task.Seq, i)
+		}
+		if want := fmt.Sprintf("#%d", i); task.SeqRef() != want {
+			t.Errorf("SeqRef() = %q", task.SeqRef())
+		}
+	}
+}
+
+func TestTaskRepository_SeqNotReused(t *testing.T) {
+	repo := setupTestDB(t)
+
+	var tasks []*Task
+	for i := 0; i < 3; i++ {
+		task := NewTask(KindBug, "Numbered", "Sequential numbering")
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+	// Deleting the newest task leaves its number unused
+	if err := repo.Delete(tasks[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	task := NewTask(KindBug, "Numbered", "Sequential numbering")
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Seq != 4 {
+		t.Errorf("Seq = %d, want 4", task.Seq)
+	}
+	if _, err := repo.GetByID("#3"); err == nil {
+		t.Error("Expected #3 to stay unassigned")
+	}
+}
+
+func TestLookupID(t *testing.T) {
+	stores := map[string]Store{
+		"sqlite": setupTestDB(t),
+		"memory": NewMemoryStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			parent := NewTask(KindFeature, "Login form", "Rework the form")
+			if err := store.Create(parent); err != nil {
+				t.Fatal(err)
+			}
+			child := NewTask(KindBug, "Flaky login test", "Fails on CI")
+			child.Parent = &parent.ID
+			if err := store.Create(child); err != nil {
+				t.Fatal(err)
+			}
+
+			for ref, want := range map[string]string{
+				child.ID:                    child.ID,
+				child.ID[:8]:                child.ID,
+				"#1":                        parent.ID,
+				child.ID[:8] + ParentSuffix: parent.ID,
+			} {
+				task, err := store.LookupID(ref)
+				if err != nil || task.ID != want {
+					t.Errorf("LookupID(%q) = %v, %v, want %s", ref, task, err, want)
+				}
+			}
+
+			// GetByID matches titles, LookupID doesn't
+			if _, err := store.GetByID("flaky"); err != nil {
+				t.Fatalf("GetByID(flaky) error = %v", err)
+			}
+			for _, ref := range []string{"flaky", "flaky" + ParentSuffix, "fffffff"} {
+				if _, err := store.LookupID(ref); errors.CodeOf(err) != errors.CodeNotFound {
+					t.Errorf("LookupID(%q) error = %v, want not found", ref, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Attachment is a file path or URL associated with a task
+type Attachment struct {
+	ID       int64     `json:"id"`
+	TaskID   string    `json:"task_id"`
+	Location string    `json:"location"`
+	Author   string    `json:"author"`
+	Created  time.Time `json:"created"`
+}
+
+// AddAttachment associates a file path or URL with a task. Attaching the same
+// location twice is an error.
+func (r *TaskRepository) AddAttachment(taskID, location string) (*Attachment, error) {
+	task, err := r.GetByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := r.db.DB.QueryRow(
+		"SELECT COUNT(*) FROM task_attachments WHERE task_id = ? AND location = ?",
+		task.ID, location,
+	).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check attachments: %w", err)
+	}
+	if count > 0 {
+		return nil, errors.NewConflictError("%s is already attached to task %s", location, task.ShortHash())
+	}
+
+	attachment := &Attachment{TaskID: task.ID, Location: location, Author: r.actor(), Created: time.Now()}
+	err = database.Retry(func() error {
+		return r.db.DB.QueryRow(`
+			INSERT INTO task_attachments (task_id, location, author, created)
+			VALUES (?, ?, ?, ?)
+			RETURNING id
+		`, attachment.TaskID, attachment.Location, attachment.Author,
+			database.FormatTime(attachment.Created)).Scan(&attachment.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add attachment: %w", err)
+	}
+
+	if err := r.recordHistory(task.ID, ActionAttach, "attachment", "", location); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// RemoveAttachment removes a file path or URL from a task
+func (r *TaskRepository) RemoveAttachment(taskID, location string) error {
+	task, err := r.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(
+		"DELETE FROM task_attachments WHERE task_id = ? AND location = ?",
+		task.ID, location,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return errors.NewConflictError("%s is not attached to task %s", location, task.ShortHash())
+	}
+
+	return r.recordHistory(task.ID, ActionDetach, "attachment", location, "")
+}
+
+// GetAttachments retrieves the attachments of a task, oldest first
+func (r *TaskRepository) GetAttachments(taskID string) ([]*Attachment, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, task_id, location, author, created
+		FROM task_attachments
+		WHERE task_id = ?
+		ORDER BY created ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	var attachments []*Attachment
+	for rows.Next() {
+		attachment := &Attachment{}
+		if err := rows.Scan(&attachment.ID, &attachment.TaskID, &attachment.Location, &attachment.Author, &attachment.Created); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		inLocalTime(&attachment.Created)
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTaskRepository_Attachments(t *testing.T) {
+	repo := setupTestDB(t)
+	repo.author = "Alice <alice@example.com>"
+
+	task := NewTask(KindFeature, "Design review", "Discuss the design document")
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, location := range []string{"docs/design.md", "https://example.com/logs/42"} {
+		if _, err := repo.AddAttachment(task.ShortHash(), location); err != nil {
+			t.Fatalf("AddAttachment(%s) error = %v", location, err)
+		}
+	}
+	if _, err := repo.AddAttachment(task.ID, "docs/design.md"); err == nil {
+		t.Error("Expected error attaching the same location twice")
+	}
+
+	attachments, err := repo.GetAttachments(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("GetAttachments() = %d attachments, want 2", len(attachments))
+	}
+	if attachments[0].Location != "docs/design.md" || attachments[0].Author != "Alice <alice@example.com>" {
+		t.Errorf("First attachment = %+v", attachments[0])
+	}
+
+	if err := repo.RemoveAttachment(task.ID, "docs/design.md"); err != nil {
+		t.Fatalf("RemoveAttachment() error = %v", err)
+	}
+	if err := repo.RemoveAttachment(task.ID, "docs/design.md"); err == nil {
+		t.Error("Expected error removing a missing attachment")
+	}
+
+	attachments, _ = repo.GetAttachments(task.ID)
+	if len(attachments) != 1 {
+		t.Errorf("GetAttachments() after removal = %d attachments, want 1", len(attachments))
+	}
+
+	history, _ := repo.GetHistory(task.ID)
+	actions := map[string]int{}
+	for _, entry := range history {
+		actions[entry.Action]++
+	}
+	if actions[ActionAttach] != 2 || actions[ActionDetach] != 1 {
+		t.Errorf("History actions = %v, want 2 attach and 1 detach", actions)
+	}
+
+	// Attachments go away with their task
+	if err := repo.Delete(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	attachments, _ = repo.GetAttachments(task.ID)
+	if len(attachments) != 0 {
+		t.Errorf("GetAttachments() after delete = %d attachments, want 0", len(attachments))
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// TransitionSubtree moves a task and its descendants to newState in one
+// transaction. Descendants go first, each after its own children and after
+// its blocker when that is part of the subtree. Descendants that are already
+// DONE or CANCELLED, or that cannot make the transition, are left alone; if
+// the task itself then still cannot make it, nothing is changed. It returns
+// the descendants that were transitioned, in the order they were changed.
+func (r *TaskRepository) TransitionSubtree(id string, newState State) (changed []*Task, err error) {
+	root, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, from, err := planSubtreeTransition(root, newState, r.GetChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback cascade: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	for i, task := range plan {
+		if _, err = tx.Exec("UPDATE tasks SET state = ? WHERE id = ?", newState, task.ID); err != nil {
+			return nil, fmt.Errorf("failed to update state of task %s: %w", task.ShortHash(), err)
+		}
+		if err = r.recordHistoryWith(tx, time.Now(), task.ID, ActionState, "state", from[i].String(), newState.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit cascade: %w", err)
+	}
+
+	// The root is always last in the plan
+	return plan[:len(plan)-1], nil
+}
+
+// PlanSubtreeTransition returns the descendants TransitionSubtree would
+// transition, in the order it would change them, without changing anything.
+// It fails like TransitionSubtree when the task itself cannot make the
+// transition.
+func (r *TaskRepository) PlanSubtreeTransition(id string, newState State) ([]*Task, error) {
+	root, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, _, err := planSubtreeTransition(root, newState, r.GetChildren)
+	if err != nil {
+		return nil, err
+	}
+	return plan[:len(plan)-1], nil
+}
+
+// planSubtreeTransition orders a task's subtree for TransitionSubtree and
+// picks the tasks that make the transition, setting their new state. It
+// returns them, root last, along with the states they had before.
+func planSubtreeTransition(root *Task, newState State, getChildren func(id string) ([]*Task, error)) (plan []*Task, from []State, err error) {
+	// Load the subtree breadth first
+	tasks := map[string]*Task{root.ID: root}
+	children := map[string][]*Task{}
+	queue := []*Task{root}
+	for len(queue) > 0 {
+		task := queue[0]
+		queue = queue[1:]
+		kids, err := getChildren(task.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		children[task.ID] = kids
+		for _, kid := range kids {
+			tasks[kid.ID] = kid
+			queue = append(queue, kid)
+		}
+	}
+
+	// Order the subtree so every task follows its children and its blocker;
+	// the visited set also stops blocker cycles
+	var order []*Task
+	visited := map[string]bool{}
+	var visit func(task *Task)
+	visit = func(task *Task) {
+		if visited[task.ID] {
+			return
+		}
+		visited[task.ID] = true
+		for _, child := range children[task.ID] {
+			visit(child)
+		}
+		if task.BlockedBy != nil {
+			if blocker, ok := tasks[*task.BlockedBy]; ok {
+				visit(blocker)
+			}
+		}
+		order = append(order, task)
+	}
+	visit(root)
+
+	// Plan the transitions in memory so parents see their children's new states
+	for _, task := range order {
+		if task != root && (task.State == StateDone || task.State == StateCancelled) {
+			continue
+		}
+		if !task.CanTransitionTo(newState, children[task.ID]) {
+			if task == root {
+				return nil, nil, transitionError(root, newState, children[root.ID])
+			}
+			continue
+		}
+		plan = append(plan, task)
+		from = append(from, task.State)
+		task.State = newState
+	}
+
+	return plan, from, nil
+}
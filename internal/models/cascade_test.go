@@ -0,0 +1,109 @@
+package models
+
+import "testing"
+
+func TestTaskRepository_TransitionSubtree(t *testing.T) {
+	repo := setupTestDB(t)
+
+	create := func(title string, state State, parent *Task) *Task {
+		t.Helper()
+		task := NewTask(KindFeature, title, "Part of a subtree")
+		task.State = state
+		if parent != nil {
+			task.Parent = &parent.ID
+		}
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+
+	root := create("Root", StateInProgress, nil)
+	first := create("First", StateNew, root)
+	second := create("Second", StateInProgress, root)
+	grandchild := create("Grandchild", StateNew, first)
+	cancelled := create("Cancelled", StateCancelled, root)
+	// Second must wait for First
+	if err := repo.Block(second.ID, first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := repo.TransitionSubtree(root.ID, StateDone)
+	if err != nil {
+		t.Fatalf("TransitionSubtree() error = %v", err)
+	}
+
+	var order []string
+	for _, task := range changed {
+		order = append(order, task.Title)
+	}
+	want := []string{"Grandchild", "First", "Second"}
+	if len(order) != len(want) {
+		t.Fatalf("changed = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("changed = %v, want %v", order, want)
+		}
+	}
+
+	for _, task := range []*Task{root, first, second, grandchild} {
+		got, err := repo.GetByID(task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.State != StateDone {
+			t.Errorf("%s state = %s, want DONE", task.Title, got.State)
+		}
+	}
+	got, err := repo.GetByID(cancelled.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != StateCancelled {
+		t.Errorf("cancelled subtask state = %s, want CANCELLED", got.State)
+	}
+}
+
+func TestTaskRepository_TransitionSubtreeAtomic(t *testing.T) {
+	repo := setupTestDB(t)
+
+	root := NewTask(KindFeature, "Root", "Has an unreviewed subtask")
+	root.State = StateNew
+	if err := repo.Create(root); err != nil {
+		t.Fatal(err)
+	}
+	open := NewTask(KindBug, "Open", "Could be completed")
+	open.State = StateNew
+	open.Parent = &root.ID
+	if err := repo.Create(open); err != nil {
+		t.Fatal(err)
+	}
+	inbox := NewTask(KindBug, "Inbox", "Cannot be completed before review")
+	inbox.Parent = &root.ID
+	if err := repo.Create(inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.TransitionSubtree(root.ID, StateDone); err == nil {
+		t.Fatal("TransitionSubtree() should fail while a subtask is in INBOX")
+	}
+
+	// Nothing may have changed
+	got, err := repo.GetByID(open.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != StateNew {
+		t.Errorf("open subtask state = %s, want NEW", got.State)
+	}
+
+	// Cancelling leaves the INBOX subtask alone instead of failing
+	changed, err := repo.TransitionSubtree(root.ID, StateCancelled)
+	if err != nil {
+		t.Fatalf("TransitionSubtree() error = %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != open.ID {
+		t.Errorf("TransitionSubtree() changed %d subtasks, want only the open one", len(changed))
+	}
+}
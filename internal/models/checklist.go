@@ -0,0 +1,72 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// checklistLine matches Markdown task list items such as "- [ ] Write docs"
+// and "- [x] Add tests", capturing the check mark and the item text
+var checklistLine = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*)$`)
+
+// ChecklistItem is a checklist line in a task description
+type ChecklistItem struct {
+	Line int // Zero-based line in the description
+	Text string
+	Done bool
+}
+
+// ParseChecklist returns the checklist items of a description in order
+func ParseChecklist(description string) []ChecklistItem {
+	var items []ChecklistItem
+	for i, line := range strings.Split(description, "\n") {
+		match := checklistLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, ChecklistItem{
+			Line: i,
+			Text: strings.TrimSpace(match[4]),
+			Done: match[2] != " ",
+		})
+	}
+	return items
+}
+
+// ChecklistProgress counts the checklist items in the task's description
+// and how many of them are checked
+func (t *Task) ChecklistProgress() (done, total int) {
+	for _, item := range ParseChecklist(t.Description) {
+		if item.Done {
+			done++
+		}
+		total++
+	}
+	return done, total
+}
+
+// ToggleChecklistItem checks or unchecks the nth checklist item of the
+// task's description, counting from 1, and returns the item as it is now
+func (t *Task) ToggleChecklistItem(n int) (ChecklistItem, error) {
+	items := ParseChecklist(t.Description)
+	if len(items) == 0 {
+		return ChecklistItem{}, errors.NewValidationError("task %s has no checklist items", t.ShortHash())
+	}
+	if n < 1 || n > len(items) {
+		return ChecklistItem{}, errors.NewValidationError("task %s has no checklist item %d (must be 1 to %d)", t.ShortHash(), n, len(items))
+	}
+
+	item := items[n-1]
+	item.Done = !item.Done
+	mark := " "
+	if item.Done {
+		mark = "x"
+	}
+
+	lines := strings.Split(t.Description, "\n")
+	lines[item.Line] = checklistLine.ReplaceAllString(lines[item.Line], "${1}"+mark+"${3}${4}")
+	t.Description = strings.Join(lines, "\n")
+	return item, nil
+}
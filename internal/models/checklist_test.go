@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	description := `Release checklist:
+- [x] Update changelog
+- [ ] Tag the release
+  * [X] Nested item
+- [] Not an item
+-[ ] Not an item either
++ [ ] Build binaries`
+
+	items := ParseChecklist(description)
+	want := []ChecklistItem{
+		{Line: 1, Text: "Update changelog", Done: true},
+		{Line: 2, Text: "Tag the release", Done: false},
+		{Line: 3, Text: "Nested item", Done: true},
+		{Line: 6, Text: "Build binaries", Done: false},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("ParseChecklist() = %+v, want %+v", items, want)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i+1, items[i], want[i])
+		}
+	}
+
+	task := &Task{Description: description}
+	if done, total := task.ChecklistProgress(); done != 2 || total != 4 {
+		t.Errorf("ChecklistProgress() = %d/%d, want 2/4", done, total)
+	}
+}
+
+func TestTask_ToggleChecklistItem(t *testing.T) {
+	task := NewTask(KindFeature, "Release", "Steps:\n- [ ] Tag the release\n  * [X] Build binaries")
+
+	item, err := task.ToggleChecklistItem(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !item.Done || item.Text != "Tag the release" {
+		t.Errorf("Expected first item to be checked, got %+v", item)
+	}
+	if item, err = task.ToggleChecklistItem(2); err != nil || item.Done {
+		t.Errorf("Expected second item to be unchecked, got %+v (%v)", item, err)
+	}
+	if want := "Steps:\n- [x] Tag the release\n  * [ ] Build binaries"; task.Description != want {
+		t.Errorf("Description = %q, want %q", task.Description, want)
+	}
+
+	for _, n := range []int{0, 3} {
+		if _, err := task.ToggleChecklistItem(n); err == nil {
+			t.Errorf("ToggleChecklistItem(%d) error = nil, want error", n)
+		}
+	}
+	if _, err := NewTask(KindBug, "Bug", "No checklist").ToggleChecklistItem(1); err == nil {
+		t.Error("Expected error for a task without checklist items")
+	}
+}
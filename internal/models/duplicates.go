@@ -0,0 +1,273 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// DefaultDuplicateThreshold is the similarity at or above which two tasks are
+// considered likely duplicates
+const DefaultDuplicateThreshold = 0.6
+
+// Similarity scores how alike two tasks are, from 0 (unrelated) to 1 (identical).
+// Titles weigh most; matching descriptions raise the score of similar titles.
+func Similarity(a, b *Task) float64 {
+	titleScore := jaccard(tokenize(a.Title), tokenize(b.Title))
+	descScore := jaccard(tokenize(a.Description), tokenize(b.Description))
+	if combined := (titleScore + descScore) / 2; combined > titleScore {
+		return combined
+	}
+	return titleScore
+}
+
+// FindDuplicates groups tasks whose pairwise similarity reaches the threshold.
+// Each returned cluster has at least two tasks, in the order they were given.
+func FindDuplicates(tasks []*Task, threshold float64) [][]*Task {
+	// Union-find over task indexes
+	parent := make([]int, len(tasks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(tasks); i++ {
+		for j := i + 1; j < len(tasks); j++ {
+			if Similarity(tasks[i], tasks[j]) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]*Task)
+	var roots []int
+	for i, task := range tasks {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], task)
+	}
+
+	var clusters [][]*Task
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+	return clusters
+}
+
+// tokenize splits text into a set of lowercase words
+func tokenize(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// jaccard returns the Jaccard index of two word sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// MergeInto folds a duplicate task into the task that is kept: tags are
+// combined, subtasks and blocked tasks move over to the kept task, the
+// duplicate is linked to it and closed (INVALID from INBOX, CANCELLED
+// otherwise). Everything happens in one transaction. Merges that would make
+// a task its own ancestor or blocker are refused.
+func (r *TaskRepository) MergeInto(keepID, duplicateID string) (err error) {
+	keep, err := r.GetByID(keepID)
+	if err != nil {
+		return err
+	}
+	duplicate, err := r.GetByID(duplicateID)
+	if err != nil {
+		return err
+	}
+	if keep.ID == duplicate.ID {
+		return errors.NewValidationError("cannot merge task %s into itself", keep.ShortHash())
+	}
+
+	// A subtask of the duplicate takes its place in the hierarchy, but one
+	// further down would end up below its own subtasks
+	if keep.Parent == nil || *keep.Parent != duplicate.ID {
+		ancestors, err := r.GetAncestors(keep.ID)
+		if err != nil {
+			return err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.ID == duplicate.ID {
+				return errors.NewValidationError("cannot merge task %s into %s, which is nested below it", duplicate.ShortHash(), keep.ShortHash())
+			}
+		}
+	}
+
+	children, err := r.GetChildren(duplicate.ID)
+	if err != nil {
+		return err
+	}
+	blocked, err := r.getBlockedBy(duplicate.ID)
+	if err != nil {
+		return err
+	}
+
+	// Tasks the kept task waits on can't wait on it in turn
+	blockers, _, err := r.GetBlockerChain(keep.ID)
+	if err != nil {
+		return err
+	}
+	for _, task := range blocked {
+		for _, blocker := range blockers {
+			if blocker.ID == task.ID {
+				return errors.NewValidationError("cannot merge task %s into %s: %s would block the task it waits on", duplicate.ShortHash(), keep.ShortHash(), task.ShortHash())
+			}
+		}
+	}
+
+	state := StateCancelled
+	if duplicate.State == StateInbox {
+		state = StateInvalid
+	}
+	closeDuplicate := duplicate.State != state && duplicate.State != StateDone
+	// The duplicate has no subtasks left when it is closed
+	if closeDuplicate && !duplicate.CanTransitionTo(state, nil) {
+		return NewTransitionError(duplicate.State, state)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback merge: %v\n", rollbackErr)
+			}
+		}
+	}()
+	now := time.Now()
+
+	// Combine tags, keeping the order of the kept task
+	tags := keep.ParseTags()
+	for _, tag := range duplicate.ParseTags() {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != len(keep.ParseTags()) {
+		before := keep.Tags
+		keep.SetTags(tags)
+		if _, err = tx.Exec("UPDATE tasks SET tags = ? WHERE id = ?", keep.Tags, keep.ID); err != nil {
+			return fmt.Errorf("failed to update tags: %w", err)
+		}
+		if err = r.recordHistoryWith(tx, now, keep.ID, ActionEdit, "tags", before, keep.Tags); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range children {
+		parent := &keep.ID
+		if child.ID == keep.ID {
+			// The kept task takes the duplicate's place in the hierarchy
+			parent = duplicate.Parent
+		}
+		if _, err = tx.Exec("UPDATE tasks SET parent = ? WHERE id = ?", parent, child.ID); err != nil {
+			return fmt.Errorf("failed to move subtask %s: %w", child.ShortHash(), err)
+		}
+		if err = r.recordHistoryWith(tx, now, child.ID, ActionEdit, "parent", duplicate.ID, derefString(parent)); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range blocked {
+		action, blocker := ActionBlock, &keep.ID
+		if task.ID == keep.ID {
+			action, blocker = ActionUnblock, nil
+		}
+		if _, err = tx.Exec("UPDATE tasks SET blocked_by = ? WHERE id = ?", blocker, task.ID); err != nil {
+			return fmt.Errorf("failed to move blocker of %s: %w", task.ShortHash(), err)
+		}
+		if err = r.recordHistoryWith(tx, now, task.ID, action, "blocked_by", duplicate.ID, derefString(blocker)); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO task_links (source_id, target_id, type, author, created)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, duplicate.ID, keep.ID, LinkDuplicates, r.actor(), database.FormatTime(now))
+	if err != nil {
+		return fmt.Errorf("failed to link duplicate: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		if err = r.recordHistoryWith(tx, now, duplicate.ID, ActionLink, LinkDuplicates, "", keep.ID); err != nil {
+			return err
+		}
+	}
+
+	if closeDuplicate {
+		if _, err = tx.Exec("UPDATE tasks SET state = ? WHERE id = ?", state, duplicate.ID); err != nil {
+			return fmt.Errorf("failed to close duplicate: %w", err)
+		}
+		if err = r.recordHistoryWith(tx, now, duplicate.ID, ActionState, "state", duplicate.State.String(), state.String()); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return nil
+}
+
+// getBlockedBy retrieves the tasks blocked by the given task
+func (r *TaskRepository) getBlockedBy(id string) ([]*Task, error) {
+	rows, err := r.db.DB.Query(`SELECT `+taskColumns+` FROM tasks WHERE blocked_by = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	return r.scanTasks(rows)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *Task
+		atLeast float64
+		below   float64
+	}{
+		{
+			name:    "identical",
+			a:       NewTask(KindBug, "Login fails on Safari", "Users cannot log in"),
+			b:       NewTask(KindBug, "Login fails on Safari", "Users cannot log in"),
+			atLeast: 1,
+			below:   1.01,
+		},
+		{
+			name:    "reworded title",
+			a:       NewTask(KindBug, "Login fails on Safari", "Users cannot log in with Safari 17"),
+			b:       NewTask(KindBug, "Safari login fails", "Users cannot log in with Safari 17"),
+			atLeast: DefaultDuplicateThreshold,
+			below:   1.01,
+		},
+		{
+			name:    "unrelated",
+			a:       NewTask(KindBug, "Login fails on Safari", "Users cannot log in"),
+			b:       NewTask(KindFeature, "Add dark mode", "Theme toggle in settings"),
+			atLeast: 0,
+			below:   0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if got < tt.atLeast || got >= tt.below {
+				t.Errorf("Similarity() = %v, want in [%v, %v)", got, tt.atLeast, tt.below)
+			}
+		})
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	a := NewTask(KindBug, "Login fails on Safari", "Users cannot log in")
+	b := NewTask(KindBug, "Safari login fails", "Users cannot log in")
+	c := NewTask(KindFeature, "Add dark mode", "Theme toggle in settings")
+	d := NewTask(KindFeature, "Add a dark mode", "Theme toggle")
+
+	clusters := FindDuplicates([]*Task{a, c, b, d}, DefaultDuplicateThreshold)
+	if len(clusters) != 2 {
+		t.Fatalf("FindDuplicates() = %d clusters, want 2", len(clusters))
+	}
+	if clusters[0][0] != a || clusters[0][1] != b {
+		t.Errorf("First cluster = %v, want login tasks", clusters[0])
+	}
+	if clusters[1][0] != c || clusters[1][1] != d {
+		t.Errorf("Second cluster = %v, want dark mode tasks", clusters[1])
+	}
+}
+
+func TestTaskRepository_MergeInto(t *testing.T) {
+	repo := setupTestDB(t)
+
+	keep := NewTask(KindBug, "Login fails on Safari", "Users cannot log in")
+	keep.Tags = "auth"
+	duplicate := NewTask(KindBug, "Safari login fails", "Users cannot log in")
+	duplicate.Tags = "safari,auth"
+	for _, task := range []*Task{keep, duplicate} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	child := NewTask(KindBug, "Reproduce on Safari 17", "Find a reliable repro")
+	child.Parent = &duplicate.ID
+	waiting := NewTask(KindFeature, "Passkeys", "Blocked on login")
+	for _, task := range []*Task{child, waiting} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Block(waiting.ID, duplicate.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.MergeInto(keep.ID, duplicate.ID); err != nil {
+		t.Fatalf("MergeInto() error = %v", err)
+	}
+
+	kept, _ := repo.GetByID(keep.ID)
+	if kept.Tags != "auth,safari" {
+		t.Errorf("Tags = %q, want %q", kept.Tags, "auth,safari")
+	}
+	merged, _ := repo.GetByID(duplicate.ID)
+	if merged.State != StateInvalid {
+		t.Errorf("Duplicate state = %s, want %s", merged.State, StateInvalid)
+	}
+	movedChild, _ := repo.GetByID(child.ID)
+	if movedChild.Parent == nil || *movedChild.Parent != keep.ID {
+		t.Errorf("Child parent = %v, want %s", movedChild.Parent, keep.ID)
+	}
+	movedBlock, _ := repo.GetByID(waiting.ID)
+	if movedBlock.BlockedBy == nil || *movedBlock.BlockedBy != keep.ID {
+		t.Errorf("Blocked by = %v, want %s", movedBlock.BlockedBy, keep.ID)
+	}
+
+	if err := repo.MergeInto(keep.ID, keep.ID); err == nil {
+		t.Error("Expected error merging a task into itself")
+	}
+}
+
+func TestTaskRepository_MergeIntoDescendant(t *testing.T) {
+	repo := setupTestDB(t)
+
+	create := func(title string, parent *Task) *Task {
+		task := NewTask(KindBug, title, "Users cannot log in")
+		if parent != nil {
+			task.Parent = &parent.ID
+		}
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	duplicate := create("Login fails on Safari", nil)
+	middle := create("Safari login investigation", duplicate)
+	keep := create("Safari login fails", middle)
+
+	// Moving the middle task below the kept one would make a cycle
+	if err := repo.MergeInto(keep.ID, duplicate.ID); err == nil {
+		t.Fatal("Expected merging into a nested subtask to fail")
+	}
+	unchanged, _ := repo.GetByID(duplicate.ID)
+	if unchanged.State != duplicate.State {
+		t.Errorf("Duplicate state = %s, want it unchanged", unchanged.State)
+	}
+	child, _ := repo.GetByID(middle.ID)
+	if child.Parent == nil || *child.Parent != duplicate.ID {
+		t.Errorf("Middle parent = %v, want it unchanged", child.Parent)
+	}
+
+	// A direct subtask takes the duplicate's place
+	if err := repo.MergeInto(middle.ID, duplicate.ID); err != nil {
+		t.Fatalf("MergeInto() error = %v", err)
+	}
+	moved, _ := repo.GetByID(middle.ID)
+	if moved.Parent != nil {
+		t.Errorf("Kept task parent = %v, want none", *moved.Parent)
+	}
+	links, err := repo.GetLinks(duplicate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].Type != LinkDuplicates || links[0].TargetID != middle.ID {
+		t.Errorf("Links = %v, want the duplicate linked to the kept task", links)
+	}
+}
+
+func TestTaskRepository_MergeIntoBlockerCycle(t *testing.T) {
+	repo := setupTestDB(t)
+
+	keep := NewTask(KindBug, "Login fails on Safari", "Users cannot log in")
+	duplicate := NewTask(KindBug, "Safari login fails", "Users cannot log in")
+	waiting := NewTask(KindFeature, "Passkeys", "Blocked on login")
+	for _, task := range []*Task{keep, duplicate, waiting} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// keep waits on waiting, which waits on the duplicate
+	if err := repo.Block(waiting.ID, duplicate.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Block(keep.ID, waiting.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.MergeInto(keep.ID, duplicate.ID); err == nil {
+		t.Fatal("Expected a merge making tasks block each other to fail")
+	}
+	unchanged, _ := repo.GetByID(waiting.ID)
+	if unchanged.BlockedBy == nil || *unchanged.BlockedBy != duplicate.ID {
+		t.Errorf("Blocked by = %v, want it unchanged", unchanged.BlockedBy)
+	}
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// State is the workflow state of a task, e.g. StateNew
+type State string
+
+// Kind is the type of work a task is, e.g. KindBug
+type Kind string
+
+// Priority is the urgency of a task, e.g. PriorityHigh
+type Priority string
+
+// Severity is the impact of a bug or regression, e.g. SeverityCritical,
+// separate from the priority it is scheduled with
+type Severity string
+
+// States lists every task state in workflow order
+var States = []State{StateInbox, StateNew, StateInProgress, StateDone, StateCancelled, StateInvalid}
+
+// Kinds lists every task kind
+var Kinds = []Kind{KindBug, KindFeature, KindRegression}
+
+// Priorities lists every priority, most urgent first
+var Priorities = []Priority{PriorityHigh, PriorityMedium, PriorityLow}
+
+// Severities lists every severity, most severe first
+var Severities = []Severity{SeverityCritical, SeverityMajor, SeverityMinor}
+
+// String returns the state as stored, e.g. "IN_PROGRESS"
+func (s State) String() string { return string(s) }
+
+// IsValid reports whether s is one of States
+func (s State) IsValid() bool { return slices.Contains(States, s) }
+
+// MarshalJSON writes the state as a string
+func (s State) MarshalJSON() ([]byte, error) { return json.Marshal(string(s)) }
+
+// UnmarshalJSON reads a state with ParseState, leaving "" unset
+func (s *State) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseState)
+}
+
+// ParseState parses a state in any case, accepting in-progress for
+// IN_PROGRESS
+func ParseState(value string) (State, error) {
+	state := State(strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(value)), "-", "_"))
+	if !state.IsValid() {
+		return "", errors.NewValidationError("invalid state: %s (must be %s)", state, joinEnum(States))
+	}
+	return state, nil
+}
+
+// String returns the kind as stored, e.g. "BUG"
+func (k Kind) String() string { return string(k) }
+
+// IsValid reports whether k is one of Kinds
+func (k Kind) IsValid() bool { return slices.Contains(Kinds, k) }
+
+// MarshalJSON writes the kind as a string
+func (k Kind) MarshalJSON() ([]byte, error) { return json.Marshal(string(k)) }
+
+// UnmarshalJSON reads a kind with ParseKind, leaving "" unset
+func (k *Kind) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, k, ParseKind)
+}
+
+// ParseKind parses a kind in any case, e.g. "bug"
+func ParseKind(value string) (Kind, error) {
+	kind := Kind(strings.ToUpper(strings.TrimSpace(value)))
+	if !kind.IsValid() {
+		return "", errors.NewValidationError("invalid kind: %s (must be %s)", value, strings.ToLower(joinEnum(Kinds)))
+	}
+	return kind, nil
+}
+
+// String returns the priority as stored, e.g. "high"
+func (p Priority) String() string { return string(p) }
+
+// IsValid reports whether p is one of Priorities
+func (p Priority) IsValid() bool { return slices.Contains(Priorities, p) }
+
+// MarshalJSON writes the priority as a string
+func (p Priority) MarshalJSON() ([]byte, error) { return json.Marshal(string(p)) }
+
+// UnmarshalJSON reads a priority with ParsePriority, leaving "" unset
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, p, ParsePriority)
+}
+
+// ParsePriority parses a priority in any case, e.g. "HIGH"
+func ParsePriority(value string) (Priority, error) {
+	priority := Priority(strings.ToLower(strings.TrimSpace(value)))
+	if !priority.IsValid() {
+		return "", errors.NewValidationError("invalid priority: %s (must be %s)", value, joinEnum(Priorities))
+	}
+	return priority, nil
+}
+
+// String returns the severity as stored, e.g. "critical"
+func (s Severity) String() string { return string(s) }
+
+// IsValid reports whether s is one of Severities
+func (s Severity) IsValid() bool { return slices.Contains(Severities, s) }
+
+// MarshalJSON writes the severity as a string
+func (s Severity) MarshalJSON() ([]byte, error) { return json.Marshal(string(s)) }
+
+// UnmarshalJSON reads a severity with ParseSeverity, leaving "" unset
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseSeverity)
+}
+
+// ParseSeverity parses a severity in any case, e.g. "Critical"
+func ParseSeverity(value string) (Severity, error) {
+	severity := Severity(strings.ToLower(strings.TrimSpace(value)))
+	if !severity.IsValid() {
+		return "", errors.NewValidationError("invalid severity: %s (must be %s)", value, joinEnum(Severities))
+	}
+	return severity, nil
+}
+
+// unmarshalEnum decodes a JSON string into dst with parse, so JSON input is
+// held to the same values as flags
+func unmarshalEnum[T ~string](data []byte, dst *T, parse func(string) (T, error)) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		*dst = ""
+		return nil
+	}
+	parsed, err := parse(value)
+	if err != nil {
+		return err
+	}
+	*dst = parsed
+	return nil
+}
+
+// joinEnum lists enum values for error messages, e.g. "high, medium, or low"
+func joinEnum[T ~string](values []T) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = string(value)
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", or " + names[len(names)-1]
+}
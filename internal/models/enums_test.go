@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    State
+		wantErr bool
+	}{
+		{"NEW", StateNew, false},
+		{"new", StateNew, false},
+		{" inbox ", StateInbox, false},
+		{"in-progress", StateInProgress, false},
+		{"IN_PROGRESS", StateInProgress, false},
+		{"cancelled", StateCancelled, false},
+		{"", "", true},
+		{"BLOCKED", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseState(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseState(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseState(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	_, err := ParseState("waiting")
+	if err == nil || err.Error() != "invalid state: WAITING (must be INBOX, NEW, IN_PROGRESS, DONE, CANCELLED, or INVALID)" {
+		t.Errorf("ParseState(waiting) error = %v", err)
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for input, want := range map[string]Kind{"bug": KindBug, "Feature": KindFeature, "REGRESSION": KindRegression} {
+		if got, err := ParseKind(input); err != nil || got != want {
+			t.Errorf("ParseKind(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	_, err := ParseKind("chore")
+	if err == nil || err.Error() != "invalid kind: chore (must be bug, feature, or regression)" {
+		t.Errorf("ParseKind(chore) error = %v", err)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for input, want := range map[string]Priority{"high": PriorityHigh, "Medium": PriorityMedium, "LOW": PriorityLow} {
+		if got, err := ParsePriority(input); err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	_, err := ParsePriority("urgent")
+	if err == nil || err.Error() != "invalid priority: urgent (must be high, medium, or low)" {
+		t.Errorf("ParsePriority(urgent) error = %v", err)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for input, want := range map[string]Severity{"critical": SeverityCritical, "Major": SeverityMajor, " MINOR ": SeverityMinor} {
+		if got, err := ParseSeverity(input); err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	_, err := ParseSeverity("blocker")
+	if err == nil || err.Error() != "invalid severity: blocker (must be critical, major, or minor)" {
+		t.Errorf("ParseSeverity(blocker) error = %v", err)
+	}
+}
+
+func TestEnumIsValid(t *testing.T) {
+	for _, state := range States {
+		if !state.IsValid() {
+			t.Errorf("%s.IsValid() = false", state)
+		}
+	}
+	for _, state := range []State{"", "new", "BLOCKED", "IN-PROGRESS"} {
+		if state.IsValid() {
+			t.Errorf("State(%q).IsValid() = true", state)
+		}
+	}
+	if Kind("bug").IsValid() || !KindBug.IsValid() {
+		t.Error("Kind.IsValid() should only accept stored kinds")
+	}
+	if Priority("HIGH").IsValid() || !PriorityHigh.IsValid() {
+		t.Error("Priority.IsValid() should only accept stored priorities")
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	type fields struct {
+		State    State    `json:"state"`
+		Kind     Kind     `json:"kind"`
+		Priority Priority `json:"priority"`
+	}
+
+	data, err := json.Marshal(fields{StateInProgress, KindBug, PriorityHigh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"state":"IN_PROGRESS","kind":"BUG","priority":"high"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var got fields
+	if err := json.Unmarshal([]byte(`{"state":"in-progress","kind":"bug","priority":"HIGH"}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (fields{StateInProgress, KindBug, PriorityHigh}) {
+		t.Errorf("Unmarshal() = %+v", got)
+	}
+
+	// Missing and empty values stay unset
+	got = fields{}
+	if err := json.Unmarshal([]byte(`{"state":""}`), &got); err != nil || got != (fields{}) {
+		t.Errorf("Unmarshal(empty) = %+v, %v", got, err)
+	}
+
+	for _, input := range []string{`{"state":"WAITING"}`, `{"kind":"chore"}`, `{"priority":"urgent"}`, `{"state":1}`} {
+		if err := json.Unmarshal([]byte(input), &got); err == nil {
+			t.Errorf("Unmarshal(%s) accepted an invalid value", input)
+		}
+	}
+}
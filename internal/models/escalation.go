@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// NextPriority returns the priority one level above p, or "" when p is
+// already the highest
+func NextPriority(p Priority) Priority {
+	switch p {
+	case PriorityLow:
+		return PriorityMedium
+	case PriorityMedium:
+		return PriorityHigh
+	default:
+		return ""
+	}
+}
+
+// ListStale retrieves NEW tasks below high priority that have not been
+// updated since cutoff, least recently updated first
+func (r *TaskRepository) ListStale(cutoff time.Time) ([]*Task, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE state = ? AND priority != ? AND updated < ?
+		ORDER BY updated ASC
+	`, StateNew, PriorityHigh, database.FormatTime(cutoff))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	return r.scanTasks(rows)
+}
+
+// Escalate raises the priority of a task by one level. The change is
+// recorded in the task's history like any other priority edit.
+func (r *TaskRepository) Escalate(task *Task) error {
+	next := NextPriority(task.Priority)
+	if next == "" {
+		return errors.NewConflictError("task %s already has the highest priority", task.ShortHash())
+	}
+	task.Priority = next
+	return r.Update(task)
+}
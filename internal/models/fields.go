@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// MaxFieldValueLength is the maximum length of a custom field value in
+// characters
+const MaxFieldValueLength = 1000
+
+// fieldName matches custom field names: a lowercase letter followed by
+// lowercase letters, digits, dashes, and underscores
+var fieldName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// ValidateFieldName checks that a custom field name is well-formed
+func ValidateFieldName(name string) error {
+	if !fieldName.MatchString(name) {
+		return errors.NewValidationError("invalid field name: %q (must start with a lowercase letter and contain only a-z, 0-9, - and _, up to 64 characters)", name)
+	}
+	return nil
+}
+
+// ValidateField checks that a custom field name is well-formed and its value
+// is not too long
+func ValidateField(name, value string) error {
+	if err := ValidateFieldName(name); err != nil {
+		return err
+	}
+	if n := utf8.RuneCountInString(value); n > MaxFieldValueLength {
+		return errors.NewValidationError("field %s is %d characters long, maximum is %d", name, n, MaxFieldValueLength)
+	}
+	return nil
+}
+
+// SetField sets a custom field of a task, replacing any previous value. An
+// empty value removes the field. The task counts as updated, so incremental
+// exports pick up the change.
+func (r *TaskRepository) SetField(taskID, name, value string) error {
+	if err := ValidateField(name, value); err != nil {
+		return err
+	}
+	task, err := r.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	fields, err := r.GetFields(task.ID)
+	if err != nil {
+		return err
+	}
+	old, ok := fields[name]
+	if old == value && (ok || value == "") {
+		return nil // Unchanged
+	}
+
+	if value == "" {
+		_, err = r.db.Exec("DELETE FROM task_fields WHERE task_id = ? AND name = ?", task.ID, name)
+	} else {
+		_, err = r.db.Exec(`
+			INSERT INTO task_fields (task_id, name, value) VALUES (?, ?, ?)
+			ON CONFLICT(task_id, name) DO UPDATE SET value = excluded.value
+		`, task.ID, name, value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set field %s: %w", name, err)
+	}
+	if _, err := r.db.Exec("UPDATE tasks SET updated = ? WHERE id = ?", database.FormatTime(time.Now()), task.ID); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	return r.recordHistory(task.ID, ActionField, name, old, value)
+}
+
+// GetFields returns the custom fields of a task by name, empty when it has
+// none
+func (r *TaskRepository) GetFields(taskID string) (map[string]string, error) {
+	rows, err := r.db.DB.Query("SELECT name, value FROM task_fields WHERE task_id = ?", taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fields: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	fields := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan field: %w", err)
+		}
+		fields[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return fields, nil
+}
+
+// FieldNames returns the names of fields in sorted order
+func FieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFieldFilters parses name=value filters as given to --field. A name
+// without a value matches tasks that have the field set to anything.
+func ParseFieldFilters(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	filters := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, value, _ := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if err := ValidateFieldName(name); err != nil {
+			return nil, err
+		}
+		filters[name] = value
+	}
+	return filters, nil
+}
+
+// fieldConditions returns the SQL conditions and arguments matching tasks
+// with the given fields, in the order of the field names
+func fieldConditions(fields map[string]string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, name := range FieldNames(fields) {
+		if value := fields[name]; value != "" {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM task_fields f WHERE f.task_id = tasks.id AND f.name = ? AND f.value = ?)")
+			args = append(args, name, value)
+		} else {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM task_fields f WHERE f.task_id = tasks.id AND f.name = ?)")
+			args = append(args, name)
+		}
+	}
+	return conditions, args
+}
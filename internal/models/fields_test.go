@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+func TestTaskRepository_Fields(t *testing.T) {
+	repo := setupTestDB(t)
+
+	acme := NewTask(KindBug, "Acme bug", "A bug reported by Acme")
+	other := NewTask(KindBug, "Other bug", "A bug reported by someone else")
+	for _, task := range []*Task{acme, other} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.SetField(acme.ID, "customer", "acme"); err != nil {
+		t.Fatalf("SetField() error = %v", err)
+	}
+	if err := repo.SetField(acme.ID, "severity", "critical"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetField(other.ID, "customer", "globex"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetField(acme.ID, "customer", "acme-corp"); err != nil {
+		t.Fatal(err)
+	}
+
+	fields, err := repo.GetFields(acme.ID)
+	if err != nil {
+		t.Fatalf("GetFields() error = %v", err)
+	}
+	if len(fields) != 2 || fields["customer"] != "acme-corp" || fields["severity"] != "critical" {
+		t.Errorf("GetFields() = %v", fields)
+	}
+
+	// Changes are recorded in the history
+	history, err := repo.GetHistory(acme.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	if last.Action != ActionField || last.Field != "customer" || last.OldValue != "acme" || last.NewValue != "acme-corp" {
+		t.Errorf("Last history entry = %+v", last)
+	}
+
+	// Filters match exact values, or any value without one
+	for _, tt := range []struct {
+		filter map[string]string
+		want   int
+	}{
+		{map[string]string{"customer": "acme-corp"}, 1},
+		{map[string]string{"customer": ""}, 2},
+		{map[string]string{"customer": "", "severity": "critical"}, 1},
+		{map[string]string{"customer": "initech"}, 0},
+	} {
+		tasks, err := repo.List(ListOptions{All: true, Fields: tt.filter})
+		if err != nil {
+			t.Fatalf("List(%v) error = %v", tt.filter, err)
+		}
+		if len(tasks) != tt.want {
+			t.Errorf("List(%v) returned %d tasks, want %d", tt.filter, len(tasks), tt.want)
+		}
+	}
+
+	// An empty value removes the field
+	if err := repo.SetField(acme.ID, "severity", ""); err != nil {
+		t.Fatal(err)
+	}
+	if fields, _ := repo.GetFields(acme.ID); len(fields) != 1 {
+		t.Errorf("GetFields() after removal = %v", fields)
+	}
+
+	// Fields go with their task
+	if err := repo.Delete(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if fields, _ := repo.GetFields(other.ID); len(fields) != 0 {
+		t.Errorf("GetFields() of deleted task = %v", fields)
+	}
+
+	for _, name := range []string{"", "Customer", "9lives", "has space"} {
+		if err := repo.SetField(acme.ID, name, "x"); errors.CodeOf(err) != errors.CodeValidation {
+			t.Errorf("SetField(%q) error = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestParseFieldFilters(t *testing.T) {
+	filters, err := ParseFieldFilters([]string{"customer=acme", "environment", "note=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 3 || filters["customer"] != "acme" || filters["environment"] != "" || filters["note"] != "a=b" {
+		t.Errorf("ParseFieldFilters() = %v", filters)
+	}
+
+	if _, err := ParseFieldFilters([]string{"Bad Name=x"}); err == nil {
+		t.Error("ParseFieldFilters() accepted an invalid name")
+	}
+}
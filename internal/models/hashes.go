@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// DefaultShortHashLength is the usual length of abbreviated task IDs, as in git
+const DefaultShortHashLength = 7
+
+// maxIDAttempts bounds how often Create generates a new ID for a task whose
+// ID is already taken
+const maxIDAttempts = 5
+
+var (
+	// shortHashLength is the configured length of abbreviated task IDs
+	shortHashLength = DefaultShortHashLength
+	// uniqueHashLength is the shortest length that tells the tasks apart
+	uniqueHashLength = 0
+)
+
+// SetShortHashLength sets the length task IDs are abbreviated to, and
+// returns the previous length so it can be restored. IDs are abbreviated to
+// more characters when that's needed to tell tasks apart.
+func SetShortHashLength(length int) int {
+	previous := shortHashLength
+	shortHashLength = length
+	return previous
+}
+
+// ShortID abbreviates a task ID so that it still identifies one task
+func ShortID(id string) string {
+	if length := max(shortHashLength, uniqueHashLength); len(id) > length {
+		return id[:length]
+	}
+	return id
+}
+
+// uniquePrefixLength returns the length of the shortest prefix of a that
+// isn't also a prefix of b
+func uniquePrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n + 1
+}
+
+// updateShortHashLength finds the shortest length of abbreviated IDs that
+// keeps every task's abbreviation unambiguous
+func (r *TaskRepository) updateShortHashLength() error {
+	rows, err := r.db.DB.Query("SELECT id FROM tasks ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("failed to read task IDs: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logRowsCloseError(err)
+		}
+	}()
+
+	// Sorted, only neighboring IDs can share the longest prefixes
+	length := 0
+	var previous string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to read task IDs: %w", err)
+		}
+		if previous != "" {
+			length = max(length, uniquePrefixLength(previous, id))
+		}
+		previous = id
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read task IDs: %w", err)
+	}
+	uniqueHashLength = length
+	return nil
+}
+
+// raiseShortHashLength lengthens abbreviated IDs if a new task's ID shares
+// a longer prefix with one of its neighbors than they currently show
+func (r *TaskRepository) raiseShortHashLength(id string) error {
+	for _, query := range []string{
+		"SELECT id FROM tasks WHERE id < ? ORDER BY id DESC LIMIT 1",
+		"SELECT id FROM tasks WHERE id > ? ORDER BY id LIMIT 1",
+	} {
+		var neighbor string
+		err := r.db.DB.QueryRow(query, id).Scan(&neighbor)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read task IDs: %w", err)
+		}
+		uniqueHashLength = max(uniqueHashLength, uniquePrefixLength(id, neighbor))
+	}
+	return nil
+}
+
+// queryRower runs single-row queries, on the database or in a transaction
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ensureUniqueID gives a new task a fresh ID in the rare case that its ID
+// is already taken
+func (r *TaskRepository) ensureUniqueID(q queryRower, task *Task) error {
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		var exists bool
+		if err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM tasks WHERE id = ?)", task.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check task ID: %w", err)
+		}
+		if !exists {
+			return nil
+		}
+		task.ID = generateTaskHash(task.Kind, task.Title, task.Description, task.Created)
+	}
+	return errors.NewConflictError("could not generate a unique ID for task %q", task.Title)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUniquePrefixLength(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"abc", "xyz", 1},
+		{"abcdef", "abcxyz", 4},
+		{"abc", "abc", 4},
+	}
+	for _, tt := range tests {
+		if got := uniquePrefixLength(tt.a, tt.b); got != tt.want {
+			t.Errorf("uniquePrefixLength(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTaskRepository_CreateIDCollision(t *testing.T) {
+	repo := setupTestDB(t)
+
+	first := NewTask(KindBug, "First", "First task")
+	if err := repo.Create(first); err != nil {
+		t.Fatal(err)
+	}
+	second := NewTask(KindBug, "Second", "Second task")
+	second.ID = first.ID
+	if err := repo.Create(second); err != nil {
+		t.Fatalf("Create() with a taken ID error = %v", err)
+	}
+	if second.ID == first.ID || len(second.ID) != 40 {
+		t.Errorf("Expected a new ID, got %s", second.ID)
+	}
+	if _, err := repo.GetByID(second.ID); err != nil {
+		t.Errorf("Task not stored under its new ID: %v", err)
+	}
+}
+
+func TestShortHashLength(t *testing.T) {
+	t.Cleanup(func() { uniqueHashLength = 0 })
+	repo := setupTestDB(t)
+
+	first := NewTask(KindBug, "First", "First task")
+	first.ID = "abcdef0" + strings.Repeat("1", 33)
+	if err := repo.Create(first); err != nil {
+		t.Fatal(err)
+	}
+	if got := first.ShortHash(); got != "abcdef0" {
+		t.Errorf("ShortHash() = %q, want the default length", got)
+	}
+
+	// Shares the first 9 characters, so 10 are needed to tell them apart
+	second := NewTask(KindBug, "Second", "Second task")
+	second.ID = "abcdef011" + strings.Repeat("2", 31)
+	if err := repo.Create(second); err != nil {
+		t.Fatal(err)
+	}
+	if got := first.ShortHash(); got != "abcdef0111" {
+		t.Errorf("ShortHash() = %q, want 10 characters", got)
+	}
+	if task, err := repo.GetByID(second.ShortHash()); err != nil || task.ID != second.ID {
+		t.Errorf("GetByID(%q) = %v, %v", second.ShortHash(), task, err)
+	}
+
+	// Opening the database again computes the same length
+	uniqueHashLength = 0
+	if err := repo.updateShortHashLength(); err != nil {
+		t.Fatal(err)
+	}
+	if uniqueHashLength != 10 {
+		t.Errorf("uniqueHashLength = %d after reopening, want 10", uniqueHashLength)
+	}
+
+	// A longer configured length applies, a shorter one doesn't
+	previous := SetShortHashLength(12)
+	defer SetShortHashLength(previous)
+	if got := first.ShortHash(); got != "abcdef011111" {
+		t.Errorf("ShortHash() = %q, want 12 characters", got)
+	}
+	SetShortHashLength(5)
+	if got := first.ShortHash(); got != "abcdef0111" {
+		t.Errorf("ShortHash() = %q, want 10 characters", got)
+	}
+}
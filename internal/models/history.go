@@ -0,0 +1,267 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/git"
+)
+
+// History actions
+const (
+	ActionCreate  = "create"
+	ActionState   = "state"
+	ActionEdit    = "edit"
+	ActionBlock   = "block"
+	ActionUnblock = "unblock"
+	ActionAttach  = "attach"
+	ActionDetach  = "detach"
+	ActionLink    = "link"
+	ActionUnlink  = "unlink"
+	ActionReason  = "reason"
+	ActionMove    = "move"
+	ActionPin     = "pin"
+	ActionUnpin   = "unpin"
+	ActionField   = "field"
+)
+
+// HistoryEntry records a single change made to a task
+type HistoryEntry struct {
+	ID       int64     `json:"id"`
+	TaskID   string    `json:"task_id"`
+	Author   string    `json:"author"`
+	Action   string    `json:"action"`
+	Field    string    `json:"field,omitempty"`
+	OldValue string    `json:"old_value,omitempty"`
+	NewValue string    `json:"new_value,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// actor returns the author to record for changes made through this repository
+func (r *TaskRepository) actor() string {
+	return r.author
+}
+
+// gitAuthor returns the configured git author, or the same default used for
+// new tasks when there is none
+func gitAuthor() string {
+	author, err := git.GetAuthor()
+	if err != nil {
+		return "Unknown <unknown@example.com>"
+	}
+	return author
+}
+
+// recordHistory appends an entry to a task's change history
+func (r *TaskRepository) recordHistory(taskID, action, field, oldValue, newValue string) error {
+	return r.recordHistoryWith(r.db, time.Now(), taskID, action, field, oldValue, newValue)
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordHistoryWith appends a history entry made at the given time through ex,
+// so changes made in a transaction are recorded in the same transaction
+func (r *TaskRepository) recordHistoryWith(ex execer, at time.Time, taskID, action, field, oldValue, newValue string) error {
+	_, err := ex.Exec(`
+		INSERT INTO task_history (task_id, author, action, field, old_value, new_value, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, taskID, r.actor(), action, field, oldValue, newValue, database.FormatTime(at))
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// recordChanges records one history entry per field that differs between two versions of a task
+func (r *TaskRepository) recordChanges(before, after *Task) error {
+	for _, change := range taskChanges(before, after) {
+		if err := r.recordHistory(after.ID, change.Action, change.Field, change.OldValue, change.NewValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskChanges returns the history entries for the fields that differ
+// between two versions of a task, without author and time
+func taskChanges(before, after *Task) []*HistoryEntry {
+	var entries []*HistoryEntry
+	add := func(action, field, from, to string) {
+		entries = append(entries, &HistoryEntry{TaskID: after.ID, Action: action, Field: field, OldValue: from, NewValue: to})
+	}
+
+	changes := []struct {
+		field    string
+		from, to string
+	}{
+		{"parent", derefString(before.Parent), derefString(after.Parent)},
+		{"priority", before.Priority.String(), after.Priority.String()},
+		{"kind", before.Kind.String(), after.Kind.String()},
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"source", before.Source, after.Source},
+		{"tags", before.Tags, after.Tags},
+		{"external_ref", before.ExternalRef, after.ExternalRef},
+		{"severity", before.Severity.String(), after.Severity.String()},
+		{"introduced_in", before.IntroducedIn, after.IntroducedIn},
+		{"environment", before.Environment, after.Environment},
+	}
+
+	if before.State != after.State {
+		add(ActionState, "state", before.State.String(), after.State.String())
+	}
+	if from, to := derefString(before.BlockedBy), derefString(after.BlockedBy); from != to {
+		action := ActionBlock
+		if to == "" {
+			action = ActionUnblock
+		}
+		add(action, "blocked_by", from, to)
+	}
+	for _, change := range changes {
+		if change.from != change.to {
+			add(ActionEdit, change.field, change.from, change.to)
+		}
+	}
+	return entries
+}
+
+// TaskChanged reports whether updating before to after changes any field
+// recorded in the history
+func TaskChanged(before, after *Task) bool {
+	return len(taskChanges(before, after)) > 0
+}
+
+// RecordReason records why a task was cancelled or rejected
+func (r *TaskRepository) RecordReason(taskID, reason string) error {
+	return r.recordHistory(taskID, ActionReason, "reason", "", reason)
+}
+
+// GetReason retrieves the most recently recorded reason for a task, or ""
+// when none was given
+func (r *TaskRepository) GetReason(taskID string) (string, error) {
+	var reason string
+	err := r.db.DB.QueryRow(`
+		SELECT COALESCE(new_value, '')
+		FROM task_history
+		WHERE task_id = ? AND action = ?
+		ORDER BY created DESC, id DESC
+		LIMIT 1
+	`, taskID, ActionReason).Scan(&reason)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get reason: %w", err)
+	}
+	return reason, nil
+}
+
+// historyColumns is the column list selected by history queries, in scanHistory order
+const historyColumns = `id, task_id, author, action, COALESCE(field, ''),
+		       COALESCE(old_value, ''), COALESCE(new_value, ''), created`
+
+// GetHistory retrieves the change history of a task, oldest first
+func (r *TaskRepository) GetHistory(taskID string) ([]*HistoryEntry, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+historyColumns+`
+		FROM task_history
+		WHERE task_id = ?
+		ORDER BY created ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	return scanHistory(rows)
+}
+
+// ListHistory retrieves changes to all tasks made at or after since, oldest first
+func (r *TaskRepository) ListHistory(since time.Time) ([]*HistoryEntry, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+historyColumns+`
+		FROM task_history
+		WHERE created >= ?
+		ORDER BY created ASC, id ASC
+	`, database.FormatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	return scanHistory(rows)
+}
+
+// Tombstone records the deletion of a task
+type Tombstone struct {
+	ID      string    `json:"id"`
+	Deleted time.Time `json:"deleted_at"`
+}
+
+// ListDeletedSince retrieves tombstones for tasks deleted at or after since
+func (r *TaskRepository) ListDeletedSince(since time.Time) ([]*Tombstone, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, deleted FROM deleted_tasks
+		WHERE deleted >= ?
+		ORDER BY deleted ASC
+	`, database.FormatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	var tombstones []*Tombstone
+	for rows.Next() {
+		tombstone := &Tombstone{}
+		if err := rows.Scan(&tombstone.ID, &tombstone.Deleted); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted task: %w", err)
+		}
+		inLocalTime(&tombstone.Deleted)
+		tombstones = append(tombstones, tombstone)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return tombstones, nil
+}
+
+// scanHistory scans history rows selected with historyColumns and closes them
+func scanHistory(rows *sql.Rows) ([]*HistoryEntry, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	var entries []*HistoryEntry
+	for rows.Next() {
+		entry := &HistoryEntry{}
+		if err := rows.Scan(&entry.ID, &entry.TaskID, &entry.Author, &entry.Action,
+			&entry.Field, &entry.OldValue, &entry.NewValue, &entry.Created); err != nil {
+			return nil, fmt.Errorf("failed to scan history: %w", err)
+		}
+		inLocalTime(&entry.Created)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// derefString returns the pointed-to string, or "" for nil
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
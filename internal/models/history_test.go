@@ -0,0 +1,263 @@
+package models
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_History(t *testing.T) {
+	repo := setupTestDB(t)
+	repo.author = "Alice <alice@example.com>"
+
+	blocker := NewTask(KindBug, "Blocker", "Blocks the other task")
+	task := NewTask(KindFeature, "Tracked", "Task with history")
+	for _, tk := range []*Task{blocker, task} {
+		if err := repo.Create(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.UpdateState(task.ID, StateNew); err != nil {
+		t.Fatal(err)
+	}
+	repo.author = "Bob <bob@example.com>"
+	if err := repo.Block(task.ID, blocker.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Unblock(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := repo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated.Priority = PriorityHigh
+	updated.Title = "Tracked and edited"
+	if err := repo.Update(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := repo.GetHistory(task.ID)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+
+	want := []struct {
+		author, action, field, from, to string
+	}{
+		{"Alice <alice@example.com>", ActionCreate, "state", "", StateInbox.String()},
+		{"Alice <alice@example.com>", ActionState, "state", StateInbox.String(), StateNew.String()},
+		{"Bob <bob@example.com>", ActionBlock, "blocked_by", "", blocker.ID},
+		{"Bob <bob@example.com>", ActionUnblock, "blocked_by", blocker.ID, ""},
+		{"Bob <bob@example.com>", ActionEdit, "priority", PriorityMedium.String(), PriorityHigh.String()},
+		{"Bob <bob@example.com>", ActionEdit, "title", "Tracked", "Tracked and edited"},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("GetHistory() returned %d entries, want %d", len(history), len(want))
+	}
+	for i, w := range want {
+		got := history[i]
+		if got.Author != w.author || got.Action != w.action || got.Field != w.field ||
+			got.OldValue != w.from || got.NewValue != w.to {
+			t.Errorf("entry %d = %+v, want %+v", i, *got, w)
+		}
+	}
+
+	// History is removed together with the task
+	if err := repo.Delete(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	history, err = repo.GetHistory(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Errorf("GetHistory() after delete returned %d entries", len(history))
+	}
+}
+
+func TestTaskRepository_HistoryConcurrentAuthor(t *testing.T) {
+	// The author is resolved when the store is created, so concurrent
+	// requests under gtd serve don't race to set it
+	for name, store := range map[string]Store{"sqlite": setupTestDB(t), "memory": NewMemoryStore()} {
+		t.Run(name, func(t *testing.T) {
+			tasks := make([]*Task, 4)
+			for i := range tasks {
+				tasks[i] = NewTask(KindFeature, "Concurrent", "Changed concurrently")
+				if err := store.Create(tasks[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var wg sync.WaitGroup
+			for _, task := range tasks {
+				wg.Add(1)
+				go func(task *Task) {
+					defer wg.Done()
+					if err := store.UpdateStateAt(task.ID, StateNew, time.Now()); err != nil {
+						t.Error(err)
+					}
+				}(task)
+			}
+			wg.Wait()
+
+			for _, task := range tasks {
+				history, err := store.GetHistory(task.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, entry := range history {
+					if entry.Author != gitAuthor() {
+						t.Errorf("history author = %q, want %q", entry.Author, gitAuthor())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRepository_ChangedSince(t *testing.T) {
+	repo := setupTestDB(t)
+
+	kept := NewTask(KindBug, "Kept", "Task that stays")
+	removed := NewTask(KindFeature, "Removed", "Task that gets deleted")
+	for _, tk := range []*Task{kept, removed} {
+		if err := repo.Create(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Delete(removed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tasks, err := repo.List(ListOptions{All: true, UpdatedSince: past})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != kept.ID {
+		t.Errorf("List(UpdatedSince=past) = %d tasks, want only the kept task", len(tasks))
+	}
+
+	tasks, err = repo.List(ListOptions{All: true, UpdatedSince: future})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("List(UpdatedSince=future) = %d tasks, want 0", len(tasks))
+	}
+
+	deleted, err := repo.ListDeletedSince(past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != removed.ID {
+		t.Errorf("ListDeletedSince(past) = %v, want tombstone for %s", deleted, removed.ID)
+	}
+
+	deleted, err = repo.ListDeletedSince(future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("ListDeletedSince(future) = %d tombstones, want 0", len(deleted))
+	}
+}
+
+func TestTaskRepository_TimestampsStoredInUTC(t *testing.T) {
+	repo := setupTestDB(t)
+
+	task := NewTask(KindBug, "Timestamps", "Task with a known creation time")
+	task.Created = time.Date(2024, 3, 10, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	task.Updated = task.Created
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	if err := repo.db.DB.QueryRow("SELECT CAST(created AS TEXT) FROM tasks WHERE id = ?", task.ID).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored != "2024-03-10T12:30:00.000Z" {
+		t.Errorf("stored created = %s, want 2024-03-10T12:30:00.000Z", stored)
+	}
+
+	got, err := repo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Created.Equal(task.Created) {
+		t.Errorf("Created = %v, want %v", got.Created, task.Created)
+	}
+	if got.Created.Location() != time.Local {
+		t.Errorf("Created location = %v, want local time", got.Created.Location())
+	}
+}
+
+func TestTaskRepository_Escalate(t *testing.T) {
+	repo := setupTestDB(t)
+
+	accept := func(task *Task) {
+		t.Helper()
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.UpdateState(task.ID, StateNew); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stale := NewTask(KindBug, "Stale", "Untouched for a while")
+	stale.Priority = PriorityLow
+	accept(stale)
+	urgent := NewTask(KindBug, "Urgent", "Already high")
+	urgent.Priority = PriorityHigh
+	accept(urgent)
+	if err := repo.Create(NewTask(KindBug, "Inbox", "Not accepted yet")); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	fresh := NewTask(KindBug, "Fresh", "Recently updated")
+	fresh.Priority = PriorityLow
+	accept(fresh)
+
+	tasks, err := repo.ListStale(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != stale.ID {
+		t.Fatalf("ListStale() returned %d tasks, want only the stale task", len(tasks))
+	}
+
+	if err := repo.Escalate(tasks[0]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Priority != PriorityMedium {
+		t.Errorf("Priority = %s, want %s", got.Priority, PriorityMedium)
+	}
+
+	history, err := repo.GetHistory(stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	if last.Field != "priority" || last.OldValue != PriorityLow.String() || last.NewValue != PriorityMedium.String() {
+		t.Errorf("last history entry = %+v, want priority low -> medium", last)
+	}
+
+	if err := repo.Escalate(urgent); err == nil {
+		t.Error("Escalate() on a high-priority task should fail")
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+// IsImported reports whether a code comment with the given fingerprint has
+// already been imported as a task
+func (r *TaskRepository) IsImported(fingerprint string) (bool, error) {
+	var count int
+	if err := r.db.DB.QueryRow(
+		"SELECT COUNT(*) FROM imported_comments WHERE fingerprint = ?", fingerprint,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check imported comments: %w", err)
+	}
+	return count > 0, nil
+}
+
+// CreateFromComments creates the tasks imported from code comments like
+// CreateBatch, recording the comments' fingerprints in the same transaction
+// so that a comment is never remembered without its task or the reverse
+func (r *TaskRepository) CreateFromComments(tasks []*Task, fingerprints []string) error {
+	if len(tasks) != len(fingerprints) {
+		return fmt.Errorf("%d tasks for %d comment fingerprints", len(tasks), len(fingerprints))
+	}
+	return r.createBatch(tasks, func(tx *sql.Tx, i int) error {
+		return recordImportWith(tx, fingerprints[i], tasks[i].ID)
+	})
+}
+
+// recordImportWith remembers through ex that the code comment with the given
+// fingerprint was imported as a task. The record is removed when the task is
+// deleted.
+func recordImportWith(ex execer, fingerprint, taskID string) error {
+	if _, err := ex.Exec(`
+		INSERT INTO imported_comments (fingerprint, task_id, created) VALUES (?, ?, ?)
+		ON CONFLICT(fingerprint) DO UPDATE SET task_id = excluded.task_id, created = excluded.created
+	`, fingerprint, taskID, database.FormatTime(time.Now())); err != nil {
+		return fmt.Errorf("failed to record imported comment: %w", err)
+	}
+	return nil
+}
+
+// FindImportMatch returns the existing task an imported task duplicates: the
+// task with the same content hash ID or, failing that, the same external
+// reference. It returns nil if there is none.
+func (r *TaskRepository) FindImportMatch(id, externalRef string) (*Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE id = ? OR (? != '' AND external_ref = ?)
+		ORDER BY id = ? DESC, created
+		LIMIT 1
+	`
+
+	task, err := scanTask(r.db.DB.QueryRow(query, id, externalRef, externalRef, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find imported task: %w", err)
+	}
+	return task, nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Link types
+const (
+	LinkRelates    = "relates"
+	LinkDuplicates = "duplicates"
+	LinkCauses     = "causes"
+	LinkRegresses  = "regresses"
+)
+
+// LinkTypes lists the valid link types
+var LinkTypes = []string{LinkRelates, LinkDuplicates, LinkCauses, LinkRegresses}
+
+// TaskLink is a typed relationship from a source task to a target task
+type TaskLink struct {
+	ID       int64     `json:"id"`
+	SourceID string    `json:"source_id"`
+	TargetID string    `json:"target_id"`
+	Type     string    `json:"type"`
+	Author   string    `json:"author"`
+	Created  time.Time `json:"created"`
+}
+
+// OtherID returns the ID of the task at the other end of the link from taskID
+func (l *TaskLink) OtherID(taskID string) string {
+	if l.SourceID == taskID {
+		return l.TargetID
+	}
+	return l.SourceID
+}
+
+// Label describes the link as seen from taskID, e.g. "duplicates" from the
+// source task and "duplicated by" from the target task
+func (l *TaskLink) Label(taskID string) string {
+	if l.SourceID == taskID {
+		switch l.Type {
+		case LinkRelates:
+			return "relates to"
+		default:
+			return l.Type
+		}
+	}
+	switch l.Type {
+	case LinkDuplicates:
+		return "duplicated by"
+	case LinkCauses:
+		return "caused by"
+	case LinkRegresses:
+		return "regressed by"
+	default:
+		return "relates to"
+	}
+}
+
+// ValidateLinkType checks that linkType is one of LinkTypes
+func ValidateLinkType(linkType string) error {
+	for _, valid := range LinkTypes {
+		if linkType == valid {
+			return nil
+		}
+	}
+	return errors.NewValidationError("invalid link type: %s (must be %s)", linkType, strings.Join(LinkTypes, ", "))
+}
+
+// AddLink links one task to another with the given link type
+func (r *TaskRepository) AddLink(sourceID, targetID, linkType string) (*TaskLink, error) {
+	if err := ValidateLinkType(linkType); err != nil {
+		return nil, err
+	}
+	source, err := r.GetByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.GetByID(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("link target not found: %w", err)
+	}
+	if source.ID == target.ID {
+		return nil, errors.NewValidationError("cannot link task %s to itself", source.ShortHash())
+	}
+
+	// "relates" has no direction, so either direction counts as existing
+	var count int
+	if err := r.db.DB.QueryRow(`
+		SELECT COUNT(*) FROM task_links
+		WHERE type = ? AND ((source_id = ? AND target_id = ?) OR (? = ? AND source_id = ? AND target_id = ?))
+	`, linkType, source.ID, target.ID, linkType, LinkRelates, target.ID, source.ID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check links: %w", err)
+	}
+	if count > 0 {
+		label := (&TaskLink{SourceID: source.ID, Type: linkType}).Label(source.ID)
+		return nil, errors.NewConflictError("task %s already %s %s", source.ShortHash(), label, target.ShortHash())
+	}
+
+	link := &TaskLink{SourceID: source.ID, TargetID: target.ID, Type: linkType, Author: r.actor(), Created: time.Now()}
+	err = database.Retry(func() error {
+		return r.db.DB.QueryRow(`
+			INSERT INTO task_links (source_id, target_id, type, author, created)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING id
+		`, link.SourceID, link.TargetID, link.Type, link.Author,
+			database.FormatTime(link.Created)).Scan(&link.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add link: %w", err)
+	}
+
+	if err := r.recordHistory(source.ID, ActionLink, linkType, "", target.ID); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// RemoveLink removes a link between two tasks
+func (r *TaskRepository) RemoveLink(sourceID, targetID, linkType string) error {
+	if err := ValidateLinkType(linkType); err != nil {
+		return err
+	}
+	source, err := r.GetByID(sourceID)
+	if err != nil {
+		return err
+	}
+	target, err := r.GetByID(targetID)
+	if err != nil {
+		return fmt.Errorf("link target not found: %w", err)
+	}
+
+	result, err := r.db.Exec(`
+		DELETE FROM task_links
+		WHERE type = ? AND ((source_id = ? AND target_id = ?) OR (? = ? AND source_id = ? AND target_id = ?))
+	`, linkType, source.ID, target.ID, linkType, LinkRelates, target.ID, source.ID)
+	if err != nil {
+		return fmt.Errorf("failed to remove link: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return errors.NewConflictError("task %s is not linked to %s as %s", source.ShortHash(), target.ShortHash(), linkType)
+	}
+
+	return r.recordHistory(source.ID, ActionUnlink, linkType, target.ID, "")
+}
+
+// GetLinks retrieves all links from or to a task, oldest first
+func (r *TaskRepository) GetLinks(taskID string) ([]*TaskLink, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id, source_id, target_id, type, author, created
+		FROM task_links
+		WHERE source_id = ? OR target_id = ?
+		ORDER BY created ASC, id ASC
+	`, taskID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	var links []*TaskLink
+	for rows.Next() {
+		link := &TaskLink{}
+		if err := rows.Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.Author, &link.Created); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		inLocalTime(&link.Created)
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return links, nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTaskRepository_Links(t *testing.T) {
+	repo := setupTestDB(t)
+
+	original := NewTask(KindBug, "Crash on save", "Saving crashes the editor")
+	duplicate := NewTask(KindBug, "Editor crashes when saving", "Same crash")
+	related := NewTask(KindFeature, "Autosave", "Save periodically")
+	for _, task := range []*Task{original, duplicate, related} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.AddLink(duplicate.ID, original.ID, LinkDuplicates); err != nil {
+		t.Fatalf("AddLink(duplicates) error = %v", err)
+	}
+	if _, err := repo.AddLink(original.ID, related.ID, LinkRelates); err != nil {
+		t.Fatalf("AddLink(relates) error = %v", err)
+	}
+
+	errorCases := []struct {
+		name           string
+		source, target string
+		linkType       string
+	}{
+		{"invalid type", original.ID, related.ID, "blocks"},
+		{"self link", original.ID, original.ID, LinkRelates},
+		{"duplicate link", duplicate.ID, original.ID, LinkDuplicates},
+		{"relates is undirected", related.ID, original.ID, LinkRelates},
+		{"unknown target", original.ID, "ffffffff", LinkCauses},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repo.AddLink(tt.source, tt.target, tt.linkType); err == nil {
+				t.Error("AddLink() error = nil, want error")
+			}
+		})
+	}
+
+	links, err := repo.GetLinks(original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("GetLinks() = %d links, want 2", len(links))
+	}
+	if got := links[0].Label(original.ID); got != "duplicated by" {
+		t.Errorf("Label() from target = %q, want %q", got, "duplicated by")
+	}
+	if got := links[0].Label(duplicate.ID); got != "duplicates" {
+		t.Errorf("Label() from source = %q, want %q", got, "duplicates")
+	}
+	if got := links[0].OtherID(original.ID); got != duplicate.ID {
+		t.Errorf("OtherID() = %s, want %s", got, duplicate.ID)
+	}
+
+	// Undirected links can be removed from either side
+	if err := repo.RemoveLink(related.ID, original.ID, LinkRelates); err != nil {
+		t.Fatalf("RemoveLink() error = %v", err)
+	}
+	if err := repo.RemoveLink(related.ID, original.ID, LinkRelates); err == nil {
+		t.Error("Expected error removing a missing link")
+	}
+
+	// Links go away with their tasks
+	if err := repo.Delete(duplicate.ID); err != nil {
+		t.Fatal(err)
+	}
+	links, _ = repo.GetLinks(original.ID)
+	if len(links) != 0 {
+		t.Errorf("GetLinks() after delete = %d links, want 0", len(links))
+	}
+}
@@ -0,0 +1,631 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// MemoryStore is a Store that keeps tasks, and what is recorded about them,
+// in memory. It orders and filters tasks, resolves task references, and
+// records history the way TaskRepository does.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tasks  map[string]*Task
+	seq    int
+	choose ChooseFunc
+	tags   TagSettings
+	author string // acting author recorded in the history
+
+	history     []*HistoryEntry
+	links       []*TaskLink
+	attachments []*Attachment
+	worklog     []*WorklogEntry
+	fields      map[string]map[string]string // task ID -> field name -> value
+	deleted     map[string]time.Time         // task ID -> time of deletion
+	imported    map[string]string            // comment fingerprint -> task ID
+	current     string                       // ID of the current task
+	recent      []string                     // IDs of the most recently listed tasks
+	lastID      int64                        // last ID given to a history entry, link, attachment, or worklog entry
+}
+
+// NewMemoryStore creates an empty in-memory task store, recording changes as
+// made by the configured git author
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		author:   gitAuthor(),
+		tasks:    make(map[string]*Task),
+		fields:   make(map[string]map[string]string),
+		deleted:  make(map[string]time.Time),
+		imported: make(map[string]string),
+	}
+}
+
+// SetChooser installs a function used to pick a task when a reference is
+// ambiguous. Without one, ambiguous prefixes return an AmbiguousTaskError.
+func (m *MemoryStore) SetChooser(choose ChooseFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.choose = choose
+}
+
+// SetTagSettings sets the per-tag settings enforced when tasks are started
+func (m *MemoryStore) SetTagSettings(settings TagSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags = settings
+}
+
+// Create adds a new task, assigning its sequential number
+func (m *MemoryStore) Create(task *Task) error {
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ensureUniqueID(task); err != nil {
+		return err
+	}
+	m.insert(task)
+	return nil
+}
+
+// ensureUniqueID gives a task whose ID is taken a new one, like
+// TaskRepository does; the caller holds the lock
+func (m *MemoryStore) ensureUniqueID(task *Task) error {
+	for attempt := 0; m.tasks[task.ID] != nil; attempt++ {
+		if attempt == maxIDAttempts {
+			return errors.NewConflictError("could not generate a unique ID for task %q", task.Title)
+		}
+		task.ID = generateTaskHash(task.Kind, task.Title, task.Description, task.Created)
+	}
+	return nil
+}
+
+// insert stores a new task, assigning its sequential number; the caller
+// holds the lock
+func (m *MemoryStore) insert(task *Task) {
+	m.seq++
+	task.Seq = m.seq
+	m.tasks[task.ID] = copyTask(task)
+	m.record(task.Created, task.ID, ActionCreate, "state", "", task.State.String())
+}
+
+// GetByID retrieves a task by its ID, a hash prefix, an alias, or part of
+// its title
+func (m *MemoryStore) GetByID(id string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return copyTask(task), nil
+}
+
+// get resolves a task reference like TaskRepository.GetByID, returning the
+// stored task; the caller holds the lock
+func (m *MemoryStore) get(id string) (*Task, error) {
+	if IsTaskAlias(id) {
+		return resolveAlias(memoryAliases{m}, id)
+	}
+	if task, ok := m.tasks[id]; ok {
+		return task, nil
+	}
+
+	var matches []*Task
+	if len(id) >= MinHashPrefixLength && len(id) < 40 && isHexString(id) {
+		for _, task := range m.tasks {
+			if strings.HasPrefix(task.ID, id) {
+				matches = append(matches, task)
+			}
+		}
+		switch {
+		case len(matches) == 1:
+			return matches[0], nil
+		case len(matches) > 1 && m.choose != nil:
+			return m.chooseFrom(id, matches)
+		case len(matches) > 1:
+			return nil, errors.NewAmbiguousTaskError(id, errorTasks(matches))
+		}
+	}
+
+	for _, task := range m.tasks {
+		if strings.Contains(strings.ToLower(task.Title), strings.ToLower(id)) {
+			matches = append(matches, task)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1 && m.choose != nil:
+		return m.chooseFrom(id, matches)
+	case len(matches) > 1:
+		return nil, errors.NewTaskNotFoundError(id, errorTasks(matches))
+	}
+
+	return nil, errors.NewTaskNotFoundError(id, errorTasks(m.all()))
+}
+
+// LookupID retrieves a task only by an alias, its full ID, or a hash prefix
+// matching no other task, like TaskRepository.LookupID
+func (m *MemoryStore) LookupID(id string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return copyTask(task), nil
+}
+
+// lookup resolves a reference like LookupID, returning the stored task; the
+// caller holds the lock
+func (m *MemoryStore) lookup(id string) (*Task, error) {
+	if IsTaskAlias(id) {
+		return resolveAlias(idLookup{memoryAliases{m}, m.lookup}, id)
+	}
+	if task, ok := m.tasks[id]; ok {
+		return task, nil
+	}
+	if len(id) >= MinHashPrefixLength && len(id) < 40 && isHexString(id) {
+		matches := m.filter(func(task *Task) bool { return strings.HasPrefix(task.ID, id) })
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+		if len(matches) > 1 {
+			sortNewestFirst(matches)
+			return nil, errors.NewAmbiguousTaskError(id, errorTasks(matches))
+		}
+	}
+	return nil, errors.NewTaskNotFoundError(id, nil)
+}
+
+// chooseFrom lets the chooser pick one of several stored tasks matching a
+// reference, newest first; the caller holds the lock
+func (m *MemoryStore) chooseFrom(ref string, matches []*Task) (*Task, error) {
+	sortNewestFirst(matches)
+	chosen, err := m.choose(ref, copyTasks(matches))
+	if err != nil {
+		return nil, err
+	}
+	return m.tasks[chosen.ID], nil
+}
+
+// Update replaces an existing task
+func (m *MemoryStore) Update(task *Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	before, ok := m.tasks[task.ID]
+	if !ok {
+		return fmt.Errorf("failed to update task: %w", errors.NewNotFoundError("task not found"))
+	}
+
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	now := time.Now()
+	updated := copyTask(task)
+	updated.Seq, updated.Rank, updated.Pinned = before.Seq, before.Rank, before.Pinned
+	updated.Created, updated.Updated = before.Created, now
+	m.tasks[task.ID] = updated
+	for _, change := range taskChanges(before, updated) {
+		m.record(now, task.ID, change.Action, change.Field, change.OldValue, change.NewValue)
+	}
+	return nil
+}
+
+// Delete removes a task along with everything recorded about it, leaving a
+// tombstone. Like the SQLite store, it refuses to delete a task that is the
+// parent or blocker of another.
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, task := range m.tasks {
+		if derefString(task.Parent) == id || derefString(task.BlockedBy) == id {
+			return fmt.Errorf("failed to delete task: task %s still references it", task.ShortHash())
+		}
+	}
+	if _, ok := m.tasks[id]; !ok {
+		return nil
+	}
+
+	delete(m.tasks, id)
+	delete(m.fields, id)
+	m.deleted[id] = time.Now()
+	m.history = slices.DeleteFunc(m.history, func(entry *HistoryEntry) bool { return entry.TaskID == id })
+	m.links = slices.DeleteFunc(m.links, func(link *TaskLink) bool { return link.SourceID == id || link.TargetID == id })
+	m.attachments = slices.DeleteFunc(m.attachments, func(attachment *Attachment) bool { return attachment.TaskID == id })
+	m.worklog = slices.DeleteFunc(m.worklog, func(entry *WorklogEntry) bool { return entry.TaskID == id })
+	for fingerprint, taskID := range m.imported {
+		if taskID == id {
+			delete(m.imported, fingerprint)
+		}
+	}
+	return nil
+}
+
+// UpdateStateAt changes the state of a task, recording the change in the
+// history as made at the given time. Backdated changes must fall between the
+// task's creation and now.
+func (m *MemoryStore) UpdateStateAt(id string, newState State, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.checkTransition(id, newState, at)
+	if err != nil {
+		return err
+	}
+	m.record(at, task.ID, ActionState, "state", task.State.String(), newState.String())
+	task.State = newState
+	task.Updated = time.Now()
+	return nil
+}
+
+// CheckTransition returns the task if UpdateStateAt could move it to
+// newState at the given time, and the error UpdateStateAt would fail with if
+// not, without changing anything
+func (m *MemoryStore) CheckTransition(id string, newState State, at time.Time) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.checkTransition(id, newState, at)
+	if err != nil {
+		return nil, err
+	}
+	return copyTask(task), nil
+}
+
+// checkTransition implements CheckTransition, returning the stored task; the
+// caller holds the lock
+func (m *MemoryStore) checkTransition(id string, newState State, at time.Time) (*Task, error) {
+	task, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if at.After(time.Now()) {
+		return nil, errors.NewValidationError("cannot change state in the future (%s)", at.Format("2006-01-02 15:04"))
+	}
+	if at.Before(task.Created) {
+		return nil, errors.NewValidationError("cannot change state before the task was created (%s)", task.Created.Format("2006-01-02 15:04"))
+	}
+
+	children := m.children(task.ID)
+	if !task.CanTransitionTo(newState, children) {
+		return nil, transitionError(task, newState, children)
+	}
+	if newState == StateInProgress {
+		inProgress := m.filter(func(task *Task) bool { return task.State == StateInProgress })
+		if err := m.tags.checkWIPLimits(task, inProgress); err != nil {
+			return nil, err
+		}
+	}
+	return task, nil
+}
+
+// TransitionSubtree moves a task and its descendants to newState, following
+// the same rules as TaskRepository.TransitionSubtree
+func (m *MemoryStore) TransitionSubtree(id string, newState State) (changed []*Task, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	root, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plan on copies so that nothing changes when the root can't transition
+	plan, from, err := planSubtreeTransition(copyTask(root), newState, func(id string) ([]*Task, error) {
+		return copyTasks(m.children(id)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, task := range plan {
+		stored := m.tasks[task.ID]
+		stored.State = newState
+		stored.Updated = now
+		m.record(now, task.ID, ActionState, "state", from[i].String(), newState.String())
+	}
+	return plan[:len(plan)-1], nil
+}
+
+// PlanSubtreeTransition returns the descendants TransitionSubtree would
+// transition, in the order it would change them, without changing anything
+func (m *MemoryStore) PlanSubtreeTransition(id string, newState State) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	root, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, _, err := planSubtreeTransition(copyTask(root), newState, func(id string) ([]*Task, error) {
+		return copyTasks(m.children(id)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan[:len(plan)-1], nil
+}
+
+// Block sets a task as blocked by another task
+func (m *MemoryStore) Block(taskID, blockingTaskID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return fmt.Errorf("task to block not found: %w", err)
+	}
+	blockingTask, err := m.get(blockingTaskID)
+	if err != nil {
+		return fmt.Errorf("blocking task not found: %w", err)
+	}
+
+	now := time.Now()
+	m.record(now, task.ID, ActionBlock, "blocked_by", derefString(task.BlockedBy), blockingTask.ID)
+	blocker := blockingTask.ID
+	task.BlockedBy = &blocker
+	task.Updated = now
+	return nil
+}
+
+// Unblock removes the blocking relationship from a task
+func (m *MemoryStore) Unblock(taskID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return err
+	}
+	if !task.IsBlocked() {
+		return nil
+	}
+	now := time.Now()
+	m.record(now, task.ID, ActionUnblock, "blocked_by", *task.BlockedBy, "")
+	task.BlockedBy = nil
+	task.Updated = now
+	return nil
+}
+
+// GetChildren retrieves all child tasks of a parent
+func (m *MemoryStore) GetChildren(parentID string) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyTasks(m.children(parentID)), nil
+}
+
+// children returns the children of a task in TaskRepository.GetChildren's
+// order; the caller holds the lock
+func (m *MemoryStore) children(parentID string) []*Task {
+	children := m.filter(func(task *Task) bool { return derefString(task.Parent) == parentID })
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].Priority != children[j].Priority {
+			return children[i].Priority > children[j].Priority
+		}
+		return children[i].Created.Before(children[j].Created)
+	})
+	return children
+}
+
+// GetBlockedTasks retrieves the tasks blocked by the given task, oldest first
+func (m *MemoryStore) GetBlockedTasks(blockerID string) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tasks := m.filter(func(task *Task) bool { return derefString(task.BlockedBy) == blockerID })
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Created.Before(tasks[j].Created) })
+	return copyTasks(tasks), nil
+}
+
+// List retrieves tasks based on the given options, in the same order as
+// TaskRepository.List
+func (m *MemoryStore) List(opts ListOptions) ([]*Task, error) {
+	excluded := map[State]bool{
+		StateDone:      !opts.ShowDone,
+		StateCancelled: !opts.ShowCancelled,
+		StateInbox:     !opts.All,
+		StateInvalid:   !opts.All,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tasks := m.filter(func(task *Task) bool {
+		switch {
+		case opts.State == "" && len(opts.States) == 0 && excluded[task.State]:
+			return false
+		case opts.State != "" && task.State != opts.State:
+			return false
+		case len(opts.States) > 0 && !slices.Contains(opts.States, task.State):
+			return false
+		case opts.Priority != "" && task.Priority != opts.Priority:
+			return false
+		case opts.Kind != "" && task.Kind != opts.Kind:
+			return false
+		case opts.Severity != "" && task.Severity != opts.Severity:
+			return false
+		case opts.IntroducedIn != "" && task.IntroducedIn != opts.IntroducedIn:
+			return false
+		case opts.Environment != "" && task.Environment != opts.Environment:
+			return false
+		case opts.Tag != "" && !m.hasTag(task, opts.Tag, opts.IncludeChildren):
+			return false
+		case opts.Blocked && task.BlockedBy == nil:
+			return false
+		case !opts.UpdatedSince.IsZero() && task.Created.Before(opts.UpdatedSince) && task.Updated.Before(opts.UpdatedSince):
+			return false
+		case !m.hasFields(task, opts.Fields):
+			return false
+		}
+		return true
+	})
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch {
+		case a.Pinned != b.Pinned:
+			return a.Pinned
+		case listStateOrder(a.State) != listStateOrder(b.State):
+			return listStateOrder(a.State) < listStateOrder(b.State)
+		case listPriorityOrder(a.Priority) != listPriorityOrder(b.Priority):
+			return listPriorityOrder(a.Priority) < listPriorityOrder(b.Priority)
+		case (a.Rank == 0) != (b.Rank == 0):
+			return b.Rank == 0
+		case a.Rank != b.Rank:
+			return a.Rank < b.Rank
+		}
+		return a.Created.After(b.Created)
+	})
+
+	if !opts.All && opts.Limit > 0 && len(tasks) > opts.Limit {
+		tasks = tasks[:opts.Limit]
+	}
+	return copyTasks(tasks), nil
+}
+
+// ListByState retrieves all tasks with a specific state, newest first
+func (m *MemoryStore) ListByState(state State) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tasks := m.filter(func(task *Task) bool { return task.State == state })
+	sortNewestFirst(tasks)
+	return copyTasks(tasks), nil
+}
+
+// Search finds tasks by searching in the given fields, title and description
+// by default, best matches first
+func (m *MemoryStore) Search(query string, fields ...string) ([]*Task, error) {
+	if len(fields) == 0 {
+		fields = DefaultSearchFields
+	}
+	if _, err := searchColumns(fields); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lowerQuery := strings.ToLower(query)
+	tasks := m.filter(func(task *Task) bool {
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(SearchFieldValue(task, field)), lowerQuery) {
+				return true
+			}
+		}
+		return false
+	})
+	sortNewestFirst(tasks)
+	tasks = copyTasks(tasks)
+	rankSearchResults(tasks, query, fields)
+	return tasks, nil
+}
+
+// all returns every stored task; the caller holds the lock
+func (m *MemoryStore) all() []*Task {
+	return m.filter(func(*Task) bool { return true })
+}
+
+// hasTag reports whether the tags of task contain tag, or with inherited,
+// those of one of its ancestors; the caller holds the lock
+func (m *MemoryStore) hasTag(task *Task, tag string, inherited bool) bool {
+	seen := make(map[string]bool)
+	for task != nil && !seen[task.ID] {
+		if strings.Contains(strings.ToLower(task.Tags), strings.ToLower(tag)) {
+			return true
+		}
+		if !inherited || task.Parent == nil {
+			return false
+		}
+		seen[task.ID] = true
+		task = m.tasks[*task.Parent]
+	}
+	return false
+}
+
+// hasFields reports whether task has the given custom field values, where
+// an empty value matches any value; the caller holds the lock
+func (m *MemoryStore) hasFields(task *Task, fields map[string]string) bool {
+	for name, value := range fields {
+		got, ok := m.fields[task.ID][name]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the stored tasks that match, in no particular order; the
+// caller holds the lock
+func (m *MemoryStore) filter(match func(task *Task) bool) []*Task {
+	var tasks []*Task
+	for _, task := range m.tasks {
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// listStateOrder ranks states the way List sorts them
+func listStateOrder(state State) int {
+	switch state {
+	case StateInProgress:
+		return 0
+	case StateNew:
+		return 1
+	}
+	return 2
+}
+
+// listPriorityOrder ranks priorities the way List sorts them
+func listPriorityOrder(priority Priority) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	}
+	return 2
+}
+
+// sortNewestFirst sorts tasks by creation time, newest first
+func sortNewestFirst(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Created.After(tasks[j].Created) })
+}
+
+// errorTasks converts tasks for error suggestions
+func errorTasks(tasks []*Task) []errors.Task {
+	result := make([]errors.Task, len(tasks))
+	for i, task := range tasks {
+		result[i] = task
+	}
+	return result
+}
+
+// copyTask returns a copy of a task that shares nothing with it
+func copyTask(task *Task) *Task {
+	c := *task
+	if task.Parent != nil {
+		parent := *task.Parent
+		c.Parent = &parent
+	}
+	if task.BlockedBy != nil {
+		blockedBy := *task.BlockedBy
+		c.BlockedBy = &blockedBy
+	}
+	return &c
+}
+
+// copyTasks copies every task in a list
+func copyTasks(tasks []*Task) []*Task {
+	copies := make([]*Task, len(tasks))
+	for i, task := range tasks {
+		copies[i] = copyTask(task)
+	}
+	return copies
+}
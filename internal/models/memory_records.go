@@ -0,0 +1,850 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// actor returns the author to record for changes made through this store
+func (m *MemoryStore) actor() string {
+	return m.author
+}
+
+// nextID returns the ID for a new history entry, link, attachment, or
+// worklog entry; the caller holds the lock
+func (m *MemoryStore) nextID() int64 {
+	m.lastID++
+	return m.lastID
+}
+
+// record appends an entry to a task's change history; the caller holds the
+// lock
+func (m *MemoryStore) record(at time.Time, taskID, action, field, oldValue, newValue string) {
+	m.history = append(m.history, &HistoryEntry{
+		ID: m.nextID(), TaskID: taskID, Author: m.actor(), Action: action,
+		Field: field, OldValue: oldValue, NewValue: newValue, Created: at,
+	})
+}
+
+// CreateBatch creates many tasks at once. Either all tasks are created or
+// none are.
+func (m *MemoryStore) CreateBatch(tasks []*Task) error {
+	return m.createBatch(tasks, nil)
+}
+
+// CreateFromComments creates the tasks imported from code comments like
+// CreateBatch, remembering the comments' fingerprints
+func (m *MemoryStore) CreateFromComments(tasks []*Task, fingerprints []string) error {
+	if len(tasks) != len(fingerprints) {
+		return fmt.Errorf("%d tasks for %d comment fingerprints", len(tasks), len(fingerprints))
+	}
+	return m.createBatch(tasks, fingerprints)
+}
+
+// createBatch creates tasks like CreateBatch, remembering the fingerprint of
+// each task's code comment when fingerprints is set
+func (m *MemoryStore) createBatch(tasks []*Task, fingerprints []string) error {
+	for _, task := range tasks {
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("validation failed for %q: %w", task.Title, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Pick every ID before storing anything, so tasks earlier in the batch
+	// count and a failure leaves the store unchanged
+	taken := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		for attempt := 0; m.tasks[task.ID] != nil || taken[task.ID]; attempt++ {
+			if attempt == maxIDAttempts {
+				return errors.NewConflictError("could not generate a unique ID for task %q", task.Title)
+			}
+			task.ID = generateTaskHash(task.Kind, task.Title, task.Description, task.Created)
+		}
+		taken[task.ID] = true
+	}
+
+	for i, task := range tasks {
+		m.insert(task)
+		if fingerprints != nil {
+			m.imported[fingerprints[i]] = task.ID
+		}
+	}
+	return nil
+}
+
+// IsImported reports whether a code comment with the given fingerprint has
+// already been imported as a task
+func (m *MemoryStore) IsImported(fingerprint string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.imported[fingerprint]
+	return ok, nil
+}
+
+// FindImportMatch returns the existing task an imported task duplicates: the
+// task with the same content hash ID or, failing that, the oldest with the
+// same external reference. It returns nil if there is none.
+func (m *MemoryStore) FindImportMatch(id, externalRef string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if task, ok := m.tasks[id]; ok {
+		return copyTask(task), nil
+	}
+	if externalRef == "" {
+		return nil, nil
+	}
+	matches := m.filter(func(task *Task) bool { return task.ExternalRef == externalRef })
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sortNewestFirst(matches)
+	return copyTask(matches[len(matches)-1]), nil
+}
+
+// MergeInto folds a duplicate task into the task that is kept, following
+// the same rules as TaskRepository.MergeInto. Nothing changes when the merge
+// is refused.
+func (m *MemoryStore) MergeInto(keepID, duplicateID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keep, err := m.get(keepID)
+	if err != nil {
+		return err
+	}
+	duplicate, err := m.get(duplicateID)
+	if err != nil {
+		return err
+	}
+	if keep.ID == duplicate.ID {
+		return errors.NewValidationError("cannot merge task %s into itself", keep.ShortHash())
+	}
+
+	// A subtask of the duplicate takes its place in the hierarchy, but one
+	// further down would end up below its own subtasks
+	if derefString(keep.Parent) != duplicate.ID {
+		for _, ancestor := range m.ancestors(keep) {
+			if ancestor.ID == duplicate.ID {
+				return errors.NewValidationError("cannot merge task %s into %s, which is nested below it", duplicate.ShortHash(), keep.ShortHash())
+			}
+		}
+	}
+
+	children := m.children(duplicate.ID)
+	blocked := m.filter(func(task *Task) bool { return derefString(task.BlockedBy) == duplicate.ID })
+
+	// Tasks the kept task waits on can't wait on it in turn
+	blockers, _ := m.blockerChain(keep)
+	for _, task := range blocked {
+		for _, blocker := range blockers {
+			if blocker.ID == task.ID {
+				return errors.NewValidationError("cannot merge task %s into %s: %s would block the task it waits on", duplicate.ShortHash(), keep.ShortHash(), task.ShortHash())
+			}
+		}
+	}
+
+	state := StateCancelled
+	if duplicate.State == StateInbox {
+		state = StateInvalid
+	}
+	closeDuplicate := duplicate.State != state && duplicate.State != StateDone
+	// The duplicate has no subtasks left when it is closed
+	if closeDuplicate && !duplicate.CanTransitionTo(state, nil) {
+		return NewTransitionError(duplicate.State, state)
+	}
+	now := time.Now()
+
+	// Combine tags, keeping the order of the kept task
+	tags := keep.ParseTags()
+	for _, tag := range duplicate.ParseTags() {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != len(keep.ParseTags()) {
+		before := keep.Tags
+		keep.SetTags(tags)
+		m.record(now, keep.ID, ActionEdit, "tags", before, keep.Tags)
+	}
+
+	for _, child := range children {
+		parent := &keep.ID
+		if child.ID == keep.ID {
+			// The kept task takes the duplicate's place in the hierarchy
+			parent = duplicate.Parent
+		}
+		child.Parent = copyString(parent)
+		m.record(now, child.ID, ActionEdit, "parent", duplicate.ID, derefString(parent))
+	}
+
+	for _, task := range blocked {
+		action, blocker := ActionBlock, &keep.ID
+		if task.ID == keep.ID {
+			action, blocker = ActionUnblock, nil
+		}
+		task.BlockedBy = copyString(blocker)
+		m.record(now, task.ID, action, "blocked_by", duplicate.ID, derefString(blocker))
+	}
+
+	if m.findLink(duplicate.ID, keep.ID, LinkDuplicates) < 0 {
+		m.links = append(m.links, &TaskLink{
+			ID: m.nextID(), SourceID: duplicate.ID, TargetID: keep.ID,
+			Type: LinkDuplicates, Author: m.actor(), Created: now,
+		})
+		m.record(now, duplicate.ID, ActionLink, LinkDuplicates, "", keep.ID)
+	}
+
+	if closeDuplicate {
+		m.record(now, duplicate.ID, ActionState, "state", duplicate.State.String(), state.String())
+		duplicate.State = state
+	}
+	return nil
+}
+
+// Escalate raises the priority of a task by one level. The change is
+// recorded in the task's history like any other priority edit.
+func (m *MemoryStore) Escalate(task *Task) error {
+	next := NextPriority(task.Priority)
+	if next == "" {
+		return errors.NewConflictError("task %s already has the highest priority", task.ShortHash())
+	}
+	task.Priority = next
+	return m.Update(task)
+}
+
+// MoveToTop ranks a task first among the tasks of its priority
+func (m *MemoryStore) MoveToTop(id string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	m.moveBefore(task, nil)
+	return copyTask(task), nil
+}
+
+// MoveBefore ranks a task directly above another task of the same priority
+func (m *MemoryStore) MoveBefore(id, otherID string) (task, other *Task, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if task, err = m.get(id); err != nil {
+		return nil, nil, err
+	}
+	if other, err = m.get(otherID); err != nil {
+		return nil, nil, err
+	}
+	if task.ID == other.ID {
+		return nil, nil, errors.NewValidationError("cannot move task %s before itself", task.ShortHash())
+	}
+	if task.Priority != other.Priority {
+		return nil, nil, errors.NewValidationError("cannot move %s priority task %s before %s priority task %s: tasks are only ranked within a priority",
+			task.Priority, task.ShortHash(), other.Priority, other.ShortHash())
+	}
+	m.moveBefore(task, other)
+	return copyTask(task), copyTask(other), nil
+}
+
+// moveBefore renumbers the ranks of every task with the task's priority like
+// TaskRepository.moveBefore; the caller holds the lock
+func (m *MemoryStore) moveBefore(task, other *Task) {
+	peers := m.filter(func(t *Task) bool { return t.Priority == task.Priority && t.ID != task.ID })
+	sort.SliceStable(peers, func(i, j int) bool {
+		a, b := peers[i], peers[j]
+		switch {
+		case (a.Rank == 0) != (b.Rank == 0):
+			return b.Rank == 0
+		case a.Rank != b.Rank:
+			return a.Rank < b.Rank
+		}
+		return a.Created.After(b.Created)
+	})
+
+	var order []*Task
+	if other == nil {
+		order = append(order, task)
+	}
+	for _, peer := range peers {
+		if other != nil && peer.ID == other.ID {
+			order = append(order, task)
+		}
+		order = append(order, peer)
+	}
+	for i, t := range order {
+		t.Rank = i + 1
+	}
+
+	// The new value is the task it was moved before, empty for the top
+	before := ""
+	if other != nil {
+		before = other.ID
+	}
+	m.record(time.Now(), task.ID, ActionMove, "rank", "", before)
+}
+
+// SetPinned pins or unpins a task. Pinned tasks are listed before all others.
+func (m *MemoryStore) SetPinned(id string, pinned bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	action := ActionUnpin
+	if pinned {
+		action = ActionPin
+	}
+	task.Pinned = pinned
+	m.record(time.Now(), task.ID, action, "pinned", "", "")
+	return nil
+}
+
+// GetAncestors retrieves the parent chain of a task, root first and ending
+// with its direct parent. A chain that loops back on itself stops before
+// the first repeated task.
+func (m *MemoryStore) GetAncestors(id string) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, ok := m.tasks[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyTasks(m.ancestors(task)), nil
+}
+
+// ancestors returns the stored parent chain of a task, root first; the
+// caller holds the lock
+func (m *MemoryStore) ancestors(task *Task) []*Task {
+	var chain []*Task
+	seen := map[string]bool{task.ID: true}
+	for task.Parent != nil && !seen[*task.Parent] {
+		parent, ok := m.tasks[*task.Parent]
+		if !ok {
+			break
+		}
+		seen[parent.ID] = true
+		chain = append([]*Task{parent}, chain...)
+		task = parent
+	}
+	return chain
+}
+
+// GetBlockerChain follows the blockers of a task and returns them, nearest
+// first. cyclic reports that the chain loops back on itself; the chain then
+// stops before the first repeated task.
+func (m *MemoryStore) GetBlockerChain(id string) (chain []*Task, cyclic bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(id)
+	if err != nil {
+		return nil, false, err
+	}
+	chain, cyclic = m.blockerChain(task)
+	return copyTasks(chain), cyclic, nil
+}
+
+// blockerChain returns the stored blockers of a task, nearest first; the
+// caller holds the lock
+func (m *MemoryStore) blockerChain(task *Task) (chain []*Task, cyclic bool) {
+	seen := map[string]bool{task.ID: true}
+	for task.BlockedBy != nil {
+		if seen[*task.BlockedBy] {
+			return chain, true
+		}
+		blocker, ok := m.tasks[*task.BlockedBy]
+		if !ok {
+			break
+		}
+		seen[blocker.ID] = true
+		chain = append(chain, blocker)
+		task = blocker
+	}
+	return chain, false
+}
+
+// GetSubtaskStats counts the subtasks of each of the given tasks. Tasks
+// without subtasks are left out of the result.
+func (m *MemoryStore) GetSubtaskStats(parentIDs []string) (map[string]*SubtaskStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make(map[string]*SubtaskStats)
+	for _, id := range parentIDs {
+		children := m.children(id)
+		if len(children) == 0 {
+			continue
+		}
+		counts := &SubtaskStats{Total: len(children)}
+		for _, child := range children {
+			if child.State == StateDone {
+				counts.Done++
+			}
+		}
+		stats[id] = counts
+	}
+	return stats, nil
+}
+
+// Count returns the number of tasks List would return, ignoring the limit
+func (m *MemoryStore) Count(opts ListOptions) (int, error) {
+	opts.Limit = 0
+	tasks, err := m.List(opts)
+	return len(tasks), err
+}
+
+// Iterate calls fn for each task List would return, in the same order. It
+// stops at the first error fn returns, and when ctx is cancelled, returning
+// that error.
+func (m *MemoryStore) Iterate(ctx context.Context, opts ListOptions, fn func(*Task) error) error {
+	tasks, err := m.List(opts)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// ListStale retrieves NEW tasks below high priority that have not been
+// updated since cutoff, least recently updated first
+func (m *MemoryStore) ListStale(cutoff time.Time) ([]*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tasks := m.filter(func(task *Task) bool {
+		return task.State == StateNew && task.Priority != PriorityHigh && task.Updated.Before(cutoff)
+	})
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Updated.Before(tasks[j].Updated) })
+	return copyTasks(tasks), nil
+}
+
+// ListDeletedSince retrieves tombstones for tasks deleted at or after since
+func (m *MemoryStore) ListDeletedSince(since time.Time) ([]*Tombstone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tombstones []*Tombstone
+	for id, deleted := range m.deleted {
+		if !deleted.Before(since) {
+			tombstones = append(tombstones, &Tombstone{ID: id, Deleted: deleted})
+		}
+	}
+	sort.Slice(tombstones, func(i, j int) bool { return tombstones[i].Deleted.Before(tombstones[j].Deleted) })
+	return tombstones, nil
+}
+
+// SetCurrentTask stores the given task as the workspace's current task
+func (m *MemoryStore) SetCurrentTask(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	m.current = task.ID
+	return nil
+}
+
+// GetCurrentTask returns the workspace's current task, or nil if none is set
+func (m *MemoryStore) GetCurrentTask() (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := memoryAliases{m}.GetCurrentTask()
+	if task == nil {
+		return nil, err
+	}
+	return copyTask(task), nil
+}
+
+// ClearCurrentTask removes the workspace's current task
+func (m *MemoryStore) ClearCurrentTask() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.current = ""
+	return nil
+}
+
+// SetRecentTasks remembers the IDs of the most recently listed tasks so they
+// can be referred to as @1..@9
+func (m *MemoryStore) SetRecentTasks(tasks []*Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recent = m.recent[:0]
+	for _, task := range tasks {
+		if len(m.recent) == MaxRecentTasks {
+			break
+		}
+		m.recent = append(m.recent, task.ID)
+	}
+	return nil
+}
+
+// RecordReason records why a task was cancelled or rejected
+func (m *MemoryStore) RecordReason(taskID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tasks[taskID]; !ok {
+		return fmt.Errorf("failed to record history: %w", errors.NewNotFoundError("task not found"))
+	}
+	m.record(time.Now(), taskID, ActionReason, "reason", "", reason)
+	return nil
+}
+
+// GetReason retrieves the most recently recorded reason for a task, or ""
+// when none was given
+func (m *MemoryStore) GetReason(taskID string) (string, error) {
+	entries, err := m.GetHistory(taskID)
+	if err != nil {
+		return "", err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Action == ActionReason {
+			return entries[i].NewValue, nil
+		}
+	}
+	return "", nil
+}
+
+// GetHistory retrieves the change history of a task, oldest first
+func (m *MemoryStore) GetHistory(taskID string) ([]*HistoryEntry, error) {
+	return m.historyWhere(func(entry *HistoryEntry) bool { return entry.TaskID == taskID }), nil
+}
+
+// ListHistory retrieves changes to all tasks made at or after since, oldest
+// first
+func (m *MemoryStore) ListHistory(since time.Time) ([]*HistoryEntry, error) {
+	return m.historyWhere(func(entry *HistoryEntry) bool { return !entry.Created.Before(since) }), nil
+}
+
+// historyWhere returns copies of the matching history entries, oldest first
+func (m *MemoryStore) historyWhere(match func(entry *HistoryEntry) bool) []*HistoryEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []*HistoryEntry
+	for _, entry := range m.history {
+		if match(entry) {
+			c := *entry
+			entries = append(entries, &c)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Created.Equal(entries[j].Created) {
+			return entries[i].Created.Before(entries[j].Created)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// AddLink links one task to another with the given link type
+func (m *MemoryStore) AddLink(sourceID, targetID, linkType string) (*TaskLink, error) {
+	if err := ValidateLinkType(linkType); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	source, target, err := m.linkEnds(sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if source.ID == target.ID {
+		return nil, errors.NewValidationError("cannot link task %s to itself", source.ShortHash())
+	}
+	if m.findLink(source.ID, target.ID, linkType) >= 0 {
+		label := (&TaskLink{SourceID: source.ID, Type: linkType}).Label(source.ID)
+		return nil, errors.NewConflictError("task %s already %s %s", source.ShortHash(), label, target.ShortHash())
+	}
+
+	link := &TaskLink{ID: m.nextID(), SourceID: source.ID, TargetID: target.ID, Type: linkType, Author: m.actor(), Created: time.Now()}
+	m.links = append(m.links, link)
+	m.record(link.Created, source.ID, ActionLink, linkType, "", target.ID)
+	c := *link
+	return &c, nil
+}
+
+// RemoveLink removes a link between two tasks
+func (m *MemoryStore) RemoveLink(sourceID, targetID, linkType string) error {
+	if err := ValidateLinkType(linkType); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	source, target, err := m.linkEnds(sourceID, targetID)
+	if err != nil {
+		return err
+	}
+	i := m.findLink(source.ID, target.ID, linkType)
+	if i < 0 {
+		return errors.NewConflictError("task %s is not linked to %s as %s", source.ShortHash(), target.ShortHash(), linkType)
+	}
+	m.links = slices.Delete(m.links, i, i+1)
+	m.record(time.Now(), source.ID, ActionUnlink, linkType, target.ID, "")
+	return nil
+}
+
+// linkEnds resolves the source and target of a link; the caller holds the
+// lock
+func (m *MemoryStore) linkEnds(sourceID, targetID string) (source, target *Task, err error) {
+	if source, err = m.get(sourceID); err != nil {
+		return nil, nil, err
+	}
+	if target, err = m.get(targetID); err != nil {
+		return nil, nil, fmt.Errorf("link target not found: %w", err)
+	}
+	return source, target, nil
+}
+
+// findLink returns the index of the link of the given type between two
+// tasks, in either direction for "relates", or -1; the caller holds the lock
+func (m *MemoryStore) findLink(sourceID, targetID, linkType string) int {
+	return slices.IndexFunc(m.links, func(link *TaskLink) bool {
+		if link.Type != linkType {
+			return false
+		}
+		return (link.SourceID == sourceID && link.TargetID == targetID) ||
+			(linkType == LinkRelates && link.SourceID == targetID && link.TargetID == sourceID)
+	})
+}
+
+// GetLinks retrieves all links from or to a task, oldest first
+func (m *MemoryStore) GetLinks(taskID string) ([]*TaskLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var links []*TaskLink
+	for _, link := range m.links {
+		if link.SourceID == taskID || link.TargetID == taskID {
+			c := *link
+			links = append(links, &c)
+		}
+	}
+	return links, nil
+}
+
+// AddAttachment associates a file path or URL with a task. Attaching the same
+// location twice is an error.
+func (m *MemoryStore) AddAttachment(taskID, location string) (*Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if m.findAttachment(task.ID, location) >= 0 {
+		return nil, errors.NewConflictError("%s is already attached to task %s", location, task.ShortHash())
+	}
+
+	attachment := &Attachment{ID: m.nextID(), TaskID: task.ID, Location: location, Author: m.actor(), Created: time.Now()}
+	m.attachments = append(m.attachments, attachment)
+	m.record(attachment.Created, task.ID, ActionAttach, "attachment", "", location)
+	c := *attachment
+	return &c, nil
+}
+
+// RemoveAttachment removes a file path or URL from a task
+func (m *MemoryStore) RemoveAttachment(taskID, location string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return err
+	}
+	i := m.findAttachment(task.ID, location)
+	if i < 0 {
+		return errors.NewConflictError("%s is not attached to task %s", location, task.ShortHash())
+	}
+	m.attachments = slices.Delete(m.attachments, i, i+1)
+	m.record(time.Now(), task.ID, ActionDetach, "attachment", location, "")
+	return nil
+}
+
+// findAttachment returns the index of a task's attachment at location, or
+// -1; the caller holds the lock
+func (m *MemoryStore) findAttachment(taskID, location string) int {
+	return slices.IndexFunc(m.attachments, func(attachment *Attachment) bool {
+		return attachment.TaskID == taskID && attachment.Location == location
+	})
+}
+
+// GetAttachments retrieves the attachments of a task, oldest first
+func (m *MemoryStore) GetAttachments(taskID string) ([]*Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var attachments []*Attachment
+	for _, attachment := range m.attachments {
+		if attachment.TaskID == taskID {
+			c := *attachment
+			attachments = append(attachments, &c)
+		}
+	}
+	return attachments, nil
+}
+
+// SetField sets a custom field of a task, replacing any previous value. An
+// empty value removes the field. The task counts as updated.
+func (m *MemoryStore) SetField(taskID, name, value string) error {
+	if err := ValidateField(name, value); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return err
+	}
+
+	old, ok := m.fields[task.ID][name]
+	if old == value && (ok || value == "") {
+		return nil // Unchanged
+	}
+	if value == "" {
+		delete(m.fields[task.ID], name)
+	} else {
+		if m.fields[task.ID] == nil {
+			m.fields[task.ID] = make(map[string]string)
+		}
+		m.fields[task.ID][name] = value
+	}
+	task.Updated = time.Now()
+	m.record(task.Updated, task.ID, ActionField, name, old, value)
+	return nil
+}
+
+// GetFields returns the custom fields of a task by name, empty when it has
+// none
+func (m *MemoryStore) GetFields(taskID string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fields := make(map[string]string, len(m.fields[taskID]))
+	for name, value := range m.fields[taskID] {
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// AddWorklog records an interval of work on a task
+func (m *MemoryStore) AddWorklog(taskID string, started, ended time.Time, note string) (*WorklogEntry, error) {
+	if ended.Before(started) {
+		return nil, errors.NewValidationError("work interval ends before it starts")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, err := m.get(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &WorklogEntry{ID: m.nextID(), TaskID: task.ID, Author: m.actor(), Started: started, Ended: ended, Note: note}
+	m.worklog = append(m.worklog, entry)
+	c := *entry
+	return &c, nil
+}
+
+// GetWorklog retrieves the work intervals recorded for a task, oldest first
+func (m *MemoryStore) GetWorklog(taskID string) ([]*WorklogEntry, error) {
+	return m.worklogWhere(func(entry *WorklogEntry) bool { return entry.TaskID == taskID }), nil
+}
+
+// ListWorklog retrieves work intervals on all tasks started at or after
+// since, oldest first
+func (m *MemoryStore) ListWorklog(since time.Time) ([]*WorklogEntry, error) {
+	return m.worklogWhere(func(entry *WorklogEntry) bool { return !entry.Started.Before(since) }), nil
+}
+
+// worklogWhere returns copies of the matching worklog entries, oldest first
+func (m *MemoryStore) worklogWhere(match func(entry *WorklogEntry) bool) []*WorklogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []*WorklogEntry
+	for _, entry := range m.worklog {
+		if match(entry) {
+			c := *entry
+			entries = append(entries, &c)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Started.Before(entries[j].Started) })
+	return entries
+}
+
+// memoryAliases looks up the tasks aliases refer to in a MemoryStore whose
+// lock is held, returning stored tasks
+type memoryAliases struct {
+	m *MemoryStore
+}
+
+func (a memoryAliases) GetByID(id string) (*Task, error) {
+	return a.m.get(id)
+}
+
+func (a memoryAliases) GetCurrentTask() (*Task, error) {
+	if a.m.current == "" {
+		return nil, nil
+	}
+	task, ok := a.m.tasks[a.m.current]
+	if !ok {
+		return nil, errors.NewNotFoundError("current task %s no longer exists", a.m.current)
+	}
+	return task, nil
+}
+
+func (a memoryAliases) getByExactID(id string) (*Task, error) {
+	task, ok := a.m.tasks[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("task not found")
+	}
+	return task, nil
+}
+
+func (a memoryAliases) getBySeq(seq int) (*Task, error) {
+	for _, task := range a.m.tasks {
+		if task.Seq == seq {
+			return task, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("task not found: %s%d", SeqPrefix, seq)
+}
+
+func (a memoryAliases) getLastTask() (*Task, error) {
+	var last *HistoryEntry
+	for _, entry := range a.m.history {
+		if entry.Author != a.m.actor() || a.m.tasks[entry.TaskID] == nil {
+			continue
+		}
+		if last == nil || entry.Created.After(last.Created) ||
+			(entry.Created.Equal(last.Created) && entry.ID > last.ID) {
+			last = entry
+		}
+	}
+	if last == nil {
+		return nil, errors.NewNotFoundError("no tasks found for @last")
+	}
+	return a.m.tasks[last.TaskID], nil
+}
+
+func (a memoryAliases) getRecentTaskID(position int) (string, error) {
+	if len(a.m.recent) == 0 {
+		return "", errors.NewNotFoundError("no recent task list (run 'gtd list' first)")
+	}
+	if position < 1 || position > len(a.m.recent) {
+		return "", errors.NewValidationError("@%d is out of range: last listing had %d tasks", position, len(a.m.recent))
+	}
+	return a.m.recent[position-1], nil
+}
+
+// copyString returns a copy of a string pointer that shares nothing with it
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMemoryStoreMatchesRepository runs the same operations against both
+// stores and compares what they return
+func TestMemoryStoreMatchesRepository(t *testing.T) {
+	stores := map[string]TaskStore{
+		"sqlite": setupTestDB(t),
+		"memory": NewMemoryStore(),
+	}
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	specs := []struct {
+		title    string
+		priority Priority
+		state    State
+		tags     string
+		parent   int // Index of the parent task, -1 for none
+	}{
+		{"Fix login crash", PriorityHigh, StateNew, "auth", -1},
+		{"Add dark mode", PriorityLow, StateInProgress, "ui", -1},
+		{"Refactor login form", PriorityMedium, StateNew, "ui,auth", 0},
+		{"Triage reports", PriorityMedium, StateInbox, "", -1},
+		{"Write changelog", PriorityHigh, StateDone, "docs", -1},
+		{"Check login copy", PriorityLow, StateNew, "", 0},
+	}
+	var tasks []*Task
+	for i, spec := range specs {
+		task := NewTask(KindBug, spec.title, "Details for "+spec.title)
+		task.Priority = spec.priority
+		task.Tags = spec.tags
+		task.Created = base.Add(time.Duration(i) * time.Minute)
+		task.Updated = task.Created
+		if spec.parent >= 0 {
+			task.Parent = &tasks[spec.parent].ID
+		}
+		tasks = append(tasks, task)
+	}
+
+	ids := func(tasks []*Task) []string {
+		result := make([]string, len(tasks))
+		for i, task := range tasks {
+			result[i] = task.ID
+		}
+		return result
+	}
+	results := map[string]map[string][]string{}
+	for name, store := range stores {
+		for i, task := range tasks {
+			created := *task
+			if err := store.Create(&created); err != nil {
+				t.Fatalf("%s: Create() error = %v", name, err)
+			}
+			if specs[i].state == StateInbox {
+				continue
+			}
+			if err := store.UpdateStateAt(task.ID, StateNew, time.Now()); err != nil {
+				t.Fatalf("%s: UpdateStateAt() error = %v", name, err)
+			}
+			if specs[i].state != StateNew {
+				if err := store.UpdateStateAt(task.ID, specs[i].state, time.Now()); err != nil {
+					t.Fatalf("%s: UpdateStateAt() error = %v", name, err)
+				}
+			}
+		}
+		if err := store.Block(tasks[1].ID, tasks[0].ID); err != nil {
+			t.Fatalf("%s: Block() error = %v", name, err)
+		}
+
+		got := map[string][]string{}
+		queries := map[string]func() ([]*Task, error){
+			"list":     func() ([]*Task, error) { return store.List(ListOptions{}) },
+			"list all": func() ([]*Task, error) { return store.List(ListOptions{All: true, ShowDone: true}) },
+			"list tag": func() ([]*Task, error) { return store.List(ListOptions{Tag: "AUTH"}) },
+			"tag children": func() ([]*Task, error) {
+				return store.List(ListOptions{Tag: "auth", IncludeChildren: true})
+			},
+			"limit":    func() ([]*Task, error) { return store.List(ListOptions{Limit: 2}) },
+			"blocked":  func() ([]*Task, error) { return store.List(ListOptions{Blocked: true}) },
+			"by state": func() ([]*Task, error) { return store.ListByState(StateNew) },
+			"children": func() ([]*Task, error) { return store.GetChildren(tasks[0].ID) },
+			"blockees": func() ([]*Task, error) { return store.GetBlockedTasks(tasks[0].ID) },
+			"search":   func() ([]*Task, error) { return store.Search("login") },
+			"tags":     func() ([]*Task, error) { return store.Search("ui", SearchTags) },
+		}
+		for query, run := range queries {
+			result, err := run()
+			if err != nil {
+				t.Fatalf("%s: %s error = %v", name, query, err)
+			}
+			got[query] = ids(result)
+		}
+
+		// Cancelling the parent cancels its open children
+		changed, err := store.TransitionSubtree(tasks[0].ID, StateCancelled)
+		if err != nil {
+			t.Fatalf("%s: TransitionSubtree() error = %v", name, err)
+		}
+		got["cascade"] = ids(changed)
+
+		task, err := store.GetByID(tasks[2].ID[:8])
+		if err != nil {
+			t.Fatalf("%s: GetByID() error = %v", name, err)
+		}
+		got["prefix"] = []string{task.ID, task.State.String()}
+
+		results[name] = got
+	}
+
+	for query, want := range results["sqlite"] {
+		if got := results["memory"][query]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: memory store returned %v, SQLite returned %v", query, got, want)
+		}
+	}
+}
+
+func TestMemoryStoreReturnsCopies(t *testing.T) {
+	store := NewMemoryStore()
+	task := NewTask(KindFeature, "Original title", "Description")
+	if err := store.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	task.Title = "Changed without Update"
+	fetched, err := store.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Title != "Original title" {
+		t.Errorf("Title = %q, want the stored task to be unaffected", fetched.Title)
+	}
+	if fetched.Seq != 1 {
+		t.Errorf("Seq = %d, want 1", fetched.Seq)
+	}
+
+	if _, err := store.GetByID("no such task"); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+}
+
+// TestMemoryStoreRecordsLikeRepository checks that links, fields, ranking,
+// and history behave the same in both stores
+func TestMemoryStoreRecordsLikeRepository(t *testing.T) {
+	stores := map[string]Store{
+		"sqlite": setupTestDB(t),
+		"memory": NewMemoryStore(),
+	}
+
+	results := map[string][]string{}
+	for name, store := range stores {
+		var tasks []*Task
+		for _, title := range []string{"First", "Second", "Third"} {
+			task := NewTask(KindFeature, title, "Details for "+title)
+			if err := store.Create(task); err != nil {
+				t.Fatalf("%s: Create() error = %v", name, err)
+			}
+			tasks = append(tasks, task)
+		}
+		first, second, third := tasks[0].ID, tasks[1].ID, tasks[2].ID
+		names := strings.NewReplacer(first, "first", second, "second", third, "third")
+
+		if _, err := store.AddLink(first, second, LinkRelates); err != nil {
+			t.Fatalf("%s: AddLink() error = %v", name, err)
+		}
+		_, dupErr := store.AddLink(second, first, LinkRelates)
+		if err := store.SetField(first, "estimate", "3d"); err != nil {
+			t.Fatalf("%s: SetField() error = %v", name, err)
+		}
+		if _, _, err := store.MoveBefore(third, first); err != nil {
+			t.Fatalf("%s: MoveBefore() error = %v", name, err)
+		}
+		if err := store.MergeInto(first, second); err != nil {
+			t.Fatalf("%s: MergeInto() error = %v", name, err)
+		}
+
+		var got []string
+		got = append(got, fmt.Sprint(dupErr != nil))
+		links, _ := store.GetLinks(second)
+		for _, link := range links {
+			got = append(got, link.Type+" "+link.SourceID+" "+link.TargetID)
+		}
+		fields, _ := store.GetFields(first)
+		got = append(got, fmt.Sprint(fields))
+		list, _ := store.List(ListOptions{All: true})
+		for _, task := range list {
+			got = append(got, task.ID+" "+task.State.String())
+		}
+		for _, id := range []string{first, second, third} {
+			history, _ := store.GetHistory(id)
+			for _, entry := range history {
+				got = append(got, entry.TaskID+" "+entry.Action+" "+entry.Field)
+			}
+		}
+		// The stores create the tasks at different times, so their IDs differ
+		for i := range got {
+			got[i] = names.Replace(got[i])
+		}
+		results[name] = got
+	}
+
+	if !reflect.DeepEqual(results["memory"], results["sqlite"]) {
+		t.Errorf("memory store recorded\n%v\nSQLite recorded\n%v", results["memory"], results["sqlite"])
+	}
+}
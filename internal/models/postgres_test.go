@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+// setupPostgresDB returns a repository on an empty schema of the Postgres
+// server given as GTD_TEST_DATABASE_URL, skipping the test without one
+func setupPostgresDB(t *testing.T) *TaskRepository {
+	t.Helper()
+	url := os.Getenv("GTD_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("GTD_TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := database.NewPostgres(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("gtd_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("failed to drop test schema: %v", err)
+		}
+		_ = admin.Close()
+	})
+
+	sep := " "
+	if strings.Contains(url, "://") {
+		sep = "?"
+		if strings.Contains(url, "?") {
+			sep = "&"
+		}
+	}
+	db, err := database.NewPostgres(url + sep + "search_path=" + schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return NewTaskRepository(db)
+}
+
+func TestTaskRepository_Postgres(t *testing.T) {
+	repo := setupPostgresDB(t)
+
+	parent := NewTask(KindFeature, "Shared backlog", "Tasks for every clone")
+	parent.Tags = "Team,backend"
+	if err := repo.Create(parent); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	child := NewTask(KindBug, "Child task", "")
+	child.Parent = &parent.ID
+	if err := repo.Create(child); err != nil {
+		t.Fatalf("Create() child error = %v", err)
+	}
+	if parent.Seq != 1 || child.Seq != 2 {
+		t.Errorf("task numbers = %d, %d, want 1, 2", parent.Seq, child.Seq)
+	}
+
+	got, err := repo.GetByID(parent.ID[:7])
+	if err != nil {
+		t.Fatalf("GetByID() by prefix error = %v", err)
+	}
+	if got.Title != parent.Title || got.Created.IsZero() {
+		t.Errorf("GetByID() = %+v", got)
+	}
+
+	got.Title = "Shared backlog, renamed"
+	got.State = StateNew
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	history, err := repo.GetHistory(got.ID)
+	if err != nil || len(history) == 0 {
+		t.Errorf("GetHistory() = %v, %v, want entries", history, err)
+	}
+
+	tasks, err := repo.List(ListOptions{Tag: "team", All: true})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != parent.ID {
+		t.Errorf("List() by tag = %v, %v, want the parent", tasks, err)
+	}
+	if count, err := repo.Count(ListOptions{All: true}); err != nil || count != 2 {
+		t.Errorf("Count() = %d, %v, want 2", count, err)
+	}
+	ancestors, err := repo.GetAncestors(child.ID)
+	if err != nil || len(ancestors) != 1 {
+		t.Errorf("GetAncestors() = %v, %v, want the parent", ancestors, err)
+	}
+
+	if err := repo.Delete(child.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	again := NewTask(KindBug, "After delete", "")
+	if err := repo.Create(again); err != nil {
+		t.Fatalf("Create() after delete error = %v", err)
+	}
+	if again.Seq != 3 {
+		t.Errorf("task number after delete = %d, want 3", again.Seq)
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubtaskStats counts a parent task's subtasks and how many of them are done
+type SubtaskStats struct {
+	Total int
+	Done  int
+}
+
+// GetSubtaskStats counts the subtasks of each of the given tasks in a single
+// query. Tasks without subtasks are left out of the result.
+func (r *TaskRepository) GetSubtaskStats(parentIDs []string) (map[string]*SubtaskStats, error) {
+	stats := make(map[string]*SubtaskStats)
+	if len(parentIDs) == 0 {
+		return stats, nil
+	}
+
+	placeholders := make([]string, len(parentIDs))
+	args := []interface{}{StateDone}
+	for i, id := range parentIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	rows, err := r.db.DB.Query(fmt.Sprintf(`
+		SELECT parent, COUNT(*), COUNT(CASE WHEN state = ? THEN 1 END)
+		FROM tasks
+		WHERE parent IN (%s)
+		GROUP BY parent
+	`, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subtasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logRowsCloseError(err)
+		}
+	}()
+
+	for rows.Next() {
+		var parent string
+		var counts SubtaskStats
+		if err := rows.Scan(&parent, &counts.Total, &counts.Done); err != nil {
+			return nil, fmt.Errorf("failed to count subtasks: %w", err)
+		}
+		stats[parent] = &counts
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count subtasks: %w", err)
+	}
+	return stats, nil
+}
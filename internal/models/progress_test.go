@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTaskRepository_GetSubtaskStats(t *testing.T) {
+	repo := setupTestDB(t)
+
+	parent := NewTask(KindFeature, "Parent feature", "Has subtasks")
+	other := NewTask(KindBug, "Standalone bug", "Has no subtasks")
+	for _, task := range []*Task{parent, other} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, state := range []State{StateDone, StateNew, StateDone, StateCancelled} {
+		child := NewTask(KindBug, "Subtask "+string(rune('A'+i)), "Subtask description")
+		child.Parent = &parent.ID
+		child.State = state
+		if err := repo.Create(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := repo.GetSubtaskStats([]string{parent.ID, other.ID})
+	if err != nil {
+		t.Fatalf("GetSubtaskStats() error = %v", err)
+	}
+	if got := stats[parent.ID]; got == nil || got.Total != 4 || got.Done != 2 {
+		t.Errorf("Expected 2/4 subtasks done, got %+v", got)
+	}
+	if got, ok := stats[other.ID]; ok {
+		t.Errorf("Expected no stats for a task without subtasks, got %+v", got)
+	}
+
+	if stats, err := repo.GetSubtaskStats(nil); err != nil || len(stats) != 0 {
+		t.Errorf("GetSubtaskStats(nil) = %v, %v", stats, err)
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// MoveToTop ranks a task first among the tasks of its priority
+func (r *TaskRepository) MoveToTop(id string) (*Task, error) {
+	task, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return task, r.moveBefore(task, nil)
+}
+
+// MoveBefore ranks a task directly above another task of the same priority
+func (r *TaskRepository) MoveBefore(id, otherID string) (task, other *Task, err error) {
+	if task, err = r.GetByID(id); err != nil {
+		return nil, nil, err
+	}
+	if other, err = r.GetByID(otherID); err != nil {
+		return nil, nil, err
+	}
+	if task.ID == other.ID {
+		return nil, nil, errors.NewValidationError("cannot move task %s before itself", task.ShortHash())
+	}
+	if task.Priority != other.Priority {
+		return nil, nil, errors.NewValidationError("cannot move %s priority task %s before %s priority task %s: tasks are only ranked within a priority",
+			task.Priority, task.ShortHash(), other.Priority, other.ShortHash())
+	}
+	return task, other, r.moveBefore(task, other)
+}
+
+// moveBefore renumbers the ranks of every task with the task's priority so
+// that it comes directly before other, or first when other is nil. Tasks
+// keep their current relative order otherwise.
+func (r *TaskRepository) moveBefore(task, other *Task) (err error) {
+	rows, err := r.db.DB.Query(`
+		SELECT id FROM tasks
+		WHERE priority = ? AND id != ?
+		ORDER BY rank IS NULL, rank, created DESC
+	`, task.Priority, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to rank tasks: %w", err)
+	}
+	var order []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to rank tasks: %w", err)
+		}
+		if other != nil && id == other.ID {
+			order = append(order, task.ID)
+		}
+		order = append(order, id)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to rank tasks: %w", err)
+	}
+	if other == nil {
+		order = append([]string{task.ID}, order...)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback move: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	for i, id := range order {
+		if _, err = tx.Exec("UPDATE tasks SET rank = ? WHERE id = ?", i+1, id); err != nil {
+			return fmt.Errorf("failed to rank task: %w", err)
+		}
+	}
+
+	// The new value is the task it was moved before, empty for the top
+	before := ""
+	if other != nil {
+		before = other.ID
+	}
+	if err = r.recordHistoryWith(tx, time.Now(), task.ID, ActionMove, "rank", "", before); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
+	}
+	return nil
+}
+
+// SetPinned pins or unpins a task. Pinned tasks are listed before all others.
+func (r *TaskRepository) SetPinned(id string, pinned bool) error {
+	action := ActionUnpin
+	if pinned {
+		action = ActionPin
+	}
+	if _, err := r.db.Exec("UPDATE tasks SET pinned = ? WHERE id = ?", pinned, id); err != nil {
+		return fmt.Errorf("failed to %s task: %w", action, err)
+	}
+	return r.recordHistory(id, action, "pinned", "", "")
+}
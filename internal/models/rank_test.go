@@ -0,0 +1,136 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_Move(t *testing.T) {
+	repo := setupTestDB(t)
+
+	created := time.Now().Add(-time.Hour)
+	newTask := func(title string, priority Priority) *Task {
+		t.Helper()
+		task := NewTask(KindFeature, title, "Ranking test task")
+		task.State = StateNew
+		task.Priority = priority
+		task.Created = created
+		task.Updated = created
+		created = created.Add(time.Minute)
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	a := newTask("A", PriorityMedium)
+	b := newTask("B", PriorityMedium)
+	c := newTask("C", PriorityMedium)
+	urgent := newTask("Urgent", PriorityHigh)
+
+	order := func(t *testing.T, want ...string) {
+		t.Helper()
+		tasks, err := repo.List(ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, task := range tasks {
+			got = append(got, task.Title)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Order = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Order = %v, want %v", got, want)
+			}
+		}
+	}
+
+	// Unranked tasks are listed newest first
+	order(t, "Urgent", "C", "B", "A")
+
+	if _, err := repo.MoveToTop(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	order(t, "Urgent", "A", "C", "B")
+
+	if _, _, err := repo.MoveBefore(b.ID, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	order(t, "Urgent", "A", "B", "C")
+
+	// Reordering is not an update
+	moved, err := repo.GetByID(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !moved.Updated.Equal(c.Updated.Truncate(time.Millisecond)) {
+		t.Errorf("Updated changed from %v to %v by ranking", c.Updated, moved.Updated)
+	}
+	if moved.Rank != 3 {
+		t.Errorf("Rank = %d, want 3", moved.Rank)
+	}
+
+	history, err := repo.GetHistory(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Action != ActionMove || last.NewValue != c.ID {
+		t.Errorf("Expected move before %s in history, got %+v", c.ShortHash(), last)
+	}
+
+	if _, _, err := repo.MoveBefore(urgent.ID, a.ID); err == nil {
+		t.Error("Expected error moving a task before a task of another priority")
+	}
+	if _, _, err := repo.MoveBefore(a.ID, a.ID); err == nil {
+		t.Error("Expected error moving a task before itself")
+	}
+}
+
+func TestTaskRepository_SetPinned(t *testing.T) {
+	repo := setupTestDB(t)
+
+	urgent := NewTask(KindBug, "Urgent bug", "High priority bug in progress")
+	urgent.State = StateInProgress
+	urgent.Priority = PriorityHigh
+	if err := repo.Create(urgent); err != nil {
+		t.Fatal(err)
+	}
+	chore := NewTask(KindFeature, "Low priority chore", "Something to keep in view")
+	chore.State = StateNew
+	chore.Priority = PriorityLow
+	if err := repo.Create(chore); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetPinned(chore.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := repo.List(ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != chore.ID || !tasks[0].Pinned {
+		t.Fatalf("Expected pinned task first, got %v", tasks)
+	}
+
+	if err := repo.SetPinned(chore.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err = repo.List(ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].ID != urgent.ID || tasks[1].Pinned {
+		t.Errorf("Expected usual order after unpinning, got %v", tasks)
+	}
+
+	history, err := repo.GetHistory(chore.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(history); n < 2 || history[n-2].Action != ActionPin || history[n-1].Action != ActionUnpin {
+		t.Errorf("Expected pin and unpin in history, got %d entries", n)
+	}
+}
@@ -1,10 +1,13 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	stderrors "errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/database"
 	"github.com/zw3rk/gtd/internal/errors"
@@ -15,19 +18,45 @@ const (
 	MinHashPrefixLength = 4
 )
 
+// errNoPrefixMatch is returned by getByHashPrefix when no task matches
+var errNoPrefixMatch = stderrors.New("task not found")
+
 // logRowsCloseError logs errors from rows.Close() without overriding the main error
 func logRowsCloseError(err error) {
 	fmt.Fprintf(os.Stderr, "Warning: failed to close rows: %v\n", err)
 }
 
+// ChooseFunc selects one task among several candidates matching an ambiguous reference
+type ChooseFunc func(ref string, candidates []*Task) (*Task, error)
+
 // TaskRepository handles database operations for tasks
 type TaskRepository struct {
-	db *database.Database
+	db     *database.Database
+	choose ChooseFunc
+	tags   TagSettings
+	author string // acting author recorded in the history
 }
 
-// NewTaskRepository creates a new task repository
+// NewTaskRepository creates a new task repository, recording changes as
+// made by the configured git author. Abbreviated task IDs are lengthened as
+// needed to be unambiguous among its tasks.
 func NewTaskRepository(db *database.Database) *TaskRepository {
-	return &TaskRepository{db: db}
+	r := &TaskRepository{db: db, author: gitAuthor()}
+	if err := r.updateShortHashLength(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return r
+}
+
+// SetChooser installs a function used to pick a task when a hash prefix is
+// ambiguous. Without one, ambiguous prefixes return an AmbiguousTaskError.
+func (r *TaskRepository) SetChooser(choose ChooseFunc) {
+	r.choose = choose
+}
+
+// SetTagSettings sets the per-tag settings enforced when tasks are started
+func (r *TaskRepository) SetTagSettings(settings TagSettings) {
+	r.tags = settings
 }
 
 // Create inserts a new task into the database
@@ -35,13 +64,105 @@ func (r *TaskRepository) Create(task *Task) error {
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
+	if err := r.ensureUniqueID(r.db.DB, task); err != nil {
+		return err
+	}
 
-	query := `
-		INSERT INTO tasks (id, parent, priority, state, kind, title, description, author, source, blocked_by, tags)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+	err := database.Retry(func() error {
+		return r.db.DB.QueryRow(insertTaskQuery, insertTaskArgs(task)...).Scan(&task.Seq)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	if err := r.raiseShortHashLength(task.ID); err != nil {
+		return err
+	}
+
+	return r.recordHistoryWith(r.db, task.Created, task.ID, ActionCreate, "state", "", task.State.String())
+}
+
+// CreateBatch creates many tasks at once, like Create but in a single
+// transaction with one prepared insert, so that imports don't commit once
+// per task. Either all tasks are created or none are.
+func (r *TaskRepository) CreateBatch(tasks []*Task) error {
+	return r.createBatch(tasks, nil)
+}
 
-	_, err := r.db.DB.Exec(query,
+// createBatch creates tasks like CreateBatch, calling created, if set, in the
+// transaction after each task is inserted
+func (r *TaskRepository) createBatch(tasks []*Task, created func(tx *sql.Tx, i int) error) (err error) {
+	for _, task := range tasks {
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("validation failed for %q: %w", task.Title, err)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to rollback batch: %v\n", rollbackErr)
+			}
+		}
+	}()
+
+	insert, err := tx.Prepare(insertTaskQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer func() { _ = insert.Close() }()
+
+	for i, task := range tasks {
+		// Checked in the transaction, so tasks earlier in the batch count
+		if err = r.ensureUniqueID(tx, task); err != nil {
+			return err
+		}
+		if err = insert.QueryRow(insertTaskArgs(task)...).Scan(&task.Seq); err != nil {
+			return fmt.Errorf("failed to create task %q: %w", task.Title, err)
+		}
+		if err = r.recordHistoryWith(tx, task.Created, task.ID, ActionCreate, "state", "", task.State.String()); err != nil {
+			return err
+		}
+		if created != nil {
+			if err = created(tx, i); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return r.updateShortHashLength()
+}
+
+// insertTaskQuery inserts a task with the arguments of insertTaskArgs,
+// assigning the next sequential number alongside the hash ID. Numbers follow
+// the highest one ever assigned, kept in the settings by a trigger, so those
+// of deleted tasks aren't reused. Postgres numbers tasks from a sequence
+// instead.
+const insertTaskQuery = `
+	INSERT INTO tasks (id, parent, priority, state, kind, title, description, author, source, blocked_by, tags, external_ref,
+	                   severity, introduced_in, environment, created, updated, seq)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, (
+		SELECT COALESCE(MAX(seq), 0) + 1 FROM (
+			SELECT MAX(seq) AS seq FROM tasks
+			UNION ALL
+			SELECT CAST(value AS INTEGER) FROM settings WHERE key = 'last_seq'
+		) AS assigned
+	))
+	RETURNING seq
+`
+
+// insertTaskArgs returns the arguments of insertTaskQuery for a task
+func insertTaskArgs(task *Task) []interface{} {
+	return []interface{}{
 		task.ID,
 		task.Parent,
 		task.Priority,
@@ -53,16 +174,22 @@ func (r *TaskRepository) Create(task *Task) error {
 		task.Source,
 		task.BlockedBy,
 		task.Tags,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
+		task.ExternalRef,
+		task.Severity,
+		task.IntroducedIn,
+		task.Environment,
+		database.FormatTime(task.Created),
+		database.FormatTime(task.Updated),
 	}
-
-	return nil
 }
 
 // Update modifies an existing task
 func (r *TaskRepository) Update(task *Task) error {
+	before, err := r.getByExactID(task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
@@ -70,11 +197,12 @@ func (r *TaskRepository) Update(task *Task) error {
 	query := `
 		UPDATE tasks
 		SET parent = ?, priority = ?, state = ?, kind = ?, title = ?, 
-		    description = ?, author = ?, source = ?, blocked_by = ?, tags = ?
+		    description = ?, author = ?, source = ?, blocked_by = ?, tags = ?,
+		    external_ref = ?, severity = NULLIF(?, ''), introduced_in = ?, environment = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.DB.Exec(query,
+	_, err = r.db.Exec(query,
 		task.Parent,
 		task.Priority,
 		task.State,
@@ -85,26 +213,45 @@ func (r *TaskRepository) Update(task *Task) error {
 		task.Source,
 		task.BlockedBy,
 		task.Tags,
+		task.ExternalRef,
+		task.Severity,
+		task.IntroducedIn,
+		task.Environment,
 		task.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
 
-	return nil
+	return r.recordChanges(before, task)
 }
 
 // Delete removes a task from the database
 func (r *TaskRepository) Delete(id string) error {
-	_, err := r.db.DB.Exec("DELETE FROM tasks WHERE id = ?", id)
+	result, err := r.db.Exec("DELETE FROM tasks WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
+
+	// Leave a tombstone so incremental exports can report the deletion
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		if _, err := r.db.Exec(`
+			INSERT INTO deleted_tasks (id, deleted) VALUES (?, ?)
+			ON CONFLICT(id) DO UPDATE SET deleted = excluded.deleted
+		`, id, database.FormatTime(time.Now())); err != nil {
+			return fmt.Errorf("failed to record deletion: %w", err)
+		}
+	}
 	return nil
 }
 
 // GetByID retrieves a task by its ID or hash prefix
 func (r *TaskRepository) GetByID(id string) (*Task, error) {
+	// Resolve aliases such as @current, @last, @1 and TASK^
+	if IsTaskAlias(id) {
+		return r.resolveAlias(id)
+	}
+
 	// First try exact match
 	task, err := r.getByExactID(id)
 	if err == nil {
@@ -112,11 +259,34 @@ func (r *TaskRepository) GetByID(id string) (*Task, error) {
 	}
 
 	// If not found and input looks like a hash prefix, try prefix match
-	if len(id) >= MinHashPrefixLength && len(id) < 40 {
+	if len(id) >= MinHashPrefixLength && len(id) < 40 && isHexString(id) {
 		task, err = r.getByHashPrefix(id)
 		if err == nil {
 			return task, nil
 		}
+		// Ambiguous prefixes and aborted selections are reported as-is
+		if !stderrors.Is(err, errNoPrefixMatch) {
+			return nil, err
+		}
+	}
+
+	// Arguments that match no ID are matched against task titles, even when
+	// they look like a hash, e.g. "cafe"
+	matches, err := r.getByTitle(id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1 && r.choose != nil:
+		return r.choose(id, matches)
+	case len(matches) > 1:
+		errorTasks := make([]errors.Task, len(matches))
+		for i, t := range matches {
+			errorTasks[i] = t
+		}
+		return nil, errors.NewTaskNotFoundError(id, errorTasks)
 	}
 
 	// Task not found - provide helpful suggestions
@@ -129,34 +299,45 @@ func (r *TaskRepository) GetByID(id string) (*Task, error) {
 	return nil, errors.NewTaskNotFoundError(id, errorTasks)
 }
 
+// LookupID retrieves a task only by an alias, its full ID, or a hash prefix
+// matching no other task. Unlike GetByID it never matches titles or asks to
+// choose between tasks: an ambiguous prefix is an AmbiguousTaskError, and
+// anything else that names no task a TaskNotFoundError.
+func (r *TaskRepository) LookupID(id string) (*Task, error) {
+	if IsTaskAlias(id) {
+		return resolveAlias(idLookup{r, r.LookupID}, id)
+	}
+	task, err := r.getByExactID(id)
+	if err == nil || errors.CodeOf(err) != errors.CodeNotFound {
+		return task, err
+	}
+	if len(id) >= MinHashPrefixLength && len(id) < 40 && isHexString(id) {
+		tasks, err := r.findByHashPrefix(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(tasks) == 1 {
+			return tasks[0], nil
+		}
+		if len(tasks) > 1 {
+			return nil, errors.NewAmbiguousTaskError(id, errorTasks(tasks))
+		}
+	}
+	return nil, errors.NewTaskNotFoundError(id, nil)
+}
+
 // getByExactID retrieves a task by its exact ID
 func (r *TaskRepository) getByExactID(id string) (*Task, error) {
-	task := &Task{}
 	query := `
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE id = ?
 	`
 
-	err := r.db.DB.QueryRow(query, id).Scan(
-		&task.ID,
-		&task.Parent,
-		&task.Priority,
-		&task.State,
-		&task.Kind,
-		&task.Title,
-		&task.Description,
-		&task.Author,
-		&task.Created,
-		&task.Updated,
-		&task.Source,
-		&task.BlockedBy,
-		&task.Tags,
-	)
+	task, err := scanTask(r.db.DB.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task not found")
+			return nil, errors.NewNotFoundError("task not found")
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
@@ -166,9 +347,28 @@ func (r *TaskRepository) getByExactID(id string) (*Task, error) {
 
 // getByHashPrefix retrieves a task by hash prefix (like git)
 func (r *TaskRepository) getByHashPrefix(prefix string) (*Task, error) {
+	tasks, err := r.findByHashPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, errNoPrefixMatch
+	}
+	if len(tasks) > 1 {
+		if r.choose != nil {
+			return r.choose(prefix, tasks)
+		}
+		return nil, errors.NewAmbiguousTaskError(prefix, errorTasks(tasks))
+	}
+
+	return tasks[0], nil
+}
+
+// findByHashPrefix retrieves all tasks whose ID starts with prefix
+func (r *TaskRepository) findByHashPrefix(prefix string) ([]*Task, error) {
 	query := `
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE id LIKE ? || '%'
 	`
@@ -184,26 +384,57 @@ func (r *TaskRepository) getByHashPrefix(prefix string) (*Task, error) {
 		}
 	}()
 
-	tasks, err := r.scanTasks(rows)
+	return r.scanTasks(rows)
+}
+
+// getByTitle finds tasks whose title contains the given text, case-insensitively.
+// The text is matched literally, so % and _ are not wildcards.
+func (r *TaskRepository) getByTitle(text string) ([]*Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE LOWER(title) LIKE '%' || LOWER(?) || '%' ESCAPE '\'
+		ORDER BY created DESC
+	`
+
+	rows, err := r.db.DB.Query(query, escapeLike(text))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to search by title: %w", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
 
-	if len(tasks) == 0 {
-		return nil, fmt.Errorf("task not found")
-	}
-	if len(tasks) > 1 {
-		return nil, fmt.Errorf("ambiguous hash prefix '%s' matches %d tasks", prefix, len(tasks))
-	}
+	return r.scanTasks(rows)
+}
 
-	return tasks[0], nil
+// likeEscaper escapes the wildcards of LIKE patterns, and the escape
+// character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes text to match literally in a LIKE pattern with
+// ESCAPE '\'
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
+// isHexString reports whether s consists only of hexadecimal digits
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return s != ""
 }
 
 // GetChildren retrieves all child tasks of a parent
 func (r *TaskRepository) GetChildren(parentID string) ([]*Task, error) {
 	query := `
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE parent = ?
 		ORDER BY priority DESC, created ASC
@@ -223,26 +454,134 @@ func (r *TaskRepository) GetChildren(parentID string) ([]*Task, error) {
 	return r.scanTasks(rows)
 }
 
+// GetAncestors retrieves the parent chain of a task in one query, root
+// first and ending with its direct parent. A task without a parent has no
+// ancestors; a chain that loops back on itself stops before the first
+// repeated task.
+func (r *TaskRepository) GetAncestors(id string) ([]*Task, error) {
+	rows, err := r.db.DB.Query(`
+		WITH RECURSIVE ancestors(id, depth, path) AS (
+			SELECT parent, 1, id || ',' || parent FROM tasks
+			WHERE id = ? AND parent IS NOT NULL
+			UNION ALL
+			SELECT tasks.parent, ancestors.depth + 1, ancestors.path || ',' || tasks.parent
+			FROM tasks JOIN ancestors ON tasks.id = ancestors.id
+			WHERE tasks.parent IS NOT NULL AND ancestors.path NOT LIKE '%' || tasks.parent || '%'
+		)
+		SELECT `+taskColumns+`
+		FROM tasks JOIN ancestors USING (id)
+		ORDER BY ancestors.depth DESC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ancestors: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	return r.scanTasks(rows)
+}
+
 // ListOptions contains filtering options for listing tasks
 type ListOptions struct {
-	State         string
-	Priority      string
-	Kind          string
+	State         State
+	States        []State // Only tasks in one of these states, including INBOX and INVALID
+	Priority      Priority
+	Kind          Kind
+	Severity      Severity
+	IntroducedIn  string // Only regressions introduced in this release
+	Environment   string // Only tasks seen in this environment
 	Tag           string
 	Blocked       bool
 	ShowDone      bool
 	ShowCancelled bool
 	Limit         int
 	All           bool
+	UpdatedSince  time.Time // Only tasks created or updated at or after this time
+
+	// Fields only lists tasks with these custom field values; an empty
+	// value matches any value of the field
+	Fields map[string]string
+
+	// IncludeChildren also lists the subtasks, at any depth, of tasks with
+	// Tag, whatever their own tags
+	IncludeChildren bool
 }
 
 // List retrieves tasks based on the given options
 func (r *TaskRepository) List(opts ListOptions) ([]*Task, error) {
+	query, args := listQuery(opts)
+	rows, err := r.db.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	return r.scanTasks(rows)
+}
+
+// Count returns the number of tasks List would return, ignoring the limit
+func (r *TaskRepository) Count(opts ListOptions) (int, error) {
+	opts.Limit = 0
+	query, args := listQuery(opts)
+	var count int
+	if err := r.db.DB.QueryRow("SELECT COUNT(*) FROM ("+query+") AS listed", args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}
+
+// Iterate calls fn for each task List would return, in the same order,
+// reading the tasks one row at a time instead of loading them all. It stops
+// at the first error fn returns, and when ctx is cancelled, returning that
+// error.
+func (r *TaskRepository) Iterate(ctx context.Context, opts ListOptions, fn func(*Task) error) error {
+	query, args := listQuery(opts)
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logRowsCloseError(err)
+		}
+	}()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		task, err := scanTask(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+	return ctx.Err()
+}
+
+// listQuery builds the query List runs for the given options. Tasks are
+// ordered by the columns of idx_list_order, so that the index can be read in
+// order instead of sorting.
+func listQuery(opts ListOptions) (string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 
 	// Default: exclude INBOX, DONE, CANCELLED, and INVALID unless specifically requested
-	if opts.State == "" {
+	if opts.State == "" && len(opts.States) == 0 {
 		excludeStates := []string{}
 		if !opts.All {
 			if !opts.ShowDone {
@@ -273,6 +612,14 @@ func (r *TaskRepository) List(opts ListOptions) ([]*Task, error) {
 		conditions = append(conditions, "state = ?")
 		args = append(args, opts.State)
 	}
+	if len(opts.States) > 0 {
+		placeholders := make([]string, len(opts.States))
+		for i, state := range opts.States {
+			placeholders[i] = "?"
+			args = append(args, state)
+		}
+		conditions = append(conditions, fmt.Sprintf("state IN (%s)", strings.Join(placeholders, ", ")))
+	}
 	if opts.Priority != "" {
 		conditions = append(conditions, "priority = ?")
 		args = append(args, opts.Priority)
@@ -281,13 +628,45 @@ func (r *TaskRepository) List(opts ListOptions) ([]*Task, error) {
 		conditions = append(conditions, "kind = ?")
 		args = append(args, opts.Kind)
 	}
-	if opts.Tag != "" {
-		conditions = append(conditions, "tags LIKE ?")
+	if opts.Severity != "" {
+		conditions = append(conditions, "severity = ?")
+		args = append(args, opts.Severity)
+	}
+	if opts.IntroducedIn != "" {
+		conditions = append(conditions, "introduced_in = ?")
+		args = append(args, opts.IntroducedIn)
+	}
+	if opts.Environment != "" {
+		conditions = append(conditions, "environment = ?")
+		args = append(args, opts.Environment)
+	}
+	if opts.Tag != "" && opts.IncludeChildren {
+		conditions = append(conditions, `id IN (
+			WITH RECURSIVE tagged(id) AS (
+				SELECT id FROM tasks WHERE LOWER(tags) LIKE LOWER(?)
+				UNION
+				SELECT t.id FROM tasks t JOIN tagged ON t.parent = tagged.id
+			)
+			SELECT id FROM tagged
+		)`)
+		args = append(args, "%"+opts.Tag+"%")
+	} else if opts.Tag != "" {
+		conditions = append(conditions, "LOWER(tags) LIKE LOWER(?)")
 		args = append(args, "%"+opts.Tag+"%")
 	}
 	if opts.Blocked {
 		conditions = append(conditions, "blocked_by IS NOT NULL")
 	}
+	if !opts.UpdatedSince.IsZero() {
+		since := database.FormatTime(opts.UpdatedSince)
+		conditions = append(conditions, "(created >= ? OR updated >= ?)")
+		args = append(args, since, since)
+	}
+	if len(opts.Fields) > 0 {
+		fieldConds, fieldArgs := fieldConditions(opts.Fields)
+		conditions = append(conditions, fieldConds...)
+		args = append(args, fieldArgs...)
+	}
 
 	whereClause := ""
 	if len(conditions) > 0 {
@@ -296,22 +675,10 @@ func (r *TaskRepository) List(opts ListOptions) ([]*Task, error) {
 
 	// Build the query with proper ordering
 	query := fmt.Sprintf(`
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT `+taskColumns+`
 		FROM tasks
 		%s
-		ORDER BY 
-			CASE state 
-				WHEN 'IN_PROGRESS' THEN 0
-				WHEN 'NEW' THEN 1
-				ELSE 2
-			END,
-			CASE priority
-				WHEN 'high' THEN 0
-				WHEN 'medium' THEN 1
-				WHEN 'low' THEN 2
-			END,
-			created DESC
+		ORDER BY pinned DESC, state_rank, priority_rank, rank IS NULL, rank, created DESC
 	`, whereClause)
 
 	// Add limit if not showing all
@@ -319,25 +686,13 @@ func (r *TaskRepository) List(opts ListOptions) ([]*Task, error) {
 		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
 	}
 
-	rows, err := r.db.DB.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks: %w", err)
-	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			// Log error but don't override the main error
-			logRowsCloseError(err)
-		}
-	}()
-
-	return r.scanTasks(rows)
+	return query, args
 }
 
 // ListByState retrieves all tasks with a specific state
-func (r *TaskRepository) ListByState(state string) ([]*Task, error) {
+func (r *TaskRepository) ListByState(state State) ([]*Task, error) {
 	query := `
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE state = ?
 		ORDER BY created DESC
@@ -357,18 +712,33 @@ func (r *TaskRepository) ListByState(state string) ([]*Task, error) {
 	return r.scanTasks(rows)
 }
 
-// Search finds tasks by searching in title and description
-func (r *TaskRepository) Search(query string) ([]*Task, error) {
+// Search finds tasks by searching in the given fields, title and description
+// by default, best matches first and newest first among equally good matches
+func (r *TaskRepository) Search(query string, fields ...string) ([]*Task, error) {
+	if len(fields) == 0 {
+		fields = DefaultSearchFields
+	}
+	columns, err := searchColumns(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = "LOWER(" + column + ") LIKE LOWER(?)"
+	}
 	searchQuery := `
-		SELECT id, parent, priority, state, kind, title, description, author,
-		       created, updated, source, blocked_by, tags
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE LOWER(title) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?)
+		WHERE ` + strings.Join(conditions, " OR ") + `
 		ORDER BY created DESC
 	`
 
-	searchTerm := "%" + query + "%"
-	rows, err := r.db.DB.Query(searchQuery, searchTerm, searchTerm)
+	args := make([]interface{}, len(columns))
+	for i := range args {
+		args[i] = "%" + query + "%"
+	}
+	rows, err := r.db.DB.Query(searchQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search tasks: %w", err)
 	}
@@ -379,87 +749,225 @@ func (r *TaskRepository) Search(query string) ([]*Task, error) {
 		}
 	}()
 
-	return r.scanTasks(rows)
+	tasks, err := r.scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	rankSearchResults(tasks, query, fields)
+	return tasks, nil
 }
 
 // UpdateState changes the state of a task
-func (r *TaskRepository) UpdateState(id string, newState string) error {
+func (r *TaskRepository) UpdateState(id string, newState State) error {
+	return r.UpdateStateAt(id, newState, time.Now())
+}
+
+// UpdateStateAt changes the state of a task, recording the change in the
+// history as made at the given time. Backdated changes must fall between the
+// task's creation and now.
+func (r *TaskRepository) UpdateStateAt(id string, newState State, at time.Time) error {
+	task, err := r.CheckTransition(id, newState, at)
+	if err != nil {
+		return err
+	}
+
+	// Update the state
+	_, err = r.db.Exec("UPDATE tasks SET state = ? WHERE id = ?", newState, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+
+	return r.recordHistoryWith(r.db, at, task.ID, ActionState, "state", task.State.String(), newState.String())
+}
+
+// CheckTransition returns the task if UpdateStateAt could move it to
+// newState at the given time, and the error UpdateStateAt would fail with if
+// not, without changing anything
+func (r *TaskRepository) CheckTransition(id string, newState State, at time.Time) (*Task, error) {
 	// Get the task first
 	task, err := r.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if at.After(time.Now()) {
+		return nil, errors.NewValidationError("cannot change state in the future (%s)", at.Format("2006-01-02 15:04"))
+	}
+	if at.Before(task.Created) {
+		return nil, errors.NewValidationError("cannot change state before the task was created (%s)", task.Created.Format("2006-01-02 15:04"))
 	}
 
 	// Get children if any
-	children, err := r.GetChildren(id)
+	children, err := r.GetChildren(task.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if transition is allowed
 	if !task.CanTransitionTo(newState, children) {
-		// Provide more detailed error for parent/child state conflicts
-		if newState == StateDone && len(children) > 0 {
-			for _, child := range children {
-				if child.State != StateDone && child.State != StateCancelled {
-					return fmt.Errorf("cannot mark parent task as DONE: child task %s is in %s state", child.ID, child.State)
-				}
-			}
-		}
-		// Provide helpful guidance on valid transitions
-		var helpMsg string
-		switch task.State {
-		case StateInbox:
-			helpMsg = "use 'gtd accept' to accept the task or 'gtd reject' to mark as invalid"
-		case StateNew:
-			helpMsg = "use 'gtd in-progress' to start work, 'gtd done' to complete, or 'gtd cancel' to cancel"
-		case StateInProgress:
-			helpMsg = "use 'gtd done' to complete or 'gtd cancel' to cancel"
-		case StateDone:
-			helpMsg = "use 'gtd in-progress' to reopen the task"
-		case StateCancelled:
-			helpMsg = "use 'gtd reopen' to move back to NEW or 'gtd in-progress' to start work"
-		case StateInvalid:
-			helpMsg = "invalid tasks cannot be transitioned to other states"
-		}
-		return fmt.Errorf("cannot transition from %s to %s (%s)", task.State, newState, helpMsg)
+		return nil, transitionError(task, newState, children)
 	}
 
-	// Update the state
-	_, err = r.db.DB.Exec("UPDATE tasks SET state = ? WHERE id = ?", newState, id)
-	if err != nil {
-		return fmt.Errorf("failed to update state: %w", err)
+	// Starting the task must stay within the WIP limits of its tags
+	if newState == StateInProgress && len(r.tags.WIPLimits) > 0 {
+		inProgress, err := r.ListByState(StateInProgress)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.tags.checkWIPLimits(task, inProgress); err != nil {
+			return nil, err
+		}
 	}
+	return task, nil
+}
 
-	return nil
+// transitionError explains why a task cannot move to newState
+func transitionError(task *Task, newState State, children []*Task) error {
+	// Provide more detailed error for parent/child state conflicts
+	if newState == StateDone && len(children) > 0 {
+		for _, child := range children {
+			if child.State != StateDone && child.State != StateCancelled {
+				return errors.NewInvalidStateTransitionErrorf(task.State, newState, "cannot mark parent task as DONE: child task %s is in %s state", child.ID, child.State)
+			}
+		}
+	}
+	// Provide helpful guidance on valid transitions
+	return NewTransitionError(task.State, newState)
 }
 
 // Block sets a task as blocked by another task
 func (r *TaskRepository) Block(taskID, blockingTaskID string) error {
 	// Verify both tasks exist
-	if _, err := r.GetByID(taskID); err != nil {
+	task, err := r.GetByID(taskID)
+	if err != nil {
 		return fmt.Errorf("task to block not found: %w", err)
 	}
-	if _, err := r.GetByID(blockingTaskID); err != nil {
+	blockingTask, err := r.GetByID(blockingTaskID)
+	if err != nil {
 		return fmt.Errorf("blocking task not found: %w", err)
 	}
 
-	_, err := r.db.DB.Exec("UPDATE tasks SET blocked_by = ? WHERE id = ?", blockingTaskID, taskID)
+	_, err = r.db.Exec("UPDATE tasks SET blocked_by = ? WHERE id = ?", blockingTask.ID, task.ID)
 	if err != nil {
 		return fmt.Errorf("failed to block task: %w", err)
 	}
 
-	return nil
+	return r.recordHistory(task.ID, ActionBlock, "blocked_by", derefString(task.BlockedBy), blockingTask.ID)
 }
 
 // Unblock removes the blocking relationship from a task
 func (r *TaskRepository) Unblock(taskID string) error {
-	_, err := r.db.DB.Exec("UPDATE tasks SET blocked_by = NULL WHERE id = ?", taskID)
+	task, err := r.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("UPDATE tasks SET blocked_by = NULL WHERE id = ?", task.ID)
 	if err != nil {
 		return fmt.Errorf("failed to unblock task: %w", err)
 	}
 
-	return nil
+	if !task.IsBlocked() {
+		return nil
+	}
+	return r.recordHistory(task.ID, ActionUnblock, "blocked_by", *task.BlockedBy, "")
+}
+
+// GetBlockedTasks retrieves the tasks blocked by the given task
+func (r *TaskRepository) GetBlockedTasks(blockerID string) ([]*Task, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE blocked_by = ?
+		ORDER BY created ASC
+	`, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	return r.scanTasks(rows)
+}
+
+// GetBlockerChain follows blocked_by from a task and returns its blockers,
+// nearest first. cyclic reports that the chain loops back on itself; the
+// chain then stops before the first repeated task.
+func (r *TaskRepository) GetBlockerChain(id string) (chain []*Task, cyclic bool, err error) {
+	task, err := r.GetByID(id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	seen := map[string]bool{task.ID: true}
+	for task.BlockedBy != nil {
+		if seen[*task.BlockedBy] {
+			return chain, true, nil
+		}
+		task, err = r.getByExactID(*task.BlockedBy)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to follow blocker chain: %w", err)
+		}
+		seen[task.ID] = true
+		chain = append(chain, task)
+	}
+	return chain, false, nil
+}
+
+// taskColumns is the column list selected by every task query, in scanTask order
+const taskColumns = `id, parent, priority, state, kind, title, description, author,
+		       created, updated, source, blocked_by, tags, COALESCE(seq, 0),
+		       COALESCE(external_ref, ''), COALESCE(rank, 0),
+		       pinned, COALESCE(severity, ''), COALESCE(introduced_in, ''),
+		       COALESCE(environment, '')`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask scans a single row selected with taskColumns
+func scanTask(row rowScanner) (*Task, error) {
+	task := &Task{}
+	err := row.Scan(
+		&task.ID,
+		&task.Parent,
+		&task.Priority,
+		&task.State,
+		&task.Kind,
+		&task.Title,
+		&task.Description,
+		&task.Author,
+		&task.Created,
+		&task.Updated,
+		&task.Source,
+		&task.BlockedBy,
+		&task.Tags,
+		&task.Seq,
+		&task.ExternalRef,
+		&task.Rank,
+		&task.Pinned,
+		&task.Severity,
+		&task.IntroducedIn,
+		&task.Environment,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inLocalTime(&task.Created, &task.Updated)
+	return task, nil
+}
+
+// inLocalTime converts timestamps read from the database to local time. The
+// cgo driver does so itself; the pure-Go driver returns them in UTC.
+func inLocalTime(times ...*time.Time) {
+	for _, t := range times {
+		*t = t.Local()
+	}
 }
 
 // scanTasks is a helper to scan multiple task rows
@@ -467,22 +975,7 @@ func (r *TaskRepository) scanTasks(rows *sql.Rows) ([]*Task, error) {
 	var tasks []*Task
 
 	for rows.Next() {
-		task := &Task{}
-		err := rows.Scan(
-			&task.ID,
-			&task.Parent,
-			&task.Priority,
-			&task.State,
-			&task.Kind,
-			&task.Title,
-			&task.Description,
-			&task.Author,
-			&task.Created,
-			&task.Updated,
-			&task.Source,
-			&task.BlockedBy,
-			&task.Tags,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
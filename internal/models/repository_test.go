@@ -1,11 +1,15 @@
 package models
 
 import (
+	"context"
+	stderrors "errors"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
 )
 
 func setupTestDB(t *testing.T) *TaskRepository {
@@ -58,6 +62,53 @@ func TestTaskRepository_Create(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_CreateWhileLocked(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := database.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	// Give up on the lock at once instead of after SQLite's busy timeout
+	db.DB.SetMaxOpenConns(1)
+	if _, err := db.DB.Exec("PRAGMA busy_timeout = 0"); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewTaskRepository(db)
+
+	// Another process holds the write lock
+	other, err := database.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = other.Close() })
+	tx, err := other.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.Create(NewTask(KindBug, "Locked out", "Created while locked"))
+	if errors.CodeOf(err) != errors.CodeBusy {
+		t.Fatalf("Create() error = %v, want a busy error", err)
+	}
+
+	// The insert goes through once the lock is released during the retries
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = tx.Commit()
+	}()
+	task := NewTask(KindBug, "Retried", "Created after the lock is released")
+	if err := repo.Create(task); err != nil {
+		t.Fatalf("Create() error = %v, want the insert to be retried", err)
+	}
+	if _, err := repo.GetByID(task.ID); err != nil {
+		t.Errorf("GetByID() error = %v", err)
+	}
+}
+
 func TestTaskRepository_CreateWithParent(t *testing.T) {
 	repo := setupTestDB(t)
 
@@ -85,6 +136,83 @@ func TestTaskRepository_CreateWithParent(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_CreateBatch(t *testing.T) {
+	repo := setupTestDB(t)
+
+	existing := NewTask(KindBug, "Existing", "A task created before the batch")
+	if err := repo.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	first := NewTask(KindBug, "First", "The first task of the batch")
+	second := NewTask(KindFeature, "Second", "The second task of the batch")
+	clash := NewTask(KindBug, "Clash", "A task whose ID is taken within the batch")
+	clash.ID = first.ID
+	if err := repo.CreateBatch([]*Task{first, second, clash}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	if clash.ID == first.ID {
+		t.Error("CreateBatch() kept an ID taken earlier in the batch")
+	}
+	for i, task := range []*Task{first, second, clash} {
+		if want := existing.Seq + i + 1; task.Seq != want {
+			t.Errorf("%s has seq %d, want %d", task.Title, task.Seq, want)
+		}
+		saved, err := repo.GetByID(task.ID)
+		if err != nil || saved.Title != task.Title {
+			t.Fatalf("GetByID(%s) = %v, %v", task.Title, saved, err)
+		}
+		history, err := repo.GetHistory(task.ID)
+		if err != nil || len(history) != 1 || history[0].Action != ActionCreate {
+			t.Errorf("%s history = %v, %v, want one create entry", task.Title, history, err)
+		}
+	}
+
+	// An invalid task fails the whole batch
+	valid := NewTask(KindBug, "Valid", "A valid task in a failing batch")
+	invalid := NewTask(KindBug, "", "A task without a title")
+	if err := repo.CreateBatch([]*Task{valid, invalid}); err == nil {
+		t.Fatal("CreateBatch() accepted an invalid task")
+	}
+	if _, err := repo.GetByID(valid.ID); err == nil {
+		t.Error("CreateBatch() created tasks of a failing batch")
+	}
+
+	if err := repo.CreateBatch(nil); err != nil {
+		t.Errorf("CreateBatch(nil) error = %v", err)
+	}
+}
+
+func TestTaskRepository_CreateFromComments(t *testing.T) {
+	repo := setupTestDB(t)
+
+	first := NewTask(KindFeature, "Cache results", "TODO in main.go")
+	second := NewTask(KindBug, "Handle timeouts", "FIXME in client.go")
+	if err := repo.CreateFromComments([]*Task{first, second}, []string{"fp1", "fp2"}); err != nil {
+		t.Fatalf("CreateFromComments() error = %v", err)
+	}
+	for _, fingerprint := range []string{"fp1", "fp2"} {
+		if done, err := repo.IsImported(fingerprint); err != nil || !done {
+			t.Errorf("IsImported(%q) = %v, %v, want true", fingerprint, done, err)
+		}
+	}
+
+	// A failing batch remembers none of its comments
+	valid := NewTask(KindBug, "Valid", "A valid task in a failing batch")
+	invalid := NewTask(KindBug, "", "A task without a title")
+	if err := repo.CreateFromComments([]*Task{valid, invalid}, []string{"fp3", "fp4"}); err == nil {
+		t.Fatal("CreateFromComments() accepted an invalid task")
+	}
+	if done, _ := repo.IsImported("fp3"); done {
+		t.Error("CreateFromComments() remembered a comment of a failing batch")
+	}
+
+	if err := repo.CreateFromComments([]*Task{valid}, nil); err == nil {
+		t.Error("CreateFromComments() accepted tasks without fingerprints")
+	}
+}
+
 func TestTaskRepository_Update(t *testing.T) {
 	repo := setupTestDB(t)
 
@@ -178,8 +306,8 @@ func TestTaskRepository_List(t *testing.T) {
 
 	// Create tasks with different states and priorities
 	tasks := []struct {
-		state    string
-		priority string
+		state    State
+		priority Priority
 		title    string
 	}{
 		{StateInProgress, PriorityHigh, "High priority in progress"},
@@ -213,6 +341,144 @@ func TestTaskRepository_List(t *testing.T) {
 	if result[0].State != StateInProgress {
 		t.Error("First task should be IN_PROGRESS")
 	}
+	var titles []string
+	for _, task := range result {
+		titles = append(titles, task.Title)
+	}
+	want := "High priority in progress, Low priority in progress, High priority new, Medium priority new"
+	if got := strings.Join(titles, ", "); got != want {
+		t.Errorf("List() order = %s, want %s", got, want)
+	}
+}
+
+func TestTaskRepository_Iterate(t *testing.T) {
+	repo := setupTestDB(t)
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		if err := repo.Create(NewTask(KindBug, title, "Task for testing iteration")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := ListOptions{All: true}
+	listed, err := repo.List(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tasks come in the order of List
+	var ids []string
+	err = repo.Iterate(context.Background(), opts, func(task *Task) error {
+		ids = append(ids, task.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	if len(ids) != len(listed) {
+		t.Fatalf("Iterate() visited %d tasks, want %d", len(ids), len(listed))
+	}
+	for i, task := range listed {
+		if ids[i] != task.ID {
+			t.Errorf("Iterate() task %d = %s, want %s", i, ids[i], task.ID)
+		}
+	}
+
+	// An error from fn stops the iteration
+	errStop := stderrors.New("stop")
+	visited := 0
+	err = repo.Iterate(context.Background(), opts, func(task *Task) error {
+		visited++
+		return errStop
+	})
+	if err != errStop || visited != 1 {
+		t.Errorf("Iterate() = %v after %d tasks, want %v after 1", err, visited, errStop)
+	}
+
+	// So does cancelling the context
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = 0
+	err = repo.Iterate(ctx, opts, func(task *Task) error {
+		visited++
+		cancel()
+		return nil
+	})
+	if !stderrors.Is(err, context.Canceled) || visited != 1 {
+		t.Errorf("Iterate() = %v after %d tasks, want %v after 1", err, visited, context.Canceled)
+	}
+}
+
+func TestTaskRepository_Severity(t *testing.T) {
+	repo := setupTestDB(t)
+
+	critical := NewTask(KindBug, "Crash on start", "The app crashes on start")
+	critical.Severity = SeverityCritical
+	unrated := NewTask(KindBug, "Typo", "A typo in the help")
+	for _, task := range []*Task{critical, unrated} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved, err := repo.GetByID(critical.ID)
+	if err != nil || saved.Severity != SeverityCritical {
+		t.Fatalf("GetByID() severity = %q, %v", saved.Severity, err)
+	}
+
+	tasks, err := repo.List(ListOptions{All: true, Severity: SeverityCritical})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != critical.ID {
+		t.Errorf("List(Severity) = %v, %v", tasks, err)
+	}
+
+	// Clearing the severity stores none, and is recorded
+	saved.Severity = ""
+	if err := repo.Update(saved); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := repo.List(ListOptions{All: true, Severity: SeverityCritical}); len(tasks) != 0 {
+		t.Errorf("List(Severity) after clearing = %d tasks", len(tasks))
+	}
+	history, err := repo.GetHistory(critical.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Field != "severity" || last.OldValue != "critical" || last.NewValue != "" {
+		t.Errorf("Last history entry = %+v", last)
+	}
+}
+
+func TestTaskRepository_ListQueryPlan(t *testing.T) {
+	repo := setupTestDB(t)
+
+	// The default list and --all read the list order index instead of
+	// sorting the table
+	for _, opts := range []ListOptions{
+		{Limit: 20},
+		{All: true, ShowDone: true, ShowCancelled: true},
+		{Tag: "backend", Limit: 20},
+	} {
+		query, args := listQuery(opts)
+		rows, err := repo.db.DB.Query("EXPLAIN QUERY PLAN "+query, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plan []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				t.Fatal(err)
+			}
+			plan = append(plan, detail)
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := strings.Join(plan, "; ")
+		if !strings.Contains(got, "USING INDEX idx_list_order") || strings.Contains(got, "TEMP B-TREE") {
+			t.Errorf("List(%+v) plan = %s, want a scan of idx_list_order without sorting", opts, got)
+		}
+	}
 }
 
 func TestTaskRepository_ListWithFilters(t *testing.T) {
@@ -261,6 +527,28 @@ func TestTaskRepository_ListWithFilters(t *testing.T) {
 	if len(result) != 1 || !strings.Contains(result[0].Tags, "backend") {
 		t.Errorf("Tag filter not working correctly, got %d tasks", len(result))
 	}
+
+	// Test states filter, which overrides the default exclusions
+	feature.State = StateInvalid
+	if err := repo.Update(feature); err != nil {
+		t.Fatal(err)
+	}
+	opts = ListOptions{States: []State{StateInbox, StateInvalid}}
+	result, err = repo.List(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Errorf("States filter not working correctly, got %d tasks", len(result))
+	}
+	opts = ListOptions{States: []State{StateInvalid}}
+	result, err = repo.List(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].State != StateInvalid {
+		t.Errorf("States filter not working correctly, got %d tasks", len(result))
+	}
 }
 
 func TestTaskRepository_Search(t *testing.T) {
@@ -399,3 +687,204 @@ func TestTaskRepository_Block(t *testing.T) {
 		t.Error("Task not properly unblocked")
 	}
 }
+
+func TestTaskRepository_GetBlockerChain(t *testing.T) {
+	repo := setupTestDB(t)
+
+	var tasks []*Task
+	for _, title := range []string{"First", "Second", "Third"} {
+		task := NewTask(KindBug, title, "Part of a blocker chain")
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+	// First is blocked by Second, which is blocked by Third
+	if err := repo.Block(tasks[0].ID, tasks[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Block(tasks[1].ID, tasks[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, cyclic, err := repo.GetBlockerChain(tasks[0].ID)
+	if err != nil {
+		t.Fatalf("GetBlockerChain() error = %v", err)
+	}
+	if cyclic || len(chain) != 2 || chain[0].ID != tasks[1].ID || chain[1].ID != tasks[2].ID {
+		t.Errorf("GetBlockerChain() = %d tasks (cyclic %v), want Second then Third", len(chain), cyclic)
+	}
+
+	chain, _, err = repo.GetBlockerChain(tasks[2].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 0 {
+		t.Errorf("unblocked task has %d blockers, want 0", len(chain))
+	}
+
+	// Closing the loop must not follow it forever
+	if err := repo.Block(tasks[2].ID, tasks[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	chain, cyclic, err = repo.GetBlockerChain(tasks[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cyclic || len(chain) != 2 {
+		t.Errorf("GetBlockerChain() = %d tasks (cyclic %v), want 2 tasks and a cycle", len(chain), cyclic)
+	}
+}
+
+func TestTaskRepository_GetAncestors(t *testing.T) {
+	repo := setupTestDB(t)
+
+	var tasks []*Task
+	for i, title := range []string{"Root", "Child", "Grandchild"} {
+		task := NewTask(KindFeature, title, "Part of a parent chain")
+		if i > 0 {
+			task.Parent = &tasks[i-1].ID
+		}
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	ancestors, err := repo.GetAncestors(tasks[2].ID)
+	if err != nil {
+		t.Fatalf("GetAncestors() error = %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0].ID != tasks[0].ID || ancestors[1].ID != tasks[1].ID {
+		t.Errorf("GetAncestors() = %d tasks, want Root then Child", len(ancestors))
+	}
+
+	ancestors, err = repo.GetAncestors(tasks[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ancestors) != 0 {
+		t.Errorf("top-level task has %d ancestors, want 0", len(ancestors))
+	}
+
+	// A corrupted chain that loops must not be followed forever
+	if _, err := repo.db.Exec("UPDATE tasks SET parent = ? WHERE id = ?", tasks[2].ID, tasks[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	ancestors, err = repo.GetAncestors(tasks[2].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ancestors) != 2 || ancestors[0].ID != tasks[0].ID {
+		t.Errorf("GetAncestors() = %d tasks, want Root then Child", len(ancestors))
+	}
+}
+
+func TestTaskRepository_GetByIDAmbiguous(t *testing.T) {
+	repo := setupTestDB(t)
+
+	first := NewTask(KindBug, "First match", "Shares a prefix")
+	first.ID = "abcd111111111111111111111111111111111111"
+	second := NewTask(KindBug, "Second match", "Shares a prefix")
+	second.ID = "abcd222222222222222222222222222222222222"
+	for _, task := range []*Task{first, second} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without a chooser the candidates are listed in the error
+	_, err := repo.GetByID("abcd")
+	var ambiguous *errors.AmbiguousTaskError
+	if !stderrors.As(err, &ambiguous) {
+		t.Fatalf("GetByID() error = %v, want AmbiguousTaskError", err)
+	}
+	for _, want := range []string{"abcd111", "First match", "abcd222", "Second match"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error does not mention %q: %v", want, err)
+		}
+	}
+
+	// With a chooser the selected task is returned
+	repo.SetChooser(func(ref string, candidates []*Task) (*Task, error) {
+		if ref != "abcd" || len(candidates) != 2 {
+			t.Errorf("chooser got ref=%q with %d candidates", ref, len(candidates))
+		}
+		return candidates[1], nil
+	})
+	task, err := repo.GetByID("abcd")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if task.ID != second.ID {
+		t.Errorf("GetByID() = %s, want %s", task.ID, second.ID)
+	}
+
+	// A failed selection is reported rather than turned into "not found"
+	repo.SetChooser(func(string, []*Task) (*Task, error) {
+		return nil, stderrors.New("selection aborted")
+	})
+	if _, err := repo.GetByID("abcd"); err == nil || !strings.Contains(err.Error(), "selection aborted") {
+		t.Errorf("GetByID() error = %v, want selection aborted", err)
+	}
+}
+
+func TestTaskRepository_GetByIDTitle(t *testing.T) {
+	repo := setupTestDB(t)
+
+	leak := NewTask(KindBug, "Fix memory leak in parser", "Parser leaks memory")
+	cache := NewTask(KindFeature, "Add cache layer", "Cache repeated queries")
+	cacheBug := NewTask(KindBug, "Cache invalidation bug", "Stale entries are served")
+	for _, task := range []*Task{leak, cache, cacheBug} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Unique, case-insensitive substring
+	task, err := repo.GetByID("MEMORY leak")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if task.ID != leak.ID {
+		t.Errorf("GetByID() = %q, want %q", task.Title, leak.Title)
+	}
+
+	// Several matches produce suggestions
+	_, err = repo.GetByID("cache")
+	if err == nil {
+		t.Fatal("GetByID() expected error for ambiguous title")
+	}
+	for _, want := range []string{"Did you mean", "Add cache layer", "Cache invalidation bug"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error does not contain %q: %v", want, err)
+		}
+	}
+
+	// Hex-looking words are matched against titles when no ID starts with them
+	task, err = repo.GetByID("add")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if task.ID != cache.ID {
+		t.Errorf("GetByID() = %q, want %q", task.Title, cache.Title)
+	}
+
+	// % and _ match literally
+	progress := NewTask(KindFeature, "Show 100% progress", "Progress bar stops short")
+	if err := repo.Create(progress); err != nil {
+		t.Fatal(err)
+	}
+	task, err = repo.GetByID("100%")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if task.ID != progress.ID {
+		t.Errorf("GetByID() = %q, want %q", task.Title, progress.Title)
+	}
+	for _, text := range []string{"%", "memory_leak", "c%e"} {
+		if task, err := repo.GetByID(text); err == nil && task.ID != progress.ID {
+			t.Errorf("GetByID(%q) = %q, want no wildcard match", text, task.Title)
+		}
+	}
+}
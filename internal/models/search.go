@@ -0,0 +1,134 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Search fields
+const (
+	SearchTitle       = "title"
+	SearchDescription = "description"
+	SearchTags        = "tags"
+	SearchSource      = "source"
+	SearchAuthor      = "author"
+)
+
+// SearchFields lists the fields that can be searched
+var SearchFields = []string{SearchTitle, SearchDescription, SearchTags, SearchSource, SearchAuthor}
+
+// DefaultSearchFields are searched when no fields are given
+var DefaultSearchFields = []string{SearchTitle, SearchDescription}
+
+// searchColumns maps search fields to their columns, rejecting unknown fields
+func searchColumns(fields []string) ([]string, error) {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		switch field {
+		case SearchTitle, SearchDescription, SearchTags, SearchSource, SearchAuthor:
+			columns[i] = field
+		default:
+			return nil, errors.NewValidationError("invalid search field: %s (must be one of %s)", field, strings.Join(SearchFields, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// SearchFieldValue returns the value of a search field of a task
+func SearchFieldValue(task *Task, field string) string {
+	switch field {
+	case SearchTitle:
+		return task.Title
+	case SearchDescription:
+		return task.Description
+	case SearchTags:
+		return task.Tags
+	case SearchSource:
+		return task.Source
+	case SearchAuthor:
+		return task.Author
+	default:
+		return ""
+	}
+}
+
+// rankSearchResults orders tasks by how well they match a search query in
+// the searched fields, keeping the given order among equally good matches
+func rankSearchResults(tasks []*Task, query string, fields []string) {
+	scores := make(map[*Task]int, len(tasks))
+	for _, task := range tasks {
+		scores[task] = searchScore(task, query, fields)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return scores[tasks[i]] > scores[tasks[j]]
+	})
+}
+
+// searchScore rates how well a task matches a search query. Matches in the
+// title count more than matches in other fields, whole words more than
+// parts of words, and repeated matches in the description a little more.
+func searchScore(task *Task, query string, fields []string) int {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0
+	}
+
+	score := 0
+	for _, field := range fields {
+		value := strings.ToLower(SearchFieldValue(task, field))
+		n := strings.Count(value, query)
+		if n == 0 {
+			continue
+		}
+
+		switch field {
+		case SearchTitle:
+			score += 10
+			if value == query {
+				score += 10
+			} else if containsWord(value, query) {
+				score += 3
+			}
+		case SearchDescription:
+			score += 3 + min(n-1, 4)
+			if containsWord(value, query) {
+				score++
+			}
+		default:
+			score += 2
+			if containsWord(value, query) {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// containsWord reports whether query occurs in text as a whole word
+func containsWord(text, query string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], query)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(query)
+		if !isWordByte(text, start-1) && !isWordByte(text, end) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isWordByte reports whether the byte at i is part of a word, false when i
+// is outside of text
+func isWordByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	r := rune(text[i])
+	return r >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
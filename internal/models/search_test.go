@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskRepository_SearchRanking(t *testing.T) {
+	repo := setupTestDB(t)
+
+	// Created oldest first, so newest-first order would be the reverse
+	tasks := []*Task{
+		NewTask(KindFeature, "Exact", "Mentions cache once"),
+		NewTask(KindBug, "Stale cache entries", "The cache keeps entries after they expire"),
+		NewTask(KindBug, "Slow startup", "Caches warm slowly; cache cache cache"),
+		NewTask(KindFeature, "Cache", "Introduce a cache"),
+		NewTask(KindBug, "Cacheless mode broken", "Fails without a warm store"),
+	}
+	for _, task := range tasks {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := repo.Search("cache")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	want := []string{"Cache", "Stale cache entries", "Cacheless mode broken", "Slow startup", "Exact"}
+	if len(results) != len(want) {
+		t.Fatalf("Search() returned %d results, want %d", len(results), len(want))
+	}
+	for i, title := range want {
+		if results[i].Title != title {
+			t.Errorf("result %d = %q, want %q", i+1, results[i].Title, title)
+		}
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		text, query string
+		want        bool
+	}{
+		{"fix the cache", "cache", true},
+		{"caches and cache", "cache", true},
+		{"cacheless", "cache", false},
+		{"my_cache", "cache", false},
+		{"cache-aside", "cache", true},
+	}
+	for _, tt := range tests {
+		if got := containsWord(tt.text, tt.query); got != tt.want {
+			t.Errorf("containsWord(%q, %q) = %v, want %v", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTaskRepository_SearchFields(t *testing.T) {
+	repo := setupTestDB(t)
+
+	tagged := NewTask(KindBug, "Slow queries", "Queries take seconds")
+	tagged.Tags = "backend,database"
+	tagged.Source = "internal/db/query.go:42"
+	if err := repo.Create(tagged); err != nil {
+		t.Fatal(err)
+	}
+	titled := NewTask(KindFeature, "Backend metrics", "Export metrics")
+	if err := repo.Create(titled); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query  string
+		fields []string
+		want   []string
+	}{
+		{"backend", nil, []string{"Backend metrics"}},
+		{"backend", []string{SearchTitle, SearchDescription, SearchTags}, []string{"Backend metrics", "Slow queries"}},
+		{"query.go", []string{SearchSource}, []string{"Slow queries"}},
+		{"query.go", nil, nil},
+	}
+	for _, tt := range tests {
+		results, err := repo.Search(tt.query, tt.fields...)
+		if err != nil {
+			t.Fatalf("Search(%q, %v) error = %v", tt.query, tt.fields, err)
+		}
+		var titles []string
+		for _, task := range results {
+			titles = append(titles, task.Title)
+		}
+		if strings.Join(titles, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("Search(%q, %v) = %v, want %v", tt.query, tt.fields, titles, tt.want)
+		}
+	}
+
+	if _, err := repo.Search("backend", "kind"); err == nil {
+		t.Error("Expected error for an unknown search field")
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SectionType is a kind of section recognized in task descriptions
+type SectionType struct {
+	Key    string   // Name used by show --section and in JSON exports
+	Titles []string // Headers that start the section, canonical first
+}
+
+// SectionTypes lists the recognized description sections
+var SectionTypes = []SectionType{
+	{Key: "acceptance", Titles: []string{"Acceptance Criteria", "Acceptance"}},
+	{Key: "repro", Titles: []string{"Repro Steps", "Steps to Reproduce", "Reproduction Steps"}},
+	{Key: "expected", Titles: []string{"Expected Behavior", "Expected Behaviour", "Expected Result"}},
+	{Key: "actual", Titles: []string{"Actual Behavior", "Actual Behaviour", "Actual Result"}},
+}
+
+// sectionHeader matches a section header line, either "Title:" with
+// optional text after the colon or a Markdown heading "## Title"
+var sectionHeader = func() *regexp.Regexp {
+	var titles []string
+	for _, sectionType := range SectionTypes {
+		for _, title := range sectionType.Titles {
+			titles = append(titles, regexp.QuoteMeta(title))
+		}
+	}
+	return regexp.MustCompile(`(?i)^\s*(#{1,6}\s+)?(` + strings.Join(titles, "|") + `)\s*(:\s*(.*?))?\s*$`)
+}()
+
+// Section is a recognized section of a task description
+type Section struct {
+	Type SectionType
+	Line int    // Zero-based line of the header in the description
+	Body string // Text up to the next section, without surrounding blank lines
+}
+
+// LookupSectionType finds a section type by its key or one of its titles,
+// ignoring case
+func LookupSectionType(name string) (SectionType, bool) {
+	name = strings.TrimSpace(name)
+	for _, sectionType := range SectionTypes {
+		if strings.EqualFold(name, sectionType.Key) {
+			return sectionType, true
+		}
+		for _, title := range sectionType.Titles {
+			if strings.EqualFold(name, title) {
+				return sectionType, true
+			}
+		}
+	}
+	return SectionType{}, false
+}
+
+// ParseSections splits the recognized sections out of a description, in the
+// order they appear. Text before the first section belongs to no section.
+func ParseSections(description string) []Section {
+	var sections []Section
+	var body []string
+	finish := func() {
+		if len(sections) > 0 {
+			sections[len(sections)-1].Body = strings.Trim(strings.Join(body, "\n"), "\n")
+		}
+		body = nil
+	}
+
+	for i, line := range strings.Split(description, "\n") {
+		match := sectionHeader.FindStringSubmatch(line)
+		if match == nil || (match[1] == "" && match[3] == "") {
+			body = append(body, line)
+			continue
+		}
+		sectionType, _ := LookupSectionType(match[2])
+		finish()
+		sections = append(sections, Section{Type: sectionType, Line: i})
+		if match[4] != "" {
+			body = append(body, match[4])
+		}
+	}
+	finish()
+	return sections
+}
+
+// FindSection returns the first section of a description with the given key
+func FindSection(description, key string) (Section, bool) {
+	for _, section := range ParseSections(description) {
+		if section.Type.Key == key {
+			return section, true
+		}
+	}
+	return Section{}, false
+}
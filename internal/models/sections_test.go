@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	description := `Saving a file sometimes crashes the editor.
+
+Repro Steps:
+1. Open a large file
+2. Press Ctrl+S
+
+## Expected behaviour
+The file is saved.
+
+acceptance criteria: no crash when saving
+Covered by a regression test
+Acceptance is discussed in the meeting notes.`
+
+	sections := ParseSections(description)
+	want := []struct {
+		key  string
+		line int
+		body string
+	}{
+		{"repro", 2, "1. Open a large file\n2. Press Ctrl+S"},
+		{"expected", 6, "The file is saved."},
+		{"acceptance", 9, "no crash when saving\nCovered by a regression test\nAcceptance is discussed in the meeting notes."},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("ParseSections() returned %d sections, want %d: %+v", len(sections), len(want), sections)
+	}
+	for i, w := range want {
+		if sections[i].Type.Key != w.key || sections[i].Line != w.line || sections[i].Body != w.body {
+			t.Errorf("section %d = %+v, want %s at line %d with body %q", i, sections[i], w.key, w.line, w.body)
+		}
+	}
+
+	if section, ok := FindSection(description, "expected"); !ok || section.Body != "The file is saved." {
+		t.Errorf("FindSection(expected) = %+v, %v", section, ok)
+	}
+	if _, ok := FindSection(description, "actual"); ok {
+		t.Error("FindSection(actual) found a section that isn't there")
+	}
+	if len(ParseSections("No sections here.\nAcceptance")) != 0 {
+		t.Error("Expected no sections without a header line")
+	}
+}
+
+func TestLookupSectionType(t *testing.T) {
+	for _, name := range []string{"acceptance", "Acceptance Criteria", "STEPS TO REPRODUCE"} {
+		if _, ok := LookupSectionType(name); !ok {
+			t.Errorf("LookupSectionType(%q) not found", name)
+		}
+	}
+	if _, ok := LookupSectionType("notes"); ok {
+		t.Error("LookupSectionType(notes) found an unknown section")
+	}
+}
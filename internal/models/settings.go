@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Settings keys
+const (
+	settingCurrentTask = "current_task" // focused task ID
+	settingRecentTasks = "recent_tasks" // comma-separated IDs from the last listing
+)
+
+// getSetting retrieves a workspace setting, returning "" if it is not set
+func (r *TaskRepository) getSetting(key string) (string, error) {
+	var value string
+	err := r.db.DB.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// setSetting stores a workspace setting, replacing any previous value
+func (r *TaskRepository) setSetting(key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// deleteSetting removes a workspace setting
+func (r *TaskRepository) deleteSetting(key string) error {
+	if _, err := r.db.Exec("DELETE FROM settings WHERE key = ?", key); err != nil {
+		return fmt.Errorf("failed to delete setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetCurrentTask stores the given task as the workspace's current task
+func (r *TaskRepository) SetCurrentTask(id string) error {
+	task, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return r.setSetting(settingCurrentTask, task.ID)
+}
+
+// GetCurrentTask returns the workspace's current task, or nil if none is set
+func (r *TaskRepository) GetCurrentTask() (*Task, error) {
+	id, err := r.getSetting(settingCurrentTask)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, nil
+	}
+
+	task, err := r.getByExactID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("current task %s no longer exists", id)
+	}
+	return task, nil
+}
+
+// ClearCurrentTask removes the workspace's current task
+func (r *TaskRepository) ClearCurrentTask() error {
+	return r.deleteSetting(settingCurrentTask)
+}
+
+// SetRecentTasks remembers the IDs of the most recently listed tasks so they
+// can be referred to as @1..@9
+func (r *TaskRepository) SetRecentTasks(tasks []*Task) error {
+	ids := make([]string, 0, MaxRecentTasks)
+	for _, task := range tasks {
+		if len(ids) == MaxRecentTasks {
+			break
+		}
+		ids = append(ids, task.ID)
+	}
+	return r.setSetting(settingRecentTasks, strings.Join(ids, ","))
+}
+
+// getRecentTaskID returns the ID at the given 1-based position of the last listing
+func (r *TaskRepository) getRecentTaskID(position int) (string, error) {
+	value, err := r.getSetting(settingRecentTasks)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", errors.NewNotFoundError("no recent task list (run 'gtd list' first)")
+	}
+
+	ids := strings.Split(value, ",")
+	if position < 1 || position > len(ids) {
+		return "", errors.NewValidationError("@%d is out of range: last listing had %d tasks", position, len(ids))
+	}
+	return ids[position-1], nil
+}
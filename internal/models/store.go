@@ -0,0 +1,93 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TaskStore stores tasks for the service layer. TaskRepository keeps them in
+// SQLite; MemoryStore keeps them in memory for tests and as a starting point
+// for other backends.
+type TaskStore interface {
+	Create(task *Task) error
+	GetByID(id string) (*Task, error)
+	Update(task *Task) error
+	Delete(id string) error
+
+	UpdateStateAt(id string, newState State, at time.Time) error
+	TransitionSubtree(id string, newState State) (changed []*Task, err error)
+	Block(taskID, blockingTaskID string) error
+	Unblock(taskID string) error
+
+	GetChildren(parentID string) ([]*Task, error)
+	GetBlockedTasks(blockerID string) ([]*Task, error)
+	List(opts ListOptions) ([]*Task, error)
+	ListByState(state State) ([]*Task, error)
+	Search(query string, fields ...string) ([]*Task, error)
+}
+
+// Store is the TaskStore the commands and the server work with, which also
+// keeps what is recorded about tasks: their history, links, attachments,
+// custom fields, and worklog, and the workspace's current and recently
+// listed tasks.
+type Store interface {
+	TaskStore
+
+	// SetChooser installs a function used to pick a task when a reference
+	// is ambiguous
+	SetChooser(choose ChooseFunc)
+	// SetTagSettings sets the per-tag settings enforced when tasks are
+	// started
+	SetTagSettings(settings TagSettings)
+	// LookupID retrieves a task by alias, ID, or unambiguous hash prefix
+	// only, without matching titles
+	LookupID(id string) (*Task, error)
+
+	CreateBatch(tasks []*Task) error
+	CreateFromComments(tasks []*Task, fingerprints []string) error
+	IsImported(fingerprint string) (bool, error)
+	FindImportMatch(id, externalRef string) (*Task, error)
+
+	CheckTransition(id string, newState State, at time.Time) (*Task, error)
+	PlanSubtreeTransition(id string, newState State) ([]*Task, error)
+	MergeInto(keepID, duplicateID string) error
+	Escalate(task *Task) error
+	MoveToTop(id string) (*Task, error)
+	MoveBefore(id, otherID string) (task, other *Task, err error)
+	SetPinned(id string, pinned bool) error
+
+	GetAncestors(id string) ([]*Task, error)
+	GetBlockerChain(id string) (chain []*Task, cyclic bool, err error)
+	GetSubtaskStats(parentIDs []string) (map[string]*SubtaskStats, error)
+	Count(opts ListOptions) (int, error)
+	Iterate(ctx context.Context, opts ListOptions, fn func(*Task) error) error
+	ListStale(cutoff time.Time) ([]*Task, error)
+	ListDeletedSince(since time.Time) ([]*Tombstone, error)
+
+	GetCurrentTask() (*Task, error)
+	SetCurrentTask(id string) error
+	ClearCurrentTask() error
+	SetRecentTasks(tasks []*Task) error
+
+	RecordReason(taskID, reason string) error
+	GetReason(taskID string) (string, error)
+	GetHistory(taskID string) ([]*HistoryEntry, error)
+	ListHistory(since time.Time) ([]*HistoryEntry, error)
+
+	AddLink(sourceID, targetID, linkType string) (*TaskLink, error)
+	RemoveLink(sourceID, targetID, linkType string) error
+	GetLinks(taskID string) ([]*TaskLink, error)
+	AddAttachment(taskID, location string) (*Attachment, error)
+	RemoveAttachment(taskID, location string) error
+	GetAttachments(taskID string) ([]*Attachment, error)
+	SetField(taskID, name, value string) error
+	GetFields(taskID string) (map[string]string, error)
+	AddWorklog(taskID string, started, ended time.Time, note string) (*WorklogEntry, error)
+	GetWorklog(taskID string) ([]*WorklogEntry, error)
+	ListWorklog(since time.Time) ([]*WorklogEntry, error)
+}
+
+var (
+	_ Store = (*TaskRepository)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
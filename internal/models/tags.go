@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// TagSettings configure tasks by tag, keyed by lowercase tag. The zero value
+// configures nothing.
+type TagSettings struct {
+	WIPLimits  map[string]int      // Maximum number of IN_PROGRESS tasks with the tag
+	Priorities map[string]Priority // Priority of new tasks with the tag, unless one is given
+}
+
+// Priority returns the highest priority configured for any of the tags in a
+// comma-separated list, or "" when none of them has one
+func (s TagSettings) Priority(tags string) Priority {
+	var priority Priority
+	for _, tag := range (&Task{Tags: tags}).ParseTags() {
+		p, ok := s.Priorities[strings.ToLower(tag)]
+		if ok && (priority == "" || listPriorityOrder(p) < listPriorityOrder(priority)) {
+			priority = p
+		}
+	}
+	return priority
+}
+
+// checkWIPLimits returns an error when starting task would take one of its
+// tags over its WIP limit, given the tasks already in progress
+func (s TagSettings) checkWIPLimits(task *Task, inProgress []*Task) error {
+	for _, tag := range task.ParseTags() {
+		limit, ok := s.WIPLimits[strings.ToLower(tag)]
+		if !ok {
+			continue
+		}
+		var started []string
+		for _, other := range inProgress {
+			if other.ID != task.ID && hasTag(other, tag) {
+				started = append(started, other.ShortHash())
+			}
+		}
+		if len(started) >= limit {
+			return errors.NewConflictError("tag %s allows %d task(s) in progress, already started: %s",
+				tag, limit, strings.Join(started, ", "))
+		}
+	}
+	return nil
+}
+
+// hasTag reports whether the task has tag, ignoring case
+func hasTag(task *Task, tag string) bool {
+	for _, t := range task.ParseTags() {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
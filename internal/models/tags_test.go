@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagPriority(t *testing.T) {
+	settings := TagSettings{
+		Priorities: map[string]Priority{"incident": PriorityHigh, "docs": PriorityLow},
+	}
+
+	tests := []struct {
+		tags string
+		want Priority
+	}{
+		{"", ""},
+		{"backend", ""},
+		{"docs", PriorityLow},
+		{"docs, Incident", PriorityHigh},
+	}
+	for _, tt := range tests {
+		if got := settings.Priority(tt.tags); got != tt.want {
+			t.Errorf("Priority(%q) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestWIPLimits(t *testing.T) {
+	stores := map[string]Store{
+		"sqlite": setupTestDB(t),
+		"memory": NewMemoryStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			store.SetTagSettings(TagSettings{WIPLimits: map[string]int{"incident": 1}})
+
+			var tasks []*Task
+			for _, tags := range []string{"incident", "Incident,backend", "backend"} {
+				task := NewTask(KindBug, "Task "+tags, "Details")
+				task.State = StateNew
+				task.Tags = tags
+				if err := store.Create(task); err != nil {
+					t.Fatal(err)
+				}
+				tasks = append(tasks, task)
+			}
+
+			if err := store.UpdateStateAt(tasks[0].ID, StateInProgress, time.Now()); err != nil {
+				t.Fatalf("starting the first incident: %v", err)
+			}
+			err := store.UpdateStateAt(tasks[1].ID, StateInProgress, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tasks[0].ShortHash()) {
+				t.Errorf("starting a second incident: error = %v, want the limit naming %s", err, tasks[0].ShortHash())
+			}
+			if err := store.UpdateStateAt(tasks[2].ID, StateInProgress, time.Now()); err != nil {
+				t.Errorf("starting an untagged task: %v", err)
+			}
+
+			// Finishing the first incident makes room
+			if err := store.UpdateStateAt(tasks[0].ID, StateDone, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.UpdateStateAt(tasks[1].ID, StateInProgress, time.Now()); err != nil {
+				t.Errorf("starting the second incident after the first: %v", err)
+			}
+		})
+	}
+}
@@ -8,40 +8,82 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/git"
 )
 
 // Task kinds
 const (
-	KindBug        = "BUG"
-	KindFeature    = "FEATURE"
-	KindRegression = "REGRESSION"
+	KindBug        Kind = "BUG"
+	KindFeature    Kind = "FEATURE"
+	KindRegression Kind = "REGRESSION"
 )
 
 // Task priorities
 const (
-	PriorityHigh   = "high"
-	PriorityMedium = "medium"
-	PriorityLow    = "low"
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+// Bug severities
+const (
+	SeverityCritical Severity = "critical"
+	SeverityMajor    Severity = "major"
+	SeverityMinor    Severity = "minor"
 )
 
 // Task states
 const (
-	StateInbox      = "INBOX"
-	StateNew        = "NEW"
-	StateInProgress = "IN_PROGRESS"
-	StateDone       = "DONE"
-	StateCancelled  = "CANCELLED"
-	StateInvalid    = "INVALID"
+	StateInbox      State = "INBOX"
+	StateNew        State = "NEW"
+	StateInProgress State = "IN_PROGRESS"
+	StateDone       State = "DONE"
+	StateCancelled  State = "CANCELLED"
+	StateInvalid    State = "INVALID"
 )
 
+// Transition is a state change a task may make, and the command making it
+type Transition struct {
+	To      State
+	Command string
+}
+
+// transitions lists the state changes allowed from each state. Every state
+// has an entry, so a state without one is not a task state.
+var transitions = map[State][]Transition{
+	StateInbox:      {{StateNew, "accept"}, {StateInvalid, "reject"}},
+	StateNew:        {{StateInProgress, "in-progress"}, {StateDone, "done"}, {StateCancelled, "cancel"}},
+	StateInProgress: {{StateDone, "done"}, {StateCancelled, "cancel"}},
+	StateDone:       {{StateInProgress, "in-progress"}},
+	StateCancelled:  {{StateNew, "reopen"}, {StateInProgress, "in-progress"}},
+	StateInvalid:    {}, // Rejected tasks are final
+}
+
+// Transitions returns the state changes allowed from state
+func Transitions(state State) []Transition {
+	return transitions[state]
+}
+
+// NewTransitionError reports that a task can't change from one state to
+// another, listing the changes it can make instead
+func NewTransitionError(from, to State) error {
+	var validStates []string
+	commands := make(map[string]string)
+	for _, transition := range transitions[from] {
+		validStates = append(validStates, transition.To.String())
+		commands[transition.To.String()] = transition.Command
+	}
+	return errors.NewInvalidStateTransitionError(from.String(), to.String(), validStates, commands)
+}
+
 // Task represents a task in the system
 type Task struct {
 	ID          string    `json:"id"`
 	Parent      *string   `json:"parent,omitempty"`
-	Priority    string    `json:"priority"`
-	State       string    `json:"state"`
-	Kind        string    `json:"kind"`
+	Priority    Priority  `json:"priority"`
+	State       State     `json:"state"`
+	Kind        Kind      `json:"kind"`
 	Title       string    `json:"title"`
 	Description string    `json:"description,omitempty"`
 	Author      string    `json:"author"`
@@ -50,10 +92,20 @@ type Task struct {
 	Source      string    `json:"source,omitempty"`
 	BlockedBy   *string   `json:"blocked_by,omitempty"`
 	Tags        string    `json:"tags,omitempty"`
+	Seq         int       `json:"seq,omitempty"`          // Sequential short ID, referenced as #N
+	ExternalRef string    `json:"external_ref,omitempty"` // Issue URL or ticket key
+	Rank        int       `json:"rank,omitempty"`         // Manual order within the priority, 0 if unranked
+	Pinned      bool      `json:"pinned,omitempty"`       // Listed before all other tasks
+	Severity    Severity  `json:"severity,omitempty"`     // Impact of a bug or regression, "" if not assessed
+
+	// Where a regression showed up: the release that introduced it and the
+	// environment it was seen in, e.g. "v2.1.0" and "prod"
+	IntroducedIn string `json:"introduced_in,omitempty"`
+	Environment  string `json:"environment,omitempty"`
 }
 
 // NewTask creates a new task with default values
-func NewTask(kind, title, description string) *Task {
+func NewTask(kind Kind, title, description string) *Task {
 	now := time.Now()
 
 	// Get author from git config
@@ -78,47 +130,49 @@ func NewTask(kind, title, description string) *Task {
 	return task
 }
 
-// Validate checks if the task has valid field values
+// Validate checks required fields and enumerated values. The configurable
+// title and description rules are checked separately, see ValidationRules.
 func (t *Task) Validate() error {
 	// Title is required
 	if strings.TrimSpace(t.Title) == "" {
-		return fmt.Errorf("title is required")
+		return errors.NewValidationError("title is required")
 	}
 
 	// Description is required
 	if strings.TrimSpace(t.Description) == "" {
-		return fmt.Errorf("description is required - tasks must have a body explaining the work")
+		return errors.NewValidationError("description is required - tasks must have a body explaining the work")
 	}
 
 	// Validate kind
-	switch t.Kind {
-	case KindBug, KindFeature, KindRegression:
-		// valid
-	default:
-		return fmt.Errorf("invalid kind: %s", t.Kind)
+	if !t.Kind.IsValid() {
+		return errors.NewValidationError("invalid kind: %s", t.Kind)
 	}
 
 	// Validate priority
-	switch t.Priority {
-	case PriorityHigh, PriorityMedium, PriorityLow:
-		// valid
-	default:
-		return fmt.Errorf("invalid priority: %s", t.Priority)
+	if !t.Priority.IsValid() {
+		return errors.NewValidationError("invalid priority: %s", t.Priority)
 	}
 
 	// Validate state
-	switch t.State {
-	case StateInbox, StateNew, StateInProgress, StateDone, StateCancelled, StateInvalid:
-		// valid
-	default:
-		return fmt.Errorf("invalid state: %s", t.State)
+	if !t.State.IsValid() {
+		return errors.NewValidationError("invalid state: %s", t.State)
+	}
+
+	// Severity is optional, and only rates bugs and regressions
+	if t.Severity != "" {
+		if !t.Severity.IsValid() {
+			return errors.NewValidationError("invalid severity: %s", t.Severity)
+		}
+		if t.Kind == KindFeature {
+			return errors.NewValidationError("severity only applies to bugs and regressions")
+		}
 	}
 
 	return nil
 }
 
 // CanTransitionTo checks if the task can transition to the given state
-func (t *Task) CanTransitionTo(newState string, children []*Task) bool {
+func (t *Task) CanTransitionTo(newState State, children []*Task) bool {
 	// First check if parent task can be marked as DONE
 	if newState == StateDone && len(children) > 0 {
 		// Parent can only be DONE if all children are DONE or CANCELLED
@@ -129,29 +183,13 @@ func (t *Task) CanTransitionTo(newState string, children []*Task) bool {
 		}
 	}
 
-	// Check basic state transitions
-	switch t.State {
-	case StateInbox:
-		// Can only transition to NEW (accept) or INVALID (reject)
-		return newState == StateNew || newState == StateInvalid
-	case StateNew:
-		// Can transition to IN_PROGRESS, DONE, or CANCELLED from NEW
-		return newState == StateInProgress || newState == StateDone || newState == StateCancelled
-	case StateInProgress:
-		// Can transition to DONE or CANCELLED
-		return newState == StateDone || newState == StateCancelled
-	case StateDone:
-		// Can only transition back to IN_PROGRESS
-		return newState == StateInProgress
-	case StateCancelled:
-		// Can transition to NEW or IN_PROGRESS
-		return newState == StateNew || newState == StateInProgress
-	case StateInvalid:
-		// Invalid tasks cannot transition to other states
-		return false
+	// Check basic state transitions; unknown states allow none
+	for _, transition := range transitions[t.State] {
+		if transition.To == newState {
+			return true
+		}
 	}
-
-	return true
+	return false
 }
 
 // IsBlocked returns true if the task is blocked by another task
@@ -182,7 +220,7 @@ func (t *Task) SetTags(tags []string) {
 }
 
 // generateTaskHash creates a unique hash for a task based on its content
-func generateTaskHash(kind, title, description string, created time.Time) string {
+func generateTaskHash(kind Kind, title, description string, created time.Time) string {
 	// Create a hash based on content and timestamp to ensure uniqueness
 	h := sha1.New()
 	// sha1.Hash implements io.Writer and never returns an error
@@ -191,12 +229,42 @@ func generateTaskHash(kind, title, description string, created time.Time) string
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// ShortHash returns the first 7 characters of the hash (like git)
+// ConvertTo changes the kind of the task, clearing the fields that don't
+// apply to the new kind: the severity of a feature, and the release and
+// environment of anything but a regression. It returns the names of the
+// fields it cleared.
+func (t *Task) ConvertTo(kind Kind) []string {
+	var cleared []string
+	if kind == KindFeature && t.Severity != "" {
+		t.Severity = ""
+		cleared = append(cleared, "severity")
+	}
+	if kind != KindRegression {
+		if t.IntroducedIn != "" {
+			t.IntroducedIn = ""
+			cleared = append(cleared, "introduced_in")
+		}
+		if t.Environment != "" {
+			t.Environment = ""
+			cleared = append(cleared, "environment")
+		}
+	}
+	t.Kind = kind
+	return cleared
+}
+
+// ShortHash returns the start of the hash (like git), 7 characters unless
+// more are needed to tell the task apart from others
 func (t *Task) ShortHash() string {
-	if len(t.ID) >= 7 {
-		return t.ID[:7]
+	return ShortID(t.ID)
+}
+
+// SeqRef returns the sequential short ID as "#N", or "" if none is assigned
+func (t *Task) SeqRef() string {
+	if t.Seq == 0 {
+		return ""
 	}
-	return t.ID
+	return fmt.Sprintf("#%d", t.Seq)
 }
 
 // GetID returns the task ID (implements errors.Task interface)
@@ -92,6 +92,55 @@ func TestTaskValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid state",
 		},
+		{
+			name: "valid rejected task",
+			task: Task{
+				Kind:        KindBug,
+				Title:       "Not a bug",
+				Description: "Works as intended",
+				Priority:    PriorityLow,
+				State:       StateInvalid,
+			},
+			wantErr: false,
+		},
+		{
+			name: "bug with severity",
+			task: Task{
+				Kind:        KindRegression,
+				Title:       "Login broken",
+				Description: "Login fails since the last release",
+				Priority:    PriorityHigh,
+				State:       StateNew,
+				Severity:    SeverityCritical,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid severity",
+			task: Task{
+				Kind:        KindBug,
+				Title:       "Fix crash",
+				Description: "The app crashes on start",
+				Priority:    PriorityHigh,
+				State:       StateNew,
+				Severity:    "blocker",
+			},
+			wantErr: true,
+			errMsg:  "invalid severity",
+		},
+		{
+			name: "feature with severity",
+			task: Task{
+				Kind:        KindFeature,
+				Title:       "Add dark mode",
+				Description: "Implement dark mode theme",
+				Priority:    PriorityHigh,
+				State:       StateNew,
+				Severity:    SeverityMinor,
+			},
+			wantErr: true,
+			errMsg:  "severity only applies to bugs and regressions",
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,11 +160,11 @@ func TestTaskValidate(t *testing.T) {
 func TestTaskCanTransitionTo(t *testing.T) {
 	tests := []struct {
 		name       string
-		from       string
-		to         string
+		from       State
+		to         State
 		canMove    bool
 		hasChild   bool
-		childState string
+		childState State
 	}{
 		// INBOX state transitions
 		{
@@ -265,6 +314,69 @@ func TestTaskCanTransitionTo(t *testing.T) {
 	}
 }
 
+func TestStateTransitionTable(t *testing.T) {
+	// allowed[from] lists every state a task may move to from each state
+	allowed := map[State][]State{
+		StateInbox:      {StateNew, StateInvalid},
+		StateNew:        {StateInProgress, StateDone, StateCancelled},
+		StateInProgress: {StateDone, StateCancelled},
+		StateDone:       {StateInProgress},
+		StateCancelled:  {StateNew, StateInProgress},
+		StateInvalid:    nil,
+	}
+
+	for _, from := range States {
+		for _, to := range States {
+			want := false
+			for _, state := range allowed[from] {
+				want = want || state == to
+			}
+			task := &Task{State: from}
+			if got := task.CanTransitionTo(to, nil); got != want {
+				t.Errorf("%s -> %s: CanTransitionTo() = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	// Every state has an entry, and only states do
+	for _, state := range States {
+		if _, ok := transitions[state]; !ok {
+			t.Errorf("No transitions entry for %s", state)
+		}
+		for _, transition := range Transitions(state) {
+			if !transition.To.IsValid() {
+				t.Errorf("%s -> %s: unknown target state", state, transition.To)
+			}
+			if transition.Command == "" {
+				t.Errorf("%s -> %s: no command", state, transition.To)
+			}
+		}
+	}
+	if len(transitions) != len(States) {
+		t.Errorf("transitions has %d entries, want %d", len(transitions), len(States))
+	}
+
+	// Unknown states can't move anywhere
+	for _, task := range []*Task{{State: "BLOCKED"}, {State: ""}} {
+		if task.CanTransitionTo(StateNew, nil) {
+			t.Errorf("%q -> NEW allowed", task.State)
+		}
+	}
+}
+
+func TestNewTransitionError(t *testing.T) {
+	err := NewTransitionError(StateInbox, StateDone)
+	for _, want := range []string{"cannot transition from INBOX to DONE", "NEW (use 'gtd accept')", "INVALID (use 'gtd reject')"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+
+	if err := NewTransitionError(StateInvalid, StateNew); strings.Contains(err.Error(), "Valid transitions") {
+		t.Errorf("Error() = %q, want no valid transitions from INVALID", err.Error())
+	}
+}
+
 func TestTaskIsBlocked(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -409,3 +521,34 @@ func TestNewTask(t *testing.T) {
 		t.Errorf("NewTask() Updated time not within expected range")
 	}
 }
+
+func TestTaskConvertTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		to          Kind
+		wantCleared []string
+	}{
+		{"to bug keeps the severity", KindBug, []string{"introduced_in", "environment"}},
+		{"to feature clears the severity", KindFeature, []string{"severity", "introduced_in", "environment"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := NewTask(KindRegression, "Login broken", "Fails after the update")
+			task.Severity = SeverityMajor
+			task.IntroducedIn = "v2.1.0"
+			task.Environment = "prod"
+
+			cleared := task.ConvertTo(tt.to)
+			if task.Kind != tt.to {
+				t.Errorf("Kind = %v, want %v", task.Kind, tt.to)
+			}
+			if strings.Join(cleared, ",") != strings.Join(tt.wantCleared, ",") {
+				t.Errorf("ConvertTo() cleared %v, want %v", cleared, tt.wantCleared)
+			}
+			if err := task.Validate(); err != nil {
+				t.Errorf("converted task is invalid: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// RuleViolationError reports a title or description that breaks a configured
+// validation rule. Unlike other validation errors it can be bypassed.
+type RuleViolationError struct {
+	Message string
+}
+
+func (e *RuleViolationError) Error() string {
+	return e.Message
+}
+
+// Code returns errors.CodeValidation
+func (e *RuleViolationError) Code() errors.Code {
+	return errors.CodeValidation
+}
+
+// IsRuleViolation reports whether err is or wraps a RuleViolationError
+func IsRuleViolation(err error) bool {
+	var violation *RuleViolationError
+	return stderrors.As(err, &violation)
+}
+
+// ruleViolation creates a RuleViolationError with a formatted message
+func ruleViolation(format string, args ...interface{}) error {
+	return &RuleViolationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationRules are optional checks on task titles and descriptions.
+// The zero value disables all rules.
+type ValidationRules struct {
+	MaxTitleLength       int  // Maximum title length in characters, 0 for no limit
+	MinDescriptionLength int  // Minimum description length in characters, 0 for no minimum
+	NoTrailingPeriod     bool // Reject titles ending with a period
+	ConventionalTitles   bool // Require a conventional-commit prefix such as "fix: " or "feat(ui): "
+}
+
+// conventionalTitle matches titles like "fix: ...", "feat(ui): ..." or "refactor!: ..."
+var conventionalTitle = regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([^()]+\))?!?: \S`)
+
+// Check returns the first rule the task breaks, if any
+func (rules ValidationRules) Check(t *Task) error {
+	title := strings.TrimSpace(t.Title)
+	description := strings.TrimSpace(t.Description)
+
+	if n := utf8.RuneCountInString(title); rules.MaxTitleLength > 0 && n > rules.MaxTitleLength {
+		return ruleViolation("title is %d characters long, maximum is %d", n, rules.MaxTitleLength)
+	}
+	if rules.NoTrailingPeriod && strings.HasSuffix(title, ".") {
+		return ruleViolation("title must not end with a period")
+	}
+	if rules.ConventionalTitles && !conventionalTitle.MatchString(title) {
+		return ruleViolation("title must start with a conventional prefix such as \"fix: \" or \"feat(scope): \"")
+	}
+	if n := utf8.RuneCountInString(description); rules.MinDescriptionLength > 0 && n < rules.MinDescriptionLength {
+		return ruleViolation("description is %d characters long, minimum is %d", n, rules.MinDescriptionLength)
+	}
+	return nil
+}
+
+// CheckUpdate checks an edited task like Check, but only when its title or
+// description changed, so tasks created before a rule was configured can
+// still be updated
+func (rules ValidationRules) CheckUpdate(before, after *Task) error {
+	if after.Title == before.Title && after.Description == before.Description {
+		return nil
+	}
+	return rules.Check(after)
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskValidateRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       ValidationRules
+		title       string
+		description string
+		errMsg      string
+	}{
+		{
+			name:        "no rules",
+			title:       "A rather long title that ends with a period.",
+			description: "Short",
+		},
+		{
+			name:        "title too long",
+			rules:       ValidationRules{MaxTitleLength: 10},
+			title:       "Title longer than ten",
+			description: "Description",
+			errMsg:      "title is 21 characters long, maximum is 10",
+		},
+		{
+			name:        "title length counts characters",
+			rules:       ValidationRules{MaxTitleLength: 5},
+			title:       "Über!",
+			description: "Description",
+		},
+		{
+			name:        "description too short",
+			rules:       ValidationRules{MinDescriptionLength: 20},
+			title:       "Fix crash",
+			description: "Crashes",
+			errMsg:      "description is 7 characters long, minimum is 20",
+		},
+		{
+			name:        "trailing period",
+			rules:       ValidationRules{NoTrailingPeriod: true},
+			title:       "Fix crash.",
+			description: "Description",
+			errMsg:      "title must not end with a period",
+		},
+		{
+			name:        "missing conventional prefix",
+			rules:       ValidationRules{ConventionalTitles: true},
+			title:       "Fix crash",
+			description: "Description",
+			errMsg:      "conventional prefix",
+		},
+		{
+			name:        "conventional prefix with scope",
+			rules:       ValidationRules{ConventionalTitles: true},
+			title:       "fix(parser): handle empty input",
+			description: "Description",
+		},
+		{
+			name:        "breaking conventional prefix",
+			rules:       ValidationRules{ConventionalTitles: true},
+			title:       "feat!: drop legacy flags",
+			description: "Description",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Check(NewTask(KindBug, tt.title, tt.description))
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Check() error = %v, want containing %q", err, tt.errMsg)
+			}
+			if !IsRuleViolation(err) {
+				t.Errorf("IsRuleViolation(%v) = false, want true", err)
+			}
+		})
+	}
+}
+
+func TestValidationRules_CheckUpdateKeepsLegacyTitles(t *testing.T) {
+	rules := ValidationRules{NoTrailingPeriod: true}
+	before := NewTask(KindBug, "Legacy title.", "Created before the rule existed")
+
+	// Unrelated edits are still allowed
+	after := *before
+	after.Priority = PriorityHigh
+	if err := rules.CheckUpdate(before, &after); err != nil {
+		t.Errorf("CheckUpdate() of priority error = %v, want nil", err)
+	}
+
+	// Editing the title applies the rules
+	after.Title = "Edited legacy title."
+	if err := rules.CheckUpdate(before, &after); !IsRuleViolation(err) {
+		t.Errorf("CheckUpdate() of title error = %v, want rule violation", err)
+	}
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// WorklogEntry is an interval of time spent working on a task
+type WorklogEntry struct {
+	ID      int64     `json:"id"`
+	TaskID  string    `json:"task_id"`
+	Author  string    `json:"author"`
+	Started time.Time `json:"started"`
+	Ended   time.Time `json:"ended"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// Duration returns the length of the interval
+func (e *WorklogEntry) Duration() time.Duration {
+	return e.Ended.Sub(e.Started)
+}
+
+// AddWorklog records an interval of work on a task
+func (r *TaskRepository) AddWorklog(taskID string, started, ended time.Time, note string) (*WorklogEntry, error) {
+	if ended.Before(started) {
+		return nil, errors.NewValidationError("work interval ends before it starts")
+	}
+
+	task, err := r.GetByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &WorklogEntry{TaskID: task.ID, Author: r.actor(), Started: started, Ended: ended, Note: note}
+	err = database.Retry(func() error {
+		return r.db.DB.QueryRow(`
+			INSERT INTO task_worklog (task_id, author, started, ended, note)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING id
+		`, entry.TaskID, entry.Author, database.FormatTime(started), database.FormatTime(ended), note).Scan(&entry.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add worklog entry: %w", err)
+	}
+	return entry, nil
+}
+
+// worklogColumns is the column list selected by worklog queries, in scanWorklog order
+const worklogColumns = `id, task_id, author, started, ended, COALESCE(note, '')`
+
+// GetWorklog retrieves the work intervals recorded for a task, oldest first
+func (r *TaskRepository) GetWorklog(taskID string) ([]*WorklogEntry, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+worklogColumns+`
+		FROM task_worklog
+		WHERE task_id = ?
+		ORDER BY started ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worklog: %w", err)
+	}
+	return scanWorklog(rows)
+}
+
+// ListWorklog retrieves work intervals on all tasks started at or after since, oldest first
+func (r *TaskRepository) ListWorklog(since time.Time) ([]*WorklogEntry, error) {
+	rows, err := r.db.DB.Query(`
+		SELECT `+worklogColumns+`
+		FROM task_worklog
+		WHERE started >= ?
+		ORDER BY started ASC, id ASC
+	`, database.FormatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worklog: %w", err)
+	}
+	return scanWorklog(rows)
+}
+
+// scanWorklog scans worklog rows selected with worklogColumns and closes them
+func scanWorklog(rows *sql.Rows) ([]*WorklogEntry, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't override the main error
+			logRowsCloseError(err)
+		}
+	}()
+
+	var entries []*WorklogEntry
+	for rows.Next() {
+		entry := &WorklogEntry{}
+		if err := rows.Scan(&entry.ID, &entry.TaskID, &entry.Author, &entry.Started, &entry.Ended, &entry.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan worklog entry: %w", err)
+		}
+		inLocalTime(&entry.Started, &entry.Ended)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_Worklog(t *testing.T) {
+	repo := setupTestDB(t)
+	repo.author = "Alice <alice@example.com>"
+
+	task := NewTask(KindFeature, "Write docs", "Document the worklog")
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	if _, err := repo.AddWorklog(task.ShortHash(), start, start.Add(25*time.Minute), "pomodoro"); err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if _, err := repo.AddWorklog(task.ID, start.Add(time.Hour), start.Add(time.Hour+10*time.Minute), ""); err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if _, err := repo.AddWorklog(task.ID, start, start.Add(-time.Minute), ""); err == nil {
+		t.Error("Expected error for an interval ending before it starts")
+	}
+
+	entries, err := repo.GetWorklog(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("GetWorklog() = %d entries, want 2", len(entries))
+	}
+	if entries[0].Duration() != 25*time.Minute || entries[0].Note != "pomodoro" || entries[0].Author != "Alice <alice@example.com>" {
+		t.Errorf("First entry = %+v", entries[0])
+	}
+
+	recent, err := repo.ListWorklog(start.Add(30 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Duration() != 10*time.Minute {
+		t.Errorf("ListWorklog() = %+v, want the second entry only", recent)
+	}
+}
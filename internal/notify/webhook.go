@@ -0,0 +1,73 @@
+// Package notify posts messages about task events to chat incoming webhooks,
+// such as those of Slack and Mattermost
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Events a webhook can be notified of
+const (
+	EventDone         = "done"          // A task was marked as done
+	EventHighPriority = "high-priority" // A high-priority task was added
+	EventInboxFull    = "inbox-full"    // The inbox grew past GTD_INBOX_LIMIT
+)
+
+// Events lists every event, in the order they are documented
+var Events = []string{EventDone, EventHighPriority, EventInboxFull}
+
+// timeout bounds a webhook request, so a slow chat server doesn't hold up
+// the command that triggered it
+const timeout = 5 * time.Second
+
+// Webhook posts messages to an incoming webhook URL for the events it is
+// subscribed to
+type Webhook struct {
+	url    string
+	events map[string]bool
+	client *http.Client
+}
+
+// NewWebhook creates a webhook that posts to url for the given events
+func NewWebhook(url string, events []string) *Webhook {
+	w := &Webhook{
+		url:    url,
+		events: make(map[string]bool, len(events)),
+		client: &http.Client{Timeout: timeout},
+	}
+	for _, event := range events {
+		w.events[event] = true
+	}
+	return w
+}
+
+// Wants reports whether the webhook is subscribed to an event
+func (w *Webhook) Wants(event string) bool {
+	return w != nil && w.events[event]
+}
+
+// Post sends a message to the webhook. Slack and Mattermost both accept a
+// JSON object with the message text.
+func (w *Webhook) Post(text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
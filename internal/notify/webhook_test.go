@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookPost(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got["text"] == "fail" {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, []string{EventDone})
+	if !webhook.Wants(EventDone) || webhook.Wants(EventInboxFull) {
+		t.Error("Expected the webhook to want only the done event")
+	}
+
+	if err := webhook.Post("Done: Fix login crash"); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got["text"] != "Done: Fix login crash" {
+		t.Errorf("posted %v", got)
+	}
+
+	if err := webhook.Post("fail"); err == nil {
+		t.Error("Expected an error for a rejected message")
+	}
+
+	var unset *Webhook
+	if unset.Wants(EventDone) {
+		t.Error("Expected an unset webhook to want nothing")
+	}
+}
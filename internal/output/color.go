@@ -0,0 +1,154 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// ANSI escape codes of the default color scheme
+const (
+	ANSIReset = "\033[0m"
+	ANSIBold  = "\033[1m"
+
+	ANSIRed    = "\033[31m"
+	ANSIGreen  = "\033[32m"
+	ANSIYellow = "\033[33m"
+	ANSIBlue   = "\033[34m"
+	ANSICyan   = "\033[36m"
+	ANSIGray   = "\033[90m"
+
+	ANSIBrightRed    = "\033[91m"
+	ANSIBrightGreen  = "\033[92m"
+	ANSIBrightYellow = "\033[93m"
+)
+
+// NamedColors are the colors that can be configured by name, e.g. for tags
+var NamedColors = map[string]string{
+	"red":           ANSIRed,
+	"green":         ANSIGreen,
+	"yellow":        ANSIYellow,
+	"blue":          ANSIBlue,
+	"cyan":          ANSICyan,
+	"gray":          ANSIGray,
+	"bright-red":    ANSIBrightRed,
+	"bright-green":  ANSIBrightGreen,
+	"bright-yellow": ANSIBrightYellow,
+}
+
+// ColorScheme holds the escape codes a Formatter colors each part of a task
+// with. Parts without a code, and all parts with a nil scheme, are written
+// plain.
+type ColorScheme struct {
+	Hash     string // Task IDs and hashes
+	Muted    string // Sequential IDs and progress counts
+	Title    string
+	Tags     string
+	Ref      string // External references
+	Blocked  string // Blocked markers and blockers
+	Pinned   string
+	State    map[models.State]string
+	Kind     map[models.Kind]string
+	Priority map[models.Priority]string
+
+	// TagColors color the titles and tags of tasks with these tags, keyed
+	// by lowercase tag
+	TagColors map[string]string
+}
+
+// DefaultColors is the color scheme of gtd on color terminals
+var DefaultColors = &ColorScheme{
+	Hash:    ANSIYellow,
+	Muted:   ANSIGray,
+	Title:   ANSIBold,
+	Tags:    ANSIBlue,
+	Ref:     ANSICyan,
+	Blocked: ANSIRed,
+	Pinned:  ANSIYellow,
+	State: map[models.State]string{
+		models.StateNew:        ANSICyan,
+		models.StateInProgress: ANSIBrightYellow,
+		models.StateDone:       ANSIBrightGreen,
+		models.StateCancelled:  ANSIGray,
+	},
+	Kind: map[models.Kind]string{
+		models.KindBug:        ANSIRed,
+		models.KindFeature:    ANSIGreen,
+		models.KindRegression: ANSIYellow,
+	},
+	Priority: map[models.Priority]string{
+		models.PriorityHigh:   ANSIBrightRed,
+		models.PriorityMedium: ANSIYellow,
+		models.PriorityLow:    ANSIGreen,
+	},
+}
+
+// paint wraps text in code, leaving it plain when either is empty
+func paint(text, code string) string {
+	if text == "" || code == "" {
+		return text
+	}
+	return code + text + ANSIReset
+}
+
+// or returns the scheme, or one without colors when it is nil
+func (s *ColorScheme) or() *ColorScheme {
+	if s == nil {
+		return &ColorScheme{}
+	}
+	return s
+}
+
+// WithTagColors returns a copy of the scheme that also colors tasks by tag
+func (s ColorScheme) WithTagColors(colors map[string]string) *ColorScheme {
+	s.TagColors = colors
+	return &s
+}
+
+// tagColor returns the code of the first of the tags with a color, or ""
+func (s *ColorScheme) tagColor(tags []string) string {
+	for _, tag := range tags {
+		if code, ok := s.TagColors[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// TaskTitle returns the title of a task, colored like its first tag with a
+// color, if any
+func (s *ColorScheme) TaskTitle(task *models.Task) string {
+	s = s.or()
+	if code := s.tagColor(task.ParseTags()); code != "" {
+		return paint(task.Title, code)
+	}
+	return paint(task.Title, s.Title)
+}
+
+// StateIcon returns the colored marker for a task state
+func (s *ColorScheme) StateIcon(state models.State) string {
+	return paint(StateIcon(state), s.or().State[state])
+}
+
+// KindPriority returns "kind(priority):" with both parts colored
+func (s *ColorScheme) KindPriority(kind models.Kind, priority models.Priority) string {
+	s = s.or()
+	return paint(strings.ToLower(kind.String()), s.Kind[kind]) + "(" + paint(priority.String(), s.Priority[priority]) + "):"
+}
+
+// TagList returns comma-separated tags as colored "#tag" words
+func (s *ColorScheme) TagList(tags string) string {
+	if tags == "" {
+		return ""
+	}
+	s = s.or()
+	tagList := strings.Split(tags, ",")
+	for i, tag := range tagList {
+		code := s.tagColor([]string{tag})
+		if code == "" {
+			code = s.Tags
+		}
+		tagList[i] = paint("#"+strings.TrimSpace(tag), code)
+	}
+	return strings.Join(tagList, " ")
+}
@@ -9,219 +9,47 @@ import (
 	"github.com/zw3rk/gtd/internal/output"
 )
 
-// ColorFormatter simulates the color formatting from cmd/format.go
-type ColorFormatter struct {
-	useColor bool
-}
-
-// ANSI color codes
-const (
-	colorReset      = "\033[0m"
-	colorBold       = "\033[1m"
-	colorRed        = "\033[31m"
-	colorGreen      = "\033[32m"
-	colorYellow     = "\033[33m"
-	colorBlue       = "\033[34m"
-	colorMagenta    = "\033[35m"
-	colorCyan       = "\033[36m"
-	colorBrightRed  = "\033[91m"
-	colorBrightBlue = "\033[94m"
-	colorDim        = "\033[2m"
-)
-
-func (f *ColorFormatter) colorize(text, color string) string {
-	if !f.useColor {
-		return text
-	}
-	return color + text + colorReset
-}
-
-func (f *ColorFormatter) formatStateColor(state string) string {
-	icon := getStateIcon(state)
-	if !f.useColor {
-		return icon
-	}
-	
-	switch state {
-	case models.StateNew:
-		return f.colorize(icon, colorGreen)
-	case models.StateInProgress:
-		return f.colorize(icon, colorYellow)
-	case models.StateDone:
-		return f.colorize(icon, colorGreen)
-	case models.StateCancelled:
-		return f.colorize(icon, colorDim)
-	default:
-		return icon
-	}
-}
-
-func (f *ColorFormatter) formatKindPriorityColor(kind, priority string) string {
-	// Format the kind part
-	kindLower := strings.ToLower(kind)
-	var kindColored string
-	switch kind {
-	case models.KindBug:
-		kindColored = f.colorize(kindLower, colorRed)
-	case models.KindFeature:
-		kindColored = f.colorize(kindLower, colorGreen)
-	case models.KindRegression:
-		kindColored = f.colorize(kindLower, colorYellow)
-	default:
-		kindColored = kindLower
-	}
-	
-	// Format the priority part
-	var priorityColored string
-	switch priority {
-	case models.PriorityHigh:
-		priorityColored = f.colorize(priority, colorBrightRed)
-	case models.PriorityMedium:
-		priorityColored = f.colorize(priority, colorYellow)
-	case models.PriorityLow:
-		priorityColored = f.colorize(priority, colorGreen)
-	default:
-		priorityColored = priority
-	}
-	
-	return fmt.Sprintf("%s(%s): ", kindColored, priorityColored)
-}
-
-func (f *ColorFormatter) formatTagsColor(tags string) string {
-	if !f.useColor {
-		return "#" + tags
-	}
-	return f.colorize("#"+tags, colorBlue)
-}
-
-func (f *ColorFormatter) FormatTask(task *models.Task, subtaskStats *output.SubtaskStats) string {
-	var b strings.Builder
-	
-	// Line 1: task <full-hash>
-	if f.useColor {
-		b.WriteString(f.colorize("task", colorYellow))
-		b.WriteString(" ")
-		b.WriteString(f.colorize(task.ID, colorYellow))
-	} else {
-		b.WriteString(fmt.Sprintf("task %s", task.ID))
-	}
-	b.WriteString("\n")
-	
-	// Line 2: Author: Name <email>
-	b.WriteString("Author: ")
-	b.WriteString(task.Author)
-	b.WriteString("\n")
-	
-	// Line 3: Date: timestamp
-	b.WriteString("Date:   ")
-	b.WriteString(task.Created.Format("Mon Jan 2 15:04:05 2006 -0700"))
-	b.WriteString("\n\n")
-	
-	// Line 4 (indented): state indicator + kind(priority): title
-	b.WriteString("  ")
-	
-	// State indicator
-	b.WriteString(f.formatStateColor(task.State))
-	b.WriteString(" ")
-	
-	// Format kind(priority):
-	if f.useColor {
-		b.WriteString(f.formatKindPriorityColor(task.Kind, task.Priority))
-	} else {
-		b.WriteString(fmt.Sprintf("%s(%s): ", strings.ToLower(task.Kind), task.Priority))
-	}
-	
-	// Title
-	title := task.Title
-	if subtaskStats != nil && subtaskStats.Total > 0 {
-		title = fmt.Sprintf("%s (%d/%d)", task.Title, subtaskStats.Done, subtaskStats.Total)
-	}
-	if f.useColor {
-		b.WriteString(f.colorize(title, colorBold))
-	} else {
-		b.WriteString(title)
-	}
-	b.WriteString("\n")
-	
-	// Body (indented with extra indent)
-	if task.Description != "" {
-		b.WriteString("\n")
-		for _, line := range strings.Split(task.Description, "\n") {
-			b.WriteString("    ")
-			b.WriteString(line)
-			b.WriteString("\n")
-		}
-	}
-	
-	// Blocked-by (if applicable)
-	if task.IsBlocked() && task.BlockedBy != nil {
-		b.WriteString("\n    Blocked-by: ")
-		if f.useColor {
-			b.WriteString(f.colorize(*task.BlockedBy, colorRed))
-		} else {
-			b.WriteString(*task.BlockedBy)
-		}
-		b.WriteString("\n")
-	}
-	
-	return b.String()
-}
-
-func getStateIcon(state string) string {
-	switch state {
-	case models.StateInbox:
-		return "?"
-	case models.StateNew:
-		return "◆"
-	case models.StateInProgress:
-		return "▶"
-	case models.StateDone:
-		return "✓"
-	case models.StateCancelled:
-		return "✗"
-	case models.StateInvalid:
-		return "⊘"
-	default:
-		return "·"
+// colorFormatter returns a formatter using the default colors, or none
+func colorFormatter(useColor bool) *output.Formatter {
+	if useColor {
+		return output.NewColorFormatter(nil, output.DefaultColors)
 	}
+	return output.NewColorFormatter(nil, nil)
 }
 
-// Tests
 func TestColorFormatting(t *testing.T) {
 	task := createTestTask("color123", "Colorful Task")
-	
+
 	t.Run("Colors enabled", func(t *testing.T) {
-		formatter := &ColorFormatter{useColor: true}
-		output := formatter.FormatTask(task, nil)
-		
+		out := colorFormatter(true).GitStyle(task, nil)
+
 		// Should contain ANSI color codes
-		if !strings.Contains(output, "\033[") {
+		if !strings.Contains(out, "\033[") {
 			t.Error("Expected ANSI color codes in output with colors enabled")
 		}
-		
+
 		// Check specific color codes
-		if !strings.Contains(output, colorYellow) {
+		if !strings.Contains(out, output.ANSIYellow+task.ID) {
 			t.Error("Expected yellow color for task hash")
 		}
-		if !strings.Contains(output, colorBold) {
+		if !strings.Contains(out, output.ANSIBold+task.Title) {
 			t.Error("Expected bold color for title")
 		}
 	})
-	
+
 	t.Run("Colors disabled", func(t *testing.T) {
-		formatter := &ColorFormatter{useColor: false}
-		output := formatter.FormatTask(task, nil)
-		
+		out := colorFormatter(false).GitStyle(task, nil)
+
 		// Should NOT contain ANSI color codes
-		if strings.Contains(output, "\033[") {
+		if strings.Contains(out, "\033[") {
 			t.Error("Should not contain ANSI color codes with colors disabled")
 		}
-		
+
 		// Should still contain all the content
-		if !strings.Contains(output, task.ID) {
+		if !strings.Contains(out, task.ID) {
 			t.Error("Missing task ID in non-colored output")
 		}
-		if !strings.Contains(output, task.Title) {
+		if !strings.Contains(out, task.Title) {
 			t.Error("Missing task title in non-colored output")
 		}
 	})
@@ -229,98 +57,90 @@ func TestColorFormatting(t *testing.T) {
 
 func TestColoredStateIcons(t *testing.T) {
 	states := []struct {
-		state         string
+		state         models.State
 		expectedIcon  string
 		expectedColor string
 	}{
-		{models.StateNew, "◆", colorGreen},
-		{models.StateInProgress, "▶", colorYellow},
-		{models.StateDone, "✓", colorGreen},
-		{models.StateCancelled, "✗", colorDim},
+		{models.StateNew, "◆", output.ANSICyan},
+		{models.StateInProgress, "▶", output.ANSIBrightYellow},
+		{models.StateDone, "✓", output.ANSIBrightGreen},
+		{models.StateCancelled, "✗", output.ANSIGray},
 	}
-	
-	formatter := &ColorFormatter{useColor: true}
-	
+
 	for _, tt := range states {
-		t.Run(tt.state, func(t *testing.T) {
-			colored := formatter.formatStateColor(tt.state)
-			
-			// Should contain the icon
-			if !strings.Contains(colored, tt.expectedIcon) {
-				t.Errorf("Missing icon %s for state %s", tt.expectedIcon, tt.state)
-			}
-			
-			// Should contain the color code
-			if !strings.Contains(colored, tt.expectedColor) {
-				t.Errorf("Missing color code %s for state %s", tt.expectedColor, tt.state)
-			}
-			
-			// Should end with reset
-			if !strings.Contains(colored, colorReset) {
-				t.Error("Missing color reset code")
+		t.Run(tt.state.String(), func(t *testing.T) {
+			colored := output.DefaultColors.StateIcon(tt.state)
+			if colored != tt.expectedColor+tt.expectedIcon+output.ANSIReset {
+				t.Errorf("StateIcon(%s) = %q", tt.state, colored)
 			}
 		})
 	}
+
+	// States without a color, and nil schemes, give the plain icon
+	if icon := output.DefaultColors.StateIcon(models.StateInbox); icon != "?" {
+		t.Errorf("StateIcon(INBOX) = %q, want plain ?", icon)
+	}
+	var plain *output.ColorScheme
+	if icon := plain.StateIcon(models.StateNew); icon != "◆" {
+		t.Errorf("nil scheme StateIcon(NEW) = %q, want plain ◆", icon)
+	}
 }
 
 func TestColoredKindPriority(t *testing.T) {
 	tests := []struct {
-		kind         string
-		priority     string
-		kindColor    string
+		kind          models.Kind
+		priority      models.Priority
+		kindColor     string
 		priorityColor string
 	}{
-		{models.KindBug, models.PriorityHigh, colorRed, colorBrightRed},
-		{models.KindFeature, models.PriorityMedium, colorGreen, colorYellow},
-		{models.KindRegression, models.PriorityLow, colorYellow, colorGreen},
+		{models.KindBug, models.PriorityHigh, output.ANSIRed, output.ANSIBrightRed},
+		{models.KindFeature, models.PriorityMedium, output.ANSIGreen, output.ANSIYellow},
+		{models.KindRegression, models.PriorityLow, output.ANSIYellow, output.ANSIGreen},
 	}
-	
-	formatter := &ColorFormatter{useColor: true}
-	
+
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s_%s", tt.kind, tt.priority), func(t *testing.T) {
-			colored := formatter.formatKindPriorityColor(tt.kind, tt.priority)
-			
-			// Should contain kind in lowercase
-			if !strings.Contains(colored, strings.ToLower(tt.kind)) {
-				t.Errorf("Missing kind %s", tt.kind)
-			}
-			
-			// Should contain priority
-			if !strings.Contains(colored, tt.priority) {
-				t.Errorf("Missing priority %s", tt.priority)
-			}
-			
-			// Should contain color codes
-			if !strings.Contains(colored, tt.kindColor) {
-				t.Errorf("Missing kind color %s", tt.kindColor)
-			}
-			if !strings.Contains(colored, tt.priorityColor) {
-				t.Errorf("Missing priority color %s", tt.priorityColor)
+			colored := output.DefaultColors.KindPriority(tt.kind, tt.priority)
+			expected := tt.kindColor + strings.ToLower(tt.kind.String()) + output.ANSIReset +
+				"(" + tt.priorityColor + tt.priority.String() + output.ANSIReset + "):"
+			if colored != expected {
+				t.Errorf("KindPriority() = %q, want %q", colored, expected)
 			}
 		})
 	}
 }
 
 func TestColoredTags(t *testing.T) {
-	formatter := &ColorFormatter{useColor: true}
-	
-	tags := "backend,api,urgent"
-	colored := formatter.formatTagsColor(tags)
-	
-	// Should have # prefix
-	if !strings.HasPrefix(strings.TrimPrefix(colored, colorBlue), "#") {
-		t.Error("Tags should have # prefix")
+	colored := output.DefaultColors.TagList("backend, api,urgent")
+	expected := output.ANSIBlue + "#backend" + output.ANSIReset + " " +
+		output.ANSIBlue + "#api" + output.ANSIReset + " " +
+		output.ANSIBlue + "#urgent" + output.ANSIReset
+	if colored != expected {
+		t.Errorf("TagList() = %q, want %q", colored, expected)
+	}
+
+	var plain *output.ColorScheme
+	if tags := plain.TagList("backend,api"); tags != "#backend #api" {
+		t.Errorf("nil scheme TagList() = %q", tags)
 	}
-	
-	// Should be blue
-	if !strings.Contains(colored, colorBlue) {
-		t.Error("Tags should be colored blue")
+}
+
+func TestColoredByTag(t *testing.T) {
+	colors := output.DefaultColors.WithTagColors(map[string]string{"incident": output.ANSIRed})
+	task := createTestTask("tag12345", "Database down")
+	task.Tags = "backend,Incident"
+
+	out := output.NewColorFormatter(nil, colors).Oneline(task, nil)
+	if !strings.Contains(out, output.ANSIRed+"Database down"+output.ANSIReset) {
+		t.Errorf("title not colored by tag: %q", out)
 	}
-	
-	// Should contain the actual tags
-	if !strings.Contains(colored, tags) {
-		t.Error("Missing tag content")
+	if !strings.Contains(out, output.ANSIRed+"#Incident") || !strings.Contains(out, output.ANSIBlue+"#backend") {
+		t.Errorf("tags not colored by tag: %q", out)
+	}
+
+	// The default scheme is left unchanged
+	if out := colorFormatter(true).Oneline(task, nil); !strings.Contains(out, output.ANSIBold+"Database down") {
+		t.Errorf("default scheme colored by tag: %q", out)
 	}
 }
 
@@ -328,18 +148,17 @@ func TestColoredBlockedBy(t *testing.T) {
 	task := createTestTask("blocked123", "Blocked Task")
 	blocker := "blocker456"
 	task.BlockedBy = &blocker
-	
-	formatter := &ColorFormatter{useColor: true}
-	output := formatter.FormatTask(task, nil)
-	
+
+	out := colorFormatter(true).GitStyle(task, nil)
+
 	// Should contain blocked-by in red
-	if !strings.Contains(output, "Blocked-by:") {
-		t.Error("Missing Blocked-by label")
+	if !strings.Contains(out, "Blocked-by:") {
+		t.Fatal("Missing Blocked-by label")
 	}
-	
+
 	// The blocker ID should be in red
-	blockedSection := output[strings.Index(output, "Blocked-by:"):]
-	if !strings.Contains(blockedSection, colorRed) {
+	blockedSection := out[strings.Index(out, "Blocked-by:"):]
+	if !strings.Contains(blockedSection, output.ANSIRed) {
 		t.Error("Blocked-by ID should be colored red")
 	}
 }
@@ -347,23 +166,35 @@ func TestColoredBlockedBy(t *testing.T) {
 func TestColorFormatterComparison(t *testing.T) {
 	// Test that colored and non-colored output have the same content
 	task := createTestTask("compare123", "Comparison Task")
-	task.Description = "Multi-line\ndescription\nfor testing"
+	task.Description = "Multi-line\ndescription\nfor testing\n- [x] one\n- [ ] two"
+	task.ExternalRef = "GH-12"
+	task.Pinned = true
+	parent := "parent456"
+	task.Parent = &parent
 	blocker := "blocker789"
 	task.BlockedBy = &blocker
-	
-	coloredFormatter := &ColorFormatter{useColor: true}
-	plainFormatter := &ColorFormatter{useColor: false}
-	
-	coloredOutput := coloredFormatter.FormatTask(task, nil)
-	plainOutput := plainFormatter.FormatTask(task, nil)
-	
-	// Strip ANSI codes from colored output
-	strippedColored := stripANSI(coloredOutput)
-	
-	// Should be identical after stripping colors
-	if strippedColored != plainOutput {
-		t.Errorf("Colored and plain output differ:\nColored (stripped):\n%s\nPlain:\n%s", 
-			strippedColored, plainOutput)
+	task.State = models.StateInProgress
+	stats := &output.SubtaskStats{Total: 3, Done: 1}
+
+	colored := colorFormatter(true)
+	plain := colorFormatter(false)
+
+	tests := []struct {
+		name          string
+		colored, want string
+	}{
+		{"git style", colored.GitStyle(task, stats), output.FormatTaskGitStyle(task, stats)},
+		{"oneline", colored.Oneline(task, stats), output.FormatTaskOnelineWithStats(task, stats)},
+		{"subtask", colored.Subtask(task), output.FormatSubtask(task)},
+		{"plain formatter", plain.GitStyle(task, stats), output.FormatTaskGitStyle(task, stats)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Should be identical after stripping colors
+			if stripped := stripANSI(tt.colored); stripped != tt.want {
+				t.Errorf("Colored and plain output differ:\nColored (stripped):\n%s\nPlain:\n%s", stripped, tt.want)
+			}
+		})
 	}
 }
 
@@ -386,59 +217,32 @@ func stripANSI(s string) string {
 func TestIndentationWithColors(t *testing.T) {
 	task := createTestTask("indent123", "Indented Task")
 	task.Description = "Line 1\nLine 2\nLine 3"
-	
-	formatter := &ColorFormatter{useColor: true}
-	output := formatter.FormatTask(task, nil)
-	
-	lines := strings.Split(output, "\n")
-	
-	// Find description lines
-	descStarted := false
-	for _, line := range lines {
+
+	out := colorFormatter(true).GitStyle(task, nil)
+
+	for _, line := range strings.Split(out, "\n") {
 		stripped := stripANSI(line)
-		if strings.TrimSpace(stripped) == "" && descStarted {
-			// Empty line after title, before description
-			continue
-		}
-		if strings.Contains(stripped, "Line ") {
-			descStarted = true
+		if strings.Contains(stripped, "Line ") && !strings.HasPrefix(stripped, "    ") {
 			// Each description line should start with 4 spaces
-			if !strings.HasPrefix(stripped, "    ") {
-				t.Errorf("Description line not properly indented: %q", stripped)
-			}
+			t.Errorf("Description line not properly indented: %q", stripped)
 		}
 	}
 }
 
-// Test that output package formatter matches non-colored formatter
-func TestOutputPackageCompatibility(t *testing.T) {
-	task := createTestTask("compat123", "Compatibility Test")
-	
-	// Get output from package formatter
-	packageOutput := output.FormatTaskGitStyle(task, nil)
-	
-	// Get output from our non-colored formatter
-	formatter := &ColorFormatter{useColor: false}
-	ourOutput := formatter.FormatTask(task, nil)
-	
-	// They should be very similar (might have minor formatting differences)
-	// Check that both contain the essential elements
-	essentials := []string{
-		"task " + task.ID,
-		"Author: " + task.Author,
-		"Date:",
-		getStateIcon(task.State),
-		strings.ToLower(task.Kind) + "(" + task.Priority + "):",
-		task.Title,
-		task.Description,
+func TestColoredFormatterList(t *testing.T) {
+	tasks := []*models.Task{createTestTask("list1", "First"), createTestTask("list2", "Second")}
+
+	var colored, plain strings.Builder
+	if err := output.NewColorFormatter(&colored, output.DefaultColors).FormatTaskList(tasks, true); err != nil {
+		t.Fatal(err)
 	}
-	
-	for _, essential := range essentials {
-		if !strings.Contains(packageOutput, essential) {
-			t.Errorf("Package output missing: %s", essential)
-		}
-		if !strings.Contains(ourOutput, essential) {
-			t.Errorf("Our output missing: %s", essential)
-		}
+	if err := output.NewFormatter(&plain).FormatTaskList(tasks, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(colored.String(), "\033[") {
+		t.Error("Expected ANSI color codes in the colored list")
 	}
-}
\ No newline at end of file
+	if stripANSI(colored.String()) != plain.String() {
+		t.Errorf("Colored list differs from plain:\n%s\n%s", stripANSI(colored.String()), plain.String())
+	}
+}
@@ -0,0 +1,355 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// JSONTask is the JSON representation of a task in exports
+type JSONTask struct {
+	ID           string  `json:"id"`
+	Kind         string  `json:"kind"`
+	State        string  `json:"state"`
+	Priority     string  `json:"priority"`
+	Title        string  `json:"title"`
+	Description  string  `json:"description"`
+	Tags         string  `json:"tags"`
+	Source       string  `json:"source"`
+	ExternalRef  string  `json:"external_ref,omitempty"`
+	Severity     string  `json:"severity,omitempty"`
+	IntroducedIn string  `json:"introduced_in,omitempty"`
+	Environment  string  `json:"environment,omitempty"`
+	Parent       *string `json:"parent,omitempty"`
+	BlockedBy    *string `json:"blocked_by,omitempty"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+
+	// Titles of the parent and blocker, when resolved by export
+	// --resolve-refs
+	ParentTitle    string `json:"parent_title,omitempty"`
+	BlockedByTitle string `json:"blocked_by_title,omitempty"`
+
+	// Recognized description sections by key, e.g. "acceptance"
+	Sections map[string]string `json:"sections,omitempty"`
+
+	Attachments []string          `json:"attachments,omitempty"`
+	Links       []JSONLink        `json:"links,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"` // Custom fields by name
+
+	// Ancestors of a subtask, root first, when resolved by show
+	ParentChain []JSONTaskRef `json:"parent_chain,omitempty"`
+}
+
+// JSONTaskRef identifies a related task by ID and title
+type JSONTaskRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// JSONLink is an outgoing link from an exported task
+type JSONLink struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// NewJSONTask converts a task to its JSON representation, without the
+// attachments and links stored apart from it
+func NewJSONTask(task *models.Task) *JSONTask {
+	item := &JSONTask{
+		ID:           task.ID,
+		Kind:         task.Kind.String(),
+		State:        task.State.String(),
+		Priority:     task.Priority.String(),
+		Title:        task.Title,
+		Description:  task.Description,
+		Tags:         task.Tags,
+		Source:       task.Source,
+		ExternalRef:  task.ExternalRef,
+		Severity:     task.Severity.String(),
+		IntroducedIn: task.IntroducedIn,
+		Environment:  task.Environment,
+		Parent:       task.Parent,
+		BlockedBy:    task.BlockedBy,
+		CreatedAt:    FormatExportTime(task.Created),
+		UpdatedAt:    FormatExportTime(task.Updated),
+	}
+	for _, section := range models.ParseSections(task.Description) {
+		if item.Sections == nil {
+			item.Sections = make(map[string]string)
+		}
+		if _, ok := item.Sections[section.Type.Key]; !ok {
+			item.Sections[section.Type.Key] = section.Body
+		}
+	}
+	return item
+}
+
+// jsonFormatter writes tasks as an indented JSON array, one element at a time
+type jsonFormatter struct {
+	w       io.Writer
+	convert func(task *models.Task) (*JSONTask, error)
+	written bool
+}
+
+func newJSONFormatter(w io.Writer, opts Options) TaskFormatter {
+	convert := opts.JSONTask
+	if convert == nil {
+		convert = func(task *models.Task) (*JSONTask, error) { return NewJSONTask(task), nil }
+	}
+	return &jsonFormatter{w: w, convert: convert}
+}
+
+func (f *jsonFormatter) WriteTask(task *models.Task) error {
+	item, err := f.convert(task)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(item, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if !f.written {
+		separator = "[\n  "
+	}
+	f.written = true
+	if _, err := io.WriteString(f.w, separator); err != nil {
+		return err
+	}
+	_, err = f.w.Write(data)
+	return err
+}
+
+func (f *jsonFormatter) Close() error {
+	end := "\n]\n"
+	if !f.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(f.w, end)
+	return err
+}
+
+// CSVColumn is a column of the csv format
+type CSVColumn struct {
+	Header string
+	Value  func(task *models.Task) string
+}
+
+// DefaultCSVColumns are the columns of the csv format without
+// Options.CSVColumns
+var DefaultCSVColumns = []CSVColumn{
+	{"ID", func(task *models.Task) string { return task.ID }},
+	{"Type", func(task *models.Task) string { return task.Kind.String() }},
+	{"State", func(task *models.Task) string { return task.State.String() }},
+	{"Priority", func(task *models.Task) string { return task.Priority.String() }},
+	{"Title", func(task *models.Task) string { return task.Title }},
+	{"Tags", func(task *models.Task) string { return task.Tags }},
+	{"Source", func(task *models.Task) string { return task.Source }},
+	{"Parent", func(task *models.Task) string { return derefOrEmpty(task.Parent) }},
+	{"BlockedBy", func(task *models.Task) string { return derefOrEmpty(task.BlockedBy) }},
+	{"Created", func(task *models.Task) string { return FormatExportTime(task.Created) }},
+	{"Updated", func(task *models.Task) string { return FormatExportTime(task.Updated) }},
+}
+
+// csvFormatter writes tasks as CSV rows after a header row
+type csvFormatter struct {
+	w       *csv.Writer
+	columns []CSVColumn
+	header  bool
+}
+
+func newCSVFormatter(w io.Writer, opts Options) TaskFormatter {
+	f := &csvFormatter{w: csv.NewWriter(w), columns: opts.CSVColumns, header: !opts.CSVNoHeader}
+	if f.columns == nil {
+		f.columns = DefaultCSVColumns
+	}
+	if opts.CSVComma != 0 {
+		f.w.Comma = opts.CSVComma
+	}
+	return f
+}
+
+// writeHeader writes the header row before the first task, or before
+// closing when there are none
+func (f *csvFormatter) writeHeader() error {
+	if !f.header {
+		return nil
+	}
+	f.header = false
+	row := make([]string, len(f.columns))
+	for i, column := range f.columns {
+		row[i] = column.Header
+	}
+	return f.w.Write(row)
+}
+
+func (f *csvFormatter) WriteTask(task *models.Task) error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(f.columns))
+	for i, column := range f.columns {
+		row[i] = column.Value(task)
+	}
+	return f.w.Write(row)
+}
+
+func (f *csvFormatter) Close() error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	f.w.Flush()
+	return f.w.Error()
+}
+
+// markdownFormatter writes a summary table of the tasks followed by their
+// details. The table starts with the number of tasks, so they are written
+// on Close.
+type markdownFormatter struct {
+	w        io.Writer
+	refTitle func(id string) string
+	tasks    []*models.Task
+}
+
+func newMarkdownFormatter(w io.Writer, opts Options) TaskFormatter {
+	return &markdownFormatter{w: w, refTitle: opts.RefTitle}
+}
+
+func (f *markdownFormatter) WriteTask(task *models.Task) error {
+	f.tasks = append(f.tasks, task)
+	return nil
+}
+
+func (f *markdownFormatter) Close() error {
+	w := &errWriter{w: f.w}
+	w.printf("# Tasks Export\n\n")
+	w.printf("Total tasks: %d\n\n", len(f.tasks))
+
+	rows := make([][]string, len(f.tasks))
+	for i, task := range f.tasks {
+		parent := "-"
+		if task.Parent != nil {
+			parent = f.ref(models.ShortID(*task.Parent), *task.Parent)
+		}
+		blockedBy := "-"
+		if task.BlockedBy != nil {
+			blockedBy = f.ref(models.ShortID(*task.BlockedBy), *task.BlockedBy)
+		}
+		rows[i] = []string{
+			strconv.Itoa(i + 1), task.Kind.String(), task.State.String(), task.Priority.String(), task.Title,
+			orDash(task.Tags), orDash(task.Source), parent, blockedBy,
+		}
+	}
+	if w.err == nil {
+		w.err = WriteMarkdownTable(f.w, []string{"ID", "Type", "State", "Priority", "Title", "Tags", "Source", "Parent", "Blocked By"}, rows)
+	}
+
+	w.printf("\n## Task Details\n\n")
+	for _, task := range f.tasks {
+		w.printf("### #%s: %s\n\n", task.ID, task.Title)
+		if task.Description != "" {
+			w.printf("%s\n\n", task.Description)
+		}
+		w.printf("- **Type:** %s\n", kindName(task.Kind))
+		w.printf("- **State:** %s %s\n", task.State, StateIcon(task.State))
+		w.printf("- **Priority:** %s %s\n", task.Priority, PriorityIcon(task.Priority))
+		if task.Tags != "" {
+			w.printf("- **Tags:** %s\n", task.Tags)
+		}
+		if task.Source != "" {
+			w.printf("- **Source:** %s\n", task.Source)
+		}
+		if task.Parent != nil {
+			w.printf("- **Parent:** %s\n", f.ref(*task.Parent, *task.Parent))
+		}
+		if task.BlockedBy != nil {
+			w.printf("- **Blocked by:** %s\n", f.ref(*task.BlockedBy, *task.BlockedBy))
+		}
+		w.printf("- **Created:** %s\n", FormatExportTime(task.Created))
+		w.printf("- **Updated:** %s\n\n", FormatExportTime(task.Updated))
+	}
+	return w.err
+}
+
+// ref shows a reference to the task with the given ID as #label, followed by
+// its title when titles are looked up and it has one
+func (f *markdownFormatter) ref(label, id string) string {
+	if f.refTitle != nil {
+		if title := f.refTitle(id); title != "" {
+			return fmt.Sprintf("#%s %s", label, title)
+		}
+	}
+	return "#" + label
+}
+
+// WriteMarkdownTable writes a Markdown table with a header row. Pipes and
+// line breaks in cells are escaped so they can't break the table.
+func WriteMarkdownTable(w io.Writer, header []string, rows [][]string) error {
+	out := &errWriter{w: w}
+	writeRow := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = markdownCellEscaper.Replace(cell)
+		}
+		out.printf("| %s |\n", strings.Join(escaped, " | "))
+	}
+
+	writeRow(header)
+	out.printf("|%s\n", strings.Repeat("---|", len(header)))
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return out.err
+}
+
+// markdownCellEscaper escapes text for use in a Markdown table cell
+var markdownCellEscaper = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
+
+// errWriter keeps the first write error, so a sequence of writes can be
+// checked once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) printf(format string, args ...interface{}) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.w, format, args...)
+	}
+}
+
+// kindName names a task kind for display, e.g. "Bug"
+func kindName(kind models.Kind) string {
+	switch kind {
+	case models.KindBug:
+		return "Bug"
+	case models.KindFeature:
+		return "Feature"
+	case models.KindRegression:
+		return "Regression"
+	}
+	return kind.String()
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// derefOrEmpty returns the string s points to, or "" when it is nil
+func derefOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
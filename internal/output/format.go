@@ -0,0 +1,36 @@
+package output
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+)
+
+// Output formats, as named by --output, --format, and GTD_DEFAULT_FORMAT
+const (
+	FormatStandard = "standard" // Git-style, one block per task
+	FormatOneline  = "oneline"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// ResolveFormat picks the output format of a command that supports the given
+// formats, the first being its default. A format given on the command line
+// wins and must be supported; otherwise the configured default
+// (GTD_DEFAULT_FORMAT) is used when the command supports it, and the
+// command's own default when it doesn't.
+func ResolveFormat(flag, configured string, supported ...string) (string, error) {
+	if flag != "" {
+		flag = strings.ToLower(flag)
+		if !slices.Contains(supported, flag) {
+			return "", errors.NewValidationError("unsupported format: %s (must be %s)", flag, strings.Join(supported, ", "))
+		}
+		return flag, nil
+	}
+	if configured = strings.ToLower(configured); slices.Contains(supported, configured) {
+		return configured, nil
+	}
+	return supported[0], nil
+}
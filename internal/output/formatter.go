@@ -12,6 +12,8 @@ import (
 // Formatter handles formatting of tasks for display
 type Formatter struct {
 	writer io.Writer
+	colors *ColorScheme
+	times  func(t time.Time) string
 }
 
 // NewFormatter creates a new formatter
@@ -19,9 +21,31 @@ func NewFormatter(w io.Writer) *Formatter {
 	return &Formatter{writer: w}
 }
 
+// NewColorFormatter creates a formatter that colors its output with colors,
+// or writes it plain when colors is nil
+func NewColorFormatter(w io.Writer, colors *ColorScheme) *Formatter {
+	return &Formatter{writer: w, colors: colors}
+}
+
+// WithTimes makes the formatter render timestamps with format instead of as
+// exact dates, e.g. to show how long ago they were
+func (f *Formatter) WithTimes(format func(t time.Time) string) *Formatter {
+	f.times = format
+	return f
+}
+
+// formatTime renders a timestamp with the configured format, an exact date by
+// default
+func (f *Formatter) formatTime(t time.Time) string {
+	if f.times != nil {
+		return f.times(t)
+	}
+	return t.Format(time.RFC1123Z)
+}
+
 // FormatTask formats a single task in git-style format
 func (f *Formatter) FormatTask(task *models.Task, stats *SubtaskStats) error {
-	output := FormatTaskGitStyle(task, stats)
+	output := f.GitStyle(task, stats)
 	_, err := fmt.Fprint(f.writer, output)
 	return err
 }
@@ -43,7 +67,7 @@ func (f *Formatter) formatTasksStandard(tasks []*models.Task) error {
 			}
 		}
 
-		output := FormatTaskGitStyle(task, nil)
+		output := f.GitStyle(task, nil)
 		if _, err := fmt.Fprint(f.writer, output); err != nil {
 			return err
 		}
@@ -54,7 +78,7 @@ func (f *Formatter) formatTasksStandard(tasks []*models.Task) error {
 // formatTasksOneline formats tasks in compact one-line format
 func (f *Formatter) formatTasksOneline(tasks []*models.Task) error {
 	for _, task := range tasks {
-		line := FormatTaskOneline(task)
+		line := f.Oneline(task, nil)
 		if _, err := fmt.Fprintln(f.writer, line); err != nil {
 			return err
 		}
@@ -63,35 +87,33 @@ func (f *Formatter) formatTasksOneline(tasks []*models.Task) error {
 }
 
 // SubtaskStats holds statistics about subtasks
-type SubtaskStats struct {
-	Total int
-	Done  int
-}
+type SubtaskStats = models.SubtaskStats
 
-// FormatTaskGitStyle formats a task in git-log style
-func FormatTaskGitStyle(task *models.Task, stats *SubtaskStats) string {
+// GitStyle formats a task in git-log style, with the progress of its
+// subtasks when stats are given
+func (f *Formatter) GitStyle(task *models.Task, stats *SubtaskStats) string {
+	colors := f.colors.or()
 	var sb strings.Builder
 
 	// Header line
-	fmt.Fprintf(&sb, "task %s\n", task.ID)
+	fmt.Fprintf(&sb, "%s %s\n", paint("task", colors.Hash), paint(task.ID, colors.Hash))
 	fmt.Fprintf(&sb, "Author: %s\n", task.Author)
-	fmt.Fprintf(&sb, "Date:   %s\n", task.Created.Format(time.RFC1123Z))
+	fmt.Fprintf(&sb, "Date:   %s\n", f.formatTime(task.Created))
 
 	// Parent reference if subtask
 	if task.Parent != nil {
-		fmt.Fprintf(&sb, "Parent: %s\n", *task.Parent)
+		fmt.Fprintf(&sb, "Parent: %s\n", paint(*task.Parent, colors.Hash))
 	}
 
 	// Empty line before content
 	sb.WriteString("\n")
 
 	// Status icon and metadata
-	icon := getStateIcon(task.State)
-	fmt.Fprintf(&sb, "  %s %s(%s): %s", icon, strings.ToLower(task.Kind), task.Priority, task.Title)
+	fmt.Fprintf(&sb, "  %s %s %s", colors.StateIcon(task.State), colors.KindPriority(task.Kind, task.Priority), colors.TaskTitle(task))
 
 	// Add subtask progress if parent
 	if stats != nil && stats.Total > 0 {
-		fmt.Fprintf(&sb, " [%d/%d]", stats.Done, stats.Total)
+		sb.WriteString(" " + paint(fmt.Sprintf("[%d/%d]", stats.Done, stats.Total), colors.Muted))
 	}
 
 	sb.WriteString("\n")
@@ -105,14 +127,29 @@ func FormatTaskGitStyle(task *models.Task, stats *SubtaskStats) string {
 
 	// Metadata section
 	var metadata []string
+	if checklist := ChecklistSummary(task); checklist != "" {
+		metadata = append(metadata, paint(checklist, colors.Muted))
+	}
+	if task.Severity != "" {
+		metadata = append(metadata, fmt.Sprintf("Severity: %s", task.Severity))
+	}
+	if task.IntroducedIn != "" {
+		metadata = append(metadata, fmt.Sprintf("Introduced-in: %s", task.IntroducedIn))
+	}
+	if task.Environment != "" {
+		metadata = append(metadata, fmt.Sprintf("Environment: %s", task.Environment))
+	}
 	if task.Source != "" {
 		metadata = append(metadata, fmt.Sprintf("Source: %s", task.Source))
 	}
+	if task.ExternalRef != "" {
+		metadata = append(metadata, fmt.Sprintf("Ref: %s", paint(task.ExternalRef, colors.Ref)))
+	}
 	if task.BlockedBy != nil {
-		metadata = append(metadata, fmt.Sprintf("Blocked-by: %s", (*task.BlockedBy)[:7]))
+		metadata = append(metadata, fmt.Sprintf("Blocked-by: %s", paint(models.ShortID(*task.BlockedBy), colors.Blocked)))
 	}
 	if task.Tags != "" {
-		metadata = append(metadata, fmt.Sprintf("Tags: %s", task.Tags))
+		metadata = append(metadata, fmt.Sprintf("Tags: %s", paint(task.Tags, colors.Tags)))
 	}
 
 	if len(metadata) > 0 {
@@ -125,67 +162,96 @@ func FormatTaskGitStyle(task *models.Task, stats *SubtaskStats) string {
 	return sb.String()
 }
 
-// FormatTaskOneline formats a task in a single line
-func FormatTaskOneline(task *models.Task) string {
-	icon := getStateIcon(task.State)
-	line := fmt.Sprintf("%s %s %s(%s): %s",
-		task.ShortHash(),
-		icon,
-		strings.ToLower(task.Kind),
-		task.Priority,
-		task.Title)
+// Oneline formats a task in a single line, with the progress of its
+// subtasks when it has any
+func (f *Formatter) Oneline(task *models.Task, stats *SubtaskStats) string {
+	colors := f.colors.or()
+	parts := []string{paint(task.ShortHash(), colors.Hash)}
+	if seq := task.SeqRef(); seq != "" {
+		parts = append(parts, paint(seq, colors.Muted))
+	}
+	parts = append(parts, colors.StateIcon(task.State))
+	if task.Pinned {
+		parts = append(parts, paint(PinnedIcon(), colors.Pinned))
+	}
+	parts = append(parts, colors.KindPriority(task.Kind, task.Priority), colors.TaskTitle(task))
 
+	if stats != nil && stats.Total > 0 {
+		parts = append(parts, paint(fmt.Sprintf("[%d/%d]", stats.Done, stats.Total), colors.Muted))
+	}
+	if checklist := ChecklistSummary(task); checklist != "" {
+		parts = append(parts, paint("["+checklist+"]", colors.Muted))
+	}
+	if task.Severity != "" {
+		parts = append(parts, paint("["+task.Severity.String()+"]", colors.Muted))
+	}
+	if task.Tags != "" {
+		parts = append(parts, colors.TagList(task.Tags))
+	}
+	if task.ExternalRef != "" {
+		parts = append(parts, paint("["+task.ExternalRef+"]", colors.Ref))
+	}
 	if task.IsBlocked() {
-		line += " [BLOCKED]"
+		parts = append(parts, paint("[BLOCKED]", colors.Blocked))
 	}
 
-	return line
+	return strings.Join(parts, " ")
 }
 
-// FormatSubtask formats a subtask with metadata
-func FormatSubtask(task *models.Task) string {
-	// Format with metadata on the right
-	icon := getStateIcon(task.State)
-	base := fmt.Sprintf("%s %s - %s", task.ShortHash(), icon, task.Title)
+// Subtask formats a subtask with its metadata aligned on the right
+func (f *Formatter) Subtask(task *models.Task) string {
+	colors := f.colors.or()
+	base := fmt.Sprintf("%s %s - %s", task.ShortHash(), StateIcon(task.State), task.Title)
 
 	// Add metadata to the right
 	var metadata []string
-	metadata = append(metadata, strings.ToLower(task.Kind))
-	metadata = append(metadata, task.Priority)
+	metadata = append(metadata, strings.ToLower(task.Kind.String()))
+	metadata = append(metadata, task.Priority.String())
 
 	if task.IsBlocked() {
 		metadata = append(metadata, "blocked")
 	}
 
-	// Calculate padding for alignment
+	// Calculate padding for alignment, before adding colors
 	const targetWidth = 80
-	baseLen := len(base)
 	metaStr := strings.Join(metadata, ", ")
-	padding := targetWidth - baseLen - len(metaStr) - 3 // 3 for " | "
+	padding := targetWidth - len(base) - len(metaStr) - 3 // 3 for " | "
 
 	if padding < 2 {
 		padding = 2
 	}
 
+	base = fmt.Sprintf("%s %s - %s", paint(task.ShortHash(), colors.Hash), colors.StateIcon(task.State), colors.TaskTitle(task))
 	return fmt.Sprintf("%s%s| %s", base, strings.Repeat(" ", padding), metaStr)
 }
 
-// getStateIcon returns an icon for the task state
-func getStateIcon(state string) string {
-	switch state {
-	case models.StateInbox:
-		return "?"
-	case models.StateNew:
-		return "◆"
-	case models.StateInProgress:
-		return "▶"
-	case models.StateDone:
-		return "✓"
-	case models.StateCancelled:
-		return "✗"
-	case models.StateInvalid:
-		return "⊘"
-	default:
-		return "·"
-	}
-}
\ No newline at end of file
+// FormatTaskGitStyle formats a task in git-log style without colors
+func FormatTaskGitStyle(task *models.Task, stats *SubtaskStats) string {
+	return NewFormatter(nil).GitStyle(task, stats)
+}
+
+// FormatTaskOneline formats a task in a single line without colors
+func FormatTaskOneline(task *models.Task) string {
+	return FormatTaskOnelineWithStats(task, nil)
+}
+
+// FormatTaskOnelineWithStats formats a task in a single line without
+// colors, with the progress of its subtasks when it has any
+func FormatTaskOnelineWithStats(task *models.Task, stats *SubtaskStats) string {
+	return NewFormatter(nil).Oneline(task, stats)
+}
+
+// ChecklistSummary describes the checklist progress of a task, e.g.
+// "2/6 checklist items", or returns "" if its description has no checklist
+func ChecklistSummary(task *models.Task) string {
+	done, total := task.ChecklistProgress()
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d checklist items", done, total)
+}
+
+// FormatSubtask formats a subtask with metadata without colors
+func FormatSubtask(task *models.Task) string {
+	return NewFormatter(nil).Subtask(task)
+}
@@ -3,6 +3,7 @@ package output_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -10,160 +11,118 @@ import (
 	"github.com/zw3rk/gtd/internal/output"
 )
 
-// FormatterFactory simulates a GetFormatter function
-type FormatterFactory struct{}
-
-type Format string
-
-const (
-	FormatStandard Format = "standard"
-	FormatOneline  Format = "oneline"
-	FormatJSON     Format = "json"
-	FormatCSV      Format = "csv"
-	FormatMarkdown Format = "markdown"
-)
-
-// TaskFormatter interface that all formatters implement
-type TaskFormatter interface {
-	FormatTask(task *models.Task) error
-	FormatTasks(tasks []*models.Task) error
-	String() string
-}
-
-// GetFormatter returns the appropriate formatter based on format string
-func (f *FormatterFactory) GetFormatter(format string) (TaskFormatter, error) {
-	switch Format(strings.ToLower(format)) {
-	case FormatJSON:
-		return &JSONFormatter{}, nil
-	case FormatCSV:
-		return &CSVFormatter{}, nil
-	case FormatMarkdown:
-		return &MarkdownFormatter{}, nil
-	case FormatStandard, FormatOneline, "":
-		// For standard/oneline, we'd return a different formatter
-		// but for testing we'll use a simple one
-		return &StandardFormatter{oneline: format == string(FormatOneline)}, nil
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
-	}
-}
-
-// StandardFormatter handles standard and oneline formats
-type StandardFormatter struct {
-	writer  bytes.Buffer
-	oneline bool
-}
-
-func (f *StandardFormatter) FormatTask(task *models.Task) error {
-	if f.oneline {
-		fmt.Fprintln(&f.writer, output.FormatTaskOneline(task))
-	} else {
-		fmt.Fprint(&f.writer, output.FormatTaskGitStyle(task, nil))
+// formatTasks writes tasks with a format of the built-in registry
+func formatTasks(t testing.TB, format string, tasks []*models.Task, opts output.Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := output.Formats.WriteTasks(&buf, format, tasks, opts); err != nil {
+		t.Fatalf("WriteTasks(%s) error = %v", format, err)
 	}
-	return nil
+	return buf.String()
 }
 
-func (f *StandardFormatter) FormatTasks(tasks []*models.Task) error {
-	for i, task := range tasks {
-		if i > 0 && !f.oneline {
-			fmt.Fprintln(&f.writer) // Empty line between tasks
-		}
-		if err := f.FormatTask(task); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (f *StandardFormatter) String() string {
-	return f.writer.String()
-}
-
-// Tests
 func TestGetFormatter(t *testing.T) {
-	factory := &FormatterFactory{}
-	
 	tests := []struct {
 		format      string
 		expectError bool
-		formatType  string
 	}{
-		{"json", false, "JSON"},
-		{"JSON", false, "JSON"},
-		{"csv", false, "CSV"},
-		{"CSV", false, "CSV"},
-		{"markdown", false, "Markdown"},
-		{"MARKDOWN", false, "Markdown"},
-		{"standard", false, "Standard"},
-		{"oneline", false, "Standard"},
-		{"", false, "Standard"}, // Default
-		{"unknown", true, ""},
-		{"xml", true, ""},
+		{"json", false},
+		{"JSON", false},
+		{"csv", false},
+		{"CSV", false},
+		{"markdown", false},
+		{"MARKDOWN", false},
+		{"standard", false},
+		{"oneline", false},
+		{"", true},
+		{"unknown", true},
+		{"xml", true},
 	}
-	
+
 	for _, tt := range tests {
 		t.Run(tt.format, func(t *testing.T) {
-			formatter, err := factory.GetFormatter(tt.format)
-			
+			var buf bytes.Buffer
+			formatter, err := output.Formats.GetFormatter(tt.format, &buf, output.Options{})
 			if tt.expectError {
 				if err == nil {
-					t.Errorf("Expected error for format %s, got none", tt.format)
+					t.Errorf("Expected error for format %q, got none", tt.format)
 				}
 				return
 			}
-			
 			if err != nil {
-				t.Errorf("Unexpected error for format %s: %v", tt.format, err)
-				return
+				t.Fatalf("Unexpected error for format %s: %v", tt.format, err)
 			}
-			
-			if formatter == nil {
-				t.Errorf("GetFormatter returned nil for format %s", tt.format)
+
+			if err := formatter.WriteTask(createTestTask("test123", "Test Task")); err != nil {
+				t.Errorf("WriteTask failed for %s formatter: %v", tt.format, err)
 			}
-			
-			// Test that the formatter works
-			task := createTestTask("test123", "Test Task")
-			if err := formatter.FormatTask(task); err != nil {
-				t.Errorf("FormatTask failed for %s formatter: %v", tt.format, err)
+			if err := formatter.Close(); err != nil {
+				t.Errorf("Close failed for %s formatter: %v", tt.format, err)
+			}
+			if !strings.Contains(buf.String(), "Test Task") {
+				t.Errorf("%s output missing the task: %q", tt.format, buf.String())
 			}
 		})
 	}
 }
 
+func TestFormatsNames(t *testing.T) {
+	want := []string{"standard", "oneline", "json", "csv", "markdown"}
+	if got := output.Formats.Names(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+// titleFormatter writes only task titles, for testing registration
+type titleFormatter struct {
+	w io.Writer
+}
+
+func (f *titleFormatter) WriteTask(task *models.Task) error {
+	_, err := fmt.Fprintln(f.w, task.Title)
+	return err
+}
+
+func (f *titleFormatter) Close() error { return nil }
+
+func TestRegistryRegister(t *testing.T) {
+	registry := output.NewRegistry()
+	registry.Register("Titles", func(w io.Writer, _ output.Options) output.TaskFormatter {
+		return &titleFormatter{w: w}
+	})
+
+	var buf bytes.Buffer
+	tasks := []*models.Task{createTestTask("a1", "First"), createTestTask("b2", "Second")}
+	if err := registry.WriteTasks(&buf, "titles", tasks, output.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "First\nSecond\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	_, err := registry.GetFormatter("json", &buf, output.Options{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported format: json (must be titles)") {
+		t.Errorf("Expected an error naming the registered formats, got %v", err)
+	}
+}
+
 func TestFormatterFactoryIntegration(t *testing.T) {
-	factory := &FormatterFactory{}
-	
-	// Create test tasks
 	tasks := []*models.Task{
 		createTestTask("task1", "First Task"),
 		createTestTask("task2", "Second Task"),
 		createTestTask("task3", "Third Task"),
 	}
-	
-	// Test each formatter type
-	formats := []string{"json", "csv", "markdown", "standard", "oneline"}
-	
-	for _, format := range formats {
+
+	for _, format := range output.Formats.Names() {
 		t.Run(format, func(t *testing.T) {
-			formatter, err := factory.GetFormatter(format)
-			if err != nil {
-				t.Fatalf("Failed to get %s formatter: %v", format, err)
-			}
-			
-			// Format tasks
-			err = formatter.FormatTasks(tasks)
-			if err != nil {
-				t.Fatalf("FormatTasks failed for %s: %v", format, err)
-			}
-			
-			output := formatter.String()
-			if output == "" {
+			out := formatTasks(t, format, tasks, output.Options{})
+			if out == "" {
 				t.Errorf("Empty output from %s formatter", format)
 			}
-			
+
 			// Verify all tasks appear in output
 			for _, task := range tasks {
-				if !strings.Contains(output, task.ID) && !strings.Contains(output, task.ShortHash()) {
+				if !strings.Contains(out, task.ID) && !strings.Contains(out, task.ShortHash()) {
 					t.Errorf("%s formatter output missing task %s", format, task.ID)
 				}
 			}
@@ -172,185 +131,126 @@ func TestFormatterFactoryIntegration(t *testing.T) {
 }
 
 func TestFormatterConsistency(t *testing.T) {
-	factory := &FormatterFactory{}
 	task := createTestTask("consist123", "Consistency Test")
-	
-	// Get output from each formatter
+
 	outputs := make(map[string]string)
-	formats := []string{"json", "csv", "markdown", "standard", "oneline"}
-	
-	for _, format := range formats {
-		formatter, err := factory.GetFormatter(format)
-		if err != nil {
-			t.Fatalf("Failed to get %s formatter: %v", format, err)
-		}
-		
-		err = formatter.FormatTask(task)
-		if err != nil {
-			t.Fatalf("FormatTask failed for %s: %v", format, err)
-		}
-		
-		outputs[format] = formatter.String()
+	for _, format := range output.Formats.Names() {
+		outputs[format] = formatTasks(t, format, []*models.Task{task}, output.Options{})
 	}
-	
+
 	// Verify each formatter produced unique output
 	seen := make(map[string]string)
-	for format, output := range outputs {
-		if prevFormat, exists := seen[output]; exists {
+	for format, out := range outputs {
+		if prevFormat, exists := seen[out]; exists {
 			t.Errorf("Formatters %s and %s produced identical output", format, prevFormat)
 		}
-		seen[output] = format
+		seen[out] = format
 	}
-	
+
 	// Verify essential information is in all outputs
-	// Note: Some formatters use lowercase for kind/state
-	essentials := []string{
-		task.Title,
-		task.Priority,
-	}
-	
-	for format, output := range outputs {
-		for _, essential := range essentials {
-			if !strings.Contains(output, essential) {
+	for format, out := range outputs {
+		for _, essential := range []string{task.Title, task.Priority.String()} {
+			if !strings.Contains(out, essential) {
 				t.Errorf("%s formatter missing essential info: %s", format, essential)
 			}
 		}
-		
-		// Check for kind and state (might be lowercase)
-		if !strings.Contains(output, task.Kind) && !strings.Contains(output, strings.ToLower(task.Kind)) {
-			t.Errorf("%s formatter missing task kind", format)
-		}
-		// State might be represented as an icon (◆ for NEW) instead of text
-		stateIcon := getStateEmoji(task.State)
-		if !strings.Contains(output, task.State) && !strings.Contains(output, strings.ToLower(task.State)) && !strings.Contains(output, stateIcon) {
-			t.Errorf("%s formatter missing task state (looked for %s or %s or icon %s)", format, task.State, strings.ToLower(task.State), stateIcon)
-		}
-	}
-}
 
-// Test case sensitivity
-func TestFormatterCaseInsensitive(t *testing.T) {
-	factory := &FormatterFactory{}
-	
-	formats := [][]string{
-		{"json", "JSON", "Json", "jSoN"},
-		{"csv", "CSV", "Csv", "cSv"},
-		{"markdown", "MARKDOWN", "Markdown", "MarkDown"},
-	}
-	
-	for _, variations := range formats {
-		baseFormat := variations[0]
-		
-		// Get formatter with base format
-		_, err := factory.GetFormatter(baseFormat)
-		if err != nil {
-			t.Fatalf("Failed to get %s formatter: %v", baseFormat, err)
+		// Kind and state may be lowercase, and the state an icon
+		if kind := task.Kind.String(); !strings.Contains(out, kind) && !strings.Contains(out, strings.ToLower(kind)) {
+			t.Errorf("%s formatter missing task kind", format)
 		}
-		
-		// Verify all variations return a formatter
-		for _, variation := range variations[1:] {
-			formatter, err := factory.GetFormatter(variation)
-			if err != nil {
-				t.Errorf("Failed to get formatter for %s (variation of %s): %v", 
-					variation, baseFormat, err)
-			}
-			if formatter == nil {
-				t.Errorf("Got nil formatter for %s", variation)
-			}
+		stateIcon := output.StateIcon(task.State)
+		if state := task.State.String(); !strings.Contains(out, state) && !strings.Contains(out, strings.ToLower(state)) && !strings.Contains(out, stateIcon) {
+			t.Errorf("%s formatter missing task state (looked for %s or icon %s)", format, task.State, stateIcon)
 		}
 	}
 }
 
-// Test formatter reusability
 func TestFormatterReusability(t *testing.T) {
-	factory := &FormatterFactory{}
-	
-	formatter, err := factory.GetFormatter("json")
-	if err != nil {
-		t.Fatalf("Failed to get JSON formatter: %v", err)
-	}
-	
-	// Use formatter multiple times
 	task1 := createTestTask("reuse1", "First Use")
 	task2 := createTestTask("reuse2", "Second Use")
-	
-	// First use
-	err = formatter.FormatTask(task1)
-	if err != nil {
-		t.Fatalf("First FormatTask failed: %v", err)
-	}
-	output1 := formatter.String()
-	
-	// Create new formatter for second use (simulating fresh state)
-	formatter2, _ := factory.GetFormatter("json")
-	err = formatter2.FormatTask(task2)
-	if err != nil {
-		t.Fatalf("Second FormatTask failed: %v", err)
-	}
-	output2 := formatter2.String()
-	
-	// Outputs should be different (different tasks)
+
+	output1 := formatTasks(t, "json", []*models.Task{task1}, output.Options{})
+	output2 := formatTasks(t, "json", []*models.Task{task2}, output.Options{})
+
+	// Formatters start fresh, so different tasks give different output
 	if output1 == output2 {
 		t.Error("Different tasks produced identical output")
 	}
-	
-	// Both should be valid JSON
-	if !strings.Contains(output1, task1.ID) {
-		t.Error("First output missing task1 ID")
+	if !strings.Contains(output1, task1.ID) || strings.Contains(output1, task2.ID) {
+		t.Errorf("First output = %q", output1)
 	}
-	if !strings.Contains(output2, task2.ID) {
-		t.Error("Second output missing task2 ID")
+	if !strings.Contains(output2, task2.ID) || strings.Contains(output2, task1.ID) {
+		t.Errorf("Second output = %q", output2)
 	}
 }
 
-// Test error propagation
 func TestFormatterErrorHandling(t *testing.T) {
-	factory := &FormatterFactory{}
-	
-	// Test with invalid format
-	_, err := factory.GetFormatter("invalid-format")
+	var buf bytes.Buffer
+	_, err := output.Formats.GetFormatter("invalid-format", &buf, output.Options{})
 	if err == nil {
-		t.Error("Expected error for invalid format")
+		t.Fatal("Expected error for invalid format")
 	}
 	if !strings.Contains(err.Error(), "unsupported format") {
 		t.Errorf("Error message should mention unsupported format, got: %v", err)
 	}
-	
-	// Test with empty format (should default to standard)
-	formatter, err := factory.GetFormatter("")
-	if err != nil {
-		t.Errorf("Empty format should default to standard, got error: %v", err)
-	}
-	if formatter == nil {
-		t.Error("Empty format should return a valid formatter")
+
+	// Conversion errors are returned by WriteTask
+	failing := output.Options{JSONTask: func(task *models.Task) (*output.JSONTask, error) {
+		return nil, fmt.Errorf("no attachments for %s", task.ID)
+	}}
+	err = output.Formats.WriteTasks(&buf, "json", []*models.Task{createTestTask("fail1", "Failing")}, failing)
+	if err == nil || !strings.Contains(err.Error(), "no attachments for fail1") {
+		t.Errorf("Expected the conversion error, got %v", err)
 	}
 }
 
-// Benchmark different formatters
 func BenchmarkFormatters(b *testing.B) {
-	factory := &FormatterFactory{}
-	
-	// Create a substantial task
 	task := createTestTask("bench123", "Benchmark Task")
 	task.Description = strings.Repeat("This is a long description line.\n", 10)
 	task.Tags = "tag1,tag2,tag3,tag4,tag5"
-	
-	formats := []string{"json", "csv", "markdown", "standard", "oneline"}
-	
-	for _, format := range formats {
+	tasks := []*models.Task{task}
+
+	for _, format := range output.Formats.Names() {
 		b.Run(format, func(b *testing.B) {
-			_, err := factory.GetFormatter(format)
-			if err != nil {
-				b.Fatalf("Failed to get %s formatter: %v", format, err)
-			}
-			
-			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				// Create new formatter each time to avoid accumulation
-				f, _ := factory.GetFormatter(format)
-				_ = f.FormatTask(task)
-				_ = f.String()
+				_ = output.Formats.WriteTasks(io.Discard, format, tasks, output.Options{})
+			}
+		})
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	listFormats := output.Formats.Names()
+	exportFormats := []string{output.FormatJSON, output.FormatCSV, output.FormatMarkdown, "xlsx"}
+
+	tests := []struct {
+		name       string
+		flag       string
+		configured string
+		supported  []string
+		want       string
+		wantErr    bool
+	}{
+		{"command default", "", "", listFormats, output.FormatStandard, false},
+		{"configured default", "", "oneline", listFormats, output.FormatOneline, false},
+		{"flag overrides configured default", "json", "oneline", listFormats, output.FormatJSON, false},
+		{"flag is case-insensitive", "CSV", "", listFormats, output.FormatCSV, false},
+		{"unsupported configured default is ignored", "", "oneline", exportFormats, output.FormatJSON, false},
+		{"configured default for export", "", "markdown", exportFormats, output.FormatMarkdown, false},
+		{"unsupported flag", "oneline", "", exportFormats, "", true},
+		{"unknown flag", "xml", "", listFormats, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := output.ResolveFormat(tt.flag, tt.configured, tt.supported...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveFormat() = %q, want %q", got, tt.want)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
@@ -285,6 +285,19 @@ func TestFormatTaskOneline(t *testing.T) {
 	}
 }
 
+func TestFormatTaskOnelineWithStats(t *testing.T) {
+	task := createTestTask("abc123def456", "Parent Task")
+
+	line := output.FormatTaskOnelineWithStats(task, &output.SubtaskStats{Total: 5, Done: 3})
+	if !strings.Contains(line, "Parent Task [3/5]") {
+		t.Errorf("Expected subtask progress after the title, got: %s", line)
+	}
+
+	if line := output.FormatTaskOnelineWithStats(task, &output.SubtaskStats{}); strings.Contains(line, "[0/0]") {
+		t.Errorf("Expected no progress without subtasks, got: %s", line)
+	}
+}
+
 func TestFormatSubtask(t *testing.T) {
 	task := createTestTask("sub123def456", "Subtask Title")
 	
@@ -312,7 +325,7 @@ func TestFormatSubtask(t *testing.T) {
 
 func TestGetStateIcon(t *testing.T) {
 	tests := []struct {
-		state    string
+		state    models.State
 		expected string
 	}{
 		{models.StateInbox, "?"},
@@ -325,7 +338,7 @@ func TestGetStateIcon(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.state, func(t *testing.T) {
+		t.Run(tt.state.String(), func(t *testing.T) {
 			task := createTestTask("test123", "Test")
 			task.State = tt.state
 			
@@ -445,21 +458,21 @@ func (w *errorWriter) Write(p []byte) (n int, err error) {
 
 // TestAllTaskKinds tests formatting for all task kinds
 func TestAllTaskKinds(t *testing.T) {
-	kinds := []string{
+	kinds := []models.Kind{
 		models.KindBug,
 		models.KindFeature,
 		models.KindRegression,
 	}
 
 	for _, kind := range kinds {
-		t.Run(kind, func(t *testing.T) {
+		t.Run(kind.String(), func(t *testing.T) {
 			task := createTestTask("kind123", fmt.Sprintf("%s Task", kind))
 			task.Kind = kind
 			
 			output := output.FormatTaskOneline(task)
 			
 			// Should contain lowercase kind
-			if !strings.Contains(output, strings.ToLower(kind)) {
+			if !strings.Contains(output, strings.ToLower(kind.String())) {
 				t.Errorf("Output missing kind %s: %s", kind, output)
 			}
 		})
@@ -468,14 +481,14 @@ func TestAllTaskKinds(t *testing.T) {
 
 // TestAllPriorities tests formatting for all priority levels
 func TestAllPriorities(t *testing.T) {
-	priorities := []string{
+	priorities := []models.Priority{
 		models.PriorityHigh,
 		models.PriorityMedium,
 		models.PriorityLow,
 	}
 
 	for _, priority := range priorities {
-		t.Run(priority, func(t *testing.T) {
+		t.Run(priority.String(), func(t *testing.T) {
 			task := createTestTask("pri123", fmt.Sprintf("%s Priority Task", priority))
 			task.Priority = priority
 			
@@ -0,0 +1,90 @@
+package output
+
+import "github.com/zw3rk/gtd/internal/models"
+
+// IconSet keys for markers that are not states or priorities
+const (
+	IconBlocked = "BLOCKED" // Shown on blocked tasks
+	IconPinned  = "PINNED"  // Shown on pinned tasks
+)
+
+// IconSet maps task states, priorities, IconBlocked, and IconPinned to the
+// markers shown in task lists and details
+type IconSet map[string]string
+
+// With returns a copy of the set with some icons replaced
+func (s IconSet) With(overrides map[string]string) IconSet {
+	set := make(IconSet, len(s)+len(overrides))
+	for key, icon := range s {
+		set[key] = icon
+	}
+	for key, icon := range overrides {
+		set[key] = icon
+	}
+	return set
+}
+
+// UnicodeIcons are the default glyphs
+var UnicodeIcons = IconSet{
+	string(models.StateInbox):      "?",
+	string(models.StateNew):        "◆", // U+25C6 - Black Diamond
+	string(models.StateInProgress): "▶", // U+25B6 - Black Right-Pointing Triangle
+	string(models.StateDone):       "✓", // U+2713 - Check Mark
+	string(models.StateCancelled):  "✗", // U+2717 - Ballot X
+	string(models.StateInvalid):    "⊘", // U+2298 - Circled Division Slash
+	IconBlocked:                    "⊘",
+	IconPinned:                     "★", // U+2605 - Black Star
+	string(models.PriorityHigh):    "!",
+	string(models.PriorityMedium):  "=",
+	string(models.PriorityLow):     "-",
+}
+
+// ASCIIIcons are bracketed words for terminals and screen readers that
+// can't handle the glyphs
+var ASCIIIcons = IconSet{
+	string(models.StateInbox):      "[INBOX]",
+	string(models.StateNew):        "[NEW]",
+	string(models.StateInProgress): "[WIP]",
+	string(models.StateDone):       "[DONE]",
+	string(models.StateCancelled):  "[CANCELLED]",
+	string(models.StateInvalid):    "[INVALID]",
+	IconBlocked:                    "[BLOCKED]",
+	IconPinned:                     "[PINNED]",
+	string(models.PriorityHigh):    "!",
+	string(models.PriorityMedium):  "=",
+	string(models.PriorityLow):     "-",
+}
+
+// icons is the icon set in use
+var icons = UnicodeIcons
+
+// SetIcons selects the icon set used by all task formatting
+func SetIcons(set IconSet) {
+	icons = set
+}
+
+// StateIcon returns the marker for a task state
+func StateIcon(state models.State) string {
+	if icon, ok := icons[string(state)]; ok {
+		return icon
+	}
+	return "·"
+}
+
+// BlockedIcon returns the marker for blocked tasks
+func BlockedIcon() string {
+	return icons[IconBlocked]
+}
+
+// PinnedIcon returns the marker for pinned tasks
+func PinnedIcon() string {
+	return icons[IconPinned]
+}
+
+// PriorityIcon returns the marker for a priority
+func PriorityIcon(priority models.Priority) string {
+	if icon, ok := icons[string(priority)]; ok {
+		return icon
+	}
+	return "."
+}
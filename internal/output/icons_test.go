@@ -0,0 +1,55 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestIcons(t *testing.T) {
+	defer SetIcons(UnicodeIcons)
+
+	task := models.NewTask(models.KindBug, "Fix login", "Users cannot log in")
+	task.State = models.StateInProgress
+	blocker := "abc1234"
+	task.BlockedBy = &blocker
+
+	if got := FormatTaskOneline(task); !strings.Contains(got, " ▶ ") {
+		t.Errorf("FormatTaskOneline() = %q, want the Unicode icon", got)
+	}
+	if BlockedIcon() != "⊘" {
+		t.Errorf("BlockedIcon() = %q, want ⊘", BlockedIcon())
+	}
+
+	SetIcons(ASCIIIcons)
+	if got := FormatTaskOneline(task); !strings.Contains(got, " [WIP] ") || strings.Contains(got, "▶") {
+		t.Errorf("FormatTaskOneline() = %q, want [WIP]", got)
+	}
+	for _, state := range []models.State{models.StateInbox, models.StateNew, models.StateDone, models.StateCancelled, models.StateInvalid} {
+		if icon := StateIcon(state); icon != "["+state.String()+"]" {
+			t.Errorf("StateIcon(%s) = %q", state, icon)
+		}
+	}
+	if BlockedIcon() != "[BLOCKED]" {
+		t.Errorf("BlockedIcon() = %q, want [BLOCKED]", BlockedIcon())
+	}
+	if StateIcon("UNKNOWN") != "·" {
+		t.Errorf("StateIcon(UNKNOWN) = %q, want ·", StateIcon("UNKNOWN"))
+	}
+}
+
+func TestIconSetWith(t *testing.T) {
+	defer SetIcons(UnicodeIcons)
+
+	SetIcons(UnicodeIcons.With(map[string]string{string(models.StateDone): "✅", string(models.PriorityHigh): "🔴"}))
+	if StateIcon(models.StateDone) != "✅" || PriorityIcon(models.PriorityHigh) != "🔴" {
+		t.Errorf("overrides not applied: %q %q", StateIcon(models.StateDone), PriorityIcon(models.PriorityHigh))
+	}
+	if StateIcon(models.StateNew) != "◆" || PriorityIcon(models.PriorityLow) != "-" {
+		t.Errorf("defaults lost: %q %q", StateIcon(models.StateNew), PriorityIcon(models.PriorityLow))
+	}
+	if UnicodeIcons[string(models.StateDone)] != "✓" {
+		t.Error("With() modified the original set")
+	}
+}
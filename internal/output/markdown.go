@@ -0,0 +1,132 @@
+package output
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// ANSI styles used when rendering Markdown for a color terminal
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiItalic    = "\033[3m"
+	ansiUnderline = "\033[4m"
+	ansiBlue      = "\033[34m"
+	ansiCyan      = "\033[36m"
+	ansiGray      = "\033[90m"
+)
+
+var (
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBullet     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdRule       = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	mdQuote      = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic     = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w_])_([^_\s][^_]*)_`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// RenderMarkdown renders the common Markdown found in task descriptions for
+// the terminal: headings, bold, italics, lists, quotes, code, and links.
+// With color disabled the markup is removed instead of styled.
+func RenderMarkdown(text string, color bool) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		// Fenced code blocks are shown verbatim, without the fences
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, ansiStyle("  "+line, ansiCyan, color))
+			continue
+		}
+
+		switch {
+		case mdHeading.MatchString(line):
+			heading := mdHeading.FindStringSubmatch(line)[2]
+			out = append(out, ansiStyle(renderInline(heading, color), ansiBold+ansiUnderline, color))
+		case mdRule.MatchString(line):
+			out = append(out, ansiStyle(strings.Repeat("─", 40), ansiGray, color))
+		case mdBullet.MatchString(line):
+			m := mdBullet.FindStringSubmatch(line)
+			out = append(out, m[1]+"• "+renderInline(m[2], color))
+		case mdQuote.MatchString(line):
+			quote := mdQuote.FindStringSubmatch(line)[1]
+			out = append(out, ansiStyle("│ ", ansiGray, color)+ansiStyle(renderInline(quote, color), ansiItalic, color))
+		default:
+			out = append(out, renderInline(line, color))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// SectionHeadings turns the recognized sections of a description, such as
+// "Acceptance Criteria:", into Markdown headings with their canonical titles,
+// so RenderMarkdown shows them as headers
+func SectionHeadings(description string) string {
+	sections := models.ParseSections(description)
+	if len(sections) == 0 {
+		return description
+	}
+
+	var parts []string
+	if intro := strings.Join(strings.Split(description, "\n")[:sections[0].Line], "\n"); strings.TrimSpace(intro) != "" {
+		parts = append(parts, strings.TrimRight(intro, "\n"))
+	}
+	for _, section := range sections {
+		parts = append(parts, "### "+section.Type.Titles[0]+"\n"+section.Body)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderInline renders inline code, links, bold, and italic text within a line
+func renderInline(line string, color bool) string {
+	// Protect code spans from further formatting
+	var spans []string
+	line = mdInlineCode.ReplaceAllStringFunc(line, func(m string) string {
+		code := mdInlineCode.FindStringSubmatch(m)[1]
+		if !color {
+			code = "`" + code + "`"
+		}
+		spans = append(spans, ansiStyle(code, ansiCyan, color))
+		return codePlaceholder(len(spans) - 1)
+	})
+
+	line = mdLink.ReplaceAllStringFunc(line, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		return parts[1] + " (" + ansiStyle(parts[2], ansiBlue+ansiUnderline, color) + ")"
+	})
+	line = mdBold.ReplaceAllStringFunc(line, func(m string) string {
+		parts := mdBold.FindStringSubmatch(m)
+		return ansiStyle(parts[1]+parts[2], ansiBold, color)
+	})
+	line = mdItalic.ReplaceAllStringFunc(line, func(m string) string {
+		parts := mdItalic.FindStringSubmatch(m)
+		return parts[1] + parts[3] + ansiStyle(parts[2]+parts[4], ansiItalic, color)
+	})
+
+	for i, span := range spans {
+		line = strings.Replace(line, codePlaceholder(i), span, 1)
+	}
+	return line
+}
+
+// codePlaceholder marks the position of the i-th code span while the rest of
+// a line is formatted
+func codePlaceholder(i int) string {
+	return "\x00" + strconv.Itoa(i) + "\x00"
+}
+
+// ansiStyle wraps s in the given ANSI codes when color is enabled
+func ansiStyle(s, codes string, color bool) string {
+	if !color {
+		return s
+	}
+	return codes + s + ansiReset
+}
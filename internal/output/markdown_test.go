@@ -0,0 +1,90 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownPlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain text is unchanged",
+			input: "Memory usage grows over time",
+			want:  "Memory usage grows over time",
+		},
+		{
+			name:  "bold and italic markers are removed",
+			input: "This is **very** important and *slightly* urgent",
+			want:  "This is very important and slightly urgent",
+		},
+		{
+			name:  "underscores inside words are kept",
+			input: "Rename snake_case_name to __init__ style",
+			want:  "Rename snake_case_name to init style",
+		},
+		{
+			name:  "lists become bullets",
+			input: "- first\n  * nested",
+			want:  "• first\n  • nested",
+		},
+		{
+			name:  "headings drop their markers",
+			input: "## Steps to reproduce",
+			want:  "Steps to reproduce",
+		},
+		{
+			name:  "links show their target",
+			input: "See [the docs](https://example.com/docs)",
+			want:  "See the docs (https://example.com/docs)",
+		},
+		{
+			name:  "inline code is left alone",
+			input: "Call `make **all**` first",
+			want:  "Call `make **all**` first",
+		},
+		{
+			name:  "code blocks lose their fences",
+			input: "Run:\n```sh\ngo test ./...\n```\nDone",
+			want:  "Run:\n  go test ./...\nDone",
+		},
+		{
+			name:  "quotes",
+			input: "> it crashed",
+			want:  "│ it crashed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.input, false); got != tt.want {
+				t.Errorf("RenderMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownColor(t *testing.T) {
+	got := RenderMarkdown("Use **bold** and `code`", true)
+	if !strings.Contains(got, ansiBold+"bold"+ansiReset) {
+		t.Errorf("Expected bold styling, got %q", got)
+	}
+	if !strings.Contains(got, ansiCyan+"code"+ansiReset) {
+		t.Errorf("Expected code styling, got %q", got)
+	}
+}
+
+func TestSectionHeadings(t *testing.T) {
+	input := "Crashes on save.\n\nrepro steps: open a file\nPress Ctrl+S\n\nAcceptance Criteria:\n- [ ] No crash"
+	want := "Crashes on save.\n\n### Repro Steps\nopen a file\nPress Ctrl+S\n\n### Acceptance Criteria\n- [ ] No crash"
+	if got := SectionHeadings(input); got != want {
+		t.Errorf("SectionHeadings() = %q, want %q", got, want)
+	}
+
+	if got := SectionHeadings("No sections here"); got != "No sections here" {
+		t.Errorf("Expected description without sections to be unchanged, got %q", got)
+	}
+}
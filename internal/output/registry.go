@@ -0,0 +1,143 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// TaskFormatter writes tasks in one output format as they are produced.
+// WriteTask is called for each task in order and Close once after the last
+// to finish the document. Formats that need every task first, such as
+// Markdown with its summary table, hold the tasks until Close.
+type TaskFormatter interface {
+	WriteTask(task *models.Task) error
+	Close() error
+}
+
+// NewFormatterFunc creates a formatter that writes to w
+type NewFormatterFunc func(w io.Writer, opts Options) TaskFormatter
+
+// Options tune the formatters of a registry. The zero value gives each
+// format's defaults.
+type Options struct {
+	// JSONTask converts a task for the json format, e.g. to load its
+	// attachments; NewJSONTask when nil
+	JSONTask func(task *models.Task) (*JSONTask, error)
+
+	CSVColumns  []CSVColumn // DefaultCSVColumns when nil
+	CSVComma    rune        // ',' when zero
+	CSVNoHeader bool
+
+	// RefTitle looks up the title of a parent or blocker for the markdown
+	// format, which shows it next to the ID; IDs only when nil
+	RefTitle func(id string) string
+
+	// Colors colors the standard and oneline formats; plain when nil
+	Colors *ColorScheme
+}
+
+// Registry maps format names to formatters
+type Registry struct {
+	formats map[string]NewFormatterFunc
+	names   []string
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{formats: make(map[string]NewFormatterFunc)}
+}
+
+// Formats is the registry of the built-in formats: standard, oneline, json,
+// csv, and markdown
+var Formats = NewRegistry()
+
+func init() {
+	Formats.Register(FormatStandard, newStandardFormatter)
+	Formats.Register(FormatOneline, newOnelineFormatter)
+	Formats.Register(FormatJSON, newJSONFormatter)
+	Formats.Register(FormatCSV, newCSVFormatter)
+	Formats.Register(FormatMarkdown, newMarkdownFormatter)
+}
+
+// Register adds a format, replacing any format of the same name
+func (r *Registry) Register(name string, newFormatter NewFormatterFunc) {
+	name = strings.ToLower(name)
+	if _, ok := r.formats[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.formats[name] = newFormatter
+}
+
+// Names lists the registered formats in the order they were registered
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// GetFormatter creates a formatter for the named format, in any case, that
+// writes to w
+func (r *Registry) GetFormatter(name string, w io.Writer, opts Options) (TaskFormatter, error) {
+	newFormatter, ok := r.formats[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.NewValidationError("unsupported format: %s (must be %s)", name, strings.Join(r.names, ", "))
+	}
+	return newFormatter(w, opts), nil
+}
+
+// WriteTasks writes tasks to w in the named format
+func (r *Registry) WriteTasks(w io.Writer, name string, tasks []*models.Task, opts Options) error {
+	formatter, err := r.GetFormatter(name, w, opts)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := formatter.WriteTask(task); err != nil {
+			return err
+		}
+	}
+	return formatter.Close()
+}
+
+// standardFormatter writes tasks in git-log style, separated by blank lines
+type standardFormatter struct {
+	w         io.Writer
+	formatter *Formatter
+	written   bool
+}
+
+func newStandardFormatter(w io.Writer, opts Options) TaskFormatter {
+	return &standardFormatter{w: w, formatter: NewColorFormatter(w, opts.Colors)}
+}
+
+func (f *standardFormatter) WriteTask(task *models.Task) error {
+	if f.written {
+		if _, err := fmt.Fprintln(f.w); err != nil {
+			return err
+		}
+	}
+	f.written = true
+	_, err := fmt.Fprint(f.w, f.formatter.GitStyle(task, nil))
+	return err
+}
+
+func (f *standardFormatter) Close() error { return nil }
+
+// onelineFormatter writes one line per task
+type onelineFormatter struct {
+	w         io.Writer
+	formatter *Formatter
+}
+
+func newOnelineFormatter(w io.Writer, opts Options) TaskFormatter {
+	return &onelineFormatter{w: w, formatter: NewColorFormatter(w, opts.Colors)}
+}
+
+func (f *onelineFormatter) WriteTask(task *models.Task) error {
+	_, err := fmt.Fprintln(f.w, f.formatter.Oneline(task, nil))
+	return err
+}
+
+func (f *onelineFormatter) Close() error { return nil }
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchSnippet finds the first case-insensitive occurrence of query in text
+// and returns it with up to radius characters of context on either side,
+// joined onto one line and with "…" where the context was cut off. ok is
+// false when text doesn't contain query.
+func MatchSnippet(text, query string, radius int) (before, match, after string, ok bool) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	needle := []rune(strings.ToLower(strings.TrimSpace(query)))
+	if len(needle) == 0 {
+		return "", "", "", false
+	}
+
+	start := -1
+	for i := 0; i+len(needle) <= len(runes) && start < 0; i++ {
+		start = i
+		for j, r := range needle {
+			if unicode.ToLower(runes[i+j]) != r {
+				start = -1
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return "", "", "", false
+	}
+	end := start + len(needle)
+
+	from, to := max(start-radius, 0), min(end+radius, len(runes))
+	before, match, after = string(runes[from:start]), string(runes[start:end]), string(runes[end:to])
+	if from > 0 {
+		before = "…" + before
+	}
+	if to < len(runes) {
+		after += "…"
+	}
+	return before, match, after, true
+}
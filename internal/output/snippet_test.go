@@ -0,0 +1,29 @@
+package output
+
+import (
+	"testing"
+)
+
+func TestMatchSnippet(t *testing.T) {
+	tests := []struct {
+		name                 string
+		text, query          string
+		before, match, after string
+		ok                   bool
+	}{
+		{"whole text", "Connection pool exhausted", "POOL", "Connection ", "pool", " exhausted", true},
+		{"cut context", "The worker connection pool is exhausted under load", "pool", "…connection ", "pool", " is exhaust…", true},
+		{"lines are joined", "First line\nsecond  line", "line sec", "First ", "line sec", "ond line", true},
+		{"unicode", "Überprüfung fehlgeschlagen", "prüfung", "Über", "prüfung", " fehlgeschl…", true},
+		{"no match", "Memory leak", "pool", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, match, after, ok := MatchSnippet(tt.text, tt.query, 11)
+			if before != tt.before || match != tt.match || after != tt.after || ok != tt.ok {
+				t.Errorf("MatchSnippet() = %q, %q, %q, %v, want %q, %q, %q, %v",
+					before, match, after, ok, tt.before, tt.match, tt.after, tt.ok)
+			}
+		})
+	}
+}
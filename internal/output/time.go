@@ -0,0 +1,66 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zw3rk/gtd/internal/database"
+)
+
+// legacyExportTimeLayout is the timestamp layout of exports made by older
+// versions of gtd: local time without a zone
+const legacyExportTimeLayout = "2006-01-02 15:04:05"
+
+// FormatExportTime formats a timestamp for exports as RFC3339 in UTC, like
+// stored timestamps, so exports read the same in every time zone
+func FormatExportTime(t time.Time) string {
+	return database.FormatTime(t)
+}
+
+// ParseExportTime parses a timestamp of an export: RFC3339, or local time
+// without a zone as exported by older versions
+func ParseExportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local(), nil
+	}
+	return time.ParseInLocation(legacyExportTimeLayout, value, time.Local)
+}
+
+// RelativeTime describes t relative to now, e.g. "just now", "5 minutes ago",
+// "3 days ago", or "in 2 hours" for times in the future
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d = -d
+		suffix = ""
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+
+	var n int
+	var unit string
+	switch {
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 7*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(7*24*time.Hour)), "week"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+
+	if suffix == "" {
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %s %s", n, unit, suffix)
+}
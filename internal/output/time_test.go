@@ -0,0 +1,57 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{2 * time.Hour, "2 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{14 * 24 * time.Hour, "2 weeks ago"},
+		{90 * 24 * time.Hour, "3 months ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+		{-2 * time.Hour, "in 2 hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := RelativeTime(now.Add(-tt.ago), now); got != tt.want {
+				t.Errorf("RelativeTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportTime(t *testing.T) {
+	at := time.Date(2024, 3, 10, 14, 30, 5, 250*int(time.Millisecond), time.FixedZone("CET", 3600))
+
+	formatted := FormatExportTime(at)
+	if formatted != "2024-03-10T13:30:05.250Z" {
+		t.Errorf("FormatExportTime() = %q, want RFC3339 in UTC", formatted)
+	}
+	if parsed, err := ParseExportTime(formatted); err != nil || !parsed.Equal(at) {
+		t.Errorf("ParseExportTime(%q) = %v, %v, want %v", formatted, parsed, err, at)
+	}
+	if parsed, err := ParseExportTime("2024-03-10T14:30:05+01:00"); err != nil || !parsed.Equal(at.Truncate(time.Second)) {
+		t.Errorf("ParseExportTime() with an offset = %v, %v", parsed, err)
+	}
+
+	// Older exports hold local time without a zone
+	legacy := time.Date(2024, 3, 10, 14, 30, 5, 0, time.Local)
+	if parsed, err := ParseExportTime("2024-03-10 14:30:05"); err != nil || !parsed.Equal(legacy) {
+		t.Errorf("ParseExportTime() of the old layout = %v, %v, want %v", parsed, err, legacy)
+	}
+	if _, err := ParseExportTime("March 10"); err == nil {
+		t.Error("ParseExportTime() accepted an invalid timestamp")
+	}
+}
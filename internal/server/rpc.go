@@ -0,0 +1,305 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+	rpcTaskNotFound   = -32001
+)
+
+// maxRPCMessageSize bounds a single JSON-RPC message, which is one line
+const maxRPCMessageSize = 16 * 1024 * 1024
+
+// rpcRequest is a JSON-RPC 2.0 request; requests without an ID are
+// notifications and get no response
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object. Application errors carry gtd's
+// error code as data.
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+// rpcErrorData is the data of an application error
+type rpcErrorData struct {
+	Code errors.Code `json:"code"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// listParams are the parameters of the list method
+type listParams struct {
+	State    string `json:"state"`
+	Priority string `json:"priority"`
+	Kind     string `json:"kind"`
+	Tag      string `json:"tag"`
+	All      bool   `json:"all"`
+	Limit    int    `json:"limit"`
+}
+
+// idParams are the parameters of methods that take a task ID
+type idParams struct {
+	ID string `json:"id"`
+}
+
+// addParams are the parameters of the add method
+type addParams struct {
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	Tags        string `json:"tags"`
+	Source      string `json:"source"`
+	Ref         string `json:"ref"`
+}
+
+// transitionParams are the parameters of the transition method
+type transitionParams struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// searchParams are the parameters of the search method
+type searchParams struct {
+	Query string `json:"query"`
+}
+
+// ServeRPC answers JSON-RPC 2.0 requests read from r, one message per line,
+// writing one response per line to w until r is exhausted. Batches are
+// supported.
+func (s *Server) ServeRPC(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRPCMessageSize)
+	encoder := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		response := s.handleMessage(line)
+		if response == nil {
+			continue
+		}
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handleMessage answers a single request or a batch, returning nil when
+// nothing needs to be sent back
+func (s *Server) handleMessage(message []byte) interface{} {
+	if message[0] != '[' {
+		if response := s.handleRequest(message); response != nil {
+			return response
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(message, &batch); err != nil {
+		return errorResponse(nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &rpcError{Code: rpcInvalidRequest, Message: "empty batch"})
+	}
+
+	var responses []*rpcResponse
+	for _, request := range batch {
+		if response := s.handleRequest(request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// handleRequest answers a single request, returning nil for notifications
+func (s *Server) handleRequest(message []byte) *rpcResponse {
+	var request rpcRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		var syntaxErr *json.SyntaxError
+		if stderrors.As(err, &syntaxErr) {
+			return errorResponse(nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+		}
+		return errorResponse(nil, &rpcError{Code: rpcInvalidRequest, Message: err.Error()})
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return errorResponse(request.ID, &rpcError{Code: rpcInvalidRequest, Message: `requests must have "jsonrpc": "2.0" and a method`})
+	}
+
+	result, err := s.call(request.Method, request.Params)
+	if request.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *rpcError
+		if !stderrors.As(err, &rpcErr) {
+			code := errors.CodeOf(err)
+			rpcErr = &rpcError{Code: rpcServerError, Message: err.Error(), Data: &rpcErrorData{Code: code}}
+			if code == errors.CodeNotFound || code == errors.CodeAmbiguousPrefix {
+				rpcErr.Code = rpcTaskNotFound
+			}
+		}
+		return errorResponse(request.ID, rpcErr)
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: result}
+}
+
+// call runs a method with its raw parameters
+func (s *Server) call(method string, raw json.RawMessage) (interface{}, error) {
+	switch method {
+	case "list":
+		var params listParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		tasks, err := s.service.ListTasks(models.ListOptions{
+			State:         models.State(strings.ToUpper(params.State)),
+			Priority:      models.Priority(strings.ToLower(params.Priority)),
+			Kind:          models.Kind(strings.ToUpper(params.Kind)),
+			Tag:           params.Tag,
+			All:           params.All,
+			ShowDone:      params.All,
+			ShowCancelled: params.All,
+			Limit:         params.Limit,
+		})
+		if tasks == nil {
+			tasks = []*models.Task{}
+		}
+		return tasks, err
+
+	case "show":
+		var params idParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		return s.detail(params.ID)
+
+	case "add":
+		var params addParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		return s.add(params)
+
+	case "transition":
+		var params transitionParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		task, err := s.transition(params.ID, params.Action, params.Reason)
+		var actionErr *unknownActionError
+		if stderrors.As(err, &actionErr) {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return task, err
+
+	case "search":
+		var params searchParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		if params.Query == "" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "query is required"}
+		}
+		tasks, err := s.service.SearchTasks(params.Query)
+		if tasks == nil {
+			tasks = []*models.Task{}
+		}
+		return tasks, err
+
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method: %s", method)}
+	}
+}
+
+// add creates a task from the add method's parameters
+func (s *Server) add(params addParams) (*models.Task, error) {
+	kind, err := models.ParseKind(params.Kind)
+	if err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+
+	task := models.NewTask(kind, params.Title, params.Description)
+	if params.Priority != "" {
+		if task.Priority, err = models.ParsePriority(params.Priority); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+	}
+	task.Tags = params.Tags
+	task.Source = params.Source
+	task.ExternalRef = params.Ref
+
+	if err := s.service.CreateTask(task); err != nil {
+		if errors.CodeOf(err) == errors.CodeValidation {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error(), Data: &rpcErrorData{Code: errors.CodeValidation}}
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// decodeParams decodes named parameters into v
+func decodeParams(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
+
+// errorResponse builds an error response, with a null ID when the request's
+// ID couldn't be determined
+func errorResponse(id json.RawMessage, err *rpcError) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: err}
+}
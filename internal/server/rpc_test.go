@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func TestServeRPC(t *testing.T) {
+	repo, _ := setupTestServer(t, false)
+
+	existing := models.NewTask(models.KindBug, "Crash on save", "Saving an empty file crashes")
+	existing.State = models.StateNew
+	if err := repo.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"add","params":{"kind":"feature","title":"Dark mode","description":"Offer a dark color scheme","priority":"high","tags":"ui"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"list","params":{"tag":"ui","state":"inbox"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"transition","params":{"id":"` + existing.ShortHash() + `","action":"start"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"show","params":{"id":"` + existing.ShortHash() + `"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"search","params":{"query":"empty file"}}`,
+		`{"jsonrpc":"2.0","method":"list"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"show","params":{"id":"ffffffff"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"transition","params":{"id":"` + existing.ID + `","action":"accept"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"transition","params":{"id":"` + existing.ID + `","action":"archive"}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"list","params":{"colour":"red"}}`,
+		`{"jsonrpc":"2.0","id":10,"method":"delete"}`,
+		`{"jsonrpc":"2.0","id":11,"method":"add","params":{"kind":"chore","title":"Tidy up"}}`,
+		`{not json`,
+		`[{"jsonrpc":"2.0","id":12,"method":"list"},{"jsonrpc":"2.0","method":"list"}]`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := New(repo, Config{}).ServeRPC(strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("ServeRPC() error = %v", err)
+	}
+
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	var responses []response
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "[") {
+			var batch []response
+			if err := json.Unmarshal([]byte(line), &batch); err != nil {
+				t.Fatalf("invalid batch response %q: %v", line, err)
+			}
+			responses = append(responses, batch...)
+			continue
+		}
+		var r response
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses = append(responses, r)
+	}
+
+	// The notification gets no response
+	if len(responses) != 13 {
+		t.Fatalf("got %d responses, want 13:\n%s", len(responses), out.String())
+	}
+
+	var added models.Task
+	if err := json.Unmarshal(responses[0].Result, &added); err != nil || added.Kind != models.KindFeature || added.Priority != models.PriorityHigh {
+		t.Errorf("add result = %s (%v)", responses[0].Result, err)
+	}
+
+	var listed []*models.Task
+	if err := json.Unmarshal(responses[1].Result, &listed); err != nil || len(listed) != 1 || listed[0].ID != added.ID {
+		t.Errorf("list result = %s (%v)", responses[1].Result, err)
+	}
+
+	var started models.Task
+	if err := json.Unmarshal(responses[2].Result, &started); err != nil || started.State != models.StateInProgress {
+		t.Errorf("transition result = %s (%v)", responses[2].Result, err)
+	}
+
+	var detail taskDetail
+	if err := json.Unmarshal(responses[3].Result, &detail); err != nil || detail.Task.ID != existing.ID || len(detail.History) == 0 {
+		t.Errorf("show result = %s (%v)", responses[3].Result, err)
+	}
+
+	var found []*models.Task
+	if err := json.Unmarshal(responses[4].Result, &found); err != nil || len(found) != 1 || found[0].ID != existing.ID {
+		t.Errorf("search result = %s (%v)", responses[4].Result, err)
+	}
+
+	wantErrors := []struct {
+		id   string
+		code int
+	}{
+		{"6", rpcTaskNotFound},
+		{"7", rpcServerError},
+		{"8", rpcInvalidParams},
+		{"9", rpcInvalidParams},
+		{"10", rpcMethodNotFound},
+		{"11", rpcInvalidParams},
+		{"null", rpcParseError},
+	}
+	for i, want := range wantErrors {
+		got := responses[5+i]
+		if string(got.ID) != want.id || got.Error == nil || got.Error.Code != want.code {
+			t.Errorf("response %s = %+v, want error code %d", want.id, got, want.code)
+		}
+	}
+
+	if string(responses[12].ID) != "12" || responses[12].Error != nil {
+		t.Errorf("batch response = %+v", responses[12])
+	}
+}
@@ -0,0 +1,276 @@
+// Package server serves tasks over a JSON HTTP API, optionally with a small
+// web UI built on that API, and over JSON-RPC for editor integrations
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+	"github.com/zw3rk/gtd/internal/services"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// Config configures a server
+type Config struct {
+	Rules         models.ValidationRules // Rules tasks clients create or edit must follow
+	RequireReason bool                   // Require a reason to reject or cancel tasks
+	UI            bool                   // Serve the web UI at /
+
+	// Addr is the address the HTTP server listens on. When it is a loopback
+	// address, requests must name it in their Host header.
+	Addr net.Addr
+}
+
+// Server handles API and UI requests for a task repository
+type Server struct {
+	repo    models.Store
+	service services.TaskService
+	config  Config
+}
+
+// New creates a server for a repository. Ambiguous task IDs are reported to
+// clients instead of prompting on the terminal, so any chooser set on the
+// repository is removed.
+func New(repo models.Store, config Config) *Server {
+	repo.SetChooser(nil)
+	return &Server{repo: repo, service: services.NewTaskService(repo, config.Rules), config: config}
+}
+
+// Subscribe registers a listener for the changes clients make
+func (s *Server) Subscribe(listener services.Listener) {
+	s.service.Subscribe(listener)
+}
+
+// Handler returns the HTTP handler for the API and, if enabled, the UI
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tasks", s.listTasks)
+	mux.HandleFunc("GET /api/tasks/{id}", s.getTask)
+	mux.HandleFunc("POST /api/tasks/{id}/{action}", s.changeState)
+
+	if s.config.UI {
+		static, err := fs.Sub(uiFiles, "ui")
+		if err != nil {
+			// The UI is embedded at build time, so this cannot happen at runtime
+			panic(err)
+		}
+		mux.Handle("GET /", http.FileServer(http.FS(static)))
+	}
+
+	return checkHost(mux, s.config.Addr)
+}
+
+// checkHost rejects requests to a server listening on a loopback address
+// whose Host header names anything else. A web page on another site can
+// point its own hostname at 127.0.0.1 (DNS rebinding), but its requests then
+// carry that hostname.
+func checkHost(handler http.Handler, addr net.Addr) http.Handler {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok || !tcpAddr.IP.IsLoopback() {
+		return handler
+	}
+	port := strconv.Itoa(tcpAddr.Port)
+	allowed := map[string]bool{
+		net.JoinHostPort(tcpAddr.IP.String(), port): true,
+		net.JoinHostPort("localhost", port):         true,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed[strings.ToLower(r.Host)] {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": fmt.Sprintf("unexpected host %q", r.Host)})
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// taskDetail is a task with its subtasks and history, as returned by
+// GET /api/tasks/{id} and the show method
+type taskDetail struct {
+	Task     *models.Task           `json:"task"`
+	Subtasks []*models.Task         `json:"subtasks"`
+	History  []*models.HistoryEntry `json:"history"`
+}
+
+// stateChange is the optional body of POST /api/tasks/{id}/{action}
+type stateChange struct {
+	Reason string `json:"reason"`
+}
+
+// listTasks lists tasks. Finished tasks are included with ?all=1, and tasks
+// can be filtered with ?state= and ?tag=.
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	all := query.Get("all") == "1"
+	state := models.State(query.Get("state"))
+	opts := models.ListOptions{
+		State:         state,
+		Tag:           query.Get("tag"),
+		All:           all,
+		ShowDone:      all || state == models.StateDone,
+		ShowCancelled: all || state == models.StateCancelled,
+	}
+
+	tasks, err := s.service.ListTasks(opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// getTask returns a task with its subtasks and history
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	detail, err := s.detail(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// detail looks up a task with its subtasks and history
+func (s *Server) detail(id string) (*taskDetail, error) {
+	task, err := s.service.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &taskDetail{Task: task, Subtasks: []*models.Task{}, History: []*models.HistoryEntry{}}
+	subtasks, err := s.service.GetSubtasks(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if subtasks != nil {
+		detail.Subtasks = subtasks
+	}
+	history, err := s.repo.GetHistory(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if history != nil {
+		detail.History = history
+	}
+	return detail, nil
+}
+
+// changeState applies a state change: accept, reject, start, done, cancel, or reopen
+func (s *Server) changeState(w http.ResponseWriter, r *http.Request) {
+	// Requiring JSON keeps plain cross-site form posts from changing tasks
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "requests must be sent as application/json"})
+		return
+	}
+
+	var body stateChange
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+
+	task, err := s.transition(r.PathValue("id"), r.PathValue("action"), body.Reason)
+	if err != nil {
+		var actionErr *unknownActionError
+		if stderrors.As(err, &actionErr) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// unknownActionError reports a state change action that doesn't exist
+type unknownActionError struct {
+	Action string
+}
+
+func (e *unknownActionError) Error() string {
+	return fmt.Sprintf("unknown action: %s", e.Action)
+}
+
+// transition applies a state change action to a task, records the reason if
+// one is given, and returns the updated task
+func (s *Server) transition(id, action, reason string) (*models.Task, error) {
+	task, err := s.service.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if (action == "reject" || action == "cancel") && s.config.RequireReason && strings.TrimSpace(reason) == "" {
+		return nil, errors.NewValidationError("a reason is required to %s a task (GTD_REQUIRE_REASON is set)", action)
+	}
+
+	switch action {
+	case "accept":
+		err = s.service.AcceptTask(task.ID)
+	case "reject":
+		err = s.service.RejectTask(task.ID)
+	case "start":
+		err = s.service.StartTask(task.ID)
+	case "done":
+		_, err = s.service.CompleteTask(task.ID)
+	case "cancel":
+		_, err = s.service.CancelTask(task.ID)
+	case "reopen":
+		err = s.service.ReopenTask(task.ID)
+	default:
+		return nil, &unknownActionError{Action: action}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		if err := s.repo.RecordReason(task.ID, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.service.GetTask(task.ID)
+}
+
+// errorStatuses maps error codes to HTTP statuses; other errors are 500s
+var errorStatuses = map[errors.Code]int{
+	errors.CodeNotFound:          http.StatusNotFound,
+	errors.CodeAmbiguousPrefix:   http.StatusNotFound,
+	errors.CodeInvalidTransition: http.StatusConflict,
+	errors.CodeConflict:          http.StatusConflict,
+	errors.CodeValidation:        http.StatusBadRequest,
+	errors.CodeBusy:              http.StatusServiceUnavailable,
+}
+
+// writeError writes an error response with the error's code and a matching status
+func writeError(w http.ResponseWriter, err error) {
+	code := errors.CodeOf(err)
+	status, ok := errorStatuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error(), "code": string(code)})
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write response: %v\n", err)
+	}
+}
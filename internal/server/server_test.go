@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zw3rk/gtd/internal/database"
+	"github.com/zw3rk/gtd/internal/errors"
+	"github.com/zw3rk/gtd/internal/models"
+)
+
+func setupTestServer(t *testing.T, ui bool) (*models.TaskRepository, *httptest.Server) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close test database: %v", err)
+		}
+	})
+
+	repo := models.NewTaskRepository(db)
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config.Handler = New(repo, Config{UI: ui, Addr: ts.Listener.Addr()}).Handler()
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return repo, ts
+}
+
+func decodeResponse(t *testing.T, resp *http.Response, wantStatus int, v interface{}) {
+	t.Helper()
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, wantStatus)
+	}
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+}
+
+func TestServerAPI(t *testing.T) {
+	repo, ts := setupTestServer(t, false)
+
+	inbox := models.NewTask(models.KindBug, "Crash on start", "Stack trace attached")
+	parent := models.NewTask(models.KindFeature, "Dark mode", "Offer a dark color scheme")
+	parent.State = models.StateNew
+	for _, task := range []*models.Task{inbox, parent} {
+		if err := repo.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subtask := models.NewTask(models.KindFeature, "Pick colors", "Choose the palette")
+	subtask.Parent = &parent.ID
+	subtask.State = models.StateNew
+	if err := repo.Create(subtask); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/tasks?state=INBOX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tasks []*models.Task
+	decodeResponse(t, resp, http.StatusOK, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != inbox.ID {
+		t.Errorf("INBOX tasks = %v, want only %s", tasks, inbox.ShortHash())
+	}
+
+	resp, err = http.Get(ts.URL + "/api/tasks/" + parent.ShortHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var detail taskDetail
+	decodeResponse(t, resp, http.StatusOK, &detail)
+	if detail.Task.ID != parent.ID || len(detail.Subtasks) != 1 || detail.Subtasks[0].ID != subtask.ID {
+		t.Errorf("detail = %+v", detail)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/tasks/ffffffff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse(t, resp, http.StatusNotFound, nil)
+
+	// State changes must be JSON
+	resp, err = http.Post(ts.URL+"/api/tasks/"+inbox.ID+"/accept", "application/x-www-form-urlencoded", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse(t, resp, http.StatusUnsupportedMediaType, nil)
+
+	resp, err = http.Post(ts.URL+"/api/tasks/"+inbox.ID+"/accept", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted models.Task
+	decodeResponse(t, resp, http.StatusOK, &accepted)
+	if accepted.State != models.StateNew {
+		t.Errorf("state after accept = %s, want %s", accepted.State, models.StateNew)
+	}
+
+	// Accepting again is an invalid transition
+	resp, err = http.Post(ts.URL+"/api/tasks/"+inbox.ID+"/accept", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse(t, resp, http.StatusConflict, nil)
+
+	resp, err = http.Post(ts.URL+"/api/tasks/"+subtask.ID+"/cancel", "application/json", strings.NewReader(`{"reason": "not needed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cancelled models.Task
+	decodeResponse(t, resp, http.StatusOK, &cancelled)
+	if cancelled.State != models.StateCancelled {
+		t.Errorf("state after cancel = %s, want %s", cancelled.State, models.StateCancelled)
+	}
+
+	resp, err = http.Post(ts.URL+"/api/tasks/"+subtask.ID+"/archive", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse(t, resp, http.StatusNotFound, nil)
+}
+
+func TestServerUI(t *testing.T) {
+	for _, ui := range []bool{false, true} {
+		_, ts := setupTestServer(t, ui)
+
+		resp, err := http.Get(ts.URL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		want := http.StatusNotFound
+		if ui {
+			want = http.StatusOK
+		}
+		if resp.StatusCode != want {
+			t.Errorf("ui=%v: GET / status = %d, want %d", ui, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestServerHostCheck(t *testing.T) {
+	_, ts := setupTestServer(t, false)
+	port := ts.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		host string
+		want int
+	}{
+		{host: fmt.Sprintf("127.0.0.1:%d", port), want: http.StatusOK},
+		{host: fmt.Sprintf("localhost:%d", port), want: http.StatusOK},
+		{host: fmt.Sprintf("attacker.example:%d", port), want: http.StatusForbidden},
+		{host: "127.0.0.1", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/tasks", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = tt.host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Host %s: status = %d, want %d", tt.host, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestServerRequireReason(t *testing.T) {
+	repo, _ := setupTestServer(t, false)
+	srv := New(repo, Config{RequireReason: true})
+
+	task := models.NewTask(models.KindBug, "Crash on start", "Stack trace attached")
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range []string{"reject", "cancel"} {
+		if _, err := srv.transition(task.ID, action, " "); errors.CodeOf(err) != errors.CodeValidation {
+			t.Errorf("%s without a reason error = %v, want validation error", action, err)
+		}
+	}
+	rejected, err := srv.transition(task.ID, "reject", "duplicate of #12")
+	if err != nil {
+		t.Fatalf("reject with a reason error = %v", err)
+	}
+	if rejected.State != models.StateInvalid {
+		t.Errorf("state after reject = %s, want %s", rejected.State, models.StateInvalid)
+	}
+}
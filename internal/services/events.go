@@ -0,0 +1,56 @@
+package services
+
+import "github.com/zw3rk/gtd/internal/models"
+
+// Event is a change made through a TaskService. It is one of TaskCreated,
+// TaskUpdated, TaskDeleted, StateChanged, TaskBlocked, or TaskUnblocked.
+type Event interface {
+	event()
+}
+
+// TaskCreated is emitted after a task is created
+type TaskCreated struct {
+	Task *models.Task
+}
+
+// TaskUpdated is emitted after a task's fields are updated
+type TaskUpdated struct {
+	Task *models.Task
+}
+
+// TaskDeleted is emitted after a task is deleted
+type TaskDeleted struct {
+	ID string
+}
+
+// StateChanged is emitted after a task moves from one state to another,
+// once for every task a cascade changes
+type StateChanged struct {
+	Task *models.Task // With its new state
+	From models.State
+	To   models.State
+}
+
+// TaskBlocked is emitted after a task is marked as blocked by another
+type TaskBlocked struct {
+	Task      *models.Task
+	BlockedBy *models.Task
+}
+
+// TaskUnblocked is emitted after a blocked task is unblocked, either
+// directly or because its blocker was finished
+type TaskUnblocked struct {
+	Task *models.Task
+}
+
+func (TaskCreated) event()   {}
+func (TaskUpdated) event()   {}
+func (TaskDeleted) event()   {}
+func (StateChanged) event()  {}
+func (TaskBlocked) event()   {}
+func (TaskUnblocked) event() {}
+
+// Listener is called with every event a TaskService emits. Listeners run
+// synchronously, in the order they subscribed, after the change is stored;
+// they cannot undo it, so they report their own failures.
+type Listener func(event Event)
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/zw3rk/gtd/internal/errors"
 	"github.com/zw3rk/gtd/internal/models"
@@ -16,12 +17,15 @@ type TaskService interface {
 	DeleteTask(id string) error
 
 	// Task state operations
-	UpdateTaskState(id, newState string) error
+	UpdateTaskState(id string, newState models.State) error
 	AcceptTask(id string) error
 	RejectTask(id string) error
 	StartTask(id string) error
-	CompleteTask(id string) error
-	CancelTask(id string) error
+	CompleteTask(id string) ([]*models.Task, error)
+	CompleteTaskAt(id string, at time.Time) ([]*models.Task, error)
+	CancelTask(id string) ([]*models.Task, error)
+	CompleteTaskCascade(id string) (changed, unblocked []*models.Task, err error)
+	CancelTaskCascade(id string) (changed, unblocked []*models.Task, err error)
 	ReopenTask(id string) error
 
 	// Task relationships
@@ -31,18 +35,36 @@ type TaskService interface {
 
 	// Task queries
 	ListTasks(opts models.ListOptions) ([]*models.Task, error)
-	ListByState(state string) ([]*models.Task, error)
+	ListByState(state models.State) ([]*models.Task, error)
 	SearchTasks(query string) ([]*models.Task, error)
+
+	// Subscribe registers a listener for the events of every later change
+	Subscribe(listener Listener)
 }
 
 // taskService is the default implementation of TaskService
 type taskService struct {
-	repo *models.TaskRepository
+	repo      models.TaskStore
+	rules     models.ValidationRules
+	listeners []Listener
+}
+
+// NewTaskService creates a new task service on top of a task store that
+// checks created and edited tasks against the given validation rules
+func NewTaskService(repo models.TaskStore, rules models.ValidationRules) TaskService {
+	return &taskService{repo: repo, rules: rules}
+}
+
+// Subscribe registers a listener for the events of every later change
+func (s *taskService) Subscribe(listener Listener) {
+	s.listeners = append(s.listeners, listener)
 }
 
-// NewTaskService creates a new task service
-func NewTaskService(repo *models.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+// emit passes an event to every listener
+func (s *taskService) emit(event Event) {
+	for _, listener := range s.listeners {
+		listener(event)
+	}
 }
 
 // CreateTask creates a new task
@@ -50,7 +72,14 @@ func (s *taskService) CreateTask(task *models.Task) error {
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	return s.repo.Create(task)
+	if err := s.rules.Check(task); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if err := s.repo.Create(task); err != nil {
+		return err
+	}
+	s.emit(TaskCreated{Task: task})
+	return nil
 }
 
 // GetTask retrieves a task by ID
@@ -63,16 +92,39 @@ func (s *taskService) UpdateTask(task *models.Task) error {
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	return s.repo.Update(task)
+	if s.rules != (models.ValidationRules{}) {
+		before, err := s.repo.GetByID(task.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.rules.CheckUpdate(before, task); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+	}
+	if err := s.repo.Update(task); err != nil {
+		return err
+	}
+	s.emit(TaskUpdated{Task: task})
+	return nil
 }
 
 // DeleteTask deletes a task
 func (s *taskService) DeleteTask(id string) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.emit(TaskDeleted{ID: id})
+	return nil
 }
 
 // UpdateTaskState updates the state of a task with validation
-func (s *taskService) UpdateTaskState(id, newState string) error {
+func (s *taskService) UpdateTaskState(id string, newState models.State) error {
+	return s.updateTaskStateAt(id, newState, time.Now())
+}
+
+// updateTaskStateAt updates the state of a task with validation, recording
+// the change as made at the given time
+func (s *taskService) updateTaskStateAt(id string, newState models.State, at time.Time) error {
 	task, err := s.repo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("task not found: %w", err)
@@ -90,14 +142,22 @@ func (s *taskService) UpdateTaskState(id, newState string) error {
 		if newState == models.StateDone && len(children) > 0 {
 			for _, child := range children {
 				if child.State != models.StateDone && child.State != models.StateCancelled {
-					return fmt.Errorf("cannot mark parent task as DONE: child task %s is in %s state", child.ShortHash(), child.State)
+					return errors.NewInvalidStateTransitionErrorf(task.State, newState, "cannot mark parent task as DONE: child task %s is in %s state", child.ShortHash(), child.State)
 				}
 			}
 		}
-		return errors.NewInvalidStateTransitionError(task.State, newState)
+		return models.NewTransitionError(task.State, newState)
+	}
+
+	if err := s.repo.UpdateStateAt(id, newState, at); err != nil {
+		return err
 	}
 
-	return s.repo.UpdateState(id, newState)
+	from := task.State
+	task.State = newState
+	task.Updated = at
+	s.emit(StateChanged{Task: task, From: from, To: newState})
+	return nil
 }
 
 // AcceptTask moves a task from INBOX to NEW
@@ -108,7 +168,7 @@ func (s *taskService) AcceptTask(id string) error {
 	}
 
 	if task.State != models.StateInbox {
-		return fmt.Errorf("task %s is not in INBOX state (current: %s)", task.ShortHash(), task.State)
+		return errors.NewInvalidStateTransitionErrorf(task.State, models.StateNew, "task %s is not in INBOX state (current: %s)", task.ShortHash(), task.State)
 	}
 
 	return s.UpdateTaskState(id, models.StateNew)
@@ -122,7 +182,7 @@ func (s *taskService) RejectTask(id string) error {
 	}
 
 	if task.State == models.StateDone {
-		return fmt.Errorf("cannot mark completed task as invalid")
+		return errors.NewInvalidStateTransitionErrorf(task.State, models.StateInvalid, "cannot mark completed task as invalid")
 	}
 
 	return s.UpdateTaskState(id, models.StateInvalid)
@@ -133,14 +193,125 @@ func (s *taskService) StartTask(id string) error {
 	return s.UpdateTaskState(id, models.StateInProgress)
 }
 
-// CompleteTask marks a task as DONE
-func (s *taskService) CompleteTask(id string) error {
-	return s.UpdateTaskState(id, models.StateDone)
+// CompleteTask marks a task as DONE and unblocks the tasks it was blocking,
+// returning them
+func (s *taskService) CompleteTask(id string) ([]*models.Task, error) {
+	return s.finishTask(id, models.StateDone, time.Now())
+}
+
+// CompleteTaskAt marks a task as DONE at an earlier time, for recording work
+// finished before it was tracked, and unblocks the tasks it was blocking
+func (s *taskService) CompleteTaskAt(id string, at time.Time) ([]*models.Task, error) {
+	return s.finishTask(id, models.StateDone, at)
+}
+
+// CancelTask marks a task as CANCELLED and unblocks the tasks it was
+// blocking, returning them
+func (s *taskService) CancelTask(id string) ([]*models.Task, error) {
+	return s.finishTask(id, models.StateCancelled, time.Now())
+}
+
+// finishTask moves a task to a final state and clears blocked_by on its
+// dependents, since nothing is left for them to wait on
+func (s *taskService) finishTask(id string, state models.State, at time.Time) ([]*models.Task, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.updateTaskStateAt(task.ID, state, at); err != nil {
+		return nil, err
+	}
+
+	return s.unblockDependents(task)
+}
+
+// CompleteTaskCascade marks a task and its open subtasks as DONE, returning
+// the subtasks that changed and the tasks that were unblocked
+func (s *taskService) CompleteTaskCascade(id string) (changed, unblocked []*models.Task, err error) {
+	return s.finishTree(id, models.StateDone)
 }
 
-// CancelTask marks a task as CANCELLED
-func (s *taskService) CancelTask(id string) error {
-	return s.UpdateTaskState(id, models.StateCancelled)
+// CancelTaskCascade marks a task and its open subtasks as CANCELLED,
+// returning the subtasks that changed and the tasks that were unblocked
+func (s *taskService) CancelTaskCascade(id string) (changed, unblocked []*models.Task, err error) {
+	return s.finishTree(id, models.StateCancelled)
+}
+
+// finishTree moves a task and its subtree to a final state and unblocks the
+// dependents of every task that changed
+func (s *taskService) finishTree(id string, state models.State) (changed, unblocked []*models.Task, err error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The cascade returns tasks with their new state, so note the old ones
+	from, err := s.subtreeStates(task)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed, err = s.repo.TransitionSubtree(task.ID, state)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task.State = state
+	for _, finished := range append(changed, task) {
+		s.emit(StateChanged{Task: finished, From: from[finished.ID], To: state})
+	}
+
+	for _, finished := range append(changed, task) {
+		dependents, err := s.unblockDependents(finished)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, dependent := range dependents {
+			// Tasks finished by the cascade itself need no mention
+			if dependent.State != models.StateDone && dependent.State != models.StateCancelled {
+				unblocked = append(unblocked, dependent)
+			}
+		}
+	}
+
+	return changed, unblocked, nil
+}
+
+// subtreeStates maps the IDs of a task and its descendants to their states
+func (s *taskService) subtreeStates(root *models.Task) (map[string]models.State, error) {
+	states := map[string]models.State{root.ID: root.State}
+	queue := []*models.Task{root}
+	for len(queue) > 0 {
+		children, err := s.repo.GetChildren(queue[0].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get children: %w", err)
+		}
+		queue = queue[1:]
+		for _, child := range children {
+			states[child.ID] = child.State
+			queue = append(queue, child)
+		}
+	}
+	return states, nil
+}
+
+// unblockDependents clears blocked_by on the tasks blocked by a finished task
+// and returns them
+func (s *taskService) unblockDependents(task *models.Task) ([]*models.Task, error) {
+	dependents, err := s.repo.GetBlockedTasks(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, dependent := range dependents {
+		if err := s.repo.Unblock(dependent.ID); err != nil {
+			return nil, fmt.Errorf("failed to unblock task %s: %w", dependent.ShortHash(), err)
+		}
+		dependent.BlockedBy = nil
+		s.emit(TaskUnblocked{Task: dependent})
+	}
+
+	return dependents, nil
 }
 
 // ReopenTask moves a cancelled task back to NEW
@@ -151,7 +322,7 @@ func (s *taskService) ReopenTask(id string) error {
 	}
 
 	if task.State != models.StateCancelled {
-		return fmt.Errorf("task %s is not in CANCELLED state (current: %s)", task.ShortHash(), task.State)
+		return errors.NewInvalidStateTransitionErrorf(task.State, models.StateNew, "task %s is not in CANCELLED state (current: %s)", task.ShortHash(), task.State)
 	}
 
 	return s.UpdateTaskState(id, models.StateNew)
@@ -172,15 +343,34 @@ func (s *taskService) BlockTask(taskID, blockingTaskID string) error {
 
 	// Validate not blocking by itself
 	if task.ID == blockingTask.ID {
-		return fmt.Errorf("cannot block a task by itself")
+		return errors.NewValidationError("cannot block a task by itself")
+	}
+
+	if err := s.repo.Block(task.ID, blockingTask.ID); err != nil {
+		return err
 	}
 
-	return s.repo.Block(taskID, blockingTaskID)
+	task.BlockedBy = &blockingTask.ID
+	s.emit(TaskBlocked{Task: task, BlockedBy: blockingTask})
+	return nil
 }
 
 // UnblockTask removes the blocking relationship from a task
 func (s *taskService) UnblockTask(taskID string) error {
-	return s.repo.Unblock(taskID)
+	task, err := s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Unblock(task.ID); err != nil {
+		return err
+	}
+
+	if task.IsBlocked() {
+		task.BlockedBy = nil
+		s.emit(TaskUnblocked{Task: task})
+	}
+	return nil
 }
 
 // GetSubtasks retrieves all subtasks of a parent task
@@ -194,7 +384,7 @@ func (s *taskService) ListTasks(opts models.ListOptions) ([]*models.Task, error)
 }
 
 // ListByState retrieves all tasks with a specific state
-func (s *taskService) ListByState(state string) ([]*models.Task, error) {
+func (s *taskService) ListByState(state models.State) ([]*models.Task, error) {
 	return s.repo.ListByState(state)
 }
 
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/zw3rk/gtd/internal/database"
@@ -22,7 +24,7 @@ func TestTaskServiceCreate(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	tests := []struct {
 		name    string
@@ -87,7 +89,7 @@ func TestTaskServiceStateTransitions(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create a task
 	task := models.NewTask(models.KindBug, "Test Task", "Description")
@@ -97,8 +99,8 @@ func TestTaskServiceStateTransitions(t *testing.T) {
 
 	tests := []struct {
 		name      string
-		fromState string
-		toState   string
+		fromState models.State
+		toState   models.State
 		wantErr   bool
 	}{
 		{
@@ -176,14 +178,14 @@ func TestTaskServiceStateTransitions(t *testing.T) {
 				if err := service.AcceptTask(testTask.ID); err != nil {
 					t.Fatal(err)
 				}
-				if err := service.CompleteTask(testTask.ID); err != nil {
+				if _, err := service.CompleteTask(testTask.ID); err != nil {
 					t.Fatal(err)
 				}
 			case models.StateCancelled:
 				if err := service.AcceptTask(testTask.ID); err != nil {
 					t.Fatal(err)
 				}
-				if err := service.CancelTask(testTask.ID); err != nil {
+				if _, err := service.CancelTask(testTask.ID); err != nil {
 					t.Fatal(err)
 				}
 			case models.StateInvalid:
@@ -225,7 +227,7 @@ func TestTaskServiceAcceptReject(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create a task in INBOX
 	task := models.NewTask(models.KindBug, "Inbox Task", "Description")
@@ -276,7 +278,7 @@ func TestTaskServiceAcceptReject(t *testing.T) {
 			t.Fatal(err)
 		}
 		// Then complete it
-		if err := service.CompleteTask(task3.ID); err != nil {
+		if _, err := service.CompleteTask(task3.ID); err != nil {
 			t.Fatal(err)
 		}
 
@@ -301,7 +303,7 @@ func TestTaskServiceBlocking(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create two tasks
 	task1 := models.NewTask(models.KindBug, "Blocker Task", "Must be done first")
@@ -334,6 +336,41 @@ func TestTaskServiceBlocking(t *testing.T) {
 		}
 	})
 
+	t.Run("completing blocker unblocks dependents", func(t *testing.T) {
+		task3 := models.NewTask(models.KindFeature, "Also Blocked", "Depends on blocker too")
+		if err := service.CreateTask(task3); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.BlockTask(task3.ID, task1.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.AcceptTask(task1.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		unblocked, err := service.CompleteTask(task1.ID)
+		if err != nil {
+			t.Fatalf("CompleteTask() error = %v", err)
+		}
+		if len(unblocked) != 2 {
+			t.Fatalf("CompleteTask() unblocked %d tasks, want 2", len(unblocked))
+		}
+		for _, task := range []*models.Task{task2, task3} {
+			updated, _ := service.GetTask(task.ID)
+			if updated.BlockedBy != nil {
+				t.Errorf("Task %s should no longer be blocked", task.Title)
+			}
+		}
+
+		history, err := repo.GetHistory(task3.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last := history[len(history)-1]; last.Action != models.ActionUnblock {
+			t.Errorf("last history action = %s, want %s", last.Action, models.ActionUnblock)
+		}
+	})
+
 	t.Run("unblock task", func(t *testing.T) {
 		err := service.UnblockTask(task2.ID)
 		if err != nil {
@@ -362,7 +399,7 @@ func TestTaskServiceParentChild(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create parent task
 	parent := models.NewTask(models.KindFeature, "Parent Task", "Has subtasks")
@@ -409,7 +446,7 @@ func TestTaskServiceParentChild(t *testing.T) {
 		}
 
 		// Try to complete parent
-		err := service.CompleteTask(parent.ID)
+		_, err := service.CompleteTask(parent.ID)
 		if err == nil {
 			t.Error("Expected error completing parent with incomplete children")
 		}
@@ -417,15 +454,15 @@ func TestTaskServiceParentChild(t *testing.T) {
 
 	t.Run("can complete parent after children", func(t *testing.T) {
 		// Complete children
-		if err := service.CompleteTask(child1.ID); err != nil {
+		if _, err := service.CompleteTask(child1.ID); err != nil {
 			t.Fatal(err)
 		}
-		if err := service.CompleteTask(child2.ID); err != nil {
+		if _, err := service.CompleteTask(child2.ID); err != nil {
 			t.Fatal(err)
 		}
 
 		// Now complete parent
-		err := service.CompleteTask(parent.ID)
+		_, err := service.CompleteTask(parent.ID)
 		if err != nil {
 			t.Errorf("CompleteTask() error = %v", err)
 		}
@@ -452,7 +489,7 @@ func TestTaskServiceSearch(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create test tasks
 	tasks := []*models.Task{
@@ -522,7 +559,7 @@ func TestTaskServiceReopen(t *testing.T) {
 	}
 
 	repo := models.NewTaskRepository(db)
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, models.ValidationRules{})
 
 	// Create and cancel a task
 	task := models.NewTask(models.KindFeature, "Cancelled Feature", "Was cancelled")
@@ -534,7 +571,7 @@ func TestTaskServiceReopen(t *testing.T) {
 	if err := service.AcceptTask(task.ID); err != nil {
 		t.Fatal(err)
 	}
-	if err := service.CancelTask(task.ID); err != nil {
+	if _, err := service.CancelTask(task.ID); err != nil {
 		t.Fatal(err)
 	}
 
@@ -559,3 +596,148 @@ func TestTaskServiceReopen(t *testing.T) {
 		}
 	})
 }
+
+// TestTaskServiceMemoryStore runs the service without a database
+func TestTaskServiceMemoryStore(t *testing.T) {
+	service := NewTaskService(models.NewMemoryStore(), models.ValidationRules{})
+
+	parent := models.NewTask(models.KindFeature, "Parent Task", "Has subtasks")
+	child := models.NewTask(models.KindBug, "Child Task", "A subtask")
+	child.Parent = &parent.ID
+	blocked := models.NewTask(models.KindBug, "Blocked Task", "Waits for the parent")
+	for _, task := range []*models.Task{parent, child, blocked} {
+		if err := service.CreateTask(task); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.AcceptTask(task.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.BlockTask(blocked.ID, parent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.CompleteTask(parent.ID); err == nil {
+		t.Error("Expected error completing parent with incomplete children")
+	}
+
+	changed, unblocked, err := service.CompleteTaskCascade(parent.ID)
+	if err != nil {
+		t.Fatalf("CompleteTaskCascade() error = %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != child.ID {
+		t.Errorf("CompleteTaskCascade() changed %v, want the child", changed)
+	}
+	if len(unblocked) != 1 || unblocked[0].ID != blocked.ID {
+		t.Errorf("CompleteTaskCascade() unblocked %v, want the blocked task", unblocked)
+	}
+
+	tasks, err := service.ListTasks(models.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != blocked.ID || tasks[0].BlockedBy != nil {
+		t.Errorf("ListTasks() = %v, want only the unblocked task", tasks)
+	}
+}
+
+// TestTaskServiceValidationRules tests that created tasks must follow the
+// rules and edited tasks only when their text changes
+func TestTaskServiceValidationRules(t *testing.T) {
+	store := models.NewMemoryStore()
+	legacy := models.NewTask(models.KindBug, "Legacy title.", "Created before the rule existed")
+	if err := NewTaskService(store, models.ValidationRules{}).CreateTask(legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewTaskService(store, models.ValidationRules{NoTrailingPeriod: true})
+	if err := service.CreateTask(models.NewTask(models.KindBug, "New title.", "Description")); !models.IsRuleViolation(err) {
+		t.Errorf("CreateTask() error = %v, want rule violation", err)
+	}
+
+	legacy.Priority = models.PriorityHigh
+	if err := service.UpdateTask(legacy); err != nil {
+		t.Errorf("UpdateTask() of priority error = %v, want nil", err)
+	}
+	legacy.Title = "Edited legacy title."
+	if err := service.UpdateTask(legacy); !models.IsRuleViolation(err) {
+		t.Errorf("UpdateTask() of title error = %v, want rule violation", err)
+	}
+}
+
+// TestTaskServiceEvents tests the events listeners receive for each change
+func TestTaskServiceEvents(t *testing.T) {
+	service := NewTaskService(models.NewMemoryStore(), models.ValidationRules{})
+
+	var events []string
+	service.Subscribe(func(event Event) {
+		switch e := event.(type) {
+		case TaskCreated:
+			events = append(events, "created "+e.Task.Title)
+		case StateChanged:
+			events = append(events, fmt.Sprintf("%s %s->%s", e.Task.Title, e.From, e.To))
+		case TaskBlocked:
+			events = append(events, e.Task.Title+" blocked by "+e.BlockedBy.Title)
+		case TaskUnblocked:
+			events = append(events, "unblocked "+e.Task.Title)
+		case TaskUpdated:
+			events = append(events, "updated "+e.Task.Title)
+		case TaskDeleted:
+			events = append(events, "deleted")
+		}
+	})
+
+	parent := models.NewTask(models.KindFeature, "Parent", "Has subtasks")
+	child := models.NewTask(models.KindBug, "Child", "A subtask")
+	child.Parent = &parent.ID
+	blocked := models.NewTask(models.KindBug, "Blocked", "Waits for the parent")
+	for _, task := range []*models.Task{parent, child, blocked} {
+		if err := service.CreateTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.AcceptTask(child.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.BlockTask(blocked.ID, parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Failed changes emit nothing
+	if err := service.BlockTask(blocked.ID, blocked.ID); err == nil {
+		t.Error("Expected error blocking a task by itself")
+	}
+	if err := service.AcceptTask(parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := service.CompleteTaskCascade(parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	blocked.Title = "Unblocked"
+	if err := service.UpdateTask(blocked); err != nil {
+		t.Fatal(err)
+	}
+	// Unblocking a task that isn't blocked changes nothing
+	if err := service.UnblockTask(blocked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteTask(blocked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"created Parent",
+		"created Child",
+		"created Blocked",
+		"Child INBOX->NEW",
+		"Blocked blocked by Parent",
+		"Parent INBOX->NEW",
+		"Child NEW->DONE",
+		"Parent NEW->DONE",
+		"unblocked Blocked",
+		"updated Unblocked",
+		"deleted",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}